@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
@@ -8,8 +9,28 @@ import (
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/config"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/eventbus"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/addresses"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/agreements"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/analytics"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/auth"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/bookings"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/calendars"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/duplicates"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/events"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/favorites"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/feeds"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/history"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/imports"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/lots"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/messages"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/organizations"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/payments"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/reference"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/rentals"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/reviews"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/users"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers/viewings"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
 	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
 	"github.com/levelord1311/backendForSharedProject/api_service/pkg/metric"
@@ -18,9 +39,9 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
-	"syscall"
 	"time"
 )
 
@@ -55,6 +76,10 @@ func main() {
 	logger.Println("initializing config...")
 	cfg := config.GetConfig()
 
+	// the event bus and the server are stopped by the signals
+	ctx, stop := signal.NotifyContext(context.Background(), shutdown.Signals...)
+	defer stop()
+
 	logger.Println("initializing router...")
 	router := httprouter.New()
 
@@ -75,15 +100,84 @@ func main() {
 	authHandler.Register(router)
 
 	lotService := lot_service.NewService(cfg.LotService.URL, "/lots", logger)
-	lotsHandler := lots.Handler{LotService: lotService, Logger: logger}
+
+	usersHandler := users.Handler{UserService: userService, LotService: lotService, Logger: logger}
+	usersHandler.Register(router)
+
+	lotsHandler := lots.Handler{LotService: lotService, UserService: userService, Logger: logger}
 	lotsHandler.Register(router)
 
+	bookingsHandler := bookings.Handler{LotService: lotService, Logger: logger}
+	bookingsHandler.Register(router)
+
+	calendarsHandler := calendars.Handler{LotService: lotService, Logger: logger}
+	calendarsHandler.Register(router)
+
+	rentalsHandler := rentals.Handler{LotService: lotService, Logger: logger}
+	rentalsHandler.Register(router)
+
+	historyHandler := history.Handler{LotService: lotService, Logger: logger}
+	historyHandler.Register(router)
+
+	messagesHandler := messages.Handler{LotService: lotService, Logger: logger}
+	messagesHandler.Register(router)
+
+	reviewsHandler := reviews.Handler{LotService: lotService, Logger: logger}
+	reviewsHandler.Register(router)
+
+	viewingsHandler := viewings.Handler{LotService: lotService, Logger: logger}
+	viewingsHandler.Register(router)
+
+	agreementsHandler := agreements.Handler{LotService: lotService, Logger: logger}
+	agreementsHandler.Register(router)
+
+	paymentsHandler := payments.Handler{LotService: lotService, Logger: logger}
+	paymentsHandler.Register(router)
+
+	organizationsHandler := organizations.Handler{LotService: lotService, Logger: logger}
+	organizationsHandler.Register(router)
+
+	importsHandler := imports.Handler{LotService: lotService, Logger: logger}
+	importsHandler.Register(router)
+
+	analyticsHandler := analytics.Handler{LotService: lotService, Logger: logger}
+	analyticsHandler.Register(router)
+
+	favoritesHandler := favorites.Handler{LotService: lotService, Logger: logger}
+	favoritesHandler.Register(router)
+
+	feedsHandler := feeds.Handler{LotService: lotService, Logger: logger}
+	feedsHandler.Register(router)
+
+	duplicatesHandler := duplicates.Handler{LotService: lotService, Logger: logger}
+	duplicatesHandler.Register(router)
+
+	addressesHandler := addresses.Handler{LotService: lotService, Logger: logger}
+	addressesHandler.Register(router)
+
+	referenceHandler := reference.Handler{LotService: lotService, Logger: logger}
+	referenceHandler.Register(router)
+
+	bus := eventbus.New()
+	busStopped := make(chan struct{})
+	go func() {
+		defer close(busStopped)
+		bus.Run(ctx, lotService, cfg.Events.PollInterval, cfg.Events.Grace, cfg.Events.BatchSize, logger)
+	}()
+	eventsHandler := events.Handler{LotService: lotService, JWTHelper: jwtHelper, Bus: bus, Heartbeat: cfg.Events.Heartbeat,
+		Logger: logger}
+	eventsHandler.Register(router)
+
 	logger.Println("starting application...")
-	start(router, logger, cfg)
+	start(ctx, router, logger, cfg)
 
+	<-busStopped
+	logger.Println("application stopped")
 }
 
-func start(router *httprouter.Router, logger logging.Logger, cfg *config.Config) {
+// start serves requests until the context is done and requests in progress are completed. Event streams
+// are ended by closing the event bus.
+func start(ctx context.Context, router *httprouter.Router, logger logging.Logger, cfg *config.Config) {
 	var server *http.Server
 	var listener net.Listener
 
@@ -115,10 +209,15 @@ func start(router *httprouter.Router, logger logging.Logger, cfg *config.Config)
 		Handler:      router,
 		WriteTimeout: 15 * time.Second,
 		ReadTimeout:  15 * time.Second,
+		ConnContext:  events.ConnContext,
 	}
 
-	go shutdown.Graceful([]os.Signal{syscall.SIGABRT, syscall.SIGQUIT, syscall.SIGHUP, os.Interrupt, syscall.SIGTERM},
-		server)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		// requests can't take longer than WriteTimeout anyway
+		shutdown.Graceful(ctx, server, server.WriteTimeout)
+	}()
 
 	logger.Println("application initialized and started")
 
@@ -126,6 +225,7 @@ func start(router *httprouter.Router, logger logging.Logger, cfg *config.Config)
 		switch {
 		case errors.Is(err, http.ErrServerClosed):
 			logger.Warn("server shutdown")
+			<-stopped
 		default:
 			logger.Fatal(err)
 		}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 )
 
+const tooManyRequestsCode = "REAS-000004"
+
 var (
 	ErrNotFound = NewAppError(nil, "not found", "", "REAS-003000")
 )
@@ -66,6 +68,13 @@ func UnauthorizedError(message string) *AppError {
 	return NewAppError(fmt.Errorf(message), message, "", "REAS-000003")
 }
 
+// TooManyRequestsError is written with 429 status code and Retry-After header (in seconds).
+func TooManyRequestsError(message, retryAfter string) *AppError {
+	err := NewAppError(fmt.Errorf(message), message, "", tooManyRequestsCode)
+	err.WithParams(ErrorParams{"retry_after": retryAfter})
+	return err
+}
+
 func APIError(message, developerMessage, code string) *AppError {
 	return NewAppError(fmt.Errorf(message), message, developerMessage, code)
 }
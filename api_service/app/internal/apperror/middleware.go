@@ -18,6 +18,12 @@ func Middleware(h appHandler) http.HandlerFunc {
 					w.Write(ErrNotFound.Marshal())
 					return
 				}
+				if appErr.Code == tooManyRequestsCode {
+					w.Header().Set("Retry-After", appErr.Params["retry_after"])
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write(appErr.Marshal())
+					return
+				}
 				err := err.(*AppError)
 				w.WriteHeader(http.StatusBadRequest)
 				w.Write(err.Marshal())
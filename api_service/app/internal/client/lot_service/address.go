@@ -0,0 +1,36 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const citiesResource = "/cities"
+
+func (c *client) SearchCities(ctx context.Context, query url.Values) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(citiesResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if len(query) > 0 {
+		uri = fmt.Sprintf("%s?%s", uri, query.Encode())
+	}
+
+	return c.send(ctx, http.MethodGet, uri, 0, nil)
+}
+
+func (c *client) SearchDistricts(ctx context.Context, cityID uint, query url.Values) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/districts", citiesResource, cityID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if len(query) > 0 {
+		uri = fmt.Sprintf("%s?%s", uri, query.Encode())
+	}
+
+	return c.send(ctx, http.MethodGet, uri, 0, nil)
+}
@@ -0,0 +1,122 @@
+package lot_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	agreementsResource         = "/agreements"
+	agreementTemplatesResource = "/agreement-templates"
+	// clientIPHeader passes IP of the client, which is kept as evidence of acceptance of agreements
+	clientIPHeader = "X-Real-IP"
+)
+
+func (c *client) GetAgreementTemplates(ctx context.Context, userID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(agreementTemplatesResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) CreateAgreementTemplate(ctx context.Context, userID uint, dto *CreateAgreementTemplateDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(agreementTemplatesResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, userID, dto)
+}
+
+func (c *client) GetAgreementTemplate(ctx context.Context, userID, id uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", agreementTemplatesResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) DeleteAgreementTemplate(ctx context.Context, userID, id uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", agreementTemplatesResource, id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, userID, nil)
+	return err
+}
+
+func (c *client) GetAgreements(ctx context.Context, userID, bookingID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/agreements", bookingsResource, bookingID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) CreateAgreement(ctx context.Context, userID, bookingID uint, dto *CreateAgreementDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/agreements", bookingsResource, bookingID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, userID, dto)
+}
+
+func (c *client) GetAgreement(ctx context.Context, userID, id uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", agreementsResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+// GetAgreementDocument returns the agreement rendered in html or pdf format with headers of the response.
+func (c *client) GetAgreementDocument(ctx context.Context, userID, id uint, format string) ([]byte, http.Header, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/document", agreementsResource, id), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if format != "" {
+		uri = fmt.Sprintf("%s?%s", uri, url.Values{"format": {format}}.Encode())
+	}
+
+	return c.sendRaw(ctx, http.MethodGet, uri, userID, "", nil)
+}
+
+// AcceptAgreement accepts the agreement on behalf of the user, ip of the client is kept as evidence.
+func (c *client) AcceptAgreement(ctx context.Context, userID, id uint, ip string, dto *AcceptAgreementDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/acceptance", agreementsResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	c.base.Logger.Debug("marshaling dto to bytes..")
+	dataBytes, err := json.Marshal(dto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dto due to err: %w", err)
+	}
+	header := http.Header{}
+	header.Set(clientIPHeader, ip)
+
+	body, _, err := c.sendWithHeader(ctx, http.MethodPut, uri, userID, header, bytes.NewBuffer(dataBytes))
+	return body, err
+}
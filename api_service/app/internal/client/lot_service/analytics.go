@@ -0,0 +1,69 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	dashboardResource = "/analytics/lots"
+
+	// sessionIDHeader passes session of anonymous visitor, so views are counted once per session
+	sessionIDHeader = "X-Session-ID"
+)
+
+// ViewLot returns the lot like GetByLotID and counts view of the lot by the user or, for anonymous
+// visitors, by the session.
+func (c *client) ViewLot(ctx context.Context, lotID, userID uint, sessionID string) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/lot/%d", c.Resource, lotID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	header := http.Header{}
+	if userID == 0 && sessionID != "" {
+		header.Set(sessionIDHeader, sessionID)
+	}
+	body, _, err := c.sendWithHeader(ctx, http.MethodGet, uri, userID, header, nil)
+	return body, err
+}
+
+func (c *client) GetLotAnalytics(ctx context.Context, userID, lotID uint, query url.Values) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/lot/%d/analytics", c.Resource, lotID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if len(query) > 0 {
+		uri = fmt.Sprintf("%s?%s", uri, query.Encode())
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) RecordReveal(ctx context.Context, userID, lotID uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/lot/%d/reveals", c.Resource, lotID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodPost, uri, userID, nil)
+	return err
+}
+
+func (c *client) GetAnalyticsDashboard(ctx context.Context, userID uint, query url.Values) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(dashboardResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if len(query) > 0 {
+		uri = fmt.Sprintf("%s?%s", uri, query.Encode())
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
@@ -0,0 +1,130 @@
+package lot_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	bookingsResource  = "/bookings"
+	requesterIDHeader = "X-Requester-ID"
+)
+
+func (c *client) CreateBooking(ctx context.Context, userID uint, dto *CreateBookingDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(bookingsResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, userID, dto)
+}
+
+func (c *client) GetBookings(ctx context.Context, userID uint, party string) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(bookingsResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if party != "" {
+		uri = fmt.Sprintf("%s?party=%s", uri, url.QueryEscape(party))
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) GetBooking(ctx context.Context, userID, id uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", bookingsResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) UpdateBooking(ctx context.Context, userID, id uint, dto *UpdateBookingDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", bookingsResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPatch, uri, userID, dto)
+}
+
+// send marshals dto, if it's not nil, sends it to uri on behalf of the user and returns body of the response.
+// Public endpoints are requested with zero userID.
+func (c *client) send(ctx context.Context, method, uri string, userID uint, dto any) ([]byte, error) {
+	var reqBody io.Reader
+	if dto != nil {
+		c.base.Logger.Debug("marshaling dto to bytes..")
+		dataBytes, err := json.Marshal(dto)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal dto due to err: %w", err)
+		}
+		reqBody = bytes.NewBuffer(dataBytes)
+	}
+
+	body, _, err := c.sendRaw(ctx, method, uri, userID, "", reqBody)
+	return body, err
+}
+
+// sendRaw sends body of given content type to uri on behalf of the user and returns body and headers
+// of the response.
+func (c *client) sendRaw(ctx context.Context, method, uri string, userID uint, contentType string,
+	reqBody io.Reader) ([]byte, http.Header, error) {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return c.sendWithHeader(ctx, method, uri, userID, header, reqBody)
+}
+
+// sendWithHeader sends body with additional headers to uri on behalf of the user and returns body and headers
+// of the response.
+func (c *client) sendWithHeader(ctx context.Context, method, uri string, userID uint, header http.Header,
+	reqBody io.Reader) ([]byte, http.Header, error) {
+	c.base.Logger.Tracef("url: %s", uri)
+
+	c.base.Logger.Debug("creating new request..")
+	req, err := http.NewRequest(method, uri, reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create new request due to error: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if userID != 0 {
+		req.Header.Set(requesterIDHeader, strconv.Itoa(int(userID)))
+	}
+
+	c.base.Logger.Debug("sending created request..")
+	reqCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	req = req.WithContext(reqCtx)
+	response, err := c.base.SendRequest(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to send request due to error: %w", err)
+	}
+
+	if !response.IsOk {
+		return nil, nil, apperror.APIError(response.Error.ErrorCode, response.Error.Message,
+			response.Error.DeveloperMessage)
+	}
+
+	c.base.Logger.Debug("reading response body..")
+	body, err := response.ReadBody()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read body due to error: %w", err)
+	}
+	return body, response.Header(), nil
+}
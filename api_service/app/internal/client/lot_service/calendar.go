@@ -0,0 +1,73 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// calendarResource returns resource of the lot calendar, with optional sub resource.
+func (c *client) calendarResource(lotID uint, sub string) string {
+	return fmt.Sprintf("%s/lot/%d/calendar%s", c.Resource, lotID, sub)
+}
+
+func (c *client) GetCalendar(ctx context.Context, lotID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(c.calendarResource(lotID, ""), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, 0, nil)
+}
+
+func (c *client) ExportCalendar(ctx context.Context, lotID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(c.calendarResource(lotID, ".ics"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, 0, nil)
+}
+
+func (c *client) CreateCalendarBlock(ctx context.Context, userID, lotID uint, dto *CreateCalendarBlockDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(c.calendarResource(lotID, "/blocks"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, userID, dto)
+}
+
+func (c *client) DeleteCalendarBlock(ctx context.Context, userID, lotID, blockID uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(c.calendarResource(lotID, fmt.Sprintf("/blocks/%d", blockID)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, userID, nil)
+	return err
+}
+
+func (c *client) GetCalendarImport(ctx context.Context, userID, lotID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(c.calendarResource(lotID, "/import"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) SetCalendarImport(ctx context.Context, userID, lotID uint, dto *SetCalendarImportDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(c.calendarResource(lotID, "/import"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPut, uri, userID, dto)
+}
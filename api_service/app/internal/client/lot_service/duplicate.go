@@ -0,0 +1,44 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const adminDuplicatesResource = "/admin/duplicates"
+
+func (c *client) GetDuplicates(ctx context.Context, query url.Values) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(adminDuplicatesResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if len(query) > 0 {
+		uri = fmt.Sprintf("%s?%s", uri, query.Encode())
+	}
+
+	return c.send(ctx, http.MethodGet, uri, 0, nil)
+}
+
+func (c *client) ModerateDuplicate(ctx context.Context, id uint, dto *ModerateDuplicateDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", adminDuplicatesResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPut, uri, 0, dto)
+}
+
+func (c *client) SetLotPhotos(ctx context.Context, userID, lotID uint, dto *SetLotPhotosDTO) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/lot/%d/photos", c.Resource, lotID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodPut, uri, userID, dto)
+	return err
+}
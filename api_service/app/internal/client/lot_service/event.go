@@ -0,0 +1,59 @@
+package lot_service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const eventsResource = "/events"
+
+// GetEvents returns up to limit events after the given one, of all users, if userID is zero.
+func (c *client) GetEvents(ctx context.Context, afterID, userID uint, limit int) ([]*Event, error) {
+	uri, err := c.base.BuildURL(eventsResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	query := url.Values{}
+	query.Set("after", strconv.Itoa(int(afterID)))
+	query.Set("limit", strconv.Itoa(limit))
+	if userID != 0 {
+		query.Set("user_id", strconv.Itoa(int(userID)))
+	}
+	uri = fmt.Sprintf("%s?%s", uri, query.Encode())
+
+	body, err := c.send(ctx, http.MethodGet, uri, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*Event
+	if err = json.Unmarshal(body, &events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal events. error: %w", err)
+	}
+	return events, nil
+}
+
+// GetLastEventID returns ID of the last published event.
+func (c *client) GetLastEventID(ctx context.Context) (uint, error) {
+	uri, err := c.base.BuildURL(eventsResource+"/last", nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	body, err := c.send(ctx, http.MethodGet, uri, 0, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var last struct {
+		ID uint `json:"id"`
+	}
+	if err = json.Unmarshal(body, &last); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal last event id. error: %w", err)
+	}
+	return last.ID, nil
+}
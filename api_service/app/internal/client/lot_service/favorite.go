@@ -0,0 +1,40 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const favoritesResource = "/favorites"
+
+func (c *client) GetFavorites(ctx context.Context, userID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(favoritesResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) AddFavorite(ctx context.Context, userID, lotID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", favoritesResource, lotID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPut, uri, userID, nil)
+}
+
+func (c *client) RemoveFavorite(ctx context.Context, userID, lotID uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", favoritesResource, lotID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, userID, nil)
+	return err
+}
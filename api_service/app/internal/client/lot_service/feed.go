@@ -0,0 +1,84 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	adminFeedsResource = "/admin/feeds"
+	feedsResource      = "/feeds"
+	cityFeedsResource  = "/city-feeds"
+)
+
+func (c *client) GetFeeds(ctx context.Context) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(adminFeedsResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, 0, nil)
+}
+
+func (c *client) CreateFeed(ctx context.Context, dto *FeedDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(adminFeedsResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, 0, dto)
+}
+
+func (c *client) UpdateFeed(ctx context.Context, id uint, dto *FeedDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", adminFeedsResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPut, uri, 0, dto)
+}
+
+func (c *client) DeleteFeed(ctx context.Context, id uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", adminFeedsResource, id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, 0, nil)
+	return err
+}
+
+// GetFeedDocument returns rendered feed with headers of the response. Conditional headers are passed
+// to lot service, body is empty, if the feed is not modified.
+func (c *client) GetFeedDocument(ctx context.Context, id uint, conditions http.Header) ([]byte, http.Header, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", feedsResource, id), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.sendWithHeader(ctx, http.MethodGet, uri, 0, conditions, nil)
+}
+
+// GetCityFeedDocument returns feed of the newest lots of the city like GetFeedDocument.
+func (c *client) GetCityFeedDocument(ctx context.Context, city, format string,
+	conditions http.Header) ([]byte, http.Header, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(cityFeedsResource, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	// escaped, so the name can't change path of the request
+	uri = fmt.Sprintf("%s/%s", uri, url.PathEscape(city))
+	if format != "" {
+		uri = fmt.Sprintf("%s?%s", uri, url.Values{"format": {format}}.Encode())
+	}
+
+	return c.sendWithHeader(ctx, http.MethodGet, uri, 0, conditions, nil)
+}
@@ -0,0 +1,37 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const adminLotsResource = "/admin/lots"
+
+// historyResource returns resource of versions of the lot, versions of any lot are served by admin resource.
+func (c *client) historyResource(lotID uint, moderator bool, sub string) string {
+	if moderator {
+		return fmt.Sprintf("%s/%d/history%s", adminLotsResource, lotID, sub)
+	}
+	return fmt.Sprintf("%s/lot/%d/history%s", c.Resource, lotID, sub)
+}
+
+func (c *client) GetLotHistory(ctx context.Context, userID, lotID uint, moderator bool) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(c.historyResource(lotID, moderator, ""), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) RevertLot(ctx context.Context, userID, lotID, number uint, moderator bool) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(c.historyResource(lotID, moderator, fmt.Sprintf("/%d/revert", number)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, userID, nil)
+}
@@ -0,0 +1,81 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const (
+	lotImportsResource        = "/lot-imports"
+	lotImportProfilesResource = "/lot-import-profiles"
+)
+
+func (c *client) GetLotImports(ctx context.Context, userID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(lotImportsResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+// CreateLotImport uploads spreadsheet with options of the import in query.
+func (c *client) CreateLotImport(ctx context.Context, userID uint, query url.Values, contentType string,
+	data io.Reader) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(lotImportsResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if len(query) > 0 {
+		uri = fmt.Sprintf("%s?%s", uri, query.Encode())
+	}
+
+	body, _, err := c.sendRaw(ctx, http.MethodPost, uri, userID, contentType, data)
+	return body, err
+}
+
+func (c *client) GetLotImport(ctx context.Context, userID, id uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", lotImportsResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) GetLotImportProfiles(ctx context.Context, userID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(lotImportProfilesResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) CreateLotImportProfile(ctx context.Context, userID uint, dto *CreateImportProfileDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(lotImportProfilesResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, userID, dto)
+}
+
+func (c *client) DeleteLotImportProfile(ctx context.Context, userID, id uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", lotImportProfilesResource, id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, userID, nil)
+	return err
+}
@@ -0,0 +1,142 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const (
+	conversationsResource = "/conversations"
+	messagesResource      = "/messages"
+)
+
+func (c *client) GetConversations(ctx context.Context, userID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(conversationsResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) StartConversation(ctx context.Context, userID uint, dto *StartConversationDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(conversationsResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, userID, dto)
+}
+
+func (c *client) GetConversation(ctx context.Context, userID, id uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", conversationsResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) GetMessages(ctx context.Context, userID, conversationID uint, query url.Values) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/messages", conversationsResource, conversationID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if len(query) > 0 {
+		uri = fmt.Sprintf("%s?%s", uri, query.Encode())
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) SendMessage(ctx context.Context, userID, conversationID uint, dto *SendMessageDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/messages", conversationsResource, conversationID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, userID, dto)
+}
+
+func (c *client) MarkRead(ctx context.Context, userID, conversationID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/read", conversationsResource, conversationID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPut, uri, userID, nil)
+}
+
+func (c *client) GetUnread(ctx context.Context, userID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(messagesResource+"/unread", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) UploadAttachment(ctx context.Context, userID uint, name, contentType string,
+	data io.Reader) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(messagesResource+"/attachments", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	uri = fmt.Sprintf("%s?name=%s", uri, url.QueryEscape(name))
+
+	body, _, err := c.sendRaw(ctx, http.MethodPost, uri, userID, contentType, data)
+	return body, err
+}
+
+func (c *client) GetAttachment(ctx context.Context, userID, id uint) ([]byte, http.Header, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/attachments/%d", messagesResource, id), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.sendRaw(ctx, http.MethodGet, uri, userID, "", nil)
+}
+
+func (c *client) GetBlockedUsers(ctx context.Context, userID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(messagesResource+"/blocks", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) BlockUser(ctx context.Context, userID, blockedID uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/blocks/%d", messagesResource, blockedID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodPut, uri, userID, nil)
+	return err
+}
+
+func (c *client) UnblockUser(ctx context.Context, userID, blockedID uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/blocks/%d", messagesResource, blockedID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, userID, nil)
+	return err
+}
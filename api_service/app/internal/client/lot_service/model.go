@@ -1,20 +1,34 @@
 package lot_service
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Lot struct {
-	ID              uint   `json:"id"`
-	CreatedByUserID uint   `json:"created_by_user_id"`
-	TypeOfEstate    string `json:"type_of_estate"`
-	Rooms           int    `json:"rooms"`
-	Area            int    `json:"area"`
-	Floor           int    `json:"floor"`
-	MaxFloor        int    `json:"max_floor"`
-	City            string `json:"city"`
-	District        string `json:"district"`
-	Street          string `json:"street"`
-	Building        string `json:"building"`
-	Price           int    `json:"price"`
+	ID              uint      `json:"id"`
+	CreatedByUserID uint      `json:"created_by_user_id"`        // agent of the lot, if it's owned by organization
+	OrganizationID  *uint     `json:"organization_id,omitempty"` // empty for lots of private landlords
+	TypeOfEstate    string    `json:"type_of_estate"`
+	Rooms           int       `json:"rooms"`
+	Area            int       `json:"area"`
+	Floor           int       `json:"floor"`
+	MaxFloor        int       `json:"max_floor"`
+	CityID          *uint     `json:"city_id"` // canonical city and district, null until the address is resolved
+	DistrictID      *uint     `json:"district_id"`
+	City            string    `json:"city"`
+	District        string    `json:"district"`
+	Street          string    `json:"street"`
+	Building        string    `json:"building"`
+	Price           int       `json:"price"`
+	Currency        string    `json:"currency"`                  // ISO 4217 code of the price
+	PricePeriod     string    `json:"price_period"`              // month, day or sale
+	Converted       *Price    `json:"converted_price,omitempty"` // price in currency from query, if it's given
+	Stay            *Stay     `json:"stay,omitempty"`            // price of the stay, if rental_mode and available_between are given
+	Location        *Location `json:"location"`                  // null for lots with unknown coordinates
+	Available       bool      `json:"available"`                 // false during stays of accepted bookings
+	Rating          Rating    `json:"rating"`                    // of the lot by its reviews
+	LandlordRating  Rating    `json:"landlord_rating"`           // of the owner by reviews of all the owner's lots
 	CreatedAt       time.Time
 	RedactedAt      time.Time
 }
@@ -22,17 +36,105 @@ type Lot struct {
 // CreateLotDTO model info
 // @Description lot information for registering in db.
 type CreateLotDTO struct {
-	CreatedByUserID uint   `json:"created_by_user_id"` // leave empty, value is taken from JWT
-	TypeOfEstate    string `json:"type_of_estate"`     // required. either "квартира" or "дом"
-	Rooms           int    `json:"rooms"`              // required. max - 6; 0 rooms means studio flat
-	Area            int    `json:"area"`               // required.
-	Floor           int    `json:"floor"`              // required. max - 163
-	MaxFloor        int    `json:"max_floor"`          // required. max - 163
-	City            string `json:"city"`               // required.
-	District        string `json:"district"`           // required.
-	Street          string `json:"street"`             // required.
-	Building        string `json:"building"`           // required.
-	Price           int    `json:"price"`              // required.
+	CreatedByUserID uint      `json:"created_by_user_id"` // leave empty, value is taken from JWT
+	OrganizationID  uint      `json:"organization_id"`    // optional. the user must be a member of the organization
+	TypeOfEstate    string    `json:"type_of_estate"`     // required. code of estate type, see /reference
+	Rooms           int       `json:"rooms"`              // required. max - 6; 0 rooms means studio flat
+	Area            int       `json:"area"`               // required.
+	Floor           int       `json:"floor"`              // required. max - 163
+	MaxFloor        int       `json:"max_floor"`          // required. max - 163
+	City            string    `json:"city"`               // required. any spelling of known city, see /cities
+	District        string    `json:"district"`           // required. any spelling of known district of the city
+	Street          string    `json:"street"`             // required.
+	Building        string    `json:"building"`           // required.
+	Price           int       `json:"price"`              // required.
+	Currency        string    `json:"currency"`           // optional. ISO 4217 code, RUB by default
+	PricePeriod     string    `json:"price_period"`       // optional. month (default), day or sale
+	Location        *Location `json:"location"`           // optional. coordinates of the building
+}
+
+// Price model info
+// @Description price converted to another currency at the latest rates
+type Price struct {
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// Stay model info
+// @Description price of the stay in the lot. Months are given only for long-term stays.
+type Stay struct {
+	Mode        string `json:"mode" enums:"long_term,short_term"`
+	CheckIn     string `json:"check_in"`
+	CheckOut    string `json:"check_out"`
+	Nights      int    `json:"nights"`
+	Months      int    `json:"months,omitempty"`
+	Price       int    `json:"price"` // for nights or months of the stay with seasonal prices
+	CleaningFee int    `json:"cleaning_fee"`
+	Total       int    `json:"total"`
+	Currency    string `json:"currency"`
+	Converted   *Price `json:"converted_total,omitempty"` // total in currency from query, if it's given
+}
+
+// Rental model info
+// @Description rental modes of the lot. Lots without modes are rented in the mode of their price period.
+type Rental struct {
+	LotID    uint          `json:"lot_id"`
+	Currency string        `json:"currency"`
+	Modes    []RentalMode  `json:"modes"`
+	Seasons  []SeasonPrice `json:"seasons"`
+}
+
+// RentalMode model info
+// @Description price is per month for long-term rent and per night for short-term rent, minimal stay is in
+// @Description the same units. Cleaning fee is for the whole stay.
+type RentalMode struct {
+	Mode        string `json:"mode" enums:"long_term,short_term"`
+	Price       int    `json:"price"`
+	MinStay     int    `json:"min_stay"`
+	CleaningFee int    `json:"cleaning_fee"`
+}
+
+// SeasonPrice model info
+// @Description price of the mode for nights or months starting in the season. End day is not included.
+type SeasonPrice struct {
+	ID    uint      `json:"id"`
+	Mode  string    `json:"mode" enums:"long_term,short_term"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Price int       `json:"price"`
+}
+
+// SetRentalDTO model info
+// @Description rental modes and seasonal prices of the lot, they replace current ones. Empty modes restore
+// @Description the default mode.
+type SetRentalDTO struct {
+	Modes   []SetRentalModeDTO  `json:"modes"`   // 2 at most, one of each mode
+	Seasons []SetSeasonPriceDTO `json:"seasons"` // seasons of the same mode must not overlap
+}
+
+// SetRentalModeDTO model info
+// @Description rental mode of the lot.
+type SetRentalModeDTO struct {
+	Mode        string `json:"mode" enums:"long_term,short_term"` // required.
+	Price       int    `json:"price" example:"3000"`              // required.
+	MinStay     int    `json:"min_stay" example:"2"`              // optional. 1 by default
+	CleaningFee int    `json:"cleaning_fee" example:"1500"`       // optional.
+}
+
+// SetSeasonPriceDTO model info
+// @Description seasonal price of the rental mode.
+type SetSeasonPriceDTO struct {
+	Mode  string `json:"mode" enums:"long_term,short_term"` // required.
+	Start string `json:"start" example:"2026-12-30"`        // required.
+	End   string `json:"end" example:"2027-01-09"`          // required. the day is not included
+	Price int    `json:"price" example:"5000"`              // required.
+}
+
+// Location model info
+// @Description coordinates of the building in degrees
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
 }
 
 type UpdateLotDTO struct {
@@ -40,3 +142,757 @@ type UpdateLotDTO struct {
 	CreatedByUserID uint `json:"created_by_user_id"`
 	Price           int  `json:"price"`
 }
+
+// LotVersion model info
+// @Description the lot after the change made by the actor. Versions are numbered from 1, the first one
+// @Description has no changes. Versions of rental action change rental modes, which aren't restored by revert.
+type LotVersion struct {
+	ID         uint        `json:"id"`
+	LotID      uint        `json:"lot_id"`
+	Number     uint        `json:"number"`
+	Action     string      `json:"action" enums:"create,update,transfer,revert,rental"`
+	ActorID    uint        `json:"actor_id"`
+	RevertedTo *uint       `json:"reverted_to,omitempty"` // number of version restored by revert
+	Changes    []LotChange `json:"changes"`
+	Snapshot   LotSnapshot `json:"snapshot"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// LotChange model info
+// @Description field of the lot changed by the version.
+type LotChange struct {
+	Field string `json:"field" example:"price"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// LotSnapshot model info
+// @Description fields of the lot kept in its versions.
+type LotSnapshot struct {
+	AgentID      uint      `json:"agent_id"`
+	TypeOfEstate string    `json:"type_of_estate"`
+	Rooms        int       `json:"rooms"`
+	Area         int       `json:"area"`
+	Floor        int       `json:"floor"`
+	MaxFloor     int       `json:"max_floor"`
+	City         string    `json:"city"`
+	District     string    `json:"district"`
+	Street       string    `json:"street"`
+	Building     string    `json:"building"`
+	Price        int       `json:"price"`
+	Currency     string    `json:"currency"`
+	PricePeriod  string    `json:"price_period"`
+	Location     *Location `json:"location"`
+}
+
+// Booking model info
+// @Description request of renter to rent the lot for given dates.
+type Booking struct {
+	ID         uint       `json:"id"`
+	LotID      uint       `json:"lot_id"`
+	RenterID   uint       `json:"renter_id"`
+	LandlordID uint       `json:"landlord_id"`
+	CheckIn    time.Time  `json:"check_in"`
+	CheckOut   time.Time  `json:"check_out"`
+	Message    string     `json:"message"`
+	Status     string     `json:"status" enums:"pending,countered,accepted,declined,cancelled,expired"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // pending and countered bookings expire, if not answered before that time
+	Total      int        `json:"total"`                // price of the stay quoted on acceptance, payments are its shares
+	Currency   string     `json:"currency"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RedactedAt time.Time  `json:"redacted_at"`
+}
+
+// CreateBookingDTO model info
+// @Description booking request.
+type CreateBookingDTO struct {
+	LotID    uint   `json:"lot_id"`                         // required.
+	RenterID uint   `json:"renter_id"`                      // leave empty, value is taken from JWT
+	CheckIn  string `json:"check_in" example:"2026-11-01"`  // required.
+	CheckOut string `json:"check_out" example:"2026-11-07"` // required. must be after check in
+	Message  string `json:"message"`                        // max 2000 characters
+}
+
+// UpdateBookingDTO model info
+// @Description action on booking. Landlord answers pending request, renter answers counter offer.
+// @Description Accepted booking can be cancelled by both sides.
+type UpdateBookingDTO struct {
+	Action   string `json:"action" enums:"accept,decline,counter,cancel"` // required.
+	CheckIn  string `json:"check_in" example:"2026-11-02"`                // required for counter offer
+	CheckOut string `json:"check_out" example:"2026-11-08"`               // required for counter offer
+	Message  string `json:"message"`
+}
+
+// CalendarPeriod model info
+// @Description busy period of the lot. End day is free for check in.
+type CalendarPeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Kind  string    `json:"kind" enums:"booked,blocked"`
+}
+
+// CalendarBlock model info
+// @Description period, when the lot can't be booked. End day is not blocked.
+type CalendarBlock struct {
+	ID      uint      `json:"id"`
+	LotID   uint      `json:"lot_id"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Source  string    `json:"source" enums:"manual,import"`
+	Summary string    `json:"summary"`
+}
+
+// CreateCalendarBlockDTO model info
+// @Description period blocked by the owner of the lot.
+type CreateCalendarBlockDTO struct {
+	Start   string `json:"start" example:"2026-12-30"` // required.
+	End     string `json:"end" example:"2027-01-02"`   // required. the day is not blocked
+	Summary string `json:"summary"`                    // max 255 characters
+}
+
+// CalendarImport model info
+// @Description external iCal calendar of the lot, it is imported periodically.
+type CalendarImport struct {
+	LotID          uint       `json:"lot_id"`
+	URL            string     `json:"url"`
+	LastImportedAt *time.Time `json:"last_imported_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// SetCalendarImportDTO model info
+// @Description URL of external iCal calendar.
+type SetCalendarImportDTO struct {
+	URL string `json:"url" example:"https://example.com/calendar.ics"` // empty URL disables import
+}
+
+// CalendarImportResult model info
+// @Description result of calendar import.
+type CalendarImportResult struct {
+	Imported  int                `json:"imported"`
+	Conflicts []CalendarConflict `json:"conflicts"`
+}
+
+// CalendarConflict model info
+// @Description imported event overlapping accepted booking.
+type CalendarConflict struct {
+	BookingID uint      `json:"booking_id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Summary   string    `json:"summary"`
+}
+
+// Conversation model info
+// @Description thread between owner of the lot and user asking about it.
+type Conversation struct {
+	ID            uint      `json:"id"`
+	LotID         uint      `json:"lot_id"`
+	RenterID      uint      `json:"renter_id"`
+	LandlordID    uint      `json:"landlord_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastMessageAt time.Time `json:"last_message_at"`
+	Unread        int       `json:"unread"` // messages of the other side not read by the user
+}
+
+// Message model info
+// @Description message in conversation.
+type Message struct {
+	ID             uint          `json:"id"`
+	ConversationID uint          `json:"conversation_id"`
+	SenderID       uint          `json:"sender_id"`
+	Body           string        `json:"body"`
+	Attachments    []*Attachment `json:"attachments"`
+	CreatedAt      time.Time     `json:"created_at"`
+	ReadAt         *time.Time    `json:"read_at,omitempty"` // read receipt of the recipient
+}
+
+// Attachment model info
+// @Description file uploaded by the user. It can be sent in one message.
+type Attachment struct {
+	ID          uint      `json:"id"`
+	MessageID   *uint     `json:"message_id,omitempty"`
+	UploaderID  uint      `json:"uploader_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BlockedUser model info
+// @Description user, who can't write to the blocker.
+type BlockedUser struct {
+	BlockerID uint      `json:"blocker_id"`
+	BlockedID uint      `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UnreadMessages model info
+// @Description number of unread messages of the user.
+type UnreadMessages struct {
+	Messages      int `json:"messages"`
+	Conversations int `json:"conversations"`
+}
+
+// ReadMessages model info
+// @Description number of messages marked as read.
+type ReadMessages struct {
+	Read int64 `json:"read"`
+}
+
+// StartConversationDTO model info
+// @Description first message to owner of the lot.
+type StartConversationDTO struct {
+	LotID         uint   `json:"lot_id"`         // required.
+	Body          string `json:"body"`           // required. max 4000 characters
+	AttachmentIDs []uint `json:"attachment_ids"` // uploaded attachments, max 10
+}
+
+// SendMessageDTO model info
+// @Description message to conversation.
+type SendMessageDTO struct {
+	Body          string `json:"body"`           // required without attachments. max 4000 characters
+	AttachmentIDs []uint `json:"attachment_ids"` // uploaded attachments, max 10
+}
+
+// Event model info
+// @Description notification for the user. Payload of message event is {lot_id, message},
+// @Description payload of booking event is the booking, payload of review event is the review,
+// @Description payload of viewing event is ViewingNotice, payload of agreement event is the agreement,
+// @Description payload of payment event is the payment, payload of price_drop event is
+// @Description {lot_id, old_price, new_price, currency, price_period} of the saved lot,
+// @Description payload of moderation event is {subject, object} where subject is review or duplicate.
+type Event struct {
+	ID        uint            `json:"id"`
+	UserID    uint            `json:"user_id"`
+	Type      string          `json:"type" enums:"message,booking,review,viewing,agreement,payment,membership,import,price_drop,moderation"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Review model info
+// @Description review of the renter after completed booking, it rates both the lot and its landlord.
+type Review struct {
+	ID         uint       `json:"id"`
+	BookingID  uint       `json:"booking_id"`
+	LotID      uint       `json:"lot_id"` // zero, if the lot was deleted
+	LandlordID uint       `json:"landlord_id"`
+	AuthorID   uint       `json:"author_id"`
+	Rating     int        `json:"rating"`
+	Text       string     `json:"text"`
+	Reply      string     `json:"reply,omitempty"` // answer of the landlord
+	RepliedAt  *time.Time `json:"replied_at,omitempty"`
+	Hidden     bool       `json:"hidden"` // hidden by moderators or after complaints, shown only to moderators
+	Reports    int        `json:"reports"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Rating model info
+// @Description average rating by visible reviews.
+type Rating struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+// CreateReviewDTO model info
+// @Description review of completed booking.
+type CreateReviewDTO struct {
+	BookingID uint   `json:"booking_id"` // required. accepted booking of the user, which is over
+	Rating    int    `json:"rating"`     // required. from 1 to 5
+	Text      string `json:"text"`       // max 5000 characters
+}
+
+// ReplyDTO model info
+// @Description answer of the landlord to the review.
+type ReplyDTO struct {
+	Text string `json:"text"` // required. max 5000 characters
+}
+
+// ReportDTO model info
+// @Description complaint about abusive review.
+type ReportDTO struct {
+	Reason string `json:"reason"` // required. max 1000 characters
+}
+
+// ModerateReviewDTO model info
+// @Description decision of the moderator about the review.
+type ModerateReviewDTO struct {
+	Hidden bool `json:"hidden"`
+}
+
+// ViewingSlot model info
+// @Description time, when the landlord shows the lot. Booked slot is an appointment of one renter.
+type ViewingSlot struct {
+	ID         uint       `json:"id"`
+	LotID      uint       `json:"lot_id"`
+	LandlordID uint       `json:"landlord_id"`
+	StartsAt   time.Time  `json:"starts_at"`
+	EndsAt     time.Time  `json:"ends_at"`
+	RenterID   *uint      `json:"renter_id,omitempty"`
+	BookedAt   *time.Time `json:"booked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ViewingAppointment model info
+// @Description booked slot with calendar invite for its parties.
+type ViewingAppointment struct {
+	Slot   *ViewingSlot `json:"slot"`
+	Invite string       `json:"invite"` // iCalendar request, or cancellation for cancelled appointments
+}
+
+// ViewingNotice model info
+// @Description payload of viewing event.
+type ViewingNotice struct {
+	Action string       `json:"action" enums:"booked,rescheduled,cancelled,reminder"`
+	Slot   *ViewingSlot `json:"slot"`
+	Invite string       `json:"invite"`
+}
+
+// CreateViewingSlotDTO model info
+// @Description time, when the landlord is ready to show the lot. Slots last from 10 minutes to 4 hours
+// @Description and start within 90 days.
+type CreateViewingSlotDTO struct {
+	StartsAt string `json:"starts_at" example:"2026-11-02T18:00:00+03:00"` // required. RFC 3339
+	EndsAt   string `json:"ends_at" example:"2026-11-02T18:30:00+03:00"`   // required. RFC 3339
+}
+
+// UpdateViewingSlotDTO model info
+// @Description new time of the slot, renter of booked slot is notified.
+type UpdateViewingSlotDTO struct {
+	StartsAt string `json:"starts_at" example:"2026-11-02T19:00:00+03:00"` // required. RFC 3339
+	EndsAt   string `json:"ends_at" example:"2026-11-02T19:30:00+03:00"`   // required. RFC 3339
+}
+
+// BookViewingDTO model info
+// @Description booking of viewing slot.
+type BookViewingDTO struct {
+	FromSlotID uint `json:"from_slot_id"` // slot of the user's appointment, which is moved to the booked slot
+}
+
+// Agreement model info
+// @Description version of rental agreement of accepted booking. Text of the version never changes,
+// @Description changes make a new version, which has to be accepted by both parties again.
+type Agreement struct {
+	ID                 uint                 `json:"id"`
+	BookingID          uint                 `json:"booking_id"`
+	LotID              uint                 `json:"lot_id"`
+	LandlordID         uint                 `json:"landlord_id"`
+	RenterID           uint                 `json:"renter_id"`
+	Version            int                  `json:"version"`
+	TemplateID         *uint                `json:"template_id,omitempty"` // missing for the default template
+	Text               string               `json:"text"`                  // lines starting with "# " are headings
+	Hash               string               `json:"hash"`                  // hex SHA-256 of the text
+	LandlordAcceptance *AgreementAcceptance `json:"landlord_acceptance,omitempty"`
+	RenterAcceptance   *AgreementAcceptance `json:"renter_acceptance,omitempty"`
+	CreatedAt          time.Time            `json:"created_at"`
+}
+
+// AgreementAcceptance model info
+// @Description evidence of acceptance of agreement by the party.
+type AgreementAcceptance struct {
+	At time.Time `json:"at"`
+	IP string    `json:"ip"`
+}
+
+// AgreementTemplate model info
+// @Description text/template of agreement. Template with zero id is the default one.
+type AgreementTemplate struct {
+	ID        uint      `json:"id"`
+	OwnerID   uint      `json:"owner_id"`
+	Name      string    `json:"name"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateAgreementTemplateDTO model info
+// @Description Go text/template of agreement. Available data: .Number, .Date, .Lot (fields of the lot),
+// @Description .Landlord and .Renter (.Name, .Email, .Phone), .CheckIn, .CheckOut, .Nights;
+// @Description functions: date, money. Lines starting with "# " are headings, paragraphs are separated
+// @Description by empty lines.
+type CreateAgreementTemplateDTO struct {
+	Name string `json:"name"` // required. max 100 characters
+	Body string `json:"body"` // required. max 65536 characters
+}
+
+// CreateAgreementDTO model info
+// @Description makes new version of agreement of the booking from the template.
+type CreateAgreementDTO struct {
+	TemplateID uint `json:"template_id"` // own template of the landlord, the default one if omitted
+}
+
+// AcceptAgreementDTO model info
+// @Description acceptance of the latest version of agreement.
+type AcceptAgreementDTO struct {
+	Hash string `json:"hash"` // required. hash of the accepted version, so parties accept exactly the text they read
+}
+
+// Payment model info
+// @Description payment of accepted booking collected from the renter for the landlord. Card data is entered
+// @Description on checkout page of the payment provider and never reaches the service.
+type Payment struct {
+	ID          uint       `json:"id"`
+	BookingID   uint       `json:"booking_id"`
+	LotID       uint       `json:"lot_id"`
+	PayerID     uint       `json:"payer_id"` // the renter
+	PayeeID     uint       `json:"payee_id"` // the landlord
+	Kind        string     `json:"kind" enums:"deposit,rent"`
+	Period      string     `json:"period,omitempty"` // month of rent, YYYY-MM
+	Amount      int64      `json:"amount"`           // in kopecks
+	Refunded    int64      `json:"refunded"`         // in kopecks, including refunds in progress
+	Currency    string     `json:"currency"`
+	Status      string     `json:"status" enums:"pending,succeeded,failed"`
+	Provider    string     `json:"provider"`
+	ProviderID  string     `json:"provider_id,omitempty"`  // ID of payment intent of the provider
+	CheckoutURL string     `json:"checkout_url,omitempty"` // the renter pays on this page
+	CreatedAt   time.Time  `json:"created_at"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+}
+
+// Refund model info
+// @Description refund of succeeded payment to the payer.
+type Refund struct {
+	ID         uint      `json:"id"`
+	PaymentID  uint      `json:"payment_id"`
+	Amount     int64     `json:"amount"` // in kopecks
+	Reason     string    `json:"reason,omitempty"`
+	Status     string    `json:"status" enums:"pending,succeeded,failed"`
+	ProviderID string    `json:"provider_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// LedgerEntry model info
+// @Description movement of money of the user. Every movement makes negative entry of the party paying
+// @Description and positive entry of the party receiving.
+type LedgerEntry struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	PaymentID uint      `json:"payment_id"`
+	RefundID  *uint     `json:"refund_id,omitempty"`
+	Type      string    `json:"type" enums:"payment,refund"`
+	Amount    int64     `json:"amount"` // in kopecks
+	Currency  string    `json:"currency"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreatePaymentDTO model info
+// @Description payment of accepted booking. Rent of a month is the part of total of the booking for nights of the stay
+// @Description in the month, deposit is equal to rent of the first month of the stay. Lots for sale aren't paid.
+type CreatePaymentDTO struct {
+	Kind   string `json:"kind" enums:"deposit,rent"` // required
+	Period string `json:"period"`                    // month of rent within the stay, YYYY-MM. required for rent
+}
+
+// CreateRefundDTO model info
+// @Description refund of succeeded payment.
+type CreateRefundDTO struct {
+	Amount int64  `json:"amount"` // required. in kopecks, refunds can't exceed amount of the payment
+	Reason string `json:"reason"` // max 500 characters
+}
+
+// SimulatePaymentDTO model info
+// @Description finishes checkout of pending payment with payment providers, which have no checkout pages.
+type SimulatePaymentDTO struct {
+	Outcome string `json:"outcome" enums:"succeeded,failed"` // required
+}
+
+// TransferLotDTO model info
+// @Description assigns lot of organization to another member of the organization. Lots are transferred
+// @Description by their agents and by owners and managers of the organization.
+type TransferLotDTO struct {
+	AgentID uint `json:"agent_id"` // required
+}
+
+// Organization model info
+// @Description real-estate agency, which owns lots managed by its members.
+type Organization struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Phone       string    `json:"phone"`
+	Email       string    `json:"email"`
+	Website     string    `json:"website"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OrganizationMember model info
+// @Description member of organization. Owners manage the organization and all its members, managers manage
+// @Description all lots of the organization and its agents, agents manage lots assigned to them.
+type OrganizationMember struct {
+	OrganizationID uint      `json:"organization_id"`
+	UserID         uint      `json:"user_id"`
+	Name           string    `json:"name"`
+	Role           string    `json:"role" enums:"owner,manager,agent"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+// OrganizationPage model info
+// @Description public page of organization.
+type OrganizationPage struct {
+	Organization
+	Members   []*OrganizationMember `json:"members"`
+	LotsCount int                   `json:"lots_count"`
+}
+
+// OrganizationDTO model info
+// @Description public data of organization.
+type OrganizationDTO struct {
+	Name        string `json:"name"`        // required. max 100 characters
+	Description string `json:"description"` // max 4000 characters
+	Phone       string `json:"phone"`       // max 20 characters
+	Email       string `json:"email"`
+	Website     string `json:"website"` // absolute URL
+}
+
+// SetMemberDTO model info
+// @Description adds the user to organization or changes role of the member.
+type SetMemberDTO struct {
+	Role string `json:"role" enums:"owner,manager,agent"` // required
+}
+
+// ImportRowError model info
+// @Description problem of the row, which is not imported. Rows are numbered as in the spreadsheet, the header is row 1.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// LotImport model info
+// @Description job importing lots from spreadsheet in background. Valid rows are imported, invalid ones
+// @Description are reported with errors. Dry run only validates rows. At most 1000 errors are kept.
+type LotImport struct {
+	ID             uint              `json:"id"`
+	UserID         uint              `json:"user_id"`
+	OrganizationID *uint             `json:"organization_id,omitempty"`
+	Format         string            `json:"format" enums:"csv,xlsx"`
+	DryRun         bool              `json:"dry_run"`
+	Mapping        map[string]string `json:"mapping"`
+	Status         string            `json:"status" enums:"queued,running,done,failed"`
+	TotalRows      int               `json:"total_rows"`
+	ValidRows      int               `json:"valid_rows"`
+	CreatedRows    int               `json:"created_rows"`
+	Errors         []ImportRowError  `json:"errors"`
+	Error          string            `json:"error,omitempty"` // why the file can't be imported, when the job failed
+	CreatedAt      time.Time         `json:"created_at"`
+	FinishedAt     *time.Time        `json:"finished_at,omitempty"`
+}
+
+// ImportProfile model info
+// @Description mapping of lot fields to spreadsheet columns saved for spreadsheets of the same layout.
+type ImportProfile struct {
+	ID        uint              `json:"id"`
+	OwnerID   uint              `json:"owner_id"`
+	Name      string            `json:"name"`
+	Mapping   map[string]string `json:"mapping"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// CreateImportProfileDTO model info
+// @Description mapping profile. Keys of mapping are fields of CreateLotDTO: type_of_estate, rooms, area, floor,
+// @Description max_floor, city, district, street, building and price; values are headers of columns.
+type CreateImportProfileDTO struct {
+	Name    string            `json:"name"`    // required. unique among profiles of the user
+	Mapping map[string]string `json:"mapping"` // required
+}
+
+// Feed model info
+// @Description feed of lots for aggregator portals. Lots are selected with filter in query syntax of lot search,
+// @Description e.g. city=Москва&rooms=gte:2&price=lte:60000.
+type Feed struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Format    string    `json:"format" enums:"yandex,avito,rss,atom"`
+	Filter    string    `json:"filter"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FeedDTO model info
+// @Description feed. Filters are estate_type, city, district, rooms, price, floor, created_at,
+// @Description available_from and available_between.
+type FeedDTO struct {
+	Name   string `json:"name"`   // required. unique
+	Format string `json:"format"` // required. yandex, avito, rss or atom
+	Filter string `json:"filter"`
+}
+
+// LotStats model info
+// @Description statistics of lots selected by filters. Percentiles are p10, p25, p50, p75 and p90.
+type LotStats struct {
+	Currency      string          `json:"currency"` // of prices, RUB unless currency is given
+	Count         int             `json:"count"`
+	Price         PriceStats      `json:"price"`
+	PricePerMeter PriceStats      `json:"price_per_m2"`
+	ByRooms       []RoomsStats    `json:"by_rooms"`
+	ByDistrict    []DistrictStats `json:"by_district"`
+	NewPerDay     []DailyListings `json:"new_per_day"`
+}
+
+// PriceStats model info
+// @Description distribution of prices
+type PriceStats struct {
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Avg         float64            `json:"avg"`
+	Median      float64            `json:"median"`
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+// RoomsStats model info
+// @Description lots with the number of rooms
+type RoomsStats struct {
+	Rooms            int     `json:"rooms"`
+	Count            int     `json:"count"`
+	AvgPrice         float64 `json:"avg_price"`
+	AvgPricePerMeter float64 `json:"avg_price_per_m2"`
+}
+
+// DistrictStats model info
+// @Description lots in the district
+type DistrictStats struct {
+	District         string  `json:"district"`
+	Count            int     `json:"count"`
+	AvgPrice         float64 `json:"avg_price"`
+	AvgPricePerMeter float64 `json:"avg_price_per_m2"`
+}
+
+// DailyListings model info
+// @Description number of lots created on the date
+type DailyListings struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// Counts model info
+// @Description numbers of views, additions to favorites, contact reveals and messages of renters.
+// @Description Views are counted once per day for each user or anonymous session.
+type Counts struct {
+	Views     int `json:"views"`
+	Favorites int `json:"favorites"`
+	Reveals   int `json:"reveals"`
+	Messages  int `json:"messages"`
+}
+
+// DailyCounts model info
+// @Description activity around the lot on the date
+type DailyCounts struct {
+	Date string `json:"date"`
+	Counts
+}
+
+// Comparison model info
+// @Description average activity around other lots of the same estate type and rooms in the district
+type Comparison struct {
+	City         string  `json:"city"`
+	District     string  `json:"district"`
+	SimilarLots  int     `json:"similar_lots"`
+	AvgViews     float64 `json:"avg_views"`
+	AvgFavorites float64 `json:"avg_favorites"`
+	AvgReveals   float64 `json:"avg_reveals"`
+	AvgMessages  float64 `json:"avg_messages"`
+}
+
+// LotAnalytics model info
+// @Description activity around the lot for the last days, from the oldest day
+type LotAnalytics struct {
+	LotID      uint          `json:"lot_id"`
+	Days       []DailyCounts `json:"days"`
+	Total      Counts        `json:"total"`
+	Comparison Comparison    `json:"comparison"`
+}
+
+// LotSummary model info
+// @Description total activity around the lot for the last days
+type LotSummary struct {
+	Lot   Lot    `json:"lot"`
+	Total Counts `json:"total"`
+}
+
+// Favorite model info
+// @Description lot saved by the user
+type Favorite struct {
+	UserID    uint      `json:"user_id"`
+	LotID     uint      `json:"lot_id"`
+	Lot       Lot       `json:"lot"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SimilarLot model info
+// @Description lot similar to another one. Score is from 0 to 1, the most similar lots have the highest score.
+type SimilarLot struct {
+	Lot   Lot     `json:"lot"`
+	Score float64 `json:"score"`
+}
+
+// Duplicate model info
+// @Description probable duplicate of the older lot. Status is one of pending, confirmed and dismissed.
+type Duplicate struct {
+	ID            uint       `json:"id"`
+	LotID         uint       `json:"lot_id"`
+	DuplicateOfID uint       `json:"duplicate_of_id"`
+	Score         float64    `json:"score"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ModeratedAt   *time.Time `json:"moderated_at,omitempty"`
+}
+
+// ModerateDuplicateDTO model info
+// @Description decision of the moderator about the duplicate.
+type ModerateDuplicateDTO struct {
+	Status string `json:"status"` // required. either "confirmed" or "dismissed"
+}
+
+// SetLotPhotosDTO model info
+// @Description photos of the lot compared with photos of other lots at the same address.
+type SetLotPhotosDTO struct {
+	Photos [][]byte `json:"photos" swaggertype:"array,string" format:"base64"` // JPEG or PNG images, up to 30
+}
+
+// City model info
+// @Description canonical city
+type City struct {
+	ID    uint              `json:"id"`
+	Name  string            `json:"name"`
+	Names map[string]string `json:"names,omitempty"` // by locale, in reference for admins only
+}
+
+// District model info
+// @Description canonical district of the city
+type District struct {
+	ID     uint              `json:"id"`
+	CityID uint              `json:"city_id"`
+	Name   string            `json:"name"`
+	Names  map[string]string `json:"names,omitempty"`
+}
+
+// EstateType model info
+// @Description type of estate of lots. Lots have its code in type_of_estate, name is localized.
+type EstateType struct {
+	ID       uint              `json:"id"`
+	Code     string            `json:"code"`
+	Name     string            `json:"name"`
+	Position int               `json:"position"`
+	Names    map[string]string `json:"names,omitempty"` // by locale, in reference for admins only
+}
+
+// Reference model info
+// @Description reference data for lists of forms and filters with names in the locale
+type Reference struct {
+	Locale      string        `json:"locale"`
+	EstateTypes []*EstateType `json:"estate_types"`
+	Cities      []*City       `json:"cities"`
+	Districts   []*District   `json:"districts"`
+}
+
+// EstateTypeDTO model info
+// @Description estate type. Code can't be changed, since lots refer to it. Names are by locale, e.g. en or en-US.
+type EstateTypeDTO struct {
+	Code     string            `json:"code"` // required on creation
+	Position int               `json:"position"`
+	Names    map[string]string `json:"names"`
+}
+
+// ReferenceNameDTO model info
+// @Description city or district. Lots of renamed city or district are renamed too.
+type ReferenceNameDTO struct {
+	Name  string            `json:"name"` // required
+	Names map[string]string `json:"names"`
+}
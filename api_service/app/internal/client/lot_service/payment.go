@@ -0,0 +1,140 @@
+package lot_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	paymentsResource        = "/payments"
+	paymentWebhooksResource = "/payment-webhooks"
+	ledgerResource          = "/ledger"
+	// idempotencyKeyHeader makes repeated refund requests return the same refund
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// webhookSkippedHeaders are not forwarded with webhooks, so callers can't act on behalf of users.
+var webhookSkippedHeaders = []string{
+	"Authorization", "Cookie", "Token", "Connection", "Content-Length", requesterIDHeader, clientIPHeader,
+}
+
+func (c *client) GetPayments(ctx context.Context, userID, bookingID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/payments", bookingsResource, bookingID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) CreatePayment(ctx context.Context, userID, bookingID uint, dto *CreatePaymentDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/payments", bookingsResource, bookingID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, userID, dto)
+}
+
+func (c *client) GetPayment(ctx context.Context, userID, id uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", paymentsResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+func (c *client) GetRefunds(ctx context.Context, userID, paymentID uint) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/refunds", paymentsResource, paymentID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+// CreateRefund refunds the payment, requests with the same idempotency key make a single refund.
+func (c *client) CreateRefund(ctx context.Context, userID, paymentID uint, idempotencyKey string,
+	dto *CreateRefundDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/refunds", paymentsResource, paymentID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	c.base.Logger.Debug("marshaling dto to bytes..")
+	dataBytes, err := json.Marshal(dto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dto due to err: %w", err)
+	}
+	header := http.Header{}
+	if idempotencyKey != "" {
+		header.Set(idempotencyKeyHeader, idempotencyKey)
+	}
+
+	body, _, err := c.sendWithHeader(ctx, http.MethodPost, uri, userID, header, bytes.NewBuffer(dataBytes))
+	return body, err
+}
+
+// GetReceipt returns receipt of the payment rendered in html or pdf format with headers of the response.
+func (c *client) GetReceipt(ctx context.Context, userID, paymentID uint, format string) ([]byte, http.Header, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/receipt", paymentsResource, paymentID), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if format != "" {
+		uri = fmt.Sprintf("%s?%s", uri, url.Values{"format": {format}}.Encode())
+	}
+
+	return c.sendRaw(ctx, http.MethodGet, uri, userID, "", nil)
+}
+
+func (c *client) SimulatePayment(ctx context.Context, userID, paymentID uint, dto *SimulatePaymentDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/simulation", paymentsResource, paymentID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, userID, dto)
+}
+
+func (c *client) GetLedger(ctx context.Context, userID uint, query url.Values) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(ledgerResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if len(query) > 0 {
+		uri = fmt.Sprintf("%s?%s", uri, query.Encode())
+	}
+
+	return c.send(ctx, http.MethodGet, uri, userID, nil)
+}
+
+// HandlePaymentWebhook forwards webhook of the provider with its headers, which carry signature of the provider.
+func (c *client) HandlePaymentWebhook(ctx context.Context, provider string, header http.Header, body []byte) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%s", paymentWebhooksResource, url.PathEscape(provider)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	forwarded := header.Clone()
+	for _, key := range webhookSkippedHeaders {
+		forwarded.Del(key)
+	}
+
+	_, _, err = c.sendWithHeader(ctx, http.MethodPost, uri, 0, forwarded, bytes.NewReader(body))
+	return err
+}
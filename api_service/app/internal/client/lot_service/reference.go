@@ -0,0 +1,135 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	referenceResource              = "/reference"
+	adminReferenceResource         = "/admin/reference"
+	adminEstateTypesResource       = "/admin/reference/estate-types"
+	adminReferenceCityResource     = "/admin/reference/cities"
+	adminReferenceDistrictResource = "/admin/reference/districts"
+)
+
+// GetReference returns reference data in the locale with headers of the response. Conditional headers
+// are passed to lot service, body is empty, if the reference is not modified.
+func (c *client) GetReference(ctx context.Context, locale string, conditions http.Header) ([]byte, http.Header,
+	error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(referenceResource, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if locale != "" {
+		uri = fmt.Sprintf("%s?%s", uri, url.Values{"locale": {locale}}.Encode())
+	}
+
+	return c.sendWithHeader(ctx, http.MethodGet, uri, 0, conditions, nil)
+}
+
+func (c *client) GetAllReference(ctx context.Context) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(adminReferenceResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodGet, uri, 0, nil)
+}
+
+func (c *client) CreateEstateType(ctx context.Context, dto *EstateTypeDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(adminEstateTypesResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, 0, dto)
+}
+
+func (c *client) UpdateEstateType(ctx context.Context, id uint, dto *EstateTypeDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", adminEstateTypesResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPut, uri, 0, dto)
+}
+
+func (c *client) DeleteEstateType(ctx context.Context, id uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", adminEstateTypesResource, id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, 0, nil)
+	return err
+}
+
+func (c *client) CreateCity(ctx context.Context, dto *ReferenceNameDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(adminReferenceCityResource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, 0, dto)
+}
+
+func (c *client) UpdateCity(ctx context.Context, id uint, dto *ReferenceNameDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", adminReferenceCityResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPut, uri, 0, dto)
+}
+
+func (c *client) DeleteCity(ctx context.Context, id uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", adminReferenceCityResource, id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, 0, nil)
+	return err
+}
+
+func (c *client) CreateDistrict(ctx context.Context, cityID uint, dto *ReferenceNameDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d/districts", adminReferenceCityResource, cityID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPost, uri, 0, dto)
+}
+
+func (c *client) UpdateDistrict(ctx context.Context, id uint, dto *ReferenceNameDTO) ([]byte, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", adminReferenceDistrictResource, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	return c.send(ctx, http.MethodPut, uri, 0, dto)
+}
+
+func (c *client) DeleteDistrict(ctx context.Context, id uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", adminReferenceDistrictResource, id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, 0, nil)
+	return err
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
 	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
 	"github.com/levelord1311/backendForSharedProject/api_service/pkg/rest"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -40,7 +42,135 @@ type LotService interface {
 	GetWithFilter(ctx context.Context, rQuery string) ([]byte, error)
 	Create(ctx context.Context, dto *CreateLotDTO) (uint, error)
 	Update(ctx context.Context, dto *UpdateLotDTO) error
+	// GetLotHistory returns versions of the lot, moderators get versions of any lot.
+	GetLotHistory(ctx context.Context, userID, lotID uint, moderator bool) ([]byte, error)
+	RevertLot(ctx context.Context, userID, lotID, number uint, moderator bool) ([]byte, error)
 	Delete(ctx context.Context, lotID, userID string) error
+	GetLotStats(ctx context.Context, rQuery string, conditions http.Header) ([]byte, http.Header, error)
+	GetSimilarLots(ctx context.Context, lotID uint, query url.Values) ([]byte, error)
+
+	SearchCities(ctx context.Context, query url.Values) ([]byte, error)
+	SearchDistricts(ctx context.Context, cityID uint, query url.Values) ([]byte, error)
+
+	GetReference(ctx context.Context, locale string, conditions http.Header) ([]byte, http.Header, error)
+	GetAllReference(ctx context.Context) ([]byte, error)
+	CreateEstateType(ctx context.Context, dto *EstateTypeDTO) ([]byte, error)
+	UpdateEstateType(ctx context.Context, id uint, dto *EstateTypeDTO) ([]byte, error)
+	DeleteEstateType(ctx context.Context, id uint) error
+	CreateCity(ctx context.Context, dto *ReferenceNameDTO) ([]byte, error)
+	UpdateCity(ctx context.Context, id uint, dto *ReferenceNameDTO) ([]byte, error)
+	DeleteCity(ctx context.Context, id uint) error
+	CreateDistrict(ctx context.Context, cityID uint, dto *ReferenceNameDTO) ([]byte, error)
+	UpdateDistrict(ctx context.Context, id uint, dto *ReferenceNameDTO) ([]byte, error)
+	DeleteDistrict(ctx context.Context, id uint) error
+
+	GetDuplicates(ctx context.Context, query url.Values) ([]byte, error)
+	ModerateDuplicate(ctx context.Context, id uint, dto *ModerateDuplicateDTO) ([]byte, error)
+	SetLotPhotos(ctx context.Context, userID, lotID uint, dto *SetLotPhotosDTO) error
+
+	CreateBooking(ctx context.Context, userID uint, dto *CreateBookingDTO) ([]byte, error)
+	GetBookings(ctx context.Context, userID uint, party string) ([]byte, error)
+	GetBooking(ctx context.Context, userID, id uint) ([]byte, error)
+	UpdateBooking(ctx context.Context, userID, id uint, dto *UpdateBookingDTO) ([]byte, error)
+
+	GetRental(ctx context.Context, lotID uint) ([]byte, error)
+	SetRental(ctx context.Context, userID, lotID uint, dto *SetRentalDTO) ([]byte, error)
+	QuoteStay(ctx context.Context, lotID uint, query url.Values) ([]byte, error)
+
+	GetCalendar(ctx context.Context, lotID uint) ([]byte, error)
+	ExportCalendar(ctx context.Context, lotID uint) ([]byte, error)
+	CreateCalendarBlock(ctx context.Context, userID, lotID uint, dto *CreateCalendarBlockDTO) ([]byte, error)
+	DeleteCalendarBlock(ctx context.Context, userID, lotID, blockID uint) error
+	GetCalendarImport(ctx context.Context, userID, lotID uint) ([]byte, error)
+	SetCalendarImport(ctx context.Context, userID, lotID uint, dto *SetCalendarImportDTO) ([]byte, error)
+
+	GetConversations(ctx context.Context, userID uint) ([]byte, error)
+	StartConversation(ctx context.Context, userID uint, dto *StartConversationDTO) ([]byte, error)
+	GetConversation(ctx context.Context, userID, id uint) ([]byte, error)
+	GetMessages(ctx context.Context, userID, conversationID uint, query url.Values) ([]byte, error)
+	SendMessage(ctx context.Context, userID, conversationID uint, dto *SendMessageDTO) ([]byte, error)
+	MarkRead(ctx context.Context, userID, conversationID uint) ([]byte, error)
+	GetUnread(ctx context.Context, userID uint) ([]byte, error)
+	UploadAttachment(ctx context.Context, userID uint, name, contentType string, data io.Reader) ([]byte, error)
+	GetAttachment(ctx context.Context, userID, id uint) ([]byte, http.Header, error)
+	GetBlockedUsers(ctx context.Context, userID uint) ([]byte, error)
+	BlockUser(ctx context.Context, userID, blockedID uint) error
+	UnblockUser(ctx context.Context, userID, blockedID uint) error
+
+	GetEvents(ctx context.Context, afterID, userID uint, limit int) ([]*Event, error)
+	GetLastEventID(ctx context.Context) (uint, error)
+
+	GetReviews(ctx context.Context, query url.Values) ([]byte, error)
+	GetReview(ctx context.Context, id uint) ([]byte, error)
+	CreateReview(ctx context.Context, userID uint, dto *CreateReviewDTO) ([]byte, error)
+	ReplyToReview(ctx context.Context, userID, id uint, dto *ReplyDTO) ([]byte, error)
+	ReportReview(ctx context.Context, userID, id uint, dto *ReportDTO) error
+	GetLandlordRating(ctx context.Context, landlordID uint) (*Rating, error)
+	GetReportedReviews(ctx context.Context) ([]byte, error)
+	ModerateReview(ctx context.Context, id uint, dto *ModerateReviewDTO) ([]byte, error)
+
+	GetLotViewingSlots(ctx context.Context, lotID uint) ([]byte, error)
+	CreateViewingSlot(ctx context.Context, userID, lotID uint, dto *CreateViewingSlotDTO) ([]byte, error)
+	GetViewings(ctx context.Context, userID uint, party string) ([]byte, error)
+	GetViewing(ctx context.Context, userID, slotID uint) ([]byte, error)
+	GetViewingInvite(ctx context.Context, userID, slotID uint) ([]byte, error)
+	UpdateViewingSlot(ctx context.Context, userID, slotID uint, dto *UpdateViewingSlotDTO) ([]byte, error)
+	DeleteViewingSlot(ctx context.Context, userID, slotID uint) error
+	BookViewing(ctx context.Context, userID, slotID uint, dto *BookViewingDTO) ([]byte, error)
+	CancelViewing(ctx context.Context, userID, slotID uint) ([]byte, error)
+
+	GetAgreementTemplates(ctx context.Context, userID uint) ([]byte, error)
+	CreateAgreementTemplate(ctx context.Context, userID uint, dto *CreateAgreementTemplateDTO) ([]byte, error)
+	GetAgreementTemplate(ctx context.Context, userID, id uint) ([]byte, error)
+	DeleteAgreementTemplate(ctx context.Context, userID, id uint) error
+	GetAgreements(ctx context.Context, userID, bookingID uint) ([]byte, error)
+	CreateAgreement(ctx context.Context, userID, bookingID uint, dto *CreateAgreementDTO) ([]byte, error)
+	GetAgreement(ctx context.Context, userID, id uint) ([]byte, error)
+	GetAgreementDocument(ctx context.Context, userID, id uint, format string) ([]byte, http.Header, error)
+	AcceptAgreement(ctx context.Context, userID, id uint, ip string, dto *AcceptAgreementDTO) ([]byte, error)
+
+	GetPayments(ctx context.Context, userID, bookingID uint) ([]byte, error)
+	CreatePayment(ctx context.Context, userID, bookingID uint, dto *CreatePaymentDTO) ([]byte, error)
+	GetPayment(ctx context.Context, userID, id uint) ([]byte, error)
+	GetRefunds(ctx context.Context, userID, paymentID uint) ([]byte, error)
+	CreateRefund(ctx context.Context, userID, paymentID uint, idempotencyKey string, dto *CreateRefundDTO) ([]byte, error)
+	GetReceipt(ctx context.Context, userID, paymentID uint, format string) ([]byte, http.Header, error)
+	SimulatePayment(ctx context.Context, userID, paymentID uint, dto *SimulatePaymentDTO) ([]byte, error)
+	GetLedger(ctx context.Context, userID uint, query url.Values) ([]byte, error)
+	HandlePaymentWebhook(ctx context.Context, provider string, header http.Header, body []byte) error
+
+	GetOrganizations(ctx context.Context, userID uint) ([]byte, error)
+	CreateOrganization(ctx context.Context, userID uint, dto *OrganizationDTO) ([]byte, error)
+	GetOrganization(ctx context.Context, id uint) ([]byte, error)
+	UpdateOrganization(ctx context.Context, userID, id uint, dto *OrganizationDTO) ([]byte, error)
+	GetOrganizationLots(ctx context.Context, id uint) ([]byte, error)
+	SetOrganizationMember(ctx context.Context, userID, id, memberID uint, dto *SetMemberDTO) ([]byte, error)
+	RemoveOrganizationMember(ctx context.Context, userID, id, memberID uint) error
+	TransferLot(ctx context.Context, userID, lotID uint, dto *TransferLotDTO) ([]byte, error)
+
+	GetLotImports(ctx context.Context, userID uint) ([]byte, error)
+	CreateLotImport(ctx context.Context, userID uint, query url.Values, contentType string, data io.Reader) ([]byte, error)
+	GetLotImport(ctx context.Context, userID, id uint) ([]byte, error)
+	GetLotImportProfiles(ctx context.Context, userID uint) ([]byte, error)
+	CreateLotImportProfile(ctx context.Context, userID uint, dto *CreateImportProfileDTO) ([]byte, error)
+	DeleteLotImportProfile(ctx context.Context, userID, id uint) error
+
+	GetFeeds(ctx context.Context) ([]byte, error)
+	CreateFeed(ctx context.Context, dto *FeedDTO) ([]byte, error)
+	UpdateFeed(ctx context.Context, id uint, dto *FeedDTO) ([]byte, error)
+	DeleteFeed(ctx context.Context, id uint) error
+	GetFeedDocument(ctx context.Context, id uint, conditions http.Header) ([]byte, http.Header, error)
+	GetCityFeedDocument(ctx context.Context, city, format string, conditions http.Header) ([]byte, http.Header, error)
+
+	ViewLot(ctx context.Context, lotID, userID uint, sessionID string) ([]byte, error)
+	GetLotAnalytics(ctx context.Context, userID, lotID uint, query url.Values) ([]byte, error)
+	GetAnalyticsDashboard(ctx context.Context, userID uint, query url.Values) ([]byte, error)
+	// RecordReveal reports contact of the lot owner revealed to the user, so it's counted in analytics.
+	RecordReveal(ctx context.Context, userID, lotID uint) error
+
+	GetFavorites(ctx context.Context, userID uint) ([]byte, error)
+	AddFavorite(ctx context.Context, userID, lotID uint) ([]byte, error)
+	RemoveFavorite(ctx context.Context, userID, lotID uint) error
 }
 
 func (c *client) GetByUserID(ctx context.Context, id string) ([]byte, error) {
@@ -210,7 +340,7 @@ func (c *client) Create(ctx context.Context, dto *CreateLotDTO) (uint, error) {
 
 func (c *client) Update(ctx context.Context, dto *UpdateLotDTO) error {
 	c.base.Logger.Debug("building url with resource and filter..")
-	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", c.Resource, dto.ID), nil)
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/lot/%d", c.Resource, dto.ID), nil)
 	if err != nil {
 		return fmt.Errorf("failed to build URL. error: %w", err)
 	}
@@ -0,0 +1,24 @@
+package lot_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const statsResource = "/stats"
+
+// GetLotStats returns statistics of lots selected by filters of the raw query with headers of the response.
+// Conditional headers are passed to lot service, body is empty, if the statistics are not modified.
+func (c *client) GetLotStats(ctx context.Context, rQuery string, conditions http.Header) ([]byte, http.Header, error) {
+	c.base.Logger.Debug("building url with resource and raw query..")
+	uri, err := c.base.BuildURL(c.Resource+statsResource, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+	if rQuery != "" {
+		uri = fmt.Sprintf("%s?%s", uri, rQuery)
+	}
+
+	return c.sendWithHeader(ctx, http.MethodGet, uri, 0, conditions, nil)
+}
@@ -4,16 +4,32 @@ import (
 	"time"
 )
 
+const RoleAdmin = "admin"
+
+// User is the private view of a user received from user_service.
+// It is shown only to the user themself and must not be returned from public endpoints.
 type User struct {
-	ID                uint      `json:"id"`
-	Username          string    `json:"username"`
-	Email             string    `json:"email"`
-	Password          string    `json:"password,omitempty"`
-	EncryptedPassword string    `json:"-"`
-	GivenName         string    `json:"given_name"`
-	FamilyName        string    `json:"family_name"`
-	CreatedAt         time.Time `json:"created_at"`
-	RedactedAt        time.Time `json:"redacted_at"`
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	GivenName     string    `json:"given_name"`
+	FamilyName    string    `json:"family_name"`
+	Phone         string    `json:"phone,omitempty"`
+	PhoneVerified bool      `json:"phone_verified"`
+	TwoFactor     bool      `json:"two_factor_enabled"`
+	Role          string    `json:"role"`
+	CreatedAt     time.Time `json:"created_at"`
+	RedactedAt    time.Time `json:"redacted_at"`
+}
+
+// PublicUser model info
+// @Description user information visible to anyone.
+type PublicUser struct {
+	ID         uint      `json:"id"`
+	Username   string    `json:"username"`
+	GivenName  string    `json:"given_name"`
+	FamilyName string    `json:"family_name"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 // CreateUserDTO model info
@@ -36,4 +52,55 @@ type UpdateUserDTO struct {
 type SignInUserDTO struct {
 	Login    string `json:"login"` // user's email or username
 	Password string `json:"password"`
+	IP       string `json:"-"`
+}
+
+// SetPhoneDTO model info
+// @Description new phone number of the user. Verification code is sent to it via SMS.
+type SetPhoneDTO struct {
+	Phone string `json:"phone" example:"+79001234567"` // E.164 format
+}
+
+// VerifyPhoneDTO model info
+// @Description code received via SMS.
+type VerifyPhoneDTO struct {
+	Code string `json:"code" example:"123456"`
+}
+
+type RevealContactDTO struct {
+	ViewerID uint `json:"viewer_id"`
+	OwnerID  uint `json:"owner_id"`
+	LotID    uint `json:"lot_id"`
+}
+
+// Contact model info
+// @Description contact data of the lot owner.
+type Contact struct {
+	UserID uint   `json:"user_id"`
+	Phone  string `json:"phone"`
+}
+
+// TOTPEnrollment model info
+// @Description secret for authenticator app. Shown only once.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/issuer:username?secret=SECRET&issuer=issuer"`
+	QRCode []byte `json:"qr_code"` // base64 encoded PNG image of otpauth_uri
+}
+
+// TOTPCodeDTO model info
+// @Description code from authenticator app.
+type TOTPCodeDTO struct {
+	Code string `json:"code" example:"123456"`
+}
+
+type VerifyTOTPDTO struct {
+	UserID uint   `json:"user_id"`
+	Code   string `json:"code"`
+}
+
+// RecoveryCodes model info
+// @Description one-time codes to sign in without authenticator app. Shown only once.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
 	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
 	"github.com/levelord1311/backendForSharedProject/api_service/pkg/rest"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -36,12 +37,28 @@ func NewService(baseURL string, resource string, logger logging.Logger) *client
 	return &c
 }
 
+const (
+	requesterIDHeader   = "X-Requester-ID"
+	requesterRoleHeader = "X-Requester-Role"
+	clientIPHeader      = "X-Real-IP"
+)
+
 type UserService interface {
 	SignIn(ctx context.Context, dto *SignInUserDTO) (*User, error)
-	GetByID(ctx context.Context, id uint) (*User, error)
+	GetProfile(ctx context.Context, id uint) (*User, error)
+	GetPublic(ctx context.Context, id uint) (*PublicUser, error)
+	GetAsAdmin(ctx context.Context, id uint) (*User, error)
 	Create(ctx context.Context, dto *CreateUserDTO) (*User, error)
 	Update(ctx context.Context, id uint, dto *UpdateUserDTO) error
 	Delete(ctx context.Context, id uint) error
+	SetPhone(ctx context.Context, id uint, dto *SetPhoneDTO) error
+	VerifyPhone(ctx context.Context, id uint, dto *VerifyPhoneDTO) error
+	RevealContact(ctx context.Context, dto *RevealContactDTO) (*Contact, error)
+	EnrollTOTP(ctx context.Context, id uint) (*TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, id uint, dto *TOTPCodeDTO) (*RecoveryCodes, error)
+	VerifyTOTP(ctx context.Context, dto *VerifyTOTPDTO) error
+	ResetTOTP(ctx context.Context, id uint) error
+	Unlock(ctx context.Context, id uint) error
 }
 
 func (c *client) SignIn(ctx context.Context, dto *SignInUserDTO) (*User, error) {
@@ -65,6 +82,9 @@ func (c *client) SignIn(ctx context.Context, dto *SignInUserDTO) (*User, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new request due to error: %w", err)
 	}
+	if dto.IP != "" {
+		req.Header.Set(clientIPHeader, dto.IP)
+	}
 
 	c.base.Logger.Debug("sending created request...")
 	// TODO implement circuit breaker pattern (i. e. hystrix lib)
@@ -76,6 +96,10 @@ func (c *client) SignIn(ctx context.Context, dto *SignInUserDTO) (*User, error)
 		return nil, fmt.Errorf("failed to send request due to error: %w", err)
 	}
 
+	if response.StatusCode() == http.StatusTooManyRequests {
+		return nil, apperror.TooManyRequestsError("too many failed sign in attempts, try again later",
+			response.Header().Get("Retry-After"))
+	}
 	if !response.IsOk {
 		return nil, apperror.APIError(response.Error.ErrorCode,
 			response.Error.Message,
@@ -92,19 +116,53 @@ func (c *client) SignIn(ctx context.Context, dto *SignInUserDTO) (*User, error)
 	return u, nil
 }
 
-func (c *client) GetByID(ctx context.Context, id uint) (*User, error) {
+// GetProfile returns private view of the user. Must be called only on behalf of the user themself.
+func (c *client) GetProfile(ctx context.Context, id uint) (*User, error) {
+	u := &User{}
+	if err := c.getByID(ctx, id, strconv.Itoa(int(id)), u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// GetPublic returns view of the user which is safe to show to anyone.
+func (c *client) GetPublic(ctx context.Context, id uint) (*PublicUser, error) {
+	u := &PublicUser{}
+	if err := c.getByID(ctx, id, "", u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// GetAsAdmin returns view of the user for admins. Role of the requester is taken from JWT claims in the context,
+// so user_service returns public view to anyone else.
+func (c *client) GetAsAdmin(ctx context.Context, id uint) (*User, error) {
+	u := &User{}
+	if err := c.getByID(ctx, id, "", u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (c *client) getByID(ctx context.Context, id uint, requesterID string, u any) error {
 
 	c.base.Logger.Debug("building url with resource and filter...")
 	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", c.resource, id), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build URL due to error: %w", err)
+		return fmt.Errorf("failed to build URL due to error: %w", err)
 	}
 	c.base.Logger.Tracef("url: %s", uri)
 
 	c.base.Logger.Debug("creating new request...")
 	req, err := http.NewRequest(http.MethodGet, uri, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new request due to error: %w", err)
+		return fmt.Errorf("failed to create new request due to error: %w", err)
+	}
+	if requesterID != "" {
+		req.Header.Set(requesterIDHeader, requesterID)
+	}
+	if role, ok := ctx.Value("role").(string); ok && role != "" {
+		req.Header.Set(requesterRoleHeader, role)
 	}
 
 	c.base.Logger.Debug("sending created request...")
@@ -114,25 +172,24 @@ func (c *client) GetByID(ctx context.Context, id uint) (*User, error) {
 	req = req.WithContext(reqCtx)
 	response, err := c.base.SendRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request due to error: %w", err)
+		return fmt.Errorf("failed to send request due to error: %w", err)
 	}
 
 	if !response.IsOk {
-		return nil, apperror.APIError(response.Error.ErrorCode,
+		return apperror.APIError(response.Error.ErrorCode,
 			response.Error.Message,
 			response.Error.DeveloperMessage)
 	}
 
 	defer response.Body().Close()
 
-	u := &User{}
 	c.base.Logger.Debug("response received, decoding body")
 	err = json.NewDecoder(response.Body()).Decode(u)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode body due to error: %w", err)
+		return fmt.Errorf("failed to decode body due to error: %w", err)
 	}
 
-	return u, nil
+	return nil
 }
 
 func (c *client) Create(ctx context.Context, dto *CreateUserDTO) (*User, error) {
@@ -186,7 +243,7 @@ func (c *client) Create(ctx context.Context, dto *CreateUserDTO) (*User, error)
 		return nil, err
 	}
 
-	u, err := c.GetByID(ctx, uint(userID))
+	u, err := c.GetProfile(ctx, uint(userID))
 	if err != nil {
 		return nil, err
 	}
@@ -257,3 +314,165 @@ func (c *client) Delete(ctx context.Context, id uint) error {
 
 	return nil
 }
+
+func (c *client) SetPhone(ctx context.Context, id uint, dto *SetPhoneDTO) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", c.resource, id), nil, "/phone")
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodPut, uri, dto)
+	return err
+}
+
+func (c *client) VerifyPhone(ctx context.Context, id uint, dto *VerifyPhoneDTO) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", c.resource, id), nil, "/phone/verification")
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodPut, uri, dto)
+	return err
+}
+
+func (c *client) RevealContact(ctx context.Context, dto *RevealContactDTO) (*Contact, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL("/contacts", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	body, err := c.send(ctx, http.MethodPost, uri, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	c.base.Logger.Debug("response received, decoding body")
+	contact := &Contact{}
+	if err = json.Unmarshal(body, contact); err != nil {
+		return nil, fmt.Errorf("failed to decode body due to error: %w", err)
+	}
+	return contact, nil
+}
+
+func (c *client) EnrollTOTP(ctx context.Context, id uint) (*TOTPEnrollment, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", c.resource, id), nil, "/totp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	body, err := c.send(ctx, http.MethodPut, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.base.Logger.Debug("response received, decoding body")
+	enrollment := &TOTPEnrollment{}
+	if err = json.Unmarshal(body, enrollment); err != nil {
+		return nil, fmt.Errorf("failed to decode body due to error: %w", err)
+	}
+	return enrollment, nil
+}
+
+func (c *client) ConfirmTOTP(ctx context.Context, id uint, dto *TOTPCodeDTO) (*RecoveryCodes, error) {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", c.resource, id), nil, "/totp/confirmation")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	body, err := c.send(ctx, http.MethodPut, uri, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	c.base.Logger.Debug("response received, decoding body")
+	codes := &RecoveryCodes{}
+	if err = json.Unmarshal(body, codes); err != nil {
+		return nil, fmt.Errorf("failed to decode body due to error: %w", err)
+	}
+	return codes, nil
+}
+
+func (c *client) VerifyTOTP(ctx context.Context, dto *VerifyTOTPDTO) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(c.resource, nil, "/auth/totp")
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodPost, uri, dto)
+	return err
+}
+
+func (c *client) ResetTOTP(ctx context.Context, id uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", c.resource, id), nil, "/totp")
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, nil)
+	return err
+}
+
+func (c *client) Unlock(ctx context.Context, id uint) error {
+	c.base.Logger.Debug("building url with resource and filter..")
+	uri, err := c.base.BuildURL(fmt.Sprintf("%s/%d", c.resource, id), nil, "/lock")
+	if err != nil {
+		return fmt.Errorf("failed to build URL. error: %w", err)
+	}
+
+	_, err = c.send(ctx, http.MethodDelete, uri, nil)
+	return err
+}
+
+// send marshals dto, if it's not nil, sends it to uri and returns body of the response.
+func (c *client) send(ctx context.Context, method, uri string, dto any) ([]byte, error) {
+	c.base.Logger.Tracef("url: %s", uri)
+
+	var reqBody io.Reader
+	if dto != nil {
+		c.base.Logger.Debug("marshaling dto to bytes..")
+		dataBytes, err := json.Marshal(dto)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal dto due to err: %w", err)
+		}
+		reqBody = bytes.NewBuffer(dataBytes)
+	}
+
+	c.base.Logger.Debug("creating new request..")
+	req, err := http.NewRequest(method, uri, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new request due to error: %w", err)
+	}
+
+	c.base.Logger.Debug("sending created request..")
+	reqCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	req = req.WithContext(reqCtx)
+	response, err := c.base.SendRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request due to error: %w", err)
+	}
+
+	if response.StatusCode() == http.StatusTooManyRequests {
+		return nil, apperror.TooManyRequestsError("too many requests, try again later",
+			response.Header().Get("Retry-After"))
+	}
+	if !response.IsOk {
+		return nil, apperror.APIError(response.Error.ErrorCode,
+			response.Error.Message,
+			response.Error.DeveloperMessage)
+	}
+
+	c.base.Logger.Debug("reading response body..")
+	body, err := response.ReadBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body due to error: %w", err)
+	}
+	return body, nil
+}
@@ -6,6 +6,7 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"sync"
+	"time"
 )
 
 type Config struct {
@@ -28,6 +29,13 @@ type Config struct {
 	LotService struct {
 		URL string `yaml:"url" env-required:"true"`
 	} `yaml:"lot_service" env-required:"true"`
+	Events struct {
+		PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+		// Grace is how long events are held back, so events of transactions committed later are published in order
+		Grace     time.Duration `yaml:"grace" env-default:"2s"`
+		BatchSize int           `yaml:"batch_size" env-default:"100"`
+		Heartbeat time.Duration `yaml:"heartbeat" env-default:"15s"`
+	} `yaml:"events"`
 }
 
 var instance *Config
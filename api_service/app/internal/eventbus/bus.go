@@ -0,0 +1,93 @@
+// Package eventbus fans out events of lot_service to streams of connected users. Every api_service
+// instance polls lot_service on its own, so users can be connected to any of them.
+package eventbus
+
+import (
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"sync"
+)
+
+// subscriberBuffer is the number of events waiting for slow subscriber before it is dropped.
+const subscriberBuffer = 64
+
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[chan *lot_service.Event]struct{}
+	lastID      uint // of the last published event
+	closed      bool
+}
+
+func New() *Bus {
+	return &Bus{
+		subscribers: make(map[uint]map[chan *lot_service.Event]struct{}),
+	}
+}
+
+// Subscribe returns channel of events of the user, ID of the last published event and function to unsubscribe.
+// Events after the returned ID are delivered to the channel, earlier ones must be read from lot_service.
+// Channel is closed, when the subscriber can't keep up with events, it should reconnect and resume after
+// the last event.
+func (b *Bus) Subscribe(userID uint) (<-chan *lot_service.Event, uint, func()) {
+	ch := make(chan *lot_service.Event, subscriberBuffer)
+
+	b.mu.Lock()
+	lastID := b.lastID
+	if b.closed {
+		b.mu.Unlock()
+		close(ch)
+		return ch, lastID, func() {}
+	}
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan *lot_service.Event]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, lastID, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(userID, ch)
+	}
+}
+
+// Publish delivers the event to subscribers of its user. Events must be published in order of their IDs.
+func (b *Bus) Publish(e *lot_service.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID = e.ID
+
+	for ch := range b.subscribers[e.UserID] {
+		select {
+		case ch <- e:
+		default:
+			b.remove(e.UserID, ch)
+		}
+	}
+}
+
+// Close closes channels of all subscribers, so streams end and clients reconnect to other instances.
+// Channels of later subscribers are closed at once.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for userID, channels := range b.subscribers {
+		for ch := range channels {
+			b.remove(userID, ch)
+		}
+	}
+}
+
+// remove must be called with the lock held.
+func (b *Bus) remove(userID uint, ch chan *lot_service.Event) {
+	if _, ok := b.subscribers[userID][ch]; !ok {
+		return
+	}
+	delete(b.subscribers[userID], ch)
+	if len(b.subscribers[userID]) == 0 {
+		delete(b.subscribers, userID)
+	}
+	close(ch)
+}
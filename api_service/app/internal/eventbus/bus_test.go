@@ -0,0 +1,146 @@
+package eventbus
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBus(t *testing.T) {
+	b := New()
+
+	first, _, unsubscribe := b.Subscribe(1)
+	second, _, _ := b.Subscribe(1)
+	other, _, _ := b.Subscribe(2)
+
+	b.Publish(&lot_service.Event{ID: 1, UserID: 1})
+	if (<-first).ID != 1 || (<-second).ID != 1 {
+		t.Fatal("event must be delivered to all subscribers of the user")
+	}
+	if len(other) != 0 {
+		t.Fatal("event must not be delivered to other users")
+	}
+
+	unsubscribe()
+	if _, ok := <-first; ok {
+		t.Fatal("channel must be closed after unsubscribe")
+	}
+	unsubscribe()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		b.Publish(&lot_service.Event{ID: uint(i + 2), UserID: 1})
+	}
+	for range second {
+	}
+	if len(b.subscribers[1]) != 0 {
+		t.Fatal("slow subscriber must be dropped")
+	}
+	if _, lastID, _ := b.Subscribe(1); lastID != subscriberBuffer+2 {
+		t.Fatalf("expected id of the last published event, got %d", lastID)
+	}
+}
+
+type source struct {
+	mu     sync.Mutex
+	events []*lot_service.Event
+}
+
+func (s *source) GetEvents(_ context.Context, afterID, _ uint, limit int) ([]*lot_service.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]*lot_service.Event, 0)
+	for _, e := range s.events {
+		if e.ID > afterID && len(res) < limit {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (s *source) GetLastEventID(_ context.Context) (uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events[len(s.events)-1].ID, nil
+}
+
+func (s *source) add(e *lot_service.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	sort.Slice(s.events, func(i, j int) bool { return s.events[i].ID < s.events[j].ID })
+}
+
+func TestRun(t *testing.T) {
+	src := &source{events: []*lot_service.Event{{ID: 1, UserID: 1}}}
+	b := New()
+	ch, _, _ := b.Subscribe(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx, src, 10*time.Millisecond, 0, 2, logging.GetLogger())
+
+	time.Sleep(30 * time.Millisecond)
+	for id := uint(2); id <= 4; id++ {
+		src.add(&lot_service.Event{ID: id, UserID: 1})
+	}
+
+	for id := uint(2); id <= 4; id++ {
+		select {
+		case e := <-ch:
+			if e.ID != id {
+				t.Fatalf("expected event %d, got %d: events published before start must be skipped", id, e.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d was not delivered", id)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("no more events expected")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream must be closed when the bus stops")
+	}
+	if late, _, _ := b.Subscribe(1); !isClosed(late) {
+		t.Fatal("subscription to stopped bus must be closed")
+	}
+}
+
+func TestRunLateCommit(t *testing.T) {
+	src := &source{events: []*lot_service.Event{{ID: 1, UserID: 1}}}
+	b := New()
+	ch, _, _ := b.Subscribe(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx, src, 10*time.Millisecond, 100*time.Millisecond, 2, logging.GetLogger())
+
+	time.Sleep(30 * time.Millisecond)
+	src.add(&lot_service.Event{ID: 3, UserID: 1})
+	time.Sleep(30 * time.Millisecond)
+	// transaction of event 2 is committed after the one of event 3
+	src.add(&lot_service.Event{ID: 2, UserID: 1})
+
+	for id := uint(2); id <= 3; id++ {
+		select {
+		case e := <-ch:
+			if e.ID != id {
+				t.Fatalf("expected event %d, got %d: events must be published in order of ids", id, e.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d was not delivered", id)
+		}
+	}
+}
+
+func isClosed(ch <-chan *lot_service.Event) bool {
+	_, ok := <-ch
+	return !ok
+}
@@ -0,0 +1,78 @@
+package eventbus
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"time"
+)
+
+// Source is where events are read from.
+type Source interface {
+	GetEvents(ctx context.Context, afterID, userID uint, limit int) ([]*lot_service.Event, error)
+	GetLastEventID(ctx context.Context) (uint, error)
+}
+
+// Run polls new events from the source every interval and publishes them to the bus until ctx is done,
+// then the bus is closed. Only events published after the start are delivered, older ones are read by streams
+// resuming after them.
+//
+// IDs of events are taken in order of inserts, but events become visible, when their transactions commit,
+// so event with lower ID may appear after the later ones. Events are published in order of their IDs,
+// when they have been polled for the grace period, and events are polled again from the last published one,
+// so events appearing late, but within the grace period, are published before the later ones.
+func (b *Bus) Run(ctx context.Context, source Source, interval, grace time.Duration, batch int,
+	logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var cursor uint
+	started := false
+	// polledAt is the time of the first poll of events, which are not published yet
+	polledAt := make(map[uint]time.Time)
+	for {
+		if !started {
+			last, err := source.GetLastEventID(ctx)
+			if err != nil {
+				logger.Errorf("failed to get last event id. error: %v", err)
+			} else {
+				cursor, started = last, true
+			}
+		}
+
+		afterID, held := cursor, false
+		for started {
+			events, err := source.GetEvents(ctx, afterID, 0, batch)
+			if err != nil {
+				logger.Errorf("failed to poll events. error: %v", err)
+				break
+			}
+			now := time.Now()
+			for _, e := range events {
+				first, ok := polledAt[e.ID]
+				if !ok {
+					first = now
+					polledAt[e.ID] = now
+				}
+				if held || now.Sub(first) < grace {
+					held = true
+					continue
+				}
+				b.Publish(e)
+				delete(polledAt, e.ID)
+				cursor = e.ID
+			}
+			if len(events) < batch {
+				break
+			}
+			afterID = events[len(events)-1].ID
+		}
+
+		select {
+		case <-ctx.Done():
+			b.Close()
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package addresses
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	citiesURL        = "/api/cities"
+	cityDistrictsURL = "/api/cities/:id/districts"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, citiesURL, apperror.Middleware(h.GetCities))
+	router.HandlerFunc(http.MethodGet, cityDistrictsURL, apperror.Middleware(h.GetDistricts))
+}
+
+// GetCities godoc
+//
+//	@Summary		Autocomplete cities
+//	@Description	get canonical cities, names of which start with q, by name. Spellings like "г. Москва"
+//	@Description	and "москва" are the same city. Lots are filtered by ID of the city with city_id.
+//	@Tags			addresses
+//	@Produce		json
+//	@Param			q		query		string	false	"beginning of the name"
+//	@Param			limit	query		int		false	"number of cities, 10 by default, 50 at most"
+//	@Success		200		{array}		lot_service.City
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/cities [get]
+func (h *Handler) GetCities(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	cities, err := h.LotService.SearchCities(r.Context(), searchQuery(r))
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(cities)
+	return nil
+}
+
+// GetDistricts godoc
+//
+//	@Summary		Autocomplete districts
+//	@Description	get canonical districts of the city, names of which start with q, by name.
+//	@Description	Lots are filtered by ID of the district with district_id.
+//	@Tags			addresses
+//	@Produce		json
+//	@Param			id		path		int		true	"City ID"
+//	@Param			q		query		string	false	"beginning of the name"
+//	@Param			limit	query		int		false	"number of districts, 10 by default, 50 at most"
+//	@Success		200		{array}		lot_service.District
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/cities/{id}/districts [get]
+func (h *Handler) GetDistricts(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	cityID, err := strconv.ParseUint(params.ByName("id"), 10, 32)
+	if err != nil || cityID == 0 {
+		return apperror.BadRequestError("id must be an unsigned integer", "")
+	}
+
+	districts, err := h.LotService.SearchDistricts(r.Context(), uint(cityID), searchQuery(r))
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(districts)
+	return nil
+}
+
+func searchQuery(r *http.Request) url.Values {
+	query := url.Values{}
+	for _, name := range []string{"q", "limit"} {
+		if v := r.URL.Query().Get(name); v != "" {
+			query.Set(name, v)
+		}
+	}
+	return query
+}
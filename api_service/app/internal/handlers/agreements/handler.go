@@ -0,0 +1,376 @@
+package agreements
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	templatesURL           = "/api/agreement-templates"
+	singleTemplateURL      = "/api/agreement-templates/:id"
+	bookingAgreementsURL   = "/api/bookings/:id/agreements"
+	singleAgreementURL     = "/api/agreements/:id"
+	agreementDocumentURL   = "/api/agreements/:id/document"
+	agreementAcceptanceURL = "/api/agreements/:id/acceptance"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, templatesURL, jwt.Middleware(apperror.Middleware(h.GetTemplates)))
+	router.HandlerFunc(http.MethodPost, templatesURL, jwt.Middleware(apperror.Middleware(h.CreateTemplate)))
+	router.HandlerFunc(http.MethodGet, singleTemplateURL, jwt.Middleware(apperror.Middleware(h.GetTemplate)))
+	router.HandlerFunc(http.MethodDelete, singleTemplateURL, jwt.Middleware(apperror.Middleware(h.DeleteTemplate)))
+	router.HandlerFunc(http.MethodGet, bookingAgreementsURL, jwt.Middleware(apperror.Middleware(h.GetAgreements)))
+	router.HandlerFunc(http.MethodPost, bookingAgreementsURL, jwt.Middleware(apperror.Middleware(h.CreateAgreement)))
+	router.HandlerFunc(http.MethodGet, singleAgreementURL, jwt.Middleware(apperror.Middleware(h.GetAgreement)))
+	router.HandlerFunc(http.MethodGet, agreementDocumentURL, jwt.Middleware(apperror.Middleware(h.GetDocument)))
+	router.HandlerFunc(http.MethodPut, agreementAcceptanceURL, jwt.Middleware(apperror.Middleware(h.Accept)))
+}
+
+// GetTemplates godoc
+//
+//	@Summary		Show agreement templates
+//	@Description	get the default template of rental agreement followed by own templates of the user
+//	@Tags			agreements
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		200		{array}		lot_service.AgreementTemplate
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/agreement-templates [get]
+func (h *Handler) GetTemplates(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	templates, err := h.LotService.GetAgreementTemplates(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(templates)
+	return nil
+}
+
+// CreateTemplate godoc
+//
+//	@Summary		Create agreement template
+//	@Description	saves own template of rental agreement. The template is checked by filling it with sample data.
+//	@Tags			agreements
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string									true	"JWT token"
+//	@Param			template	body		lot_service.CreateAgreementTemplateDTO	true	"template"
+//	@Success		201			{object}	lot_service.AgreementTemplate
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/agreement-templates [post]
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.CreateAgreementTemplateDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	template, err := h.LotService.CreateAgreementTemplate(r.Context(), userID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(template)
+	return nil
+}
+
+// GetTemplate godoc
+//
+//	@Summary		Show agreement template
+//	@Description	get own template of the user
+//	@Tags			agreements
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Template ID"
+//	@Success		200		{object}	lot_service.AgreementTemplate
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/agreement-templates/{id} [get]
+func (h *Handler) GetTemplate(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	templateID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	template, err := h.LotService.GetAgreementTemplate(r.Context(), userID, templateID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(template)
+	return nil
+}
+
+// DeleteTemplate godoc
+//
+//	@Summary		Delete agreement template
+//	@Description	deletes own template of the user. Agreements made from it are kept.
+//	@Tags			agreements
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"Template ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/agreement-templates/{id} [delete]
+func (h *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	templateID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.DeleteAgreementTemplate(r.Context(), userID, templateID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetAgreements godoc
+//
+//	@Summary		Show agreements of booking
+//	@Description	get all versions of rental agreement of the booking, the latest first. Parties only.
+//	@Tags			agreements
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Booking ID"
+//	@Success		200		{array}		lot_service.Agreement
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/bookings/{id}/agreements [get]
+func (h *Handler) GetAgreements(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	bookingID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	agreements, err := h.LotService.GetAgreements(r.Context(), userID, bookingID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(agreements)
+	return nil
+}
+
+// CreateAgreement godoc
+//
+//	@Summary		Make rental agreement
+//	@Description	fills the template with data of the lot, its landlord, the renter and the booking and saves
+//	@Description	the text as new version of agreement. Only the landlord of accepted booking can make agreements,
+//	@Description	new versions can't be made after both parties accepted the latest one. The renter is notified.
+//	@Tags			agreements
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string							true	"JWT token"
+//	@Param			id			path		int								true	"Booking ID"
+//	@Param			agreement	body		lot_service.CreateAgreementDTO	false	"template"
+//	@Success		201			{object}	lot_service.Agreement
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		403			{object}	apperror.AppError
+//	@Failure		404			{object}	apperror.AppError
+//	@Failure		409			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/bookings/{id}/agreements [post]
+func (h *Handler) CreateAgreement(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	bookingID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.CreateAgreementDTO{}
+	defer r.Body.Close()
+	if r.ContentLength != 0 {
+		if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+			return apperror.BadRequestError("failed to decode data", "")
+		}
+	}
+
+	agreement, err := h.LotService.CreateAgreement(r.Context(), userID, bookingID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(agreement)
+	return nil
+}
+
+// GetAgreement godoc
+//
+//	@Summary		Show agreement
+//	@Description	get version of rental agreement. Parties only.
+//	@Tags			agreements
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Agreement ID"
+//	@Success		200		{object}	lot_service.Agreement
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/agreements/{id} [get]
+func (h *Handler) GetAgreement(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	agreementID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	agreement, err := h.LotService.GetAgreement(r.Context(), userID, agreementID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(agreement)
+	return nil
+}
+
+// GetDocument godoc
+//
+//	@Summary		Download agreement
+//	@Description	get version of rental agreement as HTML page or PDF document with acceptances of the parties.
+//	@Description	Every page has the version and hash of its text. Parties only.
+//	@Tags			agreements
+//	@Produce		html
+//	@Produce		application/pdf
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Agreement ID"
+//	@Param			format	query		string	false	"html (default) or pdf"	Enums(html, pdf)
+//	@Success		200		{string}	string	"document"
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/agreements/{id}/document [get]
+func (h *Handler) GetDocument(w http.ResponseWriter, r *http.Request) error {
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	agreementID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	doc, header, err := h.LotService.GetAgreementDocument(r.Context(), userID, agreementID,
+		r.URL.Query().Get("format"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	w.Header().Set("Content-Type", header.Get("Content-Type"))
+	w.Header().Set("Content-Disposition", header.Get("Content-Disposition"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc)
+	return nil
+}
+
+// Accept godoc
+//
+//	@Summary		Accept agreement
+//	@Description	accepts the latest version of rental agreement by the party. Time and IP address of acceptance
+//	@Description	are kept as evidence. The agreement is signed, when both parties accepted it. The other party
+//	@Description	is notified.
+//	@Tags			agreements
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string							true	"JWT token"
+//	@Param			id			path		int								true	"Agreement ID"
+//	@Param			acceptance	body		lot_service.AcceptAgreementDTO	true	"acceptance"
+//	@Success		200			{object}	lot_service.Agreement
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		404			{object}	apperror.AppError
+//	@Failure		409			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/agreements/{id}/acceptance [put]
+func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	agreementID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.AcceptAgreementDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	agreement, err := h.LotService.AcceptAgreement(r.Context(), userID, agreementID, handlers.ClientIP(r), dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(agreement)
+	return nil
+}
@@ -0,0 +1,96 @@
+package analytics
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	lotAnalyticsURL = "/api/lots/lot/:id/analytics"
+	dashboardURL    = "/api/analytics/lots"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, lotAnalyticsURL, jwt.Middleware(apperror.Middleware(h.GetLotAnalytics)))
+	router.HandlerFunc(http.MethodGet, dashboardURL, jwt.Middleware(apperror.Middleware(h.GetDashboard)))
+}
+
+// GetLotAnalytics godoc
+//
+//	@Summary		Show analytics of lot
+//	@Description	get daily views, additions to favorites, contact reveals and messages of renters for the lot
+//	@Description	and average numbers for similar lots: other lots of the same estate type and rooms
+//	@Description	in the district. Available to the agent of the lot and managers of its organization.
+//	@Tags			analytics
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Lot ID"
+//	@Param			days	query		int		false	"number of the last days, 30 by default, up to 365"
+//	@Success		200		{object}	lot_service.LotAnalytics
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/analytics [get]
+func (h *Handler) GetLotAnalytics(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	analytics, err := h.LotService.GetLotAnalytics(r.Context(), userID, lotID, r.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(analytics)
+	return nil
+}
+
+// GetDashboard godoc
+//
+//	@Summary		Show analytics of my lots
+//	@Description	get total views, additions to favorites, contact reveals and messages of renters for each lot
+//	@Description	of the user, most viewed first.
+//	@Tags			analytics
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			days	query		int		false	"number of the last days, 30 by default, up to 365"
+//	@Success		200		{array}		lot_service.LotSummary
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/analytics/lots [get]
+func (h *Handler) GetDashboard(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	summaries, err := h.LotService.GetAnalyticsDashboard(r.Context(), userID, r.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(summaries)
+	return nil
+}
@@ -2,19 +2,35 @@ package auth
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
 	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
 	"net/http"
 )
 
 const (
-	authURL   = "/api/auth"
-	signupURL = "/api/signup"
+	authURL         = "/api/auth"
+	secondFactorURL = "/api/auth/2fa"
+	signupURL       = "/api/signup"
 )
 
+// Challenge model info
+// @Description returned instead of JWT when user has two-factor authentication enabled.
+type Challenge struct {
+	ChallengeToken string `json:"challenge_token"` // valid for 5 minutes
+}
+
+// SecondFactorDTO model info
+// @Description second step of authentication.
+type SecondFactorDTO struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code" example:"123456"` // code from authenticator app or one of recovery codes
+}
+
 type Handler struct {
 	Logger      logging.Logger
 	UserService user_service.UserService
@@ -24,6 +40,7 @@ type Handler struct {
 func (h *Handler) Register(router *httprouter.Router) {
 	router.HandlerFunc(http.MethodPost, signupURL, apperror.Middleware(h.SignUp))
 	router.HandlerFunc(http.MethodPost, authURL, apperror.Middleware(h.SignIn))
+	router.HandlerFunc(http.MethodPost, secondFactorURL, apperror.Middleware(h.SignInSecondFactor))
 }
 
 // SignUp godoc
@@ -66,19 +83,20 @@ func (h *Handler) SignUp(w http.ResponseWriter, r *http.Request) error {
 // SignIn godoc
 //
 //	@Summary Authenticate user
-//	@Description authenticates user and returns JWT
+//	@Description authenticates user and returns JWT.
+//	@Description If user has two-factor authentication enabled, returns challenge token for /auth/2fa instead.
 //	@Tags user
 //	@Accept json
 //	@Param DTO body user_service.SignInUserDTO true "user data"
 //	@Produce json
 //	@Success 200 {string} jwt.token.string
+//	@Success 202 {object}	Challenge
 //	@Failure 400 {object}	apperror.AppError
+//	@Failure 429 {object}	apperror.AppError "too many failed attempts, see Retry-After header"
 //	@Failure 418 {object}	apperror.AppError
 //	@Router /auth [post]
 func (h *Handler) SignIn(w http.ResponseWriter, r *http.Request) error {
 
-	var token []byte
-
 	w.Header().Set("Content-Type", "application/json")
 
 	switch r.Method {
@@ -88,15 +106,13 @@ func (h *Handler) SignIn(w http.ResponseWriter, r *http.Request) error {
 		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
 			return apperror.BadRequestError("failed to decode data", "")
 		}
+		dto.IP = handlers.ClientIP(r)
 		u, err := h.UserService.SignIn(r.Context(), dto)
 		if err != nil {
 			return err
 		}
 		h.Logger.Debugf("user:%v", u)
-		token, err = h.JWTHelper.GenerateAccessToken(u)
-		if err != nil {
-			return err
-		}
+		return CompleteSignIn(w, h.JWTHelper, u)
 		//case http.MethodPut:
 		//	defer r.Body.Close()
 		//	var rt jwt.RT
@@ -109,6 +125,87 @@ func (h *Handler) SignIn(w http.ResponseWriter, r *http.Request) error {
 		//	}
 	}
 
+	return nil
+}
+
+// SignInSecondFactor godoc
+//
+//	@Summary Authenticate user with second factor
+//	@Description checks code from authenticator app or recovery code and returns JWT
+//	@Tags user
+//	@Accept json
+//	@Param DTO body SecondFactorDTO true "challenge token from /auth and code"
+//	@Produce json
+//	@Success 200 {string} jwt.token.string
+//	@Failure 400 {object}	apperror.AppError
+//	@Failure 401 {object}	apperror.AppError
+//	@Failure 418 {object}	apperror.AppError
+//	@Router /auth/2fa [post]
+func (h *Handler) SignInSecondFactor(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	var dto *SecondFactorDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	userID, err := h.JWTHelper.ParseChallengeToken(dto.ChallengeToken)
+	if err != nil {
+		h.Logger.Error(err)
+		return apperror.UnauthorizedError("challenge token is invalid or expired")
+	}
+
+	err = h.UserService.VerifyTOTP(r.Context(), &user_service.VerifyTOTPDTO{
+		UserID: userID,
+		Code:   dto.Code,
+	})
+	if err != nil {
+		return err
+	}
+
+	u, err := h.UserService.GetProfile(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	token, err := h.JWTHelper.GenerateAccessToken(u)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(token)
+
+	return nil
+}
+
+// CompleteSignIn must be called by every sign in path after the user has proven the first factor.
+// It returns JWT or, if the user has two-factor authentication enabled, challenge token for the second step.
+// Any other tokens issued on sign in (i.e. refresh ones) must be issued here as well, so they are never
+// given out before the second factor is checked.
+func CompleteSignIn(w http.ResponseWriter, helper jwt.Helper, u *user_service.User) error {
+	if u.TwoFactor {
+		challenge, err := helper.GenerateChallengeToken(u)
+		if err != nil {
+			return err
+		}
+
+		challengeBytes, err := json.Marshal(&Challenge{ChallengeToken: string(challenge)})
+		if err != nil {
+			return fmt.Errorf("failed to marshal challenge. error: %w", err)
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(challengeBytes)
+		return nil
+	}
+
+	token, err := helper.GenerateAccessToken(u)
+	if err != nil {
+		return err
+	}
+
 	w.WriteHeader(http.StatusOK)
 	w.Write(token)
 
@@ -0,0 +1,183 @@
+package bookings
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	bookingsURL      = "/api/bookings"
+	singleBookingURL = "/api/bookings/:id"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPost, bookingsURL, jwt.Middleware(apperror.Middleware(h.CreateBooking)))
+	router.HandlerFunc(http.MethodGet, bookingsURL, jwt.Middleware(apperror.Middleware(h.GetBookings)))
+	router.HandlerFunc(http.MethodGet, singleBookingURL, jwt.Middleware(apperror.Middleware(h.GetBooking)))
+	router.HandlerFunc(http.MethodPatch, singleBookingURL, jwt.Middleware(apperror.Middleware(h.UpdateBooking)))
+}
+
+// CreateBooking godoc
+//
+//	@Summary		Request booking
+//	@Description	creates booking request for the lot on behalf of the user from JWT.
+//	@Description	Landlord has to answer the request before it expires.
+//	@Tags			bookings
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string						true	"JWT token"
+//	@Param			DTO		body		lot_service.CreateBookingDTO	true	"booking request"
+//	@Success		201		{object}	lot_service.Booking
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/bookings [post]
+func (h *Handler) CreateBooking(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	dto := &lot_service.CreateBookingDTO{}
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	dto.RenterID = userID
+
+	b, err := h.LotService.CreateBooking(r.Context(), userID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+	return nil
+}
+
+// GetBookings godoc
+//
+//	@Summary		Show bookings of the user
+//	@Description	get bookings made by the user from JWT or, with party=landlord, bookings of the user's lots
+//	@Tags			bookings
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			party	query		string	false	"renter (default) or landlord"	Enums(renter, landlord)
+//	@Success		200		{array}		lot_service.Booking
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/bookings [get]
+func (h *Handler) GetBookings(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	bookings, err := h.LotService.GetBookings(r.Context(), userID, r.URL.Query().Get("party"))
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(bookings)
+	return nil
+}
+
+// GetBooking godoc
+//
+//	@Summary		Show booking
+//	@Description	get booking by its ID. Available for renter of the booking, the current agent of its lot
+//	@Description	and managers of the lot's organization.
+//	@Tags			bookings
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Booking ID"
+//	@Success		200		{object}	lot_service.Booking
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/bookings/{id} [get]
+func (h *Handler) GetBooking(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	bookingID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	b, err := h.LotService.GetBooking(r.Context(), userID, bookingID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+	return nil
+}
+
+// UpdateBooking godoc
+//
+//	@Summary		Answer booking
+//	@Description	accept, decline, counter or cancel booking on behalf of the user from JWT.
+//	@Description	Lot of the accepted booking is unavailable during the stay. Price of the stay is quoted on acceptance,
+//	@Description	stays, which can't be priced in rental modes of the lot, can't be accepted. Landlord side is answered
+//	@Description	by the current agent of the lot or managers of its organization.
+//	@Tags			bookings
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string						true	"JWT token"
+//	@Param			id		path		int							true	"Booking ID"
+//	@Param			DTO		body		lot_service.UpdateBookingDTO	true	"action"
+//	@Success		200		{object}	lot_service.Booking
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/bookings/{id} [patch]
+func (h *Handler) UpdateBooking(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	dto := &lot_service.UpdateBookingDTO{}
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	bookingID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	b, err := h.LotService.UpdateBooking(r.Context(), userID, bookingID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+	return nil
+}
@@ -0,0 +1,259 @@
+package calendars
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	calendarURL       = "/api/lots/lot/:id/calendar"
+	calendarExportURL = "/api/lots/lot/:id/calendar.ics"
+	calendarBlocksURL = "/api/lots/lot/:id/calendar/blocks"
+	calendarBlockURL  = "/api/lots/lot/:id/calendar/blocks/:block_id"
+	calendarImportURL = "/api/lots/lot/:id/calendar/import"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, calendarURL, apperror.Middleware(h.GetCalendar))
+	router.HandlerFunc(http.MethodGet, calendarExportURL, apperror.Middleware(h.ExportCalendar))
+	router.HandlerFunc(http.MethodPost, calendarBlocksURL, jwt.Middleware(apperror.Middleware(h.CreateBlock)))
+	router.HandlerFunc(http.MethodDelete, calendarBlockURL, jwt.Middleware(apperror.Middleware(h.DeleteBlock)))
+	router.HandlerFunc(http.MethodGet, calendarImportURL, jwt.Middleware(apperror.Middleware(h.GetImport)))
+	router.HandlerFunc(http.MethodPut, calendarImportURL, jwt.Middleware(apperror.Middleware(h.SetImport)))
+}
+
+// GetCalendar godoc
+//
+//	@Summary		Show lot calendar
+//	@Description	get periods, when the lot is booked or blocked, starting from today
+//	@Tags			calendar
+//	@Produce		json
+//	@Param			id	path		int	true	"Lot ID"
+//	@Success		200	{array}		lot_service.CalendarPeriod
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/calendar [get]
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	periods, err := h.LotService.GetCalendar(r.Context(), lotID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(periods)
+	return nil
+}
+
+// ExportCalendar godoc
+//
+//	@Summary		Export lot calendar
+//	@Description	get booked and blocked periods of the lot as iCalendar, so other platforms can sync with it
+//	@Tags			calendar
+//	@Produce		text/calendar
+//	@Param			id	path		int	true	"Lot ID"
+//	@Success		200	{string}	string	"iCalendar"
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/calendar.ics [get]
+func (h *Handler) ExportCalendar(w http.ResponseWriter, r *http.Request) error {
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	cal, err := h.LotService.ExportCalendar(r.Context(), lotID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"lot-%d.ics\"", lotID))
+	w.WriteHeader(http.StatusOK)
+	w.Write(cal)
+	return nil
+}
+
+// CreateBlock godoc
+//
+//	@Summary		Block dates of the lot
+//	@Description	blocks period in calendar of the lot. Available for the agent of the lot and managers
+//	@Description	of its organization. Period must not overlap accepted bookings.
+//	@Tags			calendar
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string								true	"JWT token"
+//	@Param			id		path		int									true	"Lot ID"
+//	@Param			DTO		body		lot_service.CreateCalendarBlockDTO	true	"period"
+//	@Success		201		{object}	lot_service.CalendarBlock
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/calendar/blocks [post]
+func (h *Handler) CreateBlock(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	dto := &lot_service.CreateCalendarBlockDTO{}
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	b, err := h.LotService.CreateCalendarBlock(r.Context(), userID, lotID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+	return nil
+}
+
+// DeleteBlock godoc
+//
+//	@Summary		Unblock dates of the lot
+//	@Description	deletes period blocked in calendar of the lot. Imported periods can't be deleted.
+//	@Tags			calendar
+//	@Param			Token		header	string	true	"JWT token"
+//	@Param			id			path	int		true	"Lot ID"
+//	@Param			block_id	path	int		true	"Block ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/calendar/blocks/{block_id} [delete]
+func (h *Handler) DeleteBlock(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+	blockID, err := handlers.IDFromParams(r, "block_id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.DeleteCalendarBlock(r.Context(), userID, lotID, blockID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetImport godoc
+//
+//	@Summary		Show calendar import
+//	@Description	get external calendar of the lot and result of its last import. Available for the agent
+//	@Description	of the lot and managers of its organization.
+//	@Tags			calendar
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Lot ID"
+//	@Success		200		{object}	lot_service.CalendarImport
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/calendar/import [get]
+func (h *Handler) GetImport(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	imp, err := h.LotService.GetCalendarImport(r.Context(), userID, lotID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(imp)
+	return nil
+}
+
+// SetImport godoc
+//
+//	@Summary		Set calendar import
+//	@Description	sets external iCal calendar of the lot, its events block the lot. Calendar is imported
+//	@Description	at once and then periodically. Imported events overlapping accepted bookings are reported
+//	@Description	as conflicts. Empty URL disables import. Available for the agent of the lot and managers
+//	@Description	of its organization.
+//	@Tags			calendar
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string								true	"JWT token"
+//	@Param			id		path		int									true	"Lot ID"
+//	@Param			DTO		body		lot_service.SetCalendarImportDTO	true	"calendar URL"
+//	@Success		200		{object}	lot_service.CalendarImportResult
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/calendar/import [put]
+func (h *Handler) SetImport(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	dto := &lot_service.SetCalendarImportDTO{}
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.LotService.SetCalendarImport(r.Context(), userID, lotID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+	return nil
+}
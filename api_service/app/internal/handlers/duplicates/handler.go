@@ -0,0 +1,151 @@
+package duplicates
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+	"net/url"
+)
+
+const (
+	duplicatesURL         = "/api/admin/duplicates"
+	moderatedDuplicateURL = "/api/admin/duplicates/:id"
+	lotPhotosURL          = "/api/lots/lot/:id/photos"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, duplicatesURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.GetDuplicates))))
+	router.HandlerFunc(http.MethodPut, moderatedDuplicateURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.Moderate))))
+	router.HandlerFunc(http.MethodPut, lotPhotosURL, jwt.Middleware(apperror.Middleware(h.SetPhotos)))
+}
+
+// GetDuplicates godoc
+//
+//	@Summary		Show duplicates of lots
+//	@Description	get probable duplicates of lots, newest first. Lots at the same address are compared by floor,
+//	@Description	area, rooms and photos. Admins only.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			status	query		string	false	"status of duplicates" Enums(pending, confirmed, dismissed)
+//	@Param			lot_id	query		int		false	"ID of the duplicate or of the original lot"
+//	@Success		200		{array}		lot_service.Duplicate
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/admin/duplicates [get]
+func (h *Handler) GetDuplicates(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := url.Values{}
+	for _, name := range []string{"status", "lot_id"} {
+		if v := r.URL.Query().Get(name); v != "" {
+			query.Set(name, v)
+		}
+	}
+
+	duplicates, err := h.LotService.GetDuplicates(r.Context(), query)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(duplicates)
+	return nil
+}
+
+// Moderate godoc
+//
+//	@Summary		Moderate duplicate
+//	@Description	confirms or dismisses probable duplicate. Confirmed and pending duplicates are hidden
+//	@Description	from lots search with group_duplicates. Admins only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string							true	"JWT token"
+//	@Param			id			path		int								true	"Duplicate ID"
+//	@Param			decision	body		lot_service.ModerateDuplicateDTO	true	"decision"
+//	@Success		200			{object}	lot_service.Duplicate
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		403			{object}	apperror.AppError
+//	@Failure		404			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/admin/duplicates/{id} [put]
+func (h *Handler) Moderate(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	duplicateID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.ModerateDuplicateDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	duplicate, err := h.LotService.ModerateDuplicate(r.Context(), duplicateID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(duplicate)
+	return nil
+}
+
+// SetPhotos godoc
+//
+//	@Summary		Set photos of the lot
+//	@Description	replaces photos of the lot, which are compared with photos of other lots at the same address
+//	@Description	to find duplicates. Available for the agent of the lot and managers of its organization.
+//	@Tags			lot
+//	@Accept			json
+//	@Param			Token	header	string						true	"JWT token"
+//	@Param			id		path	int							true	"Lot ID"
+//	@Param			DTO		body	lot_service.SetLotPhotosDTO	true	"photos"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		403	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/photos [put]
+func (h *Handler) SetPhotos(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.SetLotPhotosDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	if err = h.LotService.SetLotPhotos(r.Context(), userID, lotID, dto); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,263 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/eventbus"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	streamURL = "/api/events/stream"
+	ticketURL = "/api/events/ticket"
+
+	// backlogBatch is the number of missed events requested from lot_service at once.
+	backlogBatch = 100
+	// retryMillis tells clients how soon to reconnect after the stream is closed.
+	retryMillis = 1000
+)
+
+// Ticket model info
+// @Description opens event stream in place of JWT, which browsers can't send with EventSource.
+type Ticket struct {
+	Ticket    string    `json:"ticket"`     // valid for a minute
+	ExpiresAt time.Time `json:"expires_at"` // of JWT, stream opened with the ticket ends then
+}
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+	JWTHelper  jwt.Helper
+	Bus        *eventbus.Bus
+	// Heartbeat is interval of comments keeping the connection alive through proxies.
+	Heartbeat time.Duration
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, streamURL, jwt.StreamMiddleware(apperror.Middleware(h.Stream)))
+	router.HandlerFunc(http.MethodPost, ticketURL, jwt.Middleware(apperror.Middleware(h.CreateTicket)))
+}
+
+// Stream godoc
+//
+//	@Summary		Stream events
+//	@Description	Server-Sent Events stream of the user from JWT: new messages and booking changes.
+//	@Description	Every event has id, reconnect with Last-Event-ID header (or last_event_id query) to receive
+//	@Description	events missed while disconnected. Browsers open the stream with ticket instead of JWT, since
+//	@Description	EventSource can't send headers. The stream ends, when JWT expires, reconnect with new ticket.
+//	@Tags			events
+//	@Produce		text/event-stream
+//	@Param			Token			header		string	false	"JWT token, required without ticket"
+//	@Param			ticket			query		string	false	"stream ticket, required without JWT token"
+//	@Param			Last-Event-ID	header		int		false	"ID of the last received event"
+//	@Param			last_event_id	query		int		false	"ID of the last received event"
+//	@Success		200				{object}	lot_service.Event	"stream of events"
+//	@Failure		400				{object}	apperror.AppError
+//	@Failure		418				{object}	apperror.AppError
+//	@Router			/events/stream [get]
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("streaming is not supported by response writer")
+	}
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	expiresAt, ok := r.Context().Value("expires_at").(time.Time)
+	if !ok {
+		return fmt.Errorf("error with type of req.context value of key 'expires_at'")
+	}
+	lastID, err := lastEventID(r)
+	if err != nil {
+		return err
+	}
+
+	// subscribe before reading the backlog, so no event is lost in between
+	events, publishedID, unsubscribe := h.Bus.Subscribe(userID)
+	defer unsubscribe()
+
+	var backlog []*lot_service.Event
+	if lastID != 0 && lastID < publishedID {
+		if backlog, err = h.readBacklog(r.Context(), userID, lastID, publishedID); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sw := &streamWriter{w: w, flusher: flusher, conn: connFromContext(r.Context()), timeout: 2 * h.Heartbeat}
+	if err = sw.write(fmt.Sprintf("retry: %d\n\n", retryMillis)); err != nil {
+		return nil
+	}
+	for _, e := range backlog {
+		if err = sw.event(e); err != nil {
+			return nil
+		}
+		lastID = e.ID
+	}
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+	expired := time.NewTimer(time.Until(expiresAt))
+	defer expired.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-expired.C:
+			return nil
+		case e, ok := <-events:
+			if !ok {
+				// the stream is too slow, client resumes after reconnect
+				return nil
+			}
+			if e.ID <= lastID {
+				continue
+			}
+			if err = sw.event(e); err != nil {
+				return nil
+			}
+			lastID = e.ID
+		case <-heartbeat.C:
+			if err = sw.write(": ping\n\n"); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// CreateTicket godoc
+//
+//	@Summary		Create stream ticket
+//	@Description	returns short-lived ticket, which opens event stream of the user from JWT in browsers.
+//	@Description	Pass it as ticket query parameter of the stream.
+//	@Tags			events
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		201		{object}	Ticket
+//	@Failure		401		{string}	string	"unauthorized"
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/events/ticket [post]
+func (h *Handler) CreateTicket(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		return fmt.Errorf("error with type of req.context value of key 'user_id'")
+	}
+	role, _ := r.Context().Value("role").(string)
+	expiresAt, ok := r.Context().Value("expires_at").(time.Time)
+	if !ok {
+		return fmt.Errorf("error with type of req.context value of key 'expires_at'")
+	}
+
+	ticket, err := h.JWTHelper.GenerateStreamTicket(userID, role, expiresAt)
+	if err != nil {
+		return err
+	}
+	ticketBytes, err := json.Marshal(&Ticket{Ticket: string(ticket), ExpiresAt: expiresAt})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ticket. error: %w", err)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(ticketBytes)
+	return nil
+}
+
+// readBacklog returns events of the user after afterID, which the bus has published up to toID. Later events
+// are delivered by the bus.
+func (h *Handler) readBacklog(ctx context.Context, userID, afterID, toID uint) ([]*lot_service.Event, error) {
+	backlog := make([]*lot_service.Event, 0)
+	for {
+		events, err := h.LotService.GetEvents(ctx, afterID, userID, backlogBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			if e.ID > toID {
+				return backlog, nil
+			}
+			backlog = append(backlog, e)
+		}
+		if len(events) < backlogBatch {
+			return backlog, nil
+		}
+		afterID = events[len(events)-1].ID
+	}
+}
+
+type streamWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (s *streamWriter) event(e *lot_service.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data))
+}
+
+// write sends data to the client at once. Write deadline of the server is moved for every write,
+// so the stream is closed only when the client stops reading.
+func (s *streamWriter) write(data string) error {
+	if s.conn != nil {
+		if err := s.conn.SetWriteDeadline(time.Now().Add(s.timeout)); err != nil {
+			return err
+		}
+	}
+	if _, err := s.w.Write([]byte(data)); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+type connKey struct{}
+
+// ConnContext must be set as ConnContext of the server, so streams can move write deadlines
+// of their connections.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, c)
+}
+
+func connFromContext(ctx context.Context) net.Conn {
+	c, _ := ctx.Value(connKey{}).(net.Conn)
+	return c
+}
+
+func lastEventID(r *http.Request) (uint, error) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("last_event_id")
+	}
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return 0, apperror.BadRequestError("last event id must be an unsigned integer", "")
+	}
+	return uint(id), nil
+}
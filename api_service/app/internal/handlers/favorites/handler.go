@@ -0,0 +1,119 @@
+package favorites
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	favoritesURL      = "/api/favorites"
+	singleFavoriteURL = "/api/favorites/:id"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, favoritesURL, jwt.Middleware(apperror.Middleware(h.GetFavorites)))
+	router.HandlerFunc(http.MethodPut, singleFavoriteURL, jwt.Middleware(apperror.Middleware(h.AddFavorite)))
+	router.HandlerFunc(http.MethodDelete, singleFavoriteURL, jwt.Middleware(apperror.Middleware(h.RemoveFavorite)))
+}
+
+// GetFavorites godoc
+//
+//	@Summary		Show favorites
+//	@Description	get lots saved by the user, newest first.
+//	@Tags			favorites
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		200		{array}		lot_service.Favorite
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/favorites [get]
+func (h *Handler) GetFavorites(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	favorites, err := h.LotService.GetFavorites(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(favorites)
+	return nil
+}
+
+// AddFavorite godoc
+//
+//	@Summary		Add lot to favorites
+//	@Description	saves the lot in favorites of the user, up to 200 lots. Adding the lot again changes nothing.
+//	@Tags			favorites
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Lot ID"
+//	@Success		200		{object}	lot_service.Favorite
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/favorites/{id} [put]
+func (h *Handler) AddFavorite(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	favorite, err := h.LotService.AddFavorite(r.Context(), userID, lotID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(favorite)
+	return nil
+}
+
+// RemoveFavorite godoc
+//
+//	@Summary		Remove lot from favorites
+//	@Tags			favorites
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"Lot ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/favorites/{id} [delete]
+func (h *Handler) RemoveFavorite(w http.ResponseWriter, r *http.Request) error {
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.RemoveFavorite(r.Context(), userID, lotID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,248 @@
+package feeds
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	feedsURL        = "/api/admin/feeds"
+	managedFeedURL  = "/api/admin/feeds/:id"
+	feedDocumentURL = "/api/feeds/:id"
+	cityFeedURL     = "/api/city-feeds/:city"
+)
+
+// conditionalHeaders are passed to lot service, so unchanged feeds aren't transferred.
+var conditionalHeaders = []string{"If-None-Match", "If-Modified-Since"}
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, feedsURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.GetFeeds))))
+	router.HandlerFunc(http.MethodPost, feedsURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.CreateFeed))))
+	router.HandlerFunc(http.MethodPut, managedFeedURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.UpdateFeed))))
+	router.HandlerFunc(http.MethodDelete, managedFeedURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.DeleteFeed))))
+	router.HandlerFunc(http.MethodGet, feedDocumentURL, apperror.Middleware(h.GetDocument))
+	router.HandlerFunc(http.MethodGet, cityFeedURL, apperror.Middleware(h.GetCityDocument))
+}
+
+// GetFeeds godoc
+//
+//	@Summary		Show feeds
+//	@Description	get feeds of lots for aggregator portals. Admins only.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		200		{array}		lot_service.Feed
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/admin/feeds [get]
+func (h *Handler) GetFeeds(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	feeds, err := h.LotService.GetFeeds(r.Context())
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(feeds)
+	return nil
+}
+
+// CreateFeed godoc
+//
+//	@Summary		Create feed
+//	@Description	creates feed of lots for aggregator portal. The feed is published at /feeds/{id}. Admins only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string				true	"JWT token"
+//	@Param			feed	body		lot_service.FeedDTO	true	"feed"
+//	@Success		201		{object}	lot_service.Feed
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/admin/feeds [post]
+func (h *Handler) CreateFeed(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	dto := &lot_service.FeedDTO{}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	feed, err := h.LotService.CreateFeed(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(feed)
+	return nil
+}
+
+// UpdateFeed godoc
+//
+//	@Summary		Update feed
+//	@Description	replaces name, format and filter of the feed. Admins only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string				true	"JWT token"
+//	@Param			id		path		int					true	"Feed ID"
+//	@Param			feed	body		lot_service.FeedDTO	true	"feed"
+//	@Success		200		{object}	lot_service.Feed
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/admin/feeds/{id} [put]
+func (h *Handler) UpdateFeed(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.FeedDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	feed, err := h.LotService.UpdateFeed(r.Context(), id, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(feed)
+	return nil
+}
+
+// DeleteFeed godoc
+//
+//	@Summary		Delete feed
+//	@Description	Admins only.
+//	@Tags			admin
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"Feed ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		403	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/admin/feeds/{id} [delete]
+func (h *Handler) DeleteFeed(w http.ResponseWriter, r *http.Request) error {
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.DeleteFeed(r.Context(), id); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetDocument godoc
+//
+//	@Summary		Download feed
+//	@Description	get feed of lots in its format: Yandex.Realty XML, Avito autoload XML, RSS or Atom.
+//	@Description	Responses have ETag and Last-Modified, conditional requests of unchanged feed get 304.
+//	@Tags			feeds
+//	@Produce		xml
+//	@Param			id				path		int		true	"Feed ID"
+//	@Param			If-None-Match	header		string	false	"ETag of the feed"
+//	@Success		200				{string}	string	"feed"
+//	@Success		304
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/feeds/{id} [get]
+func (h *Handler) GetDocument(w http.ResponseWriter, r *http.Request) error {
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	doc, header, err := h.LotService.GetFeedDocument(r.Context(), id, conditions(r))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	serveFeed(w, r, doc, header)
+	return nil
+}
+
+// GetCityDocument godoc
+//
+//	@Summary		Download feed of city
+//	@Description	get RSS or Atom feed of the newest lots of the city. Responses have ETag and Last-Modified,
+//	@Description	conditional requests of unchanged feed get 304.
+//	@Tags			feeds
+//	@Produce		xml
+//	@Param			city			path		string	true	"City"
+//	@Param			format			query		string	false	"rss (default) or atom"	Enums(rss, atom)
+//	@Param			If-None-Match	header		string	false	"ETag of the feed"
+//	@Success		200				{string}	string	"feed"
+//	@Success		304
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/city-feeds/{city} [get]
+func (h *Handler) GetCityDocument(w http.ResponseWriter, r *http.Request) error {
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+
+	doc, header, err := h.LotService.GetCityFeedDocument(r.Context(), params.ByName("city"),
+		r.URL.Query().Get("format"), conditions(r))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	serveFeed(w, r, doc, header)
+	return nil
+}
+
+func conditions(r *http.Request) http.Header {
+	header := http.Header{}
+	for _, name := range conditionalHeaders {
+		if v := r.Header.Get(name); v != "" {
+			header.Set(name, v)
+		}
+	}
+	return header
+}
+
+// serveFeed writes the feed with validators of lot service. Since conditions of the request were checked
+// by lot service with the same validators, not modified feed with empty body is answered with 304 here too.
+func serveFeed(w http.ResponseWriter, r *http.Request, doc []byte, header http.Header) {
+	modifiedAt, _ := http.ParseTime(header.Get("Last-Modified"))
+	w.Header().Set("Content-Type", header.Get("Content-Type"))
+	w.Header().Set("ETag", header.Get("ETag"))
+	http.ServeContent(w, r, "", modifiedAt, bytes.NewReader(doc))
+}
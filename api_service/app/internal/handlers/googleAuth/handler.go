@@ -72,6 +72,7 @@ func (h *Handler) handleGoogleCallback(w http.ResponseWriter, r *http.Request) e
 	}
 
 	// TODO implement user service (find and verify user by email or create new user)
+	//  and finish with auth.CompleteSignIn, so two-factor authentication is not bypassed
 
 	return nil
 }
@@ -0,0 +1,113 @@
+package history
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	lotHistoryURL = "/api/lots/lot/:id/history"
+	lotRevertURL  = "/api/lots/lot/:id/history/:number/revert"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, lotHistoryURL, jwt.Middleware(apperror.Middleware(h.GetHistory)))
+	router.HandlerFunc(http.MethodPost, lotRevertURL, jwt.Middleware(apperror.Middleware(h.Revert)))
+}
+
+// GetHistory godoc
+//
+//	@Summary		Show lot history
+//	@Description	get versions of the lot with changed fields, actors and time of changes, newest first.
+//	@Description	Available for the agent of the lot, managers of its organization and admins.
+//	@Tags			history
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Lot ID"
+//	@Success		200		{array}		lot_service.LotVersion
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/history [get]
+func (h *Handler) GetHistory(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	versions, err := h.LotService.GetLotHistory(r.Context(), userID, lotID, isModerator(r))
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(versions)
+	return nil
+}
+
+// Revert godoc
+//
+//	@Summary		Revert lot
+//	@Description	restores fields of the lot from its version, the agent of the lot is kept. The restored lot
+//	@Description	is saved as a new version. Available for the agent of the lot, managers of its organization
+//	@Description	and admins.
+//	@Tags			history
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Lot ID"
+//	@Param			number	path		int		true	"Version number"
+//	@Success		200		{object}	lot_service.Lot
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/history/{number}/revert [post]
+func (h *Handler) Revert(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+	number, err := handlers.IDFromParams(r, "number")
+	if err != nil {
+		return err
+	}
+
+	l, err := h.LotService.RevertLot(r.Context(), userID, lotID, number, isModerator(r))
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(l)
+	return nil
+}
+
+// isModerator tells, whether the user may see and revert versions of any lot.
+func isModerator(r *http.Request) bool {
+	role, _ := r.Context().Value("role").(string)
+	return role == user_service.RoleAdmin
+}
@@ -0,0 +1,244 @@
+package imports
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+	"net/url"
+)
+
+const (
+	lotImportsURL             = "/api/lot-imports"
+	singleLotImportURL        = "/api/lot-imports/:id"
+	lotImportProfilesURL      = "/api/lot-import-profiles"
+	singleLotImportProfileURL = "/api/lot-import-profiles/:id"
+)
+
+// importOptions are query parameters of import, which are passed to lot service.
+var importOptions = []string{"format", "dry_run", "profile_id", "organization_id", "mapping"}
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, lotImportsURL, jwt.Middleware(apperror.Middleware(h.GetImports)))
+	router.HandlerFunc(http.MethodPost, lotImportsURL, jwt.Middleware(apperror.Middleware(h.CreateImport)))
+	router.HandlerFunc(http.MethodGet, singleLotImportURL, jwt.Middleware(apperror.Middleware(h.GetImport)))
+	router.HandlerFunc(http.MethodGet, lotImportProfilesURL, jwt.Middleware(apperror.Middleware(h.GetProfiles)))
+	router.HandlerFunc(http.MethodPost, lotImportProfilesURL, jwt.Middleware(apperror.Middleware(h.CreateProfile)))
+	router.HandlerFunc(http.MethodDelete, singleLotImportProfileURL, jwt.Middleware(apperror.Middleware(h.DeleteProfile)))
+}
+
+// GetImports godoc
+//
+//	@Summary		Show my imports
+//	@Description	get last imports of lots started by the user, newest first.
+//	@Tags			imports
+//	@Produce		json
+//	@Param			Token	header	string	true	"JWT token"
+//	@Success		200		{array}	lot_service.LotImport
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lot-imports [get]
+func (h *Handler) GetImports(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	imports, err := h.LotService.GetLotImports(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(imports)
+	return nil
+}
+
+// CreateImport godoc
+//
+//	@Summary		Import lots
+//	@Description	uploads CSV or XLSX spreadsheet with lots, one lot per row, the first row is header.
+//	@Description	Body of the request is the file. Lots are imported in background, progress and errors
+//	@Description	of rows are shown by the returned import. Columns are matched to fields of lot by mapping
+//	@Description	of profile and mapping in query, which overrides profile. Without mapping columns are
+//	@Description	expected to be named as fields of lot.
+//	@Tags			imports
+//	@Accept			application/octet-stream
+//	@Produce		json
+//	@Param			Token			header		string	true	"JWT token"
+//	@Param			format			query		string	false	"format of file, detected by content if empty"	Enums(csv, xlsx)
+//	@Param			dry_run			query		bool	false	"only validate rows"
+//	@Param			profile_id		query		int		false	"ID of mapping profile"
+//	@Param			organization_id	query		int		false	"create lots for organization of the user"
+//	@Param			mapping			query		string	false	"JSON object of column headers by fields of lot"
+//	@Success		202				{object}	lot_service.LotImport
+//	@Failure		400				{object}	apperror.AppError
+//	@Failure		403				{object}	apperror.AppError
+//	@Failure		404				{object}	apperror.AppError
+//	@Failure		418				{object}	apperror.AppError
+//	@Router			/lot-imports [post]
+func (h *Handler) CreateImport(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	for _, name := range importOptions {
+		if v := r.URL.Query().Get(name); v != "" {
+			query.Set(name, v)
+		}
+	}
+
+	defer r.Body.Close()
+	job, err := h.LotService.CreateLotImport(r.Context(), userID, query, r.Header.Get("Content-Type"), r.Body)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(job)
+	return nil
+}
+
+// GetImport godoc
+//
+//	@Summary		Show import
+//	@Description	get status, counters and errors of rows of the import started by the user.
+//	@Tags			imports
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Import ID"
+//	@Success		200		{object}	lot_service.LotImport
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lot-imports/{id} [get]
+func (h *Handler) GetImport(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	job, err := h.LotService.GetLotImport(r.Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(job)
+	return nil
+}
+
+// GetProfiles godoc
+//
+//	@Summary		Show my mapping profiles
+//	@Description	get saved mappings of columns to fields of lot.
+//	@Tags			imports
+//	@Produce		json
+//	@Param			Token	header	string	true	"JWT token"
+//	@Success		200		{array}	lot_service.ImportProfile
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lot-import-profiles [get]
+func (h *Handler) GetProfiles(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := h.LotService.GetLotImportProfiles(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(profiles)
+	return nil
+}
+
+// CreateProfile godoc
+//
+//	@Summary		Create mapping profile
+//	@Description	saves mapping of columns to fields of lot for spreadsheets of the same layout.
+//	@Tags			imports
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string								true	"JWT token"
+//	@Param			profile	body		lot_service.CreateImportProfileDTO	true	"profile"
+//	@Success		201		{object}	lot_service.ImportProfile
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lot-import-profiles [post]
+func (h *Handler) CreateProfile(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.CreateImportProfileDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	profile, err := h.LotService.CreateLotImportProfile(r.Context(), userID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(profile)
+	return nil
+}
+
+// DeleteProfile godoc
+//
+//	@Summary		Delete mapping profile
+//	@Tags			imports
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"Profile ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/lot-import-profiles/{id} [delete]
+func (h *Handler) DeleteProfile(w http.ResponseWriter, r *http.Request) error {
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.DeleteLotImportProfile(r.Context(), userID, id); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
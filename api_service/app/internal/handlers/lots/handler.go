@@ -1,11 +1,16 @@
 package lots
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
 	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
 	"net/http"
@@ -17,22 +22,30 @@ const (
 	lotsURL      = "/api/lots"
 	lotsOfUser   = "/api/lots/user/:id"
 	singleLotURL = "/api/lots/lot/:id"
-	weekURL      = "/api/lots/week"
+	statsURL     = "/api/lots/stats"
+	contactURL   = "/api/lots/lot/:id/contact"
+	similarURL   = "/api/lots/lot/:id/similar"
+
+	sessionCookie = "session_id"
+	sessionMaxAge = 365 * 24 * 60 * 60
 )
 
 type Handler struct {
-	Logger     logging.Logger
-	LotService lot_service.LotService
+	Logger      logging.Logger
+	LotService  lot_service.LotService
+	UserService user_service.UserService
 }
 
 func (h *Handler) Register(router *httprouter.Router) {
 	router.HandlerFunc(http.MethodGet, lotsOfUser, apperror.Middleware(h.GetByUserID))
 	router.HandlerFunc(http.MethodGet, lotsURL, apperror.Middleware(h.GetLots))
 	router.HandlerFunc(http.MethodPost, lotsURL, jwt.Middleware(apperror.Middleware(h.CreateLot)))
-	router.HandlerFunc(http.MethodGet, singleLotURL, apperror.Middleware(h.GetByLotID))
+	router.HandlerFunc(http.MethodGet, singleLotURL, jwt.Optional(apperror.Middleware(h.GetByLotID)))
 	router.HandlerFunc(http.MethodPatch, singleLotURL, jwt.Middleware(apperror.Middleware(h.UpdateLot)))
 	router.HandlerFunc(http.MethodDelete, singleLotURL, jwt.Middleware(apperror.Middleware(h.DeleteLot)))
-	router.HandlerFunc(http.MethodGet, weekURL, apperror.Middleware(h.GetLastWeek))
+	router.HandlerFunc(http.MethodGet, statsURL, apperror.Middleware(h.GetStats))
+	router.HandlerFunc(http.MethodGet, similarURL, apperror.Middleware(h.GetSimilar))
+	router.HandlerFunc(http.MethodPost, contactURL, jwt.Middleware(apperror.Middleware(h.RevealContact)))
 }
 
 // GetLots godoc
@@ -41,14 +54,28 @@ func (h *Handler) Register(router *httprouter.Router) {
 //	@Description	Get lots with filter from query.
 //	@Description	Supported comparisons: eq, neq, lt, lte, gt, gte.
 //	@Description	For range use example ?created_by=2022-12-21:2022-12-22
+//	@Description	available_from and available_between select lots without bookings and blocks in the period.
+//	@Description	rental_mode selects lots rented in the mode, with available_between lots are also selected
+//	@Description	by minimal stay of the mode and get total price of the stay.
 //	@Tags			lots
 //	@Produce		json
 //	@Param 			estate_type query string false "filter by estate type"
 //	@Param 			rooms query string false "filter by rooms quantity"
+//	@Param 			city query string false "filter by city"
 //	@Param 			district query string false "filter by district"
-//	@Param 			price query string false "filter by price"
+//	@Param 			city_id query int false "filter by canonical city, see /cities"
+//	@Param 			district_id query int false "filter by canonical district, see /cities/{id}/districts"
+//	@Param 			price query string false "filter by price in currency from query, lots in all currencies are compared"
+//	@Param 			price_period query string false "filter by period of price" Enums(month, day, sale)
+//	@Param 			currency query string false "ISO 4217 code of currency to show prices in, e.g. USD"
 //	@Param 			created_at query string false "filter by date of creation"
 //	@Param 			floor query string false "filter by floor"
+//	@Param 			available_from query string false "free for at least a night since the date, e.g. 2026-11-01"
+//	@Param 			available_between query string false "free for the stay, e.g. 2026-11-01:2026-11-07 (check out day)"
+//	@Param 			rental_mode query string false "filter by rental mode" Enums(long_term, short_term)
+//	@Param 			group_duplicates query bool false "show only the original of lots flagged as duplicates"
+//	@Param 			sort_by query string false "sort field, created_at by default" Enums(created_at, price, area, rooms, floor, rating, landlord_rating)
+//	@Param 			sort_order query string false "sort order, DESC by default" Enums(ASC, DESC)
 //	@Success		200	{object}	lot_service.Lot
 //	@Failure		400	{object}	apperror.AppError
 //	@Failure		404	{object}	apperror.AppError
@@ -73,28 +100,38 @@ func (h *Handler) GetLots(w http.ResponseWriter, r *http.Request) error {
 // GetByLotID godoc
 //
 //	@Summary		Show lot by ID
-//	@Description	get lot by its ID
+//	@Description	get lot by its ID. Views are counted once per day for each user or, for anonymous visitors,
+//	@Description	for each session, which is kept in cookie.
 //	@Tags			lots
 //	@Produce		json
-//	@Param			id	path		int	true	"Lot ID"
-//	@Success		200	{object}	lot_service.Lot
-//	@Failure		400	{object}	apperror.AppError
-//	@Failure		404	{object}	apperror.AppError
-//	@Failure		418	{object}	apperror.AppError
+//	@Param			Token	header		string	false	"JWT token"
+//	@Param			id		path		int		true	"Lot ID"
+//	@Success		200		{object}	lot_service.Lot
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
 //	@Router			/lots/lot/{id} [get]
 func (h *Handler) GetByLotID(w http.ResponseWriter, r *http.Request) error {
 	w.Header().Set("Content-Type", "application/json")
 
 	h.Logger.Info("getting id from context..")
 	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
-	lotID := params.ByName("id")
+	lotID, err := strconv.ParseUint(params.ByName("id"), 10, 32)
+	if err != nil || lotID == 0 {
+		return apperror.BadRequestError("id must be an unsigned integer", "")
+	}
 
-	_, err := strconv.Atoi(lotID)
-	if err != nil {
-		return err
+	var userID uint64
+	var session string
+	if id, ok := r.Context().Value("user_id").(string); ok {
+		if userID, err = strconv.ParseUint(id, 10, 32); err != nil {
+			return err
+		}
+	} else {
+		session = sessionID(w, r)
 	}
 
-	lot, err := h.LotService.GetByLotID(r.Context(), lotID)
+	lot, err := h.LotService.ViewLot(r.Context(), uint(lotID), uint(userID), session)
 	if err != nil {
 		return err
 	}
@@ -189,9 +226,11 @@ func (h *Handler) CreateLot(w http.ResponseWriter, r *http.Request) error {
 // UpdateLot godoc
 //
 //	@Summary		Update lot price
-//	@Description	Get lots created during last 7 days.
+//	@Description	changes price of the lot, the change is saved as a version of the lot, see /lots/lot/{id}/history.
+//	@Description	Available for the agent of the lot and managers of its organization.
 //	@Tags			lots
 //	@Accept 		json
+//	@Param			Token	header		string	true	"JWT token"
 //	@Param			id	path		int	true	"Lot ID"
 //	@Param			price	body		int	true	"new lot price"
 //	@Success		204
@@ -221,12 +260,16 @@ func (h *Handler) UpdateLot(w http.ResponseWriter, r *http.Request) error {
 	dto.ID = uint(lotID)
 
 	h.Logger.Info("getting user_id from req.context()")
-	userID, ok := r.Context().Value("user_id").(uint)
+	userID, ok := r.Context().Value("user_id").(string)
 	if !ok {
-		return fmt.Errorf("error with type of req.context value of key 'user_id', must be an unsigned integer")
+		return fmt.Errorf("error with type of req.context value of key 'user_id'")
+	}
+	createdByUserID, err := strconv.Atoi(userID)
+	if err != nil {
+		return err
 	}
 
-	dto.CreatedByUserID = userID
+	dto.CreatedByUserID = uint(createdByUserID)
 
 	err = h.LotService.Update(r.Context(), dto)
 	if err != nil {
@@ -270,27 +313,76 @@ func (h *Handler) DeleteLot(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
-// GetLastWeek godoc
+// GetStats godoc
 //
-//	@Summary		Show lots created during last 7 days.
-//	@Description	Get lots created during last 7 days.
+//	@Summary		Show statistics of lots
+//	@Description	Get statistics of lots selected by the same filters as lots search: count, prices and prices
+//	@Description	per m² with percentiles, distribution by rooms and districts and new lots per day.
+//	@Description	Responses have ETag, conditional requests of unchanged statistics get 304.
 //	@Tags			lots
 //	@Produce		json
-//	@Success		200	{array}		lot_service.Lot
+//	@Param 			estate_type query string false "filter by estate type"
+//	@Param 			rooms query string false "filter by rooms quantity"
+//	@Param 			city query string false "filter by city"
+//	@Param 			district query string false "filter by district"
+//	@Param 			city_id query int false "filter by canonical city, see /cities"
+//	@Param 			district_id query int false "filter by canonical district, see /cities/{id}/districts"
+//	@Param 			price query string false "filter by price in currency from query, lots in all currencies are compared"
+//	@Param 			price_period query string false "filter by period of price" Enums(month, day, sale)
+//	@Param 			currency query string false "ISO 4217 code of currency of prices, RUB by default"
+//	@Param 			created_at query string false "filter by date of creation"
+//	@Param 			floor query string false "filter by floor"
+//	@Param 			rental_mode query string false "filter by rental mode" Enums(long_term, short_term)
+//	@Param 			days query int false "days of new lots per day, 30 by default, 365 at most"
+//	@Param			If-None-Match	header		string	false	"ETag of the statistics"
+//	@Success		200	{object}	lot_service.LotStats
+//	@Success		304
 //	@Failure		400	{object}	apperror.AppError
-//	@Failure		404	{object}	apperror.AppError
 //	@Failure		418	{object}	apperror.AppError
-//	@Router			/lots/week [get]
-func (h *Handler) GetLastWeek(w http.ResponseWriter, r *http.Request) error {
+//	@Router			/lots/stats [get]
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) error {
 	w.Header().Set("Content-Type", "application/json")
 
-	h.Logger.Info("calculating date range and building query..")
-	dateAfter := time.Now().AddDate(0, 0, -7).Format("2006-01-02")
-	dateBefore := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	header := http.Header{}
+	if v := r.Header.Get("If-None-Match"); v != "" {
+		header.Set("If-None-Match", v)
+	}
+	stats, respHeader, err := h.LotService.GetLotStats(r.Context(), r.URL.RawQuery, header)
+	if err != nil {
+		return err
+	}
 
-	rQuery := fmt.Sprintf("created_at=%s:%s", dateAfter, dateBefore)
+	w.Header().Set("ETag", respHeader.Get("ETag"))
+	w.Header().Set("Cache-Control", respHeader.Get("Cache-Control"))
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(stats))
 
-	lots, err := h.LotService.GetWithFilter(r.Context(), rQuery)
+	return nil
+}
+
+// GetSimilar godoc
+//
+//	@Summary		Show similar lots
+//	@Description	Get lots similar to the lot by estate type, rooms, area, price, district and distance,
+//	@Description	the most similar first. Lots of the same city only are recommended.
+//	@Tags			lots
+//	@Produce		json
+//	@Param			id		path		int	true	"Lot ID"
+//	@Param 			limit	query		int	false	"number of lots, 10 by default"
+//	@Success		200	{array}		lot_service.SimilarLot
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/similar [get]
+func (h *Handler) GetSimilar(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	lotID, err := strconv.ParseUint(params.ByName("id"), 10, 32)
+	if err != nil || lotID == 0 {
+		return apperror.BadRequestError("id must be an unsigned integer", "")
+	}
+
+	lots, err := h.LotService.GetSimilarLots(r.Context(), uint(lotID), r.URL.Query())
 	if err != nil {
 		return err
 	}
@@ -300,3 +392,92 @@ func (h *Handler) GetLastWeek(w http.ResponseWriter, r *http.Request) error {
 
 	return nil
 }
+
+// RevealContact godoc
+//
+//	@Summary		Reveal contact of lot owner
+//	@Description	returns phone of the lot owner. Available only for users with verified phone,
+//	@Description	number of lots per day is limited. Every reveal is recorded.
+//	@Tags			lots
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Lot ID"
+//	@Success		200		{object}	user_service.Contact
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		429		{object}	apperror.AppError	"daily limit of reveals exceeded"
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/contact [post]
+func (h *Handler) RevealContact(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	h.Logger.Info("getting id from context..")
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	lotID := params.ByName("id")
+
+	if _, err := strconv.Atoi(lotID); err != nil {
+		return apperror.BadRequestError("id must be an unsigned integer", "")
+	}
+
+	h.Logger.Info("getting user_id from req.context()")
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		return fmt.Errorf("error with type of req.context value of key 'user_id'")
+	}
+	viewerID, err := strconv.Atoi(userID)
+	if err != nil {
+		return err
+	}
+
+	lotBytes, err := h.LotService.GetByLotID(r.Context(), lotID)
+	if err != nil {
+		return err
+	}
+	l := &lot_service.Lot{}
+	if err = json.Unmarshal(lotBytes, l); err != nil {
+		return fmt.Errorf("failed to unmarshal lot. error: %w", err)
+	}
+
+	contact, err := h.UserService.RevealContact(r.Context(), &user_service.RevealContactDTO{
+		ViewerID: uint(viewerID),
+		OwnerID:  l.CreatedByUserID,
+		LotID:    l.ID,
+	})
+	if err != nil {
+		return err
+	}
+	// analytics may miss the reveal, but the contact is revealed already
+	if err = h.LotService.RecordReveal(r.Context(), uint(viewerID), l.ID); err != nil {
+		h.Logger.Warnf("failed to record reveal of lot %d. error: %v", l.ID, err)
+	}
+
+	contactBytes, err := json.Marshal(contact)
+	if err != nil {
+		return fmt.Errorf("failed to marshal contact. error: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(contactBytes)
+
+	return nil
+}
+
+// sessionID returns session of anonymous visitor from cookie. Visitors without it get session derived
+// from their IP and user agent, so clients, which don't keep cookies, aren't counted on every request.
+func sessionID(w http.ResponseWriter, r *http.Request) string {
+	if c, err := r.Cookie(sessionCookie); err == nil && c.Value != "" {
+		return c.Value
+	}
+
+	sum := sha256.Sum256([]byte(handlers.ClientIP(r) + "\n" + r.UserAgent()))
+	id := hex.EncodeToString(sum[:16])
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   sessionMaxAge,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
@@ -0,0 +1,32 @@
+package lots
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSessionID(t *testing.T) {
+	request := func(remoteAddr, userAgent string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr, r.Header["User-Agent"] = remoteAddr, []string{userAgent}
+		return r
+	}
+
+	first := sessionID(httptest.NewRecorder(), request("10.0.0.1:5000", "curl/7.88"))
+	if first == "" {
+		t.Fatal("session must be started")
+	}
+	if again := sessionID(httptest.NewRecorder(), request("10.0.0.1:6000", "curl/7.88")); again != first {
+		t.Errorf("client without cookies must keep session, got %q and %q", first, again)
+	}
+	if other := sessionID(httptest.NewRecorder(), request("10.0.0.1:5000", "Mozilla/5.0")); other == first {
+		t.Error("clients with different user agents must have different sessions")
+	}
+
+	r := request("10.0.0.2:5000", "Mozilla/5.0")
+	r.AddCookie(&http.Cookie{Name: sessionCookie, Value: "kept"})
+	if kept := sessionID(httptest.NewRecorder(), r); kept != "kept" {
+		t.Errorf("session from cookie must be used, got %q", kept)
+	}
+}
@@ -0,0 +1,450 @@
+package messages
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	conversationsURL        = "/api/conversations"
+	singleConversationURL   = "/api/conversations/:id"
+	conversationMessagesURL = "/api/conversations/:id/messages"
+	conversationReadURL     = "/api/conversations/:id/read"
+	unreadMessagesURL       = "/api/messages/unread"
+	attachmentsURL          = "/api/messages/attachments"
+	singleAttachmentURL     = "/api/messages/attachments/:id"
+	blocksURL               = "/api/messages/blocks"
+	singleBlockURL          = "/api/messages/blocks/:id"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, conversationsURL, jwt.Middleware(apperror.Middleware(h.GetConversations)))
+	router.HandlerFunc(http.MethodPost, conversationsURL, jwt.Middleware(apperror.Middleware(h.StartConversation)))
+	router.HandlerFunc(http.MethodGet, singleConversationURL, jwt.Middleware(apperror.Middleware(h.GetConversation)))
+	router.HandlerFunc(http.MethodGet, conversationMessagesURL, jwt.Middleware(apperror.Middleware(h.GetMessages)))
+	router.HandlerFunc(http.MethodPost, conversationMessagesURL, jwt.Middleware(apperror.Middleware(h.SendMessage)))
+	router.HandlerFunc(http.MethodPut, conversationReadURL, jwt.Middleware(apperror.Middleware(h.MarkRead)))
+	router.HandlerFunc(http.MethodGet, unreadMessagesURL, jwt.Middleware(apperror.Middleware(h.GetUnread)))
+	router.HandlerFunc(http.MethodPost, attachmentsURL, jwt.Middleware(apperror.Middleware(h.UploadAttachment)))
+	router.HandlerFunc(http.MethodGet, singleAttachmentURL, jwt.Middleware(apperror.Middleware(h.GetAttachment)))
+	router.HandlerFunc(http.MethodGet, blocksURL, jwt.Middleware(apperror.Middleware(h.GetBlockedUsers)))
+	router.HandlerFunc(http.MethodPut, singleBlockURL, jwt.Middleware(apperror.Middleware(h.BlockUser)))
+	router.HandlerFunc(http.MethodDelete, singleBlockURL, jwt.Middleware(apperror.Middleware(h.UnblockUser)))
+}
+
+// GetConversations godoc
+//
+//	@Summary		Show conversations
+//	@Description	get conversations of the user from JWT, recently active first, with unread counts
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		200		{array}		lot_service.Conversation
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/conversations [get]
+func (h *Handler) GetConversations(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	conversations, err := h.LotService.GetConversations(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(conversations)
+	return nil
+}
+
+// StartConversation godoc
+//
+//	@Summary		Write to owner of the lot
+//	@Description	sends the first message about the lot to its owner. Existing conversation about the lot is reused.
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string							true	"JWT token"
+//	@Param			DTO		body		lot_service.StartConversationDTO	true	"message"
+//	@Success		201		{object}	lot_service.Message
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/conversations [post]
+func (h *Handler) StartConversation(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	dto := &lot_service.StartConversationDTO{}
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	m, err := h.LotService.StartConversation(r.Context(), userID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(m)
+	return nil
+}
+
+// GetConversation godoc
+//
+//	@Summary		Show conversation
+//	@Description	get conversation by its ID. Available only for its sides.
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Conversation ID"
+//	@Success		200		{object}	lot_service.Conversation
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/conversations/{id} [get]
+func (h *Handler) GetConversation(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	conversationID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	c, err := h.LotService.GetConversation(r.Context(), userID, conversationID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(c)
+	return nil
+}
+
+// GetMessages godoc
+//
+//	@Summary		Show messages
+//	@Description	get messages of the conversation, newest first. Use ID of the last received message as before
+//	@Description	to get older ones.
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Conversation ID"
+//	@Param			before	query		int		false	"return messages older than this one"
+//	@Param			limit	query		int		false	"max number of messages, 50 by default, 200 max"
+//	@Success		200		{array}		lot_service.Message
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/conversations/{id}/messages [get]
+func (h *Handler) GetMessages(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	conversationID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	messages, err := h.LotService.GetMessages(r.Context(), userID, conversationID, r.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(messages)
+	return nil
+}
+
+// SendMessage godoc
+//
+//	@Summary		Send message
+//	@Description	sends message to the other side of the conversation, unless it has blocked the user
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string						true	"JWT token"
+//	@Param			id		path		int							true	"Conversation ID"
+//	@Param			DTO		body		lot_service.SendMessageDTO	true	"message"
+//	@Success		201		{object}	lot_service.Message
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/conversations/{id}/messages [post]
+func (h *Handler) SendMessage(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	dto := &lot_service.SendMessageDTO{}
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	conversationID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	m, err := h.LotService.SendMessage(r.Context(), userID, conversationID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(m)
+	return nil
+}
+
+// MarkRead godoc
+//
+//	@Summary		Mark conversation as read
+//	@Description	marks all messages sent to the user in the conversation as read, the sender sees read_at of them
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Conversation ID"
+//	@Success		200		{object}	lot_service.ReadMessages
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/conversations/{id}/read [put]
+func (h *Handler) MarkRead(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	conversationID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	read, err := h.LotService.MarkRead(r.Context(), userID, conversationID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(read)
+	return nil
+}
+
+// GetUnread godoc
+//
+//	@Summary		Show number of unread messages
+//	@Description	get number of unread messages of the user from JWT
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		200		{object}	lot_service.UnreadMessages
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/messages/unread [get]
+func (h *Handler) GetUnread(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	unread, err := h.LotService.GetUnread(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(unread)
+	return nil
+}
+
+// UploadAttachment godoc
+//
+//	@Summary		Upload attachment
+//	@Description	uploads file, which can be sent with a message. Body of the request is the file,
+//	@Description	its type is taken from Content-Type header. Attachment must be sent during a day.
+//	@Tags			messages
+//	@Accept			application/octet-stream
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			name	query		string	true	"file name"
+//	@Success		201		{object}	lot_service.Attachment
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/messages/attachments [post]
+func (h *Handler) UploadAttachment(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	defer r.Body.Close()
+	a, err := h.LotService.UploadAttachment(r.Context(), userID, r.URL.Query().Get("name"),
+		r.Header.Get("Content-Type"), r.Body)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(a)
+	return nil
+}
+
+// GetAttachment godoc
+//
+//	@Summary		Download attachment
+//	@Description	get file of the attachment. Available for uploader and sides of conversation it was sent to.
+//	@Tags			messages
+//	@Produce		application/octet-stream
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Attachment ID"
+//	@Success		200		{file}		file
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/messages/attachments/{id} [get]
+func (h *Handler) GetAttachment(w http.ResponseWriter, r *http.Request) error {
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	attachmentID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	data, header, err := h.LotService.GetAttachment(r.Context(), userID, attachmentID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	w.Header().Set("Content-Type", header.Get("Content-Type"))
+	w.Header().Set("Content-Disposition", header.Get("Content-Disposition"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+	return nil
+}
+
+// GetBlockedUsers godoc
+//
+//	@Summary		Show blocked users
+//	@Description	get users blocked by the user from JWT
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		200		{array}		lot_service.BlockedUser
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/messages/blocks [get]
+func (h *Handler) GetBlockedUsers(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	blocks, err := h.LotService.GetBlockedUsers(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(blocks)
+	return nil
+}
+
+// BlockUser godoc
+//
+//	@Summary		Block user
+//	@Description	forbids the user to write to the user from JWT
+//	@Tags			messages
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"ID of user to block"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/messages/blocks/{id} [put]
+func (h *Handler) BlockUser(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	blockedID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.BlockUser(r.Context(), userID, blockedID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// UnblockUser godoc
+//
+//	@Summary		Unblock user
+//	@Description	allows blocked user to write to the user from JWT again
+//	@Tags			messages
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"ID of blocked user"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/messages/blocks/{id} [delete]
+func (h *Handler) UnblockUser(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	blockedID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.UnblockUser(r.Context(), userID, blockedID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
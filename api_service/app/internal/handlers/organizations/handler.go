@@ -0,0 +1,340 @@
+package organizations
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	organizationsURL      = "/api/organizations"
+	singleOrganizationURL = "/api/organizations/:id"
+	organizationLotsURL   = "/api/organizations/:id/lots"
+	organizationMemberURL = "/api/organizations/:id/members/:user_id"
+	lotAgentURL           = "/api/lots/lot/:id/agent"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, organizationsURL, jwt.Middleware(apperror.Middleware(h.GetOrganizations)))
+	router.HandlerFunc(http.MethodPost, organizationsURL, jwt.Middleware(apperror.Middleware(h.CreateOrganization)))
+	router.HandlerFunc(http.MethodGet, singleOrganizationURL, apperror.Middleware(h.GetOrganization))
+	router.HandlerFunc(http.MethodPatch, singleOrganizationURL, jwt.Middleware(apperror.Middleware(h.UpdateOrganization)))
+	router.HandlerFunc(http.MethodGet, organizationLotsURL, apperror.Middleware(h.GetLots))
+	router.HandlerFunc(http.MethodPut, organizationMemberURL, jwt.Middleware(apperror.Middleware(h.SetMember)))
+	router.HandlerFunc(http.MethodDelete, organizationMemberURL, jwt.Middleware(apperror.Middleware(h.RemoveMember)))
+	router.HandlerFunc(http.MethodPut, lotAgentURL, jwt.Middleware(apperror.Middleware(h.TransferLot)))
+}
+
+// GetOrganizations godoc
+//
+//	@Summary		Show my organizations
+//	@Description	get organizations, which the user is a member of.
+//	@Tags			organizations
+//	@Produce		json
+//	@Param			Token	header	string	true	"JWT token"
+//	@Success		200		{array}	lot_service.Organization
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/organizations [get]
+func (h *Handler) GetOrganizations(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	organizations, err := h.LotService.GetOrganizations(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(organizations)
+	return nil
+}
+
+// CreateOrganization godoc
+//
+//	@Summary		Create organization
+//	@Description	creates agency with the user as its owner.
+//	@Tags			organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token			header		string						true	"JWT token"
+//	@Param			organization	body		lot_service.OrganizationDTO	true	"organization"
+//	@Success		201				{object}	lot_service.Organization
+//	@Failure		400				{object}	apperror.AppError
+//	@Failure		418				{object}	apperror.AppError
+//	@Router			/organizations [post]
+func (h *Handler) CreateOrganization(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.OrganizationDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	organization, err := h.LotService.CreateOrganization(r.Context(), userID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(organization)
+	return nil
+}
+
+// GetOrganization godoc
+//
+//	@Summary		Show organization
+//	@Description	get public page of organization with its members and number of lots.
+//	@Tags			organizations
+//	@Produce		json
+//	@Param			id	path		int	true	"Organization ID"
+//	@Success		200	{object}	lot_service.OrganizationPage
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/organizations/{id} [get]
+func (h *Handler) GetOrganization(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	organizationID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	page, err := h.LotService.GetOrganization(r.Context(), organizationID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+	return nil
+}
+
+// UpdateOrganization godoc
+//
+//	@Summary		Update organization
+//	@Description	replaces public data of organization. Owners only.
+//	@Tags			organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token			header		string						true	"JWT token"
+//	@Param			id				path		int							true	"Organization ID"
+//	@Param			organization	body		lot_service.OrganizationDTO	true	"organization"
+//	@Success		200				{object}	lot_service.Organization
+//	@Failure		400				{object}	apperror.AppError
+//	@Failure		403				{object}	apperror.AppError
+//	@Failure		404				{object}	apperror.AppError
+//	@Failure		418				{object}	apperror.AppError
+//	@Router			/organizations/{id} [patch]
+func (h *Handler) UpdateOrganization(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	organizationID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.OrganizationDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	organization, err := h.LotService.UpdateOrganization(r.Context(), userID, organizationID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(organization)
+	return nil
+}
+
+// GetLots godoc
+//
+//	@Summary		Show lots of organization
+//	@Description	get lots owned by organization, the newest first.
+//	@Tags			organizations
+//	@Produce		json
+//	@Param			id	path		int	true	"Organization ID"
+//	@Success		200	{array}		lot_service.Lot
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/organizations/{id}/lots [get]
+func (h *Handler) GetLots(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	organizationID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	lots, err := h.LotService.GetOrganizationLots(r.Context(), organizationID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(lots)
+	return nil
+}
+
+// SetMember godoc
+//
+//	@Summary		Set member of organization
+//	@Description	adds the user to organization or changes role of the member. Owners manage all members,
+//	@Description	managers manage agents only. Organization must keep at least one owner.
+//	@Description	The member is notified with membership event.
+//	@Tags			organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string					true	"JWT token"
+//	@Param			id		path		int						true	"Organization ID"
+//	@Param			user_id	path		int						true	"User ID"
+//	@Param			member	body		lot_service.SetMemberDTO	true	"role"
+//	@Success		200		{object}	lot_service.OrganizationMember
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/organizations/{id}/members/{user_id} [put]
+func (h *Handler) SetMember(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	organizationID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+	memberID, err := handlers.IDFromParams(r, "user_id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.SetMemberDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	member, err := h.LotService.SetOrganizationMember(r.Context(), userID, organizationID, memberID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(member)
+	return nil
+}
+
+// RemoveMember godoc
+//
+//	@Summary		Remove member of organization
+//	@Description	removes the member from organization. Members can leave by themselves, owners remove all
+//	@Description	members, managers remove agents only. Lots of the member must be transferred first.
+//	@Tags			organizations
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"Organization ID"
+//	@Param			user_id	path	int		true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		403	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		409	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/organizations/{id}/members/{user_id} [delete]
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) error {
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	organizationID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+	memberID, err := handlers.IDFromParams(r, "user_id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.RemoveOrganizationMember(r.Context(), userID, organizationID, memberID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// TransferLot godoc
+//
+//	@Summary		Transfer lot to another agent
+//	@Description	assigns lot of organization to another member of the organization.
+//	@Description	Agent of the lot, owners and managers of the organization only.
+//	@Tags			organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string						true	"JWT token"
+//	@Param			id			path		int							true	"Lot ID"
+//	@Param			transfer	body		lot_service.TransferLotDTO	true	"new agent"
+//	@Success		200			{object}	lot_service.Lot
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		403			{object}	apperror.AppError
+//	@Failure		404			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/agent [put]
+func (h *Handler) TransferLot(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.TransferLotDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	lot, err := h.LotService.TransferLot(r.Context(), userID, lotID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(lot)
+	return nil
+}
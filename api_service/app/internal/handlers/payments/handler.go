@@ -0,0 +1,392 @@
+package payments
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"io"
+	"net/http"
+)
+
+const (
+	bookingPaymentsURL = "/api/bookings/:id/payments"
+	singlePaymentURL   = "/api/payments/:id"
+	paymentRefundsURL  = "/api/payments/:id/refunds"
+	paymentReceiptURL  = "/api/payments/:id/receipt"
+	paymentSimulateURL = "/api/payments/:id/simulation"
+	ledgerURL          = "/api/ledger"
+	paymentWebhookURL  = "/api/payment-webhooks/:provider"
+	maxWebhookSize     = 64 << 10
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, bookingPaymentsURL, jwt.Middleware(apperror.Middleware(h.GetPayments)))
+	router.HandlerFunc(http.MethodPost, bookingPaymentsURL, jwt.Middleware(apperror.Middleware(h.CreatePayment)))
+	router.HandlerFunc(http.MethodGet, singlePaymentURL, jwt.Middleware(apperror.Middleware(h.GetPayment)))
+	router.HandlerFunc(http.MethodGet, paymentRefundsURL, jwt.Middleware(apperror.Middleware(h.GetRefunds)))
+	router.HandlerFunc(http.MethodPost, paymentRefundsURL, jwt.Middleware(apperror.Middleware(h.CreateRefund)))
+	router.HandlerFunc(http.MethodGet, paymentReceiptURL, jwt.Middleware(apperror.Middleware(h.GetReceipt)))
+	router.HandlerFunc(http.MethodPost, paymentSimulateURL, jwt.Middleware(apperror.Middleware(h.Simulate)))
+	router.HandlerFunc(http.MethodGet, ledgerURL, jwt.Middleware(apperror.Middleware(h.GetLedger)))
+	// webhooks come from payment providers, they are checked by signature instead of token
+	router.HandlerFunc(http.MethodPost, paymentWebhookURL, apperror.Middleware(h.HandleWebhook))
+}
+
+// GetPayments godoc
+//
+//	@Summary		Show payments of booking
+//	@Description	get payments of the booking, the latest first. Parties only.
+//	@Tags			payments
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Booking ID"
+//	@Success		200		{array}		lot_service.Payment
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/bookings/{id}/payments [get]
+func (h *Handler) GetPayments(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	bookingID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	payments, err := h.LotService.GetPayments(r.Context(), userID, bookingID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(payments)
+	return nil
+}
+
+// CreatePayment godoc
+//
+//	@Summary		Pay for booking
+//	@Description	makes payment of deposit or monthly rent of accepted booking. The renter pays it on checkout page
+//	@Description	of the payment provider, the service never sees card data. Both parties are notified with payment
+//	@Description	event, when the payment succeeds or fails. Renter only.
+//	@Tags			payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string						true	"JWT token"
+//	@Param			id		path		int							true	"Booking ID"
+//	@Param			payment	body		lot_service.CreatePaymentDTO	true	"payment"
+//	@Success		201		{object}	lot_service.Payment
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/bookings/{id}/payments [post]
+func (h *Handler) CreatePayment(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	bookingID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.CreatePaymentDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	payment, err := h.LotService.CreatePayment(r.Context(), userID, bookingID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(payment)
+	return nil
+}
+
+// GetPayment godoc
+//
+//	@Summary		Show payment
+//	@Description	get payment. Parties only.
+//	@Tags			payments
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Payment ID"
+//	@Success		200		{object}	lot_service.Payment
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/payments/{id} [get]
+func (h *Handler) GetPayment(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	paymentID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	payment, err := h.LotService.GetPayment(r.Context(), userID, paymentID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(payment)
+	return nil
+}
+
+// GetRefunds godoc
+//
+//	@Summary		Show refunds of payment
+//	@Description	get refunds of the payment. Parties only.
+//	@Tags			payments
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Payment ID"
+//	@Success		200		{array}		lot_service.Refund
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/payments/{id}/refunds [get]
+func (h *Handler) GetRefunds(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	paymentID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	refunds, err := h.LotService.GetRefunds(r.Context(), userID, paymentID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(refunds)
+	return nil
+}
+
+// CreateRefund godoc
+//
+//	@Summary		Refund payment
+//	@Description	returns part or all of succeeded payment to the payer, e.g. deposit after the stay.
+//	@Description	Requests with the same Idempotency-Key header make a single refund, so they can be safely
+//	@Description	retried. Both parties are notified. Payee only.
+//	@Tags			payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token			header		string						true	"JWT token"
+//	@Param			Idempotency-Key	header		string						false	"key of the refund, max 64 characters"
+//	@Param			id				path		int							true	"Payment ID"
+//	@Param			refund			body		lot_service.CreateRefundDTO	true	"refund"
+//	@Success		201				{object}	lot_service.Refund
+//	@Failure		400				{object}	apperror.AppError
+//	@Failure		403				{object}	apperror.AppError
+//	@Failure		404				{object}	apperror.AppError
+//	@Failure		409				{object}	apperror.AppError
+//	@Failure		418				{object}	apperror.AppError
+//	@Router			/payments/{id}/refunds [post]
+func (h *Handler) CreateRefund(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	paymentID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.CreateRefundDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	refund, err := h.LotService.CreateRefund(r.Context(), userID, paymentID, r.Header.Get("Idempotency-Key"), dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(refund)
+	return nil
+}
+
+// GetReceipt godoc
+//
+//	@Summary		Download receipt
+//	@Description	get receipt of succeeded payment with its refunds as HTML page or PDF document. Parties only.
+//	@Tags			payments
+//	@Produce		html
+//	@Produce		application/pdf
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Payment ID"
+//	@Param			format	query		string	false	"html (default) or pdf"	Enums(html, pdf)
+//	@Success		200		{string}	string	"receipt"
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/payments/{id}/receipt [get]
+func (h *Handler) GetReceipt(w http.ResponseWriter, r *http.Request) error {
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	paymentID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	doc, header, err := h.LotService.GetReceipt(r.Context(), userID, paymentID, r.URL.Query().Get("format"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	w.Header().Set("Content-Type", header.Get("Content-Type"))
+	w.Header().Set("Content-Disposition", header.Get("Content-Disposition"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc)
+	return nil
+}
+
+// Simulate godoc
+//
+//	@Summary		Simulate checkout
+//	@Description	finishes checkout of pending payment as if the renter paid or abandoned it on checkout page.
+//	@Description	Available with mock payment provider only, the result is delivered as signed webhook. Payer only.
+//	@Tags			payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string							true	"JWT token"
+//	@Param			id			path		int								true	"Payment ID"
+//	@Param			simulation	body		lot_service.SimulatePaymentDTO	true	"outcome"
+//	@Success		200			{object}	lot_service.Payment
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		403			{object}	apperror.AppError
+//	@Failure		404			{object}	apperror.AppError
+//	@Failure		409			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/payments/{id}/simulation [post]
+func (h *Handler) Simulate(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	paymentID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.SimulatePaymentDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	payment, err := h.LotService.SimulatePayment(r.Context(), userID, paymentID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(payment)
+	return nil
+}
+
+// GetLedger godoc
+//
+//	@Summary		Show ledger
+//	@Description	get movements of money of the user: payments and refunds, the oldest first.
+//	@Tags			payments
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			after	query		int		false	"return entries after this one"
+//	@Param			limit	query		int		false	"max entries, 100 by default, up to 1000"
+//	@Success		200		{array}		lot_service.LedgerEntry
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/ledger [get]
+func (h *Handler) GetLedger(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	entries, err := h.LotService.GetLedger(r.Context(), userID, r.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(entries)
+	return nil
+}
+
+// HandleWebhook godoc
+//
+//	@Summary		Payment webhook
+//	@Description	receives events of the payment provider. Webhooks are checked by signature of the provider,
+//	@Description	repeated events are applied once.
+//	@Tags			payments
+//	@Accept			json
+//	@Param			provider	path	string	true	"payment provider"	Enums(mock)
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		401	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Router			/payment-webhooks/{provider} [post]
+func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	defer r.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookSize+1))
+	if err != nil {
+		return apperror.BadRequestError("failed to read webhook", "")
+	}
+	if len(body) > maxWebhookSize {
+		return apperror.BadRequestError("webhook is too large", "")
+	}
+
+	if err = h.LotService.HandlePaymentWebhook(r.Context(), params.ByName("provider"), r.Header, body); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,434 @@
+package reference
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+	"time"
+)
+
+const (
+	referenceURL      = "/api/reference"
+	adminReferenceURL = "/api/admin/reference"
+	estateTypesURL    = "/api/admin/reference/estate-types"
+	estateTypeURL     = "/api/admin/reference/estate-types/:id"
+	citiesURL         = "/api/admin/reference/cities"
+	cityURL           = "/api/admin/reference/cities/:id"
+	cityDistrictsURL  = "/api/admin/reference/cities/:id/districts"
+	districtURL       = "/api/admin/reference/districts/:id"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, referenceURL, apperror.Middleware(h.GetReference))
+	router.HandlerFunc(http.MethodGet, adminReferenceURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.GetAll))))
+	router.HandlerFunc(http.MethodPost, estateTypesURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.CreateEstateType))))
+	router.HandlerFunc(http.MethodPut, estateTypeURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.UpdateEstateType))))
+	router.HandlerFunc(http.MethodDelete, estateTypeURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.DeleteEstateType))))
+	router.HandlerFunc(http.MethodPost, citiesURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.CreateCity))))
+	router.HandlerFunc(http.MethodPut, cityURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.UpdateCity))))
+	router.HandlerFunc(http.MethodDelete, cityURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.DeleteCity))))
+	router.HandlerFunc(http.MethodPost, cityDistrictsURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.CreateDistrict))))
+	router.HandlerFunc(http.MethodPut, districtURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.UpdateDistrict))))
+	router.HandlerFunc(http.MethodDelete, districtURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.DeleteDistrict))))
+}
+
+// GetReference godoc
+//
+//	@Summary		Show reference data
+//	@Description	get estate types, cities and districts for lists of forms and filters with names in the locale.
+//	@Description	Untranslated names are in Russian. Responses have ETag, conditional requests of unchanged
+//	@Description	reference get 304.
+//	@Tags			reference
+//	@Produce		json
+//	@Param			locale			query		string	false	"locale of names, e.g. en or en-US, ru by default"
+//	@Param			If-None-Match	header		string	false	"ETag of the reference"
+//	@Success		200				{object}	lot_service.Reference
+//	@Success		304
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/reference [get]
+func (h *Handler) GetReference(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	header := http.Header{}
+	if v := r.Header.Get("If-None-Match"); v != "" {
+		header.Set("If-None-Match", v)
+	}
+	ref, respHeader, err := h.LotService.GetReference(r.Context(), r.URL.Query().Get("locale"), header)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", respHeader.Get("ETag"))
+	w.Header().Set("Cache-Control", respHeader.Get("Cache-Control"))
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(ref))
+
+	return nil
+}
+
+// GetAll godoc
+//
+//	@Summary		Show reference data for admins
+//	@Description	get estate types, cities and districts with names in all locales. Admins only.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		200		{object}	lot_service.Reference
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/admin/reference [get]
+func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	ref, err := h.LotService.GetAllReference(r.Context())
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(ref)
+	return nil
+}
+
+// CreateEstateType godoc
+//
+//	@Summary		Create estate type
+//	@Description	adds type of estate, lots of which can be created. Admins only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string						true	"JWT token"
+//	@Param			estate_type	body		lot_service.EstateTypeDTO	true	"estate type"
+//	@Success		201			{object}	lot_service.EstateType
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		403			{object}	apperror.AppError
+//	@Failure		409			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/admin/reference/estate-types [post]
+func (h *Handler) CreateEstateType(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	dto := &lot_service.EstateTypeDTO{}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	t, err := h.LotService.CreateEstateType(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(t)
+	return nil
+}
+
+// UpdateEstateType godoc
+//
+//	@Summary		Update estate type
+//	@Description	changes position and names of estate type, its code is kept. Admins only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string						true	"JWT token"
+//	@Param			id			path		int							true	"Estate type ID"
+//	@Param			estate_type	body		lot_service.EstateTypeDTO	true	"estate type"
+//	@Success		200			{object}	lot_service.EstateType
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		403			{object}	apperror.AppError
+//	@Failure		404			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/admin/reference/estate-types/{id} [put]
+func (h *Handler) UpdateEstateType(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.EstateTypeDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	t, err := h.LotService.UpdateEstateType(r.Context(), id, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(t)
+	return nil
+}
+
+// DeleteEstateType godoc
+//
+//	@Summary		Delete estate type
+//	@Description	deletes estate type without lots. Admins only.
+//	@Tags			admin
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"Estate type ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		403	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		409	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/admin/reference/estate-types/{id} [delete]
+func (h *Handler) DeleteEstateType(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.DeleteEstateType(r.Context(), id); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// CreateCity godoc
+//
+//	@Summary		Create city
+//	@Description	adds canonical city, lots with any spelling of its name are resolved to it. Admins only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string						true	"JWT token"
+//	@Param			city	body		lot_service.ReferenceNameDTO	true	"city"
+//	@Success		201		{object}	lot_service.City
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/admin/reference/cities [post]
+func (h *Handler) CreateCity(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	dto := &lot_service.ReferenceNameDTO{}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	c, err := h.LotService.CreateCity(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(c)
+	return nil
+}
+
+// UpdateCity godoc
+//
+//	@Summary		Update city
+//	@Description	renames city with its lots and replaces its localized names. Admins only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string						true	"JWT token"
+//	@Param			id		path		int							true	"City ID"
+//	@Param			city	body		lot_service.ReferenceNameDTO	true	"city"
+//	@Success		200		{object}	lot_service.City
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/admin/reference/cities/{id} [put]
+func (h *Handler) UpdateCity(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.ReferenceNameDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	c, err := h.LotService.UpdateCity(r.Context(), id, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(c)
+	return nil
+}
+
+// DeleteCity godoc
+//
+//	@Summary		Delete city
+//	@Description	deletes city without lots along with its districts. Admins only.
+//	@Tags			admin
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"City ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		403	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		409	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/admin/reference/cities/{id} [delete]
+func (h *Handler) DeleteCity(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.DeleteCity(r.Context(), id); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// CreateDistrict godoc
+//
+//	@Summary		Create district
+//	@Description	adds canonical district to the city. Admins only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string						true	"JWT token"
+//	@Param			id			path		int							true	"City ID"
+//	@Param			district	body		lot_service.ReferenceNameDTO	true	"district"
+//	@Success		201			{object}	lot_service.District
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		403			{object}	apperror.AppError
+//	@Failure		404			{object}	apperror.AppError
+//	@Failure		409			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/admin/reference/cities/{id}/districts [post]
+func (h *Handler) CreateDistrict(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	cityID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.ReferenceNameDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	d, err := h.LotService.CreateDistrict(r.Context(), cityID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(d)
+	return nil
+}
+
+// UpdateDistrict godoc
+//
+//	@Summary		Update district
+//	@Description	renames district with its lots and replaces its localized names. Admins only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string						true	"JWT token"
+//	@Param			id			path		int							true	"District ID"
+//	@Param			district	body		lot_service.ReferenceNameDTO	true	"district"
+//	@Success		200			{object}	lot_service.District
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		403			{object}	apperror.AppError
+//	@Failure		404			{object}	apperror.AppError
+//	@Failure		409			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/admin/reference/districts/{id} [put]
+func (h *Handler) UpdateDistrict(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.ReferenceNameDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	d, err := h.LotService.UpdateDistrict(r.Context(), id, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(d)
+	return nil
+}
+
+// DeleteDistrict godoc
+//
+//	@Summary		Delete district
+//	@Description	deletes district without lots. Admins only.
+//	@Tags			admin
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"District ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		403	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		409	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/admin/reference/districts/{id} [delete]
+func (h *Handler) DeleteDistrict(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.DeleteDistrict(r.Context(), id); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,141 @@
+package rentals
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	rentalURL = "/api/lots/lot/:id/rental"
+	quoteURL  = "/api/lots/lot/:id/quote"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, rentalURL, apperror.Middleware(h.GetRental))
+	router.HandlerFunc(http.MethodPut, rentalURL, jwt.Middleware(apperror.Middleware(h.SetRental)))
+	router.HandlerFunc(http.MethodGet, quoteURL, apperror.Middleware(h.Quote))
+}
+
+// GetRental godoc
+//
+//	@Summary		Show rental modes of the lot
+//	@Description	get long-term and short-term rental modes of the lot with their seasonal prices.
+//	@Description	Lots without modes are rented in the mode of their price period at their price,
+//	@Description	lots for sale have no modes.
+//	@Tags			rental
+//	@Produce		json
+//	@Param			id	path		int	true	"Lot ID"
+//	@Success		200	{object}	lot_service.Rental
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/rental [get]
+func (h *Handler) GetRental(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	rental, err := h.LotService.GetRental(r.Context(), lotID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(rental)
+	return nil
+}
+
+// SetRental godoc
+//
+//	@Summary		Set rental modes of the lot
+//	@Description	replaces rental modes and seasonal prices of the lot. Available for the agent of the lot
+//	@Description	and managers of its organization.
+//	@Description	Lots for sale can't be rented.
+//	@Tags			rental
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string						true	"JWT token"
+//	@Param			id		path		int							true	"Lot ID"
+//	@Param			DTO		body		lot_service.SetRentalDTO	true	"rental modes"
+//	@Success		200		{object}	lot_service.Rental
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/rental [put]
+func (h *Handler) SetRental(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	dto := &lot_service.SetRentalDTO{}
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	rental, err := h.LotService.SetRental(r.Context(), userID, lotID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(rental)
+	return nil
+}
+
+// Quote godoc
+//
+//	@Summary		Show price of the stay
+//	@Description	get total price of the stay in the lot. Short-term stays are priced by nights, long-term
+//	@Description	stays by full months and the rest days at 1/30 of monthly price. Nights and months are priced
+//	@Description	by seasons they start in, cleaning fee is added once.
+//	@Tags			rental
+//	@Produce		json
+//	@Param			id			path		int		true	"Lot ID"
+//	@Param			mode		query		string	true	"rental mode"	Enums(long_term, short_term)
+//	@Param			check_in	query		string	true	"e.g. 2026-11-01"
+//	@Param			check_out	query		string	true	"e.g. 2026-11-07"
+//	@Success		200			{object}	lot_service.Stay
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		404			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/quote [get]
+func (h *Handler) Quote(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	stay, err := h.LotService.QuoteStay(r.Context(), lotID, r.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(stay)
+	return nil
+}
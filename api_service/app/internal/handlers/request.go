@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// UserIDFromContext returns id of the user, which is put to request context by JWT middleware.
+func UserIDFromContext(r *http.Request) (uint, error) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		return 0, fmt.Errorf("error with type of req.context value of key 'user_id'")
+	}
+
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// IDFromParams returns id from path parameter with the given name.
+func IDFromParams(r *http.Request, name string) (uint, error) {
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	id, err := strconv.Atoi(params.ByName(name))
+	if err != nil || id <= 0 {
+		return 0, apperror.BadRequestError(name+" must be an unsigned integer", "")
+	}
+	return uint(id), nil
+}
+
+// ClientIP returns address of the client without port.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,293 @@
+package reviews
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+	"net/url"
+)
+
+const (
+	reviewsURL         = "/api/reviews"
+	singleReviewURL    = "/api/reviews/:id"
+	reviewReplyURL     = "/api/reviews/:id/reply"
+	reviewReportsURL   = "/api/reviews/:id/reports"
+	reportedReviewsURL = "/api/admin/reviews"
+	moderatedReviewURL = "/api/admin/reviews/:id"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, reviewsURL, apperror.Middleware(h.GetReviews))
+	router.HandlerFunc(http.MethodPost, reviewsURL, jwt.Middleware(apperror.Middleware(h.CreateReview)))
+	router.HandlerFunc(http.MethodGet, singleReviewURL, apperror.Middleware(h.GetReview))
+	router.HandlerFunc(http.MethodPut, reviewReplyURL, jwt.Middleware(apperror.Middleware(h.Reply)))
+	router.HandlerFunc(http.MethodPost, reviewReportsURL, jwt.Middleware(apperror.Middleware(h.Report)))
+	router.HandlerFunc(http.MethodGet, reportedReviewsURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.GetReported))))
+	router.HandlerFunc(http.MethodPut, moderatedReviewURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.Moderate))))
+}
+
+// GetReviews godoc
+//
+//	@Summary		Show reviews
+//	@Description	get visible reviews of the lot or of all lots of the landlord, newest first.
+//	@Description	Either lot_id or landlord_id is required.
+//	@Tags			reviews
+//	@Produce		json
+//	@Param			lot_id		query		int	false	"ID of the lot"
+//	@Param			landlord_id	query		int	false	"ID of the landlord"
+//	@Success		200			{array}		lot_service.Review
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/reviews [get]
+func (h *Handler) GetReviews(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := url.Values{}
+	for _, name := range []string{"lot_id", "landlord_id"} {
+		if v := r.URL.Query().Get(name); v != "" {
+			query.Set(name, v)
+		}
+	}
+
+	reviews, err := h.LotService.GetReviews(r.Context(), query)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(reviews)
+	return nil
+}
+
+// CreateReview godoc
+//
+//	@Summary		Review completed booking
+//	@Description	leaves review about the lot and its landlord. Only the renter can review accepted booking
+//	@Description	after check out, once per booking.
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string						true	"JWT token"
+//	@Param			review	body		lot_service.CreateReviewDTO	true	"review"
+//	@Success		201		{object}	lot_service.Review
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/reviews [post]
+func (h *Handler) CreateReview(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.CreateReviewDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	review, err := h.LotService.CreateReview(r.Context(), userID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(review)
+	return nil
+}
+
+// GetReview godoc
+//
+//	@Summary		Show review
+//	@Description	get visible review by its ID
+//	@Tags			reviews
+//	@Produce		json
+//	@Param			id	path		int	true	"Review ID"
+//	@Success		200	{object}	lot_service.Review
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/reviews/{id} [get]
+func (h *Handler) GetReview(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	reviewID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	review, err := h.LotService.GetReview(r.Context(), reviewID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(review)
+	return nil
+}
+
+// Reply godoc
+//
+//	@Summary		Reply to review
+//	@Description	saves answer of the landlord from JWT to the review, the answer may be edited later
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string					true	"JWT token"
+//	@Param			id		path		int						true	"Review ID"
+//	@Param			reply	body		lot_service.ReplyDTO	true	"reply"
+//	@Success		200		{object}	lot_service.Review
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/reviews/{id}/reply [put]
+func (h *Handler) Reply(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	reviewID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.ReplyDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	review, err := h.LotService.ReplyToReview(r.Context(), userID, reviewID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(review)
+	return nil
+}
+
+// Report godoc
+//
+//	@Summary		Report review
+//	@Description	complains about abusive review. Review is hidden until moderation after complaints
+//	@Description	of several users.
+//	@Tags			reviews
+//	@Accept			json
+//	@Param			Token	header	string					true	"JWT token"
+//	@Param			id		path	int						true	"Review ID"
+//	@Param			report	body	lot_service.ReportDTO	true	"complaint"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/reviews/{id}/reports [post]
+func (h *Handler) Report(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	reviewID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.ReportDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	if err = h.LotService.ReportReview(r.Context(), userID, reviewID, dto); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetReported godoc
+//
+//	@Summary		Show reported reviews
+//	@Description	get reviews with complaints, hidden ones included, most reported first. Admins only.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		200		{array}		lot_service.Review
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/admin/reviews [get]
+func (h *Handler) GetReported(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	reviews, err := h.LotService.GetReportedReviews(r.Context())
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(reviews)
+	return nil
+}
+
+// Moderate godoc
+//
+//	@Summary		Moderate review
+//	@Description	hides abusive review or shows it again. Admins only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token		header		string							true	"JWT token"
+//	@Param			id			path		int								true	"Review ID"
+//	@Param			decision	body		lot_service.ModerateReviewDTO	true	"decision"
+//	@Success		200			{object}	lot_service.Review
+//	@Failure		400			{object}	apperror.AppError
+//	@Failure		403			{object}	apperror.AppError
+//	@Failure		404			{object}	apperror.AppError
+//	@Failure		418			{object}	apperror.AppError
+//	@Router			/admin/reviews/{id} [put]
+func (h *Handler) Moderate(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	reviewID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.ModerateReviewDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	review, err := h.LotService.ModerateReview(r.Context(), reviewID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(review)
+	return nil
+}
@@ -0,0 +1,108 @@
+package users
+
+import (
+	"context"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// secretUser is answer of user_service leaking everything it knows about the user.
+const secretUser = `{
+	"id": 1,
+	"username": "owner",
+	"given_name": "Given",
+	"family_name": "Family",
+	"email": "owner@example.com",
+	"phone": "+79990001122",
+	"password": "plain-password",
+	"encrypted_password": "$2a$10$hashhashhashhashhashha",
+	"role": "user",
+	"created_at": "2022-01-01T00:00:00Z"
+}`
+
+var (
+	credentials = []string{"plain-password", "$2a$", "hashhash", "password"}
+	contacts    = []string{"owner@example.com", "+79990001122", "email", "phone"}
+)
+
+type lotServiceStub struct {
+	lot_service.LotService
+}
+
+func (s *lotServiceStub) GetLandlordRating(context.Context, uint) (*lot_service.Rating, error) {
+	return &lot_service.Rating{}, nil
+}
+
+func TestUserContract(t *testing.T) {
+	var role string
+	userService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role = r.Header.Get("X-Requester-Role")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(secretUser))
+	}))
+	defer userService.Close()
+
+	logger := logging.GetLogger()
+	h := &Handler{
+		Logger:      logger,
+		UserService: user_service.NewService(userService.URL, "/users", logger),
+		LotService:  &lotServiceStub{},
+	}
+
+	tests := []struct {
+		name      string
+		handler   func(w http.ResponseWriter, r *http.Request) error
+		role      string
+		forbidden []string
+	}{
+		{
+			name:      "public",
+			handler:   h.GetUser,
+			forbidden: append(credentials, contacts...),
+		},
+		{
+			name:      "public requested by admin",
+			handler:   h.GetUser,
+			role:      user_service.RoleAdmin,
+			forbidden: append(credentials, contacts...),
+		},
+		{
+			name:      "admin",
+			handler:   h.GetUserAsAdmin,
+			role:      user_service.RoleAdmin,
+			forbidden: credentials,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			role = ""
+			ctx := context.WithValue(context.Background(), httprouter.ParamsKey,
+				httprouter.Params{{Key: "id", Value: "1"}})
+			if test.role != "" {
+				ctx = context.WithValue(ctx, "role", test.role)
+			}
+			request := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			recorder := httptest.NewRecorder()
+
+			if err := test.handler(recorder, request); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if role != test.role {
+				t.Fatalf("role of requester forwarded as %q, expected %q", role, test.role)
+			}
+			body := recorder.Body.String()
+			for _, secret := range test.forbidden {
+				if strings.Contains(body, secret) {
+					t.Fatalf("response %s must not contain %q", body, secret)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,380 @@
+package users
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"net/http"
+	"strconv"
+)
+
+const (
+	singleUserURL  = "/api/users/:id"
+	profileURL     = "/api/profile"
+	phoneURL       = "/api/profile/phone"
+	verifyURL      = "/api/profile/phone/verification"
+	totpURL        = "/api/profile/2fa"
+	totpConfirmURL = "/api/profile/2fa/confirmation"
+	adminUserURL   = "/api/admin/users/:id"
+	adminTOTPURL   = "/api/admin/users/:id/2fa"
+	adminLockURL   = "/api/admin/users/:id/lock"
+)
+
+type Handler struct {
+	Logger      logging.Logger
+	UserService user_service.UserService
+	LotService  lot_service.LotService
+}
+
+// PublicProfile model info
+// @Description public information about user with the user's rating as landlord.
+type PublicProfile struct {
+	*user_service.PublicUser
+	LandlordRating *lot_service.Rating `json:"landlord_rating,omitempty"` // absent, if lot service is unavailable
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, singleUserURL, apperror.Middleware(h.GetUser))
+	router.HandlerFunc(http.MethodGet, adminUserURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.GetUserAsAdmin))))
+	router.HandlerFunc(http.MethodGet, profileURL, jwt.Middleware(apperror.Middleware(h.GetProfile)))
+	router.HandlerFunc(http.MethodPut, phoneURL, jwt.Middleware(apperror.Middleware(h.SetPhone)))
+	router.HandlerFunc(http.MethodPut, verifyURL, jwt.Middleware(apperror.Middleware(h.VerifyPhone)))
+	router.HandlerFunc(http.MethodPut, totpURL, jwt.Middleware(apperror.Middleware(h.EnrollTOTP)))
+	router.HandlerFunc(http.MethodPut, totpConfirmURL, jwt.Middleware(apperror.Middleware(h.ConfirmTOTP)))
+	router.HandlerFunc(http.MethodDelete, adminTOTPURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.ResetTOTP))))
+	router.HandlerFunc(http.MethodDelete, adminLockURL,
+		jwt.Middleware(jwt.RequireRole(user_service.RoleAdmin, apperror.Middleware(h.Unlock))))
+}
+
+// GetUser godoc
+//
+//	@Summary		Show user by ID
+//	@Description	get public information about user and the user's rating as landlord. Contact data is never shown.
+//	@Tags			user
+//	@Produce		json
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	PublicProfile
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/users/{id} [get]
+func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	h.Logger.Info("getting id from context..")
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	userID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		return apperror.BadRequestError("id must be an unsigned integer", "")
+	}
+
+	u, err := h.UserService.GetPublic(r.Context(), uint(userID))
+	if err != nil {
+		return err
+	}
+
+	profile := &PublicProfile{PublicUser: u}
+	if profile.LandlordRating, err = h.LotService.GetLandlordRating(r.Context(), u.ID); err != nil {
+		h.Logger.Warnf("failed to get rating of user %d. error: %v", u.ID, err)
+	}
+
+	userBytes, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user. error: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(userBytes)
+
+	return nil
+}
+
+// GetProfile godoc
+//
+//	@Summary		Show profile
+//	@Description	get all information about user from JWT
+//	@Tags			user
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		200		{object}	user_service.User
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/profile [get]
+func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	u, err := h.UserService.GetProfile(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	userBytes, err := json.Marshal(u)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user. error: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(userBytes)
+
+	return nil
+}
+
+// SetPhone godoc
+//
+//	@Summary		Set phone
+//	@Description	sends verification code to the new phone of the user from JWT.
+//	@Description	Phone is saved in the profile only after it is verified.
+//	@Description	Codes are throttled per user and per phone, the delay grows with every code sent.
+//	@Tags			user
+//	@Accept			json
+//	@Param			Token	header	string				true	"JWT token"
+//	@Param			DTO		body	user_service.SetPhoneDTO	true	"phone"
+//	@Success		202
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		429	{object}	apperror.AppError	"code was sent recently, see Retry-After header"
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/profile/phone [put]
+func (h *Handler) SetPhone(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	var dto *user_service.SetPhoneDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	id, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.UserService.SetPhone(r.Context(), id, dto); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	return nil
+}
+
+// VerifyPhone godoc
+//
+//	@Summary		Verify phone
+//	@Description	confirms phone of the user from JWT with the code received via SMS
+//	@Tags			user
+//	@Accept			json
+//	@Param			Token	header	string					true	"JWT token"
+//	@Param			DTO		body	user_service.VerifyPhoneDTO	true	"code"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/profile/phone/verification [put]
+func (h *Handler) VerifyPhone(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	var dto *user_service.VerifyPhoneDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	id, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.UserService.VerifyPhone(r.Context(), id, dto); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// EnrollTOTP godoc
+//
+//	@Summary		Enroll two-factor authentication
+//	@Description	generates new secret for authenticator app. Two-factor authentication is enabled only after confirmation.
+//	@Tags			user
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Success		200		{object}	user_service.TOTPEnrollment
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/profile/2fa [put]
+func (h *Handler) EnrollTOTP(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := h.UserService.EnrollTOTP(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	enrollmentBytes, err := json.Marshal(enrollment)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(enrollmentBytes)
+	return nil
+}
+
+// ConfirmTOTP godoc
+//
+//	@Summary		Confirm two-factor authentication
+//	@Description	enables two-factor authentication with the first code from authenticator app and returns recovery codes
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string					true	"JWT token"
+//	@Param			DTO		body		user_service.TOTPCodeDTO	true	"code"
+//	@Success		200		{object}	user_service.RecoveryCodes
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/profile/2fa/confirmation [put]
+func (h *Handler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer r.Body.Close()
+	var dto *user_service.TOTPCodeDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	id, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	codes, err := h.UserService.ConfirmTOTP(r.Context(), id, dto)
+	if err != nil {
+		return err
+	}
+
+	codesBytes, err := json.Marshal(codes)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(codesBytes)
+	return nil
+}
+
+// GetUserAsAdmin godoc
+//
+//	@Summary		Show user to admin
+//	@Description	get information about user including contact data. Admins only.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"User ID"
+//	@Success		200		{object}	user_service.User
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/admin/users/{id} [get]
+func (h *Handler) GetUserAsAdmin(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	userID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		return apperror.BadRequestError("id must be an unsigned integer", "")
+	}
+
+	u, err := h.UserService.GetAsAdmin(r.Context(), uint(userID))
+	if err != nil {
+		return err
+	}
+
+	userBytes, err := json.Marshal(u)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user. error: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(userBytes)
+
+	return nil
+}
+
+// ResetTOTP godoc
+//
+//	@Summary		Reset two-factor authentication
+//	@Description	disables two-factor authentication of the user and removes recovery codes. Admins only.
+//	@Tags			admin
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		403	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/admin/users/{id}/2fa [delete]
+func (h *Handler) ResetTOTP(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	userID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		return apperror.BadRequestError("id must be an unsigned integer", "")
+	}
+
+	if err = h.UserService.ResetTOTP(r.Context(), uint(userID)); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Unlock godoc
+//
+//	@Summary		Unlock user
+//	@Description	removes lockout of the user caused by failed sign in attempts. Admins only.
+//	@Tags			admin
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		403	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/admin/users/{id}/lock [delete]
+func (h *Handler) Unlock(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	userID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		return apperror.BadRequestError("id must be an unsigned integer", "")
+	}
+
+	if err = h.UserService.Unlock(r.Context(), uint(userID)); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
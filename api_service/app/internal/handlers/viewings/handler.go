@@ -0,0 +1,376 @@
+package viewings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/lot_service"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/handlers"
+	"github.com/levelord1311/backendForSharedProject/api_service/internal/jwt"
+	"github.com/levelord1311/backendForSharedProject/api_service/pkg/logging"
+	"io"
+	"net/http"
+)
+
+const (
+	lotViewingsURL    = "/api/lots/lot/:id/viewings"
+	viewingsURL       = "/api/viewings"
+	singleViewingURL  = "/api/viewings/:id"
+	viewingInviteURL  = "/api/viewings/:id/invite.ics"
+	viewingBookingURL = "/api/viewings/:id/booking"
+)
+
+type Handler struct {
+	Logger     logging.Logger
+	LotService lot_service.LotService
+}
+
+func (h *Handler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, lotViewingsURL, apperror.Middleware(h.GetLotSlots))
+	router.HandlerFunc(http.MethodPost, lotViewingsURL, jwt.Middleware(apperror.Middleware(h.CreateSlot)))
+	router.HandlerFunc(http.MethodGet, viewingsURL, jwt.Middleware(apperror.Middleware(h.GetViewings)))
+	router.HandlerFunc(http.MethodGet, singleViewingURL, jwt.Middleware(apperror.Middleware(h.GetViewing)))
+	router.HandlerFunc(http.MethodPatch, singleViewingURL, jwt.Middleware(apperror.Middleware(h.UpdateSlot)))
+	router.HandlerFunc(http.MethodDelete, singleViewingURL, jwt.Middleware(apperror.Middleware(h.DeleteSlot)))
+	router.HandlerFunc(http.MethodGet, viewingInviteURL, jwt.Middleware(apperror.Middleware(h.GetInvite)))
+	router.HandlerFunc(http.MethodPut, viewingBookingURL, jwt.Middleware(apperror.Middleware(h.Book)))
+	router.HandlerFunc(http.MethodDelete, viewingBookingURL, jwt.Middleware(apperror.Middleware(h.Cancel)))
+}
+
+// GetLotSlots godoc
+//
+//	@Summary		Show viewing slots of the lot
+//	@Description	get upcoming free slots, when the lot can be viewed
+//	@Tags			viewings
+//	@Produce		json
+//	@Param			id	path		int	true	"Lot ID"
+//	@Success		200	{array}		lot_service.ViewingSlot
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/viewings [get]
+func (h *Handler) GetLotSlots(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	slots, err := h.LotService.GetLotViewingSlots(r.Context(), lotID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(slots)
+	return nil
+}
+
+// CreateSlot godoc
+//
+//	@Summary		Publish viewing slot
+//	@Description	publishes time, when the user from JWT is ready to show the lot. Available for the agent of the lot
+//	@Description	and managers of its organization. Slots of the user must not overlap.
+//	@Tags			viewings
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string								true	"JWT token"
+//	@Param			id		path		int									true	"Lot ID"
+//	@Param			slot	body		lot_service.CreateViewingSlotDTO	true	"slot"
+//	@Success		201		{object}	lot_service.ViewingSlot
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		403		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/lots/lot/{id}/viewings [post]
+func (h *Handler) CreateSlot(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.CreateViewingSlotDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	slot, err := h.LotService.CreateViewingSlot(r.Context(), userID, lotID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(slot)
+	return nil
+}
+
+// GetViewings godoc
+//
+//	@Summary		Show viewing appointments of the user
+//	@Description	get upcoming viewings booked by the user from JWT or, with party=landlord, booked slots
+//	@Description	of the user's lots
+//	@Tags			viewings
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			party	query		string	false	"renter (default) or landlord"	Enums(renter, landlord)
+//	@Success		200		{array}		lot_service.ViewingSlot
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/viewings [get]
+func (h *Handler) GetViewings(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	viewings, err := h.LotService.GetViewings(r.Context(), userID, r.URL.Query().Get("party"))
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(viewings)
+	return nil
+}
+
+// GetViewing godoc
+//
+//	@Summary		Show viewing appointment
+//	@Description	get appointment with calendar invite to one of its parties
+//	@Tags			viewings
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Slot ID"
+//	@Success		200		{object}	lot_service.ViewingAppointment
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/viewings/{id} [get]
+func (h *Handler) GetViewing(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	viewing, err := h.LotService.GetViewing(r.Context(), userID, slotID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(viewing)
+	return nil
+}
+
+// GetInvite godoc
+//
+//	@Summary		Download viewing invite
+//	@Description	get calendar invite of the appointment, so it can be added to any calendar
+//	@Tags			viewings
+//	@Produce		text/calendar
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Slot ID"
+//	@Success		200		{string}	string	"iCalendar"
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/viewings/{id}/invite.ics [get]
+func (h *Handler) GetInvite(w http.ResponseWriter, r *http.Request) error {
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	invite, err := h.LotService.GetViewingInvite(r.Context(), userID, slotID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8; method=REQUEST")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"viewing-%d.ics\"", slotID))
+	w.WriteHeader(http.StatusOK)
+	w.Write(invite)
+	return nil
+}
+
+// UpdateSlot godoc
+//
+//	@Summary		Reschedule viewing slot
+//	@Description	moves the slot of the user's lot to other time. Renter of booked slot gets updated invite.
+//	@Tags			viewings
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string								true	"JWT token"
+//	@Param			id		path		int									true	"Slot ID"
+//	@Param			slot	body		lot_service.UpdateViewingSlotDTO	true	"new time"
+//	@Success		200		{object}	lot_service.ViewingAppointment
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/viewings/{id} [patch]
+func (h *Handler) UpdateSlot(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.UpdateViewingSlotDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	viewing, err := h.LotService.UpdateViewingSlot(r.Context(), userID, slotID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(viewing)
+	return nil
+}
+
+// DeleteSlot godoc
+//
+//	@Summary		Delete viewing slot
+//	@Description	deletes the slot of the user's lot. Upcoming appointment in it is cancelled.
+//	@Tags			viewings
+//	@Param			Token	header	string	true	"JWT token"
+//	@Param			id		path	int		true	"Slot ID"
+//	@Success		204
+//	@Failure		400	{object}	apperror.AppError
+//	@Failure		404	{object}	apperror.AppError
+//	@Failure		418	{object}	apperror.AppError
+//	@Router			/viewings/{id} [delete]
+func (h *Handler) DeleteSlot(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = h.LotService.DeleteViewingSlot(r.Context(), userID, slotID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Book godoc
+//
+//	@Summary		Book viewing slot
+//	@Description	makes an appointment in the free slot. With from_slot_id the user's appointment is moved
+//	@Description	from that slot. Appointments of the user must not overlap, only one appointment per lot
+//	@Description	is allowed. Both parties get calendar invites.
+//	@Tags			viewings
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	header		string						true	"JWT token"
+//	@Param			id		path		int							true	"Slot ID"
+//	@Param			booking	body		lot_service.BookViewingDTO	false	"booking"
+//	@Success		200		{object}	lot_service.ViewingAppointment
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		409		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/viewings/{id}/booking [put]
+func (h *Handler) Book(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	dto := &lot_service.BookViewingDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil && !errors.Is(err, io.EOF) {
+		return apperror.BadRequestError("failed to decode data", "")
+	}
+
+	viewing, err := h.LotService.BookViewing(r.Context(), userID, slotID, dto)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(viewing)
+	return nil
+}
+
+// Cancel godoc
+//
+//	@Summary		Cancel viewing appointment
+//	@Description	cancels appointment by the renter or the landlord, the slot becomes free again.
+//	@Description	The other party gets calendar cancellation.
+//	@Tags			viewings
+//	@Produce		json
+//	@Param			Token	header		string	true	"JWT token"
+//	@Param			id		path		int		true	"Slot ID"
+//	@Success		200		{object}	lot_service.ViewingAppointment
+//	@Failure		400		{object}	apperror.AppError
+//	@Failure		404		{object}	apperror.AppError
+//	@Failure		418		{object}	apperror.AppError
+//	@Router			/viewings/{id}/booking [delete]
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := handlers.UserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := handlers.IDFromParams(r, "id")
+	if err != nil {
+		return err
+	}
+
+	viewing, err := h.LotService.CancelViewing(r.Context(), userID, slotID)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(viewing)
+	return nil
+}
@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/client/user_service"
 	"github.com/levelord1311/backendForSharedProject/api_service/internal/config"
@@ -11,16 +13,39 @@ import (
 
 // TODO implement refresh token
 
+const (
+	usersAudience     = "users"
+	challengeAudience = "2fa"
+	streamAudience    = "events"
+	challengeTTL      = 5 * time.Minute
+	// StreamTicketTTL is how long stream tickets can be used to open streams.
+	StreamTicketTTL = time.Minute
+)
+
 var _ Helper = &helper{}
 
 type Helper interface {
 	GenerateAccessToken(u *user_service.User) ([]byte, error)
+	GenerateChallengeToken(u *user_service.User) ([]byte, error)
+	ParseChallengeToken(token string) (uint, error)
+	// GenerateStreamTicket returns short-lived ticket, which opens event stream of the user in place of access
+	// token expiring at the given time. Streams opened with the ticket end, when the token expires.
+	GenerateStreamTicket(userID, role string, until time.Time) ([]byte, error)
 }
 
 type UserClaims struct {
 	jwt.RegisteredClaims
 	Email    string
 	Username string
+	Role     string
+}
+
+// streamClaims are claims of stream tickets. Browsers can't send headers with EventSource, so tickets are
+// passed in URLs, where they may be logged, and are short-lived for that.
+type streamClaims struct {
+	jwt.RegisteredClaims
+	Role  string
+	Until *jwt.NumericDate `json:"until"` // expiry of the access token the ticket is given for
 }
 
 type helper struct {
@@ -41,12 +66,81 @@ func (h *helper) GenerateAccessToken(u *user_service.User) ([]byte, error) {
 
 	claims := &UserClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			Audience:  []string{"users"},
+			Audience:  []string{usersAudience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
 			ID:        userIDStr,
 		},
 		Email:    u.Email,
 		Username: u.Username,
+		Role:     u.Role,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(tokenString), nil
+}
+
+// GenerateChallengeToken returns short-lived token, which proves that the user has passed the first factor.
+// It can be exchanged for access token only together with the second factor and is rejected by Middleware.
+func (h *helper) GenerateChallengeToken(u *user_service.User) ([]byte, error) {
+	key := []byte(config.GetConfig().JWT.Secret)
+
+	claims := &jwt.RegisteredClaims{
+		Audience:  []string{challengeAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(challengeTTL)),
+		ID:        strconv.Itoa(int(u.ID)),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(tokenString), nil
+}
+
+// ParseChallengeToken validates token generated by GenerateChallengeToken and returns user ID from it.
+func (h *helper) ParseChallengeToken(tokenString string) (uint, error) {
+	key := []byte(config.GetConfig().JWT.Secret)
+	claims := &jwt.RegisteredClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("wrong signing method, expected HMAC")
+		}
+		return key, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if !token.Valid || !claims.VerifyAudience(challengeAudience, true) {
+		return 0, errors.New("challenge token is not valid")
+	}
+
+	userID, err := strconv.Atoi(claims.ID)
+	if err != nil {
+		return 0, fmt.Errorf("wrong user id in challenge token. error: %w", err)
+	}
+	return uint(userID), nil
+}
+
+func (h *helper) GenerateStreamTicket(userID, role string, until time.Time) ([]byte, error) {
+	key := []byte(config.GetConfig().JWT.Secret)
+
+	claims := &streamClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  []string{streamAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(StreamTicketTTL)),
+			ID:        userID,
+		},
+		Role:  role,
+		Until: jwt.NewNumericDate(until),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
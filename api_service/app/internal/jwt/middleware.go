@@ -12,10 +12,7 @@ import (
 
 func Middleware(endpointHandler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-
-		claims := &UserClaims{}
 		logger := logging.GetLogger()
-		key := []byte(config.GetConfig().JWT.Secret)
 
 		logger.Debug("searching for 'Token' in header...")
 		if r.Header["Token"] == nil {
@@ -25,39 +22,133 @@ func Middleware(endpointHandler http.HandlerFunc) http.HandlerFunc {
 		}
 
 		logger.Debug("'Token' field has been found, parsing...")
+		claims, err := parseAccessToken(r.Header["Token"][0])
+		if err != nil {
+			unauthorized(w, err)
+			return
+		}
 
-		token, err := jwt.ParseWithClaims(r.Header["Token"][0], claims, func(token *jwt.Token) (interface{}, error) {
-			_, ok := token.Method.(*jwt.SigningMethodHMAC)
-			if !ok {
-				err := errors.New("wrong signing method, expected HMAC")
-				unauthorized(w, err)
-				return nil, err
-			}
-			logger.Debug("token signing method is correct")
-			return key, nil
-		})
+		endpointHandler(w, r.WithContext(withClaims(r.Context(), claims)))
+	}
+}
+
+// Optional passes user from valid token to the handler like Middleware, but allows requests without token.
+// Requests with invalid token are handled as anonymous too.
+func Optional(endpointHandler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header["Token"] == nil {
+			endpointHandler(w, r)
+			return
+		}
 
+		claims, err := parseAccessToken(r.Header["Token"][0])
 		if err != nil {
-			unauthorized(w, err)
+			logging.GetLogger().Debugf("request with invalid token is handled as anonymous. error: %v", err)
+			endpointHandler(w, r)
 			return
 		}
+		endpointHandler(w, r.WithContext(withClaims(r.Context(), claims)))
+	}
+}
 
-		logger.Debug("checking if token is valid...")
-		if !token.Valid {
-			err = errors.New("token is not valid")
-			unauthorized(w, err)
+// StreamMiddleware passes user to event streams like Middleware, but accepts stream ticket in ticket query
+// parameter as well, since browsers can't send headers with EventSource. Either way expiry of the access token
+// is passed to the handler, streams must end then.
+func StreamMiddleware(endpointHandler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticket := r.URL.Query().Get("ticket")
+		if ticket == "" {
+			Middleware(endpointHandler)(w, r)
 			return
 		}
 
-		logger.Debug("checking if token is expired...")
-		if !claims.VerifyExpiresAt(time.Now(), true) {
-			err = errors.New("token is expired")
+		claims, err := parseStreamTicket(ticket)
+		if err != nil {
 			unauthorized(w, err)
 			return
 		}
+		endpointHandler(w, r.WithContext(withClaims(r.Context(), claims)))
+	}
+}
+
+// parseAccessToken returns claims of valid and not expired access token.
+func parseAccessToken(tokenString string) (*UserClaims, error) {
+	logger := logging.GetLogger()
+	claims := &UserClaims{}
+	key := []byte(config.GetConfig().JWT.Secret)
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		_, ok := token.Method.(*jwt.SigningMethodHMAC)
+		if !ok {
+			return nil, errors.New("wrong signing method, expected HMAC")
+		}
+		logger.Debug("token signing method is correct")
+		return key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug("checking if token is valid...")
+	if !token.Valid {
+		return nil, errors.New("token is not valid")
+	}
+
+	logger.Debug("checking token audience...")
+	if !claims.VerifyAudience(usersAudience, true) {
+		return nil, errors.New("token is not an access token")
+	}
+
+	logger.Debug("checking if token is expired...")
+	if !claims.VerifyExpiresAt(time.Now(), true) {
+		return nil, errors.New("token is expired")
+	}
+	return claims, nil
+}
+
+// parseStreamTicket returns claims of the access token, which valid and not expired stream ticket is given for.
+func parseStreamTicket(ticket string) (*UserClaims, error) {
+	claims := &streamClaims{}
+	key := []byte(config.GetConfig().JWT.Secret)
+
+	token, err := jwt.ParseWithClaims(ticket, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("wrong signing method, expected HMAC")
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || !claims.VerifyAudience(streamAudience, true) {
+		return nil, errors.New("stream ticket is not valid")
+	}
+	if claims.Until == nil || !claims.Until.After(time.Now()) {
+		return nil, errors.New("access token of stream ticket is expired")
+	}
+
+	return &UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: claims.ID, ExpiresAt: claims.Until},
+		Role:             claims.Role,
+	}, nil
+}
 
-		ctx := context.WithValue(r.Context(), "user_id", claims.ID)
-		endpointHandler(w, r.WithContext(ctx))
+func withClaims(ctx context.Context, claims *UserClaims) context.Context {
+	ctx = context.WithValue(ctx, "user_id", claims.ID)
+	ctx = context.WithValue(ctx, "expires_at", claims.ExpiresAt.Time)
+	return context.WithValue(ctx, "role", claims.Role)
+}
+
+// RequireRole must be wrapped by Middleware, it allows only users with given role.
+func RequireRole(role string, endpointHandler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if userRole, _ := r.Context().Value("role").(string); userRole != role {
+			logging.GetLogger().Errorf("user with role %q requested endpoint for %q", userRole, role)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("forbidden"))
+			return
+		}
+		endpointHandler(w, r)
 	}
 }
 
@@ -22,6 +22,10 @@ func (ar *APIResponse) ReadBody() ([]byte, error) {
 	return io.ReadAll(ar.response.Body)
 }
 
+func (ar *APIResponse) Header() http.Header {
+	return ar.response.Header
+}
+
 func (ar *APIResponse) StatusCode() int {
 	return ar.response.StatusCode
 }
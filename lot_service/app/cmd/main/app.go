@@ -1,23 +1,69 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
+	addressDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/address/db"
+	addressService "github.com/levelord1311/backendForSharedProject/lot_service/internal/address/service"
+	agreementDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement/db"
+	agreementService "github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement/service"
+	analyticsDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics/db"
+	analyticsService "github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics/service"
+	bookingDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/db"
+	bookingService "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/service"
+	calendarDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar/db"
+	calendarService "github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar/service"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/config"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate"
+	duplicateDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate/db"
+	duplicateService "github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate/service"
+	eventDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/db"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	exchangeDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/exchange/db"
+	exchangeService "github.com/levelord1311/backendForSharedProject/lot_service/internal/exchange/service"
+	favoriteDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/favorite/db"
+	favoriteService "github.com/levelord1311/backendForSharedProject/lot_service/internal/favorite/service"
+	feedDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/feed/db"
+	feedService "github.com/levelord1311/backendForSharedProject/lot_service/internal/feed/service"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/handlers"
+	historyDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/history/db"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/db"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	importDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport/db"
+	importService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport/service"
+	messagingDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging/db"
+	messagingService "github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging/service"
+	organizationDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/db"
+	organizationService "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/service"
+	paymentDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/payment/db"
+	paymentService "github.com/levelord1311/backendForSharedProject/lot_service/internal/payment/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/recommendation"
+	recommendationService "github.com/levelord1311/backendForSharedProject/lot_service/internal/recommendation/service"
+	referenceDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/reference/db"
+	referenceService "github.com/levelord1311/backendForSharedProject/lot_service/internal/reference/service"
+	rentalDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/rental/db"
+	rentalService "github.com/levelord1311/backendForSharedProject/lot_service/internal/rental/service"
+	reviewDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/review/db"
+	reviewService "github.com/levelord1311/backendForSharedProject/lot_service/internal/review/service"
+	viewingDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing/db"
+	viewingService "github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/currency"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/geocoder"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/media"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/metric"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/payment"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/shutdown"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
-	"syscall"
+	"sync"
 	"time"
 )
 
@@ -28,6 +74,11 @@ func main() {
 	logger.Println("initializing config...")
 	cfg := config.GetConfig()
 
+	// workers and the server are stopped by the signals
+	ctx, stop := signal.NotifyContext(context.Background(), shutdown.Signals...)
+	defer stop()
+	var workers sync.WaitGroup
+
 	logger.Println("initializing router..")
 	router := httprouter.New()
 
@@ -47,8 +98,240 @@ func main() {
 		logger.Fatalln(err)
 	}
 
+	addressGeocoder, err := geocoder.New(cfg.Addresses.Geocoder, cfg.Addresses.GeocoderFile)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	addressStorage := addressDB.NewStorage(mysqlClient, logger)
+	addressesService, err := addressService.NewService(addressStorage, addressGeocoder, addressService.Config{
+		BatchSize:  cfg.Addresses.BatchSize,
+		AddUnknown: cfg.Addresses.AddUnknown,
+	}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	runWorker(&workers, func() {
+		addressService.RunResolver(ctx, addressesService, cfg.Addresses.Interval, logger)
+	})
+
+	referenceStorage := referenceDB.NewStorage(mysqlClient, logger)
+	referencesService, err := referenceService.NewService(referenceStorage, referenceService.Config{
+		CacheTTL: cfg.Reference.CacheTTL,
+	}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	ratesSource, err := currency.New(cfg.Exchange.Source, cfg.Exchange.Location, cfg.Exchange.Timeout)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	exchangeStorage := exchangeDB.NewStorage(mysqlClient, logger)
+	exchangesService, err := exchangeService.NewService(exchangeStorage, ratesSource, exchangeService.Config{
+		CacheTTL: cfg.Exchange.CacheTTL,
+	}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	runWorker(&workers, func() {
+		exchangeService.RunRefresher(ctx, exchangesService, cfg.Exchange.Interval, logger)
+	})
+
+	eventStorage := eventDB.NewStorage(mysqlClient, logger)
+	eventsService, err := eventService.NewService(eventStorage, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	runWorker(&workers, func() {
+		eventService.RunRetention(ctx, eventStorage, cfg.Events.TTL, cfg.Events.RetentionInterval, logger)
+	})
+
+	organizationStorage := organizationDB.NewStorage(mysqlClient, logger)
 	lotStorage := db.NewStorage(mysqlClient, logger)
-	lotService, err := service.NewService(lotStorage, logger)
+	favoriteStorage := favoriteDB.NewStorage(mysqlClient, logger)
+	favoritesService, err := favoriteService.NewService(favoriteStorage, lotStorage, eventsService, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	rentalStorage := rentalDB.NewStorage(mysqlClient, logger)
+	rentalsService, err := rentalService.NewService(rentalStorage, lotStorage, organizationStorage, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	duplicateStorage := duplicateDB.NewStorage(mysqlClient, logger)
+	duplicatesService, err := duplicateService.NewService(duplicateStorage, lotStorage, organizationStorage, eventsService,
+		duplicateService.Config{
+			BatchSize: cfg.Duplicates.BatchSize,
+			Threshold: cfg.Duplicates.Threshold,
+			Matching: duplicate.Matching{
+				AreaTolerance: cfg.Duplicates.AreaTolerance,
+				PhotoDistance: cfg.Duplicates.PhotoDistance,
+			},
+		}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	runWorker(&workers, func() {
+		duplicateService.RunDetector(ctx, duplicatesService, cfg.Duplicates.Interval, logger)
+	})
+
+	versionStorage := historyDB.NewStorage(mysqlClient, logger)
+	lotService, err := service.NewService(lotStorage, organizationStorage, addressesService, referencesService,
+		exchangesService, rentalsService, versionStorage, duplicatesService, favoritesService, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	organizationsService, err := organizationService.NewService(organizationStorage, lotStorage, eventsService, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	bookingStorage := bookingDB.NewStorage(mysqlClient, logger)
+	bookingsService, err := bookingService.NewService(bookingStorage, lotStorage, organizationStorage, rentalsService, eventsService,
+		cfg.Bookings.ResponseTimeout, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	runWorker(&workers, func() {
+		bookingService.RunExpiration(ctx, bookingStorage, eventsService,
+			cfg.Bookings.ExpirationInterval, logger)
+	})
+
+	calendarStorage := calendarDB.NewStorage(mysqlClient, logger)
+	calendarsService, err := calendarService.NewService(calendarStorage, bookingStorage, lotStorage, organizationStorage,
+		calendarService.Config{
+			Domain:        cfg.Calendar.Domain,
+			AllowFileURLs: cfg.Calendar.AllowFileURLs,
+			AllowedHosts:  cfg.Calendar.AllowedHosts,
+			FetchTimeout:  cfg.Calendar.FetchTimeout,
+		}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	runWorker(&workers, func() {
+		calendarService.RunImport(ctx, calendarsService, calendarStorage, cfg.Calendar.ImportInterval, logger)
+	})
+
+	mediaStorage, err := media.NewStorage(cfg.Media.Type, cfg.Media.Dir)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	messagingStorage := messagingDB.NewStorage(mysqlClient, logger)
+	messagesService, err := messagingService.NewService(messagingStorage, lotStorage, mediaStorage,
+		messagingService.NewEventNotifier(eventsService),
+		messagingService.Config{MaxAttachmentSize: cfg.Messaging.MaxAttachmentSize}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	runWorker(&workers, func() {
+		messagingService.RunRetention(ctx, messagingStorage, mediaStorage,
+			messagingService.RetentionPolicy{
+				MessagesTTL:          cfg.Messaging.MessagesTTL,
+				UnsentAttachmentsTTL: cfg.Messaging.UnsentAttachmentsTTL,
+			}, cfg.Messaging.RetentionInterval, logger)
+	})
+
+	reviewStorage := reviewDB.NewStorage(mysqlClient, logger)
+	reviewsService, err := reviewService.NewService(reviewStorage, bookingStorage, eventsService,
+		reviewService.Config{HideAfterReports: cfg.Reviews.HideAfterReports}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	viewingStorage := viewingDB.NewStorage(mysqlClient, logger)
+	viewingsService, err := viewingService.NewService(viewingStorage, lotStorage, organizationStorage, eventsService,
+		viewingService.Config{
+			Domain:       cfg.Calendar.Domain,
+			RemindBefore: cfg.Viewings.RemindBefore,
+		}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	runWorker(&workers, func() {
+		viewingService.RunReminders(ctx, viewingsService, cfg.Viewings.ReminderInterval, logger)
+	})
+
+	agreementStorage := agreementDB.NewStorage(mysqlClient, logger)
+	agreementsService, err := agreementService.NewService(agreementStorage, bookingStorage, lotStorage, eventsService,
+		agreementService.Config{FontPath: cfg.Agreements.FontPath}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	paymentProvider, err := payment.NewProvider(payment.Config{
+		Type:        cfg.Payments.Provider,
+		Secret:      cfg.Payments.WebhookSecret,
+		CheckoutURL: cfg.Payments.CheckoutURL,
+	})
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	paymentStorage := paymentDB.NewStorage(mysqlClient, logger)
+	paymentsService, err := paymentService.NewService(paymentStorage, bookingStorage, lotStorage, paymentProvider,
+		eventsService, paymentService.Config{FontPath: cfg.Payments.FontPath}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	importStorage := importDB.NewStorage(mysqlClient, logger)
+	importsService, err := importService.NewService(importStorage, lotService, organizationStorage, mediaStorage,
+		eventsService, referencesService, exchangesService, importService.Config{
+			MaxFileSize: cfg.Imports.MaxFileSize,
+			MaxRows:     cfg.Imports.MaxRows,
+		}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	runWorker(&workers, func() {
+		importService.RunImport(ctx, importsService, importStorage, cfg.Imports.Interval, logger)
+	})
+
+	feedStorage := feedDB.NewStorage(mysqlClient, logger)
+	feedsService, err := feedService.NewService(feedStorage, lotStorage, feedService.Config{
+		LotURL:       cfg.Feeds.LotURL,
+		SiteURL:      cfg.Feeds.SiteURL,
+		ContactPhone: cfg.Feeds.ContactPhone,
+		CityItems:    cfg.Feeds.CityItems,
+	}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	analyticsStorage := analyticsDB.NewStorage(mysqlClient, logger)
+	lotAnalytics, err := analyticsService.NewService(analyticsStorage, lotStorage, lotService,
+		analyticsService.Config{
+			QueueSize:      cfg.Analytics.QueueSize,
+			MaxSimilarLots: cfg.Analytics.MaxSimilarLots,
+		}, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	// views are recorded by requests, so the recorder is stopped after the server to save them all
+	recorderCtx, stopRecorder := context.WithCancel(context.Background())
+	runWorker(&workers, func() {
+		analyticsService.RunRecorder(recorderCtx, lotAnalytics, cfg.Analytics.FlushInterval, logger)
+	})
+
+	similarLots, err := recommendationService.NewService(lotStorage, &recommendation.FeatureRanker{
+		Weights: recommendation.Weights{
+			EstateType: cfg.Similar.Weights.EstateType,
+			Rooms:      cfg.Similar.Weights.Rooms,
+			Area:       cfg.Similar.Weights.Area,
+			Price:      cfg.Similar.Weights.Price,
+			District:   cfg.Similar.Weights.District,
+			Distance:   cfg.Similar.Weights.Distance,
+		},
+		PriceBand:     cfg.Similar.PriceBand,
+		MaxDistanceKm: cfg.Similar.MaxDistanceKm,
+	}, recommendationService.Config{
+		Candidates:    cfg.Similar.Candidates,
+		MinCandidates: cfg.Similar.MinCandidates,
+		PriceBand:     cfg.Similar.PriceBand,
+		Limit:         cfg.Similar.Limit,
+		MaxLimit:      cfg.Similar.MaxLimit,
+	}, logger)
 	if err != nil {
 		logger.Fatalln(err)
 	}
@@ -57,15 +340,144 @@ func main() {
 	lotsHandler := handlers.Handler{
 		Logger:     logger,
 		LotService: lotService,
+		Views:      lotAnalytics,
 	}
 	lotsHandler.Register(router)
 
+	bookingsHandler := handlers.BookingHandler{
+		Logger:         logger,
+		BookingService: bookingsService,
+	}
+	bookingsHandler.Register(router)
+
+	calendarHandler := handlers.CalendarHandler{
+		Logger:          logger,
+		CalendarService: calendarsService,
+	}
+	calendarHandler.Register(router)
+
+	messagingHandler := handlers.MessagingHandler{
+		Logger:           logger,
+		MessagingService: messagesService,
+	}
+	messagingHandler.Register(router)
+
+	eventsHandler := handlers.EventHandler{
+		Logger:       logger,
+		EventService: eventsService,
+	}
+	eventsHandler.Register(router)
+
+	reviewsHandler := handlers.ReviewHandler{
+		Logger:        logger,
+		ReviewService: reviewsService,
+	}
+	reviewsHandler.Register(router)
+
+	viewingsHandler := handlers.ViewingHandler{
+		Logger:         logger,
+		ViewingService: viewingsService,
+	}
+	viewingsHandler.Register(router)
+
+	agreementsHandler := handlers.AgreementHandler{
+		Logger:           logger,
+		AgreementService: agreementsService,
+	}
+	agreementsHandler.Register(router)
+
+	paymentsHandler := handlers.PaymentHandler{
+		Logger:         logger,
+		PaymentService: paymentsService,
+	}
+	paymentsHandler.Register(router)
+
+	organizationsHandler := handlers.OrganizationHandler{
+		Logger:              logger,
+		OrganizationService: organizationsService,
+	}
+	organizationsHandler.Register(router)
+
+	importsHandler := handlers.ImportHandler{
+		Logger:        logger,
+		ImportService: importsService,
+	}
+	importsHandler.Register(router)
+
+	feedsHandler := handlers.FeedHandler{
+		Logger:      logger,
+		FeedService: feedsService,
+	}
+	feedsHandler.Register(router)
+
+	analyticsHandler := handlers.AnalyticsHandler{
+		Logger:           logger,
+		AnalyticsService: lotAnalytics,
+	}
+	analyticsHandler.Register(router)
+
+	favoritesHandler := handlers.FavoriteHandler{
+		Logger:          logger,
+		FavoriteService: favoritesService,
+	}
+	favoritesHandler.Register(router)
+
+	recommendationHandler := handlers.RecommendationHandler{
+		Logger:                logger,
+		RecommendationService: similarLots,
+	}
+	recommendationHandler.Register(router)
+
+	duplicatesHandler := handlers.DuplicateHandler{
+		Logger:           logger,
+		DuplicateService: duplicatesService,
+	}
+	duplicatesHandler.Register(router)
+
+	addressHandler := handlers.AddressHandler{
+		Logger:         logger,
+		AddressService: addressesService,
+	}
+	addressHandler.Register(router)
+
+	referenceHandler := handlers.ReferenceHandler{
+		Logger:           logger,
+		ReferenceService: referencesService,
+	}
+	referenceHandler.Register(router)
+
+	rentalHandler := handlers.RentalHandler{
+		Logger:        logger,
+		RentalService: rentalsService,
+	}
+	rentalHandler.Register(router)
+
+	historyHandler := handlers.HistoryHandler{
+		Logger:     logger,
+		LotService: lotService,
+	}
+	historyHandler.Register(router)
+
 	logger.Println("starting application...")
-	start(router, logger, cfg)
+	start(ctx, router, logger, cfg)
+
+	logger.Println("stopping workers...")
+	stopRecorder()
+	workers.Wait()
+	logger.Println("application stopped")
+}
 
+// runWorker runs the worker in background, the wait group waits for the worker to stop.
+func runWorker(wg *sync.WaitGroup, worker func()) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		worker()
+	}()
 }
 
-func start(router http.Handler, logger logging.Logger, cfg *config.Config) {
+// start serves requests until the context is done and requests in progress are completed.
+func start(ctx context.Context, router http.Handler, logger logging.Logger, cfg *config.Config) {
 	var server *http.Server
 	var listener net.Listener
 
@@ -99,8 +511,12 @@ func start(router http.Handler, logger logging.Logger, cfg *config.Config) {
 		ReadTimeout:  15 * time.Second,
 	}
 
-	go shutdown.Graceful([]os.Signal{syscall.SIGABRT, syscall.SIGQUIT, syscall.SIGHUP, os.Interrupt, syscall.SIGTERM},
-		server)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		// requests can't take longer than WriteTimeout anyway
+		shutdown.Graceful(ctx, server, server.WriteTimeout)
+	}()
 
 	logger.Println("application initialized and started")
 
@@ -108,6 +524,7 @@ func start(router http.Handler, logger logging.Logger, cfg *config.Config) {
 		switch {
 		case errors.Is(err, http.ErrServerClosed):
 			logger.Warn("server is shutting down")
+			<-stopped
 		default:
 			logger.Fatal(err)
 		}
@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/address"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/address/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"strings"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+// likeEscaper escapes wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (s *db) FindOrCreateCity(ctx context.Context, c *address.City) error {
+	// LAST_INSERT_ID(city_id) makes ID of existing city the inserted ID
+	res, err := s.db.ExecContext(ctx, `
+	INSERT INTO cities (name, normalized)
+	VALUES (?, ?)
+	ON DUPLICATE KEY UPDATE city_id=LAST_INSERT_ID(city_id);`, c.Name, c.Key)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	found, err := s.FindCityByID(ctx, uint(id))
+	if err != nil {
+		return err
+	}
+	*c = *found
+	return nil
+}
+
+func (s *db) FindOrCreateDistrict(ctx context.Context, d *address.District) error {
+	res, err := s.db.ExecContext(ctx, `
+	INSERT INTO districts (city_id, name, normalized)
+	VALUES (?, ?, ?)
+	ON DUPLICATE KEY UPDATE district_id=LAST_INSERT_ID(district_id);`, d.CityID, d.Name, d.Key)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+	SELECT district_id, city_id, name, normalized
+	FROM districts
+	WHERE district_id=?;`, id).Scan(&d.ID, &d.CityID, &d.Name, &d.Key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.ErrNotFound
+	}
+	return err
+}
+
+func (s *db) FindCityByID(ctx context.Context, id uint) (*address.City, error) {
+	c := &address.City{}
+	err := s.db.QueryRowContext(ctx, `
+	SELECT city_id, name, normalized
+	FROM cities
+	WHERE city_id=?;`, id).Scan(&c.ID, &c.Name, &c.Key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *db) FindCityByKey(ctx context.Context, key string) (*address.City, error) {
+	c := &address.City{}
+	err := s.db.QueryRowContext(ctx, `
+	SELECT city_id, name, normalized
+	FROM cities
+	WHERE normalized=?;`, key).Scan(&c.ID, &c.Name, &c.Key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *db) FindDistrictByKey(ctx context.Context, cityID uint, key string) (*address.District, error) {
+	d := &address.District{}
+	err := s.db.QueryRowContext(ctx, `
+	SELECT district_id, city_id, name, normalized
+	FROM districts
+	WHERE city_id=? AND normalized=?;`, cityID, key).Scan(&d.ID, &d.CityID, &d.Name, &d.Key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *db) SearchCities(ctx context.Context, prefix string, limit int) ([]*address.City, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT city_id, name, normalized
+	FROM cities
+	WHERE normalized LIKE ?
+	ORDER BY name
+	LIMIT ?;`, likeEscaper.Replace(prefix)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cities := make([]*address.City, 0)
+	for rows.Next() {
+		c := &address.City{}
+		if err = rows.Scan(&c.ID, &c.Name, &c.Key); err != nil {
+			return nil, err
+		}
+		cities = append(cities, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return cities, nil
+}
+
+func (s *db) SearchDistricts(ctx context.Context, cityID uint, prefix string,
+	limit int) ([]*address.District, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT district_id, city_id, name, normalized
+	FROM districts
+	WHERE city_id=? AND normalized LIKE ?
+	ORDER BY name
+	LIMIT ?;`, cityID, likeEscaper.Replace(prefix)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	districts := make([]*address.District, 0)
+	for rows.Next() {
+		d := &address.District{}
+		if err = rows.Scan(&d.ID, &d.CityID, &d.Name, &d.Key); err != nil {
+			return nil, err
+		}
+		districts = append(districts, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return districts, nil
+}
+
+func (s *db) FindUnresolved(ctx context.Context, afterID uint, limit int) ([]*lot.Lot, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT lot_id, city, district, street, building, latitude, longitude
+	FROM lots
+	WHERE city_id IS NULL AND lot_id>?
+	ORDER BY lot_id
+	LIMIT ?;`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lots := make([]*lot.Lot, 0)
+	for rows.Next() {
+		l := &lot.Lot{}
+		var latitude, longitude sql.NullFloat64
+		err = rows.Scan(&l.ID, &l.City, &l.District, &l.Street, &l.Building, &latitude, &longitude)
+		if err != nil {
+			return nil, err
+		}
+		if latitude.Valid && longitude.Valid {
+			l.Location = &lot.Location{Latitude: latitude.Float64, Longitude: longitude.Float64}
+		}
+		lots = append(lots, l)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+func (s *db) SetLotAddress(ctx context.Context, l *lot.Lot) error {
+	var latitude, longitude *float64
+	if l.Location != nil {
+		latitude, longitude = &l.Location.Latitude, &l.Location.Longitude
+	}
+	_, err := s.db.ExecContext(ctx, `
+	UPDATE lots
+	SET city_id=?, district_id=?, city=?, district=?, latitude=?, longitude=?, redacted_at=redacted_at
+	WHERE lot_id=?;`, l.CityID, l.DistrictID, l.City, l.District, latitude, longitude, l.ID)
+	return err
+}
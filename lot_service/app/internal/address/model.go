@@ -0,0 +1,32 @@
+package address
+
+const (
+	DefSearchLimit = 10
+	MaxSearchLimit = 50
+)
+
+// City is canonical city, lots refer to it by ID. Key is normalized name, which identifies the city
+// among its spellings.
+type City struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Key  string `json:"-"`
+}
+
+// District is canonical district of the city.
+type District struct {
+	ID     uint   `json:"id"`
+	CityID uint   `json:"city_id"`
+	Name   string `json:"name"`
+	Key    string `json:"-"`
+}
+
+func NewCity(name string) *City {
+	name = CleanCity(name)
+	return &City{Name: name, Key: CityKey(name)}
+}
+
+func NewDistrict(cityID uint, name string) *District {
+	name = CleanDistrict(name)
+	return &District{CityID: cityID, Name: name, Key: DistrictKey(name)}
+}
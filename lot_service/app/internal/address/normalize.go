@@ -0,0 +1,115 @@
+package address
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// cityTypes, districtTypes and streetTypes are omitted in normalized addresses, since the same places
+// are written with and without them.
+var (
+	cityTypes = map[string]bool{
+		"город": true, "г": true,
+	}
+	districtTypes = map[string]bool{
+		"район": true, "р-н": true, "р-он": true,
+	}
+	streetTypes = map[string]bool{
+		"улица": true, "ул": true,
+		"проспект": true, "просп": true, "пр-т": true, "пр": true,
+		"переулок": true, "пер": true,
+		"бульвар": true, "б-р": true, "бул": true,
+		"шоссе": true, "ш": true,
+		"площадь": true, "пл": true,
+		"набережная": true, "наб": true,
+		"проезд": true, "пр-д": true,
+		"тупик": true, "туп": true,
+	}
+)
+
+// buildingWords are abbreviated in normalized addresses, houses are written by their numbers only.
+var buildingWords = map[string]string{
+	"дом": "", "д": "",
+	"корпус": "к", "корп": "к",
+	"строение": "с", "стр": "с",
+	"литера": "л", "лит": "л",
+}
+
+// Key returns address in lower case without punctuation and types of streets, e.g.
+// "г. Москва, ул. Ленина, д. 1 корп. 2" and "Москва, Ленина улица, 1к2" are both "москва|ленина|1к2".
+func Key(city, street, building string) string {
+	return strings.Join([]string{CityKey(city), StreetKey(street), BuildingKey(building)}, "|")
+}
+
+func CityKey(city string) string {
+	return strings.Join(withoutTypes(words(city), cityTypes), " ")
+}
+
+func DistrictKey(district string) string {
+	return strings.Join(withoutTypes(words(district), districtTypes), " ")
+}
+
+// StreetKey returns words of the street in alphabetical order, so "Маршала Жукова" and "Жукова Маршала"
+// are the same street.
+func StreetKey(street string) string {
+	w := withoutTypes(words(street), streetTypes)
+	sort.Strings(w)
+	return strings.Join(w, " ")
+}
+
+func BuildingKey(building string) string {
+	b := ""
+	for _, w := range words(building) {
+		if short, ok := buildingWords[w]; ok {
+			w = short
+		}
+		b += w
+	}
+	return b
+}
+
+// CleanCity returns name of the city without its type, e.g. "Москва" for "г. Москва".
+func CleanCity(city string) string {
+	return clean(city, cityTypes)
+}
+
+// CleanDistrict returns name of the district without its type, e.g. "Центральный" for "Центральный р-н".
+func CleanDistrict(district string) string {
+	return clean(district, districtTypes)
+}
+
+// clean drops types from the name and keeps case of other words. Names, which are types only, are kept.
+func clean(name string, types map[string]bool) string {
+	kept := make([]string, 0)
+	for _, w := range strings.Fields(name) {
+		if !types[strings.ToLower(strings.Trim(w, ".,"))] {
+			kept = append(kept, strings.Trim(w, ","))
+		}
+	}
+	if len(kept) == 0 {
+		return strings.Join(strings.Fields(name), " ")
+	}
+	return strings.Join(kept, " ")
+}
+
+func withoutTypes(words []string, types map[string]bool) []string {
+	kept := make([]string, 0, len(words))
+	for _, w := range words {
+		if !types[w] {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		return words
+	}
+	return kept
+}
+
+// words splits lower-cased text by spaces and punctuation, hyphens and slashes are kept.
+func words(s string) []string {
+	s = strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '/'
+	})
+}
@@ -0,0 +1,32 @@
+package address
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	want := "москва|ленина|1к2"
+	for _, a := range [][3]string{
+		{"Москва", "ул. Ленина", "1к2"},
+		{"г. Москва", "Ленина улица", "д. 1, корп. 2"},
+		{"МОСКВА", "  ленина ", "1 к 2"},
+	} {
+		if got := Key(a[0], a[1], a[2]); got != want {
+			t.Errorf("expected %q for %v, got %q", want, a, got)
+		}
+	}
+
+	if Key("Москва", "Ленина", "1") == Key("Москва", "Ленина", "1д") {
+		t.Error("expected different buildings to differ")
+	}
+}
+
+func TestClean(t *testing.T) {
+	if got := CleanCity("г. Санкт-Петербург"); got != "Санкт-Петербург" {
+		t.Errorf("expected city without type, got %q", got)
+	}
+	if got := CleanDistrict("Центральный р-н"); got != "Центральный" {
+		t.Errorf("expected district without type, got %q", got)
+	}
+	if DistrictKey("район Центральный") != DistrictKey("Центральный") {
+		t.Error("expected the same key of district with and without type")
+	}
+}
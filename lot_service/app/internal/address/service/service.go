@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/address"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/address/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/geocoder"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"strings"
+	"time"
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	// Resolve sets canonical city and district of the lot, the lot isn't saved. Address is geocoded first,
+	// if there is a geocoder, otherwise city and district of the lot are normalized. Location is set from
+	// geocoder, if the lot has none. Unknown cities and districts are rejected with bad request error,
+	// unless Config.AddUnknown is set.
+	Resolve(ctx context.Context, l *lot.Lot) error
+	// ResolveLots resolves and saves addresses of lots without canonical city and returns their number.
+	// Lots, which failed to resolve, are logged and skipped until the next call.
+	ResolveLots(ctx context.Context) (int, error)
+
+	SearchCities(ctx context.Context, query string, limit int) ([]*address.City, error)
+	SearchDistricts(ctx context.Context, cityID uint, query string, limit int) ([]*address.District, error)
+}
+
+type Config struct {
+	// BatchSize limits number of lots resolved at once
+	BatchSize int
+	// AddUnknown adds unknown cities and districts of lots to reference tables. It lets lots fill
+	// the tables, when admins don't maintain them, at the cost of misspelled cities in reference data.
+	AddUnknown bool
+}
+
+type service struct {
+	repository storage.Repository
+	geocoder   geocoder.Geocoder
+	cfg        Config
+	logger     logging.Logger
+}
+
+// NewService returns service, which normalizes addresses by rules only, if geocoder is nil.
+func NewService(addressStorage storage.Repository, g geocoder.Geocoder, cfg Config,
+	logger logging.Logger) (*service, error) {
+	return &service{
+		repository: addressStorage,
+		geocoder:   g,
+		cfg:        cfg,
+		logger:     logger,
+	}, nil
+}
+
+func (s *service) Resolve(ctx context.Context, l *lot.Lot) error {
+	city, district := l.City, l.District
+	if s.geocoder != nil {
+		r, err := s.geocoder.Geocode(ctx, fmt.Sprintf("%s, %s, %s", l.City, l.Street, l.Building))
+		switch {
+		case err == nil:
+			if r.City != "" {
+				city = r.City
+			}
+			if r.District != "" {
+				district = r.District
+			}
+			if l.Location == nil && (r.Latitude != 0 || r.Longitude != 0) {
+				l.Location = &lot.Location{Latitude: r.Latitude, Longitude: r.Longitude}
+			}
+		case errors.Is(err, geocoder.ErrNotFound):
+			s.logger.Debugf("address of lot %d is unknown to geocoder", l.ID)
+		default:
+			// rules are good enough, until geocoder is back
+			s.logger.Warnf("failed to geocode address of lot %d. error: %v", l.ID, err)
+		}
+	}
+
+	c, err := s.city(ctx, city)
+	if err != nil {
+		return err
+	}
+	l.CityID, l.City = &c.ID, c.Name
+
+	if strings.TrimSpace(district) == "" {
+		return nil
+	}
+	d, err := s.district(ctx, c.ID, district)
+	if err != nil {
+		return err
+	}
+	l.DistrictID, l.District = &d.ID, d.Name
+	return nil
+}
+
+func (s *service) city(ctx context.Context, name string) (*address.City, error) {
+	c := address.NewCity(name)
+	if s.cfg.AddUnknown {
+		if err := s.repository.FindOrCreateCity(ctx, c); err != nil {
+			return nil, fmt.Errorf("failed to find or create city. error: %w", err)
+		}
+		return c, nil
+	}
+
+	found, err := s.repository.FindCityByKey(ctx, c.Key)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, apperror.BadRequestError(fmt.Sprintf("unknown city %q", c.Name), "")
+		}
+		return nil, fmt.Errorf("failed to find city. error: %w", err)
+	}
+	return found, nil
+}
+
+func (s *service) district(ctx context.Context, cityID uint, name string) (*address.District, error) {
+	d := address.NewDistrict(cityID, name)
+	if s.cfg.AddUnknown {
+		if err := s.repository.FindOrCreateDistrict(ctx, d); err != nil {
+			return nil, fmt.Errorf("failed to find or create district. error: %w", err)
+		}
+		return d, nil
+	}
+
+	found, err := s.repository.FindDistrictByKey(ctx, cityID, d.Key)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, apperror.BadRequestError(fmt.Sprintf("unknown district %q", d.Name), "")
+		}
+		return nil, fmt.Errorf("failed to find district. error: %w", err)
+	}
+	return found, nil
+}
+
+func (s *service) ResolveLots(ctx context.Context) (int, error) {
+	resolved, afterID := 0, uint(0)
+	for {
+		lots, err := s.repository.FindUnresolved(ctx, afterID, s.cfg.BatchSize)
+		if err != nil {
+			return resolved, fmt.Errorf("failed to find lots with unresolved addresses. error: %w", err)
+		}
+		for _, l := range lots {
+			afterID = l.ID
+			if err = s.resolveLot(ctx, l); err != nil {
+				// the lot is retried by the next run, other lots don't wait for it
+				s.logger.Errorf("failed to resolve address of lot %d. error: %v", l.ID, err)
+				continue
+			}
+			resolved++
+		}
+		if len(lots) < s.cfg.BatchSize {
+			return resolved, nil
+		}
+	}
+}
+
+func (s *service) resolveLot(ctx context.Context, l *lot.Lot) error {
+	if err := s.Resolve(ctx, l); err != nil {
+		return err
+	}
+	if err := s.repository.SetLotAddress(ctx, l); err != nil {
+		return fmt.Errorf("failed to save address of lot. error: %w", err)
+	}
+	return nil
+}
+
+func (s *service) SearchCities(ctx context.Context, query string, limit int) ([]*address.City, error) {
+	limit, err := searchLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	cities, err := s.repository.SearchCities(ctx, address.CityKey(query), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search cities. error: %w", err)
+	}
+	return cities, nil
+}
+
+func (s *service) SearchDistricts(ctx context.Context, cityID uint, query string,
+	limit int) ([]*address.District, error) {
+	limit, err := searchLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = s.repository.FindCityByID(ctx, cityID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find city by its id. error: %w", err)
+	}
+
+	districts, err := s.repository.SearchDistricts(ctx, cityID, address.DistrictKey(query), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search districts. error: %w", err)
+	}
+	return districts, nil
+}
+
+func searchLimit(limit int) (int, error) {
+	if limit == 0 {
+		return address.DefSearchLimit, nil
+	}
+	if limit < 1 || limit > address.MaxSearchLimit {
+		return 0, apperror.BadRequestError(fmt.Sprintf("limit must be from 1 to %d", address.MaxSearchLimit), "")
+	}
+	return limit, nil
+}
+
+// RunResolver resolves addresses of new and imported lots with the interval until the context is done.
+func RunResolver(ctx context.Context, s Service, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ResolveLots(ctx); err != nil {
+				logger.Errorf("failed to resolve addresses of lots. error: %v", err)
+			}
+		}
+	}
+}
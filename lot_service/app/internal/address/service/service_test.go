@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/address"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/address/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/geocoder"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"testing"
+)
+
+type repo struct {
+	storage.Repository
+	cities    []*address.City
+	districts []*address.District
+	lots      []*lot.Lot
+	saved     []*lot.Lot
+	broken    string // key of the city, which fails to be created
+}
+
+func (r *repo) FindOrCreateCity(_ context.Context, c *address.City) error {
+	if c.Key == r.broken {
+		return errors.New("broken city")
+	}
+	for _, found := range r.cities {
+		if found.Key == c.Key {
+			*c = *found
+			return nil
+		}
+	}
+	c.ID = uint(len(r.cities) + 1)
+	copied := *c
+	r.cities = append(r.cities, &copied)
+	return nil
+}
+
+func (r *repo) FindOrCreateDistrict(_ context.Context, d *address.District) error {
+	for _, found := range r.districts {
+		if found.CityID == d.CityID && found.Key == d.Key {
+			*d = *found
+			return nil
+		}
+	}
+	d.ID = uint(len(r.districts) + 1)
+	copied := *d
+	r.districts = append(r.districts, &copied)
+	return nil
+}
+
+func (r *repo) FindCityByKey(_ context.Context, key string) (*address.City, error) {
+	for _, c := range r.cities {
+		if c.Key == key {
+			return c, nil
+		}
+	}
+	return nil, apperror.ErrNotFound
+}
+
+func (r *repo) FindDistrictByKey(_ context.Context, cityID uint, key string) (*address.District, error) {
+	for _, d := range r.districts {
+		if d.CityID == cityID && d.Key == key {
+			return d, nil
+		}
+	}
+	return nil, apperror.ErrNotFound
+}
+
+func (r *repo) FindUnresolved(_ context.Context, afterID uint, limit int) ([]*lot.Lot, error) {
+	unresolved := make([]*lot.Lot, 0)
+	for _, l := range r.lots {
+		if l.CityID == nil && l.ID > afterID && len(unresolved) < limit {
+			unresolved = append(unresolved, l)
+		}
+	}
+	return unresolved, nil
+}
+
+func (r *repo) SetLotAddress(_ context.Context, l *lot.Lot) error {
+	r.saved = append(r.saved, l)
+	return nil
+}
+
+func TestResolveLots(t *testing.T) {
+	r := &repo{lots: []*lot.Lot{
+		{ID: 1, City: "г. Москва", District: "Тверской р-н", Street: "ул. Тверская", Building: "1"},
+		{ID: 2, City: "москва", District: "район Тверской", Street: "Тверская", Building: "3"},
+		{ID: 3, City: "Казань", District: "Вахитовский", Street: "Баумана", Building: "10"},
+	}}
+	g := geocoder.NewStaticGeocoder(map[string]*geocoder.Result{
+		"Казань, Баумана, 10": {City: "Казань", District: "Вахитовский район", Latitude: 55.79, Longitude: 49.11},
+	})
+	s, _ := NewService(r, g, Config{BatchSize: 2, AddUnknown: true}, logging.GetLogger())
+
+	resolved, err := s.ResolveLots(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != 3 || len(r.saved) != 3 {
+		t.Fatalf("expected 3 resolved lots, got %d", resolved)
+	}
+	if len(r.cities) != 2 || len(r.districts) != 2 {
+		t.Errorf("expected 2 cities and 2 districts, got %d and %d", len(r.cities), len(r.districts))
+	}
+
+	moscow := r.lots[0]
+	if *moscow.CityID != *r.lots[1].CityID || *moscow.DistrictID != *r.lots[1].DistrictID {
+		t.Error("expected the same city and district of lots in Moscow")
+	}
+	if moscow.City != "Москва" || moscow.District != "Тверской" {
+		t.Errorf("expected canonical names, got %q and %q", moscow.City, moscow.District)
+	}
+
+	kazan := r.lots[2]
+	if kazan.District != "Вахитовский" || kazan.Location == nil || kazan.Location.Latitude != 55.79 {
+		t.Errorf("expected district and location from geocoder, got %q and %v", kazan.District, kazan.Location)
+	}
+}
+
+func TestResolveLotsSkipsFailed(t *testing.T) {
+	r := &repo{broken: address.CityKey("Тверь"), lots: []*lot.Lot{
+		{ID: 1, City: "Тверь", Street: "Советская", Building: "1"},
+		{ID: 2, City: "Тверь", Street: "Советская", Building: "2"},
+		{ID: 3, City: "Казань", Street: "Баумана", Building: "10"},
+	}}
+	s, _ := NewService(r, nil, Config{BatchSize: 2, AddUnknown: true}, logging.GetLogger())
+
+	resolved, err := s.ResolveLots(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != 1 || len(r.saved) != 1 || r.saved[0].ID != 3 {
+		t.Fatalf("expected only lot 3 resolved after failed ones, got %d", resolved)
+	}
+}
+
+func TestResolveKnownOnly(t *testing.T) {
+	r := &repo{
+		cities:    []*address.City{address.NewCity("Москва")},
+		districts: []*address.District{address.NewDistrict(1, "Тверской")},
+	}
+	r.cities[0].ID, r.districts[0].ID = 1, 1
+	s, _ := NewService(r, nil, Config{BatchSize: 2}, logging.GetLogger())
+
+	tests := []struct {
+		name     string
+		lot      *lot.Lot
+		rejected bool
+	}{
+		{name: "known city and district", lot: &lot.Lot{City: "г. Москва", District: "Тверской р-н"}},
+		{name: "known city without district", lot: &lot.Lot{City: "москва"}},
+		{name: "unknown city", lot: &lot.Lot{City: "Тверь", District: "Центральный"}, rejected: true},
+		{name: "unknown district", lot: &lot.Lot{City: "Москва", District: "Арбат"}, rejected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Resolve(context.Background(), tt.lot)
+			if tt.rejected {
+				var appErr *apperror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != apperror.BadRequestError("", "").Code {
+					t.Fatalf("expected bad request error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.lot.CityID == nil || *tt.lot.CityID != 1 || tt.lot.City != "Москва" {
+				t.Errorf("expected canonical city, got %q", tt.lot.City)
+			}
+		})
+	}
+	if len(r.cities) != 1 || len(r.districts) != 1 {
+		t.Errorf("reference data must not be changed, got %d cities and %d districts", len(r.cities), len(r.districts))
+	}
+}
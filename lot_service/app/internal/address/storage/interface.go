@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/address"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+)
+
+type Repository interface {
+	// FindOrCreateCity sets ID and name of the city with the same key, the city is created if it's new.
+	FindOrCreateCity(ctx context.Context, c *address.City) error
+	// FindOrCreateDistrict sets ID and name of the district of the city with the same key, the district
+	// is created if it's new.
+	FindOrCreateDistrict(ctx context.Context, d *address.District) error
+	FindCityByID(ctx context.Context, id uint) (*address.City, error)
+	FindCityByKey(ctx context.Context, key string) (*address.City, error)
+	FindDistrictByKey(ctx context.Context, cityID uint, key string) (*address.District, error)
+	// SearchCities returns cities, keys of which start with the prefix, ordered by name.
+	SearchCities(ctx context.Context, prefix string, limit int) ([]*address.City, error)
+	// SearchDistricts returns districts of the city, keys of which start with the prefix, ordered by name.
+	SearchDistricts(ctx context.Context, cityID uint, prefix string, limit int) ([]*address.District, error)
+
+	// FindUnresolved returns lots without canonical city with ID greater than afterID, oldest first.
+	// Only ID, address and location of the lots are filled.
+	FindUnresolved(ctx context.Context, afterID uint, limit int) ([]*lot.Lot, error)
+	// SetLotAddress saves canonical city, district and location of the lot. Time of change of the lot is kept.
+	SetLotAddress(ctx context.Context, l *lot.Lot) error
+}
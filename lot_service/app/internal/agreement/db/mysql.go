@@ -0,0 +1,343 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"strings"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is either database or transaction.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *db) CreateTemplate(ctx context.Context, t *agreement.Template) (uint, error) {
+	res, err := s.db.ExecContext(ctx, `
+	INSERT INTO agreement_templates (owner_id, name, body, created_at)
+	VALUES (?, ?, ?, ?);`, t.OwnerID, t.Name, t.Body, t.CreatedAt.UTC())
+	if err != nil {
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), nil
+}
+
+const templateColumns = `template_id, owner_id, name, body, created_at`
+
+func scanTemplate(row scanner) (*agreement.Template, error) {
+	t := &agreement.Template{}
+	var createdAt mysql.RawTime
+	if err := row.Scan(&t.ID, &t.OwnerID, &t.Name, &t.Body, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if t.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *db) FindTemplate(ctx context.Context, id uint) (*agreement.Template, error) {
+	t, err := scanTemplate(s.db.QueryRowContext(ctx, `
+	SELECT `+templateColumns+`
+	FROM agreement_templates
+	WHERE template_id=?;`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *db) FindTemplates(ctx context.Context, ownerID uint) ([]*agreement.Template, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT `+templateColumns+`
+	FROM agreement_templates
+	WHERE owner_id=?
+	ORDER BY template_id;`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := make([]*agreement.Template, 0)
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (s *db) DeleteTemplate(ctx context.Context, id, ownerID uint) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM agreement_templates WHERE template_id=? AND owner_id=?;`,
+		id, ownerID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+const agreementColumns = `
+	agreement_id, booking_id, lot_id, landlord_id, renter_id, version, template_id, text, hash,
+	landlord_accepted_at, IFNULL(landlord_ip, ''), renter_accepted_at, IFNULL(renter_ip, ''), created_at`
+
+func scanAgreement(row scanner) (*agreement.Agreement, error) {
+	a := &agreement.Agreement{}
+	var templateID sql.NullInt64
+	var landlordAt, renterAt *mysql.RawTime
+	var landlordIP, renterIP string
+	var createdAt mysql.RawTime
+	err := row.Scan(&a.ID, &a.BookingID, &a.LotID, &a.LandlordID, &a.RenterID, &a.Version, &templateID,
+		&a.Text, &a.Hash, &landlordAt, &landlordIP, &renterAt, &renterIP, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if a.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	if templateID.Valid {
+		id := uint(templateID.Int64)
+		a.TemplateID = &id
+	}
+	if a.LandlordAcceptance, err = acceptance(landlordAt, landlordIP); err != nil {
+		return nil, err
+	}
+	if a.RenterAcceptance, err = acceptance(renterAt, renterIP); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func acceptance(at *mysql.RawTime, ip string) (*agreement.Acceptance, error) {
+	if at == nil {
+		return nil, nil
+	}
+	t, err := at.Time()
+	if err != nil {
+		return nil, err
+	}
+	return &agreement.Acceptance{At: t, IP: ip}, nil
+}
+
+func findAgreement(ctx context.Context, q querier, id uint, forUpdate bool) (*agreement.Agreement, error) {
+	queryString := `
+	SELECT` + agreementColumns + `
+	FROM agreements
+	WHERE agreement_id=?`
+	if forUpdate {
+		queryString += ` FOR UPDATE`
+	}
+
+	a, err := scanAgreement(q.QueryRowContext(ctx, queryString, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// findLatest returns the latest version of agreement of the booking or nil, if there are no versions.
+func findLatest(ctx context.Context, q querier, bookingID uint, forUpdate bool) (*agreement.Agreement, error) {
+	queryString := `
+	SELECT` + agreementColumns + `
+	FROM agreements
+	WHERE booking_id=?
+	ORDER BY version DESC
+	LIMIT 1`
+	if forUpdate {
+		queryString += ` FOR UPDATE`
+	}
+
+	a, err := scanAgreement(q.QueryRowContext(ctx, queryString, bookingID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return a, err
+}
+
+// lockBooking serializes changes of agreements of the booking.
+func lockBooking(ctx context.Context, tx *sql.Tx, bookingID uint) error {
+	var id uint
+	err := tx.QueryRowContext(ctx, `SELECT booking_id FROM bookings WHERE booking_id=? FOR UPDATE;`, bookingID).
+		Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.ErrNotFound
+	}
+	return err
+}
+
+func (s *db) Create(ctx context.Context, a *agreement.Agreement) (*agreement.Agreement, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = lockBooking(ctx, tx, a.BookingID); err != nil {
+		return nil, err
+	}
+	latest, err := findLatest(ctx, tx, a.BookingID, false)
+	if err != nil {
+		return nil, err
+	}
+	version := 1
+	if latest != nil {
+		if latest.Signed() {
+			return nil, apperror.ConflictError("agreement is accepted by both parties already")
+		}
+		version = latest.Version + 1
+	}
+
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO agreements (booking_id, lot_id, landlord_id, renter_id, version, template_id, text, hash, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		a.BookingID, a.LotID, a.LandlordID, a.RenterID, version, a.TemplateID, a.Text, a.Hash, a.CreatedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := findAgreement(ctx, tx, uint(retID), false)
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func (s *db) FindByID(ctx context.Context, id uint) (*agreement.Agreement, error) {
+	return findAgreement(ctx, s.db, id, false)
+}
+
+func (s *db) FindByBookingID(ctx context.Context, bookingID uint) ([]*agreement.Agreement, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT`+agreementColumns+`
+	FROM agreements
+	WHERE booking_id=?
+	ORDER BY version DESC;`, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	agreements := make([]*agreement.Agreement, 0)
+	for rows.Next() {
+		a, err := scanAgreement(rows)
+		if err != nil {
+			return nil, err
+		}
+		agreements = append(agreements, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return agreements, nil
+}
+
+func (s *db) Accept(ctx context.Context, id uint, party booking.Party,
+	acceptance *agreement.Acceptance) (*agreement.Agreement, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	a, err := findAgreement(ctx, tx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := findLatest(ctx, tx, a.BookingID, true)
+	if err != nil {
+		return nil, err
+	}
+	if latest.ID != a.ID {
+		return nil, apperror.ConflictError("agreement has newer version")
+	}
+	if latest.AcceptanceOf(party) != nil {
+		return nil, apperror.ConflictError("agreement is accepted by the party already")
+	}
+
+	columns := "renter_accepted_at=?, renter_ip=?"
+	if party == booking.PartyLandlord {
+		columns = "landlord_accepted_at=?, landlord_ip=?"
+	}
+	queryString := fmt.Sprintf(`UPDATE agreements SET %s WHERE agreement_id=?;`, columns)
+	if _, err = tx.ExecContext(ctx, queryString, acceptance.At.UTC(), acceptance.IP, id); err != nil {
+		return nil, err
+	}
+
+	accepted, err := findAgreement(ctx, tx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	return accepted, tx.Commit()
+}
+
+func (s *db) FindPerson(ctx context.Context, userID uint) (*agreement.Person, error) {
+	p := &agreement.Person{ID: userID}
+	var username, givenName, familyName string
+	err := s.db.QueryRowContext(ctx, `
+	SELECT username, IFNULL(given_name, ''), IFNULL(family_name, ''), email, IFNULL(phone, '')
+	FROM users
+	WHERE user_id=?;`, userID).Scan(&username, &givenName, &familyName, &p.Email, &p.Phone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	p.Name = strings.TrimSpace(givenName + " " + familyName)
+	if p.Name == "" {
+		p.Name = username
+	}
+	return p, nil
+}
@@ -0,0 +1,108 @@
+package agreement
+
+import (
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/pdf"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// block is a heading or a paragraph of agreement text.
+type block struct {
+	Heading bool
+	Text    string
+}
+
+func blocks(text string) []block {
+	var res []block
+	for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		p = strings.Trim(p, "\n")
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		if strings.HasPrefix(p, "# ") {
+			res = append(res, block{Heading: true, Text: strings.TrimPrefix(p, "# ")})
+			continue
+		}
+		res = append(res, block{Text: p})
+	}
+	return res
+}
+
+// footer identifies the version, so printed pages can be matched with the accepted text.
+func (a *Agreement) footer() string {
+	return fmt.Sprintf("Версия %d · SHA-256 %s", a.Version, a.Hash)
+}
+
+// acceptances describes evidence of acceptance by the parties.
+func (a *Agreement) acceptances() []string {
+	parties := []struct {
+		party booking.Party
+		name  string
+	}{{booking.PartyLandlord, "Арендодатель"}, {booking.PartyRenter, "Арендатор"}}
+
+	lines := make([]string, 0, len(parties))
+	for _, p := range parties {
+		acceptance := a.AcceptanceOf(p.party)
+		if acceptance == nil {
+			lines = append(lines, fmt.Sprintf("%s: не принят", p.name))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: принят %s UTC с IP-адреса %s",
+			p.name, acceptance.At.UTC().Format("02.01.2006 15:04:05"), acceptance.IP))
+	}
+	return lines
+}
+
+var htmlTemplate = template.Must(template.New("agreement").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Договор № {{.Agreement.BookingID}}-{{.Agreement.Version}}</title>
+<style>
+body { font-family: serif; max-width: 50em; margin: 2em auto; line-height: 1.4; }
+h2 { text-align: center; font-size: 1.1em; }
+p { white-space: pre-line; text-align: justify; }
+footer { margin-top: 3em; font-size: 0.8em; color: #555; word-break: break-all; }
+</style>
+</head>
+<body>
+{{range .Blocks}}{{if .Heading}}<h2>{{.Text}}</h2>{{else}}<p>{{.Text}}</p>{{end}}
+{{end}}<footer>
+{{range .Acceptances}}<div>{{.}}</div>
+{{end}}<div>{{.Footer}}</div>
+</footer>
+</body>
+</html>
+`))
+
+// WriteHTML writes the version as HTML page.
+func WriteHTML(w io.Writer, a *Agreement) error {
+	return htmlTemplate.Execute(w, struct {
+		Agreement   *Agreement
+		Blocks      []block
+		Acceptances []string
+		Footer      string
+	}{a, blocks(a.Text), a.acceptances(), a.footer()})
+}
+
+// WritePDF writes the version as PDF document with the font.
+func WritePDF(w io.Writer, font *pdf.Font, a *Agreement) error {
+	d := pdf.New(font)
+	d.Title = fmt.Sprintf("Договор № %d-%d", a.BookingID, a.Version)
+	d.Created = a.CreatedAt
+	d.Footer = a.footer()
+
+	for _, b := range blocks(a.Text) {
+		if b.Heading {
+			d.Heading(b.Text)
+		} else {
+			d.Paragraph(b.Text)
+		}
+	}
+	d.Paragraph(strings.Join(a.acceptances(), "\n"))
+
+	return d.Write(w)
+}
@@ -0,0 +1,133 @@
+package agreement
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	"time"
+)
+
+const (
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+)
+
+// MaxTemplateSize limits body of templates in characters.
+const MaxTemplateSize = 64 << 10
+
+// Acceptance is evidence, that the party has accepted the version of agreement.
+type Acceptance struct {
+	At time.Time `json:"at"`
+	IP string    `json:"ip"`
+}
+
+// Agreement is a version of rental agreement of accepted booking. Text of the version never changes,
+// changes of agreement make a new version, which has to be accepted again.
+type Agreement struct {
+	ID         uint   `json:"id"`
+	BookingID  uint   `json:"booking_id"`
+	LotID      uint   `json:"lot_id"`
+	LandlordID uint   `json:"landlord_id"`
+	RenterID   uint   `json:"renter_id"`
+	Version    int    `json:"version"`
+	TemplateID *uint  `json:"template_id,omitempty"` // nil for the default template or deleted one
+	Text       string `json:"text"`
+	// Hash is hex SHA-256 of the text, parties send it on acceptance to confirm the text they agree to.
+	Hash               string      `json:"hash"`
+	LandlordAcceptance *Acceptance `json:"landlord_acceptance,omitempty"`
+	RenterAcceptance   *Acceptance `json:"renter_acceptance,omitempty"`
+	CreatedAt          time.Time   `json:"created_at"`
+}
+
+// Signed reports whether both parties accepted the version.
+func (a *Agreement) Signed() bool {
+	return a.LandlordAcceptance != nil && a.RenterAcceptance != nil
+}
+
+// PartyOf returns the side of the agreement the user belongs to.
+func (a *Agreement) PartyOf(userID uint) (booking.Party, bool) {
+	switch userID {
+	case a.RenterID:
+		return booking.PartyRenter, true
+	case a.LandlordID:
+		return booking.PartyLandlord, true
+	default:
+		return "", false
+	}
+}
+
+// AcceptanceOf returns acceptance of the party or nil, if the party has not accepted the version.
+func (a *Agreement) AcceptanceOf(party booking.Party) *Acceptance {
+	if party == booking.PartyLandlord {
+		return a.LandlordAcceptance
+	}
+	return a.RenterAcceptance
+}
+
+// Hash returns hex SHA-256 of the text.
+func Hash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Template is text/template of agreement, which landlords use instead of the default one.
+type Template struct {
+	ID        uint      `json:"id"`
+	OwnerID   uint      `json:"owner_id"`
+	Name      string    `json:"name"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Document is rendered agreement.
+type Document struct {
+	ContentType string
+	FileName    string
+	Data        []byte
+}
+
+type CreateTemplateDTO struct {
+	OwnerID uint   `json:"owner_id"`
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+}
+
+// CreateAgreementDTO makes new version of agreement of the booking from the template,
+// zero TemplateID stands for the default template.
+type CreateAgreementDTO struct {
+	BookingID  uint `json:"booking_id"`
+	UserID     uint `json:"user_id"`
+	TemplateID uint `json:"template_id"`
+}
+
+type AcceptDTO struct {
+	ID     uint   `json:"id"`
+	UserID uint   `json:"user_id"`
+	Hash   string `json:"hash"`
+	IP     string `json:"ip"`
+}
+
+func (dto *CreateTemplateDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.OwnerID, validation.Required),
+		validation.Field(&dto.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&dto.Body, validation.Required, validation.Length(1, MaxTemplateSize)),
+	)
+}
+
+func (dto *CreateAgreementDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.BookingID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+	)
+}
+
+func (dto *AcceptDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Hash, validation.Required, validation.Length(64, 64)),
+		validation.Field(&dto.IP, validation.Required),
+	)
+}
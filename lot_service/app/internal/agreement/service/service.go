@@ -0,0 +1,335 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	bookingStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/pdf"
+	"time"
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	CreateTemplate(ctx context.Context, dto *agreement.CreateTemplateDTO) (*agreement.Template, error)
+	// GetTemplates returns the default template followed by templates of the user.
+	GetTemplates(ctx context.Context, userID uint) ([]*agreement.Template, error)
+	GetTemplate(ctx context.Context, id, userID uint) (*agreement.Template, error)
+	DeleteTemplate(ctx context.Context, id, userID uint) error
+
+	// Create makes new version of agreement of accepted booking. Only the landlord can make agreements.
+	Create(ctx context.Context, dto *agreement.CreateAgreementDTO) (*agreement.Agreement, error)
+	GetByBookingID(ctx context.Context, bookingID, userID uint) ([]*agreement.Agreement, error)
+	GetByID(ctx context.Context, id, userID uint) (*agreement.Agreement, error)
+	// GetDocument renders the version in html or pdf format.
+	GetDocument(ctx context.Context, id, userID uint, format string) (*agreement.Document, error)
+	// Accept records acceptance of the latest version by the party, the other party is notified.
+	Accept(ctx context.Context, dto *agreement.AcceptDTO) (*agreement.Agreement, error)
+}
+
+type Config struct {
+	// FontPath is TrueType font of PDF documents, it must support Cyrillic. Empty path or font, which fails
+	// to load, disables PDF.
+	FontPath string
+}
+
+type service struct {
+	repository storage.Repository
+	bookings   bookingStorage.Repository
+	lots       lotStorage.Repository
+	events     eventService.Publisher
+	font       *pdf.Font
+	logger     logging.Logger
+}
+
+func NewService(agreementStorage storage.Repository, bookings bookingStorage.Repository, lots lotStorage.Repository,
+	events eventService.Publisher, cfg Config, logger logging.Logger) (*service, error) {
+	s := &service{
+		repository: agreementStorage,
+		bookings:   bookings,
+		lots:       lots,
+		events:     events,
+		logger:     logger,
+	}
+	if cfg.FontPath != "" {
+		font, err := pdf.LoadFont(cfg.FontPath)
+		if err != nil {
+			logger.Warnf("failed to load font of agreements, pdf documents are disabled. error: %v", err)
+		} else {
+			s.font = font
+		}
+	}
+	return s, nil
+}
+
+func (s *service) CreateTemplate(ctx context.Context, dto *agreement.CreateTemplateDTO) (*agreement.Template, error) {
+	s.logger.Debug("validating template fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	if err := agreement.CheckTemplate(dto.Body); err != nil {
+		return nil, apperror.BadRequestError("invalid template", err.Error())
+	}
+
+	t := &agreement.Template{
+		OwnerID:   dto.OwnerID,
+		Name:      dto.Name,
+		Body:      dto.Body,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	s.logger.Debug("creating new agreement template..")
+	id, err := s.repository.CreateTemplate(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agreement template. error: %w", err)
+	}
+	t.ID = id
+	return t, nil
+}
+
+func (s *service) GetTemplates(ctx context.Context, userID uint) ([]*agreement.Template, error) {
+	templates, err := s.repository.FindTemplates(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find agreement templates. error: %w", err)
+	}
+	return append([]*agreement.Template{defaultTemplate()}, templates...), nil
+}
+
+func defaultTemplate() *agreement.Template {
+	return &agreement.Template{Name: agreement.DefaultTemplateName, Body: agreement.DefaultTemplate}
+}
+
+func (s *service) GetTemplate(ctx context.Context, id, userID uint) (*agreement.Template, error) {
+	if id == 0 {
+		return defaultTemplate(), nil
+	}
+
+	t, err := s.repository.FindTemplate(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find agreement template. error: %w", err)
+	}
+	if t.OwnerID != userID {
+		return nil, apperror.ErrNotFound
+	}
+	return t, nil
+}
+
+func (s *service) DeleteTemplate(ctx context.Context, id, userID uint) error {
+	if err := s.repository.DeleteTemplate(ctx, id, userID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete agreement template. error: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Create(ctx context.Context, dto *agreement.CreateAgreementDTO) (*agreement.Agreement, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	b, err := s.findBooking(ctx, dto.BookingID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if b.LandlordID != dto.UserID {
+		return nil, apperror.ForbiddenError("only the landlord can make agreement")
+	}
+	if b.Status != booking.StatusAccepted {
+		return nil, apperror.ConflictError("agreement can be made only for accepted booking")
+	}
+
+	t, err := s.GetTemplate(ctx, dto.TemplateID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.data(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	text, err := agreement.Render(t.Body, data)
+	if err != nil {
+		return nil, apperror.BadRequestError("failed to fill template", err.Error())
+	}
+
+	a := &agreement.Agreement{
+		BookingID:  b.ID,
+		LotID:      b.LotID,
+		LandlordID: b.LandlordID,
+		RenterID:   b.RenterID,
+		Text:       text,
+		Hash:       agreement.Hash(text),
+		CreatedAt:  data.Date,
+	}
+	if t.ID != 0 {
+		a.TemplateID = &t.ID
+	}
+
+	s.logger.Debug("creating new version of agreement..")
+	a, err = s.repository.Create(ctx, a)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create agreement. error: %w", err)
+	}
+
+	s.events.Publish(ctx, a.RenterID, event.TypeAgreement, a)
+	return a, nil
+}
+
+// data collects data of the booking for templates.
+func (s *service) data(ctx context.Context, b *booking.Booking) (*agreement.Data, error) {
+	l, err := s.lots.FindByLotID(ctx, b.LotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot. error: %w", err)
+	}
+	landlord, err := s.repository.FindPerson(ctx, b.LandlordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find landlord. error: %w", err)
+	}
+	renter, err := s.repository.FindPerson(ctx, b.RenterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find renter. error: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	return &agreement.Data{
+		Number:   fmt.Sprintf("%d", b.ID),
+		Date:     now,
+		Lot:      l,
+		Landlord: *landlord,
+		Renter:   *renter,
+		CheckIn:  b.CheckIn,
+		CheckOut: b.CheckOut,
+		Nights:   int(b.CheckOut.Sub(b.CheckIn).Hours() / 24),
+	}, nil
+}
+
+func (s *service) GetByBookingID(ctx context.Context, bookingID, userID uint) ([]*agreement.Agreement, error) {
+	if _, err := s.findBooking(ctx, bookingID, userID); err != nil {
+		return nil, err
+	}
+
+	agreements, err := s.repository.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find agreements of booking. error: %w", err)
+	}
+	return agreements, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id, userID uint) (*agreement.Agreement, error) {
+	a, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find agreement. error: %w", err)
+	}
+	if _, ok := a.PartyOf(userID); !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return a, nil
+}
+
+func (s *service) GetDocument(ctx context.Context, id, userID uint, format string) (*agreement.Document, error) {
+	a, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	doc := &agreement.Document{FileName: fmt.Sprintf("agreement-%d-%d.%s", a.BookingID, a.Version, format)}
+	switch format {
+	case agreement.FormatHTML:
+		doc.ContentType = "text/html; charset=utf-8"
+		err = agreement.WriteHTML(&buf, a)
+	case agreement.FormatPDF:
+		if s.font == nil {
+			return nil, apperror.BadRequestError("pdf documents are not available", "font is not configured")
+		}
+		doc.ContentType = "application/pdf"
+		err = agreement.WritePDF(&buf, s.font, a)
+	default:
+		return nil, apperror.BadRequestError("format must be either html or pdf", "")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render agreement. error: %w", err)
+	}
+
+	doc.Data = buf.Bytes()
+	return doc, nil
+}
+
+func (s *service) Accept(ctx context.Context, dto *agreement.AcceptDTO) (*agreement.Agreement, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	a, err := s.GetByID(ctx, dto.ID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Hash != dto.Hash {
+		return nil, apperror.ConflictError("hash doesn't match text of the agreement")
+	}
+	b, err := s.findBooking(ctx, a.BookingID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if b.Status != booking.StatusAccepted {
+		return nil, apperror.ConflictError("booking of the agreement is not accepted")
+	}
+	party, _ := a.PartyOf(dto.UserID)
+
+	a, err = s.repository.Accept(ctx, a.ID, party, &agreement.Acceptance{
+		At: time.Now().UTC().Truncate(time.Second),
+		IP: dto.IP,
+	})
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to accept agreement. error: %w", err)
+	}
+
+	other := a.RenterID
+	if party == booking.PartyRenter {
+		other = a.LandlordID
+	}
+	s.events.Publish(ctx, other, event.TypeAgreement, a)
+	return a, nil
+}
+
+// findBooking returns the booking to its party.
+func (s *service) findBooking(ctx context.Context, id, userID uint) (*booking.Booking, error) {
+	b, err := s.bookings.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find booking. error: %w", err)
+	}
+	if _, ok := b.PartyOf(userID); !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return b, nil
+}
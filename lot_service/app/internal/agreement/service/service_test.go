@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	bookingStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"strings"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	storage.Repository
+	versions []*agreement.Agreement
+}
+
+func (r *repo) Create(_ context.Context, a *agreement.Agreement) (*agreement.Agreement, error) {
+	copied := *a
+	copied.ID = uint(len(r.versions) + 1)
+	copied.Version = len(r.versions) + 1
+	r.versions = append(r.versions, &copied)
+	return &copied, nil
+}
+
+func (r *repo) FindByID(_ context.Context, id uint) (*agreement.Agreement, error) {
+	if id == 0 || int(id) > len(r.versions) {
+		return nil, apperror.ErrNotFound
+	}
+	copied := *r.versions[id-1]
+	return &copied, nil
+}
+
+func (r *repo) Accept(_ context.Context, id uint, party booking.Party,
+	acceptance *agreement.Acceptance) (*agreement.Agreement, error) {
+	a := r.versions[id-1]
+	if party == booking.PartyLandlord {
+		a.LandlordAcceptance = acceptance
+	} else {
+		a.RenterAcceptance = acceptance
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (r *repo) FindPerson(_ context.Context, userID uint) (*agreement.Person, error) {
+	return &agreement.Person{ID: userID, Name: map[uint]string{10: "Пётр Петров", 20: "Иван Иванов"}[userID]}, nil
+}
+
+type bookings struct {
+	bookingStorage.Repository
+	booking *booking.Booking
+}
+
+func (b *bookings) FindByID(_ context.Context, _ uint) (*booking.Booking, error) {
+	return b.booking, nil
+}
+
+type lots struct {
+	lotStorage.Repository
+}
+
+func (l *lots) FindByLotID(_ context.Context, id uint) (*lot.Lot, error) {
+	return &lot.Lot{ID: id, CreatedByUserID: 20, TypeOfEstate: "квартира", City: "Москва", Price: 45000}, nil
+}
+
+type publisher struct {
+	recipients []uint
+}
+
+func (p *publisher) Publish(_ context.Context, userID uint, _ string, _ any) {
+	p.recipients = append(p.recipients, userID)
+}
+
+func newService(status booking.Status) (*service, *repo, *publisher) {
+	r, p := &repo{}, &publisher{}
+	b := &booking.Booking{
+		ID: 1, LotID: 2, RenterID: 10, LandlordID: 20, Status: status,
+		CheckIn:  time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+		CheckOut: time.Date(2026, 11, 9, 0, 0, 0, 0, time.UTC),
+	}
+	s, _ := NewService(r, &bookings{booking: b}, &lots{}, p, Config{}, logging.GetLogger())
+	return s, r, p
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+
+	s, _, _ := newService(booking.StatusAccepted)
+	if _, err := s.Create(ctx, &agreement.CreateAgreementDTO{BookingID: 1, UserID: 10}); !sameError(err,
+		apperror.ForbiddenError("")) {
+		t.Errorf("expected forbidden error for renter, got %v", err)
+	}
+
+	s, _, _ = newService(booking.StatusPending)
+	if _, err := s.Create(ctx, &agreement.CreateAgreementDTO{BookingID: 1, UserID: 20}); !sameError(err,
+		apperror.ConflictError("")) {
+		t.Errorf("expected conflict for pending booking, got %v", err)
+	}
+
+	s, _, p := newService(booking.StatusAccepted)
+	a, err := s.Create(ctx, &agreement.CreateAgreementDTO{BookingID: 1, UserID: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Иван Иванов", "Пётр Петров", "45 000 руб.", "ночей: 7"} {
+		if !strings.Contains(a.Text, want) {
+			t.Errorf("expected %q in agreement", want)
+		}
+	}
+	if a.Hash != agreement.Hash(a.Text) {
+		t.Error("hash must be of the text")
+	}
+	if len(p.recipients) != 1 || p.recipients[0] != 10 {
+		t.Errorf("renter must be notified, got %v", p.recipients)
+	}
+}
+
+func TestAccept(t *testing.T) {
+	ctx := context.Background()
+	s, _, p := newService(booking.StatusAccepted)
+	a, err := s.Create(ctx, &agreement.CreateAgreementDTO{BookingID: 1, UserID: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.Accept(ctx, &agreement.AcceptDTO{ID: a.ID, UserID: 10, Hash: strings.Repeat("0", 64), IP: "10.0.0.1"})
+	if !sameError(err, apperror.ConflictError("")) {
+		t.Errorf("expected conflict for other text, got %v", err)
+	}
+	_, err = s.Accept(ctx, &agreement.AcceptDTO{ID: a.ID, UserID: 11, Hash: a.Hash, IP: "10.0.0.1"})
+	if !errors.Is(err, apperror.ErrNotFound) {
+		t.Errorf("expected not found for stranger, got %v", err)
+	}
+
+	p.recipients = nil
+	accepted, err := s.Accept(ctx, &agreement.AcceptDTO{ID: a.ID, UserID: 10, Hash: a.Hash, IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepted.RenterAcceptance == nil || accepted.RenterAcceptance.IP != "10.0.0.1" {
+		t.Errorf("acceptance of renter must be recorded with IP, got %+v", accepted.RenterAcceptance)
+	}
+	if accepted.Signed() {
+		t.Error("agreement must not be signed before the landlord accepts it")
+	}
+	if len(p.recipients) != 1 || p.recipients[0] != 20 {
+		t.Errorf("landlord must be notified, got %v", p.recipients)
+	}
+}
+
+// sameError compares app errors by code, since their messages differ.
+func sameError(err, want error) bool {
+	var got, expected *apperror.AppError
+	if !errors.As(err, &got) || !errors.As(want, &expected) {
+		return false
+	}
+	return got.Code == expected.Code
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+)
+
+type Repository interface {
+	CreateTemplate(ctx context.Context, t *agreement.Template) (uint, error)
+	FindTemplate(ctx context.Context, id uint) (*agreement.Template, error)
+	FindTemplates(ctx context.Context, ownerID uint) ([]*agreement.Template, error)
+	// DeleteTemplate deletes template of the owner, agreements made from it keep their text.
+	DeleteTemplate(ctx context.Context, id, ownerID uint) error
+
+	// Create saves the next version of agreement of the booking. New versions can't be made
+	// after the latest one is accepted by both parties.
+	Create(ctx context.Context, a *agreement.Agreement) (*agreement.Agreement, error)
+	FindByID(ctx context.Context, id uint) (*agreement.Agreement, error)
+	// FindByBookingID returns all versions of agreement of the booking, the latest first.
+	FindByBookingID(ctx context.Context, bookingID uint) ([]*agreement.Agreement, error)
+	// Accept records acceptance of the party. Only the latest version can be accepted, once by each party.
+	Accept(ctx context.Context, id uint, party booking.Party, acceptance *agreement.Acceptance) (*agreement.Agreement, error)
+
+	// FindPerson returns contact data of the user for agreements.
+	FindPerson(ctx context.Context, userID uint) (*agreement.Person, error)
+}
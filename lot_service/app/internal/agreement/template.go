@@ -0,0 +1,93 @@
+package agreement
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultTemplate is used, when the landlord has not chosen own one.
+//
+//go:embed default.tmpl
+var DefaultTemplate string
+
+const DefaultTemplateName = "Договор аренды (по умолчанию)"
+
+// Person is a party of agreement.
+type Person struct {
+	ID    uint
+	Name  string
+	Email string
+	Phone string
+}
+
+// Data is filled into templates. Lines of rendered text starting with "# " are headings,
+// paragraphs are separated by empty lines.
+type Data struct {
+	Number   string // of the agreement, it's ID of the booking
+	Date     time.Time
+	Lot      *lot.Lot
+	Landlord Person
+	Renter   Person
+	CheckIn  time.Time
+	CheckOut time.Time
+	Nights   int
+}
+
+var funcs = template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format("02.01.2006")
+	},
+	// money formats amount with spaces between thousands
+	"money": func(amount int) string {
+		s := fmt.Sprint(amount)
+		var b strings.Builder
+		for i, r := range s {
+			if i > 0 && (len(s)-i)%3 == 0 && s[i-1] != '-' {
+				b.WriteRune(' ')
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	},
+}
+
+// sampleData is used to check templates before they are saved.
+var sampleData = &Data{
+	Number:   "1",
+	Date:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	Lot:      &lot.Lot{ID: 1, TypeOfEstate: "квартира", Rooms: 1, Area: 30, Floor: 1, MaxFloor: 5, City: "Москва"},
+	Landlord: Person{ID: 1, Name: "Арендодатель"},
+	Renter:   Person{ID: 2, Name: "Арендатор"},
+	CheckIn:  time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	CheckOut: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+	Nights:   1,
+}
+
+// CheckTemplate parses the template and renders it with sample data, so errors of templates
+// are found when they are saved rather than when agreements are made.
+func CheckTemplate(body string) error {
+	_, err := Render(body, sampleData)
+	return err
+}
+
+// Render fills the template with data.
+func Render(body string, data *Data) (string, error) {
+	tmpl, err := template.New("agreement").Funcs(funcs).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err = tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(b.String())
+	if text == "" {
+		return "", fmt.Errorf("template renders empty text")
+	}
+	return text, nil
+}
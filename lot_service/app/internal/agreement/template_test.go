@@ -0,0 +1,45 @@
+package agreement
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultTemplate(t *testing.T) {
+	text, err := Render(DefaultTemplate, sampleData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"ПОМЕЩЕНИЯ № 1\n", "с 02.01.2026 по 03.01.2026 (ночей: 1)", "Арендатор: Арендатор"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in agreement", want)
+		}
+	}
+	if b := blocks(text); !b[0].Heading || !strings.HasPrefix(b[0].Text, "ДОГОВОР АРЕНДЫ") {
+		t.Errorf("agreement must start with heading, got %+v", b[0])
+	}
+}
+
+func TestCheckTemplate(t *testing.T) {
+	tests := map[string]bool{
+		"Договор № {{.Number}} на {{money .Lot.Price}} руб.": true,
+		"{{.Lot.Owner}}":      false,
+		"{{.Number":           false,
+		"{{if false}}{{end}}": false,
+	}
+	for body, valid := range tests {
+		if err := CheckTemplate(body); (err == nil) != valid {
+			t.Errorf("%q: expected valid %v, got error %v", body, valid, err)
+		}
+	}
+}
+
+func TestMoney(t *testing.T) {
+	money := funcs["money"].(func(int) string)
+	for amount, want := range map[int]string{0: "0", 999: "999", 45000: "45 000", 1234567: "1 234 567", -1500: "-1 500"} {
+		if got := money(amount); got != want {
+			t.Errorf("%d: expected %q, got %q", amount, want, got)
+		}
+	}
+}
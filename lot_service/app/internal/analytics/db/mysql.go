@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ storage.Repository = &db{}
+
+// batch limits number of rows inserted and lots selected by a query.
+const batch = 500
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+// source is a table of events of one kind, events are counted by lot and time.
+type source struct {
+	from  string
+	lotID string
+	at    string
+	where string
+	add   func(c *analytics.Counts, n int)
+}
+
+var sources = []source{
+	{
+		from:  "lot_views e",
+		lotID: "e.lot_id",
+		at:    "e.day",
+		add:   func(c *analytics.Counts, n int) { c.Views += n },
+	},
+	{
+		from:  "favorites e",
+		lotID: "e.lot_id",
+		at:    "e.created_at",
+		add:   func(c *analytics.Counts, n int) { c.Favorites += n },
+	},
+	{
+		from:  "lot_reveals e",
+		lotID: "e.lot_id",
+		at:    "e.created_at",
+		add:   func(c *analytics.Counts, n int) { c.Reveals += n },
+	},
+	{
+		// replies of the landlord aren't counted
+		from:  "messages e JOIN conversations c ON c.conversation_id=e.conversation_id",
+		lotID: "c.lot_id",
+		at:    "e.created_at",
+		where: " AND e.sender_id=c.renter_id",
+		add:   func(c *analytics.Counts, n int) { c.Messages += n },
+	},
+}
+
+func (s *db) CreateViews(ctx context.Context, views []*analytics.View) error {
+	for start := 0; start < len(views); start += batch {
+		end := start + batch
+		if end > len(views) {
+			end = len(views)
+		}
+
+		args := make([]any, 0, 3*(end-start))
+		for _, v := range views[start:end] {
+			args = append(args, v.LotID, v.Day(), v.Viewer())
+		}
+		// views of deleted lots are ignored too
+		_, err := s.db.ExecContext(ctx, `
+		INSERT IGNORE INTO lot_views (lot_id, day, viewer)
+		VALUES (?, ?, ?)`+strings.Repeat(", (?, ?, ?)", end-start-1)+`;`, args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *db) CreateReveal(ctx context.Context, r *analytics.Reveal) error {
+	_, err := s.db.ExecContext(ctx, `
+	INSERT INTO lot_reveals (lot_id, viewer_id)
+	VALUES (?, ?);`, r.LotID, r.ViewerID)
+	return err
+}
+
+func (s *db) CountDaily(ctx context.Context, lotID uint, since time.Time) ([]analytics.DailyCounts, error) {
+	byDate := make(map[string]*analytics.DailyCounts)
+	dates := make([]string, 0)
+	for _, src := range sources {
+		rows, err := s.db.QueryContext(ctx, `
+		SELECT DATE(`+src.at+`) AS date, COUNT(*)
+		FROM `+src.from+`
+		WHERE `+src.lotID+`=? AND `+src.at+`>=?`+src.where+`
+		GROUP BY date;`, lotID, since.UTC())
+		if err != nil {
+			return nil, err
+		}
+		err = scanCounts(rows, func(date string, n int) {
+			d, ok := byDate[date]
+			if !ok {
+				d = &analytics.DailyCounts{Date: date}
+				byDate[date] = d
+				dates = append(dates, date)
+			}
+			src.add(&d.Counts, n)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	days := make([]analytics.DailyCounts, 0, len(dates))
+	for _, date := range dates {
+		days = append(days, *byDate[date])
+	}
+	return days, nil
+}
+
+func (s *db) CountByLots(ctx context.Context, lotIDs []uint, since time.Time) (map[uint]analytics.Counts, error) {
+	counts := make(map[uint]analytics.Counts, len(lotIDs))
+	for start := 0; start < len(lotIDs); start += batch {
+		end := start + batch
+		if end > len(lotIDs) {
+			end = len(lotIDs)
+		}
+
+		args := make([]any, 0, end-start+1)
+		for _, id := range lotIDs[start:end] {
+			args = append(args, id)
+		}
+		args = append(args, since.UTC())
+		for _, src := range sources {
+			rows, err := s.db.QueryContext(ctx, `
+			SELECT `+src.lotID+` AS id, COUNT(*)
+			FROM `+src.from+`
+			WHERE `+src.lotID+` IN (?`+strings.Repeat(", ?", end-start-1)+`) AND `+src.at+`>=?`+src.where+`
+			GROUP BY id;`, args...)
+			if err != nil {
+				return nil, err
+			}
+			err = scanCounts(rows, func(id string, n int) {
+				lotID, _ := strconv.ParseUint(id, 10, 32)
+				c := counts[uint(lotID)]
+				src.add(&c, n)
+				counts[uint(lotID)] = c
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return counts, nil
+}
+
+// scanCounts passes counts to add by their keys, rows are closed.
+func scanCounts(rows *sql.Rows, add func(key string, n int)) error {
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		var n int
+		if err := rows.Scan(&key, &n); err != nil {
+			return err
+		}
+		add(key, n)
+	}
+	return rows.Err()
+}
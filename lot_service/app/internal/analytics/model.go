@@ -0,0 +1,92 @@
+package analytics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"time"
+)
+
+const (
+	// DefDays is number of days in analytics of lots
+	DefDays = 30
+	MaxDays = 365
+)
+
+// View of the lot page. Views are counted once per day for each user or, for anonymous visitors,
+// for each session.
+type View struct {
+	LotID     uint
+	UserID    uint   // zero for anonymous visitors
+	SessionID string // ignored for users
+	ViewedAt  time.Time
+}
+
+// Viewer identifies the visitor without storing the session.
+func (v *View) Viewer() string {
+	key := fmt.Sprintf("s:%s", v.SessionID)
+	if v.UserID != 0 {
+		key = fmt.Sprintf("u:%d", v.UserID)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Day is the date of the view in UTC, views are deduplicated within it.
+func (v *View) Day() string {
+	return v.ViewedAt.UTC().Format("2006-01-02")
+}
+
+// Reveal of the contact of the lot owner. Reveals are made by user_service and reported by api_service.
+type Reveal struct {
+	LotID    uint
+	ViewerID uint
+}
+
+// Counts are numbers of views, additions to favorites, contact reveals and messages from renters.
+type Counts struct {
+	Views     int `json:"views"`
+	Favorites int `json:"favorites"`
+	Reveals   int `json:"reveals"`
+	Messages  int `json:"messages"`
+}
+
+// Add adds counts of another period or lot.
+func (c *Counts) Add(o Counts) {
+	c.Views += o.Views
+	c.Favorites += o.Favorites
+	c.Reveals += o.Reveals
+	c.Messages += o.Messages
+}
+
+type DailyCounts struct {
+	Date string `json:"date"` // YYYY-MM-DD in UTC
+	Counts
+}
+
+// LotAnalytics is activity around the lot for the last days.
+type LotAnalytics struct {
+	LotID      uint          `json:"lot_id"`
+	Days       []DailyCounts `json:"days"` // from the oldest day
+	Total      Counts        `json:"total"`
+	Comparison Comparison    `json:"comparison"`
+}
+
+// Comparison is average activity around similar lots: other lots of the same estate type and number of rooms
+// in the same district for the same days.
+type Comparison struct {
+	City         string  `json:"city"`
+	District     string  `json:"district"`
+	SimilarLots  int     `json:"similar_lots"`
+	AvgViews     float64 `json:"avg_views"`
+	AvgFavorites float64 `json:"avg_favorites"`
+	AvgReveals   float64 `json:"avg_reveals"`
+	AvgMessages  float64 `json:"avg_messages"`
+}
+
+// LotSummary is activity around the lot for the last days in dashboard of its agent.
+type LotSummary struct {
+	Lot   *lot.Lot `json:"lot"`
+	Total Counts   `json:"total"`
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	apiSort "github.com/levelord1311/backendForSharedProject/lot_service/pkg/api/sort"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var _ Service = &service{}
+
+// ViewRecorder records views of lots without waiting for storage.
+type ViewRecorder interface {
+	// RecordView counts view of the lot by the user or by anonymous session. Views of the agent of the lot
+	// and of unknown visitors aren't counted.
+	RecordView(l *lot.Lot, userID uint, sessionID string)
+}
+
+type Service interface {
+	ViewRecorder
+	// SaveViews saves views recorded since the previous call and returns number of saved views.
+	SaveViews(ctx context.Context) (int, error)
+	// RecordReveal counts reveal of the contact of the lot owner to the user. Reveals by the agent
+	// of the lot aren't counted.
+	RecordReveal(ctx context.Context, lotID, userID uint) error
+
+	// GetLotAnalytics returns activity around the lot for the last days, zero days means analytics.DefDays.
+	// Analytics are available to the agent of the lot and to managers of its organization.
+	GetLotAnalytics(ctx context.Context, lotID, userID uint, days int) (*analytics.LotAnalytics, error)
+	// GetDashboard returns activity around lots of the agent for the last days, most viewed first.
+	GetDashboard(ctx context.Context, userID uint, days int) ([]*analytics.LotSummary, error)
+}
+
+type Config struct {
+	// QueueSize limits number of views waiting for saving, views over the limit are dropped
+	QueueSize int
+	// MaxSimilarLots limits number of lots the lot is compared with
+	MaxSimilarLots int
+}
+
+type service struct {
+	repository  storage.Repository
+	lots        lotStorage.Repository
+	lotsService lotService.Service
+	cfg         Config
+	logger      logging.Logger
+
+	views chan *analytics.View
+}
+
+func NewService(analyticsStorage storage.Repository, lots lotStorage.Repository, lotsService lotService.Service,
+	cfg Config, logger logging.Logger) (*service, error) {
+	return &service{
+		repository:  analyticsStorage,
+		lots:        lots,
+		lotsService: lotsService,
+		cfg:         cfg,
+		logger:      logger,
+		views:       make(chan *analytics.View, cfg.QueueSize),
+	}, nil
+}
+
+func (s *service) RecordView(l *lot.Lot, userID uint, sessionID string) {
+	if (userID == 0 && sessionID == "") || userID == l.CreatedByUserID {
+		return
+	}
+
+	v := &analytics.View{LotID: l.ID, UserID: userID, SessionID: sessionID, ViewedAt: time.Now()}
+	select {
+	case s.views <- v:
+	default:
+		s.logger.Warnf("queue of views is full, view of lot %d is dropped", l.ID)
+	}
+}
+
+func (s *service) SaveViews(ctx context.Context) (int, error) {
+	views := make([]*analytics.View, 0, len(s.views))
+	seen := make(map[string]bool, len(s.views))
+	for len(views) < cap(s.views) {
+		var v *analytics.View
+		select {
+		case v = <-s.views:
+		default:
+		}
+		if v == nil {
+			break
+		}
+		key := fmt.Sprintf("%d|%s|%s", v.LotID, v.Day(), v.Viewer())
+		if !seen[key] {
+			seen[key] = true
+			views = append(views, v)
+		}
+	}
+	if len(views) == 0 {
+		return 0, nil
+	}
+
+	if err := s.repository.CreateViews(ctx, views); err != nil {
+		return 0, fmt.Errorf("failed to save views. error: %w", err)
+	}
+	return len(views), nil
+}
+
+func (s *service) RecordReveal(ctx context.Context, lotID, userID uint) error {
+	l, err := s.lots.FindByLotID(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to find lot by its id. error: %w", err)
+	}
+	if userID == l.CreatedByUserID {
+		return nil
+	}
+
+	if err = s.repository.CreateReveal(ctx, &analytics.Reveal{LotID: l.ID, ViewerID: userID}); err != nil {
+		return fmt.Errorf("failed to save reveal. error: %w", err)
+	}
+	return nil
+}
+
+func (s *service) GetLotAnalytics(ctx context.Context, lotID, userID uint, days int) (*analytics.LotAnalytics, error) {
+	since, days, err := period(days)
+	if err != nil {
+		return nil, err
+	}
+	l, err := s.lotsService.FindForChange(ctx, lotID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	daily, err := s.repository.CountDaily(ctx, l.ID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count activity of lot. error: %w", err)
+	}
+	byDate := make(map[string]analytics.Counts, len(daily))
+	for _, d := range daily {
+		byDate[d.Date] = d.Counts
+	}
+
+	a := &analytics.LotAnalytics{
+		LotID: l.ID,
+		Days:  make([]analytics.DailyCounts, 0, days),
+	}
+	for i := 0; i < days; i++ {
+		date := since.AddDate(0, 0, i).Format("2006-01-02")
+		a.Days = append(a.Days, analytics.DailyCounts{Date: date, Counts: byDate[date]})
+		a.Total.Add(byDate[date])
+	}
+
+	if a.Comparison, err = s.compare(ctx, l, since); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *service) GetDashboard(ctx context.Context, userID uint, days int) ([]*analytics.LotSummary, error) {
+	since, _, err := period(days)
+	if err != nil {
+		return nil, err
+	}
+
+	lots, err := s.lots.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find lots of user. error: %w", err)
+	}
+	lotIDs := make([]uint, 0, len(lots))
+	for _, l := range lots {
+		lotIDs = append(lotIDs, l.ID)
+	}
+	counts, err := s.repository.CountByLots(ctx, lotIDs, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count activity of lots. error: %w", err)
+	}
+
+	summaries := make([]*analytics.LotSummary, 0, len(lots))
+	for _, l := range lots {
+		summaries = append(summaries, &analytics.LotSummary{Lot: l, Total: counts[l.ID]})
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Total.Views > summaries[j].Total.Views
+	})
+	return summaries, nil
+}
+
+// compare returns average activity around other lots of the same estate type and number of rooms
+// in the district of the lot.
+func (s *service) compare(ctx context.Context, l *lot.Lot, since time.Time) (analytics.Comparison, error) {
+	c := analytics.Comparison{City: l.City, District: l.District}
+	options, err := lotService.NewQueryOptions(&apiSort.Options{Field: apiSort.DefSort, Order: apiSort.DefOrder}, url.Values{
+		"city":        {l.City},
+		"district":    {l.District},
+		"estate_type": {l.TypeOfEstate},
+		"rooms":       {strconv.Itoa(l.Rooms)},
+	})
+	if err != nil {
+		return c, err
+	}
+	options.WithLimit(s.cfg.MaxSimilarLots + 1)
+
+	similar, err := s.lots.FindWithFilter(ctx, options)
+	if err != nil {
+		return c, fmt.Errorf("failed to find similar lots. error: %w", err)
+	}
+	lotIDs := make([]uint, 0, len(similar))
+	for _, sl := range similar {
+		if sl.ID != l.ID && len(lotIDs) < s.cfg.MaxSimilarLots {
+			lotIDs = append(lotIDs, sl.ID)
+		}
+	}
+	if len(lotIDs) == 0 {
+		return c, nil
+	}
+
+	counts, err := s.repository.CountByLots(ctx, lotIDs, since)
+	if err != nil {
+		return c, fmt.Errorf("failed to count activity of similar lots. error: %w", err)
+	}
+	var total analytics.Counts
+	for _, lc := range counts {
+		total.Add(lc)
+	}
+	c.SimilarLots = len(lotIDs)
+	c.AvgViews = average(total.Views, len(lotIDs))
+	c.AvgFavorites = average(total.Favorites, len(lotIDs))
+	c.AvgReveals = average(total.Reveals, len(lotIDs))
+	c.AvgMessages = average(total.Messages, len(lotIDs))
+	return c, nil
+}
+
+// period returns the first day of the last days in UTC, zero days means analytics.DefDays.
+func period(days int) (time.Time, int, error) {
+	if days == 0 {
+		days = analytics.DefDays
+	}
+	if days < 1 || days > analytics.MaxDays {
+		return time.Time{}, 0, apperror.BadRequestError(fmt.Sprintf("days must be from 1 to %d", analytics.MaxDays), "")
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, 1-days), days, nil
+}
+
+func average(total, n int) float64 {
+	return math.Round(float64(total)/float64(n)*100) / 100
+}
+
+// RunRecorder saves recorded views every interval until ctx is done. Views recorded by then are saved too.
+func RunRecorder(ctx context.Context, s Service, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if _, err := s.SaveViews(context.Background()); err != nil {
+				logger.Errorf("failed to save views. error: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if _, err := s.SaveViews(ctx); err != nil {
+				logger.Errorf("failed to save views. error: %v", err)
+			}
+		}
+	}
+}
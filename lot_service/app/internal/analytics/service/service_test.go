@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	storage.Repository
+	views   []*analytics.View
+	reveals []*analytics.Reveal
+	daily   []analytics.DailyCounts
+	counts  map[uint]analytics.Counts
+	lotIDs  []uint
+}
+
+func (r *repo) CreateViews(_ context.Context, views []*analytics.View) error {
+	r.views = append(r.views, views...)
+	return nil
+}
+
+func (r *repo) CreateReveal(_ context.Context, reveal *analytics.Reveal) error {
+	r.reveals = append(r.reveals, reveal)
+	return nil
+}
+
+func (r *repo) CountDaily(_ context.Context, _ uint, _ time.Time) ([]analytics.DailyCounts, error) {
+	return r.daily, nil
+}
+
+func (r *repo) CountByLots(_ context.Context, lotIDs []uint, _ time.Time) (map[uint]analytics.Counts, error) {
+	r.lotIDs = lotIDs
+	return r.counts, nil
+}
+
+type lots struct {
+	lotStorage.Repository
+	lots []*lot.Lot
+}
+
+func (l *lots) FindByLotID(_ context.Context, id uint) (*lot.Lot, error) {
+	for _, lt := range l.lots {
+		if lt.ID == id {
+			return lt, nil
+		}
+	}
+	return nil, apperror.ErrNotFound
+}
+
+func (l *lots) FindWithFilter(_ context.Context, _ lotStorage.QueryOptions) ([]*lot.Lot, error) {
+	return l.lots, nil
+}
+
+type organizations struct {
+	organizationStorage.Repository
+	roles map[uint]organization.Role
+}
+
+func (o *organizations) FindMember(_ context.Context, organizationID, userID uint) (*organization.Member, error) {
+	role, ok := o.roles[userID]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return &organization.Member{OrganizationID: organizationID, UserID: userID, Role: role}, nil
+}
+
+type lotsService struct {
+	lotService.Service
+	lots          lotStorage.Repository
+	organizations organizationStorage.Repository
+}
+
+func (s *lotsService) FindForChange(ctx context.Context, lotID, userID uint) (*lot.Lot, error) {
+	return lotService.FindForChange(ctx, s.lots, s.organizations, lotID, userID)
+}
+
+func TestSaveViews(t *testing.T) {
+	r := &repo{}
+	s, _ := NewService(r, &lots{}, &lotsService{}, Config{QueueSize: 4}, logging.GetLogger())
+	l := &lot.Lot{ID: 1, CreatedByUserID: 10}
+
+	s.RecordView(l, 10, "")  // agent of the lot
+	s.RecordView(l, 0, "")   // unknown visitor
+	s.RecordView(l, 20, "")  // user
+	s.RecordView(l, 20, "a") // the same user in another session
+	s.RecordView(l, 0, "a")  // anonymous session
+	s.RecordView(l, 0, "b")  // another session
+	s.RecordView(l, 0, "c")  // over size of the queue
+
+	n, err := s.SaveViews(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || len(r.views) != 3 {
+		t.Errorf("expected 3 distinct views saved, got %d", n)
+	}
+	if r.views[1].Viewer() == r.views[2].Viewer() {
+		t.Errorf("views of user and of anonymous session must be different")
+	}
+
+	if n, _ = s.SaveViews(context.Background()); n != 0 {
+		t.Errorf("views must be saved once, saved again %d", n)
+	}
+}
+
+func TestGetLotAnalytics(t *testing.T) {
+	organizationID := uint(1)
+	l := &lot.Lot{ID: 1, CreatedByUserID: 10, OrganizationID: &organizationID, TypeOfEstate: "квартира", Rooms: 2,
+		City: "Москва", District: "Арбат"}
+	today := time.Now().UTC().Format("2006-01-02")
+	r := &repo{
+		daily: []analytics.DailyCounts{{Date: today, Counts: analytics.Counts{Views: 5, Favorites: 1}}},
+		counts: map[uint]analytics.Counts{
+			2: {Views: 4, Reveals: 1},
+			3: {Views: 1, Messages: 2},
+		},
+	}
+	members := &organizations{roles: map[uint]organization.Role{
+		11: organization.RoleManager,
+		12: organization.RoleAgent,
+	}}
+	found := &lots{lots: []*lot.Lot{l, {ID: 2}, {ID: 3}, {ID: 4}}}
+	s, _ := NewService(r, found, &lotsService{lots: found, organizations: members}, Config{MaxSimilarLots: 10},
+		logging.GetLogger())
+	ctx := context.Background()
+
+	for _, userID := range []uint{12, 20} {
+		_, err := s.GetLotAnalytics(ctx, l.ID, userID, 7)
+		var appErr *apperror.AppError
+		if !errors.As(err, &appErr) || appErr.Code != apperror.ForbiddenError("").Code {
+			t.Errorf("user %d must not see analytics of the lot, got %v", userID, err)
+		}
+	}
+	if _, err := s.GetLotAnalytics(ctx, l.ID, 10, analytics.MaxDays+1); err == nil {
+		t.Errorf("expected error for too many days")
+	}
+
+	a, err := s.GetLotAnalytics(ctx, l.ID, 11, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Days) != 7 || a.Days[6].Date != today || a.Days[6].Views != 5 || a.Days[0].Views != 0 {
+		t.Errorf("expected 7 days with views today, got %v", a.Days)
+	}
+	if a.Total != (analytics.Counts{Views: 5, Favorites: 1}) {
+		t.Errorf("unexpected total %v", a.Total)
+	}
+	if len(r.lotIDs) != 3 || r.lotIDs[0] != 2 {
+		t.Errorf("the lot must be compared with other lots only, got %v", r.lotIDs)
+	}
+	c := a.Comparison
+	if c.SimilarLots != 3 || c.AvgViews != 1.67 || c.AvgMessages != 0.67 || c.District != "Арбат" {
+		t.Errorf("unexpected comparison %+v", c)
+	}
+}
+
+func TestRecordReveal(t *testing.T) {
+	r := &repo{}
+	s, _ := NewService(r, &lots{lots: []*lot.Lot{{ID: 1, CreatedByUserID: 10}}}, nil, Config{}, logging.GetLogger())
+	ctx := context.Background()
+
+	if err := s.RecordReveal(ctx, 1, 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordReveal(ctx, 1, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordReveal(ctx, 2, 20); !errors.Is(err, apperror.ErrNotFound) {
+		t.Errorf("expected not found error for unknown lot, got %v", err)
+	}
+	if len(r.reveals) != 1 || r.reveals[0].ViewerID != 20 {
+		t.Errorf("expected only reveal to renter counted, got %d", len(r.reveals))
+	}
+}
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics"
+	"time"
+)
+
+type Repository interface {
+	// CreateViews saves the views, repeated views of the same viewer on the same day are ignored.
+	CreateViews(ctx context.Context, views []*analytics.View) error
+	CreateReveal(ctx context.Context, r *analytics.Reveal) error
+	// CountDaily returns counts of the lot by days since the time, days without activity are omitted.
+	CountDaily(ctx context.Context, lotID uint, since time.Time) ([]analytics.DailyCounts, error)
+	// CountByLots returns total counts of the lots since the time by IDs of the lots, lots without activity
+	// are omitted.
+	CountByLots(ctx context.Context, lotIDs []uint, since time.Time) (map[uint]analytics.Counts, error)
+}
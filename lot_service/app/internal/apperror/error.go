@@ -5,6 +5,11 @@ import (
 	"fmt"
 )
 
+const (
+	forbiddenCode = "RELS-000004"
+	conflictCode  = "RELS-000005"
+)
+
 var (
 	ErrNotFound = NewAppError(nil, "not found", "", "RELS-003000")
 )
@@ -66,6 +71,14 @@ func UnauthorizedError(message string) *AppError {
 	return NewAppError(fmt.Errorf(message), message, "", "RELS-000003")
 }
 
+func ForbiddenError(message string) *AppError {
+	return NewAppError(fmt.Errorf(message), message, "", forbiddenCode)
+}
+
+func ConflictError(message string) *AppError {
+	return NewAppError(fmt.Errorf(message), message, "", conflictCode)
+}
+
 func APIError(message, developerMessage, code string) *AppError {
 	return NewAppError(fmt.Errorf(message), message, developerMessage, code)
 }
@@ -18,9 +18,15 @@ func Middleware(h appHandler) http.HandlerFunc {
 					w.Write(ErrNotFound.Marshal())
 					return
 				}
-				err := err.(*AppError)
-				w.WriteHeader(http.StatusBadRequest)
-				w.Write(err.Marshal())
+				switch appErr.Code {
+				case forbiddenCode:
+					w.WriteHeader(http.StatusForbidden)
+				case conflictCode:
+					w.WriteHeader(http.StatusConflict)
+				default:
+					w.WriteHeader(http.StatusBadRequest)
+				}
+				w.Write(appErr.Marshal())
 				return
 			}
 			w.WriteHeader(418)
@@ -0,0 +1,337 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"time"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+const bookingColumns = `
+	booking_id, lot_id, renter_id, landlord_id, check_in, check_out,
+	IFNULL(message, ""),
+	status, expires_at, total, currency, created_at, redacted_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBooking(row scanner) (*booking.Booking, error) {
+	b := &booking.Booking{}
+	var checkIn, checkOut, expiresAt, createdAt, redactedAt *mysql.RawTime
+	err := row.Scan(
+		&b.ID,
+		&b.LotID,
+		&b.RenterID,
+		&b.LandlordID,
+		&checkIn,
+		&checkOut,
+		&b.Message,
+		&b.Status,
+		&expiresAt,
+		&b.Total,
+		&b.Currency,
+		&createdAt,
+		&redactedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.CheckIn, err = checkIn.Date(); err != nil {
+		return nil, err
+	}
+	if b.CheckOut, err = checkOut.Date(); err != nil {
+		return nil, err
+	}
+	if expiresAt != nil {
+		t, err := expiresAt.Time()
+		if err != nil {
+			return nil, err
+		}
+		b.ExpiresAt = &t
+	}
+	if b.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	if b.RedactedAt, err = redactedAt.Time(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *db) Create(ctx context.Context, b *booking.Booking) (uint, error) {
+	queryString := `
+	INSERT INTO bookings (lot_id, renter_id, landlord_id, check_in, check_out, message, status, expires_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?);`
+
+	res, err := s.db.ExecContext(ctx, queryString,
+		b.LotID,
+		b.RenterID,
+		b.LandlordID,
+		b.CheckIn.Format(booking.DateLayout),
+		b.CheckOut.Format(booking.DateLayout),
+		b.Message,
+		b.Status,
+		nullTime(b.ExpiresAt),
+	)
+	if err != nil {
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), nil
+}
+
+func (s *db) FindByID(ctx context.Context, id uint) (*booking.Booking, error) {
+	queryString := `
+	SELECT` + bookingColumns + `
+	FROM bookings
+	WHERE booking_id=?;`
+
+	b, err := scanBooking(s.db.QueryRowContext(ctx, queryString, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *db) FindByRenterID(ctx context.Context, renterID uint) ([]*booking.Booking, error) {
+	return s.findBy(ctx, "renter_id=?", renterID)
+}
+
+func (s *db) FindByLandlordID(ctx context.Context, landlordID uint) ([]*booking.Booking, error) {
+	return s.findBy(ctx, "lot_id IN (SELECT lot_id FROM lots WHERE user_id=?)", landlordID)
+}
+
+func (s *db) FindAcceptedByLotID(ctx context.Context, lotID uint, from time.Time) ([]*booking.Booking, error) {
+	queryString := `
+	SELECT` + bookingColumns + `
+	FROM bookings
+	WHERE lot_id=? AND status=? AND check_out>?
+	ORDER BY check_in;`
+
+	rows, err := s.db.QueryContext(ctx, queryString, lotID, booking.StatusAccepted, from.Format(booking.DateLayout))
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+// findBy must be called only with constant column names.
+func (s *db) findBy(ctx context.Context, condition string, userID uint) ([]*booking.Booking, error) {
+	queryString := `
+	SELECT` + bookingColumns + `
+	FROM bookings
+	WHERE ` + condition + `
+	ORDER BY created_at DESC;`
+
+	rows, err := s.db.QueryContext(ctx, queryString, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+func scanBookings(rows *sql.Rows) ([]*booking.Booking, error) {
+	defer rows.Close()
+
+	bookings := make([]*booking.Booking, 0)
+	for rows.Next() {
+		b, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, b)
+	}
+	if err := rows.Err(); err != nil {
+		return bookings, err
+	}
+	return bookings, nil
+}
+
+func (s *db) Update(ctx context.Context, b *booking.Booking, from booking.Status) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// lock the lot, so two bookings can't be accepted for the same dates concurrently
+	var lotID uint
+	err = tx.QueryRowContext(ctx, `SELECT lot_id FROM lots WHERE lot_id=? FOR UPDATE;`, b.LotID).Scan(&lotID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperror.ErrNotFound
+		}
+		return err
+	}
+
+	if b.Status == booking.StatusAccepted {
+		var overlaps bool
+		err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM bookings
+			WHERE lot_id=? AND booking_id<>? AND status=? AND check_in<? AND check_out>?
+		);`,
+			b.LotID, b.ID, booking.StatusAccepted,
+			b.CheckOut.Format(booking.DateLayout), b.CheckIn.Format(booking.DateLayout)).Scan(&overlaps)
+		if err != nil {
+			return err
+		}
+		if overlaps {
+			return apperror.ConflictError("lot is already booked for these dates")
+		}
+
+		err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM calendar_blocks
+			WHERE lot_id=? AND start_date<? AND end_date>?
+		);`,
+			b.LotID, b.CheckOut.Format(booking.DateLayout), b.CheckIn.Format(booking.DateLayout)).Scan(&overlaps)
+		if err != nil {
+			return err
+		}
+		if overlaps {
+			return apperror.ConflictError("lot is blocked in the calendar for these dates")
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `
+	UPDATE bookings
+	SET landlord_id=?, check_in=?, check_out=?, message=?, status=?, expires_at=?, total=?, currency=?
+	WHERE booking_id=? AND status=?;`,
+		b.LandlordID,
+		b.CheckIn.Format(booking.DateLayout),
+		b.CheckOut.Format(booking.DateLayout),
+		b.Message,
+		b.Status,
+		nullTime(b.ExpiresAt),
+		b.Total,
+		b.Currency,
+		b.ID,
+		from,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ConflictError("booking has been changed, reload it and try again")
+	}
+
+	if err = refreshAvailability(ctx, tx, b.LotID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *db) Expire(ctx context.Context, now time.Time) ([]*booking.Booking, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+	SELECT`+bookingColumns+`
+	FROM bookings
+	WHERE status IN (?, ?) AND expires_at<?
+	FOR UPDATE;`,
+		booking.StatusPending, booking.StatusCountered, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	expired, err := scanBookings(rows)
+	if err != nil || len(expired) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(expired))
+	for _, b := range expired {
+		b.Status = booking.StatusExpired
+		ids = append(ids, b.ID)
+	}
+	sqlQ, args, err := sq.Update("bookings").
+		Set("status", booking.StatusExpired).
+		Where(sq.Eq{"booking_id": ids}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, sqlQ, args...); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+func (s *db) RefreshAvailability(ctx context.Context, today time.Time) error {
+	day := today.Format(booking.DateLayout)
+	_, err := s.db.ExecContext(ctx, `
+	UPDATE lots
+	SET available = NOT EXISTS (
+		SELECT 1 FROM bookings
+		WHERE bookings.lot_id=lots.lot_id AND status=? AND check_in<=? AND check_out>?
+	)
+	WHERE available=FALSE OR lot_id IN (
+		SELECT lot_id FROM bookings
+		WHERE status=? AND check_in<=? AND check_out>?
+	);`,
+		booking.StatusAccepted, day, day,
+		booking.StatusAccepted, day, day)
+	return err
+}
+
+// refreshAvailability sets availability of one lot according to stays of its accepted bookings.
+func refreshAvailability(ctx context.Context, tx *sql.Tx, lotID uint, today time.Time) error {
+	day := today.Format(booking.DateLayout)
+	_, err := tx.ExecContext(ctx, `
+	UPDATE lots
+	SET available = NOT EXISTS (
+		SELECT 1 FROM bookings
+		WHERE lot_id=? AND status=? AND check_in<=? AND check_out>?
+	)
+	WHERE lot_id=?;`,
+		lotID, booking.StatusAccepted, day, day, lotID)
+	return err
+}
+
+func nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
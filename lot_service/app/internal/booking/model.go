@@ -0,0 +1,169 @@
+package booking
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/rules"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // waiting for landlord's answer
+	StatusCountered Status = "countered" // landlord proposed other dates, waiting for renter's answer
+	StatusAccepted  Status = "accepted"
+	StatusDeclined  Status = "declined"
+	StatusCancelled Status = "cancelled"
+	StatusExpired   Status = "expired" // request was not answered in time
+)
+
+type Action string
+
+const (
+	ActionAccept  Action = "accept"
+	ActionDecline Action = "decline"
+	ActionCounter Action = "counter"
+	ActionCancel  Action = "cancel"
+)
+
+// Party is a side of the booking, the one acting on it.
+type Party string
+
+const (
+	PartyRenter   Party = "renter"
+	PartyLandlord Party = "landlord"
+)
+
+var ErrTransitionNotAllowed = errors.New("action is not allowed in current status of booking")
+
+type transition struct {
+	from   Status
+	action Action
+	party  Party
+}
+
+// transitions is the state machine of a booking. Counter offer of the renter returns request to the landlord.
+var transitions = map[transition]Status{
+	{StatusPending, ActionAccept, PartyLandlord}:  StatusAccepted,
+	{StatusPending, ActionDecline, PartyLandlord}: StatusDeclined,
+	{StatusPending, ActionCounter, PartyLandlord}: StatusCountered,
+	{StatusPending, ActionCancel, PartyRenter}:    StatusCancelled,
+
+	{StatusCountered, ActionAccept, PartyRenter}:  StatusAccepted,
+	{StatusCountered, ActionDecline, PartyRenter}: StatusDeclined,
+	{StatusCountered, ActionCounter, PartyRenter}: StatusPending,
+	{StatusCountered, ActionCancel, PartyRenter}:  StatusCancelled,
+
+	{StatusAccepted, ActionCancel, PartyRenter}:   StatusCancelled,
+	{StatusAccepted, ActionCancel, PartyLandlord}: StatusCancelled,
+}
+
+// NextStatus returns status of the booking after the action of the party.
+func NextStatus(from Status, action Action, party Party) (Status, error) {
+	next, ok := transitions[transition{from: from, action: action, party: party}]
+	if !ok {
+		return "", ErrTransitionNotAllowed
+	}
+	return next, nil
+}
+
+// AwaitsAnswer reports whether someone has to answer the booking before it expires.
+func (s Status) AwaitsAnswer() bool {
+	return s == StatusPending || s == StatusCountered
+}
+
+type Booking struct {
+	ID         uint       `json:"id"`
+	LotID      uint       `json:"lot_id"`
+	RenterID   uint       `json:"renter_id"`
+	LandlordID uint       `json:"landlord_id"`
+	CheckIn    time.Time  `json:"check_in"`
+	CheckOut   time.Time  `json:"check_out"`
+	Message    string     `json:"message"` // last message of the party, which made the request or counter offer
+	Status     Status     `json:"status"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // answer is expected before that time
+	Total      int        `json:"total"`                // price of the stay quoted, when the booking was accepted
+	Currency   string     `json:"currency"`             // of the total
+	CreatedAt  time.Time  `json:"created_at"`
+	RedactedAt time.Time  `json:"redacted_at"`
+}
+
+// PartyOf returns the side of the booking the user belongs to.
+func (b *Booking) PartyOf(userID uint) (Party, bool) {
+	switch userID {
+	case b.RenterID:
+		return PartyRenter, true
+	case b.LandlordID:
+		return PartyLandlord, true
+	default:
+		return "", false
+	}
+}
+
+// Overlaps reports whether the stay intersects with period from checkIn to checkOut.
+// Check out day is free for the next check in.
+func (b *Booking) Overlaps(checkIn, checkOut time.Time) bool {
+	return b.CheckIn.Before(checkOut) && checkIn.Before(b.CheckOut)
+}
+
+type CreateBookingDTO struct {
+	LotID    uint   `json:"lot_id"`
+	RenterID uint   `json:"renter_id"`
+	CheckIn  string `json:"check_in"`  // YYYY-MM-DD
+	CheckOut string `json:"check_out"` // YYYY-MM-DD
+	Message  string `json:"message"`
+}
+
+type UpdateBookingDTO struct {
+	ID       uint   `json:"id"`
+	UserID   uint   `json:"user_id"`
+	Action   Action `json:"action"`
+	CheckIn  string `json:"check_in"`  // required for counter offer
+	CheckOut string `json:"check_out"` // required for counter offer
+	Message  string `json:"message"`
+}
+
+func (dto *CreateBookingDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.LotID, validation.Required),
+		validation.Field(&dto.RenterID, validation.Required),
+		validation.Field(&dto.CheckIn, validation.Required, validation.Date(DateLayout)),
+		validation.Field(&dto.CheckOut, validation.Required, validation.Date(DateLayout)),
+		validation.Field(&dto.Message, validation.Length(0, 2000)),
+	)
+}
+
+func (dto *UpdateBookingDTO) ValidateFields() error {
+	isCounter := dto.Action == ActionCounter
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Action, validation.Required, validation.In(
+			ActionAccept, ActionDecline, ActionCounter, ActionCancel)),
+		validation.Field(&dto.CheckIn, validation.By(rules.RequiredIf(isCounter)), validation.Date(DateLayout)),
+		validation.Field(&dto.CheckOut, validation.By(rules.RequiredIf(isCounter)), validation.Date(DateLayout)),
+		validation.Field(&dto.Message, validation.Length(0, 2000)),
+	)
+}
+
+// ParseStay parses and checks dates of the stay. Check in must not be in the past.
+func ParseStay(checkIn, checkOut string, today time.Time) (time.Time, time.Time, error) {
+	in, err := time.Parse(DateLayout, checkIn)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	out, err := time.Parse(DateLayout, checkOut)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !in.Before(out) {
+		return time.Time{}, time.Time{}, errors.New("check out must be after check in")
+	}
+	if in.Before(today) {
+		return time.Time{}, time.Time{}, errors.New("check in must not be in the past")
+	}
+	return in, out, nil
+}
@@ -0,0 +1,53 @@
+package booking
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextStatus(t *testing.T) {
+	cases := []struct {
+		from    Status
+		action  Action
+		party   Party
+		want    Status
+		wantErr bool
+	}{
+		{from: StatusPending, action: ActionAccept, party: PartyLandlord, want: StatusAccepted},
+		{from: StatusPending, action: ActionCounter, party: PartyLandlord, want: StatusCountered},
+		{from: StatusPending, action: ActionAccept, party: PartyRenter, wantErr: true},
+		{from: StatusCountered, action: ActionAccept, party: PartyRenter, want: StatusAccepted},
+		{from: StatusCountered, action: ActionCounter, party: PartyRenter, want: StatusPending},
+		{from: StatusCountered, action: ActionAccept, party: PartyLandlord, wantErr: true},
+		{from: StatusAccepted, action: ActionCancel, party: PartyLandlord, want: StatusCancelled},
+		{from: StatusDeclined, action: ActionAccept, party: PartyLandlord, wantErr: true},
+		{from: StatusExpired, action: ActionCancel, party: PartyRenter, wantErr: true},
+	}
+
+	for _, test := range cases {
+		got, err := NextStatus(test.from, test.action, test.party)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s %s %s: expected error, got %s", test.party, test.action, test.from, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("%s %s %s: got %s, %v; want %s", test.party, test.action, test.from, got, err, test.want)
+		}
+	}
+}
+
+func TestParseStay(t *testing.T) {
+	today := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	if _, _, err := ParseStay("2026-10-19", "2026-10-21", today); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, _, err := ParseStay("2026-10-21", "2026-10-21", today); err == nil {
+		t.Error("expected error for empty stay")
+	}
+	if _, _, err := ParseStay("2026-10-18", "2026-10-21", today); err == nil {
+		t.Error("expected error for check in in the past")
+	}
+}
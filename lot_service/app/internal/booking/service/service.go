@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"time"
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	Create(ctx context.Context, dto *booking.CreateBookingDTO) (*booking.Booking, error)
+	GetByID(ctx context.Context, id, userID uint) (*booking.Booking, error)
+	GetByUser(ctx context.Context, userID uint, party booking.Party) ([]*booking.Booking, error)
+	Update(ctx context.Context, dto *booking.UpdateBookingDTO) (*booking.Booking, error)
+}
+
+// StayQuoter prices stays in lots.
+type StayQuoter interface {
+	QuoteStay(ctx context.Context, l *lot.Lot, checkIn, checkOut time.Time) (*lot.Stay, error)
+}
+
+type service struct {
+	repository      storage.Repository
+	lots            lotStorage.Repository
+	organizations   organizationStorage.Repository
+	quotes          StayQuoter
+	events          eventService.Publisher
+	responseTimeout time.Duration
+	logger          logging.Logger
+}
+
+// NewService returns booking service. Requests, which are not answered during responseTimeout, expire.
+// The other party is notified about new requests and answers with events. Price of the stay is quoted,
+// when the booking is accepted, later changes of prices of the lot don't change it. Landlord of the booking
+// is the current agent of its lot, managers of the lot's organization act on behalf of the agent.
+func NewService(bookingStorage storage.Repository, lots lotStorage.Repository,
+	organizations organizationStorage.Repository, quotes StayQuoter, events eventService.Publisher,
+	responseTimeout time.Duration, logger logging.Logger) (*service, error) {
+	return &service{
+		repository:      bookingStorage,
+		lots:            lots,
+		organizations:   organizations,
+		quotes:          quotes,
+		events:          events,
+		responseTimeout: responseTimeout,
+		logger:          logger,
+	}, nil
+}
+
+func (s *service) Create(ctx context.Context, dto *booking.CreateBookingDTO) (*booking.Booking, error) {
+	s.logger.Debug("validating booking fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	checkIn, checkOut, err := booking.ParseStay(dto.CheckIn, dto.CheckOut, today())
+	if err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	l, err := s.lots.FindByLotID(ctx, dto.LotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot of booking. error: %w", err)
+	}
+	if l.CreatedByUserID == dto.RenterID {
+		return nil, apperror.BadRequestError("own lot can't be booked", "")
+	}
+
+	expiresAt := time.Now().Add(s.responseTimeout)
+	b := &booking.Booking{
+		LotID:      l.ID,
+		RenterID:   dto.RenterID,
+		LandlordID: l.CreatedByUserID,
+		CheckIn:    checkIn,
+		CheckOut:   checkOut,
+		Message:    dto.Message,
+		Status:     booking.StatusPending,
+		ExpiresAt:  &expiresAt,
+	}
+
+	s.logger.Debug("creating new booking..")
+	b.ID, err = s.repository.Create(ctx, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create booking. error: %w", err)
+	}
+
+	s.events.Publish(ctx, b.LandlordID, event.TypeBooking, b)
+	return b, nil
+}
+
+// GetByID returns booking, if the user is one of its parties.
+func (s *service) GetByID(ctx context.Context, id, userID uint) (*booking.Booking, error) {
+	b, err := s.findByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err = s.partyOf(ctx, b, userID); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *service) findByID(ctx context.Context, id uint) (*booking.Booking, error) {
+	b, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find booking by its id. error: %w", err)
+	}
+	return b, nil
+}
+
+// GetByUser returns bookings made by the user as renter or bookings of the user's lots.
+func (s *service) GetByUser(ctx context.Context, userID uint, party booking.Party) ([]*booking.Booking, error) {
+	var bookings []*booking.Booking
+	var err error
+	switch party {
+	case booking.PartyRenter:
+		bookings, err = s.repository.FindByRenterID(ctx, userID)
+	case booking.PartyLandlord:
+		bookings, err = s.repository.FindByLandlordID(ctx, userID)
+	default:
+		return nil, apperror.BadRequestError("party must be either renter or landlord", "")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bookings of user. error: %w", err)
+	}
+	return bookings, nil
+}
+
+// Update applies action of one of the parties to the booking.
+func (s *service) Update(ctx context.Context, dto *booking.UpdateBookingDTO) (*booking.Booking, error) {
+	s.logger.Debug("validating DTO fields..")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	b, err := s.findByID(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	party, l, err := s.partyOf(ctx, b, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Status.AwaitsAnswer() && b.ExpiresAt != nil && time.Now().After(*b.ExpiresAt) {
+		return nil, apperror.ConflictError("booking request has expired")
+	}
+
+	from := b.Status
+	b.Status, err = booking.NextStatus(from, dto.Action, party)
+	if err != nil {
+		return nil, apperror.ConflictError(fmt.Sprintf("%s can't %s %s booking", party, dto.Action, from))
+	}
+
+	if dto.Action == booking.ActionCounter {
+		b.CheckIn, b.CheckOut, err = booking.ParseStay(dto.CheckIn, dto.CheckOut, today())
+		if err != nil {
+			return nil, apperror.BadRequestError(err.Error(), "")
+		}
+	}
+	if dto.Message != "" {
+		b.Message = dto.Message
+	}
+	// the lot could be transferred to another agent since the booking was made
+	b.LandlordID = l.CreatedByUserID
+	if b.Status == booking.StatusAccepted {
+		if err = s.quote(ctx, b, l); err != nil {
+			return nil, err
+		}
+	}
+
+	b.ExpiresAt = nil
+	if b.Status.AwaitsAnswer() {
+		expiresAt := time.Now().Add(s.responseTimeout)
+		b.ExpiresAt = &expiresAt
+	}
+
+	s.logger.Debugf("booking %d: %s -> %s by %s", b.ID, from, b.Status, party)
+	if err = s.repository.Update(ctx, b, from); err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update booking. error: %w", err)
+	}
+
+	otherParty := b.RenterID
+	if party == booking.PartyRenter {
+		otherParty = b.LandlordID
+	}
+	s.events.Publish(ctx, otherParty, event.TypeBooking, b)
+	return b, nil
+}
+
+// partyOf returns the side of the booking the user belongs to and lot of the booking. Landlord side
+// is resolved by the lot, so the user, who has made the booking as agent of the lot, isn't its party after transfer.
+func (s *service) partyOf(ctx context.Context, b *booking.Booking, userID uint) (booking.Party, *lot.Lot, error) {
+	if userID == b.RenterID {
+		l, err := s.lots.FindByLotID(ctx, b.LotID)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return "", nil, err
+			}
+			return "", nil, fmt.Errorf("failed to find lot of booking. error: %w", err)
+		}
+		return booking.PartyRenter, l, nil
+	}
+
+	l, err := lotService.FindForChange(ctx, s.lots, s.organizations, b.LotID, userID)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			// don't tell others that booking exists
+			return "", nil, apperror.ErrNotFound
+		}
+		return "", nil, err
+	}
+	return booking.PartyLandlord, l, nil
+}
+
+// quote saves price of the stay in the lot to the booking.
+func (s *service) quote(ctx context.Context, b *booking.Booking, l *lot.Lot) error {
+	stay, err := s.quotes.QuoteStay(ctx, l, b.CheckIn, b.CheckOut)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return err
+		}
+		return fmt.Errorf("failed to quote stay of booking. error: %w", err)
+	}
+	b.Total, b.Currency = stay.Total, stay.Currency
+	return nil
+}
+
+// RunExpiration expires unanswered requests and refreshes availability of lots, whose stays start or end,
+// every interval until ctx is done. Both parties are notified about expired requests.
+func RunExpiration(ctx context.Context, repository storage.Repository, events eventService.Publisher,
+	interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := repository.Expire(ctx, time.Now())
+			if err != nil {
+				logger.Errorf("failed to expire bookings. error: %v", err)
+			} else if len(expired) > 0 {
+				logger.Infof("%d booking requests expired", len(expired))
+			}
+			for _, b := range expired {
+				events.Publish(ctx, b.RenterID, event.TypeBooking, b)
+				events.Publish(ctx, b.LandlordID, event.TypeBooking, b)
+			}
+
+			if err = repository.RefreshAvailability(ctx, today()); err != nil {
+				logger.Errorf("failed to refresh availability of lots. error: %v", err)
+			}
+		}
+	}
+}
+
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
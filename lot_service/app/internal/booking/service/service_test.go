@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	storage.Repository
+	booking *booking.Booking
+}
+
+func (r *repo) FindByID(_ context.Context, id uint) (*booking.Booking, error) {
+	if id != r.booking.ID {
+		return nil, apperror.ErrNotFound
+	}
+	copied := *r.booking
+	return &copied, nil
+}
+
+func (r *repo) Update(_ context.Context, b *booking.Booking, from booking.Status) error {
+	if r.booking.Status != from {
+		return apperror.ConflictError("")
+	}
+	copied := *b
+	r.booking = &copied
+	return nil
+}
+
+type lots struct {
+	lotStorage.Repository
+	agentID uint
+}
+
+func (l *lots) FindByLotID(_ context.Context, id uint) (*lot.Lot, error) {
+	organizationID := uint(5)
+	return &lot.Lot{ID: id, CreatedByUserID: l.agentID, OrganizationID: &organizationID}, nil
+}
+
+type organizations struct {
+	organizationStorage.Repository
+}
+
+// FindMember returns manager 40 of the organization.
+func (o *organizations) FindMember(_ context.Context, organizationID, userID uint) (*organization.Member, error) {
+	if userID != 40 {
+		return nil, apperror.ErrNotFound
+	}
+	return &organization.Member{OrganizationID: organizationID, UserID: userID, Role: organization.RoleManager}, nil
+}
+
+type quoter struct{}
+
+func (q *quoter) QuoteStay(_ context.Context, _ *lot.Lot, checkIn, checkOut time.Time) (*lot.Stay, error) {
+	return &lot.Stay{Total: int(checkOut.Sub(checkIn).Hours()/24) * 3000, Currency: "RUB"}, nil
+}
+
+type publisher struct {
+	recipients []uint
+}
+
+func (p *publisher) Publish(_ context.Context, userID uint, _ string, _ any) {
+	p.recipients = append(p.recipients, userID)
+}
+
+// TestUpdateAfterTransfer checks that the lot transferred from agent 20 to agent 30 is answered
+// by the new agent, and the previous one isn't a party of its bookings anymore.
+func TestUpdateAfterTransfer(t *testing.T) {
+	checkIn := today().AddDate(0, 1, 0)
+	expiresAt := time.Now().Add(time.Hour)
+	r := &repo{booking: &booking.Booking{
+		ID: 1, LotID: 2, RenterID: 10, LandlordID: 20, Status: booking.StatusPending,
+		CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 3), ExpiresAt: &expiresAt,
+	}}
+	p := &publisher{}
+	s, _ := NewService(r, &lots{agentID: 30}, &organizations{}, &quoter{}, p, time.Hour, logging.GetLogger())
+	ctx := context.Background()
+
+	if _, err := s.GetByID(ctx, 1, 20); !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("previous agent got booking, error: %v", err)
+	}
+	if _, err := s.Update(ctx, &booking.UpdateBookingDTO{ID: 1, UserID: 20, Action: booking.ActionAccept}); !errors.Is(
+		err, apperror.ErrNotFound) {
+		t.Fatalf("previous agent answered booking, error: %v", err)
+	}
+	if _, err := s.GetByID(ctx, 1, 40); err != nil {
+		t.Fatalf("manager of organization didn't get booking, error: %v", err)
+	}
+
+	b, err := s.Update(ctx, &booking.UpdateBookingDTO{ID: 1, UserID: 30, Action: booking.ActionAccept})
+	if err != nil {
+		t.Fatalf("new agent didn't accept booking, error: %v", err)
+	}
+	if b.Status != booking.StatusAccepted || b.Total != 3*3000 {
+		t.Errorf("got booking %s with total %d, want accepted with total %d", b.Status, b.Total, 3*3000)
+	}
+	if r.booking.LandlordID != 30 {
+		t.Errorf("saved landlord %d, want 30", r.booking.LandlordID)
+	}
+
+	if _, err = s.Update(ctx, &booking.UpdateBookingDTO{ID: 1, UserID: 10, Action: booking.ActionCancel}); err != nil {
+		t.Fatalf("renter didn't cancel booking, error: %v", err)
+	}
+	if len(p.recipients) != 2 || p.recipients[0] != 10 || p.recipients[1] != 30 {
+		t.Errorf("notified %v, want [10 30]", p.recipients)
+	}
+}
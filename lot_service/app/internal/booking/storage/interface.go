@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, b *booking.Booking) (uint, error)
+	FindByID(ctx context.Context, id uint) (*booking.Booking, error)
+	FindByRenterID(ctx context.Context, renterID uint) ([]*booking.Booking, error)
+	// FindByLandlordID returns bookings of lots, the landlord is the agent of now.
+	FindByLandlordID(ctx context.Context, landlordID uint) ([]*booking.Booking, error)
+	// FindAcceptedByLotID returns accepted bookings of the lot, which are not over by the given day.
+	FindAcceptedByLotID(ctx context.Context, lotID uint, from time.Time) ([]*booking.Booking, error)
+	// Update saves booking with its current landlord, if its status is still the same as given one. Booking is checked
+	// for overlapping with other accepted bookings and calendar blocks of the lot, when it becomes accepted.
+	// Availability of the lot is updated as well.
+	Update(ctx context.Context, b *booking.Booking, from booking.Status) error
+	// Expire marks requests, which were not answered in time, as expired and returns them.
+	Expire(ctx context.Context, now time.Time) ([]*booking.Booking, error)
+	// RefreshAvailability makes lots unavailable, when stays of their accepted bookings start, and available again,
+	// when the stays are over.
+	RefreshAvailability(ctx context.Context, today time.Time) error
+}
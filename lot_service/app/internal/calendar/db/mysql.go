@@ -0,0 +1,236 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"time"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+func (s *db) FindBlocks(ctx context.Context, lotID uint) ([]*calendar.Block, error) {
+	queryString := `
+	SELECT block_id, lot_id, start_date, end_date, source, IFNULL(summary, ""), IFNULL(uid, "")
+	FROM calendar_blocks
+	WHERE lot_id=?
+	ORDER BY start_date;`
+
+	rows, err := s.db.QueryContext(ctx, queryString, lotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := make([]*calendar.Block, 0)
+	for rows.Next() {
+		b := &calendar.Block{}
+		var start, end *mysql.RawTime
+		if err = rows.Scan(&b.ID, &b.LotID, &start, &end, &b.Source, &b.Summary, &b.UID); err != nil {
+			return nil, err
+		}
+		if b.Start, err = start.Date(); err != nil {
+			return nil, err
+		}
+		if b.End, err = end.Date(); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	if err = rows.Err(); err != nil {
+		return blocks, err
+	}
+	return blocks, nil
+}
+
+func (s *db) CreateBlock(ctx context.Context, b *calendar.Block) (uint, error) {
+	res, err := s.db.ExecContext(ctx, insertBlockQuery, blockArgs(b)...)
+	if err != nil {
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), nil
+}
+
+func (s *db) DeleteBlock(ctx context.Context, lotID, blockID uint) error {
+	queryString := `
+	DELETE
+	FROM calendar_blocks
+	WHERE block_id=? AND lot_id=? AND source=?;`
+
+	res, err := s.db.ExecContext(ctx, queryString, blockID, lotID, calendar.SourceManual)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+func (s *db) ReplaceImported(ctx context.Context, lotID uint, blocks []*calendar.Block) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM calendar_blocks WHERE lot_id=? AND source=?;`,
+		lotID, calendar.SourceImport)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, insertBlockQuery)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, b := range blocks {
+		if _, err = stmt.ExecContext(ctx, blockArgs(b)...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const insertBlockQuery = `
+	INSERT INTO calendar_blocks (lot_id, start_date, end_date, source, summary, uid)
+	VALUES (?, ?, ?, ?, ?, ?);`
+
+func blockArgs(b *calendar.Block) []any {
+	return []any{
+		b.LotID,
+		b.Start.Format(calendar.DateLayout),
+		b.End.Format(calendar.DateLayout),
+		b.Source,
+		b.Summary,
+		b.UID,
+	}
+}
+
+const importColumns = `lot_id, url, last_imported_at, IFNULL(last_error, "")`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanImport(row scanner) (*calendar.Import, error) {
+	imp := &calendar.Import{}
+	var lastImportedAt *mysql.RawTime
+	if err := row.Scan(&imp.LotID, &imp.URL, &lastImportedAt, &imp.LastError); err != nil {
+		return nil, err
+	}
+	if lastImportedAt != nil {
+		t, err := lastImportedAt.Time()
+		if err != nil {
+			return nil, err
+		}
+		imp.LastImportedAt = &t
+	}
+	return imp, nil
+}
+
+func (s *db) FindImport(ctx context.Context, lotID uint) (*calendar.Import, error) {
+	queryString := `
+	SELECT ` + importColumns + `
+	FROM calendar_imports
+	WHERE lot_id=?;`
+
+	imp, err := scanImport(s.db.QueryRowContext(ctx, queryString, lotID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return imp, nil
+}
+
+func (s *db) FindImports(ctx context.Context) ([]*calendar.Import, error) {
+	queryString := `
+	SELECT ` + importColumns + `
+	FROM calendar_imports;`
+
+	rows, err := s.db.QueryContext(ctx, queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	imports := make([]*calendar.Import, 0)
+	for rows.Next() {
+		imp, err := scanImport(rows)
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, imp)
+	}
+	if err = rows.Err(); err != nil {
+		return imports, err
+	}
+	return imports, nil
+}
+
+func (s *db) SaveImport(ctx context.Context, imp *calendar.Import) error {
+	queryString := `
+	INSERT INTO calendar_imports (lot_id, url)
+	VALUES (?, ?)
+	ON DUPLICATE KEY UPDATE url=VALUES(url), last_imported_at=NULL, last_error=NULL;`
+
+	_, err := s.db.ExecContext(ctx, queryString, imp.LotID, imp.URL)
+	return err
+}
+
+func (s *db) DeleteImport(ctx context.Context, lotID uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM calendar_imports WHERE lot_id=?;`, lotID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM calendar_blocks WHERE lot_id=? AND source=?;`,
+		lotID, calendar.SourceImport)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *db) SetImportResult(ctx context.Context, lotID uint, at time.Time, importErr string) error {
+	queryString := `
+	UPDATE calendar_imports
+	SET last_imported_at=?, last_error=NULLIF(?, "")
+	WHERE lot_id=?;`
+
+	_, err := s.db.ExecContext(ctx, queryString, at.UTC(), importErr, lotID)
+	return err
+}
@@ -0,0 +1,109 @@
+package calendar
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+type Source string
+
+const (
+	SourceManual Source = "manual" // blocked by the owner
+	SourceImport Source = "import" // imported from external calendar
+)
+
+// Block is a period, when the lot can't be booked. End day is free.
+type Block struct {
+	ID      uint      `json:"id"`
+	LotID   uint      `json:"lot_id"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Source  Source    `json:"source"`
+	Summary string    `json:"summary"`
+	UID     string    `json:"-"` // UID of imported event
+}
+
+const (
+	KindBooked  = "booked"
+	KindBlocked = "blocked"
+)
+
+// Period is a busy period in the public calendar of the lot, it doesn't tell who has booked it.
+type Period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Kind  string    `json:"kind"`
+}
+
+// Import is external calendar, which is periodically imported as blocks of the lot.
+type Import struct {
+	LotID          uint       `json:"lot_id"`
+	URL            string     `json:"url"`
+	LastImportedAt *time.Time `json:"last_imported_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// Conflict is imported block overlapping accepted booking of the lot.
+type Conflict struct {
+	BookingID uint      `json:"booking_id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Summary   string    `json:"summary"`
+}
+
+type ImportResult struct {
+	Imported  int        `json:"imported"`
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+type CreateBlockDTO struct {
+	LotID   uint   `json:"lot_id"`
+	UserID  uint   `json:"user_id"`
+	Start   string `json:"start"` // YYYY-MM-DD
+	End     string `json:"end"`   // YYYY-MM-DD, the day is not blocked
+	Summary string `json:"summary"`
+}
+
+type SetImportDTO struct {
+	LotID  uint   `json:"lot_id"`
+	UserID uint   `json:"user_id"`
+	URL    string `json:"url"` // empty URL disables import
+}
+
+func (dto *CreateBlockDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.LotID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Start, validation.Required, validation.Date(DateLayout)),
+		validation.Field(&dto.End, validation.Required, validation.Date(DateLayout)),
+		validation.Field(&dto.Summary, validation.Length(0, 255)),
+	)
+}
+
+func (dto *SetImportDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.LotID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.URL, validation.Length(0, 2000), is.RequestURL),
+	)
+}
+
+// ParsePeriod parses dates of the period and checks that it is not empty.
+func ParsePeriod(start, end string) (time.Time, time.Time, error) {
+	s, err := time.Parse(DateLayout, start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	e, err := time.Parse(DateLayout, end)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !s.Before(e) {
+		return time.Time{}, time.Time{}, errors.New("end must be after start")
+	}
+	return s, e, nil
+}
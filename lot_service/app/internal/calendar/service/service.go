@@ -0,0 +1,475 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	bookingStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/ical"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+const (
+	// maxCalendarSize limits size of imported calendars.
+	maxCalendarSize = 1 << 20
+	// maxRedirects limits redirects followed while fetching calendars.
+	maxRedirects = 5
+)
+
+// sharedAddressSpace is carrier-grade NAT range, which is internal like private networks.
+var sharedAddressSpace = net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+var _ Service = &service{}
+
+type Service interface {
+	GetPeriods(ctx context.Context, lotID uint) ([]calendar.Period, error)
+	Export(ctx context.Context, lotID uint, w io.Writer) error
+	CreateBlock(ctx context.Context, dto *calendar.CreateBlockDTO) (*calendar.Block, error)
+	DeleteBlock(ctx context.Context, lotID, blockID, userID uint) error
+	GetImport(ctx context.Context, lotID, userID uint) (*calendar.Import, error)
+	// SetImport sets or removes external calendar of the lot. New calendar is imported at once.
+	SetImport(ctx context.Context, dto *calendar.SetImportDTO) (*calendar.ImportResult, error)
+	Import(ctx context.Context, lotID uint) (*calendar.ImportResult, error)
+}
+
+type Config struct {
+	// Domain is used in UIDs of exported events.
+	Domain string
+	// AllowFileURLs allows importing calendars from local files, it must be used only for testing.
+	AllowFileURLs bool
+	// AllowedHosts may be fetched even at internal addresses, e.g. 127.0.0.1 of test servers. Calendars
+	// of other hosts are fetched only from public addresses, so owners of lots can't reach internal services.
+	AllowedHosts []string
+	FetchTimeout time.Duration
+}
+
+type service struct {
+	repository    storage.Repository
+	bookings      bookingStorage.Repository
+	lots          lotStorage.Repository
+	organizations organizationStorage.Repository
+	client        *http.Client
+	cfg           Config
+	logger        logging.Logger
+}
+
+func NewService(calendarStorage storage.Repository, bookings bookingStorage.Repository, lots lotStorage.Repository,
+	organizations organizationStorage.Repository, cfg Config, logger logging.Logger) (*service, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// addresses are checked on dial, so calendars aren't fetched through proxies
+	transport.Proxy = nil
+	transport.DialContext = guardedDial(&net.Dialer{Timeout: cfg.FetchTimeout}, cfg.AllowedHosts)
+	if cfg.AllowFileURLs {
+		transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	}
+
+	return &service{
+		repository:    calendarStorage,
+		bookings:      bookings,
+		lots:          lots,
+		organizations: organizations,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.FetchTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				return nil
+			},
+		},
+		cfg:    cfg,
+		logger: logger,
+	}, nil
+}
+
+// GetPeriods returns busy periods of the lot starting from today.
+func (s *service) GetPeriods(ctx context.Context, lotID uint) ([]calendar.Period, error) {
+	if _, err := s.findLot(ctx, lotID); err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.repository.FindBlocks(ctx, lotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find calendar blocks. error: %w", err)
+	}
+	bookings, err := s.bookings.FindAcceptedByLotID(ctx, lotID, today())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accepted bookings. error: %w", err)
+	}
+
+	periods := make([]calendar.Period, 0, len(blocks)+len(bookings))
+	for _, b := range bookings {
+		periods = append(periods, calendar.Period{Start: b.CheckIn, End: b.CheckOut, Kind: calendar.KindBooked})
+	}
+	for _, b := range blocks {
+		if b.End.After(today()) {
+			periods = append(periods, calendar.Period{Start: b.Start, End: b.End, Kind: calendar.KindBlocked})
+		}
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Start.Before(periods[j].Start)
+	})
+	return periods, nil
+}
+
+// Export writes busy periods of the lot as iCalendar. Imported blocks are exported too,
+// so platforms syncing with each other through this service see all of them.
+func (s *service) Export(ctx context.Context, lotID uint, w io.Writer) error {
+	if _, err := s.findLot(ctx, lotID); err != nil {
+		return err
+	}
+
+	blocks, err := s.repository.FindBlocks(ctx, lotID)
+	if err != nil {
+		return fmt.Errorf("failed to find calendar blocks. error: %w", err)
+	}
+	bookings, err := s.bookings.FindAcceptedByLotID(ctx, lotID, today())
+	if err != nil {
+		return fmt.Errorf("failed to find accepted bookings. error: %w", err)
+	}
+
+	events := make([]ical.Event, 0, len(blocks)+len(bookings))
+	for _, b := range bookings {
+		events = append(events, ical.Event{
+			UID:     fmt.Sprintf("booking-%d@%s", b.ID, s.cfg.Domain),
+			Summary: "Booked",
+			Start:   b.CheckIn,
+			End:     b.CheckOut,
+		})
+	}
+	for _, b := range blocks {
+		events = append(events, ical.Event{
+			UID:     fmt.Sprintf("block-%d@%s", b.ID, s.cfg.Domain),
+			Summary: "Not available",
+			Start:   b.Start,
+			End:     b.End,
+		})
+	}
+
+	prodID := fmt.Sprintf("-//%s//lot %d//EN", s.cfg.Domain, lotID)
+	if err = ical.Encode(w, prodID, events); err != nil {
+		return fmt.Errorf("failed to encode calendar. error: %w", err)
+	}
+	return nil
+}
+
+func (s *service) CreateBlock(ctx context.Context, dto *calendar.CreateBlockDTO) (*calendar.Block, error) {
+	s.logger.Debug("validating block fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	start, end, err := calendar.ParsePeriod(dto.Start, dto.End)
+	if err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	if err = s.checkOwner(ctx, dto.LotID, dto.UserID); err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.bookings.FindAcceptedByLotID(ctx, dto.LotID, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accepted bookings. error: %w", err)
+	}
+	for _, b := range bookings {
+		if b.Overlaps(start, end) {
+			return nil, apperror.ConflictError(fmt.Sprintf("block overlaps accepted booking %d", b.ID))
+		}
+	}
+
+	b := &calendar.Block{
+		LotID:   dto.LotID,
+		Start:   start,
+		End:     end,
+		Source:  calendar.SourceManual,
+		Summary: dto.Summary,
+	}
+	b.ID, err = s.repository.CreateBlock(ctx, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calendar block. error: %w", err)
+	}
+	return b, nil
+}
+
+func (s *service) DeleteBlock(ctx context.Context, lotID, blockID, userID uint) error {
+	if err := s.checkOwner(ctx, lotID, userID); err != nil {
+		return err
+	}
+	if err := s.repository.DeleteBlock(ctx, lotID, blockID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete calendar block. error: %w", err)
+	}
+	return nil
+}
+
+func (s *service) GetImport(ctx context.Context, lotID, userID uint) (*calendar.Import, error) {
+	if err := s.checkOwner(ctx, lotID, userID); err != nil {
+		return nil, err
+	}
+	imp, err := s.repository.FindImport(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find calendar import. error: %w", err)
+	}
+	return imp, nil
+}
+
+func (s *service) SetImport(ctx context.Context, dto *calendar.SetImportDTO) (*calendar.ImportResult, error) {
+	s.logger.Debug("validating import fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	if err := s.checkOwner(ctx, dto.LotID, dto.UserID); err != nil {
+		return nil, err
+	}
+
+	if dto.URL == "" {
+		if err := s.repository.DeleteImport(ctx, dto.LotID); err != nil {
+			return nil, fmt.Errorf("failed to delete calendar import. error: %w", err)
+		}
+		return &calendar.ImportResult{Conflicts: []calendar.Conflict{}}, nil
+	}
+
+	if err := s.checkScheme(dto.URL); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	if err := s.repository.SaveImport(ctx, &calendar.Import{LotID: dto.LotID, URL: dto.URL}); err != nil {
+		return nil, fmt.Errorf("failed to save calendar import. error: %w", err)
+	}
+
+	res, err := s.Import(ctx, dto.LotID)
+	if err != nil {
+		// the calendar is saved and will be imported again later
+		return nil, apperror.BadRequestError(fmt.Sprintf("failed to import calendar: %v", err), "")
+	}
+	return res, nil
+}
+
+// Import replaces imported blocks of the lot with events of its external calendar and reports
+// those of them, which overlap accepted bookings. Result of the import is saved with the import.
+func (s *service) Import(ctx context.Context, lotID uint) (*calendar.ImportResult, error) {
+	imp, err := s.repository.FindImport(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find calendar import. error: %w", err)
+	}
+
+	res, importErr := s.importCalendar(ctx, imp)
+
+	lastError := ""
+	switch {
+	case importErr != nil:
+		lastError = importErr.Error()
+	case len(res.Conflicts) > 0:
+		lastError = fmt.Sprintf("%d imported events overlap accepted bookings", len(res.Conflicts))
+	}
+	if err = s.repository.SetImportResult(ctx, lotID, time.Now(), lastError); err != nil {
+		s.logger.Errorf("failed to save result of calendar import of lot %d. error: %v", lotID, err)
+	}
+	return res, importErr
+}
+
+func (s *service) importCalendar(ctx context.Context, imp *calendar.Import) (*calendar.ImportResult, error) {
+	events, err := s.fetch(ctx, imp.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*calendar.Block, 0, len(events))
+	for _, e := range events {
+		if !e.End.After(today()) {
+			continue
+		}
+		blocks = append(blocks, &calendar.Block{
+			LotID:   imp.LotID,
+			Start:   e.Start,
+			End:     e.End,
+			Source:  calendar.SourceImport,
+			Summary: truncate(e.Summary, 255),
+			UID:     truncate(e.UID, 255),
+		})
+	}
+	if err = s.repository.ReplaceImported(ctx, imp.LotID, blocks); err != nil {
+		return nil, fmt.Errorf("failed to save imported blocks. error: %w", err)
+	}
+
+	bookings, err := s.bookings.FindAcceptedByLotID(ctx, imp.LotID, today())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accepted bookings. error: %w", err)
+	}
+	res := &calendar.ImportResult{
+		Imported:  len(blocks),
+		Conflicts: make([]calendar.Conflict, 0),
+	}
+	for _, block := range blocks {
+		for _, b := range bookings {
+			if b.Overlaps(block.Start, block.End) {
+				res.Conflicts = append(res.Conflicts, calendar.Conflict{
+					BookingID: b.ID,
+					Start:     block.Start,
+					End:       block.End,
+					Summary:   block.Summary,
+				})
+			}
+		}
+	}
+	if len(res.Conflicts) > 0 {
+		s.logger.Warnf("%d imported events of lot %d overlap accepted bookings", len(res.Conflicts), imp.LotID)
+	}
+	return res, nil
+}
+
+func (s *service) fetch(ctx context.Context, rawURL string) ([]ical.Event, error) {
+	if err := s.checkScheme(rawURL); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch calendar: status %s", resp.Status)
+	}
+	return ical.Decode(io.LimitReader(resp.Body, maxCalendarSize))
+}
+
+func (s *service) checkScheme(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return nil
+	case "file":
+		if s.cfg.AllowFileURLs {
+			return nil
+		}
+	}
+	return fmt.Errorf("calendar URL scheme %q is not allowed", u.Scheme)
+}
+
+// guardedDial dials only public addresses of hosts, which aren't allowed explicitly. Hosts are resolved
+// before the check and the checked address is dialed, so redirects and DNS changes can't bypass it.
+func guardedDial(dialer *net.Dialer, allowedHosts []string) func(ctx context.Context, network,
+	addr string) (net.Conn, error) {
+	allowed := make(map[string]bool, len(allowedHosts))
+	for _, h := range allowedHosts {
+		allowed[h] = true
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if allowed[host] || allowed[addr] {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if !isPublic(ip.IP) {
+				return nil, fmt.Errorf("calendar host %s has internal address", host)
+			}
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("calendar host %s has no addresses", host)
+		}
+		var conn net.Conn
+		for _, ip := range ips {
+			if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port)); err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified() && !sharedAddressSpace.Contains(ip)
+}
+
+func (s *service) findLot(ctx context.Context, lotID uint) (*lot.Lot, error) {
+	l, err := s.lots.FindByLotID(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot. error: %w", err)
+	}
+	return l, nil
+}
+
+// checkOwner allows managing the calendar only to the agent of the lot and managers of its organization.
+func (s *service) checkOwner(ctx context.Context, lotID, userID uint) error {
+	_, err := lotService.FindForChange(ctx, s.lots, s.organizations, lotID, userID)
+	return err
+}
+
+// RunImport imports all external calendars every interval until ctx is done.
+func RunImport(ctx context.Context, s Service, repository storage.Repository, interval time.Duration,
+	logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			imports, err := repository.FindImports(ctx)
+			if err != nil {
+				logger.Errorf("failed to find calendar imports. error: %v", err)
+				continue
+			}
+			for _, imp := range imports {
+				if _, err = s.Import(ctx, imp.LotID); err != nil {
+					logger.Warnf("failed to import calendar of lot %d. error: %v", imp.LotID, err)
+				}
+			}
+		}
+	}
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
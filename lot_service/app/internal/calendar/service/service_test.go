@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	bookingStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type calendarRepo struct {
+	storage.Repository
+	imp     *calendar.Import
+	blocks  []*calendar.Block
+	lastErr string
+}
+
+func (r *calendarRepo) FindImport(_ context.Context, _ uint) (*calendar.Import, error) {
+	return r.imp, nil
+}
+
+func (r *calendarRepo) ReplaceImported(_ context.Context, _ uint, blocks []*calendar.Block) error {
+	r.blocks = blocks
+	return nil
+}
+
+func (r *calendarRepo) SetImportResult(_ context.Context, _ uint, _ time.Time, importErr string) error {
+	r.lastErr = importErr
+	return nil
+}
+
+type bookingRepo struct {
+	bookingStorage.Repository
+	accepted []*booking.Booking
+}
+
+func (r *bookingRepo) FindAcceptedByLotID(_ context.Context, _ uint, _ time.Time) ([]*booking.Booking, error) {
+	return r.accepted, nil
+}
+
+func icsData(start, end time.Time) string {
+	return fmt.Sprintf("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:1@example.com\r\n"+
+		"DTSTART;VALUE=DATE:%s\r\nDTEND;VALUE=DATE:%s\r\nSUMMARY:Reserved\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		start.Format("20060102"), end.Format("20060102"))
+}
+
+func TestImport(t *testing.T) {
+	start := today().AddDate(0, 0, 10)
+	end := start.AddDate(0, 0, 3)
+	data := icsData(start, end)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(data))
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "calendar.ics")
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	serverURL, _ := url.Parse(server.URL)
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	testCases := []struct {
+		name          string
+		url           string
+		allowFiles    bool
+		allowedHosts  []string
+		accepted      []*booking.Booking
+		wantErr       bool
+		wantConflicts int
+	}{
+		{name: "http", url: server.URL, allowedHosts: []string{serverURL.Host}},
+		{name: "internal address", url: server.URL, wantErr: true},
+		{name: "redirect to internal address", url: redirect.URL, allowedHosts: []string{"127.0.0.1"}, wantErr: true},
+		{name: "file", url: "file://" + file, allowFiles: true},
+		{name: "file not allowed", url: "file://" + file, wantErr: true},
+		{
+			name:         "conflict",
+			url:          server.URL,
+			allowedHosts: []string{"127.0.0.1"},
+			accepted: []*booking.Booking{
+				{ID: 7, CheckIn: end.AddDate(0, 0, -1), CheckOut: end.AddDate(0, 0, 2)},
+				{ID: 8, CheckIn: end, CheckOut: end.AddDate(0, 0, 2)},
+			},
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &calendarRepo{imp: &calendar.Import{LotID: 1, URL: tc.url}}
+			s, _ := NewService(repo, &bookingRepo{accepted: tc.accepted}, nil, nil,
+				Config{AllowFileURLs: tc.allowFiles, AllowedHosts: tc.allowedHosts, FetchTimeout: time.Second}, logging.GetLogger())
+
+			res, err := s.Import(context.Background(), 1)
+			if tc.wantErr {
+				if err == nil || repo.lastErr == "" {
+					t.Fatalf("expected error to be returned and saved, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Imported != 1 || len(repo.blocks) != 1 {
+				t.Fatalf("expected one imported block, got %d", len(repo.blocks))
+			}
+			if b := repo.blocks[0]; !b.Start.Equal(start) || !b.End.Equal(end) || b.Source != calendar.SourceImport {
+				t.Errorf("unexpected block %+v", b)
+			}
+			if len(res.Conflicts) != tc.wantConflicts {
+				t.Fatalf("expected %d conflicts, got %d", tc.wantConflicts, len(res.Conflicts))
+			}
+			if tc.wantConflicts > 0 && (res.Conflicts[0].BookingID != 7 || repo.lastErr == "") {
+				t.Errorf("unexpected conflict %+v, last error %q", res.Conflicts[0], repo.lastErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar"
+	"time"
+)
+
+type Repository interface {
+	FindBlocks(ctx context.Context, lotID uint) ([]*calendar.Block, error)
+	CreateBlock(ctx context.Context, b *calendar.Block) (uint, error)
+	// DeleteBlock deletes only manual blocks, imported ones are replaced on every import.
+	DeleteBlock(ctx context.Context, lotID, blockID uint) error
+	// ReplaceImported replaces all imported blocks of the lot.
+	ReplaceImported(ctx context.Context, lotID uint, blocks []*calendar.Block) error
+
+	FindImport(ctx context.Context, lotID uint) (*calendar.Import, error)
+	FindImports(ctx context.Context) ([]*calendar.Import, error)
+	SaveImport(ctx context.Context, imp *calendar.Import) error
+	DeleteImport(ctx context.Context, lotID uint) error
+	SetImportResult(ctx context.Context, lotID uint, at time.Time, importErr string) error
+}
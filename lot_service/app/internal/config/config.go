@@ -4,6 +4,7 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
 	"sync"
+	"time"
 )
 
 type Config struct {
@@ -21,6 +22,132 @@ type Config struct {
 		Password string `yaml:"password" env-default:"testPassword"`
 		DBName   string `yaml:"db_name" env-default:"test_db"`
 	} `yaml:"MysqlDB"`
+
+	Bookings struct {
+		ResponseTimeout    time.Duration `yaml:"response_timeout" env-default:"48h"`
+		ExpirationInterval time.Duration `yaml:"expiration_interval" env-default:"1m"`
+	} `yaml:"bookings"`
+
+	Calendar struct {
+		Domain         string        `yaml:"domain" env-default:"lots.local"`
+		ImportInterval time.Duration `yaml:"import_interval" env-default:"30m"`
+		FetchTimeout   time.Duration `yaml:"fetch_timeout" env-default:"10s"`
+		// AllowFileURLs allows file:// calendars, so import can be tested without external services
+		AllowFileURLs bool `yaml:"allow_file_urls" env-default:"false"`
+		// AllowedHosts may be fetched at internal addresses, e.g. "127.0.0.1" for testing, empty by default
+		AllowedHosts []string `yaml:"allowed_hosts" env-separator:","`
+	} `yaml:"calendar"`
+
+	Media struct {
+		Type string `yaml:"type" env-default:"local"`
+		Dir  string `yaml:"dir" env-default:"media"`
+	} `yaml:"media"`
+
+	Messaging struct {
+		MaxAttachmentSize int64 `yaml:"max_attachment_size" env-default:"10485760"`
+		// MessagesTTL is how long messages are kept, zero keeps them forever
+		MessagesTTL          time.Duration `yaml:"messages_ttl" env-default:"8760h"`
+		UnsentAttachmentsTTL time.Duration `yaml:"unsent_attachments_ttl" env-default:"24h"`
+		RetentionInterval    time.Duration `yaml:"retention_interval" env-default:"1h"`
+	} `yaml:"messaging"`
+
+	Events struct {
+		// TTL is how long clients can resume receiving events after disconnect
+		TTL               time.Duration `yaml:"ttl" env-default:"168h"`
+		RetentionInterval time.Duration `yaml:"retention_interval" env-default:"1h"`
+	} `yaml:"events"`
+	Reviews struct {
+		// HideAfterReports hides review until moderation, zero disables hiding
+		HideAfterReports int `yaml:"hide_after_reports" env-default:"3"`
+	} `yaml:"reviews"`
+	Viewings struct {
+		RemindBefore     time.Duration `yaml:"remind_before" env-default:"2h"`
+		ReminderInterval time.Duration `yaml:"reminder_interval" env-default:"1m"`
+	} `yaml:"viewings"`
+	Agreements struct {
+		// FontPath is TrueType font with Cyrillic for PDF agreements, e.g. DejaVuSans.ttf, empty path disables PDF
+		FontPath string `yaml:"font_path" env-default:""`
+	} `yaml:"agreements"`
+	Payments struct {
+		// Provider is type of payment provider, only mock provider is supported for now
+		Provider string `yaml:"provider" env-default:"mock"`
+		// WebhookSecret signs webhooks of the provider, it has no default, since the webhook route is public
+		WebhookSecret string `yaml:"webhook_secret" env-required:"true"`
+		CheckoutURL   string `yaml:"checkout_url" env-default:"http://localhost:8081/mock-checkout"`
+		// FontPath is TrueType font with Cyrillic for PDF receipts, e.g. DejaVuSans.ttf, empty path disables PDF
+		FontPath string `yaml:"font_path" env-default:""`
+	} `yaml:"payments"`
+	Imports struct {
+		MaxFileSize int64         `yaml:"max_file_size" env-default:"10485760"`
+		MaxRows     int           `yaml:"max_rows" env-default:"5000"`
+		Interval    time.Duration `yaml:"interval" env-default:"5s"`
+	} `yaml:"imports"`
+	Feeds struct {
+		// LotURL is page of the lot on the site, %d is replaced with ID of the lot
+		LotURL string `yaml:"lot_url" env-default:"http://localhost:3000/lots/%d"`
+		// SiteURL is public URL of the site, feeds are served by it under /api
+		SiteURL string `yaml:"site_url" env-default:"http://localhost:3000"`
+		// ContactPhone is published for lots of private landlords, whose phones are revealed only on request
+		ContactPhone string `yaml:"contact_phone" env-default:""`
+		CityItems    int    `yaml:"city_items" env-default:"50"`
+	} `yaml:"feeds"`
+	Analytics struct {
+		// QueueSize limits number of views waiting for saving, views over the limit are dropped
+		QueueSize      int           `yaml:"queue_size" env-default:"10000"`
+		FlushInterval  time.Duration `yaml:"flush_interval" env-default:"5s"`
+		MaxSimilarLots int           `yaml:"max_similar_lots" env-default:"500"`
+	} `yaml:"analytics"`
+	Similar struct {
+		Weights struct {
+			EstateType float64 `yaml:"estate_type" env-default:"3"`
+			Rooms      float64 `yaml:"rooms" env-default:"2"`
+			Area       float64 `yaml:"area" env-default:"1"`
+			Price      float64 `yaml:"price" env-default:"2"`
+			District   float64 `yaml:"district" env-default:"1"`
+			Distance   float64 `yaml:"distance" env-default:"2"`
+		} `yaml:"weights"`
+		// PriceBand is relative difference of prices of candidates, e.g. 0.3 for prices from 70% to 130%
+		PriceBand     float64 `yaml:"price_band" env-default:"0.3"`
+		MaxDistanceKm float64 `yaml:"max_distance_km" env-default:"5"`
+		// Candidates limits number of lots ranked per request
+		Candidates int `yaml:"candidates" env-default:"200"`
+		// MinCandidates widens search to other prices and estate types of the city, when fewer lots are found
+		MinCandidates int `yaml:"min_candidates" env-default:"10"`
+		Limit         int `yaml:"limit" env-default:"10"`
+		MaxLimit      int `yaml:"max_limit" env-default:"50"`
+	} `yaml:"similar"`
+	Duplicates struct {
+		Interval  time.Duration `yaml:"interval" env-default:"10s"`
+		BatchSize int           `yaml:"batch_size" env-default:"100"`
+		// Threshold is minimal score from 0 to 1 of probable duplicates
+		Threshold float64 `yaml:"threshold" env-default:"0.8"`
+		// AreaTolerance is relative difference of areas, at which areas aren't similar at all
+		AreaTolerance float64 `yaml:"area_tolerance" env-default:"0.1"`
+		// PhotoDistance is maximal Hamming distance of perceptual hashes of copies of the same photo
+		PhotoDistance int `yaml:"photo_distance" env-default:"10"`
+	} `yaml:"duplicates"`
+	Addresses struct {
+		// Geocoder is none for normalization by rules only or file for geocoder faked by JSON file
+		Geocoder     string        `yaml:"geocoder" env-default:"none"`
+		GeocoderFile string        `yaml:"geocoder_file" env-default:"geocoder.json"`
+		Interval     time.Duration `yaml:"interval" env-default:"10s"`
+		BatchSize    int           `yaml:"batch_size" env-default:"100"`
+		// AddUnknown lets lots add their cities and districts to reference data, instead of rejecting them
+		AddUnknown bool `yaml:"add_unknown" env-default:"false"`
+	} `yaml:"addresses"`
+	Reference struct {
+		// CacheTTL is how long reference data are cached, cities added by lots (see Addresses.AddUnknown)
+		// are listed after it
+		CacheTTL time.Duration `yaml:"cache_ttl" env-default:"1m"`
+	} `yaml:"reference"`
+	Exchange struct {
+		// Source of rates is none to keep saved rates, file for JSON file or http for JSON served by URL
+		Source   string        `yaml:"source" env-default:"none"`
+		Location string        `yaml:"location" env-default:"rates.json"`
+		Timeout  time.Duration `yaml:"timeout" env-default:"10s"`
+		Interval time.Duration `yaml:"interval" env-default:"1h"`
+		CacheTTL time.Duration `yaml:"cache_ttl" env-default:"1m"`
+	} `yaml:"exchange"`
 }
 
 var instance *Config
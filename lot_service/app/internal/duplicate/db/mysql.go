@@ -0,0 +1,257 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/phash"
+	"strings"
+	"time"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func (s *db) FindUnchecked(ctx context.Context, limit int) ([]*lot.Lot, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT lots.lot_id, lots.user_id, lots.city, lots.street, lots.building, lots.floor, lots.area, lots.rooms
+	FROM lots
+	LEFT JOIN lot_fingerprints f ON f.lot_id=lots.lot_id
+	WHERE f.lot_id IS NULL
+	ORDER BY lots.lot_id
+	LIMIT ?;`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lots := make([]*lot.Lot, 0)
+	for rows.Next() {
+		l := &lot.Lot{}
+		err = rows.Scan(&l.ID, &l.CreatedByUserID, &l.City, &l.Street, &l.Building, &l.Floor, &l.Area, &l.Rooms)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, l)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+const fingerprintColumns = `lot_id, address, floor, area, rooms, photo_hashes`
+
+func scanFingerprint(row scanner) (*duplicate.Fingerprint, error) {
+	f := &duplicate.Fingerprint{}
+	var hashes string
+	if err := row.Scan(&f.LotID, &f.Address, &f.Floor, &f.Area, &f.Rooms, &hashes); err != nil {
+		return nil, err
+	}
+	for _, v := range strings.Split(hashes, ",") {
+		if v == "" {
+			continue
+		}
+		h, err := phash.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		f.PhotoHashes = append(f.PhotoHashes, h)
+	}
+	return f, nil
+}
+
+func (s *db) FindFingerprint(ctx context.Context, lotID uint) (*duplicate.Fingerprint, error) {
+	row := s.db.QueryRowContext(ctx, `
+	SELECT `+fingerprintColumns+`
+	FROM lot_fingerprints
+	WHERE lot_id=?;`, lotID)
+	f, err := scanFingerprint(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *db) FindFingerprintsByAddress(ctx context.Context, address string) ([]*duplicate.Fingerprint, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT `+fingerprintColumns+`
+	FROM lot_fingerprints
+	WHERE address=?
+	ORDER BY lot_id;`, address)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fingerprints := make([]*duplicate.Fingerprint, 0)
+	for rows.Next() {
+		f, err := scanFingerprint(rows)
+		if err != nil {
+			return nil, err
+		}
+		fingerprints = append(fingerprints, f)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return fingerprints, nil
+}
+
+func (s *db) SaveFingerprint(ctx context.Context, f *duplicate.Fingerprint) error {
+	hashes := make([]string, 0, len(f.PhotoHashes))
+	for _, h := range f.PhotoHashes {
+		hashes = append(hashes, phash.Format(h))
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+	INSERT INTO lot_fingerprints (lot_id, address, floor, area, rooms, photo_hashes)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		address=VALUES(address),
+		floor=VALUES(floor),
+		area=VALUES(area),
+		rooms=VALUES(rooms),
+		photo_hashes=VALUES(photo_hashes);`,
+		f.LotID, f.Address, f.Floor, f.Area, f.Rooms, strings.Join(hashes, ","))
+	return err
+}
+
+func (s *db) CreateDuplicates(ctx context.Context, duplicates []*duplicate.Duplicate) error {
+	if len(duplicates) == 0 {
+		return nil
+	}
+	qb := sq.Insert("lot_duplicates").
+		Options("IGNORE").
+		Columns("lot_id", "duplicate_of_id", "score", "status", "created_at")
+	for _, d := range duplicates {
+		qb = qb.Values(d.LotID, d.DuplicateOfID, d.Score, d.Status, d.CreatedAt.UTC())
+	}
+	sqlQ, args, err := qb.ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, sqlQ, args...)
+	return err
+}
+
+const duplicateColumns = `duplicate_id, lot_id, duplicate_of_id, score, status, created_at, moderated_at`
+
+func scanDuplicate(row scanner) (*duplicate.Duplicate, error) {
+	d := &duplicate.Duplicate{}
+	var createdAt, moderatedAt *mysql.RawTime
+	err := row.Scan(&d.ID, &d.LotID, &d.DuplicateOfID, &d.Score, &d.Status, &createdAt, &moderatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if d.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	if moderatedAt != nil {
+		t, err := moderatedAt.Time()
+		if err != nil {
+			return nil, err
+		}
+		d.ModeratedAt = &t
+	}
+	return d, nil
+}
+
+func (s *db) FindByID(ctx context.Context, id uint) (*duplicate.Duplicate, error) {
+	row := s.db.QueryRowContext(ctx, `
+	SELECT `+duplicateColumns+`
+	FROM lot_duplicates
+	WHERE duplicate_id=?;`, id)
+	d, err := scanDuplicate(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *db) Find(ctx context.Context, filter storage.Filter) ([]*duplicate.Duplicate, error) {
+	qb := sq.Select(strings.Split(duplicateColumns, ", ")...).
+		From("lot_duplicates").
+		OrderBy("duplicate_id DESC")
+	if filter.Status != "" {
+		qb = qb.Where(sq.Eq{"status": filter.Status})
+	}
+	if filter.LotID != 0 {
+		qb = qb.Where(sq.Or{sq.Eq{"lot_id": filter.LotID}, sq.Eq{"duplicate_of_id": filter.LotID}})
+	}
+	sqlQ, args, err := qb.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQ, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	duplicates := make([]*duplicate.Duplicate, 0)
+	for rows.Next() {
+		d, err := scanDuplicate(rows)
+		if err != nil {
+			return nil, err
+		}
+		duplicates = append(duplicates, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+func (s *db) Update(ctx context.Context, d *duplicate.Duplicate) error {
+	var moderatedAt *time.Time
+	if d.ModeratedAt != nil {
+		t := d.ModeratedAt.UTC()
+		moderatedAt = &t
+	}
+	res, err := s.db.ExecContext(ctx, `
+	UPDATE lot_duplicates
+	SET status=?, moderated_at=?
+	WHERE duplicate_id=?;`, d.Status, moderatedAt, d.ID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		// the status is the same or the duplicate doesn't exist
+		_, err = s.FindByID(ctx, d.ID)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package duplicate
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/address"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/phash"
+	"math"
+	"time"
+)
+
+const (
+	StatusPending   = "pending"   // waits for moderator
+	StatusConfirmed = "confirmed" // the same estate, the lot is grouped with the original in search
+	StatusDismissed = "dismissed" // different estates at the same address
+)
+
+// Fingerprint identifies estate of the lot. Lots at the same normalized address are compared by their
+// fingerprints, photo hashes are compared only when both lots have photos.
+type Fingerprint struct {
+	LotID       uint
+	Address     string // normalized, see address.Key
+	Floor       int
+	Area        int
+	Rooms       int
+	PhotoHashes []uint64
+}
+
+// Duplicate is a probable duplicate of the older lot.
+type Duplicate struct {
+	ID            uint       `json:"id"`
+	LotID         uint       `json:"lot_id"`
+	DuplicateOfID uint       `json:"duplicate_of_id"`
+	Score         float64    `json:"score"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ModeratedAt   *time.Time `json:"moderated_at,omitempty"`
+}
+
+type ModerateDTO struct {
+	ID     uint   `json:"id"`
+	Status string `json:"status"`
+}
+
+// MaxPhotos limits number of photos of the lot compared with photos of other lots.
+const MaxPhotos = 30
+
+// SetPhotosDTO replaces photos of the lot, photos are JPEG or PNG images encoded in base64.
+type SetPhotosDTO struct {
+	LotID  uint     `json:"lot_id"`
+	UserID uint     `json:"user_id"`
+	Photos [][]byte `json:"photos"`
+}
+
+// Matching configures comparison of fingerprints.
+type Matching struct {
+	// AreaTolerance is relative difference of areas, at which areas aren't similar at all, e.g. 0.1
+	AreaTolerance float64
+	// PhotoDistance is maximal Hamming distance of hashes of copies of the same photo
+	PhotoDistance int
+}
+
+func NewFingerprint(l *lot.Lot) *Fingerprint {
+	return &Fingerprint{
+		LotID:   l.ID,
+		Address: address.Key(l.City, l.Street, l.Building),
+		Floor:   l.Floor,
+		Area:    l.Area,
+		Rooms:   l.Rooms,
+	}
+}
+
+// Score returns similarity of fingerprints from 0 to 1, fingerprints of different addresses have zero score.
+// Matching photos weigh as much as floor, rooms and area together.
+func (f *Fingerprint) Score(o *Fingerprint, m Matching) float64 {
+	if f.Address != o.Address {
+		return 0
+	}
+
+	sum, total := 0.0, 3.0
+	if f.Floor == o.Floor {
+		sum++
+	}
+	if f.Rooms == o.Rooms {
+		sum++
+	}
+	if m.AreaTolerance > 0 && f.Area > 0 {
+		sum += math.Max(0, 1-math.Abs(float64(f.Area-o.Area))/float64(f.Area)/m.AreaTolerance)
+	} else if f.Area == o.Area {
+		sum++
+	}
+	if len(f.PhotoHashes) > 0 && len(o.PhotoHashes) > 0 {
+		sum += 3 * matchingPhotos(f.PhotoHashes, o.PhotoHashes, m.PhotoDistance)
+		total += 3
+	}
+	return math.Round(sum/total*1000) / 1000
+}
+
+// matchingPhotos returns share of photos of the first lot, which have copies among photos of the other.
+func matchingPhotos(hashes, other []uint64, distance int) float64 {
+	matched := 0
+	for _, h := range hashes {
+		for _, o := range other {
+			if phash.Distance(h, o) <= distance {
+				matched++
+				break
+			}
+		}
+	}
+	return float64(matched) / float64(len(hashes))
+}
+
+func (dto *SetPhotosDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.LotID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Photos, validation.Length(0, MaxPhotos)),
+	)
+}
+
+func (dto *ModerateDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.Status, validation.Required, validation.In(StatusConfirmed, StatusDismissed)),
+	)
+}
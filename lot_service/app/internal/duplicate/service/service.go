@@ -0,0 +1,219 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/phash"
+	"time"
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	// CheckLot fingerprints new lot and flags its probable duplicates among lots at the same address.
+	CheckLot(ctx context.Context, l *lot.Lot) error
+	// Detect fingerprints lots, which weren't checked at creation, and flags their probable duplicates.
+	// It returns number of checked lots.
+	Detect(ctx context.Context) (int, error)
+	// SetPhotos replaces perceptual hashes of photos of the lot and checks the lot for duplicates again.
+	// Photos are set by those, who can change the lot.
+	SetPhotos(ctx context.Context, dto *duplicate.SetPhotosDTO) error
+
+	GetAll(ctx context.Context, filter storage.Filter) ([]*duplicate.Duplicate, error)
+	// Moderate confirms or dismisses the duplicate. Confirmed duplicates are grouped with their originals
+	// in search. The agent of the duplicate lot is notified.
+	Moderate(ctx context.Context, dto *duplicate.ModerateDTO) (*duplicate.Duplicate, error)
+}
+
+type Config struct {
+	// BatchSize limits number of lots fingerprinted at once
+	BatchSize int
+	// Threshold is minimal score of probable duplicates
+	Threshold float64
+	Matching  duplicate.Matching
+}
+
+type service struct {
+	repository    storage.Repository
+	lots          lotStorage.Repository
+	organizations organizationStorage.Repository
+	events        eventService.Publisher
+	cfg           Config
+	logger        logging.Logger
+}
+
+func NewService(duplicateStorage storage.Repository, lots lotStorage.Repository,
+	organizations organizationStorage.Repository, events eventService.Publisher, cfg Config,
+	logger logging.Logger) (*service, error) {
+	if cfg.BatchSize <= 0 {
+		return nil, fmt.Errorf("batch size of duplicate detection must be positive, got %d", cfg.BatchSize)
+	}
+	return &service{
+		repository:    duplicateStorage,
+		lots:          lots,
+		organizations: organizations,
+		events:        events,
+		cfg:           cfg,
+		logger:        logger,
+	}, nil
+}
+
+func (s *service) CheckLot(ctx context.Context, l *lot.Lot) error {
+	return s.check(ctx, duplicate.NewFingerprint(l))
+}
+
+func (s *service) Detect(ctx context.Context) (int, error) {
+	checked := 0
+	for {
+		lots, err := s.repository.FindUnchecked(ctx, s.cfg.BatchSize)
+		if err != nil {
+			return checked, fmt.Errorf("failed to find unchecked lots. error: %w", err)
+		}
+		for _, l := range lots {
+			if err = s.check(ctx, duplicate.NewFingerprint(l)); err != nil {
+				return checked, err
+			}
+			checked++
+		}
+		if len(lots) < s.cfg.BatchSize {
+			return checked, nil
+		}
+	}
+}
+
+func (s *service) SetPhotos(ctx context.Context, dto *duplicate.SetPhotosDTO) error {
+	if err := dto.ValidateFields(); err != nil {
+		return apperror.BadRequestError(err.Error(), "")
+	}
+	hashes := make([]uint64, 0, len(dto.Photos))
+	for i, p := range dto.Photos {
+		h, err := phash.Decode(bytes.NewReader(p))
+		if err != nil {
+			return apperror.BadRequestError(fmt.Sprintf("photo %d: %v", i, err), "")
+		}
+		hashes = append(hashes, h)
+	}
+
+	l, err := lotService.FindForChange(ctx, s.lots, s.organizations, dto.LotID, dto.UserID)
+	if err != nil {
+		return err
+	}
+
+	f := duplicate.NewFingerprint(l)
+	f.PhotoHashes = hashes
+	return s.check(ctx, f)
+}
+
+// check compares the fingerprint with fingerprints of other lots at the address and saves it. The newer lot
+// of probable duplicates is the duplicate of the older one.
+func (s *service) check(ctx context.Context, f *duplicate.Fingerprint) error {
+	others, err := s.repository.FindFingerprintsByAddress(ctx, f.Address)
+	if err != nil {
+		return fmt.Errorf("failed to find fingerprints by address. error: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	duplicates := make([]*duplicate.Duplicate, 0)
+	for _, o := range others {
+		if o.LotID == f.LotID {
+			continue
+		}
+		score := f.Score(o, s.cfg.Matching)
+		if score < s.cfg.Threshold {
+			continue
+		}
+		d := &duplicate.Duplicate{LotID: f.LotID, DuplicateOfID: o.LotID, Score: score,
+			Status: duplicate.StatusPending, CreatedAt: now}
+		if o.LotID > f.LotID {
+			d.LotID, d.DuplicateOfID = o.LotID, f.LotID
+		}
+		duplicates = append(duplicates, d)
+	}
+
+	if len(duplicates) > 0 {
+		s.logger.Infof("lot %d has %d probable duplicates", f.LotID, len(duplicates))
+		if err = s.repository.CreateDuplicates(ctx, duplicates); err != nil {
+			return fmt.Errorf("failed to create duplicates. error: %w", err)
+		}
+	}
+	if err = s.repository.SaveFingerprint(ctx, f); err != nil {
+		return fmt.Errorf("failed to save fingerprint of lot. error: %w", err)
+	}
+	return nil
+}
+
+func (s *service) GetAll(ctx context.Context, filter storage.Filter) ([]*duplicate.Duplicate, error) {
+	switch filter.Status {
+	case "", duplicate.StatusPending, duplicate.StatusConfirmed, duplicate.StatusDismissed:
+	default:
+		return nil, apperror.BadRequestError(fmt.Sprintf("unknown status %q", filter.Status), "")
+	}
+
+	duplicates, err := s.repository.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates. error: %w", err)
+	}
+	return duplicates, nil
+}
+
+func (s *service) Moderate(ctx context.Context, dto *duplicate.ModerateDTO) (*duplicate.Duplicate, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	d, err := s.repository.FindByID(ctx, dto.ID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find duplicate by its id. error: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	d.Status = dto.Status
+	d.ModeratedAt = &now
+	if err = s.repository.Update(ctx, d); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update duplicate. error: %w", err)
+	}
+
+	l, err := s.lots.FindByLotID(ctx, d.LotID)
+	if err != nil {
+		s.logger.Errorf("failed to find lot %d to notify its agent about moderation. error: %v", d.LotID, err)
+		return d, nil
+	}
+	s.events.Publish(ctx, l.CreatedByUserID, event.TypeModeration,
+		&event.Moderation{Subject: event.ModerationDuplicate, Object: d})
+	return d, nil
+}
+
+// RunDetector checks new lots for duplicates with the interval until the context is done.
+func RunDetector(ctx context.Context, s Service, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Detect(ctx); err != nil {
+				logger.Errorf("failed to detect duplicates. error: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"testing"
+)
+
+type repo struct {
+	storage.Repository
+	lots         []*lot.Lot
+	fingerprints map[uint]*duplicate.Fingerprint
+	duplicates   []*duplicate.Duplicate
+}
+
+func (r *repo) FindUnchecked(_ context.Context, limit int) ([]*lot.Lot, error) {
+	unchecked := make([]*lot.Lot, 0)
+	for _, l := range r.lots {
+		if _, ok := r.fingerprints[l.ID]; !ok && len(unchecked) < limit {
+			unchecked = append(unchecked, l)
+		}
+	}
+	return unchecked, nil
+}
+
+func (r *repo) FindFingerprint(_ context.Context, lotID uint) (*duplicate.Fingerprint, error) {
+	f, ok := r.fingerprints[lotID]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return f, nil
+}
+
+func (r *repo) FindFingerprintsByAddress(_ context.Context, address string) ([]*duplicate.Fingerprint, error) {
+	found := make([]*duplicate.Fingerprint, 0)
+	for _, f := range r.fingerprints {
+		if f.Address == address {
+			found = append(found, f)
+		}
+	}
+	return found, nil
+}
+
+func (r *repo) SaveFingerprint(_ context.Context, f *duplicate.Fingerprint) error {
+	r.fingerprints[f.LotID] = f
+	return nil
+}
+
+func (r *repo) CreateDuplicates(_ context.Context, duplicates []*duplicate.Duplicate) error {
+	r.duplicates = append(r.duplicates, duplicates...)
+	return nil
+}
+
+func TestDetect(t *testing.T) {
+	r := &repo{
+		lots: []*lot.Lot{
+			{ID: 1, City: "Москва", Street: "ул. Ленина", Building: "1", Floor: 3, Area: 50, Rooms: 2},
+			// the same flat posted by another agent
+			{ID: 2, City: "г. Москва", Street: "Ленина улица", Building: "д. 1", Floor: 3, Area: 51, Rooms: 2},
+			// another flat in the building
+			{ID: 3, City: "Москва", Street: "Ленина", Building: "1", Floor: 7, Area: 50, Rooms: 2},
+			{ID: 4, City: "Москва", Street: "Ленина", Building: "2", Floor: 3, Area: 50, Rooms: 2},
+		},
+		fingerprints: make(map[uint]*duplicate.Fingerprint),
+	}
+	cfg := Config{
+		BatchSize: 2,
+		Threshold: 0.8,
+		Matching:  duplicate.Matching{AreaTolerance: 0.1, PhotoDistance: 10},
+	}
+	s, _ := NewService(r, nil, nil, nil, cfg, logging.GetLogger())
+
+	checked, err := s.Detect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked != 4 {
+		t.Errorf("expected 4 checked lots, got %d", checked)
+	}
+	if len(r.duplicates) != 1 {
+		t.Fatalf("expected 1 duplicate, got %d", len(r.duplicates))
+	}
+	d := r.duplicates[0]
+	if d.LotID != 2 || d.DuplicateOfID != 1 || d.Status != duplicate.StatusPending {
+		t.Errorf("expected lot 2 pending as duplicate of lot 1, got %+v", d)
+	}
+
+	if checked, _ = s.Detect(context.Background()); checked != 0 {
+		t.Errorf("expected checked lots to be skipped, got %d", checked)
+	}
+}
+
+func TestCheckLot(t *testing.T) {
+	r := &repo{fingerprints: map[uint]*duplicate.Fingerprint{
+		1: duplicate.NewFingerprint(&lot.Lot{ID: 1, City: "Москва", Street: "Ленина", Building: "1", Floor: 3,
+			Area: 50, Rooms: 2}),
+	}}
+	cfg := Config{BatchSize: 1, Threshold: 0.8}
+	if _, err := NewService(r, nil, nil, nil, Config{Threshold: 0.8}, logging.GetLogger()); err == nil {
+		t.Fatal("zero batch size must be rejected")
+	}
+	s, _ := NewService(r, nil, nil, nil, cfg, logging.GetLogger())
+
+	l := &lot.Lot{ID: 2, City: "г. Москва", Street: "ул. Ленина", Building: "д. 1", Floor: 3, Area: 50, Rooms: 2}
+	if err := s.CheckLot(context.Background(), l); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.duplicates) != 1 || r.duplicates[0].LotID != 2 || r.duplicates[0].DuplicateOfID != 1 {
+		t.Fatalf("expected new lot 2 flagged as duplicate of lot 1, got %+v", r.duplicates)
+	}
+	if _, ok := r.fingerprints[2]; !ok {
+		t.Fatal("fingerprint of checked lot must be saved")
+	}
+}
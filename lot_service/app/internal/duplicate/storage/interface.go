@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+)
+
+// Filter selects duplicates by status and by lot, which is either the duplicate or the original.
+// Zero values select all of them.
+type Filter struct {
+	Status string
+	LotID  uint
+}
+
+type Repository interface {
+	// FindUnchecked returns lots without fingerprints, oldest first. Only ID, agent, address, floor, area and
+	// rooms of the lots are filled.
+	FindUnchecked(ctx context.Context, limit int) ([]*lot.Lot, error)
+	FindFingerprint(ctx context.Context, lotID uint) (*duplicate.Fingerprint, error)
+	FindFingerprintsByAddress(ctx context.Context, address string) ([]*duplicate.Fingerprint, error)
+	// SaveFingerprint creates fingerprint of the lot or replaces the previous one.
+	SaveFingerprint(ctx context.Context, f *duplicate.Fingerprint) error
+
+	// CreateDuplicates saves new duplicates, pairs of lots found before keep their status.
+	CreateDuplicates(ctx context.Context, duplicates []*duplicate.Duplicate) error
+	FindByID(ctx context.Context, id uint) (*duplicate.Duplicate, error)
+	Find(ctx context.Context, filter Filter) ([]*duplicate.Duplicate, error)
+	Update(ctx context.Context, d *duplicate.Duplicate) error
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	sq "github.com/Masterminds/squirrel"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"time"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+func (s *db) Create(ctx context.Context, e *event.Event) (uint, error) {
+	queryString := `
+	INSERT INTO events (user_id, type, payload, created_at)
+	VALUES (?, ?, ?, ?);`
+
+	res, err := s.db.ExecContext(ctx, queryString, e.UserID, e.Type, []byte(e.Payload), e.CreatedAt.UTC())
+	if err != nil {
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), nil
+}
+
+func (s *db) FindAfter(ctx context.Context, afterID, userID uint, limit uint64) ([]*event.Event, error) {
+	qb := sq.Select("event_id, user_id, type, payload, created_at").
+		From("events").
+		Where(sq.Gt{"event_id": afterID}).
+		OrderBy("event_id").
+		Limit(limit)
+	if userID != 0 {
+		qb = qb.Where(sq.Eq{"user_id": userID})
+	}
+	sqlQ, args, err := qb.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQ, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*event.Event, 0)
+	for rows.Next() {
+		e := &event.Event{}
+		var payload []byte
+		var createdAt *mysql.RawTime
+		if err = rows.Scan(&e.ID, &e.UserID, &e.Type, &payload, &createdAt); err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		if e.CreatedAt, err = createdAt.Time(); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err = rows.Err(); err != nil {
+		return events, err
+	}
+	return events, nil
+}
+
+func (s *db) LastID(ctx context.Context) (uint, error) {
+	var id uint
+	err := s.db.QueryRowContext(ctx, `SELECT IFNULL(MAX(event_id), 0) FROM events;`).Scan(&id)
+	return id, err
+}
+
+func (s *db) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM events WHERE created_at<?;`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
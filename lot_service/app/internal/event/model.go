@@ -0,0 +1,41 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	TypeMessage    = "message"    // new message in conversation
+	TypeBooking    = "booking"    // booking was requested or its status changed
+	TypeReview     = "review"     // review of the landlord was left or answered
+	TypeViewing    = "viewing"    // viewing appointment was booked, changed, cancelled or is coming soon
+	TypeAgreement  = "agreement"  // version of rental agreement was made or accepted by the other party
+	TypePayment    = "payment"    // payment succeeded, failed or was refunded
+	TypeMembership = "membership" // user was added to organization, got another role or was removed
+	TypeImport     = "import"     // import of lots from spreadsheet is finished
+	TypePriceDrop  = "price_drop" // price of lot in favorites of the user dropped
+	TypeModeration = "moderation" // moderator or complaints changed visibility of review or lot of the user
+)
+
+// Subjects of moderation events.
+const (
+	ModerationReview    = "review"    // review was hidden or shown again
+	ModerationDuplicate = "duplicate" // lot was confirmed as duplicate of another lot or the duplicate was dismissed
+)
+
+// Moderation is payload of moderation events, Object is the moderated review or duplicate.
+type Moderation struct {
+	Subject string `json:"subject"`
+	Object  any    `json:"object"`
+}
+
+// Event is a notification for the user, which is delivered to clients in real time.
+// IDs grow monotonically, so clients can resume receiving events after the last seen one.
+type Event struct {
+	ID        uint            `json:"id"`
+	UserID    uint            `json:"user_id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
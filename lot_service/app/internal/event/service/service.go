@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"time"
+)
+
+const (
+	defaultEventsLimit = 100
+	maxEventsLimit     = 1000
+)
+
+// Publisher records events for users. Events are best effort, failures are logged and don't fail
+// operations producing them.
+type Publisher interface {
+	Publish(ctx context.Context, userID uint, eventType string, payload any)
+}
+
+var _ Service = &service{}
+
+type Service interface {
+	Publisher
+	GetAfter(ctx context.Context, afterID, userID uint, limit uint64) ([]*event.Event, error)
+	GetLastID(ctx context.Context) (uint, error)
+}
+
+type service struct {
+	repository storage.Repository
+	logger     logging.Logger
+}
+
+func NewService(eventStorage storage.Repository, logger logging.Logger) (*service, error) {
+	return &service{
+		repository: eventStorage,
+		logger:     logger,
+	}, nil
+}
+
+func (s *service) Publish(ctx context.Context, userID uint, eventType string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		s.logger.Errorf("failed to marshal payload of %s event. error: %v", eventType, err)
+		return
+	}
+
+	e := &event.Event{
+		UserID:    userID,
+		Type:      eventType,
+		Payload:   data,
+		CreatedAt: time.Now(),
+	}
+	if e.ID, err = s.repository.Create(ctx, e); err != nil {
+		s.logger.Errorf("failed to publish %s event for user %d. error: %v", eventType, userID, err)
+		return
+	}
+	s.logger.Debugf("event %d (%s) published for user %d", e.ID, eventType, userID)
+}
+
+func (s *service) GetAfter(ctx context.Context, afterID, userID uint, limit uint64) ([]*event.Event, error) {
+	if limit == 0 {
+		limit = defaultEventsLimit
+	} else if limit > maxEventsLimit {
+		limit = maxEventsLimit
+	}
+
+	events, err := s.repository.FindAfter(ctx, afterID, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find events. error: %w", err)
+	}
+	return events, nil
+}
+
+func (s *service) GetLastID(ctx context.Context) (uint, error) {
+	id, err := s.repository.LastID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find last event. error: %w", err)
+	}
+	return id, nil
+}
+
+// RunRetention deletes events older than ttl every interval until ctx is done. Clients can't resume
+// from deleted events.
+func RunRetention(ctx context.Context, repository storage.Repository, ttl, interval time.Duration,
+	logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := repository.DeleteBefore(ctx, time.Now().Add(-ttl))
+			if err != nil {
+				logger.Errorf("failed to delete old events. error: %v", err)
+			} else if deleted > 0 {
+				logger.Infof("%d old events deleted", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, e *event.Event) (uint, error)
+	// FindAfter returns up to limit events with ID greater than afterID in order of IDs.
+	// Events of all users are returned, if userID is zero.
+	FindAfter(ctx context.Context, afterID, userID uint, limit uint64) ([]*event.Event, error)
+	// LastID returns ID of the last event or zero.
+	LastID(ctx context.Context) (uint, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/exchange/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/currency"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+func (s *db) FindRates(ctx context.Context) (*currency.Rates, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT currency, rate, updated_at FROM currency_rates;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := currency.NewRates(lot.DefaultCurrency)
+	for rows.Next() {
+		var code string
+		var rate float64
+		var rawUpdatedAt mysql.RawTime
+		if err = rows.Scan(&code, &rate, &rawUpdatedAt); err != nil {
+			return nil, err
+		}
+		updatedAt, err := rawUpdatedAt.Time()
+		if err != nil {
+			return nil, err
+		}
+		rates.Values[code] = rate
+		if updatedAt.After(rates.UpdatedAt) {
+			rates.UpdatedAt = updatedAt
+		}
+	}
+	return rates, rows.Err()
+}
+
+func (s *db) SaveRates(ctx context.Context, rates *currency.Rates) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM currency_rates;`); err != nil {
+		return err
+	}
+	for code, rate := range rates.Values {
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO currency_rates (currency, rate, updated_at)
+		VALUES (?, ?, ?);`, code, rate, rates.UpdatedAt.UTC())
+		if err != nil {
+			return err
+		}
+	}
+
+	// prices in currencies without rates can't be compared, so they are left out of price filters
+	_, err = tx.ExecContext(ctx, `
+	UPDATE lots
+	LEFT JOIN currency_rates r ON r.currency=lots.currency
+	SET lots.price_base=ROUND(lots.price * r.rate), lots.redacted_at=lots.redacted_at
+	WHERE NOT (lots.price_base <=> ROUND(lots.price * r.rate));`)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
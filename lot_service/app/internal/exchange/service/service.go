@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/exchange/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/currency"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	// Rates returns rates of currencies in lot.DefaultCurrency. Rates are cached and loaded again, when
+	// they are refreshed or the cache expires.
+	Rates(ctx context.Context) (*currency.Rates, error)
+	// Refresh loads rates from the source and converts prices of lots at them, if the rates are changed.
+	// It does nothing without source.
+	Refresh(ctx context.Context) error
+}
+
+type Config struct {
+	// CacheTTL is how long rates are cached, rates saved by other instances are used after it
+	CacheTTL time.Duration
+}
+
+type service struct {
+	repository storage.Repository
+	source     currency.Source
+	cfg        Config
+	logger     logging.Logger
+
+	mu       sync.Mutex
+	rates    *currency.Rates
+	loadedAt time.Time
+}
+
+// NewService returns service, which keeps the saved rates, if source is nil.
+func NewService(exchangeStorage storage.Repository, source currency.Source, cfg Config,
+	logger logging.Logger) (*service, error) {
+	return &service{
+		repository: exchangeStorage,
+		source:     source,
+		cfg:        cfg,
+		logger:     logger,
+	}, nil
+}
+
+func (s *service) Rates(ctx context.Context) (*currency.Rates, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rates != nil && time.Since(s.loadedAt) < s.cfg.CacheTTL {
+		return s.rates, nil
+	}
+
+	rates, err := s.repository.FindRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find rates of currencies. error: %w", err)
+	}
+	s.rates, s.loadedAt = rates, time.Now()
+	return rates, nil
+}
+
+func (s *service) Refresh(ctx context.Context) error {
+	if s.source == nil {
+		return nil
+	}
+	loaded, err := s.source.Rates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load rates of currencies. error: %w", err)
+	}
+	loaded, err = loaded.Rebase(lot.DefaultCurrency)
+	if err != nil {
+		return fmt.Errorf("failed to convert rates to %s. error: %w", lot.DefaultCurrency, err)
+	}
+
+	saved, err := s.Rates(ctx)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(saved.Values, loaded.Values) {
+		return nil
+	}
+
+	s.logger.Infof("saving rates of %d currencies..", len(loaded.Values))
+	if err = s.repository.SaveRates(ctx, loaded); err != nil {
+		return fmt.Errorf("failed to save rates of currencies. error: %w", err)
+	}
+	s.mu.Lock()
+	s.rates, s.loadedAt = loaded, time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// RunRefresher refreshes rates at start and then with the interval.
+func RunRefresher(ctx context.Context, s Service, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil {
+			logger.Errorf("failed to refresh rates of currencies. error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/currency"
+)
+
+// Repository keeps rates of currencies in the base currency of lots, see lot.DefaultCurrency.
+type Repository interface {
+	// FindRates returns the saved rates.
+	FindRates(ctx context.Context) (*currency.Rates, error)
+	// SaveRates replaces the saved rates and converts prices of lots at them.
+	SaveRates(ctx context.Context, rates *currency.Rates) error
+}
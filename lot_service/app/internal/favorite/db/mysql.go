@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/favorite"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/favorite/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+func (s *db) Create(ctx context.Context, f *favorite.Favorite) error {
+	_, err := s.db.ExecContext(ctx, `
+	INSERT IGNORE INTO favorites (user_id, lot_id, created_at)
+	VALUES (?, ?, ?);`, f.UserID, f.LotID, f.CreatedAt.UTC())
+	return err
+}
+
+func (s *db) FindByUserID(ctx context.Context, userID uint) ([]*favorite.Favorite, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT user_id, lot_id, created_at
+	FROM favorites
+	WHERE user_id=?
+	ORDER BY created_at DESC, lot_id DESC;`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favorites := make([]*favorite.Favorite, 0)
+	for rows.Next() {
+		f := &favorite.Favorite{}
+		var createdAt mysql.RawTime
+		if err = rows.Scan(&f.UserID, &f.LotID, &createdAt); err != nil {
+			return nil, err
+		}
+		if f.CreatedAt, err = createdAt.Time(); err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, f)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return favorites, nil
+}
+
+func (s *db) Count(ctx context.Context, userID uint) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM favorites WHERE user_id=?;`, userID).Scan(&count)
+	return count, err
+}
+
+func (s *db) FindUserIDs(ctx context.Context, lotID uint) ([]uint, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT user_id FROM favorites WHERE lot_id=?;`, lotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIDs := make([]uint, 0)
+	for rows.Next() {
+		var userID uint
+		if err = rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+func (s *db) Delete(ctx context.Context, userID, lotID uint) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM favorites WHERE user_id=? AND lot_id=?;`, userID, lotID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
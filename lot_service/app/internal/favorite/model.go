@@ -0,0 +1,26 @@
+package favorite
+
+import (
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"time"
+)
+
+// MaxFavorites limits number of lots in favorites of a user.
+const MaxFavorites = 200
+
+// PriceDrop is payload of price drop events for users, who saved the lot.
+type PriceDrop struct {
+	LotID    uint   `json:"lot_id"`
+	OldPrice int    `json:"old_price"`
+	NewPrice int    `json:"new_price"`
+	Currency string `json:"currency"`
+	Period   string `json:"price_period"`
+}
+
+// Favorite is lot saved by the user.
+type Favorite struct {
+	UserID    uint      `json:"user_id"`
+	LotID     uint      `json:"lot_id"`
+	Lot       *lot.Lot  `json:"lot,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
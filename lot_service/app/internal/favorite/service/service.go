@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/favorite"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/favorite/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"time"
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	// Add saves the lot in favorites of the user. Adding the lot again changes nothing.
+	Add(ctx context.Context, userID, lotID uint) (*favorite.Favorite, error)
+	// GetAll returns favorites of the user with their lots, newest first.
+	GetAll(ctx context.Context, userID uint) ([]*favorite.Favorite, error)
+	Remove(ctx context.Context, userID, lotID uint) error
+	// NotifyPriceDrop notifies users, who saved the lot, that its price dropped. Failures are only logged.
+	NotifyPriceDrop(ctx context.Context, before, after *lot.Lot)
+}
+
+type service struct {
+	repository storage.Repository
+	lots       lotStorage.Repository
+	events     eventService.Publisher
+	logger     logging.Logger
+}
+
+func NewService(favoriteStorage storage.Repository, lots lotStorage.Repository, events eventService.Publisher,
+	logger logging.Logger) (*service, error) {
+	return &service{
+		repository: favoriteStorage,
+		lots:       lots,
+		events:     events,
+		logger:     logger,
+	}, nil
+}
+
+func (s *service) Add(ctx context.Context, userID, lotID uint) (*favorite.Favorite, error) {
+	l, err := s.lots.FindByLotID(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot. error: %w", err)
+	}
+
+	count, err := s.repository.Count(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count favorites. error: %w", err)
+	}
+	if count >= favorite.MaxFavorites {
+		return nil, apperror.BadRequestError(fmt.Sprintf("at most %d lots can be saved", favorite.MaxFavorites), "")
+	}
+
+	f := &favorite.Favorite{
+		UserID:    userID,
+		LotID:     lotID,
+		Lot:       l,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if err = s.repository.Create(ctx, f); err != nil {
+		return nil, fmt.Errorf("failed to save favorite. error: %w", err)
+	}
+	return f, nil
+}
+
+func (s *service) GetAll(ctx context.Context, userID uint) ([]*favorite.Favorite, error) {
+	favorites, err := s.repository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find favorites. error: %w", err)
+	}
+	found := make([]*favorite.Favorite, 0, len(favorites))
+	for _, f := range favorites {
+		if f.Lot, err = s.lots.FindByLotID(ctx, f.LotID); err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				// the lot is deleted along with its favorites
+				continue
+			}
+			return nil, fmt.Errorf("failed to find lot of favorite. error: %w", err)
+		}
+		found = append(found, f)
+	}
+	return found, nil
+}
+
+func (s *service) Remove(ctx context.Context, userID, lotID uint) error {
+	if err := s.repository.Delete(ctx, userID, lotID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to remove favorite. error: %w", err)
+	}
+	return nil
+}
+
+func (s *service) NotifyPriceDrop(ctx context.Context, before, after *lot.Lot) {
+	if after.Price >= before.Price || after.Currency != before.Currency || after.PricePeriod != before.PricePeriod {
+		return
+	}
+	userIDs, err := s.repository.FindUserIDs(ctx, after.ID)
+	if err != nil {
+		s.logger.Errorf("failed to find users, who saved lot %d. error: %v", after.ID, err)
+		return
+	}
+
+	drop := &favorite.PriceDrop{
+		LotID:    after.ID,
+		OldPrice: before.Price,
+		NewPrice: after.Price,
+		Currency: after.Currency,
+		Period:   after.PricePeriod,
+	}
+	for _, userID := range userIDs {
+		s.events.Publish(ctx, userID, event.TypePriceDrop, drop)
+	}
+}
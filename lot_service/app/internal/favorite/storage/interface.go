@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/favorite"
+)
+
+type Repository interface {
+	// Create saves the lot in favorites of the user, the lot saved already keeps its time of saving.
+	Create(ctx context.Context, f *favorite.Favorite) error
+	// FindByUserID returns favorites of the user, newest first.
+	FindByUserID(ctx context.Context, userID uint) ([]*favorite.Favorite, error)
+	Count(ctx context.Context, userID uint) (int, error)
+	// FindUserIDs returns IDs of users, who saved the lot.
+	FindUserIDs(ctx context.Context, lotID uint) ([]uint, error)
+	Delete(ctx context.Context, userID, lotID uint) error
+}
@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/feed"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/feed/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"strings"
+)
+
+var _ storage.Repository = &db{}
+
+// errDuplicateEntry is code of MySQL error on violation of unique key.
+const errDuplicateEntry = 1062
+
+// contactsBatch limits number of lots in a query of contacts.
+const contactsBatch = 500
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func (s *db) Create(ctx context.Context, f *feed.Feed) (uint, error) {
+	res, err := s.db.ExecContext(ctx, `
+	INSERT INTO feeds (name, format, filter, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?);`, f.Name, f.Format, f.Filter, f.CreatedAt.UTC(), f.UpdatedAt.UTC())
+	if err != nil {
+		return 0, duplicate(err)
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), nil
+}
+
+const feedColumns = `feed_id, name, format, filter, created_at, updated_at`
+
+func scanFeed(row scanner) (*feed.Feed, error) {
+	f := &feed.Feed{}
+	var createdAt, updatedAt mysql.RawTime
+	if err := row.Scan(&f.ID, &f.Name, &f.Format, &f.Filter, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if f.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	if f.UpdatedAt, err = updatedAt.Time(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *db) FindByID(ctx context.Context, id uint) (*feed.Feed, error) {
+	f, err := scanFeed(s.db.QueryRowContext(ctx, `
+	SELECT `+feedColumns+`
+	FROM feeds
+	WHERE feed_id=?;`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *db) FindAll(ctx context.Context) ([]*feed.Feed, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT `+feedColumns+`
+	FROM feeds
+	ORDER BY name;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := make([]*feed.Feed, 0)
+	for rows.Next() {
+		f, err := scanFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, f)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+func (s *db) Update(ctx context.Context, f *feed.Feed) error {
+	res, err := s.db.ExecContext(ctx, `
+	UPDATE feeds
+	SET name=?, format=?, filter=?, updated_at=?
+	WHERE feed_id=?;`, f.Name, f.Format, f.Filter, f.UpdatedAt.UTC(), f.ID)
+	if err != nil {
+		return duplicate(err)
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		// nothing is changed or the feed doesn't exist
+		_, err = s.FindByID(ctx, f.ID)
+		return err
+	}
+	return nil
+}
+
+func (s *db) Delete(ctx context.Context, id uint) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM feeds WHERE feed_id=?;`, id)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+func (s *db) FindContacts(ctx context.Context, lotIDs []uint) (map[uint]*feed.Contact, error) {
+	contacts := make(map[uint]*feed.Contact, len(lotIDs))
+	for start := 0; start < len(lotIDs); start += contactsBatch {
+		end := start + contactsBatch
+		if end > len(lotIDs) {
+			end = len(lotIDs)
+		}
+		if err := s.findContacts(ctx, lotIDs[start:end], contacts); err != nil {
+			return nil, err
+		}
+	}
+	return contacts, nil
+}
+
+// findContacts adds contacts of the lots to the map. Lots of organizations are shown with phone and email
+// of the organization, phones of private landlords aren't published.
+func (s *db) findContacts(ctx context.Context, lotIDs []uint, contacts map[uint]*feed.Contact) error {
+	args := make([]any, 0, len(lotIDs))
+	for _, id := range lotIDs {
+		args = append(args, id)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT l.lot_id,
+		IFNULL(NULLIF(TRIM(CONCAT(IFNULL(u.given_name, ''), ' ', IFNULL(u.family_name, ''))), ''), u.username),
+		IFNULL(o.name, ''), IFNULL(o.phone, ''), IFNULL(o.email, '')
+	FROM lots l
+	JOIN users u ON u.user_id=l.user_id
+	LEFT JOIN organizations o ON o.organization_id=l.organization_id
+	WHERE l.lot_id IN (?`+strings.Repeat(", ?", len(lotIDs)-1)+`);`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lotID uint
+		c := &feed.Contact{}
+		if err = rows.Scan(&lotID, &c.Name, &c.Organization, &c.Phone, &c.Email); err != nil {
+			return err
+		}
+		contacts[lotID] = c
+	}
+	return rows.Err()
+}
+
+func duplicate(err error) error {
+	var mysqlErr *driver.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+		return apperror.ConflictError("feed with the name exists already")
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package feed
+
+import (
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"net/url"
+	"time"
+)
+
+const (
+	FormatYandex = "yandex" // Yandex.Realty XML
+	FormatAvito  = "avito"  // Avito autoload XML
+	FormatRSS    = "rss"
+	FormatAtom   = "atom"
+)
+
+// availabilityFilters are parameters of lot search, which aren't filters of fields, but are allowed in feeds.
+var availabilityFilters = map[string]bool{
+	"available_from":    true,
+	"available_between": true,
+	"group_duplicates":  true,
+}
+
+// Feed is a list of lots published for aggregator portals. Lots are selected with the filter.
+type Feed struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Format    string    `json:"format"`
+	Filter    string    `json:"filter"` // in query syntax of lot search, e.g. city=Москва&rooms=gte:2
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"` // when name, format or filter were changed
+}
+
+type CreateFeedDTO struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Filter string `json:"filter"`
+}
+
+type UpdateFeedDTO struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Filter string `json:"filter"`
+}
+
+// Contact is shown in feed as seller of the lot.
+type Contact struct {
+	Name         string
+	Phone        string
+	Email        string
+	Organization string // empty for private landlords
+}
+
+// Item is lot in the feed.
+type Item struct {
+	Lot     *lot.Lot
+	URL     string
+	Contact Contact
+}
+
+// Channel describes the feed in RSS and Atom.
+type Channel struct {
+	Title     string
+	URL       string // of the feed itself
+	SiteURL   string
+	UpdatedAt time.Time
+}
+
+// Document is rendered feed. ETag changes along with the lots of the feed.
+type Document struct {
+	ContentType string
+	Data        []byte
+	ETag        string
+	ModifiedAt  time.Time
+}
+
+func (dto *CreateFeedDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&dto.Format, validation.Required, validation.In(
+			FormatYandex, FormatAvito, FormatRSS, FormatAtom)),
+		validation.Field(&dto.Filter, validation.Length(0, 1000), validation.By(validateFilter)))
+}
+
+func (dto *UpdateFeedDTO) ValidateFields() error {
+	if err := validation.Validate(dto.ID, validation.Required); err != nil {
+		return fmt.Errorf("id: %w", err)
+	}
+	create := CreateFeedDTO{Name: dto.Name, Format: dto.Format, Filter: dto.Filter}
+	return create.ValidateFields()
+}
+
+// validateFilter checks, that filter is a query of known parameters of lot search.
+func validateFilter(value any) error {
+	query, err := url.ParseQuery(value.(string))
+	if err != nil {
+		return fmt.Errorf("must be in query syntax: %w", err)
+	}
+	for name := range query {
+		if _, ok := storage.FilterDataType(name); !ok && !availabilityFilters[name] {
+			return fmt.Errorf("unknown filter %q", name)
+		}
+	}
+	return nil
+}
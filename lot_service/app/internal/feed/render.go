@@ -0,0 +1,388 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"strings"
+	"time"
+)
+
+const (
+	estateFlat  = "квартира"
+	estateHouse = "дом"
+)
+
+// ContentType returns content type of documents in the format.
+func ContentType(format string) string {
+	switch format {
+	case FormatRSS:
+		return "application/rss+xml; charset=utf-8"
+	case FormatAtom:
+		return "application/atom+xml; charset=utf-8"
+	}
+	return "application/xml; charset=utf-8"
+}
+
+// Render renders items in the format. Channel is used in RSS and Atom only.
+func Render(format string, ch Channel, items []*Item) ([]byte, error) {
+	var v any
+	switch format {
+	case FormatYandex:
+		v = yandexFeed(ch, items)
+	case FormatAvito:
+		v = avitoFeed(items)
+	case FormatRSS:
+		v = rssFeed(ch, items)
+	case FormatAtom:
+		v = atomFeed(ch, items)
+	default:
+		return nil, fmt.Errorf("unknown format of feed %q", format)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to render %s feed. error: %w", format, err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// Title is short description of the lot, e.g. "2-комн. квартира, 45 м², Москва, Ленина, 1".
+func Title(l *lot.Lot) string {
+	kind := l.TypeOfEstate
+	if l.Rooms > 0 {
+		kind = fmt.Sprintf("%d-комн. %s", l.Rooms, l.TypeOfEstate)
+	} else if l.TypeOfEstate == estateFlat {
+		kind = "студия"
+	}
+	return fmt.Sprintf("%s, %d м², %s", kind, l.Area, address(l))
+}
+
+func description(l *lot.Lot) string {
+	parts := []string{Title(l), fmt.Sprintf("район %s", l.District)}
+	if l.Floor > 0 && l.MaxFloor > 0 {
+		parts = append(parts, fmt.Sprintf("этаж %d из %d", l.Floor, l.MaxFloor))
+	}
+	parts = append(parts, price(l))
+	return strings.Join(parts, ", ")
+}
+
+// price is price of the lot with its currency and period, e.g. "50000 руб. в месяц".
+func price(l *lot.Lot) string {
+	unit := l.Currency
+	if unit == lot.DefaultCurrency {
+		unit = "руб."
+	}
+	switch l.PricePeriod {
+	case lot.PeriodDay:
+		return fmt.Sprintf("%d %s в сутки", l.Price, unit)
+	case lot.PeriodSale:
+		return fmt.Sprintf("%d %s", l.Price, unit)
+	}
+	return fmt.Sprintf("%d %s в месяц", l.Price, unit)
+}
+
+func address(l *lot.Lot) string {
+	return fmt.Sprintf("%s, %s, %s", l.City, l.Street, l.Building)
+}
+
+// formatTime formats time in UTC, zero time of empty feed is omitted.
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(layout)
+}
+
+// modifiedAt returns time of the last change of the lot.
+func modifiedAt(l *lot.Lot) time.Time {
+	if l.RedactedAt.After(l.CreatedAt) {
+		return l.RedactedAt
+	}
+	return l.CreatedAt
+}
+
+type yandexRealtyFeed struct {
+	XMLName        xml.Name      `xml:"http://webmaster.yandex.ru/schemas/feed/realty/2010-06 realty-feed"`
+	GenerationDate string        `xml:"generation-date,omitempty"`
+	Offers         []yandexOffer `xml:"offer"`
+}
+
+type yandexOffer struct {
+	InternalID     uint           `xml:"internal-id,attr"`
+	Type           string         `xml:"type"`
+	PropertyType   string         `xml:"property-type"`
+	Category       string         `xml:"category"`
+	URL            string         `xml:"url"`
+	CreationDate   string         `xml:"creation-date"`
+	LastUpdateDate string         `xml:"last-update-date"`
+	Location       yandexLocation `xml:"location"`
+	SalesAgent     yandexAgent    `xml:"sales-agent"`
+	Price          yandexPrice    `xml:"price"`
+	Area           yandexArea     `xml:"area"`
+	Rooms          int            `xml:"rooms,omitempty"`
+	Studio         string         `xml:"studio,omitempty"`
+	Floor          int            `xml:"floor,omitempty"`
+	FloorsTotal    int            `xml:"floors-total,omitempty"`
+	Description    string         `xml:"description"`
+}
+
+type yandexLocation struct {
+	Country         string `xml:"country"`
+	LocalityName    string `xml:"locality-name"`
+	SubLocalityName string `xml:"sub-locality-name"`
+	Address         string `xml:"address"`
+}
+
+type yandexAgent struct {
+	Name         string `xml:"name,omitempty"`
+	Phone        string `xml:"phone,omitempty"`
+	Category     string `xml:"category"`
+	Organization string `xml:"organization,omitempty"`
+	Email        string `xml:"email,omitempty"`
+}
+
+type yandexPrice struct {
+	Value    int    `xml:"value"`
+	Currency string `xml:"currency"`
+	Period   string `xml:"period,omitempty"` // of rent only
+}
+
+type yandexArea struct {
+	Value int    `xml:"value"`
+	Unit  string `xml:"unit"`
+}
+
+func yandexFeed(ch Channel, items []*Item) *yandexRealtyFeed {
+	f := &yandexRealtyFeed{
+		GenerationDate: formatTime(ch.UpdatedAt, time.RFC3339),
+		Offers:         make([]yandexOffer, 0, len(items)),
+	}
+	for _, item := range items {
+		l := item.Lot
+		o := yandexOffer{
+			InternalID:     l.ID,
+			Type:           "аренда",
+			PropertyType:   "жилая",
+			Category:       l.TypeOfEstate,
+			URL:            item.URL,
+			CreationDate:   l.CreatedAt.UTC().Format(time.RFC3339),
+			LastUpdateDate: modifiedAt(l).UTC().Format(time.RFC3339),
+			Location: yandexLocation{
+				Country:         "Россия",
+				LocalityName:    l.City,
+				SubLocalityName: l.District,
+				Address:         fmt.Sprintf("%s, %s", l.Street, l.Building),
+			},
+			SalesAgent: yandexAgent{
+				Name:         item.Contact.Name,
+				Phone:        item.Contact.Phone,
+				Category:     "владелец",
+				Organization: item.Contact.Organization,
+				Email:        item.Contact.Email,
+			},
+			Price:       yandexPrice{Value: l.Price, Currency: l.Currency, Period: "месяц"},
+			Area:        yandexArea{Value: l.Area, Unit: "кв. м"},
+			Rooms:       l.Rooms,
+			Floor:       l.Floor,
+			FloorsTotal: l.MaxFloor,
+			Description: description(l),
+		}
+		if item.Contact.Organization != "" {
+			o.SalesAgent.Category = "агентство"
+		}
+		switch l.PricePeriod {
+		case lot.PeriodDay:
+			o.Price.Period = "день"
+		case lot.PeriodSale:
+			o.Type, o.Price.Period = "продажа", ""
+		}
+		if l.Rooms == 0 && l.TypeOfEstate == estateFlat {
+			o.Studio = "1"
+		}
+		f.Offers = append(f.Offers, o)
+	}
+	return f
+}
+
+type avitoAds struct {
+	XMLName       xml.Name  `xml:"Ads"`
+	FormatVersion string    `xml:"formatVersion,attr"`
+	Target        string    `xml:"target,attr"`
+	Ads           []avitoAd `xml:"Ad"`
+}
+
+type avitoAd struct {
+	ID             uint   `xml:"Id"`
+	ManagerName    string `xml:"ManagerName,omitempty"`
+	ContactPhone   string `xml:"ContactPhone,omitempty"`
+	CompanyName    string `xml:"CompanyName,omitempty"`
+	Address        string `xml:"Address"`
+	Category       string `xml:"Category"`
+	OperationType  string `xml:"OperationType"`
+	PropertyRights string `xml:"PropertyRights"`
+	LeaseType      string `xml:"LeaseType,omitempty"` // of rent only
+	ObjectType     string `xml:"ObjectType,omitempty"`
+	Price          int    `xml:"Price"`
+	Rooms          string `xml:"Rooms,omitempty"`
+	Square         int    `xml:"Square"`
+	Floor          int    `xml:"Floor,omitempty"`
+	Floors         int    `xml:"Floors,omitempty"`
+	Description    string `xml:"Description"`
+}
+
+func avitoFeed(items []*Item) *avitoAds {
+	f := &avitoAds{
+		FormatVersion: "3",
+		Target:        "Avito.ru",
+		Ads:           make([]avitoAd, 0, len(items)),
+	}
+	for _, item := range items {
+		l := item.Lot
+		ad := avitoAd{
+			ID:             l.ID,
+			ManagerName:    item.Contact.Name,
+			ContactPhone:   item.Contact.Phone,
+			CompanyName:    item.Contact.Organization,
+			Address:        address(l),
+			Category:       "Квартиры",
+			OperationType:  "Сдам",
+			PropertyRights: "Собственник",
+			LeaseType:      "На длительный срок",
+			Price:          l.BasePrice(), // Avito accepts prices in rubles only
+			Square:         l.Area,
+			Floors:         l.MaxFloor,
+			Description:    fmt.Sprintf("%s\n%s", description(l), item.URL),
+		}
+		if item.Contact.Organization != "" {
+			ad.PropertyRights = "Посредник"
+		}
+		switch l.PricePeriod {
+		case lot.PeriodDay:
+			ad.LeaseType = "Посуточно"
+		case lot.PeriodSale:
+			ad.OperationType, ad.LeaseType = "Продам", ""
+		}
+		if l.TypeOfEstate == estateHouse {
+			ad.Category = "Дома, дачи, коттеджи"
+			ad.ObjectType = "Дом"
+		} else {
+			ad.Floor = l.Floor
+			ad.Rooms = "Студия"
+			if l.Rooms > 0 {
+				ad.Rooms = fmt.Sprint(l.Rooms)
+			}
+		}
+		f.Ads = append(f.Ads, ad)
+	}
+	return f
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func rssFeed(ch Channel, items []*Item) *rss {
+	f := &rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         ch.Title,
+			Link:          ch.SiteURL,
+			Description:   ch.Title,
+			LastBuildDate: formatTime(ch.UpdatedAt, time.RFC1123Z),
+			Items:         make([]rssItem, 0, len(items)),
+		},
+	}
+	for _, item := range items {
+		f.Channel.Items = append(f.Channel.Items, rssItem{
+			Title:       Title(item.Lot),
+			Link:        item.URL,
+			Description: description(item.Lot),
+			GUID:        rssGUID{IsPermaLink: true, Value: item.URL},
+			PubDate:     item.Lot.CreatedAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return f
+}
+
+type atom struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated,omitempty"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Link      atomLink   `xml:"link"`
+	Author    atomAuthor `xml:"author"`
+	Summary   string     `xml:"summary"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+func atomFeed(ch Channel, items []*Item) *atom {
+	f := &atom{
+		ID:      ch.URL,
+		Title:   ch.Title,
+		Updated: formatTime(ch.UpdatedAt, time.RFC3339),
+		Links:   []atomLink{{Rel: "self", Href: ch.URL}, {Href: ch.SiteURL}},
+		Entries: make([]atomEntry, 0, len(items)),
+	}
+	for _, item := range items {
+		author := item.Contact.Organization
+		if author == "" {
+			author = item.Contact.Name
+		}
+		f.Entries = append(f.Entries, atomEntry{
+			ID:        item.URL,
+			Title:     Title(item.Lot),
+			Updated:   modifiedAt(item.Lot).UTC().Format(time.RFC3339),
+			Published: item.Lot.CreatedAt.UTC().Format(time.RFC3339),
+			Link:      atomLink{Href: item.URL},
+			Author:    atomAuthor{Name: author},
+			Summary:   description(item.Lot),
+		})
+	}
+	return f
+}
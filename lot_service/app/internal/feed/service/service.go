@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/feed"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/feed/storage"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/api/sort"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCached limits number of documents kept in memory, since feeds of cities are requested by any name.
+const maxCached = 1000
+
+var _ Service = &service{}
+
+type Service interface {
+	Create(ctx context.Context, dto *feed.CreateFeedDTO) (*feed.Feed, error)
+	GetAll(ctx context.Context) ([]*feed.Feed, error)
+	Update(ctx context.Context, dto *feed.UpdateFeedDTO) (*feed.Feed, error)
+	Delete(ctx context.Context, id uint) error
+
+	// GetDocument returns the feed rendered with all its lots, newest first. The document is rendered again
+	// only when the feed or its lots change, otherwise the previous document is returned with the same ETag.
+	GetDocument(ctx context.Context, id uint) (*feed.Document, error)
+	// GetCityDocument returns RSS or Atom feed of the newest lots of the city, rendered like GetDocument.
+	GetCityDocument(ctx context.Context, city, format string) (*feed.Document, error)
+}
+
+type Config struct {
+	// LotURL is format of URL of lot page on the site, ID of the lot is its only argument
+	LotURL string
+	// SiteURL is link of RSS and Atom feeds, URLs of feeds of cities start with it too
+	SiteURL string
+	// ContactPhone is published for lots of private landlords, whose phones are revealed only on request
+	ContactPhone string
+	// CityItems is number of the newest lots in feeds of cities
+	CityItems int
+}
+
+type service struct {
+	repository storage.Repository
+	lots       lotStorage.Repository
+	cfg        Config
+	logger     logging.Logger
+
+	mu    sync.Mutex
+	cache map[string]*feed.Document
+}
+
+func NewService(feedStorage storage.Repository, lots lotStorage.Repository, cfg Config,
+	logger logging.Logger) (*service, error) {
+	return &service{
+		repository: feedStorage,
+		lots:       lots,
+		cfg:        cfg,
+		logger:     logger,
+		cache:      make(map[string]*feed.Document),
+	}, nil
+}
+
+func (s *service) Create(ctx context.Context, dto *feed.CreateFeedDTO) (*feed.Feed, error) {
+	s.logger.Debug("validating feed fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	if _, err := queryOptions(dto.Filter); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	f := &feed.Feed{
+		Name:      dto.Name,
+		Format:    dto.Format,
+		Filter:    dto.Filter,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.logger.Debug("creating new feed..")
+	var err error
+	f.ID, err = s.repository.Create(ctx, f)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create feed. error: %w", err)
+	}
+	return f, nil
+}
+
+func (s *service) GetAll(ctx context.Context) ([]*feed.Feed, error) {
+	feeds, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find feeds. error: %w", err)
+	}
+	return feeds, nil
+}
+
+func (s *service) Update(ctx context.Context, dto *feed.UpdateFeedDTO) (*feed.Feed, error) {
+	s.logger.Debug("validating feed fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	if _, err := queryOptions(dto.Filter); err != nil {
+		return nil, err
+	}
+
+	f, err := s.find(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	f.Name = dto.Name
+	f.Format = dto.Format
+	f.Filter = dto.Filter
+	f.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	if err = s.repository.Update(ctx, f); err != nil {
+		var appErr *apperror.AppError
+		if errors.Is(err, apperror.ErrNotFound) || errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update feed. error: %w", err)
+	}
+	return f, nil
+}
+
+func (s *service) Delete(ctx context.Context, id uint) error {
+	if err := s.repository.Delete(ctx, id); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete feed. error: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.cache, feedKey(id))
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *service) GetDocument(ctx context.Context, id uint) (*feed.Document, error) {
+	f, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	options, err := queryOptions(f.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := feed.Channel{
+		Title:   f.Name,
+		URL:     fmt.Sprintf("%s/api/feeds/%d", s.cfg.SiteURL, f.ID),
+		SiteURL: s.cfg.SiteURL,
+	}
+	return s.render(ctx, feedKey(f.ID), f.Format, options, ch, f.UpdatedAt)
+}
+
+func (s *service) GetCityDocument(ctx context.Context, city, format string) (*feed.Document, error) {
+	city = strings.TrimSpace(city)
+	if city == "" {
+		return nil, apperror.BadRequestError("city is required", "")
+	}
+	if format != feed.FormatRSS && format != feed.FormatAtom {
+		return nil, apperror.BadRequestError("feeds of cities are available in rss and atom formats", "")
+	}
+
+	options, err := queryOptions(url.Values{"city": {city}}.Encode())
+	if err != nil {
+		return nil, err
+	}
+	options.WithLimit(s.cfg.CityItems)
+
+	ch := feed.Channel{
+		Title: fmt.Sprintf("Новые объявления: %s", city),
+		URL: fmt.Sprintf("%s/api/city-feeds/%s?%s", s.cfg.SiteURL, url.PathEscape(city),
+			url.Values{"format": {format}}.Encode()),
+		SiteURL: s.cfg.SiteURL,
+	}
+	key := fmt.Sprintf("city:%s:%s", format, strings.ToLower(city))
+	return s.render(ctx, key, format, options, ch, time.Time{})
+}
+
+// render returns cached document, if version of the lots and time of change of the feed are the same,
+// otherwise renders the document again.
+func (s *service) render(ctx context.Context, key, format string, options *lotStorage.Options, ch feed.Channel,
+	changedAt time.Time) (*feed.Document, error) {
+	v, err := s.lots.Version(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version of lots of feed. error: %w", err)
+	}
+	tag := etag(key, format, changedAt, v)
+
+	s.mu.Lock()
+	doc, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && doc.ETag == tag {
+		return doc, nil
+	}
+
+	s.logger.Debugf("rendering feed %s..", key)
+	lots, err := s.lots.FindWithFilter(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find lots of feed. error: %w", err)
+	}
+	lotIDs := make([]uint, 0, len(lots))
+	for _, l := range lots {
+		lotIDs = append(lotIDs, l.ID)
+	}
+	contacts, err := s.repository.FindContacts(ctx, lotIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find contacts of lots of feed. error: %w", err)
+	}
+
+	items := make([]*feed.Item, 0, len(lots))
+	for _, l := range lots {
+		item := &feed.Item{Lot: l, URL: fmt.Sprintf(s.cfg.LotURL, l.ID)}
+		if c, ok := contacts[l.ID]; ok {
+			item.Contact = *c
+		}
+		if item.Contact.Phone == "" {
+			item.Contact.Phone = s.cfg.ContactPhone
+		}
+		items = append(items, item)
+	}
+
+	modifiedAt := changedAt
+	if v.ModifiedAt.After(modifiedAt) {
+		modifiedAt = v.ModifiedAt
+	}
+	ch.UpdatedAt = modifiedAt
+	data, err := feed.Render(format, ch, items)
+	if err != nil {
+		return nil, err
+	}
+
+	doc = &feed.Document{
+		ContentType: feed.ContentType(format),
+		Data:        data,
+		ETag:        tag,
+		ModifiedAt:  modifiedAt,
+	}
+	s.store(key, doc)
+	return doc, nil
+}
+
+func (s *service) store(key string, doc *feed.Document) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.cache[key]; !ok && len(s.cache) >= maxCached {
+		for k := range s.cache {
+			delete(s.cache, k)
+			break
+		}
+	}
+	s.cache[key] = doc
+}
+
+func (s *service) find(ctx context.Context, id uint) (*feed.Feed, error) {
+	f, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find feed by its id. error: %w", err)
+	}
+	return f, nil
+}
+
+// queryOptions selects lots matching the filter, newest first.
+func queryOptions(filter string) (*lotStorage.Options, error) {
+	query, err := url.ParseQuery(filter)
+	if err != nil {
+		return nil, apperror.BadRequestError("filter must be in query syntax", "")
+	}
+	return lotService.NewQueryOptions(&sort.Options{Field: sort.DefSort, Order: sort.DefOrder}, query)
+}
+
+func feedKey(id uint) string {
+	return fmt.Sprintf("feed:%d", id)
+}
+
+// etag is weak, since changes of contacts of agents don't change version of lots.
+func etag(key, format string, changedAt time.Time, v *lotStorage.Version) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%d|%d|%d",
+		key, format, changedAt.Unix(), v.Count, v.LastID, v.ModifiedAt.Unix())))
+	return fmt.Sprintf(`W/"%s"`, hex.EncodeToString(sum[:16]))
+}
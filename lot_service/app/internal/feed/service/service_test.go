@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/feed"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/feed/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"strings"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	storage.Repository
+	feed *feed.Feed
+}
+
+func (r *repo) FindByID(_ context.Context, _ uint) (*feed.Feed, error) {
+	if r.feed == nil {
+		return nil, apperror.ErrNotFound
+	}
+	return r.feed, nil
+}
+
+func (r *repo) FindContacts(_ context.Context, lotIDs []uint) (map[uint]*feed.Contact, error) {
+	contacts := make(map[uint]*feed.Contact)
+	for _, id := range lotIDs {
+		contacts[id] = &feed.Contact{Name: "Иван Петров", Organization: "Агентство"}
+	}
+	return contacts, nil
+}
+
+type lots struct {
+	lotStorage.Repository
+	lots     []*lot.Lot
+	searches int
+	filters  map[string][]lotStorage.FilterOption
+}
+
+func (l *lots) Version(_ context.Context, _ lotStorage.QueryOptions) (*lotStorage.Version, error) {
+	v := &lotStorage.Version{Count: len(l.lots)}
+	for _, lt := range l.lots {
+		if lt.ID > v.LastID {
+			v.LastID = lt.ID
+		}
+		if lt.RedactedAt.After(v.ModifiedAt) {
+			v.ModifiedAt = lt.RedactedAt
+		}
+	}
+	return v, nil
+}
+
+func (l *lots) FindWithFilter(_ context.Context, options lotStorage.QueryOptions) ([]*lot.Lot, error) {
+	l.searches++
+	l.filters = options.GetFilters()
+	return l.lots, nil
+}
+
+func TestGetDocument(t *testing.T) {
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	l := &lots{lots: []*lot.Lot{{
+		ID: 1, TypeOfEstate: "квартира", Rooms: 2, Area: 45, Floor: 3, MaxFloor: 9, City: "Москва",
+		District: "Арбат", Street: "Арбат", Building: "1", Price: 50000, CreatedAt: created, RedactedAt: created,
+	}}}
+	r := &repo{feed: &feed.Feed{ID: 7, Name: "yandex", Format: feed.FormatYandex, Filter: "city=Москва&rooms=gte:2",
+		UpdatedAt: created}}
+	s, _ := NewService(r, l, Config{LotURL: "https://site/lots/%d", SiteURL: "https://site"}, logging.GetLogger())
+	ctx := context.Background()
+
+	doc, err := s.GetDocument(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.filters["city"]) != 1 || l.filters["rooms"][0].Operator != ">=" {
+		t.Errorf("lots must be selected with filter of the feed, got %v", l.filters)
+	}
+	var parsed struct {
+		Offers []struct {
+			ID    uint   `xml:"internal-id,attr"`
+			URL   string `xml:"url"`
+			Agent struct {
+				Category string `xml:"category"`
+			} `xml:"sales-agent"`
+		} `xml:"offer"`
+	}
+	if err = xml.Unmarshal(doc.Data, &parsed); err != nil {
+		t.Fatalf("feed is not valid XML: %v", err)
+	}
+	if len(parsed.Offers) != 1 || parsed.Offers[0].URL != "https://site/lots/1" ||
+		parsed.Offers[0].Agent.Category != "агентство" {
+		t.Errorf("unexpected offers %+v", parsed.Offers)
+	}
+
+	again, err := s.GetDocument(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.searches != 1 || again.ETag != doc.ETag {
+		t.Errorf("unchanged feed must not be rendered again, rendered %d times", l.searches)
+	}
+
+	l.lots[0].RedactedAt = created.Add(time.Hour)
+	changed, err := s.GetDocument(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.searches != 2 || changed.ETag == doc.ETag || !changed.ModifiedAt.Equal(created.Add(time.Hour)) {
+		t.Errorf("feed must be rendered again after change of lot, rendered %d times, modified at %v",
+			l.searches, changed.ModifiedAt)
+	}
+}
+
+func TestGetCityDocument(t *testing.T) {
+	s, _ := NewService(&repo{}, &lots{}, Config{SiteURL: "https://site", CityItems: 10}, logging.GetLogger())
+
+	doc, err := s.GetCityDocument(context.Background(), "Москва", feed.FormatAtom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(doc.Data), `<feed xmlns="http://www.w3.org/2005/Atom">`) {
+		t.Errorf("atom feed expected, got %s", doc.Data)
+	}
+
+	_, err = s.GetCityDocument(context.Background(), "Москва", feed.FormatYandex)
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) {
+		t.Errorf("expected bad request for yandex feed of city, got %v", err)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	s, _ := NewService(&repo{}, &lots{}, Config{}, logging.GetLogger())
+
+	_, err := s.Create(context.Background(), &feed.CreateFeedDTO{Name: "avito", Format: feed.FormatAvito,
+		Filter: "city=Москва&owner=1"})
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) || !strings.Contains(err.Error(), "owner") {
+		t.Errorf("expected bad request for unknown filter, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/feed"
+)
+
+type Repository interface {
+	Create(ctx context.Context, f *feed.Feed) (uint, error)
+	FindByID(ctx context.Context, id uint) (*feed.Feed, error)
+	FindAll(ctx context.Context) ([]*feed.Feed, error)
+	Update(ctx context.Context, f *feed.Feed) error
+	Delete(ctx context.Context, id uint) error
+	// FindContacts returns contacts of agents of the lots and of their organizations by IDs of the lots.
+	FindContacts(ctx context.Context, lotIDs []uint) (map[uint]*feed.Contact, error)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	addressService "github.com/levelord1311/backendForSharedProject/lot_service/internal/address/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"strconv"
+)
+
+const (
+	citiesURL        = "/api/cities"
+	cityDistrictsURL = "/api/cities/:id/districts"
+)
+
+// AddressHandler autocompletes canonical cities and districts, lots are filtered by their IDs.
+type AddressHandler struct {
+	Logger         logging.Logger
+	AddressService addressService.Service
+}
+
+func (h *AddressHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, citiesURL, apperror.Middleware(h.GetCities))
+	router.HandlerFunc(http.MethodGet, cityDistrictsURL, apperror.Middleware(h.GetDistricts))
+}
+
+// GetCities returns cities, names of which start with q from the query.
+func (h *AddressHandler) GetCities(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET CITIES")
+	w.Header().Set("Content-Type", "application/json")
+
+	limit, err := limitFromQuery(r)
+	if err != nil {
+		return err
+	}
+
+	cities, err := h.AddressService.SearchCities(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, cities, http.StatusOK)
+}
+
+// GetDistricts returns districts of the city, names of which start with q from the query.
+func (h *AddressHandler) GetDistricts(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET DISTRICTS")
+	w.Header().Set("Content-Type", "application/json")
+
+	cityID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	limit, err := limitFromQuery(r)
+	if err != nil {
+		return err
+	}
+
+	districts, err := h.AddressService.SearchDistricts(r.Context(), cityID, r.URL.Query().Get("q"), limit)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, districts, http.StatusOK)
+}
+
+// limitFromQuery returns zero, if the limit is omitted.
+func limitFromQuery(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, apperror.BadRequestError("limit must be an integer", "")
+	}
+	return limit, nil
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement"
+	agreementService "github.com/levelord1311/backendForSharedProject/lot_service/internal/agreement/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	agreementTemplatesURL      = "/api/agreement-templates"
+	singleAgreementTemplateURL = "/api/agreement-templates/:id"
+	bookingAgreementsURL       = "/api/bookings/:id/agreements"
+	agreementsURL              = "/api/agreements"
+	singleAgreementURL         = "/api/agreements/:id"
+	agreementDocumentURL       = "/api/agreements/:id/document"
+	agreementAcceptanceURL     = "/api/agreements/:id/acceptance"
+	// clientIPHeader is set by api_service to IP of the client, it's kept as evidence of acceptance
+	clientIPHeader = "X-Real-IP"
+)
+
+type AgreementHandler struct {
+	Logger           logging.Logger
+	AgreementService agreementService.Service
+}
+
+func (h *AgreementHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, agreementTemplatesURL, apperror.Middleware(h.GetTemplates))
+	router.HandlerFunc(http.MethodPost, agreementTemplatesURL, apperror.Middleware(h.CreateTemplate))
+	router.HandlerFunc(http.MethodGet, singleAgreementTemplateURL, apperror.Middleware(h.GetTemplate))
+	router.HandlerFunc(http.MethodDelete, singleAgreementTemplateURL, apperror.Middleware(h.DeleteTemplate))
+	router.HandlerFunc(http.MethodGet, bookingAgreementsURL, apperror.Middleware(h.GetAgreements))
+	router.HandlerFunc(http.MethodPost, bookingAgreementsURL, apperror.Middleware(h.CreateAgreement))
+	router.HandlerFunc(http.MethodGet, singleAgreementURL, apperror.Middleware(h.GetAgreement))
+	router.HandlerFunc(http.MethodGet, agreementDocumentURL, apperror.Middleware(h.GetDocument))
+	router.HandlerFunc(http.MethodPut, agreementAcceptanceURL, apperror.Middleware(h.Accept))
+}
+
+// GetTemplates returns the default template and templates of the requester.
+func (h *AgreementHandler) GetTemplates(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET AGREEMENT TEMPLATES")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	templates, err := h.AgreementService.GetTemplates(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, templates, http.StatusOK)
+}
+
+func (h *AgreementHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE AGREEMENT TEMPLATE")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create template dto..")
+	dto := &agreement.CreateTemplateDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.OwnerID = userID
+
+	t, err := h.AgreementService.CreateTemplate(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", agreementTemplatesURL, t.ID))
+	return writeJSON(w, t, http.StatusCreated)
+}
+
+func (h *AgreementHandler) GetTemplate(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET AGREEMENT TEMPLATE")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	templateID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	t, err := h.AgreementService.GetTemplate(r.Context(), templateID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, t, http.StatusOK)
+}
+
+func (h *AgreementHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("DELETE AGREEMENT TEMPLATE")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	templateID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.AgreementService.DeleteTemplate(r.Context(), templateID, userID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetAgreements returns all versions of agreement of the booking, the latest first.
+func (h *AgreementHandler) GetAgreements(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET AGREEMENTS OF BOOKING")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	bookingID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	agreements, err := h.AgreementService.GetByBookingID(r.Context(), bookingID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, agreements, http.StatusOK)
+}
+
+func (h *AgreementHandler) CreateAgreement(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE AGREEMENT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	bookingID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create agreement dto..")
+	dto := &agreement.CreateAgreementDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.BookingID = bookingID
+	dto.UserID = userID
+
+	a, err := h.AgreementService.Create(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", agreementsURL, a.ID))
+	return writeJSON(w, a, http.StatusCreated)
+}
+
+func (h *AgreementHandler) GetAgreement(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET AGREEMENT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	agreementID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	a, err := h.AgreementService.GetByID(r.Context(), agreementID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, a, http.StatusOK)
+}
+
+// GetDocument renders the agreement in format from the query, html by default.
+func (h *AgreementHandler) GetDocument(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET AGREEMENT DOCUMENT")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	agreementID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = agreement.FormatHTML
+	}
+
+	doc, err := h.AgreementService.GetDocument(r.Context(), agreementID, userID, format)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", doc.FileName))
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc.Data)
+	return nil
+}
+
+// Accept records acceptance of the agreement by the requester with IP of the client.
+func (h *AgreementHandler) Accept(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("ACCEPT AGREEMENT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	agreementID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into accept dto..")
+	dto := &agreement.AcceptDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = agreementID
+	dto.UserID = userID
+	dto.IP = r.Header.Get(clientIPHeader)
+
+	a, err := h.AgreementService.Accept(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, a, http.StatusOK)
+}
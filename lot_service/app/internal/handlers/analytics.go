@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	analyticsService "github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"strconv"
+)
+
+const (
+	lotAnalyticsURL = "/api/lots/lot/:id/analytics"
+	lotRevealsURL   = "/api/lots/lot/:id/reveals"
+	dashboardURL    = "/api/analytics/lots"
+
+	// sessionIDHeader is set by api_service to ID of session of anonymous visitor, so views are counted
+	// once per session
+	sessionIDHeader = "X-Session-ID"
+)
+
+type AnalyticsHandler struct {
+	Logger           logging.Logger
+	AnalyticsService analyticsService.Service
+}
+
+func (h *AnalyticsHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, lotAnalyticsURL, apperror.Middleware(h.GetLotAnalytics))
+	router.HandlerFunc(http.MethodGet, dashboardURL, apperror.Middleware(h.GetDashboard))
+	router.HandlerFunc(http.MethodPost, lotRevealsURL, apperror.Middleware(h.RecordReveal))
+}
+
+func (h *AnalyticsHandler) GetLotAnalytics(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET LOT ANALYTICS")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	days, err := daysFromQuery(r)
+	if err != nil {
+		return err
+	}
+
+	a, err := h.AnalyticsService.GetLotAnalytics(r.Context(), lotID, userID, days)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, a, http.StatusOK)
+}
+
+// RecordReveal is called by api_service after contact of the lot owner is revealed to the requester.
+func (h *AnalyticsHandler) RecordReveal(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("RECORD REVEAL")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.AnalyticsService.RecordReveal(r.Context(), lotID, userID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *AnalyticsHandler) GetDashboard(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET ANALYTICS DASHBOARD")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	days, err := daysFromQuery(r)
+	if err != nil {
+		return err
+	}
+
+	summaries, err := h.AnalyticsService.GetDashboard(r.Context(), userID, days)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, summaries, http.StatusOK)
+}
+
+// daysFromQuery returns number of days from the query, zero if it's omitted.
+func daysFromQuery(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("days")
+	if v == "" {
+		return 0, nil
+	}
+	days, err := strconv.Atoi(v)
+	if err != nil || days <= 0 {
+		return 0, apperror.BadRequestError("days must be a positive integer", "")
+	}
+	return days, nil
+}
+
+// viewer returns user or session of anonymous visitor passed by api_service. Both are empty for requests
+// of other services.
+func viewer(r *http.Request) (uint, string) {
+	userID, _ := strconv.ParseUint(r.Header.Get(requesterIDHeader), 10, 32)
+	return uint(userID), r.Header.Get(sessionIDHeader)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	bookingService "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"strconv"
+)
+
+const (
+	bookingsURL      = "/api/bookings"
+	singleBookingURL = "/api/bookings/:id"
+
+	// requesterIDHeader is set by api_service to ID of the user from JWT
+	requesterIDHeader = "X-Requester-ID"
+)
+
+type BookingHandler struct {
+	Logger         logging.Logger
+	BookingService bookingService.Service
+}
+
+func (h *BookingHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPost, bookingsURL, apperror.Middleware(h.CreateBooking))
+	router.HandlerFunc(http.MethodGet, bookingsURL, apperror.Middleware(h.GetBookings))
+	router.HandlerFunc(http.MethodGet, singleBookingURL, apperror.Middleware(h.GetBooking))
+	router.HandlerFunc(http.MethodPatch, singleBookingURL, apperror.Middleware(h.UpdateBooking))
+}
+
+func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE BOOKING")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create booking dto..")
+	dto := &booking.CreateBookingDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.RenterID = userID
+
+	b, err := h.BookingService.Create(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeBooking(w, b, http.StatusCreated)
+}
+
+func (h *BookingHandler) GetBookings(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET BOOKINGS")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	party := booking.Party(r.URL.Query().Get("party"))
+	if party == "" {
+		party = booking.PartyRenter
+	}
+
+	bookings, err := h.BookingService.GetByUser(r.Context(), userID, party)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("marshalling bookings..")
+	bookingsBytes, err := json.Marshal(bookings)
+	if err != nil {
+		return fmt.Errorf("failed to marshall bookings. error: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(bookingsBytes)
+	return nil
+}
+
+func (h *BookingHandler) GetBooking(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET BOOKING")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	bookingID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	b, err := h.BookingService.GetByID(r.Context(), bookingID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeBooking(w, b, http.StatusOK)
+}
+
+func (h *BookingHandler) UpdateBooking(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("UPDATE BOOKING")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	bookingID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into update booking dto..")
+	dto := &booking.UpdateBookingDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = bookingID
+	dto.UserID = userID
+
+	b, err := h.BookingService.Update(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeBooking(w, b, http.StatusOK)
+}
+
+func writeBooking(w http.ResponseWriter, b *booking.Booking, status int) error {
+	bookingBytes, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to marshall booking. error: %w", err)
+	}
+
+	if status == http.StatusCreated {
+		w.Header().Set("Location", fmt.Sprintf("%s/%d", bookingsURL, b.ID))
+	}
+	w.WriteHeader(status)
+	w.Write(bookingBytes)
+	return nil
+}
+
+func idFromParams(r *http.Request) (uint, error) {
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || id <= 0 {
+		return 0, apperror.BadRequestError("id must be an unsigned integer", "")
+	}
+	return uint(id), nil
+}
+
+// requesterID returns ID of the user making the request. Endpoints using it are available only via api_service.
+func requesterID(r *http.Request) (uint, error) {
+	id, err := strconv.Atoi(r.Header.Get(requesterIDHeader))
+	if err != nil || id <= 0 {
+		return 0, apperror.UnauthorizedError(requesterIDHeader + " header is required")
+	}
+	return uint(id), nil
+}
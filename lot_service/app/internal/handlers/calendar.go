@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar"
+	calendarService "github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"strconv"
+)
+
+const (
+	calendarURL       = "/api/lots/lot/:id/calendar"
+	calendarExportURL = "/api/lots/lot/:id/calendar.ics"
+	calendarBlocksURL = "/api/lots/lot/:id/calendar/blocks"
+	calendarBlockURL  = "/api/lots/lot/:id/calendar/blocks/:block_id"
+	calendarImportURL = "/api/lots/lot/:id/calendar/import"
+)
+
+type CalendarHandler struct {
+	Logger          logging.Logger
+	CalendarService calendarService.Service
+}
+
+func (h *CalendarHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, calendarURL, apperror.Middleware(h.GetCalendar))
+	router.HandlerFunc(http.MethodGet, calendarExportURL, apperror.Middleware(h.ExportCalendar))
+	router.HandlerFunc(http.MethodPost, calendarBlocksURL, apperror.Middleware(h.CreateBlock))
+	router.HandlerFunc(http.MethodDelete, calendarBlockURL, apperror.Middleware(h.DeleteBlock))
+	router.HandlerFunc(http.MethodGet, calendarImportURL, apperror.Middleware(h.GetImport))
+	router.HandlerFunc(http.MethodPut, calendarImportURL, apperror.Middleware(h.SetImport))
+}
+
+func (h *CalendarHandler) GetCalendar(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET CALENDAR")
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	periods, err := h.CalendarService.GetPeriods(r.Context(), lotID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, periods, http.StatusOK)
+}
+
+func (h *CalendarHandler) ExportCalendar(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("EXPORT CALENDAR")
+
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	// calendar is buffered, so errors can still be written as JSON
+	var buf bytes.Buffer
+	if err = h.CalendarService.Export(r.Context(), lotID, &buf); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"lot-%d.ics\"", lotID))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+	return nil
+}
+
+func (h *CalendarHandler) CreateBlock(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE CALENDAR BLOCK")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create block dto..")
+	dto := &calendar.CreateBlockDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.LotID = lotID
+	dto.UserID = userID
+
+	b, err := h.CalendarService.CreateBlock(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/lots/lot/%d/calendar/blocks/%d", lotID, b.ID))
+	return writeJSON(w, b, http.StatusCreated)
+}
+
+func (h *CalendarHandler) DeleteBlock(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("DELETE CALENDAR BLOCK")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	blockID, err := strconv.Atoi(params.ByName("block_id"))
+	if err != nil || blockID <= 0 {
+		return apperror.BadRequestError("block_id must be an unsigned integer", "")
+	}
+
+	if err = h.CalendarService.DeleteBlock(r.Context(), lotID, uint(blockID), userID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *CalendarHandler) GetImport(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET CALENDAR IMPORT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	imp, err := h.CalendarService.GetImport(r.Context(), lotID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, imp, http.StatusOK)
+}
+
+func (h *CalendarHandler) SetImport(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("SET CALENDAR IMPORT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into set import dto..")
+	dto := &calendar.SetImportDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.LotID = lotID
+	dto.UserID = userID
+
+	res, err := h.CalendarService.SetImport(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, res, http.StatusOK)
+}
+
+func writeJSON(w http.ResponseWriter, v any, status int) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshall response. error: %w", err)
+	}
+
+	w.WriteHeader(status)
+	w.Write(body)
+	return nil
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate"
+	duplicateService "github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	duplicatesURL         = "/api/admin/duplicates"
+	moderatedDuplicateURL = "/api/admin/duplicates/:id"
+	lotPhotosURL          = "/api/lots/lot/:id/photos"
+
+	// maxPhotosSize limits size of body with photos of the lot encoded in base64
+	maxPhotosSize = 64 << 20
+)
+
+// DuplicateHandler serves probable duplicates of lots. Its admin endpoints must be exposed by api_service
+// to moderators only.
+type DuplicateHandler struct {
+	Logger           logging.Logger
+	DuplicateService duplicateService.Service
+}
+
+func (h *DuplicateHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, duplicatesURL, apperror.Middleware(h.GetDuplicates))
+	router.HandlerFunc(http.MethodPut, moderatedDuplicateURL, apperror.Middleware(h.Moderate))
+	router.HandlerFunc(http.MethodPut, lotPhotosURL, apperror.Middleware(h.SetPhotos))
+}
+
+// SetPhotos replaces photos of the lot compared with photos of other lots at the same address.
+func (h *DuplicateHandler) SetPhotos(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("SET LOT PHOTOS")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into set photos dto..")
+	dto := &duplicate.SetPhotosDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPhotosSize)).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.LotID, dto.UserID = lotID, userID
+
+	if err = h.DuplicateService.SetPhotos(r.Context(), dto); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetDuplicates returns duplicates filtered by status and lot_id from the query, newest first.
+func (h *DuplicateHandler) GetDuplicates(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET DUPLICATES")
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := uintFromQuery(r, "lot_id")
+	if err != nil {
+		return err
+	}
+	filter := storage.Filter{Status: r.URL.Query().Get("status"), LotID: lotID}
+
+	duplicates, err := h.DuplicateService.GetAll(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, duplicates, http.StatusOK)
+}
+
+func (h *DuplicateHandler) Moderate(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("MODERATE DUPLICATE")
+	w.Header().Set("Content-Type", "application/json")
+
+	duplicateID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into moderate dto..")
+	dto := &duplicate.ModerateDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = duplicateID
+
+	d, err := h.DuplicateService.Moderate(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, d, http.StatusOK)
+}
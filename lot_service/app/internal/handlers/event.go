@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"strconv"
+)
+
+// Events are read by api_service, which delivers them to clients, these endpoints are not public.
+const (
+	eventsURL      = "/api/events"
+	lastEventIDURL = "/api/events/last"
+)
+
+type EventHandler struct {
+	Logger       logging.Logger
+	EventService eventService.Service
+}
+
+func (h *EventHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, eventsURL, apperror.Middleware(h.GetEvents))
+	router.HandlerFunc(http.MethodGet, lastEventIDURL, apperror.Middleware(h.GetLastEventID))
+}
+
+// GetEvents returns events after the given one, of all users or of user_id only.
+func (h *EventHandler) GetEvents(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Trace("GET EVENTS")
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	var params [3]uint64
+	for i, name := range []string{"after", "user_id", "limit"} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return apperror.BadRequestError(name+" must be an unsigned integer", "")
+		}
+		params[i] = n
+	}
+
+	events, err := h.EventService.GetAfter(r.Context(), uint(params[0]), uint(params[1]), params[2])
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, events, http.StatusOK)
+}
+
+func (h *EventHandler) GetLastEventID(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Trace("GET LAST EVENT ID")
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := h.EventService.GetLastID(r.Context())
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, map[string]uint{"id": id}, http.StatusOK)
+}
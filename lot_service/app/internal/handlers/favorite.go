@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	favoriteService "github.com/levelord1311/backendForSharedProject/lot_service/internal/favorite/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	favoritesURL      = "/api/favorites"
+	singleFavoriteURL = "/api/favorites/:id"
+)
+
+// FavoriteHandler manages lots saved by the requester, favorites are identified by IDs of their lots.
+type FavoriteHandler struct {
+	Logger          logging.Logger
+	FavoriteService favoriteService.Service
+}
+
+func (h *FavoriteHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, favoritesURL, apperror.Middleware(h.GetFavorites))
+	router.HandlerFunc(http.MethodPut, singleFavoriteURL, apperror.Middleware(h.AddFavorite))
+	router.HandlerFunc(http.MethodDelete, singleFavoriteURL, apperror.Middleware(h.RemoveFavorite))
+}
+
+func (h *FavoriteHandler) GetFavorites(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET FAVORITES")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	favorites, err := h.FavoriteService.GetAll(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, favorites, http.StatusOK)
+}
+
+func (h *FavoriteHandler) AddFavorite(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("ADD FAVORITE")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	f, err := h.FavoriteService.Add(r.Context(), userID, lotID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, f, http.StatusOK)
+}
+
+func (h *FavoriteHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("REMOVE FAVORITE")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.FavoriteService.Remove(r.Context(), userID, lotID); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/feed"
+	feedService "github.com/levelord1311/backendForSharedProject/lot_service/internal/feed/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	feedsURL         = "/api/admin/feeds"
+	managedFeedURL   = "/api/admin/feeds/:id"
+	feedDocumentURL  = "/api/feeds/:id"
+	cityFeedURL      = "/api/city-feeds/:city"
+	feedsQueryFormat = "format"
+)
+
+// FeedHandler serves feeds of lots for aggregator portals. Admin endpoints must be exposed by api_service
+// to admins only.
+type FeedHandler struct {
+	Logger      logging.Logger
+	FeedService feedService.Service
+}
+
+func (h *FeedHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, feedsURL, apperror.Middleware(h.GetFeeds))
+	router.HandlerFunc(http.MethodPost, feedsURL, apperror.Middleware(h.CreateFeed))
+	router.HandlerFunc(http.MethodPut, managedFeedURL, apperror.Middleware(h.UpdateFeed))
+	router.HandlerFunc(http.MethodDelete, managedFeedURL, apperror.Middleware(h.DeleteFeed))
+	router.HandlerFunc(http.MethodGet, feedDocumentURL, apperror.Middleware(h.GetDocument))
+	router.HandlerFunc(http.MethodGet, cityFeedURL, apperror.Middleware(h.GetCityDocument))
+}
+
+func (h *FeedHandler) GetFeeds(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET FEEDS")
+	w.Header().Set("Content-Type", "application/json")
+
+	feeds, err := h.FeedService.GetAll(r.Context())
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, feeds, http.StatusOK)
+}
+
+func (h *FeedHandler) CreateFeed(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE FEED")
+	w.Header().Set("Content-Type", "application/json")
+
+	h.Logger.Debug("decoding r.body into create feed dto..")
+	dto := &feed.CreateFeedDTO{}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+
+	f, err := h.FeedService.Create(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/feeds/%d", f.ID))
+	return writeJSON(w, f, http.StatusCreated)
+}
+
+func (h *FeedHandler) UpdateFeed(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("UPDATE FEED")
+	w.Header().Set("Content-Type", "application/json")
+
+	feedID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into update feed dto..")
+	dto := &feed.UpdateFeedDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = feedID
+
+	f, err := h.FeedService.Update(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, f, http.StatusOK)
+}
+
+func (h *FeedHandler) DeleteFeed(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("DELETE FEED")
+	w.Header().Set("Content-Type", "application/json")
+
+	feedID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.FeedService.Delete(r.Context(), feedID); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func (h *FeedHandler) GetDocument(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET FEED DOCUMENT")
+
+	feedID, err := idFromParams(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	doc, err := h.FeedService.GetDocument(r.Context(), feedID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	serveFeed(w, r, doc)
+	return nil
+}
+
+// GetCityDocument serves feed of the newest lots of the city in format from the query, rss by default.
+func (h *FeedHandler) GetCityDocument(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET CITY FEED DOCUMENT")
+
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	format := r.URL.Query().Get(feedsQueryFormat)
+	if format == "" {
+		format = feed.FormatRSS
+	}
+
+	doc, err := h.FeedService.GetCityDocument(r.Context(), params.ByName("city"), format)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	serveFeed(w, r, doc)
+	return nil
+}
+
+// serveFeed writes the document with ETag and Last-Modified. Conditional requests of unchanged feed
+// are answered with 304 Not Modified.
+func serveFeed(w http.ResponseWriter, r *http.Request, doc *feed.Document) {
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("ETag", doc.ETag)
+	http.ServeContent(w, r, "", doc.ModifiedAt, bytes.NewReader(doc.Data))
+}
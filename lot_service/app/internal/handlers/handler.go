@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
+	analyticsService "github.com/levelord1311/backendForSharedProject/lot_service/internal/analytics/service"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
@@ -11,17 +13,24 @@ import (
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 const (
 	lotsURL      = "/api/lots"
 	lotsOfUser   = "/api/lots/user/:id"
 	singleLotURL = "/api/lots/lot/:id"
+	lotAgentURL  = "/api/lots/lot/:id/agent"
+	lotStatsURL  = "/api/lots/stats"
+	// statsMaxAge is how long clients and proxies may use stats without revalidation, in seconds
+	statsMaxAge = 60
 )
 
 type Handler struct {
 	Logger     logging.Logger
 	LotService service.Service
+	// Views counts views of lots asynchronously
+	Views analyticsService.ViewRecorder
 }
 
 func (h *Handler) Register(router *httprouter.Router) {
@@ -29,7 +38,9 @@ func (h *Handler) Register(router *httprouter.Router) {
 	router.HandlerFunc(http.MethodGet, singleLotURL, apperror.Middleware(h.GetLot))
 	router.HandlerFunc(http.MethodGet, lotsURL, sort.Middleware(apperror.Middleware(h.GetLots)))
 	router.HandlerFunc(http.MethodGet, lotsOfUser, apperror.Middleware(h.GetLotsByUser))
+	router.HandlerFunc(http.MethodGet, lotStatsURL, apperror.Middleware(h.GetStats))
 	router.HandlerFunc(http.MethodPatch, singleLotURL, apperror.Middleware(h.UpdateLotPrice))
+	router.HandlerFunc(http.MethodPut, lotAgentURL, apperror.Middleware(h.TransferLot))
 	//	router.HandlerFunc(http.MethodDelete, singleLotURL, apperror.Middleware(h.DeleteLot))
 }
 
@@ -66,6 +77,8 @@ func (h *Handler) GetLot(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return err
 	}
+	userID, sessionID := viewer(r)
+	h.Views.RecordView(l, userID, sessionID)
 
 	h.Logger.Debug("marshalling lot..")
 	lotBytes, err := json.Marshal(l)
@@ -125,6 +138,29 @@ func (h *Handler) GetLots(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// GetStats serves aggregates over lots selected with filter of lot search. Stats have ETag, so conditional
+// requests of unchanged stats are answered with 304 Not Modified.
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET LOT STATS")
+	w.Header().Set("Content-Type", "application/json")
+
+	st, err := h.LotService.GetStats(r.Context(), r.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("marshalling stats..")
+	statsBytes, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to marshall stats. error: %w", err)
+	}
+
+	w.Header().Set("ETag", st.ETag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", statsMaxAge))
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(statsBytes))
+	return nil
+}
+
 func (h *Handler) UpdateLotPrice(w http.ResponseWriter, r *http.Request) error {
 	h.Logger.Info("UPDATE LOT PRICE")
 	w.Header().Set("Content-Type", "application/json")
@@ -156,6 +192,37 @@ func (h *Handler) UpdateLotPrice(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// TransferLot assigns lot of organization to another agent of the organization.
+func (h *Handler) TransferLot(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("TRANSFER LOT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into transfer lot dto..")
+	dto := &lot.TransferLotDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = lotID
+	dto.UserID = userID
+
+	l, err := h.LotService.Transfer(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, l, http.StatusOK)
+}
+
 // TODO исправить delete - вместо полноценного удаления из БД вешать признак "пометка на удаление"
 
 //func (h *Handler) DeleteLot(w http.ResponseWriter, r *http.Request) error {
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"strconv"
+)
+
+const (
+	lotHistoryURL      = "/api/lots/lot/:id/history"
+	lotRevertURL       = "/api/lots/lot/:id/history/:number/revert"
+	adminLotHistoryURL = "/api/admin/lots/:id/history"
+	adminLotRevertURL  = "/api/admin/lots/:id/history/:number/revert"
+)
+
+// HistoryHandler serves versions of lots. Admin endpoints must be exposed by api_service to moderators only.
+type HistoryHandler struct {
+	Logger     logging.Logger
+	LotService service.Service
+}
+
+func (h *HistoryHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, lotHistoryURL, apperror.Middleware(h.GetHistory))
+	router.HandlerFunc(http.MethodPost, lotRevertURL, apperror.Middleware(h.Revert))
+	router.HandlerFunc(http.MethodGet, adminLotHistoryURL, apperror.Middleware(h.GetModeratedHistory))
+	router.HandlerFunc(http.MethodPost, adminLotRevertURL, apperror.Middleware(h.ModeratedRevert))
+}
+
+func (h *HistoryHandler) GetHistory(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET LOT HISTORY")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	return h.getHistory(w, r, &history.GetDTO{UserID: userID})
+}
+
+func (h *HistoryHandler) GetModeratedHistory(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET MODERATED LOT HISTORY")
+	w.Header().Set("Content-Type", "application/json")
+
+	return h.getHistory(w, r, &history.GetDTO{Moderator: true})
+}
+
+func (h *HistoryHandler) getHistory(w http.ResponseWriter, r *http.Request, dto *history.GetDTO) error {
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	dto.LotID = lotID
+
+	versions, err := h.LotService.GetHistory(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, versions, http.StatusOK)
+}
+
+func (h *HistoryHandler) Revert(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("REVERT LOT")
+	w.Header().Set("Content-Type", "application/json")
+
+	return h.revert(w, r, false)
+}
+
+// ModeratedRevert reverts any lot, the moderator is taken from requester header.
+func (h *HistoryHandler) ModeratedRevert(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("MODERATED REVERT LOT")
+	w.Header().Set("Content-Type", "application/json")
+
+	return h.revert(w, r, true)
+}
+
+func (h *HistoryHandler) revert(w http.ResponseWriter, r *http.Request, moderator bool) error {
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	number, err := strconv.Atoi(params.ByName("number"))
+	if err != nil || number <= 0 {
+		return apperror.BadRequestError("number must be an unsigned integer", "")
+	}
+
+	l, err := h.LotService.Revert(r.Context(), &history.RevertDTO{
+		LotID:     lotID,
+		Number:    uint(number),
+		UserID:    userID,
+		Moderator: moderator,
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, l, http.StatusOK)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport"
+	importService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"strconv"
+)
+
+const (
+	lotImportsURL             = "/api/lot-imports"
+	singleLotImportURL        = "/api/lot-imports/:id"
+	lotImportProfilesURL      = "/api/lot-import-profiles"
+	singleLotImportProfileURL = "/api/lot-import-profiles/:id"
+)
+
+type ImportHandler struct {
+	Logger        logging.Logger
+	ImportService importService.Service
+}
+
+func (h *ImportHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, lotImportsURL, apperror.Middleware(h.GetJobs))
+	router.HandlerFunc(http.MethodPost, lotImportsURL, apperror.Middleware(h.CreateJob))
+	router.HandlerFunc(http.MethodGet, singleLotImportURL, apperror.Middleware(h.GetJob))
+	router.HandlerFunc(http.MethodGet, lotImportProfilesURL, apperror.Middleware(h.GetProfiles))
+	router.HandlerFunc(http.MethodPost, lotImportProfilesURL, apperror.Middleware(h.CreateProfile))
+	router.HandlerFunc(http.MethodDelete, singleLotImportProfileURL, apperror.Middleware(h.DeleteProfile))
+}
+
+func (h *ImportHandler) GetJobs(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET LOT IMPORTS")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	jobs, err := h.ImportService.GetJobs(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, jobs, http.StatusOK)
+}
+
+// CreateJob queues import of spreadsheet in raw body of the request. Options are passed in query:
+// format, dry_run, profile_id, organization_id and mapping as JSON object.
+func (h *ImportHandler) CreateJob(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE LOT IMPORT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	dto := &lotimport.CreateJobDTO{
+		UserID: userID,
+		Format: query.Get("format"),
+	}
+	if v := query.Get("dry_run"); v != "" {
+		if dto.DryRun, err = strconv.ParseBool(v); err != nil {
+			return apperror.BadRequestError("dry_run must be a boolean", "")
+		}
+	}
+	if dto.ProfileID, err = uintFromQuery(r, "profile_id"); err != nil {
+		return err
+	}
+	if dto.OrganizationID, err = uintFromQuery(r, "organization_id"); err != nil {
+		return err
+	}
+	if v := query.Get("mapping"); v != "" {
+		if err = json.Unmarshal([]byte(v), &dto.Mapping); err != nil {
+			return apperror.BadRequestError("mapping must be a JSON object of columns by fields", "")
+		}
+	}
+	defer r.Body.Close()
+
+	j, err := h.ImportService.CreateJob(r.Context(), dto, r.Body)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", lotImportsURL, j.ID))
+	return writeJSON(w, j, http.StatusAccepted)
+}
+
+func (h *ImportHandler) GetJob(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET LOT IMPORT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	jobID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	j, err := h.ImportService.GetJob(r.Context(), jobID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, j, http.StatusOK)
+}
+
+func (h *ImportHandler) GetProfiles(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET LOT IMPORT PROFILES")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := h.ImportService.GetProfiles(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, profiles, http.StatusOK)
+}
+
+func (h *ImportHandler) CreateProfile(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE LOT IMPORT PROFILE")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create profile dto..")
+	dto := &lotimport.CreateProfileDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.OwnerID = userID
+
+	p, err := h.ImportService.CreateProfile(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", lotImportProfilesURL, p.ID))
+	return writeJSON(w, p, http.StatusCreated)
+}
+
+func (h *ImportHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("DELETE LOT IMPORT PROFILE")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	profileID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.ImportService.DeleteProfile(r.Context(), profileID, userID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
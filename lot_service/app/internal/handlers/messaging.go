@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging"
+	messagingService "github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+const (
+	conversationsURL        = "/api/conversations"
+	singleConversationURL   = "/api/conversations/:id"
+	conversationMessagesURL = "/api/conversations/:id/messages"
+	conversationReadURL     = "/api/conversations/:id/read"
+	unreadMessagesURL       = "/api/messages/unread"
+	attachmentsURL          = "/api/messages/attachments"
+	singleAttachmentURL     = "/api/messages/attachments/:id"
+	blocksURL               = "/api/messages/blocks"
+	singleBlockURL          = "/api/messages/blocks/:id"
+)
+
+type MessagingHandler struct {
+	Logger           logging.Logger
+	MessagingService messagingService.Service
+}
+
+func (h *MessagingHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, conversationsURL, apperror.Middleware(h.GetConversations))
+	router.HandlerFunc(http.MethodPost, conversationsURL, apperror.Middleware(h.StartConversation))
+	router.HandlerFunc(http.MethodGet, singleConversationURL, apperror.Middleware(h.GetConversation))
+	router.HandlerFunc(http.MethodGet, conversationMessagesURL, apperror.Middleware(h.GetMessages))
+	router.HandlerFunc(http.MethodPost, conversationMessagesURL, apperror.Middleware(h.SendMessage))
+	router.HandlerFunc(http.MethodPut, conversationReadURL, apperror.Middleware(h.MarkRead))
+	router.HandlerFunc(http.MethodGet, unreadMessagesURL, apperror.Middleware(h.GetUnread))
+	router.HandlerFunc(http.MethodPost, attachmentsURL, apperror.Middleware(h.UploadAttachment))
+	router.HandlerFunc(http.MethodGet, singleAttachmentURL, apperror.Middleware(h.GetAttachment))
+	router.HandlerFunc(http.MethodGet, blocksURL, apperror.Middleware(h.GetBlocks))
+	router.HandlerFunc(http.MethodPut, singleBlockURL, apperror.Middleware(h.Block))
+	router.HandlerFunc(http.MethodDelete, singleBlockURL, apperror.Middleware(h.Unblock))
+}
+
+func (h *MessagingHandler) GetConversations(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET CONVERSATIONS")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	conversations, err := h.MessagingService.GetConversations(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, conversations, http.StatusOK)
+}
+
+func (h *MessagingHandler) StartConversation(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("START CONVERSATION")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into start conversation dto..")
+	dto := &messaging.StartConversationDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.UserID = userID
+
+	m, err := h.MessagingService.StartConversation(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", conversationsURL, m.ConversationID))
+	return writeJSON(w, m, http.StatusCreated)
+}
+
+func (h *MessagingHandler) GetConversation(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET CONVERSATION")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	conversationID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	c, err := h.MessagingService.GetConversation(r.Context(), conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, c, http.StatusOK)
+}
+
+func (h *MessagingHandler) GetMessages(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET MESSAGES")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	conversationID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	var beforeID, limit uint64
+	if v := r.URL.Query().Get("before"); v != "" {
+		if beforeID, err = strconv.ParseUint(v, 10, 32); err != nil {
+			return apperror.BadRequestError("before must be an unsigned integer", "")
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.ParseUint(v, 10, 32); err != nil {
+			return apperror.BadRequestError("limit must be an unsigned integer", "")
+		}
+	}
+
+	messages, err := h.MessagingService.GetMessages(r.Context(), conversationID, userID, uint(beforeID), limit)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, messages, http.StatusOK)
+}
+
+func (h *MessagingHandler) SendMessage(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("SEND MESSAGE")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	conversationID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into send message dto..")
+	dto := &messaging.SendMessageDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ConversationID = conversationID
+	dto.SenderID = userID
+
+	m, err := h.MessagingService.SendMessage(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, m, http.StatusCreated)
+}
+
+func (h *MessagingHandler) MarkRead(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("MARK MESSAGES READ")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	conversationID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	read, err := h.MessagingService.MarkRead(r.Context(), conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, map[string]int64{"read": read}, http.StatusOK)
+}
+
+func (h *MessagingHandler) GetUnread(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET UNREAD MESSAGES")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	unread, err := h.MessagingService.GetUnread(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, unread, http.StatusOK)
+}
+
+// UploadAttachment saves raw body of the request. Name of the file is passed in query.
+func (h *MessagingHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("UPLOAD ATTACHMENT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	dto := &messaging.UploadAttachmentDTO{
+		UploaderID:  userID,
+		Name:        r.URL.Query().Get("name"),
+		ContentType: r.Header.Get("Content-Type"),
+	}
+	defer r.Body.Close()
+
+	a, err := h.MessagingService.UploadAttachment(r.Context(), dto, r.Body)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", attachmentsURL, a.ID))
+	return writeJSON(w, a, http.StatusCreated)
+}
+
+func (h *MessagingHandler) GetAttachment(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET ATTACHMENT")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	attachmentID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	a, data, err := h.MessagingService.OpenAttachment(r.Context(), attachmentID, userID)
+	if err != nil {
+		return err
+	}
+	defer data.Close()
+
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+	w.WriteHeader(http.StatusOK)
+	if _, err = io.Copy(w, data); err != nil {
+		h.Logger.Errorf("failed to write attachment %d. error: %v", a.ID, err)
+	}
+	return nil
+}
+
+func (h *MessagingHandler) GetBlocks(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET BLOCKED USERS")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	blocks, err := h.MessagingService.GetBlocks(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, blocks, http.StatusOK)
+}
+
+func (h *MessagingHandler) Block(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("BLOCK USER")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	blockedID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.MessagingService.Block(r.Context(), userID, blockedID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *MessagingHandler) Unblock(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("UNBLOCK USER")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	blockedID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.MessagingService.Unblock(r.Context(), userID, blockedID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+	organizationService "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"strconv"
+)
+
+const (
+	organizationsURL      = "/api/organizations"
+	singleOrganizationURL = "/api/organizations/:id"
+	organizationLotsURL   = "/api/organizations/:id/lots"
+	organizationMemberURL = "/api/organizations/:id/members/:user_id"
+)
+
+type OrganizationHandler struct {
+	Logger              logging.Logger
+	OrganizationService organizationService.Service
+}
+
+func (h *OrganizationHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPost, organizationsURL, apperror.Middleware(h.CreateOrganization))
+	router.HandlerFunc(http.MethodGet, organizationsURL, apperror.Middleware(h.GetOrganizations))
+	router.HandlerFunc(http.MethodGet, singleOrganizationURL, apperror.Middleware(h.GetOrganization))
+	router.HandlerFunc(http.MethodPatch, singleOrganizationURL, apperror.Middleware(h.UpdateOrganization))
+	router.HandlerFunc(http.MethodGet, organizationLotsURL, apperror.Middleware(h.GetLots))
+	router.HandlerFunc(http.MethodPut, organizationMemberURL, apperror.Middleware(h.SetMember))
+	router.HandlerFunc(http.MethodDelete, organizationMemberURL, apperror.Middleware(h.RemoveMember))
+}
+
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE ORGANIZATION")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create organization dto..")
+	dto := &organization.CreateOrganizationDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.OwnerID = userID
+
+	o, err := h.OrganizationService.Create(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", organizationsURL, o.ID))
+	return writeJSON(w, o, http.StatusCreated)
+}
+
+// GetOrganizations returns organizations of the requester.
+func (h *OrganizationHandler) GetOrganizations(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET ORGANIZATIONS")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	organizations, err := h.OrganizationService.GetByMemberID(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, organizations, http.StatusOK)
+}
+
+// GetOrganization returns public page of the organization.
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET ORGANIZATION")
+	w.Header().Set("Content-Type", "application/json")
+
+	organizationID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.OrganizationService.GetPage(r.Context(), organizationID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, page, http.StatusOK)
+}
+
+func (h *OrganizationHandler) UpdateOrganization(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("UPDATE ORGANIZATION")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	organizationID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into update organization dto..")
+	dto := &organization.UpdateOrganizationDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = organizationID
+	dto.UserID = userID
+
+	o, err := h.OrganizationService.Update(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, o, http.StatusOK)
+}
+
+func (h *OrganizationHandler) GetLots(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET ORGANIZATION LOTS")
+	w.Header().Set("Content-Type", "application/json")
+
+	organizationID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	lots, err := h.OrganizationService.GetLots(r.Context(), organizationID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, lots, http.StatusOK)
+}
+
+// SetMember adds the user to the organization or changes role of the member.
+func (h *OrganizationHandler) SetMember(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("SET ORGANIZATION MEMBER")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	organizationID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	memberID, err := memberIDFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into set member dto..")
+	dto := &organization.SetMemberDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.OrganizationID = organizationID
+	dto.UserID = userID
+	dto.MemberID = memberID
+
+	m, err := h.OrganizationService.SetMember(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, m, http.StatusOK)
+}
+
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("REMOVE ORGANIZATION MEMBER")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	organizationID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	memberID, err := memberIDFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.OrganizationService.RemoveMember(r.Context(), organizationID, userID, memberID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func memberIDFromParams(r *http.Request) (uint, error) {
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	id, err := strconv.Atoi(params.ByName("user_id"))
+	if err != nil || id <= 0 {
+		return 0, apperror.BadRequestError("user_id must be an unsigned integer", "")
+	}
+	return uint(id), nil
+}
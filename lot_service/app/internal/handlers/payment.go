@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/payment"
+	paymentService "github.com/levelord1311/backendForSharedProject/lot_service/internal/payment/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+const (
+	bookingPaymentsURL = "/api/bookings/:id/payments"
+	paymentsURL        = "/api/payments"
+	singlePaymentURL   = "/api/payments/:id"
+	paymentRefundsURL  = "/api/payments/:id/refunds"
+	paymentReceiptURL  = "/api/payments/:id/receipt"
+	paymentSimulateURL = "/api/payments/:id/simulation"
+	ledgerURL          = "/api/ledger"
+	// paymentWebhookURL is called by payment providers, it's public and checked by signature of the provider
+	paymentWebhookURL = "/api/payment-webhooks/:provider"
+	// idempotencyKeyHeader makes repeated refund requests return the same refund
+	idempotencyKeyHeader = "Idempotency-Key"
+	maxWebhookSize       = 64 << 10
+)
+
+type PaymentHandler struct {
+	Logger         logging.Logger
+	PaymentService paymentService.Service
+}
+
+func (h *PaymentHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, bookingPaymentsURL, apperror.Middleware(h.GetPayments))
+	router.HandlerFunc(http.MethodPost, bookingPaymentsURL, apperror.Middleware(h.CreatePayment))
+	router.HandlerFunc(http.MethodGet, singlePaymentURL, apperror.Middleware(h.GetPayment))
+	router.HandlerFunc(http.MethodGet, paymentRefundsURL, apperror.Middleware(h.GetRefunds))
+	router.HandlerFunc(http.MethodPost, paymentRefundsURL, apperror.Middleware(h.CreateRefund))
+	router.HandlerFunc(http.MethodGet, paymentReceiptURL, apperror.Middleware(h.GetReceipt))
+	router.HandlerFunc(http.MethodPost, paymentSimulateURL, apperror.Middleware(h.Simulate))
+	router.HandlerFunc(http.MethodGet, ledgerURL, apperror.Middleware(h.GetLedger))
+	router.HandlerFunc(http.MethodPost, paymentWebhookURL, apperror.Middleware(h.HandleWebhook))
+}
+
+func (h *PaymentHandler) GetPayments(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET PAYMENTS OF BOOKING")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	bookingID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	payments, err := h.PaymentService.GetByBookingID(r.Context(), bookingID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, payments, http.StatusOK)
+}
+
+func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE PAYMENT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	bookingID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create payment dto..")
+	dto := &payment.CreatePaymentDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.BookingID = bookingID
+	dto.UserID = userID
+
+	p, err := h.PaymentService.Create(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", paymentsURL, p.ID))
+	return writeJSON(w, p, http.StatusCreated)
+}
+
+func (h *PaymentHandler) GetPayment(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET PAYMENT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	paymentID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	p, err := h.PaymentService.GetByID(r.Context(), paymentID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, p, http.StatusOK)
+}
+
+func (h *PaymentHandler) GetRefunds(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET REFUNDS")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	paymentID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	refunds, err := h.PaymentService.GetRefunds(r.Context(), paymentID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, refunds, http.StatusOK)
+}
+
+// CreateRefund refunds the payment. Requests with the same Idempotency-Key header make a single refund.
+func (h *PaymentHandler) CreateRefund(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE REFUND")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	paymentID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create refund dto..")
+	dto := &payment.CreateRefundDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.PaymentID = paymentID
+	dto.UserID = userID
+	dto.IdempotencyKey = r.Header.Get(idempotencyKeyHeader)
+
+	refund, err := h.PaymentService.Refund(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, refund, http.StatusCreated)
+}
+
+// GetReceipt renders receipt of the payment in format from the query, html by default.
+func (h *PaymentHandler) GetReceipt(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET RECEIPT")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	paymentID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = payment.FormatHTML
+	}
+
+	doc, err := h.PaymentService.GetReceipt(r.Context(), paymentID, userID, format)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		return err
+	}
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", doc.FileName))
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc.Data)
+	return nil
+}
+
+// Simulate finishes checkout of the payment, when the provider has no checkout pages.
+func (h *PaymentHandler) Simulate(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("SIMULATE CHECKOUT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	paymentID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into simulate dto..")
+	dto := &payment.SimulateDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = paymentID
+	dto.UserID = userID
+
+	p, err := h.PaymentService.Simulate(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, p, http.StatusOK)
+}
+
+// GetLedger returns ledger entries of the requester after the one from the query.
+func (h *PaymentHandler) GetLedger(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET LEDGER")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	afterID, err := uintFromQuery(r, "after")
+	if err != nil {
+		return err
+	}
+	limit, err := uintFromQuery(r, "limit")
+	if err != nil {
+		return err
+	}
+
+	entries, err := h.PaymentService.GetLedger(r.Context(), userID, afterID, uint64(limit))
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, entries, http.StatusOK)
+}
+
+// HandleWebhook applies event of the payment provider. Providers retry webhooks until they get success status.
+func (h *PaymentHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("PAYMENT WEBHOOK")
+	w.Header().Set("Content-Type", "application/json")
+
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	defer r.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookSize+1))
+	if err != nil {
+		return apperror.BadRequestError("failed to read webhook", "")
+	}
+	if len(body) > maxWebhookSize {
+		return apperror.BadRequestError("webhook is too large", strconv.Itoa(maxWebhookSize))
+	}
+
+	if err = h.PaymentService.HandleWebhook(r.Context(), params.ByName("provider"), r.Header, body); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	recommendationService "github.com/levelord1311/backendForSharedProject/lot_service/internal/recommendation/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+)
+
+const similarLotsURL = "/api/lots/lot/:id/similar"
+
+type RecommendationHandler struct {
+	Logger                logging.Logger
+	RecommendationService recommendationService.Service
+}
+
+func (h *RecommendationHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, similarLotsURL, apperror.Middleware(h.GetSimilarLots))
+}
+
+func (h *RecommendationHandler) GetSimilarLots(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET SIMILAR LOTS")
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	limit, err := limitFromQuery(r)
+	if err != nil {
+		return err
+	}
+
+	similar, err := h.RecommendationService.GetSimilar(r.Context(), lotID, limit)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, similar, http.StatusOK)
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/reference"
+	referenceService "github.com/levelord1311/backendForSharedProject/lot_service/internal/reference/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"time"
+)
+
+const (
+	referenceURL             = "/api/reference"
+	adminReferenceURL        = "/api/admin/reference"
+	estateTypesURL           = "/api/admin/reference/estate-types"
+	estateTypeURL            = "/api/admin/reference/estate-types/:id"
+	referenceCitiesURL       = "/api/admin/reference/cities"
+	referenceCityURL         = "/api/admin/reference/cities/:id"
+	referenceCityDistrictURL = "/api/admin/reference/cities/:id/districts"
+	referenceDistrictURL     = "/api/admin/reference/districts/:id"
+
+	// referenceMaxAge is how long clients and proxies may use reference without revalidation, in seconds
+	referenceMaxAge = 300
+)
+
+// ReferenceHandler serves reference data for lists of the frontend. Admin endpoints must be exposed
+// by api_service to admins only.
+type ReferenceHandler struct {
+	Logger           logging.Logger
+	ReferenceService referenceService.Service
+}
+
+func (h *ReferenceHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, referenceURL, apperror.Middleware(h.GetReference))
+	router.HandlerFunc(http.MethodGet, adminReferenceURL, apperror.Middleware(h.GetAll))
+	router.HandlerFunc(http.MethodPost, estateTypesURL, apperror.Middleware(h.CreateEstateType))
+	router.HandlerFunc(http.MethodPut, estateTypeURL, apperror.Middleware(h.UpdateEstateType))
+	router.HandlerFunc(http.MethodDelete, estateTypeURL, apperror.Middleware(h.DeleteEstateType))
+	router.HandlerFunc(http.MethodPost, referenceCitiesURL, apperror.Middleware(h.CreateCity))
+	router.HandlerFunc(http.MethodPut, referenceCityURL, apperror.Middleware(h.UpdateCity))
+	router.HandlerFunc(http.MethodDelete, referenceCityURL, apperror.Middleware(h.DeleteCity))
+	router.HandlerFunc(http.MethodPost, referenceCityDistrictURL, apperror.Middleware(h.CreateDistrict))
+	router.HandlerFunc(http.MethodPut, referenceDistrictURL, apperror.Middleware(h.UpdateDistrict))
+	router.HandlerFunc(http.MethodDelete, referenceDistrictURL, apperror.Middleware(h.DeleteDistrict))
+}
+
+// GetReference returns reference data with names in locale from the query. Conditional requests
+// of unchanged reference get 304.
+func (h *ReferenceHandler) GetReference(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET REFERENCE")
+	w.Header().Set("Content-Type", "application/json")
+
+	ref, err := h.ReferenceService.Get(r.Context(), r.URL.Query().Get("locale"))
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("marshalling reference..")
+	refBytes, err := json.Marshal(ref)
+	if err != nil {
+		return fmt.Errorf("failed to marshall reference. error: %w", err)
+	}
+
+	w.Header().Set("ETag", ref.ETag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", referenceMaxAge))
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(refBytes))
+	return nil
+}
+
+func (h *ReferenceHandler) GetAll(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET ALL REFERENCE")
+	w.Header().Set("Content-Type", "application/json")
+
+	ref, err := h.ReferenceService.GetAll(r.Context())
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, ref, http.StatusOK)
+}
+
+func (h *ReferenceHandler) CreateEstateType(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE ESTATE TYPE")
+	w.Header().Set("Content-Type", "application/json")
+
+	h.Logger.Debug("decoding r.body into create estate type dto..")
+	dto := &reference.CreateEstateTypeDTO{}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+
+	t, err := h.ReferenceService.CreateEstateType(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, t, http.StatusCreated)
+}
+
+func (h *ReferenceHandler) UpdateEstateType(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("UPDATE ESTATE TYPE")
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into update estate type dto..")
+	dto := &reference.UpdateEstateTypeDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = id
+
+	t, err := h.ReferenceService.UpdateEstateType(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, t, http.StatusOK)
+}
+
+func (h *ReferenceHandler) DeleteEstateType(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("DELETE ESTATE TYPE")
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.ReferenceService.DeleteEstateType(r.Context(), id); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func (h *ReferenceHandler) CreateCity(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE CITY")
+	w.Header().Set("Content-Type", "application/json")
+
+	h.Logger.Debug("decoding r.body into create city dto..")
+	dto := &reference.CreateCityDTO{}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+
+	c, err := h.ReferenceService.CreateCity(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, c, http.StatusCreated)
+}
+
+func (h *ReferenceHandler) UpdateCity(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("UPDATE CITY")
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into update city dto..")
+	dto := &reference.UpdateCityDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = id
+
+	c, err := h.ReferenceService.UpdateCity(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, c, http.StatusOK)
+}
+
+func (h *ReferenceHandler) DeleteCity(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("DELETE CITY")
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.ReferenceService.DeleteCity(r.Context(), id); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func (h *ReferenceHandler) CreateDistrict(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE DISTRICT")
+	w.Header().Set("Content-Type", "application/json")
+
+	cityID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create district dto..")
+	dto := &reference.CreateDistrictDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.CityID = cityID
+
+	d, err := h.ReferenceService.CreateDistrict(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, d, http.StatusCreated)
+}
+
+func (h *ReferenceHandler) UpdateDistrict(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("UPDATE DISTRICT")
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into update district dto..")
+	dto := &reference.UpdateDistrictDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = id
+
+	d, err := h.ReferenceService.UpdateDistrict(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, d, http.StatusOK)
+}
+
+func (h *ReferenceHandler) DeleteDistrict(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("DELETE DISTRICT")
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.ReferenceService.DeleteDistrict(r.Context(), id); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
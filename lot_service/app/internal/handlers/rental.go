@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/rental"
+	rentalService "github.com/levelord1311/backendForSharedProject/lot_service/internal/rental/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+)
+
+const (
+	rentalURL = "/api/lots/lot/:id/rental"
+	quoteURL  = "/api/lots/lot/:id/quote"
+)
+
+type RentalHandler struct {
+	Logger        logging.Logger
+	RentalService rentalService.Service
+}
+
+func (h *RentalHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, rentalURL, apperror.Middleware(h.GetRental))
+	router.HandlerFunc(http.MethodPut, rentalURL, apperror.Middleware(h.SetRental))
+	router.HandlerFunc(http.MethodGet, quoteURL, apperror.Middleware(h.Quote))
+}
+
+func (h *RentalHandler) GetRental(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET RENTAL")
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	rent, err := h.RentalService.Get(r.Context(), lotID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, rent, http.StatusOK)
+}
+
+func (h *RentalHandler) SetRental(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("SET RENTAL")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into set rental dto..")
+	dto := &rental.SetRentalDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.LotID = lotID
+	dto.UserID = userID
+
+	rent, err := h.RentalService.Set(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, rent, http.StatusOK)
+}
+
+// Quote returns price of stay from query, e.g. ?mode=short_term&check_in=2026-11-01&check_out=2026-11-07.
+func (h *RentalHandler) Quote(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("QUOTE STAY")
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	stay, err := h.RentalService.Quote(r.Context(), &rental.QuoteDTO{
+		LotID:    lotID,
+		Mode:     query.Get("mode"),
+		CheckIn:  query.Get("check_in"),
+		CheckOut: query.Get("check_out"),
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, stay, http.StatusOK)
+}
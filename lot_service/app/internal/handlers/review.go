@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/review"
+	reviewService "github.com/levelord1311/backendForSharedProject/lot_service/internal/review/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/http"
+	"strconv"
+)
+
+const (
+	reviewsURL             = "/api/reviews"
+	singleReviewURL        = "/api/reviews/:id"
+	reviewReplyURL         = "/api/reviews/:id/reply"
+	reviewReportsURL       = "/api/reviews/:id/reports"
+	landlordRatingURL      = "/api/ratings/landlords/:id"
+	reportedReviewsURL     = "/api/admin/reviews"
+	moderatedReviewURL     = "/api/admin/reviews/:id"
+	reviewsQueryLotID      = "lot_id"
+	reviewsQueryLandlordID = "landlord_id"
+)
+
+// ReviewHandler serves reviews. Admin endpoints must be exposed by api_service to moderators only.
+type ReviewHandler struct {
+	Logger        logging.Logger
+	ReviewService reviewService.Service
+}
+
+func (h *ReviewHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPost, reviewsURL, apperror.Middleware(h.CreateReview))
+	router.HandlerFunc(http.MethodGet, reviewsURL, apperror.Middleware(h.GetReviews))
+	router.HandlerFunc(http.MethodGet, singleReviewURL, apperror.Middleware(h.GetReview))
+	router.HandlerFunc(http.MethodPut, reviewReplyURL, apperror.Middleware(h.Reply))
+	router.HandlerFunc(http.MethodPost, reviewReportsURL, apperror.Middleware(h.Report))
+	router.HandlerFunc(http.MethodGet, landlordRatingURL, apperror.Middleware(h.GetLandlordRating))
+	router.HandlerFunc(http.MethodGet, reportedReviewsURL, apperror.Middleware(h.GetReported))
+	router.HandlerFunc(http.MethodPut, moderatedReviewURL, apperror.Middleware(h.Moderate))
+}
+
+func (h *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE REVIEW")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create review dto..")
+	dto := &review.CreateReviewDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.AuthorID = userID
+
+	rv, err := h.ReviewService.Create(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", reviewsURL, rv.ID))
+	return writeJSON(w, rv, http.StatusCreated)
+}
+
+// GetReviews returns reviews of the lot or of the landlord, one of query parameters is required.
+func (h *ReviewHandler) GetReviews(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET REVIEWS")
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := uintFromQuery(r, reviewsQueryLotID)
+	if err != nil {
+		return err
+	}
+	landlordID, err := uintFromQuery(r, reviewsQueryLandlordID)
+	if err != nil {
+		return err
+	}
+
+	var reviews []*review.Review
+	switch {
+	case lotID != 0 && landlordID == 0:
+		reviews, err = h.ReviewService.GetByLot(r.Context(), lotID)
+	case landlordID != 0 && lotID == 0:
+		reviews, err = h.ReviewService.GetByLandlord(r.Context(), landlordID)
+	default:
+		return apperror.BadRequestError("either lot_id or landlord_id is required", "")
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, reviews, http.StatusOK)
+}
+
+func (h *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET REVIEW")
+	w.Header().Set("Content-Type", "application/json")
+
+	reviewID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	rv, err := h.ReviewService.GetByID(r.Context(), reviewID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, rv, http.StatusOK)
+}
+
+func (h *ReviewHandler) Reply(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("REPLY TO REVIEW")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	reviewID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into reply dto..")
+	dto := &review.ReplyDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ReviewID = reviewID
+	dto.LandlordID = userID
+
+	rv, err := h.ReviewService.Reply(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, rv, http.StatusOK)
+}
+
+func (h *ReviewHandler) Report(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("REPORT REVIEW")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	reviewID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into report dto..")
+	dto := &review.ReportDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ReviewID = reviewID
+	dto.UserID = userID
+
+	if err = h.ReviewService.Report(r.Context(), dto); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *ReviewHandler) GetLandlordRating(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET LANDLORD RATING")
+	w.Header().Set("Content-Type", "application/json")
+
+	landlordID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	rating, err := h.ReviewService.GetLandlordRating(r.Context(), landlordID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, rating, http.StatusOK)
+}
+
+func (h *ReviewHandler) GetReported(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET REPORTED REVIEWS")
+	w.Header().Set("Content-Type", "application/json")
+
+	reviews, err := h.ReviewService.GetReported(r.Context())
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, reviews, http.StatusOK)
+}
+
+func (h *ReviewHandler) Moderate(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("MODERATE REVIEW")
+	w.Header().Set("Content-Type", "application/json")
+
+	reviewID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into moderate dto..")
+	dto := &review.ModerateDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ReviewID = reviewID
+
+	rv, err := h.ReviewService.Moderate(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, rv, http.StatusOK)
+}
+
+// uintFromQuery returns zero, if the parameter is absent.
+func uintFromQuery(r *http.Request, name string) (uint, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return 0, apperror.BadRequestError(name+" must be an unsigned integer", "")
+	}
+	return uint(id), nil
+}
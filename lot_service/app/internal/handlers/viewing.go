@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing"
+	viewingService "github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"io"
+	"net/http"
+)
+
+const (
+	lotViewingsURL    = "/api/lots/lot/:id/viewings"
+	viewingsURL       = "/api/viewings"
+	singleViewingURL  = "/api/viewings/:id"
+	viewingInviteURL  = "/api/viewings/:id/invite.ics"
+	viewingBookingURL = "/api/viewings/:id/booking"
+)
+
+type ViewingHandler struct {
+	Logger         logging.Logger
+	ViewingService viewingService.Service
+}
+
+func (h *ViewingHandler) Register(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, lotViewingsURL, apperror.Middleware(h.GetLotSlots))
+	router.HandlerFunc(http.MethodPost, lotViewingsURL, apperror.Middleware(h.CreateSlot))
+	router.HandlerFunc(http.MethodGet, viewingsURL, apperror.Middleware(h.GetAppointments))
+	router.HandlerFunc(http.MethodGet, singleViewingURL, apperror.Middleware(h.GetAppointment))
+	router.HandlerFunc(http.MethodPatch, singleViewingURL, apperror.Middleware(h.UpdateSlot))
+	router.HandlerFunc(http.MethodDelete, singleViewingURL, apperror.Middleware(h.DeleteSlot))
+	router.HandlerFunc(http.MethodGet, viewingInviteURL, apperror.Middleware(h.GetInvite))
+	router.HandlerFunc(http.MethodPut, viewingBookingURL, apperror.Middleware(h.Book))
+	router.HandlerFunc(http.MethodDelete, viewingBookingURL, apperror.Middleware(h.Cancel))
+}
+
+// GetLotSlots is public, the owner of the lot sees booked slots too.
+func (h *ViewingHandler) GetLotSlots(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET VIEWING SLOTS")
+	w.Header().Set("Content-Type", "application/json")
+
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+	// anonymous requests have no requester
+	userID, _ := requesterID(r)
+
+	slots, err := h.ViewingService.GetLotSlots(r.Context(), lotID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, slots, http.StatusOK)
+}
+
+func (h *ViewingHandler) CreateSlot(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CREATE VIEWING SLOT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	lotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into create slot dto..")
+	dto := &viewing.CreateSlotDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.LotID = lotID
+	dto.LandlordID = userID
+
+	slot, err := h.ViewingService.CreateSlot(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", viewingsURL, slot.ID))
+	return writeJSON(w, slot, http.StatusCreated)
+}
+
+func (h *ViewingHandler) GetAppointments(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET VIEWING APPOINTMENTS")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+
+	party := viewing.Party(r.URL.Query().Get("party"))
+	if party == "" {
+		party = viewing.PartyRenter
+	}
+
+	slots, err := h.ViewingService.GetAppointments(r.Context(), userID, party)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, slots, http.StatusOK)
+}
+
+func (h *ViewingHandler) GetAppointment(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET VIEWING APPOINTMENT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	appointment, err := h.ViewingService.GetAppointment(r.Context(), slotID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, appointment, http.StatusOK)
+}
+
+func (h *ViewingHandler) GetInvite(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("GET VIEWING INVITE")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	appointment, err := h.ViewingService.GetAppointment(r.Context(), slotID, userID)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8; method=REQUEST")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="viewing-%d.ics"`, slotID))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(appointment.Invite))
+	return nil
+}
+
+func (h *ViewingHandler) UpdateSlot(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("UPDATE VIEWING SLOT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into update slot dto..")
+	dto := &viewing.UpdateSlotDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.ID = slotID
+	dto.LandlordID = userID
+
+	appointment, err := h.ViewingService.UpdateSlot(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, appointment, http.StatusOK)
+}
+
+func (h *ViewingHandler) DeleteSlot(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("DELETE VIEWING SLOT")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	if err = h.ViewingService.DeleteSlot(r.Context(), slotID, userID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Book books the slot. Body is optional, from_slot_id in it moves existing appointment.
+func (h *ViewingHandler) Book(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("BOOK VIEWING SLOT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Debug("decoding r.body into book slot dto..")
+	dto := &viewing.BookSlotDTO{}
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(dto); err != nil && !errors.Is(err, io.EOF) {
+		return apperror.BadRequestError("invalid data", "")
+	}
+	dto.SlotID = slotID
+	dto.RenterID = userID
+
+	appointment, err := h.ViewingService.Book(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, appointment, http.StatusOK)
+}
+
+func (h *ViewingHandler) Cancel(w http.ResponseWriter, r *http.Request) error {
+	h.Logger.Info("CANCEL VIEWING APPOINTMENT")
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := requesterID(r)
+	if err != nil {
+		return err
+	}
+	slotID, err := idFromParams(r)
+	if err != nil {
+		return err
+	}
+
+	appointment, err := h.ViewingService.Cancel(r.Context(), slotID, userID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, appointment, http.StatusOK)
+}
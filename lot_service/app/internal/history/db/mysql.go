@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+const versionColumns = "version_id, lot_id, number, action, actor_id, reverted_to, changes, snapshot, created_at"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanVersion(row scanner) (*history.Version, error) {
+	v := &history.Version{}
+	var revertedTo sql.NullInt64
+	var changes, snapshot []byte
+	var createdAt *mysql.RawTime
+	err := row.Scan(&v.ID, &v.LotID, &v.Number, &v.Action, &v.ActorID, &revertedTo, &changes, &snapshot,
+		&createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if revertedTo.Valid {
+		number := uint(revertedTo.Int64)
+		v.RevertedTo = &number
+	}
+	if err = json.Unmarshal(changes, &v.Changes); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(snapshot, &v.Snapshot); err != nil {
+		return nil, err
+	}
+	if v.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Save saves version of the record as the next version of its lot in the transaction of the change of the lot
+// and sets its ID and number. The lot row is locked, so versions of the lot are numbered one by one.
+func Save(ctx context.Context, tx *sql.Tx, record *history.Record) error {
+	v := record.Version
+	var last uint
+	err := tx.QueryRowContext(ctx, `
+	SELECT IFNULL((SELECT MAX(number) FROM lot_versions WHERE lot_id=lots.lot_id), 0)
+	FROM lots
+	WHERE lot_id=?
+	FOR UPDATE;`, v.LotID).Scan(&last)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperror.ErrNotFound
+		}
+		return err
+	}
+
+	if last == 0 && record.Initial != nil {
+		if err = insert(ctx, tx, record.Initial, 1); err != nil {
+			return err
+		}
+		last = 1
+	}
+	return insert(ctx, tx, v, last+1)
+}
+
+func insert(ctx context.Context, tx *sql.Tx, v *history.Version, number uint) error {
+	changes, err := json.Marshal(v.Changes)
+	if err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(v.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO lot_versions (lot_id, number, action, actor_id, reverted_to, changes, snapshot, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+		v.LotID, number, v.Action, v.ActorID, v.RevertedTo, changes, snapshot, v.CreatedAt.UTC())
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	v.ID, v.Number = uint(id), number
+	return nil
+}
+
+func (s *db) FindByLotID(ctx context.Context, lotID uint) ([]*history.Version, error) {
+	sqlQ, args, err := sq.Select(versionColumns).
+		From("lot_versions").
+		Where(sq.Eq{"lot_id": lotID}).
+		OrderBy("number DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQ, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]*history.Version, 0)
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (s *db) Find(ctx context.Context, lotID, number uint) (*history.Version, error) {
+	queryString := `
+	SELECT ` + versionColumns + `
+	FROM lot_versions
+	WHERE lot_id=? AND number=?;`
+
+	v, err := scanVersion(s.db.QueryRowContext(ctx, queryString, lotID, number))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,245 @@
+package history
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/rental"
+	"reflect"
+	"time"
+)
+
+// Actions, which make versions of lots.
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionTransfer = "transfer"
+	ActionRevert   = "revert"
+	// ActionRental changes rental modes of the lot, they are kept in changes only and aren't restored by revert
+	ActionRental = "rental"
+)
+
+// Snapshot is state of the lot kept in its versions: fields, which are set by owners of the lot.
+type Snapshot struct {
+	AgentID      uint          `json:"agent_id"`
+	TypeOfEstate string        `json:"type_of_estate"`
+	Rooms        int           `json:"rooms"`
+	Area         int           `json:"area"`
+	Floor        int           `json:"floor"`
+	MaxFloor     int           `json:"max_floor"`
+	City         string        `json:"city"`
+	District     string        `json:"district"`
+	Street       string        `json:"street"`
+	Building     string        `json:"building"`
+	Price        int           `json:"price"`
+	Currency     string        `json:"currency"`
+	PricePeriod  string        `json:"price_period"`
+	Location     *lot.Location `json:"location"`
+}
+
+// Change is field of the snapshot changed by the version.
+type Change struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// Version is the lot after the change made by the actor. Versions of the lot are numbered from 1,
+// the first one has no changes.
+type Version struct {
+	ID         uint      `json:"id"`
+	LotID      uint      `json:"lot_id"`
+	Number     uint      `json:"number"`
+	Action     string    `json:"action"`
+	ActorID    uint      `json:"actor_id"`
+	RevertedTo *uint     `json:"reverted_to,omitempty"` // number of version restored by revert
+	Changes    []Change  `json:"changes"`
+	Snapshot   Snapshot  `json:"snapshot"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Record is version of the changed lot saved in the same transaction as the change. Initial version is saved
+// before it, if the lot has no versions yet, it's nil for new lots.
+type Record struct {
+	Version *Version
+	Initial *Version
+}
+
+// GetDTO requests versions of the lot. Moderators get versions of any lot.
+type GetDTO struct {
+	LotID     uint `json:"lot_id"`
+	UserID    uint `json:"user_id"`
+	Moderator bool `json:"moderator"`
+}
+
+// RevertDTO restores the lot from its version. Moderators revert any lot.
+type RevertDTO struct {
+	LotID     uint `json:"lot_id"`
+	Number    uint `json:"number"`
+	UserID    uint `json:"user_id"`
+	Moderator bool `json:"moderator"`
+}
+
+func (dto *GetDTO) ValidateFields() error {
+	userRules := make([]validation.Rule, 0, 1)
+	if !dto.Moderator {
+		userRules = append(userRules, validation.Required)
+	}
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.LotID, validation.Required),
+		validation.Field(&dto.UserID, userRules...))
+}
+
+func (dto *RevertDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.LotID, validation.Required),
+		validation.Field(&dto.Number, validation.Required),
+		validation.Field(&dto.UserID, validation.Required))
+}
+
+func NewSnapshot(l *lot.Lot) Snapshot {
+	s := Snapshot{
+		AgentID:      l.CreatedByUserID,
+		TypeOfEstate: l.TypeOfEstate,
+		Rooms:        l.Rooms,
+		Area:         l.Area,
+		Floor:        l.Floor,
+		MaxFloor:     l.MaxFloor,
+		City:         l.City,
+		District:     l.District,
+		Street:       l.Street,
+		Building:     l.Building,
+		Price:        l.Price,
+		Currency:     l.Currency,
+		PricePeriod:  l.PricePeriod,
+	}
+	if l.Location != nil {
+		location := *l.Location
+		s.Location = &location
+	}
+	return s
+}
+
+// Restore sets fields of the snapshot to the lot except its agent, lots are reassigned by transfer only.
+// Canonical city and district are reset, if the address is changed.
+func (s Snapshot) Restore(l *lot.Lot) {
+	if l.City != s.City || l.District != s.District {
+		l.CityID, l.DistrictID = nil, nil
+	}
+	l.TypeOfEstate = s.TypeOfEstate
+	l.Rooms = s.Rooms
+	l.Area = s.Area
+	l.Floor = s.Floor
+	l.MaxFloor = s.MaxFloor
+	l.City = s.City
+	l.District = s.District
+	l.Street = s.Street
+	l.Building = s.Building
+	l.Price = s.Price
+	l.Currency = s.Currency
+	l.PricePeriod = s.PricePeriod
+	l.Location = nil
+	if s.Location != nil {
+		location := *s.Location
+		l.Location = &location
+	}
+}
+
+type field struct {
+	name  string
+	value any
+}
+
+// fields returns fields of the snapshot in order of their JSON.
+func (s Snapshot) fields() []field {
+	return []field{
+		{"agent_id", s.AgentID},
+		{"type_of_estate", s.TypeOfEstate},
+		{"rooms", s.Rooms},
+		{"area", s.Area},
+		{"floor", s.Floor},
+		{"max_floor", s.MaxFloor},
+		{"city", s.City},
+		{"district", s.District},
+		{"street", s.Street},
+		{"building", s.Building},
+		{"price", s.Price},
+		{"currency", s.Currency},
+		{"price_period", s.PricePeriod},
+		{"location", s.Location},
+	}
+}
+
+// Diff returns fields changed between the snapshots.
+func Diff(prev, next Snapshot) []Change {
+	changes := make([]Change, 0)
+	nextFields := next.fields()
+	for i, f := range prev.fields() {
+		if !reflect.DeepEqual(f.value, nextFields[i].value) {
+			changes = append(changes, Change{Field: f.name, Old: f.value, New: nextFields[i].value})
+		}
+	}
+	return changes
+}
+
+// NewVersion returns version of the lot changed by the actor, before is nil for new lots.
+func NewVersion(action string, actorID uint, before, after *lot.Lot) *Version {
+	v := &Version{
+		LotID:     after.ID,
+		Action:    action,
+		ActorID:   actorID,
+		Changes:   make([]Change, 0),
+		Snapshot:  NewSnapshot(after),
+		CreatedAt: time.Now().UTC(),
+	}
+	if before != nil {
+		v.Changes = Diff(NewSnapshot(before), v.Snapshot)
+	}
+	return v
+}
+
+// NewRecord returns record of the lot changed by the actor, before is nil for new lots. Changes, which leave
+// the lot the same, have no record.
+func NewRecord(action string, actorID uint, before, after *lot.Lot) *Record {
+	return newRecord(NewVersion(action, actorID, before, after), before)
+}
+
+// NewRentalRecord returns record of changed rental modes of the lot. Setting the same modes has no record.
+func NewRentalRecord(actorID uint, l *lot.Lot, before, after *rental.Rental) *Record {
+	v := NewVersion(ActionRental, actorID, l, l)
+	old, next := rentalState(before), rentalState(after)
+	if !reflect.DeepEqual(old, next) {
+		v.Changes = append(v.Changes, Change{Field: "rental", Old: old, New: next})
+	}
+	return newRecord(v, l)
+}
+
+// rentalState returns copy of rental modes without IDs of seasons, seasons get new IDs on every save.
+func rentalState(r *rental.Rental) *rental.Rental {
+	state := *r
+	state.Seasons = make([]*rental.Season, 0, len(r.Seasons))
+	for _, season := range r.Seasons {
+		copied := *season
+		copied.ID = 0
+		state.Seasons = append(state.Seasons, &copied)
+	}
+	return &state
+}
+
+func newRecord(v *Version, before *lot.Lot) *Record {
+	if before == nil {
+		return &Record{Version: v}
+	}
+	if len(v.Changes) == 0 {
+		return nil
+	}
+	return &Record{Version: v, Initial: Initial(before)}
+}
+
+// Initial returns the first version of the lot, which was created before versioning.
+func Initial(l *lot.Lot) *Version {
+	v := NewVersion(ActionCreate, l.CreatedByUserID, nil, l)
+	if !l.CreatedAt.IsZero() {
+		v.CreatedAt = l.CreatedAt
+	}
+	return v
+}
@@ -0,0 +1,33 @@
+package history
+
+import (
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	cityID := uint(1)
+	before := &lot.Lot{ID: 1, CreatedByUserID: 10, City: "Москва", District: "ЦАО", CityID: &cityID, Price: 50000,
+		Currency: "RUB", Location: &lot.Location{Latitude: 55.75, Longitude: 37.62}}
+	after := *before
+	after.Price = 60000
+	after.Location = &lot.Location{Latitude: 55.75, Longitude: 37.62}
+
+	v := NewVersion(ActionUpdate, 10, before, &after)
+	want := []Change{{Field: "price", Old: 50000, New: 60000}}
+	if !reflect.DeepEqual(v.Changes, want) {
+		t.Errorf("expected changes %+v, got %+v", want, v.Changes)
+	}
+
+	after.District = "САО"
+	after.CreatedByUserID = 11
+	restored := after
+	NewSnapshot(before).Restore(&restored)
+	if len(Diff(NewSnapshot(before), NewSnapshot(&restored))) != 1 || restored.CreatedByUserID != 11 {
+		t.Errorf("expected all fields except agent to be restored, got %+v", restored)
+	}
+	if restored.CityID != nil {
+		t.Error("canonical city of changed address must be reset")
+	}
+}
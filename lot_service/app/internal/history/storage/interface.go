@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
+)
+
+// Repository reads versions of lots. Versions are saved by repositories of lots and rental modes
+// in transactions of changes, see history.Record.
+type Repository interface {
+	// FindByLotID returns versions of the lot, newest first.
+	FindByLotID(ctx context.Context, lotID uint) ([]*history.Version, error)
+	Find(ctx context.Context, lotID, number uint) (*history.Version, error)
+}
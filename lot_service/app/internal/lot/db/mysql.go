@@ -8,11 +8,17 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/duplicate"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
+	historyDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/history/db"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"math"
+	"strconv"
 	"strings"
-	"time"
 )
 
 var _ storage.Repository = &db{}
@@ -29,160 +35,245 @@ func NewStorage(storage *sql.DB, logger logging.Logger) *db {
 	}
 }
 
-type rawTime []byte
+const lotColumns = `
+	lot_id, user_id, organization_id, type_of_estate, rooms, area, floor,
+	IFNULL(max_floor, 0),
+	city_id, district_id, city, district, street, building, price, currency, price_period, price_base, available,
+	(SELECT IFNULL(AVG(rv.rating), 0) FROM reviews rv
+	WHERE rv.lot_id=lots.lot_id AND rv.hidden=FALSE) AS rating,
+	(SELECT COUNT(*) FROM reviews rv
+	WHERE rv.lot_id=lots.lot_id AND rv.hidden=FALSE) AS rating_count,
+	(SELECT IFNULL(AVG(rv.rating), 0) FROM reviews rv
+	WHERE rv.landlord_id=lots.user_id AND rv.hidden=FALSE) AS landlord_rating,
+	(SELECT COUNT(*) FROM reviews rv
+	WHERE rv.landlord_id=lots.user_id AND rv.hidden=FALSE) AS landlord_rating_count,
+	latitude, longitude,
+	created_at, redacted_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
 
-func (t *rawTime) time() (time.Time, error) {
-	return time.Parse("2006-01-02 15:04:05", string(*t))
+// scanLot scans row selected with lotColumns.
+func scanLot(row scanner) (*lot.Lot, error) {
+	l := &lot.Lot{}
+	var createdAt, redactedAt *mysql.RawTime
+	var organizationID, cityID, districtID, priceBase sql.NullInt64
+	var latitude, longitude sql.NullFloat64
+	err := row.Scan(
+		&l.ID,
+		&l.CreatedByUserID,
+		&organizationID,
+		&l.TypeOfEstate,
+		&l.Rooms,
+		&l.Area,
+		&l.Floor,
+		&l.MaxFloor,
+		&cityID,
+		&districtID,
+		&l.City,
+		&l.District,
+		&l.Street,
+		&l.Building,
+		&l.Price,
+		&l.Currency,
+		&l.PricePeriod,
+		&priceBase,
+		&l.Available,
+		&l.Rating.Average,
+		&l.Rating.Count,
+		&l.LandlordRating.Average,
+		&l.LandlordRating.Count,
+		&latitude,
+		&longitude,
+		&createdAt,
+		&redactedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if organizationID.Valid {
+		id := uint(organizationID.Int64)
+		l.OrganizationID = &id
+	}
+	if cityID.Valid {
+		id := uint(cityID.Int64)
+		l.CityID = &id
+	}
+	if districtID.Valid {
+		id := uint(districtID.Int64)
+		l.DistrictID = &id
+	}
+	if priceBase.Valid {
+		l.PriceBase = int(priceBase.Int64)
+	}
+	if latitude.Valid && longitude.Valid {
+		l.Location = &lot.Location{Latitude: latitude.Float64, Longitude: longitude.Float64}
+	}
+
+	l.CreatedAt, err = createdAt.Time()
+	if err != nil {
+		return nil, err
+	}
+
+	l.RedactedAt, err = redactedAt.Time()
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
 }
 
-func (s *db) Create(ctx context.Context, lot *lot.Lot) (uint, error) {
+// change runs the change of the lot and saves version of the record in one transaction.
+func (s *db) change(ctx context.Context, record *history.Record, change func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = change(tx); err != nil {
+		return err
+	}
+	if record != nil {
+		if err = historyDB.Save(ctx, tx, record); err != nil {
+			return fmt.Errorf("failed to save version of lot. error: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *db) Create(ctx context.Context, lot *lot.Lot, record *history.Record) (uint, error) {
 	queryString := `
 	INSERT INTO lots (
 		user_id,
+		organization_id,
 		type_of_estate,
 		rooms,
 		area,
 		floor,
 		max_floor,
+		city_id,
+		district_id,
 		city,
 		district,
 		street,
 		building,
-		price
+		price,
+		currency,
+		price_period,
+		price_base,
+		latitude,
+		longitude
 	)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+		(SELECT ROUND(? * rate) FROM currency_rates WHERE currency=?), ?, ?);`
 
-	stmt, err := s.db.PrepareContext(ctx, queryString)
-	if err != nil {
-		return 0, err
+	var latitude, longitude *float64
+	if lot.Location != nil {
+		latitude, longitude = &lot.Location.Latitude, &lot.Location.Longitude
 	}
-	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx,
-		lot.CreatedByUserID,
-		lot.TypeOfEstate,
-		lot.Rooms,
-		lot.Area,
-		lot.Floor,
-		lot.MaxFloor,
-		lot.City,
-		lot.District,
-		lot.Street,
-		lot.Building,
-		lot.Price,
-	)
-	if err != nil {
-		return 0, err
-	}
-	retID, err := res.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-	return uint(retID), err
+	var id uint
+	err := s.change(ctx, record, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, queryString,
+			lot.CreatedByUserID,
+			lot.OrganizationID,
+			lot.TypeOfEstate,
+			lot.Rooms,
+			lot.Area,
+			lot.Floor,
+			lot.MaxFloor,
+			lot.CityID,
+			lot.DistrictID,
+			lot.City,
+			lot.District,
+			lot.Street,
+			lot.Building,
+			lot.Price,
+			lot.Currency,
+			lot.PricePeriod,
+			lot.Price,
+			lot.Currency,
+			latitude,
+			longitude,
+		)
+		if err != nil {
+			return err
+		}
+		retID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		id = uint(retID)
+		if record != nil {
+			record.Version.LotID = id
+		}
+		return nil
+	})
+	return id, err
 }
 
 func (s *db) FindByLotID(ctx context.Context, id uint) (*lot.Lot, error) {
-	l := &lot.Lot{}
-	var createdAt, redactedAt *rawTime
-
 	queryString := `
-	SELECT *
+	SELECT` + lotColumns + `
 	FROM lots 
 	WHERE lot_id=?;`
 
-	err := s.db.QueryRowContext(ctx, queryString, id).Scan(
-		&l.ID,
-		&l.CreatedByUserID,
-		&l.TypeOfEstate,
-		&l.Rooms,
-		&l.Area,
-		&l.Floor,
-		&l.MaxFloor,
-		&l.City,
-		&l.District,
-		&l.Street,
-		&l.Building,
-		&l.Price,
-		&createdAt,
-		&redactedAt,
-	)
+	l, err := scanLot(s.db.QueryRowContext(ctx, queryString, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, apperror.ErrNotFound
 		}
 		return nil, err
 	}
-
-	l.CreatedAt, err = createdAt.time()
-	if err != nil {
-		return nil, err
-	}
-
-	l.RedactedAt, err = redactedAt.time()
-	if err != nil {
-		return nil, err
-	}
-
 	return l, nil
 }
 
 func (s *db) FindByUserID(ctx context.Context, id uint) ([]*lot.Lot, error) {
-	lotsByUser := make([]*lot.Lot, 0, 10)
-
 	queryString := `
-	SELECT *
+	SELECT` + lotColumns + `
 	FROM lots
 	WHERE user_id=?;`
 
-	rows, err := s.db.QueryContext(ctx, queryString, id)
+	return s.query(ctx, queryString, id)
+}
+
+func (s *db) FindByOrganizationID(ctx context.Context, id uint) ([]*lot.Lot, error) {
+	queryString := `
+	SELECT` + lotColumns + `
+	FROM lots
+	WHERE organization_id=?
+	ORDER BY lot_id DESC;`
+
+	return s.query(ctx, queryString, id)
+}
+
+func (s *db) query(ctx context.Context, queryString string, args ...any) ([]*lot.Lot, error) {
+	lots := make([]*lot.Lot, 0, 10)
+
+	rows, err := s.db.QueryContext(ctx, queryString, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	for rows.Next() {
-		l := &lot.Lot{}
-		var createdAt, redactedAt *rawTime
-		if err = rows.Scan(
-			&l.ID,
-			&l.CreatedByUserID,
-			&l.TypeOfEstate,
-			&l.Rooms,
-			&l.Area,
-			&l.Floor,
-			&l.MaxFloor,
-			&l.City,
-			&l.District,
-			&l.Street,
-			&l.Building,
-			&l.Price,
-			&createdAt,
-			&redactedAt,
-		); err != nil {
-			return nil, err
-		}
-		l.CreatedAt, err = createdAt.time()
+		l, err := scanLot(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		l.RedactedAt, err = redactedAt.time()
-		if err != nil {
-			return nil, err
-		}
-		lotsByUser = append(lotsByUser, l)
+		lots = append(lots, l)
 	}
 	if err = rows.Err(); err != nil {
-		return lotsByUser, err
+		return lots, err
 	}
-	return lotsByUser, nil
+	return lots, nil
 }
 
 func (s *db) FindWithFilter(ctx context.Context, qo storage.QueryOptions) ([]*lot.Lot, error) {
-	qb := sq.Select("*").From("lots")
-
-	fo := qo.GetFilters()
-	if len(fo) != 0 {
-		qb = addFilters(qb, fo)
+	qb := filtered(sq.Select(lotColumns).From("lots"), qo)
+	qb = qb.OrderBy(qo.GetOrderBy(), "lot_id DESC")
+	if limit := qo.GetLimit(); limit > 0 {
+		qb = qb.Limit(uint64(limit))
 	}
 
 	sqlQ, args, err := qb.ToSql()
@@ -195,35 +286,11 @@ func (s *db) FindWithFilter(ctx context.Context, qo storage.QueryOptions) ([]*lo
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	lots := make([]*lot.Lot, 0)
 	for rows.Next() {
-		l := &lot.Lot{}
-		var createdAt, redactedAt *rawTime
-		if err = rows.Scan(
-			&l.ID,
-			&l.CreatedByUserID,
-			&l.TypeOfEstate,
-			&l.Rooms,
-			&l.Area,
-			&l.Floor,
-			&l.MaxFloor,
-			&l.City,
-			&l.District,
-			&l.Street,
-			&l.Building,
-			&l.Price,
-			&createdAt,
-			&redactedAt,
-		); err != nil {
-			return nil, err
-		}
-		l.CreatedAt, err = createdAt.time()
-		if err != nil {
-			return nil, err
-		}
-
-		l.RedactedAt, err = redactedAt.time()
+		l, err := scanLot(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -236,42 +303,124 @@ func (s *db) FindWithFilter(ctx context.Context, qo storage.QueryOptions) ([]*lo
 
 }
 
-func (s *db) Update(ctx context.Context, lot *lot.Lot) error {
-	queryString := `
-	UPDATE lots
-	SET price=?
-	WHERE lot_id=? AND user_id=?;`
-	stmt, err := s.db.PrepareContext(ctx, queryString)
+func (s *db) Version(ctx context.Context, qo storage.QueryOptions) (*storage.Version, error) {
+	qb := filtered(sq.Select("COUNT(*)", "IFNULL(MAX(lot_id), 0)", "MAX(redacted_at)").From("lots"), qo)
+
+	sqlQ, args, err := qb.ToSql()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer stmt.Close()
+	s.logger.Tracef("SQL Query: %s", formatQuery(sqlQ))
 
-	res, err := stmt.ExecContext(ctx, lot.Price, lot.ID, lot.CreatedByUserID)
-	if err != nil {
-		return err
+	v := &storage.Version{}
+	var modifiedAt *mysql.RawTime
+	if err = s.db.QueryRowContext(ctx, sqlQ, args...).Scan(&v.Count, &v.LastID, &modifiedAt); err != nil {
+		return nil, err
+	}
+	if modifiedAt != nil {
+		if v.ModifiedAt, err = modifiedAt.Time(); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (s *db) Update(ctx context.Context, lot *lot.Lot, record *history.Record) error {
+	queryString := `
+	UPDATE lots
+	SET price=?, price_base=(SELECT ROUND(? * rate) FROM currency_rates WHERE currency=lots.currency)
+	WHERE lot_id=?;`
+
+	return s.change(ctx, record, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, queryString, lot.Price, lot.Price, lot.ID)
+		if err != nil {
+			return err
+		}
+		// the price is the same or the lot doesn't exist
+		return s.checkAffected(ctx, res, lot.ID)
+	})
+}
+
+func (s *db) Restore(ctx context.Context, lot *lot.Lot, record *history.Record) error {
+	queryString := `
+	UPDATE lots
+	SET type_of_estate=?, rooms=?, area=?, floor=?, max_floor=?,
+		city_id=?, district_id=?, city=?, district=?, street=?, building=?,
+		price=?, currency=?, price_period=?,
+		price_base=(SELECT ROUND(? * rate) FROM currency_rates WHERE currency=?),
+		latitude=?, longitude=?
+	WHERE lot_id=?;`
+
+	var latitude, longitude *float64
+	if lot.Location != nil {
+		latitude, longitude = &lot.Location.Latitude, &lot.Location.Longitude
 	}
+
+	return s.change(ctx, record, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, queryString,
+			lot.TypeOfEstate,
+			lot.Rooms,
+			lot.Area,
+			lot.Floor,
+			lot.MaxFloor,
+			lot.CityID,
+			lot.DistrictID,
+			lot.City,
+			lot.District,
+			lot.Street,
+			lot.Building,
+			lot.Price,
+			lot.Currency,
+			lot.PricePeriod,
+			lot.Price,
+			lot.Currency,
+			latitude,
+			longitude,
+			lot.ID,
+		)
+		if err != nil {
+			return err
+		}
+		// the lot is the same or doesn't exist
+		return s.checkAffected(ctx, res, lot.ID)
+	})
+}
+
+func (s *db) UpdateAgent(ctx context.Context, lotID, userID uint, record *history.Record) error {
+	return s.change(ctx, record, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE lots SET user_id=? WHERE lot_id=?;`, userID, lotID)
+		if err != nil {
+			return err
+		}
+		// the lot is assigned to the user already or doesn't exist
+		return s.checkAffected(ctx, res, lotID)
+	})
+}
+
+// checkAffected returns apperror.ErrNotFound, if the lot wasn't changed, because it doesn't exist.
+func (s *db) checkAffected(ctx context.Context, res sql.Result, lotID uint) error {
 	rowsAff, err := res.RowsAffected()
 	if err != nil {
 		return err
 	} else if rowsAff == 0 {
-		return apperror.ErrNotFound
+		_, err = s.FindByLotID(ctx, lotID)
+		return err
 	}
 	return nil
 }
 
-func (s *db) Delete(ctx context.Context, lotID, userID uint) error {
+func (s *db) Delete(ctx context.Context, lotID uint) error {
 	queryString := `
 	DELETE
 	FROM lots 
-	WHERE lot_id=? AND user_id=?;`
+	WHERE lot_id=?;`
 	stmt, err := s.db.PrepareContext(ctx, queryString)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, lotID, userID)
+	res, err := stmt.ExecContext(ctx, lotID)
 	if err != nil {
 		return err
 	}
@@ -289,29 +438,93 @@ func formatQuery(q string) string {
 	return strings.ReplaceAll(strings.ReplaceAll(q, "\t", ""), "\n", " ")
 }
 
-func addFilters(qb sq.SelectBuilder, fo map[string][]storage.FilterOption) sq.SelectBuilder {
+// filtered adds filters and availability of options to the query.
+func filtered(qb sq.SelectBuilder, qo storage.QueryOptions) sq.SelectBuilder {
+	if fo := qo.GetFilters(); len(fo) != 0 {
+		qb = addFilters(qb, fo, qo.GetCurrencyRate())
+	}
+	if a := qo.GetAvailability(); a != nil {
+		qb = addAvailability(qb, a)
+	}
+	if r := qo.GetRental(); r != nil {
+		qb = addRental(qb, r)
+	}
+	if qo.GetGroupDuplicates() {
+		qb = qb.Where(sq.Expr(`NOT EXISTS (
+		SELECT 1 FROM lot_duplicates
+		WHERE lot_duplicates.lot_id=lots.lot_id AND status<>?
+	)`, duplicate.StatusDismissed))
+	}
+	return qb
+}
+
+// addFilters adds filters to the query. Values are passed as arguments of the query, so they can't change
+// the SQL. Prices are compared in lot.DefaultCurrency, rate converts prices of filters to it.
+func addFilters(qb sq.SelectBuilder, fo map[string][]storage.FilterOption, rate float64) sq.SelectBuilder {
 	for k, filters := range fo {
 		queryValues := ""
+		args := make([]any, 0, len(filters))
+		column := k
+		if k == "price" {
+			column = "price_base"
+		}
 		for i, values := range filters {
 			for j, v := range values.Value {
-				switch values.Type {
-				case "date":
-					fallthrough
-				case "string":
-					v = fmt.Sprintf("\"%s\"", v)
+				if k == "price" {
+					args = append(args, basePrice(v, rate))
+				} else {
+					args = append(args, v)
 				}
 				if i == 0 && j == 0 {
-					queryValues = fmt.Sprintf("(%s %s %v", k, values.Operator, v)
+					queryValues = fmt.Sprintf("(%s %s ?", column, values.Operator)
 				} else if j != 0 {
-					queryValues = fmt.Sprintf("%s %s %s", queryValues, "AND", v)
+					queryValues = fmt.Sprintf("%s %s ?", queryValues, "AND")
 				} else {
-					queryValues = fmt.Sprintf("%s %s %s %s %s", queryValues, "OR", k, values.Operator, v)
+					queryValues = fmt.Sprintf("%s %s %s %s ?", queryValues, "OR", column, values.Operator)
 				}
 			}
 
 		}
 		queryValues = fmt.Sprintf("%s)", queryValues)
-		qb = qb.Where(queryValues)
+		qb = qb.Where(sq.Expr(queryValues, args...))
 	}
 	return qb
 }
+
+// addRental selects lots with the rental mode or, if they have no modes, with the price period of the mode.
+func addRental(qb sq.SelectBuilder, r *storage.Rental) sq.SelectBuilder {
+	mode := "m.lot_id=lots.lot_id AND m.mode=?"
+	args := []any{r.Mode}
+	if r.Stay > 0 {
+		mode += " AND m.min_stay<=?"
+		args = append(args, r.Stay)
+	}
+	args = append(args, r.Period)
+	return qb.Where(sq.Expr(`(
+		EXISTS (SELECT 1 FROM lot_rental_modes m WHERE `+mode+`)
+		OR lots.price_period=? AND NOT EXISTS (SELECT 1 FROM lot_rental_modes m WHERE m.lot_id=lots.lot_id)
+	)`, args...))
+}
+
+// basePrice converts price of filter to lot.DefaultCurrency, invalid prices are compared as they are.
+func basePrice(v string, rate float64) any {
+	price, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	return math.Round(price * rate)
+}
+
+// addAvailability excludes lots with accepted bookings or calendar blocks overlapping the period.
+func addAvailability(qb sq.SelectBuilder, a *storage.Availability) sq.SelectBuilder {
+	from, to := a.From.Format(booking.DateLayout), a.To.Format(booking.DateLayout)
+	return qb.
+		Where(sq.Expr(`NOT EXISTS (
+		SELECT 1 FROM bookings
+		WHERE bookings.lot_id=lots.lot_id AND status=? AND check_in<? AND check_out>?
+	)`, booking.StatusAccepted, to, from)).
+		Where(sq.Expr(`NOT EXISTS (
+		SELECT 1 FROM calendar_blocks
+		WHERE calendar_blocks.lot_id=lots.lot_id AND start_date<? AND end_date>?
+	)`, to, from))
+}
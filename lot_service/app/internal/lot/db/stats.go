@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"math"
+	"time"
+)
+
+// Stats aggregates lots selected by options with a few queries. Percentiles are selected by offset in ordered
+// prices, so window functions of MySQL 8 aren't required. Prices are in currency of options, lots in currencies
+// without rates are counted, but their prices are left out.
+func (s *db) Stats(ctx context.Context, qo storage.QueryOptions, since time.Time) (*lot.Stats, error) {
+	// the rate is a number, not user's input, so it's safe to format it into expressions
+	price := fmt.Sprintf("price_base/%g", qo.GetCurrencyRate())
+	pricePerMeter := price + "/area"
+
+	st := &lot.Stats{
+		Currency:   qo.GetCurrency(),
+		ByRooms:    make([]lot.RoomsStats, 0),
+		ByDistrict: make([]lot.DistrictStats, 0),
+		NewPerDay:  make([]lot.DailyListings, 0),
+	}
+
+	var priced int
+	qb := filtered(sq.Select(
+		"COUNT(*)", "COUNT(price_base)",
+		"IFNULL(MIN("+price+"), 0)", "IFNULL(MAX("+price+"), 0)", "IFNULL(AVG("+price+"), 0)",
+		"IFNULL(MIN("+pricePerMeter+"), 0)", "IFNULL(MAX("+pricePerMeter+"), 0)", "IFNULL(AVG("+pricePerMeter+"), 0)",
+	).From("lots"), qo)
+	err := s.scanRow(ctx, qb, &st.Count, &priced,
+		&st.Price.Min, &st.Price.Max, &st.Price.Avg,
+		&st.PricePerMeter.Min, &st.PricePerMeter.Max, &st.PricePerMeter.Avg)
+	if err != nil {
+		return nil, err
+	}
+
+	if st.Price.Percentiles, err = s.percentiles(ctx, qo, price, priced); err != nil {
+		return nil, err
+	}
+	if st.PricePerMeter.Percentiles, err = s.percentiles(ctx, qo, pricePerMeter, priced); err != nil {
+		return nil, err
+	}
+	st.Price.Median = st.Price.Percentiles["p50"]
+	st.PricePerMeter.Median = st.PricePerMeter.Percentiles["p50"]
+	if st.Count == 0 {
+		return st, nil
+	}
+
+	qb = filtered(sq.Select("rooms", "COUNT(*)", "IFNULL(AVG("+price+"), 0)", "IFNULL(AVG("+pricePerMeter+"), 0)").From("lots"), qo).
+		GroupBy("rooms").
+		OrderBy("rooms")
+	err = s.scanRows(ctx, qb, func(rows *sql.Rows) error {
+		r := lot.RoomsStats{}
+		if err := rows.Scan(&r.Rooms, &r.Count, &r.AvgPrice, &r.AvgPricePerMeter); err != nil {
+			return err
+		}
+		st.ByRooms = append(st.ByRooms, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	qb = filtered(sq.Select("district", "COUNT(*)", "IFNULL(AVG("+price+"), 0)", "IFNULL(AVG("+pricePerMeter+"), 0)").From("lots"), qo).
+		GroupBy("district").
+		OrderBy("COUNT(*) DESC", "district")
+	err = s.scanRows(ctx, qb, func(rows *sql.Rows) error {
+		d := lot.DistrictStats{}
+		if err := rows.Scan(&d.District, &d.Count, &d.AvgPrice, &d.AvgPricePerMeter); err != nil {
+			return err
+		}
+		st.ByDistrict = append(st.ByDistrict, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	qb = filtered(sq.Select("DATE(created_at) AS day", "COUNT(*)").From("lots"), qo).
+		Where(sq.GtOrEq{"created_at": since.UTC()}).
+		GroupBy("day").
+		OrderBy("day")
+	err = s.scanRows(ctx, qb, func(rows *sql.Rows) error {
+		d := lot.DailyListings{}
+		if err := rows.Scan(&d.Date, &d.Count); err != nil {
+			return err
+		}
+		st.NewPerDay = append(st.NewPerDay, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// percentiles returns lot.StatsPercentiles of the expression of price by nearest rank among count lots
+// with converted prices.
+func (s *db) percentiles(ctx context.Context, qo storage.QueryOptions, expr string,
+	count int) (map[string]float64, error) {
+	percentiles := make(map[string]float64, len(lot.StatsPercentiles))
+	for _, p := range lot.StatsPercentiles {
+		name := fmt.Sprintf("p%d", p)
+		percentiles[name] = 0
+		if count == 0 {
+			continue
+		}
+
+		offset := int(math.Ceil(float64(p)/100*float64(count))) - 1
+		if offset < 0 {
+			offset = 0
+		}
+		qb := filtered(sq.Select(expr).From("lots"), qo).
+			Where("price_base IS NOT NULL").
+			OrderBy(expr).
+			Limit(1).
+			Offset(uint64(offset))
+		var v float64
+		if err := s.scanRow(ctx, qb, &v); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// lots were deleted after counting
+				continue
+			}
+			return nil, err
+		}
+		percentiles[name] = v
+	}
+	return percentiles, nil
+}
+
+func (s *db) scanRow(ctx context.Context, qb sq.SelectBuilder, dest ...any) error {
+	sqlQ, args, err := qb.ToSql()
+	if err != nil {
+		return err
+	}
+	s.logger.Tracef("SQL Query: %s", formatQuery(sqlQ))
+
+	return s.db.QueryRowContext(ctx, sqlQ, args...).Scan(dest...)
+}
+
+func (s *db) scanRows(ctx context.Context, qb sq.SelectBuilder, scan func(rows *sql.Rows) error) error {
+	sqlQ, args, err := qb.ToSql()
+	if err != nil {
+		return err
+	}
+	s.logger.Tracef("SQL Query: %s", formatQuery(sqlQ))
+
+	rows, err := s.db.QueryContext(ctx, sqlQ, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
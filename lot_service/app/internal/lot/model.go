@@ -2,38 +2,113 @@ package lot
 
 import (
 	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/review"
+	"math"
+	"strings"
 	"time"
 )
 
+// DefaultCurrency is base currency, prices of lots in other currencies are compared after conversion to it.
+const DefaultCurrency = "RUB"
+
+// Periods of prices.
+const (
+	PeriodMonth = "month"
+	PeriodDay   = "day"
+	PeriodSale  = "sale" // price of the estate itself
+)
+
 type Lot struct {
-	ID              uint   `json:"id"`
-	CreatedByUserID uint   `json:"created_by_user_id"`
-	TypeOfEstate    string `json:"type_of_estate"`
-	Rooms           int    `json:"rooms"`
-	Area            int    `json:"area"`
-	Floor           int    `json:"floor"`
-	MaxFloor        int    `json:"max_floor"`
-	City            string `json:"city"`
-	District        string `json:"district"`
-	Street          string `json:"street"`
-	Building        string `json:"building"`
-	Price           int    `json:"price"`
+	ID              uint          `json:"id"`
+	CreatedByUserID uint          `json:"created_by_user_id"`        // agent of the lot, if it's owned by organization
+	OrganizationID  *uint         `json:"organization_id,omitempty"` // nil for lots of private landlords
+	TypeOfEstate    string        `json:"type_of_estate"`
+	Rooms           int           `json:"rooms"`
+	Area            int           `json:"area"`
+	Floor           int           `json:"floor"`
+	MaxFloor        int           `json:"max_floor"`
+	CityID          *uint         `json:"city_id"` // canonical city and district, nil until the address is resolved
+	DistrictID      *uint         `json:"district_id"`
+	City            string        `json:"city"`
+	District        string        `json:"district"`
+	Street          string        `json:"street"`
+	Building        string        `json:"building"`
+	Price           int           `json:"price"`
+	Currency        string        `json:"currency"`                  // ISO 4217 code
+	PricePeriod     string        `json:"price_period"`              // month, day or sale
+	PriceBase       int           `json:"-"`                         // price in DefaultCurrency, zero until it's converted
+	Converted       *Price        `json:"converted_price,omitempty"` // price in currency requested for display
+	Stay            *Stay         `json:"stay,omitempty"`            // price of stay of searches by rental mode and dates
+	Location        *Location     `json:"location"`
+	Available       bool          `json:"available"`       // false during stays of accepted bookings
+	Rating          review.Rating `json:"rating"`          // of the lot by its reviews
+	LandlordRating  review.Rating `json:"landlord_rating"` // of the owner by reviews of all the owner's lots
 	CreatedAt       time.Time
 	RedactedAt      time.Time
 }
 
 type CreateLotDTO struct {
-	CreatedByUserID uint   `json:"created_by_user_id"`
-	TypeOfEstate    string `json:"type_of_estate"`
-	Rooms           int    `json:"rooms"`
-	Area            int    `json:"area"`
-	Floor           int    `json:"floor"`
-	MaxFloor        int    `json:"max_floor"`
-	City            string `json:"city"`
-	District        string `json:"district"`
-	Street          string `json:"street"`
-	Building        string `json:"building"`
-	Price           int    `json:"price"`
+	CreatedByUserID uint      `json:"created_by_user_id"`
+	OrganizationID  uint      `json:"organization_id"` // optional, the creator must be a member of the organization
+	TypeOfEstate    string    `json:"type_of_estate"`
+	Rooms           int       `json:"rooms"`
+	Area            int       `json:"area"`
+	Floor           int       `json:"floor"`
+	MaxFloor        int       `json:"max_floor"`
+	City            string    `json:"city"`
+	District        string    `json:"district"`
+	Street          string    `json:"street"`
+	Building        string    `json:"building"`
+	Price           int       `json:"price"`
+	Currency        string    `json:"currency"`     // optional, DefaultCurrency by default
+	PricePeriod     string    `json:"price_period"` // optional, month by default
+	Location        *Location `json:"location"`     // optional
+}
+
+// Price is amount in the currency.
+type Price struct {
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// Stay is price of stay in rental mode of the lot in its currency. Short-term stays are priced by nights,
+// long-term stays by months with the rest of days priced as parts of month.
+type Stay struct {
+	Mode        string `json:"mode"`
+	CheckIn     string `json:"check_in"`  // YYYY-MM-DD
+	CheckOut    string `json:"check_out"` // YYYY-MM-DD
+	Nights      int    `json:"nights"`
+	Months      int    `json:"months,omitempty"` // full months of long-term stay
+	Price       int    `json:"price"`            // of nights or months with seasonal prices
+	CleaningFee int    `json:"cleaning_fee"`
+	Total       int    `json:"total"`
+	Currency    string `json:"currency"`
+	Converted   *Price `json:"converted_total,omitempty"` // total in currency requested for display
+}
+
+// Location is coordinates of the building in degrees, it's null for lots with unknown coordinates.
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// earthRadius is mean radius of the Earth in kilometers.
+const earthRadius = 6371.0
+
+// DistanceKm returns great-circle distance to another location in kilometers.
+func (l Location) DistanceKm(o Location) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(o.Latitude - l.Latitude)
+	dLon := rad(o.Longitude - l.Longitude)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(l.Latitude))*math.Cos(rad(o.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func (l Location) Validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.Latitude, validation.Min(-90.0), validation.Max(90.0)),
+		validation.Field(&l.Longitude, validation.Min(-180.0), validation.Max(180.0)))
 }
 
 type UpdateLotDTO struct {
@@ -42,9 +117,30 @@ type UpdateLotDTO struct {
 	Price           int  `json:"price"`
 }
 
+// TransferLotDTO assigns lot of organization to another agent of the organization.
+type TransferLotDTO struct {
+	ID      uint `json:"id"`
+	UserID  uint `json:"user_id"` // the one making the transfer
+	AgentID uint `json:"agent_id"`
+}
+
 func NewLot(dto *CreateLotDTO) *Lot {
+	var organizationID *uint
+	if dto.OrganizationID != 0 {
+		id := dto.OrganizationID
+		organizationID = &id
+	}
+	currency := strings.ToUpper(strings.TrimSpace(dto.Currency))
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	period := dto.PricePeriod
+	if period == "" {
+		period = PeriodMonth
+	}
 	return &Lot{
 		CreatedByUserID: dto.CreatedByUserID,
+		OrganizationID:  organizationID,
 		TypeOfEstate:    dto.TypeOfEstate,
 		Rooms:           dto.Rooms,
 		Area:            dto.Area,
@@ -55,6 +151,9 @@ func NewLot(dto *CreateLotDTO) *Lot {
 		Street:          dto.Street,
 		Building:        dto.Building,
 		Price:           dto.Price,
+		Currency:        currency,
+		PricePeriod:     period,
+		Location:        dto.Location,
 	}
 }
 
@@ -66,13 +165,24 @@ func UpdatedLot(dto *UpdateLotDTO) *Lot {
 	}
 }
 
-func (l *Lot) ValidateFields() error {
+// DefaultEstateTypes are used to validate lots, when types of estate aren't loaded from reference data.
+var DefaultEstateTypes = []string{"квартира", "дом"}
+
+// BasePrice returns price in DefaultCurrency, lots, which aren't converted yet, are compared by their prices.
+func (l *Lot) BasePrice() int {
+	if l.PriceBase > 0 {
+		return l.PriceBase
+	}
+	return l.Price
+}
+
+// ValidateFields checks fields of the lot, its type of estate must be one of estateTypes and its currency
+// must be one of currencies. City and district are checked against reference data, when they are resolved.
+func (l *Lot) ValidateFields(estateTypes, currencies []string) error {
 	return validation.ValidateStruct(
 		l,
 		validation.Field(&l.CreatedByUserID, validation.Required),
-		validation.Field(&l.TypeOfEstate, validation.Required, validation.In(
-			"квартира",
-			"дом")),
+		validation.Field(&l.TypeOfEstate, validation.Required, validation.In(values(estateTypes)...)),
 		validation.Field(&l.Rooms, validation.Max(6)),
 		validation.Field(&l.Area, validation.Required),
 		validation.Field(&l.Floor, validation.Required, validation.Max(163)),
@@ -82,12 +192,73 @@ func (l *Lot) ValidateFields() error {
 		validation.Field(&l.Street, validation.Required),
 		validation.Field(&l.Building, validation.Required),
 		validation.Field(&l.Price, validation.Required),
+		validation.Field(&l.Currency, validation.Required, validation.In(values(currencies)...)),
+		validation.Field(&l.PricePeriod, validation.Required, validation.In(PeriodMonth, PeriodDay, PeriodSale)),
+		validation.Field(&l.Location),
 	)
 }
 
+func values(known []string) []any {
+	v := make([]any, 0, len(known))
+	for _, k := range known {
+		v = append(v, k)
+	}
+	return v
+}
+
 func (dto *UpdateLotDTO) ValidateFields() error {
 	return validation.ValidateStruct(dto,
 		validation.Field(&dto.ID, validation.Required),
 		validation.Field(&dto.CreatedByUserID, validation.Required),
 		validation.Field(&dto.Price, validation.Required))
 }
+
+func (dto *TransferLotDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.AgentID, validation.Required))
+}
+
+// StatsPercentiles are percentiles of prices in Stats.
+var StatsPercentiles = []int{10, 25, 50, 75, 90}
+
+// Stats are aggregates over lots selected with filter of lot search.
+type Stats struct {
+	Currency      string          `json:"currency"` // of prices
+	Count         int             `json:"count"`
+	Price         PriceStats      `json:"price"`
+	PricePerMeter PriceStats      `json:"price_per_m2"`
+	ByRooms       []RoomsStats    `json:"by_rooms"`
+	ByDistrict    []DistrictStats `json:"by_district"` // most listed first
+	NewPerDay     []DailyListings `json:"new_per_day"` // from the oldest day
+	ETag          string          `json:"-"`           // changes along with selected lots
+}
+
+type PriceStats struct {
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Avg         float64            `json:"avg"`
+	Median      float64            `json:"median"`
+	Percentiles map[string]float64 `json:"percentiles"` // by names like p10 and p90
+}
+
+type RoomsStats struct {
+	Rooms            int     `json:"rooms"`
+	Count            int     `json:"count"`
+	AvgPrice         float64 `json:"avg_price"`
+	AvgPricePerMeter float64 `json:"avg_price_per_m2"`
+}
+
+type DistrictStats struct {
+	District         string  `json:"district"`
+	Count            int     `json:"count"`
+	AvgPrice         float64 `json:"avg_price"`
+	AvgPricePerMeter float64 `json:"avg_price_per_m2"`
+}
+
+// DailyListings is number of lots created during the day.
+type DailyListings struct {
+	Date  string `json:"date"` // YYYY-MM-DD in UTC
+	Count int    `json:"count"`
+}
@@ -2,17 +2,29 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
+	historyStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/history/storage"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/rental"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/api/filter"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/api/sort"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/currency"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"math"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 var _ Service = &service{}
@@ -22,37 +34,177 @@ type Service interface {
 	GetByLotID(ctx context.Context, id string) (*lot.Lot, error)
 	GetByUserID(ctx context.Context, id string) ([]*lot.Lot, error)
 	GetLotsWithFilter(ctx context.Context, query url.Values) ([]*lot.Lot, error)
+	// GetStats aggregates lots selected with filter from query. New lots are counted for the number of days
+	// from the query. Stats are computed again only when the selected lots change.
+	GetStats(ctx context.Context, query url.Values) (*lot.Stats, error)
+	// Update changes the lot. Lots are changed by their agents and by owners and managers of their organizations.
+	// Users, who saved the lot, are notified, when its price drops.
 	Update(ctx context.Context, dto *lot.UpdateLotDTO) error
 	Delete(ctx context.Context, lotID, userID uint) error
+	// Transfer assigns lot of organization to another member of the organization.
+	Transfer(ctx context.Context, dto *lot.TransferLotDTO) (*lot.Lot, error)
+	// GetHistory returns versions of the lot, newest first. Versions are shown to those, who can change the lot,
+	// and to moderators.
+	GetHistory(ctx context.Context, dto *history.GetDTO) ([]*history.Version, error)
+	// Revert restores the lot from its version, the restored lot is saved as a new version.
+	Revert(ctx context.Context, dto *history.RevertDTO) (*lot.Lot, error)
+	// FindForChange returns the lot, if the user is its agent or manages lots of its organization.
+	FindForChange(ctx context.Context, lotID, userID uint) (*lot.Lot, error)
 }
 
+// AddressResolver sets canonical city and district of new lot.
+type AddressResolver interface {
+	Resolve(ctx context.Context, l *lot.Lot) error
+}
+
+// EstateTypes returns codes of known types of estate from reference data.
+type EstateTypes interface {
+	EstateTypes(ctx context.Context) ([]string, error)
+}
+
+// KnownEstateTypes returns types of estate lots are validated with, lot.DefaultEstateTypes are returned,
+// if types is nil.
+func KnownEstateTypes(ctx context.Context, types EstateTypes) ([]string, error) {
+	if types == nil {
+		return lot.DefaultEstateTypes, nil
+	}
+	known, err := types.EstateTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get estate types. error: %w", err)
+	}
+	return known, nil
+}
+
+// Rates returns rates of currencies in lot.DefaultCurrency.
+type Rates interface {
+	Rates(ctx context.Context) (*currency.Rates, error)
+}
+
+// KnownRates returns rates prices of lots are converted at, only lot.DefaultCurrency is known, if rates is nil.
+func KnownRates(ctx context.Context, rates Rates) (*currency.Rates, error) {
+	if rates == nil {
+		return currency.NewRates(lot.DefaultCurrency), nil
+	}
+	known, err := rates.Rates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rates of currencies. error: %w", err)
+	}
+	return known, nil
+}
+
+// StayQuoter sets prices of the stay to lots found by rental mode and dates.
+type StayQuoter interface {
+	QuoteLots(ctx context.Context, lots []*lot.Lot, mode string, checkIn, checkOut time.Time) error
+}
+
+// DuplicateChecker flags probable duplicates of new lot.
+type DuplicateChecker interface {
+	CheckLot(ctx context.Context, l *lot.Lot) error
+}
+
+// PriceWatchers notifies users, who watch the lot, that its price dropped.
+type PriceWatchers interface {
+	NotifyPriceDrop(ctx context.Context, before, after *lot.Lot)
+}
+
+const (
+	// DefStatsDays is number of days of new lots in stats
+	DefStatsDays = 30
+	MaxStatsDays = 365
+	// maxCachedStats limits number of stats kept in memory for different filters
+	maxCachedStats = 1000
+)
+
 type service struct {
-	repository storage.Repository
-	logger     logging.Logger
+	repository    storage.Repository
+	organizations organizationStorage.Repository
+	addresses     AddressResolver
+	estateTypes   EstateTypes
+	rates         Rates
+	stays         StayQuoter
+	versions      historyStorage.Repository
+	duplicates    DuplicateChecker
+	watchers      PriceWatchers
+	logger        logging.Logger
+
+	mu    sync.Mutex
+	stats map[string]*lot.Stats
 }
 
-func NewService(lotStorage storage.Repository, logger logging.Logger) (*service, error) {
+// NewService returns service, which leaves addresses of new lots unresolved, if addresses is nil, validates
+// types of estate with lot.DefaultEstateTypes, if estateTypes is nil, accepts prices in lot.DefaultCurrency
+// only, if rates is nil, doesn't price stays of searches, if stays is nil, shows no versions of lots,
+// if versions is nil, doesn't check new lots for duplicates, if duplicates is nil, and doesn't notify about
+// dropped prices, if watchers is nil.
+func NewService(lotStorage storage.Repository, organizations organizationStorage.Repository,
+	addresses AddressResolver, estateTypes EstateTypes, rates Rates, stays StayQuoter,
+	versions historyStorage.Repository, duplicates DuplicateChecker, watchers PriceWatchers,
+	logger logging.Logger) (*service, error) {
 	return &service{
-		repository: lotStorage,
-		logger:     logger,
+		repository:    lotStorage,
+		organizations: organizations,
+		addresses:     addresses,
+		estateTypes:   estateTypes,
+		rates:         rates,
+		stays:         stays,
+		versions:      versions,
+		duplicates:    duplicates,
+		watchers:      watchers,
+		logger:        logger,
+		stats:         make(map[string]*lot.Stats),
 	}, nil
 }
 
 func (s *service) Create(ctx context.Context, dto *lot.CreateLotDTO) (uint, error) {
+	estateTypes, err := KnownEstateTypes(ctx, s.estateTypes)
+	if err != nil {
+		return 0, err
+	}
+	rates, err := KnownRates(ctx, s.rates)
+	if err != nil {
+		return 0, err
+	}
 	lot := lot.NewLot(dto)
 	s.logger.Debug("validating lot fields...")
-	if err := lot.ValidateFields(); err != nil {
+	if err = lot.ValidateFields(estateTypes, rates.Codes()); err != nil {
 		return 0, err
 	}
+	if lot.OrganizationID != nil {
+		if _, err := s.findMember(ctx, *lot.OrganizationID, lot.CreatedByUserID); err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return 0, apperror.ForbiddenError("only members of the organization can create its lots")
+			}
+			return 0, err
+		}
+	}
+
+	if s.addresses != nil {
+		if err := s.addresses.Resolve(ctx, lot); err != nil {
+			var appErr *apperror.AppError
+			if errors.As(err, &appErr) {
+				return 0, err
+			}
+			// the lot is resolved again in background
+			s.logger.Warnf("failed to resolve address of new lot. error: %v", err)
+			lot.CityID, lot.DistrictID = nil, nil
+		}
+	}
 
 	s.logger.Debug("creating new lot..")
-	userID, err := s.repository.Create(ctx, lot)
+	userID, err := s.repository.Create(ctx, lot, history.NewRecord(history.ActionCreate, lot.CreatedByUserID, nil, lot))
 	if err != nil {
 		if errors.Is(err, apperror.ErrNotFound) {
 			return 0, err
 		}
 		return 0, fmt.Errorf("failed to create lot. error: %w", err)
 	}
+	lot.ID = userID
+	if s.duplicates != nil {
+		if err = s.duplicates.CheckLot(ctx, lot); err != nil {
+			// the lot is checked again by the detector
+			s.logger.Warnf("failed to check new lot %d for duplicates. error: %v", lot.ID, err)
+		}
+	}
 
 	return userID, nil
 
@@ -96,10 +248,21 @@ func (s *service) GetLotsWithFilter(ctx context.Context, query url.Values) ([]*l
 	if options, ok := ctx.Value(sort.OptionsContextKey).(sort.Options); ok {
 		so = &options
 	}
+	if so == nil {
+		so = &sort.Options{Field: sort.DefSort, Order: sort.DefOrder}
+	}
+	if !storage.SortAllowed(so.Field) {
+		return nil, apperror.BadRequestError(fmt.Sprintf("lots can't be sorted by %q", so.Field), "")
+	}
 
-	fo := getFiltersFromQuery(query)
-
-	options := storage.NewOptions(so, fo)
+	options, err := NewQueryOptions(so, query)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := s.withCurrency(ctx, options, query)
+	if err != nil {
+		return nil, err
+	}
 	s.logger.Debugf("GOT OPTIONS FOR DB: %v", options)
 
 	l, err = s.repository.FindWithFilter(ctx, options)
@@ -109,18 +272,125 @@ func (s *service) GetLotsWithFilter(ctx context.Context, query url.Values) ([]*l
 		}
 		return nil, fmt.Errorf("failed to find lots with filter. error: %w", err)
 	}
+	// stays are priced only for stays with both dates
+	if r, a := options.GetRental(), options.GetAvailability(); r != nil && r.Stay > 0 && s.stays != nil {
+		if err = s.stays.QuoteLots(ctx, l, r.Mode, a.From, a.To); err != nil {
+			return nil, err
+		}
+	}
+	if query.Get("currency") != "" {
+		convert(l, rates, options.GetCurrency())
+	}
 	return l, nil
 }
 
+// withCurrency sets currency from query to the options, prices are in lot.DefaultCurrency without it.
+func (s *service) withCurrency(ctx context.Context, options *storage.Options,
+	query url.Values) (*currency.Rates, error) {
+	rates, err := KnownRates(ctx, s.rates)
+	if err != nil {
+		return nil, err
+	}
+	code := strings.ToUpper(query.Get("currency"))
+	if code == "" {
+		return rates, nil
+	}
+	rate, ok := rates.Values[code]
+	if !ok {
+		return nil, apperror.BadRequestError(fmt.Sprintf("unknown currency %q", code), "")
+	}
+	options.WithCurrency(code, rate)
+	return rates, nil
+}
+
+// convert sets prices of lots and their stays in the currency, lots in currencies without rates are left
+// unconverted.
+func convert(lots []*lot.Lot, rates *currency.Rates, code string) {
+	for _, l := range lots {
+		amount, err := rates.Convert(float64(l.Price), l.Currency, code)
+		if err != nil {
+			continue
+		}
+		l.Converted = &lot.Price{Amount: int(math.Round(amount)), Currency: code}
+		if l.Stay != nil {
+			total, _ := rates.Convert(float64(l.Stay.Total), l.Currency, code)
+			l.Stay.Converted = &lot.Price{Amount: int(math.Round(total)), Currency: code}
+		}
+	}
+}
+
+func (s *service) GetStats(ctx context.Context, query url.Values) (*lot.Stats, error) {
+	days := DefStatsDays
+	if v := query.Get("days"); v != "" {
+		var err error
+		if days, err = strconv.Atoi(v); err != nil || days < 1 || days > MaxStatsDays {
+			return nil, apperror.BadRequestError(fmt.Sprintf("days must be from 1 to %d", MaxStatsDays), "")
+		}
+	}
+
+	options, err := NewQueryOptions(&sort.Options{Field: sort.DefSort, Order: sort.DefOrder}, query)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := s.withCurrency(ctx, options, query)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := s.repository.Version(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version of lots. error: %w", err)
+	}
+	// series of days moves at midnight, so stats of unchanged lots change too
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	key := statsKey(query, days)
+	tag := statsETag(key, today, v, rates.UpdatedAt)
+
+	s.mu.Lock()
+	st, ok := s.stats[key]
+	s.mu.Unlock()
+	if ok && st.ETag == tag {
+		return st, nil
+	}
+
+	since := today.AddDate(0, 0, 1-days)
+	st, err = s.repository.Stats(ctx, options, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats of lots. error: %w", err)
+	}
+	st.NewPerDay = fillDays(st.NewPerDay, since, days)
+	roundStats(st)
+	st.ETag = tag
+
+	s.mu.Lock()
+	if _, ok = s.stats[key]; !ok && len(s.stats) >= maxCachedStats {
+		for k := range s.stats {
+			delete(s.stats, k)
+			break
+		}
+	}
+	s.stats[key] = st
+	s.mu.Unlock()
+	return st, nil
+}
+
 func (s *service) Update(ctx context.Context, dto *lot.UpdateLotDTO) error {
 	s.logger.Debug("validating DTO fields..")
 	if err := dto.ValidateFields(); err != nil {
 		return err
 	}
 
+	before, err := s.FindForChange(ctx, dto.ID, dto.CreatedByUserID)
+	if err != nil {
+		return err
+	}
+
 	updatedLot := lot.UpdatedLot(dto)
+	after := *before
+	after.Price = updatedLot.Price
+	record := history.NewRecord(history.ActionUpdate, dto.CreatedByUserID, before, &after)
 
-	err := s.repository.Update(ctx, updatedLot)
+	err = s.repository.Update(ctx, updatedLot, record)
 
 	if err != nil {
 		if errors.Is(err, apperror.ErrNotFound) {
@@ -128,12 +398,19 @@ func (s *service) Update(ctx context.Context, dto *lot.UpdateLotDTO) error {
 		}
 		return fmt.Errorf("failed to update lot. error: %w", err)
 	}
+	if s.watchers != nil {
+		s.watchers.NotifyPriceDrop(ctx, before, &after)
+	}
 	return nil
 
 }
 
 func (s *service) Delete(ctx context.Context, lotID, userID uint) error {
-	err := s.repository.Delete(ctx, lotID, userID)
+	if _, err := s.FindForChange(ctx, lotID, userID); err != nil {
+		return err
+	}
+
+	err := s.repository.Delete(ctx, lotID)
 
 	if err != nil {
 		if errors.Is(err, apperror.ErrNotFound) {
@@ -144,6 +421,208 @@ func (s *service) Delete(ctx context.Context, lotID, userID uint) error {
 	return nil
 }
 
+func (s *service) Transfer(ctx context.Context, dto *lot.TransferLotDTO) (*lot.Lot, error) {
+	s.logger.Debug("validating DTO fields..")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	l, err := s.FindForChange(ctx, dto.ID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if l.OrganizationID == nil {
+		return nil, apperror.BadRequestError("only lots of organizations can be transferred", "")
+	}
+	if _, err = s.findMember(ctx, *l.OrganizationID, dto.AgentID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, apperror.BadRequestError("the agent must be a member of the organization", "")
+		}
+		return nil, err
+	}
+
+	before := *l
+	l.CreatedByUserID = dto.AgentID
+	record := history.NewRecord(history.ActionTransfer, dto.UserID, &before, l)
+	if err = s.repository.UpdateAgent(ctx, l.ID, dto.AgentID, record); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to transfer lot. error: %w", err)
+	}
+	return l, nil
+}
+
+func (s *service) GetHistory(ctx context.Context, dto *history.GetDTO) ([]*history.Version, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	if _, err := s.findForHistory(ctx, dto.LotID, dto.UserID, dto.Moderator); err != nil {
+		return nil, err
+	}
+	if s.versions == nil {
+		return make([]*history.Version, 0), nil
+	}
+
+	versions, err := s.versions.FindByLotID(ctx, dto.LotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find versions of lot. error: %w", err)
+	}
+	return versions, nil
+}
+
+func (s *service) Revert(ctx context.Context, dto *history.RevertDTO) (*lot.Lot, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	before, err := s.findForHistory(ctx, dto.LotID, dto.UserID, dto.Moderator)
+	if err != nil {
+		return nil, err
+	}
+	if s.versions == nil {
+		return nil, apperror.ErrNotFound
+	}
+	v, err := s.versions.Find(ctx, dto.LotID, dto.Number)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find version of lot. error: %w", err)
+	}
+
+	l := *before
+	v.Snapshot.Restore(&l)
+	record := history.NewRecord(history.ActionRevert, dto.UserID, before, &l)
+	if record == nil {
+		return before, nil
+	}
+	record.Version.RevertedTo = &v.Number
+
+	// estate types and currencies of old versions may be unknown already
+	estateTypes, err := KnownEstateTypes(ctx, s.estateTypes)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := KnownRates(ctx, s.rates)
+	if err != nil {
+		return nil, err
+	}
+	if err = l.ValidateFields(estateTypes, rates.Codes()); err != nil {
+		return nil, apperror.BadRequestError(fmt.Sprintf("version %d can't be restored: %v", v.Number, err), "")
+	}
+	if l.CityID == nil && s.addresses != nil {
+		if err = s.addresses.Resolve(ctx, &l); err != nil {
+			var appErr *apperror.AppError
+			if errors.As(err, &appErr) {
+				return nil, apperror.BadRequestError(fmt.Sprintf("version %d can't be restored: %v", v.Number, err), "")
+			}
+			// the lot is resolved again in background
+			s.logger.Warnf("failed to resolve address of reverted lot. error: %v", err)
+			l.CityID, l.DistrictID = nil, nil
+		}
+	}
+
+	if err = s.repository.Restore(ctx, &l, record); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to revert lot. error: %w", err)
+	}
+	return &l, nil
+}
+
+// findForHistory returns the lot, if the user can change it or is a moderator.
+func (s *service) findForHistory(ctx context.Context, lotID, userID uint, moderator bool) (*lot.Lot, error) {
+	if !moderator {
+		return s.FindForChange(ctx, lotID, userID)
+	}
+	l, err := s.repository.FindByLotID(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot by its id. error: %w", err)
+	}
+	return l, nil
+}
+
+func (s *service) FindForChange(ctx context.Context, lotID, userID uint) (*lot.Lot, error) {
+	return FindForChange(ctx, s.repository, s.organizations, lotID, userID)
+}
+
+// FindForChange returns the lot, if the user is its agent or manages lots of its organization. Services, which
+// change lots, check permissions with it.
+func FindForChange(ctx context.Context, lots storage.Repository, organizations organizationStorage.Repository,
+	lotID, userID uint) (*lot.Lot, error) {
+	l, err := lots.FindByLotID(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot by its id. error: %w", err)
+	}
+	ok, err := CanChange(ctx, organizations, l, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperror.ForbiddenError("only the agent of the lot or managers of its organization can change it")
+	}
+	return l, nil
+}
+
+// CanChange reports whether the user is the agent of the lot or manages lots of its organization.
+func CanChange(ctx context.Context, organizations organizationStorage.Repository, l *lot.Lot,
+	userID uint) (bool, error) {
+	if l.CreatedByUserID == userID {
+		return true, nil
+	}
+	if l.OrganizationID == nil || organizations == nil {
+		return false, nil
+	}
+	m, err := organizations.FindMember(ctx, *l.OrganizationID, userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to find member of organization. error: %w", err)
+	}
+	return m.Role.CanManageLots(), nil
+}
+
+func (s *service) findMember(ctx context.Context, organizationID, userID uint) (*organization.Member, error) {
+	m, err := s.organizations.FindMember(ctx, organizationID, userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find member of organization. error: %w", err)
+	}
+	return m, nil
+}
+
+// NewQueryOptions parses filters, availability and rental mode of lots from query,
+// e.g. city=Москва&rooms=gte:2&price=lte:50000.
+func NewQueryOptions(so *sort.Options, query url.Values) (*storage.Options, error) {
+	fo := getFiltersFromQuery(query)
+
+	availability, err := getAvailabilityFromQuery(query)
+	if err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	r, err := getRentalFromQuery(query, availability)
+	if err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	group := false
+	if v := query.Get("group_duplicates"); v != "" {
+		if group, err = strconv.ParseBool(v); err != nil {
+			return nil, apperror.BadRequestError("group_duplicates must be a boolean", "")
+		}
+	}
+	return storage.NewOptions(so, fo).WithAvailability(availability).WithRental(r).WithGroupedDuplicates(group), nil
+}
+
 func getFiltersFromQuery(query url.Values) *filter.Options {
 	fo := filter.NewOptions(make(map[string][]filter.Field))
 
@@ -176,3 +655,103 @@ func getFiltersFromQuery(query url.Values) *filter.Options {
 	}
 	return fo
 }
+
+// getAvailabilityFromQuery reads available_from=YYYY-MM-DD (free for at least a night since the day)
+// or available_between=YYYY-MM-DD:YYYY-MM-DD (free for the whole stay, the last day is check out).
+func getAvailabilityFromQuery(query url.Values) (*storage.Availability, error) {
+	if v := query.Get("available_between"); v != "" {
+		split := strings.Split(v, ":")
+		if len(split) != 2 {
+			return nil, errors.New("available_between must be in format YYYY-MM-DD:YYYY-MM-DD")
+		}
+		from, to, err := calendar.ParsePeriod(split[0], split[1])
+		if err != nil {
+			return nil, fmt.Errorf("wrong available_between: %w", err)
+		}
+		return &storage.Availability{From: from, To: to}, nil
+	}
+
+	if v := query.Get("available_from"); v != "" {
+		from, err := time.Parse(calendar.DateLayout, v)
+		if err != nil {
+			return nil, fmt.Errorf("wrong available_from: %w", err)
+		}
+		return &storage.Availability{From: from, To: from.AddDate(0, 0, 1)}, nil
+	}
+	return nil, nil
+}
+
+// getRentalFromQuery reads rental_mode=long_term or rental_mode=short_term. Minimal stays of lots are checked
+// for stays of available_between only, since available_from doesn't tell the length of stay.
+func getRentalFromQuery(query url.Values, availability *storage.Availability) (*storage.Rental, error) {
+	mode := query.Get("rental_mode")
+	if mode == "" {
+		return nil, nil
+	}
+	if mode != rental.ModeLongTerm && mode != rental.ModeShortTerm {
+		return nil, fmt.Errorf("rental_mode must be %s or %s", rental.ModeLongTerm, rental.ModeShortTerm)
+	}
+	r := &storage.Rental{Mode: mode, Period: rental.PeriodOfMode(mode)}
+	if availability != nil && query.Get("available_between") != "" {
+		r.Stay = rental.StayLength(mode, availability.From, availability.To)
+		if r.Stay == 0 {
+			return nil, errors.New("long-term stay must be at least a month")
+		}
+	}
+	return r, nil
+}
+
+// statsKey identifies stats by parameters of the query, which change them.
+func statsKey(query url.Values, days int) string {
+	params := url.Values{}
+	for name, values := range query {
+		if _, ok := storage.FilterDataType(name); ok || name == "available_from" || name == "available_between" ||
+			name == "group_duplicates" || name == "currency" || name == "rental_mode" {
+			params[name] = values
+		}
+	}
+	return fmt.Sprintf("%s|%d", params.Encode(), days)
+}
+
+// statsETag changes with selected lots and with rates, since prices of stats are converted at them.
+func statsETag(key string, today time.Time, v *storage.Version, ratesUpdatedAt time.Time) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%d|%d|%d",
+		key, today.Format(calendar.DateLayout), v.Count, v.LastID, v.ModifiedAt.Unix(), ratesUpdatedAt.Unix())))
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16]))
+}
+
+// fillDays adds days without new lots to the series, so it has all days since the given one.
+func fillDays(series []lot.DailyListings, since time.Time, days int) []lot.DailyListings {
+	counts := make(map[string]int, len(series))
+	for _, d := range series {
+		counts[d.Date] = d.Count
+	}
+	filled := make([]lot.DailyListings, 0, days)
+	for i := 0; i < days; i++ {
+		date := since.AddDate(0, 0, i).Format(calendar.DateLayout)
+		filled = append(filled, lot.DailyListings{Date: date, Count: counts[date]})
+	}
+	return filled
+}
+
+// roundStats rounds averages and prices per square meter to kopecks.
+func roundStats(st *lot.Stats) {
+	for _, p := range []*lot.PriceStats{&st.Price, &st.PricePerMeter} {
+		p.Min, p.Max, p.Avg, p.Median = round(p.Min), round(p.Max), round(p.Avg), round(p.Median)
+		for name, v := range p.Percentiles {
+			p.Percentiles[name] = round(v)
+		}
+	}
+	for i := range st.ByRooms {
+		st.ByRooms[i].AvgPrice = round(st.ByRooms[i].AvgPrice)
+		st.ByRooms[i].AvgPricePerMeter = round(st.ByRooms[i].AvgPricePerMeter)
+	}
+	for i := range st.ByDistrict {
+		st.ByDistrict[i].AvgPrice = round(st.ByDistrict[i].AvgPrice)
+		st.ByDistrict[i].AvgPricePerMeter = round(st.ByDistrict[i].AvgPricePerMeter)
+	}
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
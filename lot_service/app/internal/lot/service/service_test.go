@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
+	historyStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/history/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/currency"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	storage.Repository
+	lot      *lot.Lot
+	agent    uint
+	version  storage.Version
+	stats    int
+	options  storage.QueryOptions
+	versions *versions
+}
+
+func (r *repo) FindByLotID(_ context.Context, _ uint) (*lot.Lot, error) {
+	copied := *r.lot
+	return &copied, nil
+}
+
+func (r *repo) Update(_ context.Context, l *lot.Lot, record *history.Record) error {
+	r.lot.Price = l.Price
+	r.versions.save(record)
+	return nil
+}
+
+func (r *repo) Restore(_ context.Context, l *lot.Lot, record *history.Record) error {
+	restored := *l
+	r.lot = &restored
+	r.versions.save(record)
+	return nil
+}
+
+func (r *repo) UpdateAgent(_ context.Context, _, userID uint, record *history.Record) error {
+	r.agent = userID
+	r.versions.save(record)
+	return nil
+}
+
+func (r *repo) Version(_ context.Context, _ storage.QueryOptions) (*storage.Version, error) {
+	v := r.version
+	return &v, nil
+}
+
+func (r *repo) Stats(_ context.Context, _ storage.QueryOptions, since time.Time) (*lot.Stats, error) {
+	r.stats++
+	return &lot.Stats{
+		Count:     2,
+		Price:     lot.PriceStats{Avg: 100.0 / 3, Percentiles: map[string]float64{"p50": 30}},
+		NewPerDay: []lot.DailyListings{{Date: since.AddDate(0, 0, 1).Format("2006-01-02"), Count: 2}},
+	}, nil
+}
+
+func (r *repo) FindWithFilter(_ context.Context, options storage.QueryOptions) ([]*lot.Lot, error) {
+	r.options = options
+	return []*lot.Lot{
+		{ID: 1, Price: 9000, Currency: "RUB"},
+		{ID: 2, Price: 100, Currency: "USD"},
+		{ID: 3, Price: 100, Currency: "GBP"},
+	}, nil
+}
+
+type rates struct{}
+
+func (rates) Rates(_ context.Context) (*currency.Rates, error) {
+	return &currency.Rates{Base: "RUB", Values: map[string]float64{"RUB": 1, "USD": 90}}, nil
+}
+
+type stays struct {
+	mode   string
+	nights int
+}
+
+func (q *stays) QuoteLots(_ context.Context, lots []*lot.Lot, mode string, checkIn, checkOut time.Time) error {
+	q.mode, q.nights = mode, int(checkOut.Sub(checkIn).Hours()/24)
+	for _, l := range lots {
+		l.Stay = &lot.Stay{Mode: mode, Total: l.Price * q.nights}
+	}
+	return nil
+}
+
+type versions struct {
+	historyStorage.Repository
+	saved []*history.Version
+}
+
+// save saves version of the record like the repository of lots in transaction of the change.
+func (v *versions) save(record *history.Record) {
+	if v == nil || record == nil {
+		return
+	}
+	if len(v.saved) == 0 && record.Initial != nil {
+		record.Initial.Number = 1
+		v.saved = append(v.saved, record.Initial)
+	}
+	record.Version.Number = uint(len(v.saved) + 1)
+	v.saved = append(v.saved, record.Version)
+}
+
+func (v *versions) Find(_ context.Context, _, number uint) (*history.Version, error) {
+	if number == 0 || int(number) > len(v.saved) {
+		return nil, apperror.ErrNotFound
+	}
+	return v.saved[number-1], nil
+}
+
+type watchers struct {
+	drops [][2]int
+}
+
+func (w *watchers) NotifyPriceDrop(_ context.Context, before, after *lot.Lot) {
+	w.drops = append(w.drops, [2]int{before.Price, after.Price})
+}
+
+type organizations struct {
+	organizationStorage.Repository
+	roles map[uint]organization.Role
+}
+
+func (o *organizations) FindMember(_ context.Context, organizationID, userID uint) (*organization.Member, error) {
+	role, ok := o.roles[userID]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return &organization.Member{OrganizationID: organizationID, UserID: userID, Role: role}, nil
+}
+
+func TestTransfer(t *testing.T) {
+	organizationID := uint(1)
+	members := &organizations{roles: map[uint]organization.Role{
+		10: organization.RoleManager,
+		11: organization.RoleAgent,
+		12: organization.RoleAgent,
+	}}
+
+	tests := []struct {
+		name    string
+		lot     *lot.Lot
+		userID  uint
+		agentID uint
+		want    error
+	}{
+		{name: "manager", lot: &lot.Lot{ID: 1, CreatedByUserID: 11, OrganizationID: &organizationID}, userID: 10, agentID: 12},
+		{name: "agent of the lot", lot: &lot.Lot{ID: 1, CreatedByUserID: 11, OrganizationID: &organizationID}, userID: 11, agentID: 12},
+		{
+			name: "other agent", lot: &lot.Lot{ID: 1, CreatedByUserID: 11, OrganizationID: &organizationID}, userID: 12, agentID: 12,
+			want: apperror.ForbiddenError(""),
+		},
+		{
+			name: "not a member", lot: &lot.Lot{ID: 1, CreatedByUserID: 11, OrganizationID: &organizationID}, userID: 10, agentID: 20,
+			want: apperror.BadRequestError("", ""),
+		},
+		{
+			name: "private lot", lot: &lot.Lot{ID: 1, CreatedByUserID: 11}, userID: 11, agentID: 12,
+			want: apperror.BadRequestError("", ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &repo{lot: tt.lot}
+			s, _ := NewService(r, members, nil, nil, nil, nil, nil, nil, nil, logging.GetLogger())
+
+			l, err := s.Transfer(context.Background(), &lot.TransferLotDTO{ID: 1, UserID: tt.userID, AgentID: tt.agentID})
+			if tt.want != nil {
+				if !sameError(err, tt.want) {
+					t.Fatalf("expected %v, got %v", tt.want, err)
+				}
+				if r.agent != 0 {
+					t.Error("lot must not be transferred")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.agent != tt.agentID || l.CreatedByUserID != tt.agentID {
+				t.Errorf("expected lot of agent %d, got %d", tt.agentID, r.agent)
+			}
+		})
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	r := &repo{version: storage.Version{Count: 2, LastID: 5}}
+	s, _ := NewService(r, nil, nil, nil, nil, nil, nil, nil, nil, logging.GetLogger())
+	ctx := context.Background()
+
+	if _, err := s.GetStats(ctx, url.Values{"days": {"0"}}); !sameError(err, apperror.BadRequestError("", "")) {
+		t.Errorf("expected bad request for zero days, got %v", err)
+	}
+
+	query := url.Values{"days": {"7"}, "district": {"Арбат"}, "sort_by": {"price"}}
+	st, err := s.GetStats(ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.NewPerDay) != 7 || st.NewPerDay[1].Count != 2 || st.NewPerDay[0].Count != 0 {
+		t.Errorf("expected 7 days with new lots on the second one, got %v", st.NewPerDay)
+	}
+	if st.Price.Avg != 33.33 || st.ETag == "" {
+		t.Errorf("expected rounded average and etag, got %v and %q", st.Price.Avg, st.ETag)
+	}
+
+	// sorting doesn't change stats
+	query.Set("sort_by", "area")
+	if again, _ := s.GetStats(ctx, query); r.stats != 1 || again.ETag != st.ETag {
+		t.Errorf("stats of unchanged lots must not be computed again, computed %d times", r.stats)
+	}
+
+	r.version.LastID = 6
+	if changed, _ := s.GetStats(ctx, query); r.stats != 2 || changed.ETag == st.ETag {
+		t.Errorf("stats must be computed again after new lot, computed %d times", r.stats)
+	}
+}
+
+func TestGetLotsWithFilterCurrency(t *testing.T) {
+	r := &repo{}
+	s, _ := NewService(r, nil, nil, nil, rates{}, nil, nil, nil, nil, logging.GetLogger())
+	ctx := context.Background()
+
+	lots, err := s.GetLotsWithFilter(ctx, url.Values{"currency": {"usd"}, "price": {"lte:200"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.options.GetCurrency() != "USD" || r.options.GetCurrencyRate() != 90 {
+		t.Errorf("expected filters in USD at 90, got %s at %v", r.options.GetCurrency(), r.options.GetCurrencyRate())
+	}
+	if c := lots[0].Converted; c == nil || c.Amount != 100 || c.Currency != "USD" {
+		t.Errorf("expected 9000 RUB converted to 100 USD, got %+v", c)
+	}
+	if c := lots[1].Converted; c == nil || c.Amount != 100 {
+		t.Errorf("expected 100 USD kept, got %+v", c)
+	}
+	if lots[2].Converted != nil {
+		t.Errorf("price in currency without rate must not be converted, got %+v", lots[2].Converted)
+	}
+
+	if lots, _ = s.GetLotsWithFilter(ctx, url.Values{}); lots[0].Converted != nil {
+		t.Errorf("prices must not be converted without currency")
+	}
+	if _, err = s.GetLotsWithFilter(ctx, url.Values{"currency": {"GBP"}}); !sameError(err, apperror.BadRequestError("", "")) {
+		t.Errorf("expected bad request for unknown currency, got %v", err)
+	}
+}
+
+func TestGetLotsWithFilterRental(t *testing.T) {
+	r := &repo{}
+	q := &stays{}
+	s, _ := NewService(r, nil, nil, nil, rates{}, q, nil, nil, nil, logging.GetLogger())
+	ctx := context.Background()
+
+	query := url.Values{"rental_mode": {"short_term"}, "available_between": {"2026-11-01:2026-11-04"}, "currency": {"USD"}}
+	lots, err := s.GetLotsWithFilter(ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rent := r.options.GetRental(); rent == nil || rent.Stay != 3 || rent.Period != lot.PeriodDay {
+		t.Errorf("expected short-term rent for 3 nights, got %+v", rent)
+	}
+	if q.mode != "short_term" || q.nights != 3 {
+		t.Errorf("expected stay of 3 nights to be priced, got %s for %d nights", q.mode, q.nights)
+	}
+	if c := lots[0].Stay.Converted; c == nil || c.Amount != 300 {
+		t.Errorf("expected 27000 RUB of the stay converted to 300 USD, got %+v", c)
+	}
+
+	// available_from doesn't tell length of the stay
+	q.mode = ""
+	if _, err = s.GetLotsWithFilter(ctx, url.Values{"rental_mode": {"short_term"}, "available_from": {"2026-11-01"}}); err != nil {
+		t.Fatal(err)
+	}
+	if q.mode != "" || r.options.GetRental().Stay != 0 {
+		t.Errorf("stay without check out must not be priced")
+	}
+
+	for _, query = range []url.Values{
+		{"rental_mode": {"hourly"}},
+		{"rental_mode": {"long_term"}, "available_between": {"2026-11-01:2026-11-20"}},
+	} {
+		if _, err = s.GetLotsWithFilter(ctx, query); !sameError(err, apperror.BadRequestError("", "")) {
+			t.Errorf("expected bad request for %v, got %v", query, err)
+		}
+	}
+}
+
+// sameError compares app errors by code, since their messages differ.
+func sameError(err, want error) bool {
+	var got, expected *apperror.AppError
+	if !errors.As(err, &got) || !errors.As(want, &expected) {
+		return false
+	}
+	return got.Code == expected.Code
+}
+
+func TestRevert(t *testing.T) {
+	r := &repo{lot: &lot.Lot{ID: 1, CreatedByUserID: 11, TypeOfEstate: "квартира", Rooms: 2, Area: 50, Floor: 3,
+		MaxFloor: 9, City: "Москва", District: "ЦАО", Street: "Тверская", Building: "1", Price: 50000,
+		Currency: "RUB", PricePeriod: lot.PeriodMonth}}
+	v := &versions{}
+	r.versions = v
+	s, _ := NewService(r, nil, nil, nil, nil, nil, v, nil, nil, logging.GetLogger())
+	ctx := context.Background()
+
+	if err := s.Update(ctx, &lot.UpdateLotDTO{ID: 1, CreatedByUserID: 11, Price: 60000}); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.saved) != 2 || v.saved[0].Action != history.ActionCreate || len(v.saved[1].Changes) != 1 {
+		t.Fatalf("expected initial version and version with new price, got %+v", v.saved)
+	}
+
+	_, err := s.Revert(ctx, &history.RevertDTO{LotID: 1, Number: 1, UserID: 12})
+	if !sameError(err, apperror.ForbiddenError("")) {
+		t.Fatalf("expected forbidden for stranger, got %v", err)
+	}
+
+	l, err := s.Revert(ctx, &history.RevertDTO{LotID: 1, Number: 1, UserID: 99, Moderator: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reverted := v.saved[len(v.saved)-1]
+	if l.Price != 50000 || r.lot.Price != 50000 || reverted.ActorID != 99 || *reverted.RevertedTo != 1 {
+		t.Errorf("expected lot reverted by moderator to the first price, got %+v and %+v", l, reverted)
+	}
+
+	if _, err = s.Revert(ctx, &history.RevertDTO{LotID: 1, Number: 3, UserID: 11}); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.saved) != 3 {
+		t.Errorf("revert to the current state must not make a version, got %d versions", len(v.saved))
+	}
+}
+
+func TestUpdateNotifiesWatchers(t *testing.T) {
+	r := &repo{lot: &lot.Lot{ID: 1, CreatedByUserID: 11, Price: 50000, Currency: "RUB"}}
+	w := &watchers{}
+	s, _ := NewService(r, nil, nil, nil, nil, nil, nil, nil, w, logging.GetLogger())
+
+	if err := s.Update(context.Background(), &lot.UpdateLotDTO{ID: 1, CreatedByUserID: 11, Price: 45000}); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.drops) != 1 || w.drops[0] != [2]int{50000, 45000} {
+		t.Errorf("expected watchers notified of price 50000 changed to 45000, got %v", w.drops)
+	}
+}
@@ -2,19 +2,45 @@ package storage
 
 import (
 	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
 	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"time"
 )
 
+// Repository of lots. Changes of lots save their versions from record in the same transaction,
+// nil record saves no version.
 type Repository interface {
-	Create(ctx context.Context, lot *lot.Lot) (uint, error)
+	// Create saves new lot and sets ID of the lot to version of the record.
+	Create(ctx context.Context, lot *lot.Lot, record *history.Record) (uint, error)
 	FindByLotID(ctx context.Context, id uint) (*lot.Lot, error)
 	FindByUserID(ctx context.Context, id uint) ([]*lot.Lot, error)
+	FindByOrganizationID(ctx context.Context, id uint) ([]*lot.Lot, error)
 	FindWithFilter(ctx context.Context, options QueryOptions) ([]*lot.Lot, error)
-	Update(ctx context.Context, lot *lot.Lot) error
-	Delete(ctx context.Context, lotID, userID uint) error
+	// Version returns version of lots selected by options, limit and order of options are ignored.
+	Version(ctx context.Context, options QueryOptions) (*Version, error)
+	// Stats aggregates lots selected by options, new lots are counted by days since the given time.
+	Stats(ctx context.Context, options QueryOptions, since time.Time) (*lot.Stats, error)
+	Update(ctx context.Context, lot *lot.Lot, record *history.Record) error
+	// Restore sets description, address, price and location of the lot, its agent and organization are kept.
+	Restore(ctx context.Context, lot *lot.Lot, record *history.Record) error
+	// UpdateAgent assigns the lot to another user.
+	UpdateAgent(ctx context.Context, lotID, userID uint, record *history.Record) error
+	Delete(ctx context.Context, lotID uint) error
 }
 
 type QueryOptions interface {
 	GetOrderBy() string
 	GetFilters() map[string][]FilterOption
+	// GetAvailability returns period, when the lots must be free, or nil.
+	GetAvailability() *Availability
+	// GetRental returns rental mode and stay, lots must be rented for, or nil.
+	GetRental() *Rental
+	// GetGroupDuplicates tells, whether lots flagged as duplicates of other lots are left out.
+	GetGroupDuplicates() bool
+	// GetLimit returns maximum number of lots to select, zero means no limit.
+	GetLimit() int
+	// GetCurrency returns currency of prices in filters and stats.
+	GetCurrency() string
+	// GetCurrencyRate returns value of unit of the currency in lot.DefaultCurrency.
+	GetCurrencyRate() float64
 }
@@ -2,19 +2,40 @@ package storage
 
 import (
 	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/api/filter"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/api/sort"
+	"time"
 )
 
 var _ QueryOptions = &Options{}
 
 var allowedFilters = map[string]string{
-	"estate_type": "string",
-	"rooms":       "int",
-	"district":    "string",
-	"price":       "int",
-	"created_at":  "date",
-	"floor":       "int",
+	"estate_type":  "string",
+	"city":         "string",
+	"city_id":      "int",
+	"district_id":  "int",
+	"rooms":        "int",
+	"district":     "string",
+	"price":        "int", // in currency of options
+	"price_period": "string",
+	"created_at":   "date",
+	"floor":        "int",
+}
+
+// allowedSorts are fields lots can be ordered by, ratings are aggregated over visible reviews.
+var allowedSorts = map[string]bool{
+	"created_at":      true,
+	"price":           true,
+	"area":            true,
+	"rooms":           true,
+	"floor":           true,
+	"rating":          true,
+	"landlord_rating": true,
+}
+
+func SortAllowed(field string) bool {
+	return allowedSorts[field]
 }
 
 func FilterDataType(fltr string) (string, bool) {
@@ -23,9 +44,36 @@ func FilterDataType(fltr string) (string, bool) {
 }
 
 type Options struct {
-	sortField string
-	sortOrder string
-	fo        map[string][]FilterOption
+	sortField       string
+	sortOrder       string
+	fo              map[string][]FilterOption
+	availability    *Availability
+	groupDuplicates bool
+	limit           int
+	currency        string
+	rate            float64
+	rental          *Rental
+}
+
+// Version identifies state of lots selected by options. It changes, when lot is added to the selection,
+// changed or leaves the selection.
+type Version struct {
+	Count      int
+	LastID     uint
+	ModifiedAt time.Time // of the lot changed last, zero for empty selection
+}
+
+// Availability is a period without accepted bookings and calendar blocks. End day is not included.
+type Availability struct {
+	From time.Time
+	To   time.Time
+}
+
+// Rental selects lots rented in the mode. Lots without rental modes are rented in the mode of their price period.
+type Rental struct {
+	Mode   string
+	Period string // price period of lots without rental modes, which are rented in the mode
+	Stay   int    // nights or months of the stay, lots with longer minimal stay are left out, zero for any stay
 }
 
 type FilterOption struct {
@@ -55,10 +103,77 @@ func NewOptions(so *sort.Options, fo *filter.Options) *Options {
 	}
 }
 
+// GetOrderBy orders lots by price in lot.DefaultCurrency, since prices are in different currencies.
 func (o *Options) GetOrderBy() string {
-	return fmt.Sprintf("%s %s", o.sortField, o.sortOrder)
+	field := o.sortField
+	if field == "price" {
+		field = "price_base"
+	}
+	return fmt.Sprintf("%s %s", field, o.sortOrder)
 }
 
 func (o *Options) GetFilters() map[string][]FilterOption {
 	return o.fo
 }
+
+// WithAvailability makes options select only lots, which are free during the period.
+func (o *Options) WithAvailability(a *Availability) *Options {
+	o.availability = a
+	return o
+}
+
+func (o *Options) GetAvailability() *Availability {
+	return o.availability
+}
+
+// WithGroupedDuplicates makes options leave out pending and confirmed duplicates, so only the original
+// of duplicates is selected.
+func (o *Options) WithGroupedDuplicates(group bool) *Options {
+	o.groupDuplicates = group
+	return o
+}
+
+func (o *Options) GetGroupDuplicates() bool {
+	return o.groupDuplicates
+}
+
+// WithRental makes options select only lots rented in the mode for the stay.
+func (o *Options) WithRental(r *Rental) *Options {
+	o.rental = r
+	return o
+}
+
+func (o *Options) GetRental() *Rental {
+	return o.rental
+}
+
+// WithLimit makes options select only first n lots, zero selects all of them.
+func (o *Options) WithLimit(n int) *Options {
+	o.limit = n
+	return o
+}
+
+func (o *Options) GetLimit() int {
+	return o.limit
+}
+
+// WithCurrency makes options filter prices in the currency, rate is value of its unit in lot.DefaultCurrency.
+func (o *Options) WithCurrency(code string, rate float64) *Options {
+	o.currency, o.rate = code, rate
+	return o
+}
+
+// GetCurrency returns currency of prices of filters, it's lot.DefaultCurrency by default.
+func (o *Options) GetCurrency() string {
+	if o.currency == "" {
+		return lot.DefaultCurrency
+	}
+	return o.currency
+}
+
+func (o *Options) GetCurrencyRate() float64 {
+	if o.rate == 0 {
+		return 1
+	}
+	return o.rate
+}
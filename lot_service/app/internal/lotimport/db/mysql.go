@@ -0,0 +1,300 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"time"
+)
+
+var _ storage.Repository = &db{}
+
+// errDuplicateEntry is code of MySQL error on violation of unique key.
+const errDuplicateEntry = 1062
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func (s *db) CreateProfile(ctx context.Context, p *lotimport.Profile) (uint, error) {
+	mapping, err := json.Marshal(p.Mapping)
+	if err != nil {
+		return 0, err
+	}
+	res, err := s.db.ExecContext(ctx, `
+	INSERT INTO lot_import_profiles (owner_id, name, mapping, created_at)
+	VALUES (?, ?, ?, ?);`, p.OwnerID, p.Name, mapping, p.CreatedAt.UTC())
+	if err != nil {
+		var mysqlErr *driver.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+			return 0, apperror.ConflictError("profile with the name exists already")
+		}
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), nil
+}
+
+const profileColumns = `profile_id, owner_id, name, mapping, created_at`
+
+func scanProfile(row scanner) (*lotimport.Profile, error) {
+	p := &lotimport.Profile{}
+	var mapping []byte
+	var createdAt mysql.RawTime
+	if err := row.Scan(&p.ID, &p.OwnerID, &p.Name, &mapping, &createdAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mapping, &p.Mapping); err != nil {
+		return nil, err
+	}
+	var err error
+	if p.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *db) FindProfile(ctx context.Context, id uint) (*lotimport.Profile, error) {
+	p, err := scanProfile(s.db.QueryRowContext(ctx, `
+	SELECT `+profileColumns+`
+	FROM lot_import_profiles
+	WHERE profile_id=?;`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *db) FindProfiles(ctx context.Context, ownerID uint) ([]*lotimport.Profile, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT `+profileColumns+`
+	FROM lot_import_profiles
+	WHERE owner_id=?
+	ORDER BY name;`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make([]*lotimport.Profile, 0)
+	for rows.Next() {
+		p, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func (s *db) DeleteProfile(ctx context.Context, id, ownerID uint) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM lot_import_profiles WHERE profile_id=? AND owner_id=?;`,
+		id, ownerID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+func (s *db) CreateJob(ctx context.Context, j *lotimport.Job) (uint, error) {
+	mapping, err := json.Marshal(j.Mapping)
+	if err != nil {
+		return 0, err
+	}
+	res, err := s.db.ExecContext(ctx, `
+	INSERT INTO lot_import_jobs (user_id, organization_id, format, dry_run, mapping, status, file_key, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+		j.UserID, j.OrganizationID, j.Format, j.DryRun, mapping, j.Status, j.FileKey, j.CreatedAt.UTC())
+	if err != nil {
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), nil
+}
+
+const jobColumns = `
+	job_id, user_id, organization_id, format, dry_run, mapping, status, total_rows, valid_rows, created_rows,
+	errors, error, file_key, created_at, finished_at`
+
+func scanJob(row scanner) (*lotimport.Job, error) {
+	j := &lotimport.Job{}
+	var organizationID sql.NullInt64
+	var mapping, rowErrors []byte
+	var createdAt mysql.RawTime
+	var finishedAt *mysql.RawTime
+	err := row.Scan(&j.ID, &j.UserID, &organizationID, &j.Format, &j.DryRun, &mapping, &j.Status, &j.TotalRows,
+		&j.ValidRows, &j.CreatedRows, &rowErrors, &j.Error, &j.FileKey, &createdAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if organizationID.Valid {
+		id := uint(organizationID.Int64)
+		j.OrganizationID = &id
+	}
+	if err = json.Unmarshal(mapping, &j.Mapping); err != nil {
+		return nil, err
+	}
+	j.Errors = make([]lotimport.RowError, 0)
+	if rowErrors != nil {
+		if err = json.Unmarshal(rowErrors, &j.Errors); err != nil {
+			return nil, err
+		}
+	}
+	if j.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	if finishedAt != nil {
+		t, err := finishedAt.Time()
+		if err != nil {
+			return nil, err
+		}
+		j.FinishedAt = &t
+	}
+	return j, nil
+}
+
+func (s *db) FindJob(ctx context.Context, id uint) (*lotimport.Job, error) {
+	j, err := scanJob(s.db.QueryRowContext(ctx, `
+	SELECT`+jobColumns+`
+	FROM lot_import_jobs
+	WHERE job_id=?;`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return j, nil
+}
+
+func (s *db) FindJobs(ctx context.Context, userID uint, limit uint64) ([]*lotimport.Job, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT`+jobColumns+`
+	FROM lot_import_jobs
+	WHERE user_id=?
+	ORDER BY job_id DESC
+	LIMIT ?;`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]*lotimport.Job, 0)
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (s *db) ClaimJob(ctx context.Context) (*lotimport.Job, error) {
+	for {
+		var id uint
+		err := s.db.QueryRowContext(ctx, `
+		SELECT job_id
+		FROM lot_import_jobs
+		WHERE status=?
+		ORDER BY job_id
+		LIMIT 1;`, lotimport.StatusQueued).Scan(&id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		// other instance of the service may claim the job first
+		res, err := s.db.ExecContext(ctx, `UPDATE lot_import_jobs SET status=? WHERE job_id=? AND status=?;`,
+			lotimport.StatusRunning, id, lotimport.StatusQueued)
+		if err != nil {
+			return nil, err
+		}
+		rowsAff, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if rowsAff == 1 {
+			return s.FindJob(ctx, id)
+		}
+	}
+}
+
+func (s *db) FinishJob(ctx context.Context, j *lotimport.Job) error {
+	rowErrors, err := json.Marshal(j.Errors)
+	if err != nil {
+		return err
+	}
+	var finishedAt *time.Time
+	if j.FinishedAt != nil {
+		t := j.FinishedAt.UTC()
+		finishedAt = &t
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+	UPDATE lot_import_jobs
+	SET status=?, total_rows=?, valid_rows=?, created_rows=?, errors=?, error=?, file_key=?, finished_at=?
+	WHERE job_id=?;`, j.Status, j.TotalRows, j.ValidRows, j.CreatedRows, rowErrors, j.Error, j.FileKey,
+		finishedAt, j.ID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+func (s *db) RequeueRunning(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE lot_import_jobs SET status=? WHERE status=?;`,
+		lotimport.StatusQueued, lotimport.StatusRunning)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
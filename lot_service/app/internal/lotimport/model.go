@@ -0,0 +1,238 @@
+package lotimport
+
+import (
+	"errors"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/spreadsheet"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed" // the file can't be read, rows with errors don't fail the job
+)
+
+// MaxErrors limits number of row errors kept in the job.
+const MaxErrors = 1000
+
+// Fields are fields of lot.CreateLotDTO, which are filled from columns of spreadsheet.
+var Fields = []string{
+	"type_of_estate", "rooms", "area", "floor", "max_floor",
+	"city", "district", "street", "building", "price", "currency", "price_period",
+}
+
+// Mapping maps fields of lots to headers of columns. Fields without mapping are read from columns
+// named as the fields, if there are such columns. Mapping is validated along with DTOs containing it.
+type Mapping map[string]string
+
+func (m Mapping) Validate() error {
+	for field, column := range m {
+		if !knownField(field) {
+			return fmt.Errorf("unknown field %q", field)
+		}
+		if strings.TrimSpace(column) == "" {
+			return fmt.Errorf("column of field %q is empty", field)
+		}
+	}
+	return nil
+}
+
+// Merge returns mapping with fields of the other mapping replacing fields of m.
+func (m Mapping) Merge(other Mapping) Mapping {
+	merged := make(Mapping, len(m)+len(other))
+	for field, column := range m {
+		merged[field] = column
+	}
+	for field, column := range other {
+		merged[field] = column
+	}
+	return merged
+}
+
+// Columns returns index of column by field. Headers are compared ignoring case and spaces around.
+// Columns of mapped fields must be present in the header.
+func (m Mapping) Columns(header []string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		h = normalizeHeader(h)
+		if _, ok := index[h]; !ok && h != "" {
+			index[h] = i
+		}
+	}
+
+	columns := make(map[string]int, len(Fields))
+	for _, field := range Fields {
+		column, mapped := m[field]
+		if !mapped {
+			column = field
+		}
+		i, ok := index[normalizeHeader(column)]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("column %q of field %s is not found", column, field)
+			}
+			continue
+		}
+		columns[field] = i
+	}
+	return columns, nil
+}
+
+func normalizeHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(h))
+}
+
+func knownField(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Profile is mapping saved by the user for spreadsheets of the same layout.
+type Profile struct {
+	ID        uint      `json:"id"`
+	OwnerID   uint      `json:"owner_id"`
+	Name      string    `json:"name"`
+	Mapping   Mapping   `json:"mapping"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RowError is a problem of the row, which is not imported. Rows are numbered as in the spreadsheet,
+// the header is row 1.
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Job imports lots from spreadsheet in background. Valid rows are imported, invalid ones are reported.
+// Dry run only validates rows.
+type Job struct {
+	ID             uint       `json:"id"`
+	UserID         uint       `json:"user_id"`
+	OrganizationID *uint      `json:"organization_id,omitempty"` // lots are created for the organization
+	Format         string     `json:"format"`
+	DryRun         bool       `json:"dry_run"`
+	Mapping        Mapping    `json:"mapping"`
+	Status         string     `json:"status"`
+	TotalRows      int        `json:"total_rows"`
+	ValidRows      int        `json:"valid_rows"`
+	CreatedRows    int        `json:"created_rows"`
+	Errors         []RowError `json:"errors"` // at most MaxErrors
+	Error          string     `json:"error,omitempty"`
+	FileKey        string     `json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
+// AddError keeps the error, unless the job has too many errors already.
+func (j *Job) AddError(e RowError) {
+	if len(j.Errors) < MaxErrors {
+		j.Errors = append(j.Errors, e)
+	}
+}
+
+type CreateProfileDTO struct {
+	OwnerID uint    `json:"owner_id"`
+	Name    string  `json:"name"`
+	Mapping Mapping `json:"mapping"`
+}
+
+// CreateJobDTO describes uploaded spreadsheet. Mapping replaces fields of the profile, empty format
+// is detected by content of the file.
+type CreateJobDTO struct {
+	UserID         uint    `json:"user_id"`
+	OrganizationID uint    `json:"organization_id"`
+	Format         string  `json:"format"`
+	DryRun         bool    `json:"dry_run"`
+	ProfileID      uint    `json:"profile_id"`
+	Mapping        Mapping `json:"mapping"`
+}
+
+func (dto *CreateProfileDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.OwnerID, validation.Required),
+		validation.Field(&dto.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&dto.Mapping, validation.Required),
+	)
+}
+
+func (dto *CreateJobDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Format, validation.In(spreadsheet.FormatCSV, spreadsheet.FormatXLSX)),
+		validation.Field(&dto.Mapping),
+	)
+}
+
+// NewLotDTO fills lot fields from values of the row. It returns messages of fields, which can't be parsed.
+func NewLotDTO(columns map[string]int, values []string) (*lot.CreateLotDTO, map[string]string) {
+	get := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(values) {
+			return ""
+		}
+		return strings.TrimSpace(values[i])
+	}
+	problems := make(map[string]string)
+	getInt := func(field string) int {
+		n, err := parseInt(get(field))
+		if err != nil {
+			problems[field] = err.Error()
+		}
+		return n
+	}
+
+	dto := &lot.CreateLotDTO{
+		TypeOfEstate: strings.ToLower(get("type_of_estate")),
+		Rooms:        getInt("rooms"),
+		Area:         getInt("area"),
+		Floor:        getInt("floor"),
+		MaxFloor:     getInt("max_floor"),
+		City:         get("city"),
+		District:     get("district"),
+		Street:       get("street"),
+		Building:     get("building"),
+		Price:        getInt("price"),
+		Currency:     get("currency"),
+		PricePeriod:  strings.ToLower(get("price_period")),
+	}
+	return dto, problems
+}
+
+// parseInt reads whole numbers, which spreadsheets may format with spaces between thousands
+// or write as floats. Empty value is zero.
+func parseInt(s string) (int, error) {
+	s = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "").Replace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil || f != math.Trunc(f) || math.Abs(f) > math.MaxInt32 {
+		return 0, errors.New("must be a whole number")
+	}
+	return int(f), nil
+}
+
+// Blank reports whether the row has no values, such rows are skipped.
+func Blank(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport/storage"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/media"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/spreadsheet"
+	"io"
+	"sort"
+	"time"
+)
+
+var _ Service = &service{}
+
+// jobsLimit is number of the latest jobs shown to the user.
+const jobsLimit = 50
+
+// maxErrorLength fits message of failed job into its column.
+const maxErrorLength = 500
+
+type Service interface {
+	CreateProfile(ctx context.Context, dto *lotimport.CreateProfileDTO) (*lotimport.Profile, error)
+	GetProfiles(ctx context.Context, ownerID uint) ([]*lotimport.Profile, error)
+	DeleteProfile(ctx context.Context, id, ownerID uint) error
+
+	// CreateJob saves the spreadsheet and queues its import. Lots of organization can be imported by its members.
+	CreateJob(ctx context.Context, dto *lotimport.CreateJobDTO, data io.Reader) (*lotimport.Job, error)
+	GetJob(ctx context.Context, id, userID uint) (*lotimport.Job, error)
+	GetJobs(ctx context.Context, userID uint) ([]*lotimport.Job, error)
+	// ProcessNext imports the oldest queued job and notifies its user. It returns false, if there are no queued jobs.
+	ProcessNext(ctx context.Context) (bool, error)
+}
+
+type Config struct {
+	MaxFileSize int64
+	// MaxRows limits number of rows with values, files with more rows fail.
+	MaxRows int
+}
+
+type service struct {
+	repository    storage.Repository
+	lots          lotService.Service
+	organizations organizationStorage.Repository
+	media         media.Storage
+	events        eventService.Publisher
+	estateTypes   lotService.EstateTypes
+	rates         lotService.Rates
+	cfg           Config
+	logger        logging.Logger
+}
+
+// NewService returns service, which validates types of estate of lots with lot.DefaultEstateTypes,
+// if estateTypes is nil, and accepts prices in lot.DefaultCurrency only, if rates is nil. Lots are created
+// by lots, so they are versioned and checked for duplicates as lots created one by one.
+func NewService(importStorage storage.Repository, lots lotService.Service,
+	organizations organizationStorage.Repository, mediaStorage media.Storage, events eventService.Publisher,
+	estateTypes lotService.EstateTypes, rates lotService.Rates, cfg Config, logger logging.Logger) (*service, error) {
+	return &service{
+		repository:    importStorage,
+		lots:          lots,
+		organizations: organizations,
+		media:         mediaStorage,
+		events:        events,
+		estateTypes:   estateTypes,
+		rates:         rates,
+		cfg:           cfg,
+		logger:        logger,
+	}, nil
+}
+
+func (s *service) CreateProfile(ctx context.Context, dto *lotimport.CreateProfileDTO) (*lotimport.Profile, error) {
+	s.logger.Debug("validating profile fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	p := &lotimport.Profile{
+		OwnerID:   dto.OwnerID,
+		Name:      dto.Name,
+		Mapping:   dto.Mapping,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	var err error
+	p.ID, err = s.repository.CreateProfile(ctx, p)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create import profile. error: %w", err)
+	}
+	return p, nil
+}
+
+func (s *service) GetProfiles(ctx context.Context, ownerID uint) ([]*lotimport.Profile, error) {
+	profiles, err := s.repository.FindProfiles(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find import profiles. error: %w", err)
+	}
+	return profiles, nil
+}
+
+func (s *service) DeleteProfile(ctx context.Context, id, ownerID uint) error {
+	if err := s.repository.DeleteProfile(ctx, id, ownerID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete import profile. error: %w", err)
+	}
+	return nil
+}
+
+func (s *service) CreateJob(ctx context.Context, dto *lotimport.CreateJobDTO, data io.Reader) (*lotimport.Job, error) {
+	s.logger.Debug("validating import fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	mapping := lotimport.Mapping{}
+	if dto.ProfileID != 0 {
+		p, err := s.repository.FindProfile(ctx, dto.ProfileID)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return nil, apperror.BadRequestError("profile is not found", "")
+			}
+			return nil, fmt.Errorf("failed to find import profile. error: %w", err)
+		}
+		if p.OwnerID != dto.UserID {
+			return nil, apperror.BadRequestError("profile is not found", "")
+		}
+		mapping = p.Mapping
+	}
+	mapping = mapping.Merge(dto.Mapping)
+
+	var organizationID *uint
+	if dto.OrganizationID != 0 {
+		if _, err := s.organizations.FindMember(ctx, dto.OrganizationID, dto.UserID); err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return nil, apperror.ForbiddenError("only members of the organization can import its lots")
+			}
+			return nil, fmt.Errorf("failed to find member of organization. error: %w", err)
+		}
+		organizationID = &dto.OrganizationID
+	}
+
+	// one byte more than allowed tells that the file is too large
+	content, err := io.ReadAll(io.LimitReader(data, s.cfg.MaxFileSize+1))
+	if err != nil {
+		return nil, apperror.BadRequestError("failed to read file", err.Error())
+	}
+	if int64(len(content)) > s.cfg.MaxFileSize {
+		return nil, apperror.BadRequestError(fmt.Sprintf("file must not be larger than %d bytes", s.cfg.MaxFileSize), "")
+	}
+	if len(content) == 0 {
+		return nil, apperror.BadRequestError("file is empty", "")
+	}
+	format := dto.Format
+	if format == "" {
+		format = spreadsheet.DetectFormat(content)
+	}
+
+	key, err := s.media.Save(ctx, bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save imported file. error: %w", err)
+	}
+
+	j := &lotimport.Job{
+		UserID:         dto.UserID,
+		OrganizationID: organizationID,
+		Format:         format,
+		DryRun:         dto.DryRun,
+		Mapping:        mapping,
+		Status:         lotimport.StatusQueued,
+		Errors:         make([]lotimport.RowError, 0),
+		FileKey:        key,
+		CreatedAt:      time.Now().UTC().Truncate(time.Second),
+	}
+	j.ID, err = s.repository.CreateJob(ctx, j)
+	if err != nil {
+		s.deleteFile(ctx, key)
+		return nil, fmt.Errorf("failed to create import job. error: %w", err)
+	}
+	return j, nil
+}
+
+func (s *service) GetJob(ctx context.Context, id, userID uint) (*lotimport.Job, error) {
+	j, err := s.repository.FindJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find import job. error: %w", err)
+	}
+	if j.UserID != userID {
+		return nil, apperror.ErrNotFound
+	}
+	return j, nil
+}
+
+func (s *service) GetJobs(ctx context.Context, userID uint) ([]*lotimport.Job, error) {
+	jobs, err := s.repository.FindJobs(ctx, userID, jobsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find import jobs. error: %w", err)
+	}
+	return jobs, nil
+}
+
+func (s *service) ProcessNext(ctx context.Context) (bool, error) {
+	j, err := s.repository.ClaimJob(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim import job. error: %w", err)
+	}
+	if j == nil {
+		return false, nil
+	}
+
+	s.logger.Infof("importing lots of job %d..", j.ID)
+	if err = s.process(ctx, j); err != nil {
+		j.Status = lotimport.StatusFailed
+		j.Error = err.Error()
+		if message := []rune(j.Error); len(message) > maxErrorLength {
+			j.Error = string(message[:maxErrorLength])
+		}
+	} else {
+		j.Status = lotimport.StatusDone
+	}
+	finishedAt := time.Now().UTC().Truncate(time.Second)
+	j.FinishedAt = &finishedAt
+
+	key := j.FileKey
+	j.FileKey = ""
+	if err = s.repository.FinishJob(ctx, j); err != nil {
+		return true, fmt.Errorf("failed to save results of import job. error: %w", err)
+	}
+	s.deleteFile(ctx, key)
+
+	s.events.Publish(ctx, j.UserID, event.TypeImport, j)
+	return true, nil
+}
+
+// process validates rows of the file and creates lots of valid ones, unless it's dry run.
+// Returned error fails the whole job.
+func (s *service) process(ctx context.Context, j *lotimport.Job) error {
+	rows, err := s.readFile(ctx, j)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return errors.New("file is empty")
+	}
+	columns, err := j.Mapping.Columns(rows[0])
+	if err != nil {
+		return err
+	}
+
+	for _, values := range rows[1:] {
+		if !lotimport.Blank(values) {
+			j.TotalRows++
+		}
+	}
+	if j.TotalRows > s.cfg.MaxRows {
+		return fmt.Errorf("file must not have more than %d rows", s.cfg.MaxRows)
+	}
+	estateTypes, err := lotService.KnownEstateTypes(ctx, s.estateTypes)
+	if err != nil {
+		return err
+	}
+	rates, err := lotService.KnownRates(ctx, s.rates)
+	if err != nil {
+		return err
+	}
+
+	for i, values := range rows[1:] {
+		if lotimport.Blank(values) {
+			continue
+		}
+		rowNumber := i + 2
+
+		dto, rowErrors := newLot(j, columns, values, estateTypes, rates.Codes())
+		if len(rowErrors) > 0 {
+			for _, e := range rowErrors {
+				e.Row = rowNumber
+				j.AddError(e)
+			}
+			continue
+		}
+		j.ValidRows++
+		if j.DryRun {
+			continue
+		}
+
+		if _, err = s.lots.Create(ctx, dto); err != nil {
+			s.logger.Errorf("failed to create lot of row %d of import job %d. error: %v", rowNumber, j.ID, err)
+			j.AddError(lotimport.RowError{Row: rowNumber, Message: "failed to create lot"})
+			continue
+		}
+		j.CreatedRows++
+	}
+	return nil
+}
+
+func (s *service) readFile(ctx context.Context, j *lotimport.Job) ([][]string, error) {
+	rc, err := s.media.Open(ctx, j.FileKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	rows, err := spreadsheet.Read(j.Format, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s file: %w", j.Format, err)
+	}
+	return rows, nil
+}
+
+// newLot builds lot of the row and validates it with lot.Lot.ValidateFields.
+func newLot(j *lotimport.Job, columns map[string]int, values []string,
+	estateTypes, currencies []string) (*lot.CreateLotDTO, []lotimport.RowError) {
+	dto, problems := lotimport.NewLotDTO(columns, values)
+	dto.CreatedByUserID = j.UserID
+	if j.OrganizationID != nil {
+		dto.OrganizationID = *j.OrganizationID
+	}
+	l := lot.NewLot(dto)
+
+	if err := l.ValidateFields(estateTypes, currencies); err != nil {
+		var fieldErrors validation.Errors
+		if !errors.As(err, &fieldErrors) {
+			return nil, []lotimport.RowError{{Message: err.Error()}}
+		}
+		for field, fieldErr := range fieldErrors {
+			// unparsed values are reported as is, they are zero for validation
+			if _, ok := problems[field]; !ok {
+				problems[field] = fieldErr.Error()
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return dto, nil
+	}
+
+	rowErrors := make([]lotimport.RowError, 0, len(problems))
+	for field, message := range problems {
+		rowErrors = append(rowErrors, lotimport.RowError{Field: field, Message: message})
+	}
+	sort.Slice(rowErrors, func(i, j int) bool { return rowErrors[i].Field < rowErrors[j].Field })
+	return nil, rowErrors
+}
+
+func (s *service) deleteFile(ctx context.Context, key string) {
+	if err := s.media.Delete(ctx, key); err != nil {
+		s.logger.Errorf("failed to delete imported file %s. error: %v", key, err)
+	}
+}
+
+// RunImport processes queued jobs one by one. Jobs interrupted by restart are queued again on start,
+// so only one instance of the service should run imports.
+func RunImport(ctx context.Context, s Service, importStorage storage.Repository, interval time.Duration,
+	logger logging.Logger) {
+	n, err := importStorage.RequeueRunning(ctx)
+	if err != nil {
+		logger.Errorf("failed to requeue interrupted import jobs. error: %v", err)
+	} else if n > 0 {
+		logger.Infof("requeued %d interrupted import jobs", n)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				processed, err := s.ProcessNext(ctx)
+				if err != nil {
+					logger.Errorf("failed to process import job. error: %v", err)
+					break
+				}
+				if !processed {
+					break
+				}
+			}
+		}
+	}
+}
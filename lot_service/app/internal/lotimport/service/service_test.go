@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/media"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type repo struct {
+	storage.Repository
+	queued   *lotimport.Job
+	finished *lotimport.Job
+}
+
+func (r *repo) CreateJob(_ context.Context, j *lotimport.Job) (uint, error) {
+	r.queued = j
+	return 1, nil
+}
+
+func (r *repo) ClaimJob(_ context.Context) (*lotimport.Job, error) {
+	j := r.queued
+	r.queued = nil
+	return j, nil
+}
+
+func (r *repo) FinishJob(_ context.Context, j *lotimport.Job) error {
+	r.finished = j
+	return nil
+}
+
+type lots struct {
+	lotService.Service
+	created []*lot.Lot
+}
+
+func (l *lots) Create(_ context.Context, dto *lot.CreateLotDTO) (uint, error) {
+	l.created = append(l.created, lot.NewLot(dto))
+	return uint(len(l.created)), nil
+}
+
+type files struct {
+	media.Storage
+	data map[string][]byte
+}
+
+func (f *files) Save(_ context.Context, data io.Reader) (string, error) {
+	b, err := io.ReadAll(data)
+	if err != nil {
+		return "", err
+	}
+	f.data["key"] = b
+	return "key", nil
+}
+
+func (f *files) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := f.data[key]
+	if !ok {
+		return nil, media.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *files) Delete(_ context.Context, key string) error {
+	delete(f.data, key)
+	return nil
+}
+
+type publisher struct {
+	recipients []uint
+}
+
+func (p *publisher) Publish(_ context.Context, userID uint, _ string, _ any) {
+	p.recipients = append(p.recipients, userID)
+}
+
+const sheet = `Тип;Комнаты;Площадь;Этаж;Этажей;Город;Район;Улица;Дом;Цена
+Квартира;2;54,0;3;9;Москва;Арбат;Арбат;10;85 000
+;;;;;;;;;
+офис;1;30;1;5;Москва;Арбат;Арбат;12;дорого
+`
+
+var mapping = lotimport.Mapping{
+	"type_of_estate": "Тип",
+	"rooms":          "Комнаты",
+	"area":           "Площадь",
+	"floor":          "Этаж",
+	"max_floor":      "Этажей",
+	"city":           "Город",
+	"district":       "Район",
+	"street":         "Улица",
+	"building":       "Дом",
+	"price":          "Цена",
+}
+
+func TestImport(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		r := &repo{}
+		l := &lots{}
+		f := &files{data: make(map[string][]byte)}
+		p := &publisher{}
+		s, _ := NewService(r, l, nil, f, p, nil, nil, Config{MaxFileSize: 1 << 20, MaxRows: 10}, logging.GetLogger())
+		ctx := context.Background()
+
+		j, err := s.CreateJob(ctx, &lotimport.CreateJobDTO{UserID: 7, DryRun: dryRun, Mapping: mapping},
+			bytes.NewReader([]byte(sheet)))
+		if err != nil {
+			t.Fatalf("dry run %t: failed to create job: %v", dryRun, err)
+		}
+		if j.Format != "csv" || j.Status != lotimport.StatusQueued {
+			t.Fatalf("dry run %t: expected queued csv job, got %s %s", dryRun, j.Status, j.Format)
+		}
+
+		processed, err := s.ProcessNext(ctx)
+		if err != nil || !processed {
+			t.Fatalf("dry run %t: expected processed job, got %t, %v", dryRun, processed, err)
+		}
+		done := r.finished
+		if done.Status != lotimport.StatusDone || done.TotalRows != 2 || done.ValidRows != 1 {
+			t.Errorf("dry run %t: expected done job with 1 of 2 valid rows, got %+v", dryRun, done)
+		}
+		wantErrors := []lotimport.RowError{
+			{Row: 4, Field: "price", Message: "must be a whole number"},
+			{Row: 4, Field: "type_of_estate", Message: "must be a valid value"},
+		}
+		if !reflect.DeepEqual(done.Errors, wantErrors) {
+			t.Errorf("dry run %t: expected errors %v, got %v", dryRun, wantErrors, done.Errors)
+		}
+
+		wantCreated := 1
+		if dryRun {
+			wantCreated = 0
+		}
+		if done.CreatedRows != wantCreated || len(l.created) != wantCreated {
+			t.Errorf("dry run %t: expected %d created lots, got %d", dryRun, wantCreated, len(l.created))
+		}
+		if !dryRun && (l.created[0].Price != 85000 || l.created[0].Area != 54 || l.created[0].CreatedByUserID != 7) {
+			t.Errorf("unexpected lot %+v", l.created[0])
+		}
+		if len(f.data) != 0 {
+			t.Errorf("dry run %t: file must be deleted after import", dryRun)
+		}
+		if len(p.recipients) != 1 || p.recipients[0] != 7 {
+			t.Errorf("dry run %t: expected event for the user, got %v", dryRun, p.recipients)
+		}
+
+		if processed, _ = s.ProcessNext(ctx); processed {
+			t.Errorf("dry run %t: no jobs must be left", dryRun)
+		}
+	}
+}
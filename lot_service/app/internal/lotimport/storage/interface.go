@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lotimport"
+)
+
+type Repository interface {
+	CreateProfile(ctx context.Context, p *lotimport.Profile) (uint, error)
+	FindProfile(ctx context.Context, id uint) (*lotimport.Profile, error)
+	FindProfiles(ctx context.Context, ownerID uint) ([]*lotimport.Profile, error)
+	DeleteProfile(ctx context.Context, id, ownerID uint) error
+
+	CreateJob(ctx context.Context, j *lotimport.Job) (uint, error)
+	FindJob(ctx context.Context, id uint) (*lotimport.Job, error)
+	// FindJobs returns the latest jobs of the user.
+	FindJobs(ctx context.Context, userID uint, limit uint64) ([]*lotimport.Job, error)
+	// ClaimJob marks the oldest queued job as running and returns it, or returns nil, if there are no queued jobs.
+	ClaimJob(ctx context.Context) (*lotimport.Job, error)
+	// FinishJob saves results of the job.
+	FinishJob(ctx context.Context, j *lotimport.Job) error
+	// RequeueRunning queues again jobs, which were interrupted by restart of the service.
+	RequeueRunning(ctx context.Context) (int64, error)
+}
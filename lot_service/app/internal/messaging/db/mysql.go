@@ -0,0 +1,516 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"time"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// conversationColumns has one placeholder for ID of the user, whose unread messages are counted.
+const conversationColumns = `
+	c.conversation_id, c.lot_id, c.renter_id, c.landlord_id, c.created_at, c.last_message_at,
+	(SELECT COUNT(*) FROM messages m
+	WHERE m.conversation_id=c.conversation_id AND m.sender_id<>? AND m.read_at IS NULL)`
+
+func scanConversation(row scanner) (*messaging.Conversation, error) {
+	c := &messaging.Conversation{}
+	var createdAt, lastMessageAt *mysql.RawTime
+	err := row.Scan(&c.ID, &c.LotID, &c.RenterID, &c.LandlordID, &createdAt, &lastMessageAt, &c.Unread)
+	if err != nil {
+		return nil, err
+	}
+	if c.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	if c.LastMessageAt, err = lastMessageAt.Time(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *db) CreateConversation(ctx context.Context, c *messaging.Conversation) (uint, error) {
+	// LAST_INSERT_ID(expr) makes LastInsertId return ID of the existing conversation
+	queryString := `
+	INSERT INTO conversations (lot_id, renter_id, landlord_id, created_at, last_message_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE conversation_id=LAST_INSERT_ID(conversation_id);`
+
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, queryString, c.LotID, c.RenterID, c.LandlordID, now, now)
+	if err != nil {
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), nil
+}
+
+func (s *db) FindConversation(ctx context.Context, id, userID uint) (*messaging.Conversation, error) {
+	queryString := `
+	SELECT` + conversationColumns + `
+	FROM conversations c
+	WHERE c.conversation_id=?;`
+
+	c, err := scanConversation(s.db.QueryRowContext(ctx, queryString, userID, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *db) FindConversationsByUser(ctx context.Context, userID uint) ([]*messaging.Conversation, error) {
+	queryString := `
+	SELECT` + conversationColumns + `
+	FROM conversations c
+	WHERE c.renter_id=? OR c.landlord_id=?
+	ORDER BY c.last_message_at DESC;`
+
+	rows, err := s.db.QueryContext(ctx, queryString, userID, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conversations := make([]*messaging.Conversation, 0)
+	for rows.Next() {
+		c, err := scanConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, c)
+	}
+	if err = rows.Err(); err != nil {
+		return conversations, err
+	}
+	return conversations, nil
+}
+
+func (s *db) CreateMessage(ctx context.Context, m *messaging.Message, attachmentIDs []uint) (uint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO messages (conversation_id, sender_id, body, created_at)
+	VALUES (?, ?, ?, ?);`,
+		m.ConversationID, m.SenderID, m.Body, m.CreatedAt.UTC())
+	if err != nil {
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	messageID := uint(retID)
+
+	_, err = tx.ExecContext(ctx, `UPDATE conversations SET last_message_at=? WHERE conversation_id=?;`,
+		m.CreatedAt.UTC(), m.ConversationID)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(attachmentIDs) > 0 {
+		sqlQ, args, err := sq.Update("message_attachments").
+			Set("message_id", messageID).
+			Where(sq.Eq{"attachment_id": attachmentIDs, "uploader_id": m.SenderID, "message_id": nil}).
+			ToSql()
+		if err != nil {
+			return 0, err
+		}
+		res, err = tx.ExecContext(ctx, sqlQ, args...)
+		if err != nil {
+			return 0, err
+		}
+		rowsAff, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		} else if rowsAff != int64(len(attachmentIDs)) {
+			return 0, apperror.BadRequestError("attachments must be uploaded by the sender and not sent yet", "")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	m.Attachments, err = s.findAttachments(ctx, []uint{messageID})
+	if err != nil {
+		return 0, err
+	}
+	return messageID, nil
+}
+
+const messageColumns = "message_id, conversation_id, sender_id, body, created_at, read_at"
+
+func scanMessage(row scanner) (*messaging.Message, error) {
+	m := &messaging.Message{Attachments: make([]*messaging.Attachment, 0)}
+	var createdAt, readAt *mysql.RawTime
+	err := row.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Body, &createdAt, &readAt)
+	if err != nil {
+		return nil, err
+	}
+	if m.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	if readAt != nil {
+		t, err := readAt.Time()
+		if err != nil {
+			return nil, err
+		}
+		m.ReadAt = &t
+	}
+	return m, nil
+}
+
+func (s *db) FindMessage(ctx context.Context, id uint) (*messaging.Message, error) {
+	queryString := `
+	SELECT ` + messageColumns + `
+	FROM messages
+	WHERE message_id=?;`
+
+	m, err := scanMessage(s.db.QueryRowContext(ctx, queryString, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	m.Attachments, err = s.findAttachments(ctx, []uint{m.ID})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *db) FindMessages(ctx context.Context, conversationID, beforeID uint, limit uint64) ([]*messaging.Message, error) {
+	qb := sq.Select(messageColumns).
+		From("messages").
+		Where(sq.Eq{"conversation_id": conversationID}).
+		OrderBy("message_id DESC").
+		Limit(limit)
+	if beforeID != 0 {
+		qb = qb.Where(sq.Lt{"message_id": beforeID})
+	}
+	sqlQ, args, err := qb.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQ, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]*messaging.Message, 0)
+	byID := make(map[uint]*messaging.Message)
+	ids := make([]uint, 0)
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+		byID[m.ID] = m
+		ids = append(ids, m.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.findAttachments(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attachments {
+		m := byID[*a.MessageID]
+		m.Attachments = append(m.Attachments, a)
+	}
+	return messages, nil
+}
+
+func (s *db) MarkRead(ctx context.Context, conversationID, readerID uint, at time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+	UPDATE messages
+	SET read_at=?
+	WHERE conversation_id=? AND sender_id<>? AND read_at IS NULL;`,
+		at.UTC(), conversationID, readerID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *db) CountUnread(ctx context.Context, userID uint) (*messaging.Unread, error) {
+	queryString := `
+	SELECT COUNT(*), COUNT(DISTINCT m.conversation_id)
+	FROM messages m
+	JOIN conversations c ON c.conversation_id=m.conversation_id
+	WHERE (c.renter_id=? OR c.landlord_id=?) AND m.sender_id<>? AND m.read_at IS NULL;`
+
+	u := &messaging.Unread{}
+	if err := s.db.QueryRowContext(ctx, queryString, userID, userID, userID).Scan(&u.Messages, &u.Conversations); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+const attachmentColumns = "attachment_id, message_id, uploader_id, name, content_type, size, storage_key, created_at"
+
+func scanAttachment(row scanner) (*messaging.Attachment, error) {
+	a := &messaging.Attachment{}
+	var messageID sql.NullInt64
+	var createdAt *mysql.RawTime
+	err := row.Scan(&a.ID, &messageID, &a.UploaderID, &a.Name, &a.ContentType, &a.Size, &a.StorageKey, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if messageID.Valid {
+		id := uint(messageID.Int64)
+		a.MessageID = &id
+	}
+	if a.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *db) CreateAttachment(ctx context.Context, a *messaging.Attachment) (uint, error) {
+	queryString := `
+	INSERT INTO message_attachments (uploader_id, name, content_type, size, storage_key, created_at)
+	VALUES (?, ?, ?, ?, ?, ?);`
+
+	res, err := s.db.ExecContext(ctx, queryString,
+		a.UploaderID, a.Name, a.ContentType, a.Size, a.StorageKey, a.CreatedAt.UTC())
+	if err != nil {
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), nil
+}
+
+func (s *db) FindAttachment(ctx context.Context, id uint) (*messaging.Attachment, error) {
+	queryString := `
+	SELECT ` + attachmentColumns + `
+	FROM message_attachments
+	WHERE attachment_id=?;`
+
+	a, err := scanAttachment(s.db.QueryRowContext(ctx, queryString, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *db) findAttachments(ctx context.Context, messageIDs []uint) ([]*messaging.Attachment, error) {
+	attachments := make([]*messaging.Attachment, 0)
+	if len(messageIDs) == 0 {
+		return attachments, nil
+	}
+
+	sqlQ, args, err := sq.Select(attachmentColumns).
+		From("message_attachments").
+		Where(sq.Eq{"message_id": messageIDs}).
+		OrderBy("attachment_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQ, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	if err = rows.Err(); err != nil {
+		return attachments, err
+	}
+	return attachments, nil
+}
+
+func (s *db) Block(ctx context.Context, blockerID, blockedID uint) error {
+	_, err := s.db.ExecContext(ctx, `
+	INSERT IGNORE INTO user_blocks (blocker_id, blocked_id)
+	VALUES (?, ?);`,
+		blockerID, blockedID)
+	return err
+}
+
+func (s *db) Unblock(ctx context.Context, blockerID, blockedID uint) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM user_blocks WHERE blocker_id=? AND blocked_id=?;`,
+		blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+func (s *db) IsBlocked(ctx context.Context, blockerID, blockedID uint) (bool, error) {
+	var blocked bool
+	err := s.db.QueryRowContext(ctx, `
+	SELECT EXISTS (SELECT 1 FROM user_blocks WHERE blocker_id=? AND blocked_id=?);`,
+		blockerID, blockedID).Scan(&blocked)
+	return blocked, err
+}
+
+func (s *db) FindBlocks(ctx context.Context, blockerID uint) ([]*messaging.Block, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT blocker_id, blocked_id, created_at
+	FROM user_blocks
+	WHERE blocker_id=?
+	ORDER BY created_at DESC;`, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := make([]*messaging.Block, 0)
+	for rows.Next() {
+		b := &messaging.Block{}
+		var createdAt *mysql.RawTime
+		if err = rows.Scan(&b.BlockerID, &b.BlockedID, &createdAt); err != nil {
+			return nil, err
+		}
+		if b.CreatedAt, err = createdAt.Time(); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	if err = rows.Err(); err != nil {
+		return blocks, err
+	}
+	return blocks, nil
+}
+
+func (s *db) DeleteMessagesBefore(ctx context.Context, before time.Time) ([]string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	keys, err := queryKeys(ctx, tx, `
+	SELECT a.storage_key
+	FROM message_attachments a
+	JOIN messages m ON m.message_id=a.message_id
+	WHERE m.created_at<?;`, before.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	// attachments are deleted by foreign key
+	if _, err = tx.ExecContext(ctx, `DELETE FROM messages WHERE created_at<?;`, before.UTC()); err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+	DELETE FROM conversations
+	WHERE last_message_at<? AND NOT EXISTS (
+		SELECT 1 FROM messages WHERE messages.conversation_id=conversations.conversation_id
+	);`, before.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *db) DeleteUnsentAttachments(ctx context.Context, before time.Time) ([]string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	keys, err := queryKeys(ctx, tx, `
+	SELECT storage_key
+	FROM message_attachments
+	WHERE message_id IS NULL AND created_at<?
+	FOR UPDATE;`, before.UTC())
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM message_attachments WHERE message_id IS NULL AND created_at<?;`,
+		before.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func queryKeys(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0)
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
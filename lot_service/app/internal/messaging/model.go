@@ -0,0 +1,114 @@
+package messaging
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/rules"
+	"time"
+)
+
+// Conversation is a thread between the owner of the lot and another user, who asks about it.
+type Conversation struct {
+	ID            uint      `json:"id"`
+	LotID         uint      `json:"lot_id"`
+	RenterID      uint      `json:"renter_id"`
+	LandlordID    uint      `json:"landlord_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastMessageAt time.Time `json:"last_message_at"`
+	Unread        int       `json:"unread"` // messages of the other side not read by the requester
+}
+
+// Participant tells whether the user is a side of the conversation.
+func (c *Conversation) Participant(userID uint) bool {
+	return userID == c.RenterID || userID == c.LandlordID
+}
+
+// Recipient returns the other side of the conversation.
+func (c *Conversation) Recipient(senderID uint) uint {
+	if senderID == c.RenterID {
+		return c.LandlordID
+	}
+	return c.RenterID
+}
+
+type Message struct {
+	ID             uint          `json:"id"`
+	ConversationID uint          `json:"conversation_id"`
+	SenderID       uint          `json:"sender_id"`
+	Body           string        `json:"body"`
+	Attachments    []*Attachment `json:"attachments"`
+	CreatedAt      time.Time     `json:"created_at"`
+	ReadAt         *time.Time    `json:"read_at,omitempty"` // read receipt of the recipient
+}
+
+// Attachment is a file uploaded by the user, it becomes visible to the other side when sent with a message.
+type Attachment struct {
+	ID          uint      `json:"id"`
+	MessageID   *uint     `json:"message_id,omitempty"`
+	UploaderID  uint      `json:"uploader_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Block forbids the blocked user to write to the blocker.
+type Block struct {
+	BlockerID uint      `json:"blocker_id"`
+	BlockedID uint      `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Unread struct {
+	Messages      int `json:"messages"`
+	Conversations int `json:"conversations"`
+}
+
+const maxAttachments = 10
+
+type StartConversationDTO struct {
+	LotID         uint   `json:"lot_id"`
+	UserID        uint   `json:"user_id"`
+	Body          string `json:"body"`
+	AttachmentIDs []uint `json:"attachment_ids"`
+}
+
+type SendMessageDTO struct {
+	ConversationID uint   `json:"conversation_id"`
+	SenderID       uint   `json:"sender_id"`
+	Body           string `json:"body"`
+	AttachmentIDs  []uint `json:"attachment_ids"`
+}
+
+type UploadAttachmentDTO struct {
+	UploaderID  uint
+	Name        string
+	ContentType string
+}
+
+func (dto *StartConversationDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.LotID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Body, validation.Required, validation.Length(1, 4000)),
+		validation.Field(&dto.AttachmentIDs, validation.Length(0, maxAttachments)),
+	)
+}
+
+func (dto *SendMessageDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ConversationID, validation.Required),
+		validation.Field(&dto.SenderID, validation.Required),
+		// message may consist of attachments only
+		validation.Field(&dto.Body, validation.By(rules.RequiredIf(len(dto.AttachmentIDs) == 0)), validation.Length(0, 4000)),
+		validation.Field(&dto.AttachmentIDs, validation.Length(0, maxAttachments)),
+	)
+}
+
+func (dto *UploadAttachmentDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.UploaderID, validation.Required),
+		validation.Field(&dto.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&dto.ContentType, validation.Required, validation.Length(1, 100)),
+	)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging"
+)
+
+// Notifier tells the recipient about new message. Failures of notifications must not fail sending.
+type Notifier interface {
+	MessageSent(ctx context.Context, recipientID uint, c *messaging.Conversation, m *messaging.Message)
+}
+
+type eventNotifier struct {
+	publisher eventService.Publisher
+}
+
+// NewEventNotifier returns notifier, which publishes events delivered to clients in real time.
+func NewEventNotifier(publisher eventService.Publisher) *eventNotifier {
+	return &eventNotifier{publisher: publisher}
+}
+
+type messageEvent struct {
+	LotID   uint               `json:"lot_id"`
+	Message *messaging.Message `json:"message"`
+}
+
+func (n *eventNotifier) MessageSent(ctx context.Context, recipientID uint, c *messaging.Conversation, m *messaging.Message) {
+	n.publisher.Publish(ctx, recipientID, event.TypeMessage, messageEvent{LotID: c.LotID, Message: m})
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/media"
+	"time"
+)
+
+// RetentionPolicy tells how long messages and attachments are kept.
+type RetentionPolicy struct {
+	// MessagesTTL is age, after which messages with their attachments are deleted. Zero keeps them forever.
+	MessagesTTL time.Duration
+	// UnsentAttachmentsTTL is time, during which uploaded attachment must be sent.
+	UnsentAttachmentsTTL time.Duration
+}
+
+// RunRetention deletes messages and attachments according to the policy every interval until ctx is done.
+func RunRetention(ctx context.Context, repository storage.Repository, mediaStorage media.Storage,
+	policy RetentionPolicy, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			applyRetention(ctx, repository, mediaStorage, policy, logger)
+		}
+	}
+}
+
+func applyRetention(ctx context.Context, repository storage.Repository, mediaStorage media.Storage,
+	policy RetentionPolicy, logger logging.Logger) {
+	var keys []string
+
+	if policy.MessagesTTL > 0 {
+		deleted, err := repository.DeleteMessagesBefore(ctx, time.Now().Add(-policy.MessagesTTL))
+		if err != nil {
+			logger.Errorf("failed to delete old messages. error: %v", err)
+		}
+		keys = append(keys, deleted...)
+	}
+
+	if policy.UnsentAttachmentsTTL > 0 {
+		deleted, err := repository.DeleteUnsentAttachments(ctx, time.Now().Add(-policy.UnsentAttachmentsTTL))
+		if err != nil {
+			logger.Errorf("failed to delete unsent attachments. error: %v", err)
+		}
+		keys = append(keys, deleted...)
+	}
+
+	for _, key := range keys {
+		if err := mediaStorage.Delete(ctx, key); err != nil {
+			logger.Errorf("failed to delete media %s. error: %v", key, err)
+		}
+	}
+	if len(keys) > 0 {
+		logger.Infof("%d attachments deleted by retention policy", len(keys))
+	}
+}
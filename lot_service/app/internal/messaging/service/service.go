@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/media"
+	"io"
+	"time"
+)
+
+const (
+	defaultMessagesLimit = 50
+	maxMessagesLimit     = 200
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	// StartConversation writes the first message about the lot to its owner. Existing conversation
+	// of the user about the lot is reused.
+	StartConversation(ctx context.Context, dto *messaging.StartConversationDTO) (*messaging.Message, error)
+	GetConversations(ctx context.Context, userID uint) ([]*messaging.Conversation, error)
+	GetConversation(ctx context.Context, id, userID uint) (*messaging.Conversation, error)
+	GetMessages(ctx context.Context, conversationID, userID, beforeID uint, limit uint64) ([]*messaging.Message, error)
+	SendMessage(ctx context.Context, dto *messaging.SendMessageDTO) (*messaging.Message, error)
+	// MarkRead marks all messages sent to the user in the conversation as read.
+	MarkRead(ctx context.Context, conversationID, userID uint) (int64, error)
+	GetUnread(ctx context.Context, userID uint) (*messaging.Unread, error)
+
+	UploadAttachment(ctx context.Context, dto *messaging.UploadAttachmentDTO, data io.Reader) (*messaging.Attachment, error)
+	// OpenAttachment returns attachment, if the user uploaded it or is a side of conversation it was sent to.
+	OpenAttachment(ctx context.Context, id, userID uint) (*messaging.Attachment, io.ReadCloser, error)
+
+	GetBlocks(ctx context.Context, userID uint) ([]*messaging.Block, error)
+	Block(ctx context.Context, userID, blockedID uint) error
+	Unblock(ctx context.Context, userID, blockedID uint) error
+}
+
+type Config struct {
+	MaxAttachmentSize int64
+}
+
+type service struct {
+	repository storage.Repository
+	lots       lotStorage.Repository
+	media      media.Storage
+	notifier   Notifier
+	cfg        Config
+	logger     logging.Logger
+}
+
+func NewService(messagingStorage storage.Repository, lots lotStorage.Repository, mediaStorage media.Storage,
+	notifier Notifier, cfg Config, logger logging.Logger) (*service, error) {
+	return &service{
+		repository: messagingStorage,
+		lots:       lots,
+		media:      mediaStorage,
+		notifier:   notifier,
+		cfg:        cfg,
+		logger:     logger,
+	}, nil
+}
+
+func (s *service) StartConversation(ctx context.Context, dto *messaging.StartConversationDTO) (*messaging.Message, error) {
+	s.logger.Debug("validating message fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	l, err := s.lots.FindByLotID(ctx, dto.LotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot of conversation. error: %w", err)
+	}
+	if l.CreatedByUserID == dto.UserID {
+		return nil, apperror.BadRequestError("owner of the lot can't start conversation about it", "")
+	}
+
+	c := &messaging.Conversation{
+		LotID:      l.ID,
+		RenterID:   dto.UserID,
+		LandlordID: l.CreatedByUserID,
+	}
+	if err = s.checkBlocked(ctx, c.LandlordID, dto.UserID); err != nil {
+		return nil, err
+	}
+
+	c.ID, err = s.repository.CreateConversation(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation. error: %w", err)
+	}
+
+	return s.send(ctx, c, dto.UserID, dto.Body, dto.AttachmentIDs)
+}
+
+func (s *service) GetConversations(ctx context.Context, userID uint) ([]*messaging.Conversation, error) {
+	conversations, err := s.repository.FindConversationsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find conversations of user. error: %w", err)
+	}
+	return conversations, nil
+}
+
+func (s *service) GetConversation(ctx context.Context, id, userID uint) (*messaging.Conversation, error) {
+	c, err := s.repository.FindConversation(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find conversation. error: %w", err)
+	}
+	if !c.Participant(userID) {
+		// don't tell others that conversation exists
+		return nil, apperror.ErrNotFound
+	}
+	return c, nil
+}
+
+func (s *service) GetMessages(ctx context.Context, conversationID, userID, beforeID uint,
+	limit uint64) ([]*messaging.Message, error) {
+	if _, err := s.GetConversation(ctx, conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	if limit == 0 {
+		limit = defaultMessagesLimit
+	} else if limit > maxMessagesLimit {
+		limit = maxMessagesLimit
+	}
+
+	messages, err := s.repository.FindMessages(ctx, conversationID, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find messages. error: %w", err)
+	}
+	return messages, nil
+}
+
+func (s *service) SendMessage(ctx context.Context, dto *messaging.SendMessageDTO) (*messaging.Message, error) {
+	s.logger.Debug("validating message fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	c, err := s.GetConversation(ctx, dto.ConversationID, dto.SenderID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkBlocked(ctx, c.Recipient(dto.SenderID), dto.SenderID); err != nil {
+		return nil, err
+	}
+
+	return s.send(ctx, c, dto.SenderID, dto.Body, dto.AttachmentIDs)
+}
+
+func (s *service) send(ctx context.Context, c *messaging.Conversation, senderID uint, body string,
+	attachmentIDs []uint) (*messaging.Message, error) {
+	m := &messaging.Message{
+		ConversationID: c.ID,
+		SenderID:       senderID,
+		Body:           body,
+		CreatedAt:      time.Now().UTC().Truncate(time.Second),
+	}
+
+	var err error
+	m.ID, err = s.repository.CreateMessage(ctx, m, attachmentIDs)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create message. error: %w", err)
+	}
+
+	s.notifier.MessageSent(ctx, c.Recipient(senderID), c, m)
+	return m, nil
+}
+
+func (s *service) MarkRead(ctx context.Context, conversationID, userID uint) (int64, error) {
+	if _, err := s.GetConversation(ctx, conversationID, userID); err != nil {
+		return 0, err
+	}
+	read, err := s.repository.MarkRead(ctx, conversationID, userID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark messages as read. error: %w", err)
+	}
+	return read, nil
+}
+
+func (s *service) GetUnread(ctx context.Context, userID uint) (*messaging.Unread, error) {
+	unread, err := s.repository.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages. error: %w", err)
+	}
+	return unread, nil
+}
+
+func (s *service) UploadAttachment(ctx context.Context, dto *messaging.UploadAttachmentDTO,
+	data io.Reader) (*messaging.Attachment, error) {
+	s.logger.Debug("validating attachment fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	// one byte more than allowed tells that the file is too large
+	counter := &countingReader{r: io.LimitReader(data, s.cfg.MaxAttachmentSize+1)}
+	key, err := s.media.Save(ctx, counter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save attachment. error: %w", err)
+	}
+	if counter.n > s.cfg.MaxAttachmentSize {
+		s.deleteMedia(ctx, key)
+		return nil, apperror.BadRequestError(
+			fmt.Sprintf("attachment must not be larger than %d bytes", s.cfg.MaxAttachmentSize), "")
+	}
+
+	a := &messaging.Attachment{
+		UploaderID:  dto.UploaderID,
+		Name:        dto.Name,
+		ContentType: dto.ContentType,
+		Size:        counter.n,
+		StorageKey:  key,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	a.ID, err = s.repository.CreateAttachment(ctx, a)
+	if err != nil {
+		s.deleteMedia(ctx, key)
+		return nil, fmt.Errorf("failed to create attachment. error: %w", err)
+	}
+	return a, nil
+}
+
+func (s *service) OpenAttachment(ctx context.Context, id, userID uint) (*messaging.Attachment, io.ReadCloser, error) {
+	a, err := s.repository.FindAttachment(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("failed to find attachment. error: %w", err)
+	}
+
+	if a.UploaderID != userID {
+		if a.MessageID == nil {
+			return nil, nil, apperror.ErrNotFound
+		}
+		m, err := s.repository.FindMessage(ctx, *a.MessageID)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return nil, nil, err
+			}
+			return nil, nil, fmt.Errorf("failed to find message of attachment. error: %w", err)
+		}
+		if _, err = s.GetConversation(ctx, m.ConversationID, userID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	data, err := s.media.Open(ctx, a.StorageKey)
+	if err != nil {
+		if errors.Is(err, media.ErrNotFound) {
+			return nil, nil, apperror.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open attachment. error: %w", err)
+	}
+	return a, data, nil
+}
+
+func (s *service) GetBlocks(ctx context.Context, userID uint) ([]*messaging.Block, error) {
+	blocks, err := s.repository.FindBlocks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blocked users. error: %w", err)
+	}
+	return blocks, nil
+}
+
+func (s *service) Block(ctx context.Context, userID, blockedID uint) error {
+	if userID == blockedID {
+		return apperror.BadRequestError("user can't block himself", "")
+	}
+	if err := s.repository.Block(ctx, userID, blockedID); err != nil {
+		return fmt.Errorf("failed to block user. error: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Unblock(ctx context.Context, userID, blockedID uint) error {
+	if err := s.repository.Unblock(ctx, userID, blockedID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to unblock user. error: %w", err)
+	}
+	return nil
+}
+
+// checkBlocked forbids writing to the recipient, who has blocked the sender.
+func (s *service) checkBlocked(ctx context.Context, recipientID, senderID uint) error {
+	blocked, err := s.repository.IsBlocked(ctx, recipientID, senderID)
+	if err != nil {
+		return fmt.Errorf("failed to check blocks. error: %w", err)
+	}
+	if blocked {
+		return apperror.ForbiddenError("recipient doesn't accept your messages")
+	}
+	return nil
+}
+
+func (s *service) deleteMedia(ctx context.Context, key string) {
+	if err := s.media.Delete(ctx, key); err != nil {
+		s.logger.Errorf("failed to delete media %s. error: %v", key, err)
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
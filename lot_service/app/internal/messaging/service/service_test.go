@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/media"
+	"os"
+	"strings"
+	"testing"
+)
+
+type repo struct {
+	storage.Repository
+	conversation *messaging.Conversation
+	blocked      bool
+	sent         []*messaging.Message
+	attachments  []*messaging.Attachment
+}
+
+func (r *repo) FindConversation(_ context.Context, _, _ uint) (*messaging.Conversation, error) {
+	return r.conversation, nil
+}
+
+func (r *repo) IsBlocked(_ context.Context, _, _ uint) (bool, error) {
+	return r.blocked, nil
+}
+
+func (r *repo) CreateMessage(_ context.Context, m *messaging.Message, _ []uint) (uint, error) {
+	r.sent = append(r.sent, m)
+	return uint(len(r.sent)), nil
+}
+
+func (r *repo) CreateAttachment(_ context.Context, a *messaging.Attachment) (uint, error) {
+	r.attachments = append(r.attachments, a)
+	return uint(len(r.attachments)), nil
+}
+
+type notifier struct {
+	recipients []uint
+}
+
+func (n *notifier) MessageSent(_ context.Context, recipientID uint, _ *messaging.Conversation, _ *messaging.Message) {
+	n.recipients = append(n.recipients, recipientID)
+}
+
+func newTestService(t *testing.T, r *repo, n Notifier) (*service, string) {
+	dir := t.TempDir()
+	mediaStorage, err := media.NewLocalStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := NewService(r, nil, mediaStorage, n, Config{MaxAttachmentSize: 5}, logging.GetLogger())
+	return s, dir
+}
+
+func TestSendMessage(t *testing.T) {
+	conversation := &messaging.Conversation{ID: 1, LotID: 2, RenterID: 10, LandlordID: 20}
+	dto := &messaging.SendMessageDTO{ConversationID: 1, SenderID: 10, Body: "hello"}
+
+	t.Run("notifies recipient", func(t *testing.T) {
+		r, n := &repo{conversation: conversation}, &notifier{}
+		s, _ := newTestService(t, r, n)
+
+		if _, err := s.SendMessage(context.Background(), dto); err != nil {
+			t.Fatal(err)
+		}
+		if len(r.sent) != 1 || len(n.recipients) != 1 || n.recipients[0] != 20 {
+			t.Errorf("expected message to landlord, got %d messages to %v", len(r.sent), n.recipients)
+		}
+	})
+
+	t.Run("blocked", func(t *testing.T) {
+		r, n := &repo{conversation: conversation, blocked: true}, &notifier{}
+		s, _ := newTestService(t, r, n)
+
+		_, err := s.SendMessage(context.Background(), dto)
+		var appErr *apperror.AppError
+		if !errors.As(err, &appErr) || len(r.sent) != 0 {
+			t.Fatalf("expected message to be forbidden, got %v", err)
+		}
+	})
+
+	t.Run("stranger", func(t *testing.T) {
+		r := &repo{conversation: conversation}
+		s, _ := newTestService(t, r, &notifier{})
+
+		stranger := *dto
+		stranger.SenderID = 30
+		if _, err := s.SendMessage(context.Background(), &stranger); !errors.Is(err, apperror.ErrNotFound) {
+			t.Fatalf("expected not found, got %v", err)
+		}
+	})
+}
+
+func TestUploadAttachment(t *testing.T) {
+	dto := &messaging.UploadAttachmentDTO{UploaderID: 10, Name: "plan.txt", ContentType: "text/plain"}
+
+	r := &repo{}
+	s, dir := newTestService(t, r, &notifier{})
+
+	a, err := s.UploadAttachment(context.Background(), dto, strings.NewReader("12345"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Size != 5 || a.StorageKey == "" {
+		t.Errorf("unexpected attachment %+v", a)
+	}
+
+	if _, err = s.UploadAttachment(context.Background(), dto, strings.NewReader("123456")); err == nil {
+		t.Fatal("expected too large attachment to be rejected")
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || len(r.attachments) != 1 {
+		t.Errorf("expected rejected file to be deleted, got %d files", len(files))
+	}
+}
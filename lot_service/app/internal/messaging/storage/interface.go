@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/messaging"
+	"time"
+)
+
+type Repository interface {
+	// CreateConversation returns ID of the existing conversation of the renter about the lot or creates new one.
+	CreateConversation(ctx context.Context, c *messaging.Conversation) (uint, error)
+	// FindConversation returns conversation with unread count of the user.
+	FindConversation(ctx context.Context, id, userID uint) (*messaging.Conversation, error)
+	FindConversationsByUser(ctx context.Context, userID uint) ([]*messaging.Conversation, error)
+
+	// CreateMessage saves message and links the attachments to it. Attachments must be uploaded
+	// by the sender and not be sent yet.
+	CreateMessage(ctx context.Context, m *messaging.Message, attachmentIDs []uint) (uint, error)
+	FindMessage(ctx context.Context, id uint) (*messaging.Message, error)
+	// FindMessages returns up to limit messages older than beforeID, if it's not zero, newest first.
+	FindMessages(ctx context.Context, conversationID, beforeID uint, limit uint64) ([]*messaging.Message, error)
+	// MarkRead marks messages sent to the reader in the conversation as read and returns their number.
+	MarkRead(ctx context.Context, conversationID, readerID uint, at time.Time) (int64, error)
+	CountUnread(ctx context.Context, userID uint) (*messaging.Unread, error)
+
+	CreateAttachment(ctx context.Context, a *messaging.Attachment) (uint, error)
+	FindAttachment(ctx context.Context, id uint) (*messaging.Attachment, error)
+
+	Block(ctx context.Context, blockerID, blockedID uint) error
+	Unblock(ctx context.Context, blockerID, blockedID uint) error
+	IsBlocked(ctx context.Context, blockerID, blockedID uint) (bool, error)
+	FindBlocks(ctx context.Context, blockerID uint) ([]*messaging.Block, error)
+
+	// DeleteMessagesBefore deletes messages created before the time, conversations left without messages
+	// and returns storage keys of deleted attachments.
+	DeleteMessagesBefore(ctx context.Context, before time.Time) ([]string, error)
+	// DeleteUnsentAttachments deletes attachments, which were not sent before the time, and returns their keys.
+	DeleteUnsentAttachments(ctx context.Context, before time.Time) ([]string, error)
+}
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+)
+
+var _ storage.Repository = &db{}
+
+// errNoReferencedRow is code of MySQL error on violation of foreign key, e.g. when the user doesn't exist.
+const errNoReferencedRow = 1452
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is either database or transaction.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const organizationColumns = `organization_id, name, description, phone, email, website, created_at`
+
+func scanOrganization(row scanner) (*organization.Organization, error) {
+	o := &organization.Organization{}
+	var createdAt mysql.RawTime
+	if err := row.Scan(&o.ID, &o.Name, &o.Description, &o.Phone, &o.Email, &o.Website, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if o.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (s *db) Create(ctx context.Context, o *organization.Organization, ownerID uint) (uint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO organizations (name, description, phone, email, website, created_at)
+	VALUES (?, ?, ?, ?, ?, ?);`, o.Name, o.Description, o.Phone, o.Email, o.Website, o.CreatedAt.UTC())
+	if err != nil {
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+	INSERT INTO organization_members (organization_id, user_id, role, joined_at)
+	VALUES (?, ?, ?, ?);`, retID, ownerID, organization.RoleOwner, o.CreatedAt.UTC()); err != nil {
+		return 0, err
+	}
+	return uint(retID), tx.Commit()
+}
+
+func (s *db) FindByID(ctx context.Context, id uint) (*organization.Organization, error) {
+	o, err := scanOrganization(s.db.QueryRowContext(ctx, `
+	SELECT `+organizationColumns+`
+	FROM organizations
+	WHERE organization_id=?;`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return o, nil
+}
+
+func (s *db) FindByMemberID(ctx context.Context, userID uint) ([]*organization.Organization, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT `+organizationColumns+`
+	FROM organizations
+	WHERE organization_id IN (SELECT organization_id FROM organization_members WHERE user_id=?)
+	ORDER BY organization_id;`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	organizations := make([]*organization.Organization, 0)
+	for rows.Next() {
+		o, err := scanOrganization(rows)
+		if err != nil {
+			return nil, err
+		}
+		organizations = append(organizations, o)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return organizations, nil
+}
+
+func (s *db) Update(ctx context.Context, o *organization.Organization) error {
+	res, err := s.db.ExecContext(ctx, `
+	UPDATE organizations
+	SET name=?, description=?, phone=?, email=?, website=?
+	WHERE organization_id=?;`, o.Name, o.Description, o.Phone, o.Email, o.Website, o.ID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		// nothing changed or the organization doesn't exist
+		_, err = s.FindByID(ctx, o.ID)
+		return err
+	}
+	return nil
+}
+
+func (s *db) CountLots(ctx context.Context, id uint) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM lots WHERE organization_id=?;`, id).Scan(&count)
+	return count, err
+}
+
+const memberColumns = `
+	m.organization_id, m.user_id,
+	IFNULL(NULLIF(TRIM(CONCAT(IFNULL(u.given_name, ''), ' ', IFNULL(u.family_name, ''))), ''), u.username),
+	m.role, m.joined_at`
+
+func scanMember(row scanner) (*organization.Member, error) {
+	m := &organization.Member{}
+	var joinedAt mysql.RawTime
+	if err := row.Scan(&m.OrganizationID, &m.UserID, &m.Name, &m.Role, &joinedAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if m.JoinedAt, err = joinedAt.Time(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func findMember(ctx context.Context, q querier, organizationID, userID uint) (*organization.Member, error) {
+	m, err := scanMember(q.QueryRowContext(ctx, `
+	SELECT `+memberColumns+`
+	FROM organization_members m
+	JOIN users u ON u.user_id=m.user_id
+	WHERE m.organization_id=? AND m.user_id=?;`, organizationID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *db) FindMember(ctx context.Context, organizationID, userID uint) (*organization.Member, error) {
+	return findMember(ctx, s.db, organizationID, userID)
+}
+
+func (s *db) FindMembers(ctx context.Context, organizationID uint) ([]*organization.Member, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT `+memberColumns+`
+	FROM organization_members m
+	JOIN users u ON u.user_id=m.user_id
+	WHERE m.organization_id=?
+	ORDER BY FIELD(m.role, ?, ?, ?), m.joined_at;`,
+		organizationID, organization.RoleOwner, organization.RoleManager, organization.RoleAgent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]*organization.Member, 0)
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// lockMembers serializes changes of members, so the organization always keeps an owner.
+func lockMembers(ctx context.Context, tx *sql.Tx, organizationID uint) error {
+	var id uint
+	err := tx.QueryRowContext(ctx, `SELECT organization_id FROM organizations WHERE organization_id=? FOR UPDATE;`,
+		organizationID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.ErrNotFound
+	}
+	return err
+}
+
+// checkOwnerLeft returns conflict, if the change of the member leaves the organization without owners.
+func checkOwnerLeft(ctx context.Context, tx *sql.Tx, organizationID, userID uint) error {
+	var owners int
+	err := tx.QueryRowContext(ctx, `
+	SELECT COUNT(*)
+	FROM organization_members
+	WHERE organization_id=? AND role=? AND user_id<>?;`, organizationID, organization.RoleOwner, userID).
+		Scan(&owners)
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return apperror.ConflictError("organization must have an owner")
+	}
+	return nil
+}
+
+func (s *db) SetMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = lockMembers(ctx, tx, m.OrganizationID); err != nil {
+		return nil, err
+	}
+	if m.Role != organization.RoleOwner {
+		if err = checkOwnerLeft(ctx, tx, m.OrganizationID, m.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO organization_members (organization_id, user_id, role, joined_at)
+	VALUES (?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE role=VALUES(role);`, m.OrganizationID, m.UserID, m.Role, m.JoinedAt.UTC())
+	if err != nil {
+		var mysqlErr *driver.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errNoReferencedRow {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	saved, err := findMember(ctx, tx, m.OrganizationID, m.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return saved, tx.Commit()
+}
+
+func (s *db) RemoveMember(ctx context.Context, organizationID, userID uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = lockMembers(ctx, tx, organizationID); err != nil {
+		return err
+	}
+	if err = checkOwnerLeft(ctx, tx, organizationID, userID); err != nil {
+		return err
+	}
+	var lots int
+	if err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM lots WHERE organization_id=? AND user_id=?;`,
+		organizationID, userID).Scan(&lots); err != nil {
+		return err
+	}
+	if lots > 0 {
+		return apperror.ConflictError("lots of the member must be transferred to other members first")
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM organization_members WHERE organization_id=? AND user_id=?;`,
+		organizationID, userID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,120 @@
+package organization
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"time"
+)
+
+// Role of member in organization.
+type Role string
+
+const (
+	RoleOwner   Role = "owner"   // manages the organization and all its members
+	RoleManager Role = "manager" // manages all lots of the organization and its agents
+	RoleAgent   Role = "agent"   // manages lots assigned to the agent
+)
+
+// CanManageLots reports whether the role can edit, delete and transfer any lot of the organization.
+// Agents can manage only lots assigned to them.
+func (r Role) CanManageLots() bool {
+	return r == RoleOwner || r == RoleManager
+}
+
+// CanManageMember reports whether the role can add, change or remove member with the other role.
+func (r Role) CanManageMember(other Role) bool {
+	switch r {
+	case RoleOwner:
+		return true
+	case RoleManager:
+		return other == RoleAgent
+	default:
+		return false
+	}
+}
+
+// Organization is an agency, which owns lots managed by its members.
+type Organization struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Phone       string    `json:"phone"`
+	Email       string    `json:"email"`
+	Website     string    `json:"website"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Member struct {
+	OrganizationID uint      `json:"organization_id"`
+	UserID         uint      `json:"user_id"`
+	Name           string    `json:"name"`
+	Role           Role      `json:"role"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+// Page is public page of the organization.
+type Page struct {
+	Organization
+	Members   []*Member `json:"members"`
+	LotsCount int       `json:"lots_count"`
+}
+
+type CreateOrganizationDTO struct {
+	OwnerID     uint   `json:"owner_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Phone       string `json:"phone"`
+	Email       string `json:"email"`
+	Website     string `json:"website"`
+}
+
+// UpdateOrganizationDTO replaces public data of the organization.
+type UpdateOrganizationDTO struct {
+	ID          uint   `json:"id"`
+	UserID      uint   `json:"user_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Phone       string `json:"phone"`
+	Email       string `json:"email"`
+	Website     string `json:"website"`
+}
+
+// SetMemberDTO adds the user to the organization or changes role of the member.
+type SetMemberDTO struct {
+	OrganizationID uint `json:"organization_id"`
+	UserID         uint `json:"user_id"` // the one making the change
+	MemberID       uint `json:"member_id"`
+	Role           Role `json:"role"`
+}
+
+func (dto *CreateOrganizationDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.OwnerID, validation.Required),
+		validation.Field(&dto.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&dto.Description, validation.Length(0, 4000)),
+		validation.Field(&dto.Phone, validation.Length(0, 20)),
+		validation.Field(&dto.Email, validation.Length(0, 255), is.Email),
+		validation.Field(&dto.Website, validation.Length(0, 255), is.RequestURL),
+	)
+}
+
+func (dto *UpdateOrganizationDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&dto.Description, validation.Length(0, 4000)),
+		validation.Field(&dto.Phone, validation.Length(0, 20)),
+		validation.Field(&dto.Email, validation.Length(0, 255), is.Email),
+		validation.Field(&dto.Website, validation.Length(0, 255), is.RequestURL),
+	)
+}
+
+func (dto *SetMemberDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.OrganizationID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.MemberID, validation.Required),
+		validation.Field(&dto.Role, validation.Required, validation.In(RoleOwner, RoleManager, RoleAgent)),
+	)
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"time"
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	// Create saves the organization with the creator as its owner.
+	Create(ctx context.Context, dto *organization.CreateOrganizationDTO) (*organization.Organization, error)
+	// GetPage returns public page of the organization.
+	GetPage(ctx context.Context, id uint) (*organization.Page, error)
+	GetByMemberID(ctx context.Context, userID uint) ([]*organization.Organization, error)
+	// Update changes public data of the organization. Only owners can change it.
+	Update(ctx context.Context, dto *organization.UpdateOrganizationDTO) (*organization.Organization, error)
+	GetLots(ctx context.Context, id uint) ([]*lot.Lot, error)
+
+	// SetMember adds the user to the organization or changes role of the member. Owners manage all members,
+	// managers manage agents only.
+	SetMember(ctx context.Context, dto *organization.SetMemberDTO) (*organization.Member, error)
+	// RemoveMember removes the member from the organization, members can leave by themselves.
+	RemoveMember(ctx context.Context, organizationID, userID, memberID uint) error
+}
+
+type service struct {
+	repository storage.Repository
+	lots       lotStorage.Repository
+	events     eventService.Publisher
+	logger     logging.Logger
+}
+
+func NewService(organizationStorage storage.Repository, lots lotStorage.Repository, events eventService.Publisher,
+	logger logging.Logger) (*service, error) {
+	return &service{
+		repository: organizationStorage,
+		lots:       lots,
+		events:     events,
+		logger:     logger,
+	}, nil
+}
+
+func (s *service) Create(ctx context.Context, dto *organization.CreateOrganizationDTO) (*organization.Organization, error) {
+	s.logger.Debug("validating organization fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	o := &organization.Organization{
+		Name:        dto.Name,
+		Description: dto.Description,
+		Phone:       dto.Phone,
+		Email:       dto.Email,
+		Website:     dto.Website,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+
+	s.logger.Debug("creating new organization..")
+	id, err := s.repository.Create(ctx, o, dto.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization. error: %w", err)
+	}
+	o.ID = id
+	return o, nil
+}
+
+func (s *service) GetPage(ctx context.Context, id uint) (*organization.Page, error) {
+	o, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repository.FindMembers(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find members of organization. error: %w", err)
+	}
+	lotsCount, err := s.repository.CountLots(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count lots of organization. error: %w", err)
+	}
+
+	return &organization.Page{
+		Organization: *o,
+		Members:      members,
+		LotsCount:    lotsCount,
+	}, nil
+}
+
+func (s *service) GetByMemberID(ctx context.Context, userID uint) ([]*organization.Organization, error) {
+	organizations, err := s.repository.FindByMemberID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find organizations of user. error: %w", err)
+	}
+	return organizations, nil
+}
+
+func (s *service) Update(ctx context.Context, dto *organization.UpdateOrganizationDTO) (*organization.Organization, error) {
+	s.logger.Debug("validating organization fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	o, err := s.find(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	requester, err := s.findRequester(ctx, o.ID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if requester.Role != organization.RoleOwner {
+		return nil, apperror.ForbiddenError("only owners can change the organization")
+	}
+
+	o.Name = dto.Name
+	o.Description = dto.Description
+	o.Phone = dto.Phone
+	o.Email = dto.Email
+	o.Website = dto.Website
+	if err = s.repository.Update(ctx, o); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update organization. error: %w", err)
+	}
+	return o, nil
+}
+
+func (s *service) GetLots(ctx context.Context, id uint) ([]*lot.Lot, error) {
+	if _, err := s.find(ctx, id); err != nil {
+		return nil, err
+	}
+	lots, err := s.lots.FindByOrganizationID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find lots of organization. error: %w", err)
+	}
+	return lots, nil
+}
+
+func (s *service) SetMember(ctx context.Context, dto *organization.SetMemberDTO) (*organization.Member, error) {
+	s.logger.Debug("validating member fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	requester, err := s.findRequester(ctx, dto.OrganizationID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repository.FindMember(ctx, dto.OrganizationID, dto.MemberID)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, fmt.Errorf("failed to find member of organization. error: %w", err)
+	}
+	if current != nil && !requester.Role.CanManageMember(current.Role) ||
+		!requester.Role.CanManageMember(dto.Role) {
+		return nil, apperror.ForbiddenError(fmt.Sprintf("%s can't manage the member", requester.Role))
+	}
+
+	m, err := s.repository.SetMember(ctx, &organization.Member{
+		OrganizationID: dto.OrganizationID,
+		UserID:         dto.MemberID,
+		Role:           dto.Role,
+		JoinedAt:       time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save member of organization. error: %w", err)
+	}
+
+	if m.UserID != dto.UserID {
+		s.events.Publish(ctx, m.UserID, event.TypeMembership, m)
+	}
+	return m, nil
+}
+
+func (s *service) RemoveMember(ctx context.Context, organizationID, userID, memberID uint) error {
+	m, err := s.repository.FindMember(ctx, organizationID, memberID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to find member of organization. error: %w", err)
+	}
+	if userID != memberID {
+		requester, err := s.findRequester(ctx, organizationID, userID)
+		if err != nil {
+			return err
+		}
+		if !requester.Role.CanManageMember(m.Role) {
+			return apperror.ForbiddenError(fmt.Sprintf("%s can't manage the member", requester.Role))
+		}
+	}
+
+	if err = s.repository.RemoveMember(ctx, organizationID, memberID); err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return err
+		}
+		return fmt.Errorf("failed to remove member of organization. error: %w", err)
+	}
+
+	if userID != memberID {
+		s.events.Publish(ctx, memberID, event.TypeMembership, m)
+	}
+	return nil
+}
+
+func (s *service) find(ctx context.Context, id uint) (*organization.Organization, error) {
+	o, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find organization by its id. error: %w", err)
+	}
+	return o, nil
+}
+
+// findRequester returns membership of the user making changes of the organization.
+func (s *service) findRequester(ctx context.Context, organizationID, userID uint) (*organization.Member, error) {
+	m, err := s.repository.FindMember(ctx, organizationID, userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, apperror.ForbiddenError("only members can change the organization")
+		}
+		return nil, fmt.Errorf("failed to find member of organization. error: %w", err)
+	}
+	return m, nil
+}
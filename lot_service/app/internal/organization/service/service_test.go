@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"testing"
+)
+
+type repo struct {
+	storage.Repository
+	roles   map[uint]organization.Role
+	removed []uint
+}
+
+func (r *repo) FindMember(_ context.Context, organizationID, userID uint) (*organization.Member, error) {
+	role, ok := r.roles[userID]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return &organization.Member{OrganizationID: organizationID, UserID: userID, Role: role}, nil
+}
+
+func (r *repo) SetMember(_ context.Context, m *organization.Member) (*organization.Member, error) {
+	r.roles[m.UserID] = m.Role
+	return m, nil
+}
+
+func (r *repo) RemoveMember(_ context.Context, _, userID uint) error {
+	r.removed = append(r.removed, userID)
+	return nil
+}
+
+type publisher struct {
+	recipients []uint
+}
+
+func (p *publisher) Publish(_ context.Context, userID uint, _ string, _ any) {
+	p.recipients = append(p.recipients, userID)
+}
+
+const (
+	owner   = 1
+	manager = 2
+	agent   = 3
+	other   = 4
+)
+
+func newRepo() *repo {
+	return &repo{roles: map[uint]organization.Role{
+		owner:   organization.RoleOwner,
+		manager: organization.RoleManager,
+		agent:   organization.RoleAgent,
+	}}
+}
+
+func TestSetMember(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID uint
+		member uint
+		role   organization.Role
+		want   error
+	}{
+		{name: "owner adds manager", userID: owner, member: other, role: organization.RoleManager},
+		{name: "owner promotes agent", userID: owner, member: agent, role: organization.RoleOwner},
+		{name: "manager adds agent", userID: manager, member: other, role: organization.RoleAgent},
+		{
+			name: "manager adds manager", userID: manager, member: other, role: organization.RoleManager,
+			want: apperror.ForbiddenError(""),
+		},
+		{
+			name: "manager demotes owner", userID: manager, member: owner, role: organization.RoleAgent,
+			want: apperror.ForbiddenError(""),
+		},
+		{
+			name: "agent adds agent", userID: agent, member: other, role: organization.RoleAgent,
+			want: apperror.ForbiddenError(""),
+		},
+		{
+			name: "not a member", userID: other, member: other, role: organization.RoleOwner,
+			want: apperror.ForbiddenError(""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRepo()
+			p := &publisher{}
+			s, _ := NewService(r, nil, p, logging.GetLogger())
+
+			m, err := s.SetMember(context.Background(), &organization.SetMemberDTO{
+				OrganizationID: 1,
+				UserID:         tt.userID,
+				MemberID:       tt.member,
+				Role:           tt.role,
+			})
+			if tt.want != nil {
+				if !sameError(err, tt.want) {
+					t.Fatalf("expected %v, got %v", tt.want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if m.Role != tt.role || r.roles[tt.member] != tt.role {
+				t.Errorf("expected role %s, got %s", tt.role, r.roles[tt.member])
+			}
+			if len(p.recipients) != 1 || p.recipients[0] != tt.member {
+				t.Errorf("expected event for member %d, got %v", tt.member, p.recipients)
+			}
+		})
+	}
+}
+
+func TestRemoveMember(t *testing.T) {
+	r := newRepo()
+	p := &publisher{}
+	s, _ := NewService(r, nil, p, logging.GetLogger())
+	ctx := context.Background()
+
+	if err := s.RemoveMember(ctx, 1, manager, owner); !sameError(err, apperror.ForbiddenError("")) {
+		t.Errorf("expected forbidden error for manager removing owner, got %v", err)
+	}
+	if err := s.RemoveMember(ctx, 1, manager, other); !errors.Is(err, apperror.ErrNotFound) {
+		t.Errorf("expected not found error for unknown member, got %v", err)
+	}
+	if err := s.RemoveMember(ctx, 1, agent, agent); err != nil {
+		t.Fatalf("agent must be able to leave, got %v", err)
+	}
+	if len(p.recipients) != 0 {
+		t.Errorf("member leaving by themselves must not be notified, got %v", p.recipients)
+	}
+	if err := s.RemoveMember(ctx, 1, owner, manager); err != nil {
+		t.Fatalf("owner must be able to remove manager, got %v", err)
+	}
+	if len(r.removed) != 2 || len(p.recipients) != 1 || p.recipients[0] != manager {
+		t.Errorf("expected manager removed and notified, got removed %v, notified %v", r.removed, p.recipients)
+	}
+}
+
+// sameError compares app errors by code, since their messages differ.
+func sameError(err, want error) bool {
+	var got, expected *apperror.AppError
+	if !errors.As(err, &got) || !errors.As(want, &expected) {
+		return false
+	}
+	return got.Code == expected.Code
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+)
+
+type Repository interface {
+	// Create saves the organization with the user as its owner.
+	Create(ctx context.Context, o *organization.Organization, ownerID uint) (uint, error)
+	FindByID(ctx context.Context, id uint) (*organization.Organization, error)
+	// FindByMemberID returns organizations, which the user is a member of.
+	FindByMemberID(ctx context.Context, userID uint) ([]*organization.Organization, error)
+	Update(ctx context.Context, o *organization.Organization) error
+	// CountLots returns number of lots owned by the organization.
+	CountLots(ctx context.Context, id uint) (int, error)
+
+	FindMember(ctx context.Context, organizationID, userID uint) (*organization.Member, error)
+	FindMembers(ctx context.Context, organizationID uint) ([]*organization.Member, error)
+	// SetMember adds the user to the organization or changes the role. Organization must keep an owner.
+	SetMember(ctx context.Context, m *organization.Member) (*organization.Member, error)
+	// RemoveMember removes the member, if no lots of the organization are assigned to the member.
+	// Organization must keep an owner.
+	RemoveMember(ctx context.Context, organizationID, userID uint) error
+}
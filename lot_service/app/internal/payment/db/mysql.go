@@ -0,0 +1,472 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/payment"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/payment/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"strings"
+	"time"
+)
+
+var _ storage.Repository = &db{}
+
+// errDuplicateEntry is code of MySQL error on violation of unique key.
+const errDuplicateEntry = 1062
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is either database or transaction.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+const paymentColumns = `
+	payment_id, booking_id, lot_id, payer_id, payee_id, kind, period, amount, refunded, currency, status,
+	provider, IFNULL(provider_id, ''), checkout_url, created_at, paid_at`
+
+func scanPayment(row scanner) (*payment.Payment, error) {
+	p := &payment.Payment{}
+	var createdAt mysql.RawTime
+	var paidAt *mysql.RawTime
+	err := row.Scan(&p.ID, &p.BookingID, &p.LotID, &p.PayerID, &p.PayeeID, &p.Kind, &p.Period, &p.Amount,
+		&p.Refunded, &p.Currency, &p.Status, &p.Provider, &p.ProviderID, &p.CheckoutURL, &createdAt, &paidAt)
+	if err != nil {
+		return nil, err
+	}
+	if p.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	if paidAt != nil {
+		t, err := paidAt.Time()
+		if err != nil {
+			return nil, err
+		}
+		p.PaidAt = &t
+	}
+	return p, nil
+}
+
+func findPayment(ctx context.Context, q querier, id uint, forUpdate bool) (*payment.Payment, error) {
+	queryString := `
+	SELECT` + paymentColumns + `
+	FROM payments
+	WHERE payment_id=?`
+	if forUpdate {
+		queryString += ` FOR UPDATE`
+	}
+
+	p, err := scanPayment(q.QueryRowContext(ctx, queryString, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// lockBooking serializes creation of payments of the booking.
+func lockBooking(ctx context.Context, tx *sql.Tx, bookingID uint) error {
+	var id uint
+	err := tx.QueryRowContext(ctx, `SELECT booking_id FROM bookings WHERE booking_id=? FOR UPDATE;`, bookingID).
+		Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.ErrNotFound
+	}
+	return err
+}
+
+func (s *db) Create(ctx context.Context, p *payment.Payment) (*payment.Payment, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = lockBooking(ctx, tx, p.BookingID); err != nil {
+		return nil, err
+	}
+	var status payment.Status
+	err = tx.QueryRowContext(ctx, `
+	SELECT status
+	FROM payments
+	WHERE booking_id=? AND kind=? AND period=? AND status IN (?, ?)
+	LIMIT 1;`, p.BookingID, p.Kind, p.Period, payment.StatusPending, payment.StatusSucceeded).Scan(&status)
+	switch {
+	case err == nil && status == payment.StatusPending:
+		return nil, apperror.ConflictError("payment is waiting for the payer already")
+	case err == nil:
+		return nil, apperror.ConflictError("payment has been paid already")
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO payments (booking_id, lot_id, payer_id, payee_id, kind, period, amount, currency, status, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		p.BookingID, p.LotID, p.PayerID, p.PayeeID, p.Kind, p.Period, p.Amount, p.Currency, payment.StatusPending,
+		p.CreatedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := findPayment(ctx, tx, uint(retID), false)
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func (s *db) SetIntent(ctx context.Context, id uint, provider, providerID, checkoutURL string) error {
+	_, err := s.db.ExecContext(ctx, `
+	UPDATE payments
+	SET provider=?, provider_id=?, checkout_url=?
+	WHERE payment_id=?;`, provider, providerID, checkoutURL, id)
+	return err
+}
+
+func (s *db) Fail(ctx context.Context, id uint) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE payments SET status=? WHERE payment_id=? AND status=?;`,
+		payment.StatusFailed, id, payment.StatusPending)
+	return err
+}
+
+func (s *db) FindByID(ctx context.Context, id uint) (*payment.Payment, error) {
+	return findPayment(ctx, s.db, id, false)
+}
+
+func (s *db) FindByBookingID(ctx context.Context, bookingID uint) ([]*payment.Payment, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT`+paymentColumns+`
+	FROM payments
+	WHERE booking_id=?
+	ORDER BY payment_id DESC;`, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]*payment.Payment, 0)
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (s *db) ApplyEvent(ctx context.Context, provider, eventID, providerID string, status payment.Status,
+	at time.Time) (*payment.Payment, bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO payment_webhook_events (provider, event_id, received_at)
+	VALUES (?, ?, ?);`, provider, eventID, at.UTC())
+	if err != nil {
+		var mysqlErr *driver.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var id uint
+	err = tx.QueryRowContext(ctx, `SELECT payment_id FROM payments WHERE provider=? AND provider_id=?;`,
+		provider, providerID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, apperror.ErrNotFound
+		}
+		return nil, false, err
+	}
+	p, err := findPayment(ctx, tx, id, true)
+	if err != nil {
+		return nil, false, err
+	}
+	// late events don't change finished payments, but they are recorded as received
+	if p.Status != payment.StatusPending {
+		return p, false, tx.Commit()
+	}
+
+	if status == payment.StatusSucceeded {
+		_, err = tx.ExecContext(ctx, `UPDATE payments SET status=?, paid_at=? WHERE payment_id=?;`,
+			status, at.UTC(), p.ID)
+		if err == nil {
+			err = addEntries(ctx, tx, p, nil, payment.EntryPayment, p.PayerID, p.PayeeID, p.Amount, at)
+		}
+	} else {
+		_, err = tx.ExecContext(ctx, `UPDATE payments SET status=? WHERE payment_id=?;`, status, p.ID)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	applied, err := findPayment(ctx, tx, p.ID, false)
+	if err != nil {
+		return nil, false, err
+	}
+	return applied, true, tx.Commit()
+}
+
+// addEntries writes movement of amount from one user to another into the ledger.
+func addEntries(ctx context.Context, q querier, p *payment.Payment, refundID *uint, entryType payment.EntryType,
+	from, to uint, amount int64, at time.Time) error {
+	_, err := q.ExecContext(ctx, `
+	INSERT INTO ledger_entries (user_id, payment_id, refund_id, type, amount, currency, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?);`,
+		from, p.ID, refundID, entryType, -amount, p.Currency, at.UTC(),
+		to, p.ID, refundID, entryType, amount, p.Currency, at.UTC())
+	return err
+}
+
+const refundColumns = `refund_id, payment_id, amount, reason, status, idempotency_key, IFNULL(provider_id, ''), created_at`
+
+func scanRefund(row scanner) (*payment.Refund, error) {
+	r := &payment.Refund{}
+	var createdAt mysql.RawTime
+	err := row.Scan(&r.ID, &r.PaymentID, &r.Amount, &r.Reason, &r.Status, &r.IdempotencyKey, &r.ProviderID,
+		&createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if r.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func findRefund(ctx context.Context, q querier, where string, args ...any) (*payment.Refund, error) {
+	r, err := scanRefund(q.QueryRowContext(ctx, `
+	SELECT `+refundColumns+`
+	FROM payment_refunds
+	WHERE `+where, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *db) CreateRefund(ctx context.Context, r *payment.Refund) (*payment.Refund, bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	p, err := findPayment(ctx, tx, r.PaymentID, true)
+	if err != nil {
+		return nil, false, err
+	}
+	existing, err := findRefund(ctx, tx, `payment_id=? AND idempotency_key=?;`, r.PaymentID, r.IdempotencyKey)
+	if err == nil {
+		return existing, false, nil
+	} else if !errors.Is(err, apperror.ErrNotFound) {
+		return nil, false, err
+	}
+
+	if p.Status != payment.StatusSucceeded {
+		return nil, false, apperror.ConflictError("only succeeded payments can be refunded")
+	}
+	if p.Refunded+r.Amount > p.Amount {
+		return nil, false, apperror.ConflictError("refunds can't exceed amount of the payment")
+	}
+
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO payment_refunds (payment_id, amount, reason, status, idempotency_key, created_at)
+	VALUES (?, ?, ?, ?, ?, ?);`, r.PaymentID, r.Amount, r.Reason, payment.RefundPending, r.IdempotencyKey,
+		r.CreatedAt.UTC())
+	if err != nil {
+		return nil, false, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE payments SET refunded=refunded+? WHERE payment_id=?;`,
+		r.Amount, r.PaymentID); err != nil {
+		return nil, false, err
+	}
+
+	created, err := findRefund(ctx, tx, `refund_id=?;`, retID)
+	if err != nil {
+		return nil, false, err
+	}
+	return created, true, tx.Commit()
+}
+
+func (s *db) CompleteRefund(ctx context.Context, id uint, providerID string, at time.Time) (*payment.Refund, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	r, err := findRefund(ctx, tx, `refund_id=? FOR UPDATE;`, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != payment.RefundPending {
+		return nil, apperror.ConflictError("refund is finished already")
+	}
+	p, err := findPayment(ctx, tx, r.PaymentID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE payment_refunds SET status=?, provider_id=? WHERE refund_id=?;`,
+		payment.RefundSucceeded, providerID, id); err != nil {
+		return nil, err
+	}
+	if err = addEntries(ctx, tx, p, &r.ID, payment.EntryRefund, p.PayeeID, p.PayerID, r.Amount, at); err != nil {
+		return nil, err
+	}
+
+	completed, err := findRefund(ctx, tx, `refund_id=?;`, id)
+	if err != nil {
+		return nil, err
+	}
+	return completed, tx.Commit()
+}
+
+func (s *db) FailRefund(ctx context.Context, id uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	r, err := findRefund(ctx, tx, `refund_id=? FOR UPDATE;`, id)
+	if err != nil {
+		return err
+	}
+	if r.Status != payment.RefundPending {
+		return nil
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE payment_refunds SET status=? WHERE refund_id=?;`,
+		payment.RefundFailed, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE payments SET refunded=refunded-? WHERE payment_id=?;`,
+		r.Amount, r.PaymentID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *db) FindRefunds(ctx context.Context, paymentID uint) ([]*payment.Refund, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT `+refundColumns+`
+	FROM payment_refunds
+	WHERE payment_id=?
+	ORDER BY refund_id;`, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := make([]*payment.Refund, 0)
+	for rows.Next() {
+		r, err := scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+func (s *db) FindEntries(ctx context.Context, userID, afterID uint, limit uint64) ([]*payment.Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT entry_id, user_id, payment_id, refund_id, type, amount, currency, created_at
+	FROM ledger_entries
+	WHERE user_id=? AND entry_id>?
+	ORDER BY entry_id
+	LIMIT ?;`, userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*payment.Entry, 0)
+	for rows.Next() {
+		e := &payment.Entry{}
+		var refundID sql.NullInt64
+		var createdAt mysql.RawTime
+		if err = rows.Scan(&e.ID, &e.UserID, &e.PaymentID, &refundID, &e.Type, &e.Amount, &e.Currency,
+			&createdAt); err != nil {
+			return nil, err
+		}
+		if e.CreatedAt, err = createdAt.Time(); err != nil {
+			return nil, err
+		}
+		if refundID.Valid {
+			id := uint(refundID.Int64)
+			e.RefundID = &id
+		}
+		entries = append(entries, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *db) FindName(ctx context.Context, userID uint) (string, error) {
+	var username, givenName, familyName string
+	err := s.db.QueryRowContext(ctx, `
+	SELECT username, IFNULL(given_name, ''), IFNULL(family_name, '')
+	FROM users
+	WHERE user_id=?;`, userID).Scan(&username, &givenName, &familyName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", apperror.ErrNotFound
+		}
+		return "", err
+	}
+
+	if name := strings.TrimSpace(givenName + " " + familyName); name != "" {
+		return name, nil
+	}
+	return username, nil
+}
@@ -0,0 +1,222 @@
+package payment
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindDeposit Kind = "deposit" // security deposit of the booking, paid once
+	KindRent    Kind = "rent"    // monthly rent, paid once per month of the stay
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending" // waiting for the payer on checkout page of the provider
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+const (
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+)
+
+// Currency of payments of lots without currency, amounts are kept in minor units of currencies of payments.
+const Currency = "RUB"
+
+// PeriodLayout is format of month of rent.
+const PeriodLayout = "2006-01"
+
+// Payment is collected from the renter of accepted booking for its landlord through payment provider.
+// Card data is entered on checkout page of the provider and never reaches the service.
+type Payment struct {
+	ID        uint   `json:"id"`
+	BookingID uint   `json:"booking_id"`
+	LotID     uint   `json:"lot_id"`
+	PayerID   uint   `json:"payer_id"` // the renter
+	PayeeID   uint   `json:"payee_id"` // the landlord
+	Kind      Kind   `json:"kind"`
+	Period    string `json:"period,omitempty"` // month of rent, YYYY-MM
+	Amount    int64  `json:"amount"`           // in kopecks
+	Refunded  int64  `json:"refunded"`         // in kopecks, including refunds in progress
+	Currency  string `json:"currency"`
+	Status    Status `json:"status"`
+	Provider  string `json:"provider"`
+	// ProviderID is ID of payment intent of the provider.
+	ProviderID  string     `json:"provider_id,omitempty"`
+	CheckoutURL string     `json:"checkout_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+}
+
+// PartyOf returns the side of the payment the user belongs to.
+func (p *Payment) PartyOf(userID uint) (booking.Party, bool) {
+	switch userID {
+	case p.PayerID:
+		return booking.PartyRenter, true
+	case p.PayeeID:
+		return booking.PartyLandlord, true
+	default:
+		return "", false
+	}
+}
+
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending" // amount is reserved, the provider is asked to refund it
+	RefundSucceeded RefundStatus = "succeeded"
+	RefundFailed    RefundStatus = "failed"
+)
+
+// Refund returns part or all of succeeded payment to the payer.
+type Refund struct {
+	ID        uint         `json:"id"`
+	PaymentID uint         `json:"payment_id"`
+	Amount    int64        `json:"amount"` // in kopecks
+	Reason    string       `json:"reason,omitempty"`
+	Status    RefundStatus `json:"status"`
+	// IdempotencyKey makes repeated requests return the same refund.
+	IdempotencyKey string    `json:"-"`
+	ProviderID     string    `json:"provider_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type EntryType string
+
+const (
+	EntryPayment EntryType = "payment"
+	EntryRefund  EntryType = "refund"
+)
+
+// Entry is a movement of money in the ledger of the user. Every movement makes two entries:
+// negative one of the party paying and positive one of the party receiving.
+type Entry struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	PaymentID uint      `json:"payment_id"`
+	RefundID  *uint     `json:"refund_id,omitempty"`
+	Type      EntryType `json:"type"`
+	Amount    int64     `json:"amount"` // in kopecks
+	Currency  string    `json:"currency"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Document is rendered receipt.
+type Document struct {
+	ContentType string
+	FileName    string
+	Data        []byte
+}
+
+type CreatePaymentDTO struct {
+	BookingID uint   `json:"booking_id"`
+	UserID    uint   `json:"user_id"`
+	Kind      Kind   `json:"kind"`
+	Period    string `json:"period"` // required for rent
+}
+
+type CreateRefundDTO struct {
+	PaymentID      uint   `json:"payment_id"`
+	UserID         uint   `json:"user_id"`
+	Amount         int64  `json:"amount"` // in kopecks
+	Reason         string `json:"reason"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+// SimulateDTO finishes checkout of the payment with providers, which have no checkout pages.
+type SimulateDTO struct {
+	ID      uint   `json:"id"`
+	UserID  uint   `json:"user_id"`
+	Outcome Status `json:"outcome"`
+}
+
+func (dto *CreatePaymentDTO) ValidateFields() error {
+	isRent := dto.Kind == KindRent
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.BookingID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Kind, validation.Required, validation.In(KindDeposit, KindRent)),
+		validation.Field(&dto.Period, validation.By(func(value interface{}) error {
+			if !isRent {
+				if !validation.IsEmpty(value) {
+					return errors.New("must be blank for deposit")
+				}
+				return nil
+			}
+			return validation.Validate(value, validation.Required, validation.Date(PeriodLayout))
+		})),
+	)
+}
+
+func (dto *CreateRefundDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.PaymentID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Amount, validation.Required, validation.Min(int64(1))),
+		validation.Field(&dto.Reason, validation.Length(0, 500)),
+		validation.Field(&dto.IdempotencyKey, validation.Length(0, 64)),
+	)
+}
+
+func (dto *SimulateDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Outcome, validation.Required, validation.In(StatusSucceeded, StatusFailed)),
+	)
+}
+
+// CheckPeriod checks, that the month of rent intersects with the stay.
+func CheckPeriod(period string, b *booking.Booking) error {
+	month, err := time.Parse(PeriodLayout, period)
+	if err != nil {
+		return err
+	}
+	if !b.Overlaps(month, month.AddDate(0, 1, 0)) {
+		return errors.New("month of rent must be within the stay")
+	}
+	return nil
+}
+
+// Amount returns amount of the payment of the booking in minor units. Price of the stay quoted on acceptance
+// is split between months of rent by nights of the stay in them, deposit is equal to rent of the first month
+// of the stay.
+func Amount(b *booking.Booking, kind Kind, period string) (int64, error) {
+	from := b.CheckIn
+	if kind == KindRent {
+		month, err := time.Parse(PeriodLayout, period)
+		if err != nil {
+			return 0, err
+		}
+		from = month
+	}
+	return share(b, from, from.AddDate(0, 1, 0)), nil
+}
+
+// share returns part of price of the stay for its nights from the day to the other one, shares of adjacent
+// periods add up to the whole price.
+func share(b *booking.Booking, from, to time.Time) int64 {
+	total, nights := int64(b.Total)*100, nightsBefore(b, b.CheckOut)
+	if nights == 0 {
+		return 0
+	}
+	return total*nightsBefore(b, to)/nights - total*nightsBefore(b, from)/nights
+}
+
+// nightsBefore returns number of nights of the stay before the day.
+func nightsBefore(b *booking.Booking, day time.Time) int64 {
+	if day.Before(b.CheckIn) {
+		return 0
+	}
+	if day.After(b.CheckOut) {
+		day = b.CheckOut
+	}
+	return int64(day.Sub(b.CheckIn).Hours()) / 24
+}
@@ -0,0 +1,98 @@
+package payment
+
+import (
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/pdf"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Receipt confirms succeeded payment to its parties.
+type Receipt struct {
+	Payment   *Payment
+	Refunds   []*Refund
+	PayerName string
+	PayeeName string
+}
+
+// FormatAmount formats amount in kopecks as rubles with spaces between thousands, e.g. "45 000,00".
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	s := fmt.Sprint(amount / 100)
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	return fmt.Sprintf("%s%s,%02d", sign, b.String(), amount%100)
+}
+
+func (r *Receipt) title() string {
+	return fmt.Sprintf("Квитанция № %d", r.Payment.ID)
+}
+
+// lines describes the payment and its succeeded refunds.
+func (r *Receipt) lines() []string {
+	p := r.Payment
+	purpose := fmt.Sprintf("Залог по бронированию № %d", p.BookingID)
+	if p.Kind == KindRent {
+		purpose = fmt.Sprintf("Арендная плата за %s по бронированию № %d", p.Period, p.BookingID)
+	}
+	lines := []string{
+		"Дата оплаты: " + p.PaidAt.UTC().Format("02.01.2006 15:04:05") + " UTC",
+		"Плательщик: " + r.PayerName,
+		"Получатель: " + r.PayeeName,
+		fmt.Sprintf("Назначение: %s, объявление № %d", purpose, p.LotID),
+		fmt.Sprintf("Сумма: %s %s", FormatAmount(p.Amount), p.Currency),
+		fmt.Sprintf("Платёжная система: %s, платёж %s", p.Provider, p.ProviderID),
+	}
+	for _, refund := range r.Refunds {
+		if refund.Status != RefundSucceeded {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("Возврат от %s: %s %s",
+			refund.CreatedAt.UTC().Format("02.01.2006"), FormatAmount(refund.Amount), p.Currency))
+	}
+	return lines
+}
+
+var htmlTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: serif; max-width: 40em; margin: 2em auto; line-height: 1.4; }
+h2 { text-align: center; font-size: 1.1em; }
+</style>
+</head>
+<body>
+<h2>{{.Title}}</h2>
+{{range .Lines}}<div>{{.}}</div>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes the receipt as HTML page.
+func WriteHTML(w io.Writer, r *Receipt) error {
+	return htmlTemplate.Execute(w, struct {
+		Title string
+		Lines []string
+	}{r.title(), r.lines()})
+}
+
+// WritePDF writes the receipt as PDF document with the font.
+func WritePDF(w io.Writer, font *pdf.Font, r *Receipt) error {
+	d := pdf.New(font)
+	d.Title = r.title()
+	d.Created = *r.Payment.PaidAt
+	d.Heading(r.title())
+	d.Paragraph(strings.Join(r.lines(), "\n"))
+	return d.Write(w)
+}
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	bookingStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/payment"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/payment/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	provider "github.com/levelord1311/backendForSharedProject/lot_service/pkg/payment"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/pdf"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultEntriesLimit = 100
+	maxEntriesLimit     = 1000
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	// Create makes payment of accepted booking and intent of the provider, the renter pays it on the checkout page.
+	// Amounts are shares of price of the stay quoted on acceptance, see payment.Amount. Lots for sale aren't paid.
+	Create(ctx context.Context, dto *payment.CreatePaymentDTO) (*payment.Payment, error)
+	GetByBookingID(ctx context.Context, bookingID, userID uint) ([]*payment.Payment, error)
+	GetByID(ctx context.Context, id, userID uint) (*payment.Payment, error)
+	// HandleWebhook applies event of the provider to the payment. Repeated events are ignored.
+	HandleWebhook(ctx context.Context, providerName string, header http.Header, body []byte) error
+	// Simulate finishes checkout of pending payment with providers, which have no checkout pages.
+	Simulate(ctx context.Context, dto *payment.SimulateDTO) (*payment.Payment, error)
+
+	// Refund returns amount of succeeded payment to the payer. Only the payee can refund.
+	Refund(ctx context.Context, dto *payment.CreateRefundDTO) (*payment.Refund, error)
+	GetRefunds(ctx context.Context, paymentID, userID uint) ([]*payment.Refund, error)
+
+	// GetLedger returns movements of money of the user after the given entry, the oldest first.
+	GetLedger(ctx context.Context, userID, afterID uint, limit uint64) ([]*payment.Entry, error)
+	// GetReceipt renders receipt of succeeded payment in html or pdf format.
+	GetReceipt(ctx context.Context, id, userID uint, format string) (*payment.Document, error)
+}
+
+type Config struct {
+	// FontPath is TrueType font of PDF receipts, it must support Cyrillic. Empty path or font, which fails
+	// to load, disables PDF.
+	FontPath string
+}
+
+type service struct {
+	repository storage.Repository
+	bookings   bookingStorage.Repository
+	lots       lotStorage.Repository
+	provider   provider.Provider
+	events     eventService.Publisher
+	font       *pdf.Font
+	logger     logging.Logger
+}
+
+func NewService(paymentStorage storage.Repository, bookings bookingStorage.Repository, lots lotStorage.Repository,
+	paymentProvider provider.Provider, events eventService.Publisher, cfg Config,
+	logger logging.Logger) (*service, error) {
+	s := &service{
+		repository: paymentStorage,
+		bookings:   bookings,
+		lots:       lots,
+		provider:   paymentProvider,
+		events:     events,
+		logger:     logger,
+	}
+	if cfg.FontPath != "" {
+		font, err := pdf.LoadFont(cfg.FontPath)
+		if err != nil {
+			logger.Warnf("failed to load font of receipts, pdf documents are disabled. error: %v", err)
+		} else {
+			s.font = font
+		}
+	}
+	return s, nil
+}
+
+func (s *service) Create(ctx context.Context, dto *payment.CreatePaymentDTO) (*payment.Payment, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	b, err := s.findBooking(ctx, dto.BookingID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if b.RenterID != dto.UserID {
+		return nil, apperror.ForbiddenError("only the renter pays for the booking")
+	}
+	if b.Status != booking.StatusAccepted {
+		return nil, apperror.ConflictError("only accepted booking can be paid")
+	}
+	if dto.Kind == payment.KindRent {
+		if err = payment.CheckPeriod(dto.Period, b); err != nil {
+			return nil, apperror.BadRequestError(err.Error(), "")
+		}
+	}
+	l, err := s.lots.FindByLotID(ctx, b.LotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot. error: %w", err)
+	}
+	if l.PricePeriod == lot.PeriodSale {
+		return nil, apperror.BadRequestError("lots for sale aren't paid through bookings", "")
+	}
+	if b.Total == 0 {
+		return nil, apperror.ConflictError("booking has no price of the stay")
+	}
+	amount, err := payment.Amount(b, dto.Kind, dto.Period)
+	if err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	currency := b.Currency
+	if currency == "" {
+		currency = payment.Currency
+	}
+	p := &payment.Payment{
+		BookingID: b.ID,
+		LotID:     b.LotID,
+		PayerID:   b.RenterID,
+		PayeeID:   b.LandlordID,
+		Kind:      dto.Kind,
+		Period:    dto.Period,
+		Amount:    amount,
+		Currency:  currency,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	s.logger.Debug("creating new payment..")
+	p, err = s.repository.Create(ctx, p)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) || errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create payment. error: %w", err)
+	}
+
+	intent, err := s.provider.CreateIntent(ctx, &provider.IntentRequest{
+		Amount:         p.Amount,
+		Currency:       p.Currency,
+		Description:    fmt.Sprintf("%s of booking %d", p.Kind, p.BookingID),
+		IdempotencyKey: fmt.Sprintf("payment-%d", p.ID),
+	})
+	if err != nil {
+		if failErr := s.repository.Fail(ctx, p.ID); failErr != nil {
+			s.logger.Errorf("failed to mark payment %d as failed. error: %v", p.ID, failErr)
+		}
+		return nil, fmt.Errorf("failed to create payment intent. error: %w", err)
+	}
+	if err = s.repository.SetIntent(ctx, p.ID, s.provider.Name(), intent.ID, intent.CheckoutURL); err != nil {
+		return nil, fmt.Errorf("failed to save payment intent. error: %w", err)
+	}
+	p.Provider, p.ProviderID, p.CheckoutURL = s.provider.Name(), intent.ID, intent.CheckoutURL
+	return p, nil
+}
+
+func (s *service) GetByBookingID(ctx context.Context, bookingID, userID uint) ([]*payment.Payment, error) {
+	if _, err := s.findBooking(ctx, bookingID, userID); err != nil {
+		return nil, err
+	}
+
+	payments, err := s.repository.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find payments of booking. error: %w", err)
+	}
+	return payments, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id, userID uint) (*payment.Payment, error) {
+	p, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find payment. error: %w", err)
+	}
+	if _, ok := p.PartyOf(userID); !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return p, nil
+}
+
+func (s *service) HandleWebhook(ctx context.Context, providerName string, header http.Header, body []byte) error {
+	if providerName != s.provider.Name() {
+		return apperror.ErrNotFound
+	}
+	e, err := s.provider.ParseWebhook(header, body)
+	if err != nil {
+		if errors.Is(err, provider.ErrInvalidSignature) {
+			return apperror.UnauthorizedError(err.Error())
+		}
+		return apperror.BadRequestError("invalid webhook", err.Error())
+	}
+
+	var status payment.Status
+	switch e.Type {
+	case provider.EventSucceeded:
+		status = payment.StatusSucceeded
+	case provider.EventFailed:
+		status = payment.StatusFailed
+	default:
+		s.logger.Infof("skipping %s event %s of unknown type %q", providerName, e.ID, e.Type)
+		return nil
+	}
+
+	p, applied, err := s.repository.ApplyEvent(ctx, providerName, e.ID, e.IntentID, status,
+		time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to apply payment event. error: %w", err)
+	}
+	if applied {
+		s.notify(ctx, p)
+	}
+	return nil
+}
+
+func (s *service) Simulate(ctx context.Context, dto *payment.SimulateDTO) (*payment.Payment, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	simulator, ok := s.provider.(provider.Simulator)
+	if !ok {
+		return nil, apperror.BadRequestError("payment provider has its own checkout", "")
+	}
+
+	p, err := s.GetByID(ctx, dto.ID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if p.PayerID != dto.UserID {
+		return nil, apperror.ForbiddenError("only the payer can pay")
+	}
+	if p.Status != payment.StatusPending || p.ProviderID == "" {
+		return nil, apperror.ConflictError("payment is not waiting for the payer")
+	}
+
+	eventType := provider.EventSucceeded
+	if dto.Outcome == payment.StatusFailed {
+		eventType = provider.EventFailed
+	}
+	header, body, err := simulator.Simulate(p.ProviderID, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate checkout. error: %w", err)
+	}
+	if err = s.HandleWebhook(ctx, p.Provider, header, body); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, p.ID, dto.UserID)
+}
+
+func (s *service) Refund(ctx context.Context, dto *payment.CreateRefundDTO) (*payment.Refund, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	p, err := s.GetByID(ctx, dto.PaymentID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if p.PayeeID != dto.UserID {
+		return nil, apperror.ForbiddenError("only the payee can refund")
+	}
+	if dto.IdempotencyKey == "" {
+		if dto.IdempotencyKey, err = randomKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	r, created, err := s.repository.CreateRefund(ctx, &payment.Refund{
+		PaymentID:      p.ID,
+		Amount:         dto.Amount,
+		Reason:         dto.Reason,
+		IdempotencyKey: dto.IdempotencyKey,
+		CreatedAt:      time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create refund. error: %w", err)
+	}
+	if !created {
+		return r, nil
+	}
+
+	providerID, err := s.provider.Refund(ctx, p.ProviderID, r.Amount, fmt.Sprintf("refund-%d", r.ID))
+	if err != nil {
+		if failErr := s.repository.FailRefund(ctx, r.ID); failErr != nil {
+			s.logger.Errorf("failed to release refund %d. error: %v", r.ID, failErr)
+		}
+		return nil, fmt.Errorf("failed to refund payment. error: %w", err)
+	}
+	r, err = s.repository.CompleteRefund(ctx, r.ID, providerID, time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete refund. error: %w", err)
+	}
+
+	if p, err = s.repository.FindByID(ctx, p.ID); err == nil {
+		s.notify(ctx, p)
+	}
+	return r, nil
+}
+
+func (s *service) GetRefunds(ctx context.Context, paymentID, userID uint) ([]*payment.Refund, error) {
+	if _, err := s.GetByID(ctx, paymentID, userID); err != nil {
+		return nil, err
+	}
+
+	refunds, err := s.repository.FindRefunds(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find refunds. error: %w", err)
+	}
+	return refunds, nil
+}
+
+func (s *service) GetLedger(ctx context.Context, userID, afterID uint, limit uint64) ([]*payment.Entry, error) {
+	if limit == 0 {
+		limit = defaultEntriesLimit
+	} else if limit > maxEntriesLimit {
+		limit = maxEntriesLimit
+	}
+
+	entries, err := s.repository.FindEntries(ctx, userID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find ledger entries. error: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *service) GetReceipt(ctx context.Context, id, userID uint, format string) (*payment.Document, error) {
+	p, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != payment.StatusSucceeded {
+		return nil, apperror.ConflictError("receipts are issued for succeeded payments only")
+	}
+
+	receipt := &payment.Receipt{Payment: p}
+	if receipt.Refunds, err = s.repository.FindRefunds(ctx, p.ID); err != nil {
+		return nil, fmt.Errorf("failed to find refunds. error: %w", err)
+	}
+	if receipt.PayerName, err = s.repository.FindName(ctx, p.PayerID); err != nil {
+		return nil, fmt.Errorf("failed to find payer. error: %w", err)
+	}
+	if receipt.PayeeName, err = s.repository.FindName(ctx, p.PayeeID); err != nil {
+		return nil, fmt.Errorf("failed to find payee. error: %w", err)
+	}
+
+	var buf bytes.Buffer
+	doc := &payment.Document{FileName: fmt.Sprintf("receipt-%d.%s", p.ID, format)}
+	switch format {
+	case payment.FormatHTML:
+		doc.ContentType = "text/html; charset=utf-8"
+		err = payment.WriteHTML(&buf, receipt)
+	case payment.FormatPDF:
+		if s.font == nil {
+			return nil, apperror.BadRequestError("pdf documents are not available", "font is not configured")
+		}
+		doc.ContentType = "application/pdf"
+		err = payment.WritePDF(&buf, s.font, receipt)
+	default:
+		return nil, apperror.BadRequestError("format must be either html or pdf", "")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render receipt. error: %w", err)
+	}
+
+	doc.Data = buf.Bytes()
+	return doc, nil
+}
+
+// notify sends the payment to both parties.
+func (s *service) notify(ctx context.Context, p *payment.Payment) {
+	s.events.Publish(ctx, p.PayerID, event.TypePayment, p)
+	s.events.Publish(ctx, p.PayeeID, event.TypePayment, p)
+}
+
+// findBooking returns the booking to its party.
+func (s *service) findBooking(ctx context.Context, id, userID uint) (*booking.Booking, error) {
+	b, err := s.bookings.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find booking. error: %w", err)
+	}
+	if _, ok := b.PartyOf(userID); !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return b, nil
+}
+
+func randomKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	bookingStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/payment"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/payment/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	provider "github.com/levelord1311/backendForSharedProject/lot_service/pkg/payment"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	storage.Repository
+	payments []*payment.Payment
+	refunds  []*payment.Refund
+	events   map[string]bool
+	entries  []*payment.Entry
+}
+
+func (r *repo) Create(_ context.Context, p *payment.Payment) (*payment.Payment, error) {
+	for _, existing := range r.payments {
+		if existing.Kind == p.Kind && existing.Period == p.Period && existing.Status != payment.StatusFailed {
+			return nil, apperror.ConflictError("")
+		}
+	}
+	copied := *p
+	copied.ID = uint(len(r.payments) + 1)
+	copied.Status = payment.StatusPending
+	r.payments = append(r.payments, &copied)
+	return &copied, nil
+}
+
+func (r *repo) SetIntent(_ context.Context, id uint, provider, providerID, checkoutURL string) error {
+	p := r.payments[id-1]
+	p.Provider, p.ProviderID, p.CheckoutURL = provider, providerID, checkoutURL
+	return nil
+}
+
+func (r *repo) FindByID(_ context.Context, id uint) (*payment.Payment, error) {
+	if id == 0 || int(id) > len(r.payments) {
+		return nil, apperror.ErrNotFound
+	}
+	copied := *r.payments[id-1]
+	return &copied, nil
+}
+
+func (r *repo) ApplyEvent(_ context.Context, _, eventID, providerID string, status payment.Status,
+	at time.Time) (*payment.Payment, bool, error) {
+	if r.events[eventID] {
+		return nil, false, nil
+	}
+	r.events[eventID] = true
+	for _, p := range r.payments {
+		if p.ProviderID != providerID {
+			continue
+		}
+		if p.Status != payment.StatusPending {
+			return p, false, nil
+		}
+		p.Status = status
+		if status == payment.StatusSucceeded {
+			p.PaidAt = &at
+			r.entries = append(r.entries,
+				&payment.Entry{UserID: p.PayerID, Amount: -p.Amount},
+				&payment.Entry{UserID: p.PayeeID, Amount: p.Amount})
+		}
+		return p, true, nil
+	}
+	return nil, false, apperror.ErrNotFound
+}
+
+func (r *repo) CreateRefund(_ context.Context, refund *payment.Refund) (*payment.Refund, bool, error) {
+	for _, existing := range r.refunds {
+		if existing.PaymentID == refund.PaymentID && existing.IdempotencyKey == refund.IdempotencyKey {
+			return existing, false, nil
+		}
+	}
+	p := r.payments[refund.PaymentID-1]
+	if p.Status != payment.StatusSucceeded || p.Refunded+refund.Amount > p.Amount {
+		return nil, false, apperror.ConflictError("")
+	}
+	p.Refunded += refund.Amount
+	copied := *refund
+	copied.ID = uint(len(r.refunds) + 1)
+	copied.Status = payment.RefundPending
+	r.refunds = append(r.refunds, &copied)
+	return &copied, true, nil
+}
+
+func (r *repo) CompleteRefund(_ context.Context, id uint, providerID string, _ time.Time) (*payment.Refund, error) {
+	refund := r.refunds[id-1]
+	refund.Status, refund.ProviderID = payment.RefundSucceeded, providerID
+	return refund, nil
+}
+
+type bookings struct {
+	bookingStorage.Repository
+	total int
+}
+
+// FindByID returns booking of 68 nights, 29 of them are in November, 31 in December and 8 in January.
+func (b *bookings) FindByID(_ context.Context, id uint) (*booking.Booking, error) {
+	return &booking.Booking{
+		ID: id, LotID: 2, RenterID: 10, LandlordID: 20, Status: booking.StatusAccepted,
+		CheckIn:  time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+		CheckOut: time.Date(2027, 1, 9, 0, 0, 0, 0, time.UTC),
+		Total:    b.total, Currency: "RUB",
+	}, nil
+}
+
+type lots struct {
+	lotStorage.Repository
+	price  int
+	period string
+}
+
+func (l *lots) FindByLotID(_ context.Context, id uint) (*lot.Lot, error) {
+	return &lot.Lot{ID: id, CreatedByUserID: 20, Price: l.price, Currency: "RUB", PricePeriod: l.period}, nil
+}
+
+type publisher struct {
+	recipients []uint
+}
+
+func (p *publisher) Publish(_ context.Context, userID uint, _ string, _ any) {
+	p.recipients = append(p.recipients, userID)
+}
+
+func newService(t *testing.T) (*service, *repo, *publisher) {
+	mock, err := provider.NewMockProvider("secret", "https://pay.local/checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, p := &repo{events: make(map[string]bool)}, &publisher{}
+	// the stay was quoted at 3000 per night
+	s, err := NewService(r, &bookings{total: 68 * 3000}, &lots{price: 3000, period: lot.PeriodDay}, mock, p,
+		Config{}, logging.GetLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, r, p
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newService(t)
+
+	if _, err := s.Create(ctx, &payment.CreatePaymentDTO{BookingID: 1, UserID: 20, Kind: payment.KindDeposit}); !sameError(
+		err, apperror.ForbiddenError("")) {
+		t.Errorf("expected forbidden error for landlord, got %v", err)
+	}
+	if _, err := s.Create(ctx, &payment.CreatePaymentDTO{BookingID: 1, UserID: 10, Kind: payment.KindRent,
+		Period: "2027-02"}); !sameError(err, apperror.BadRequestError("", "")) {
+		t.Errorf("expected bad request for month after the stay, got %v", err)
+	}
+
+	p, err := s.Create(ctx, &payment.CreatePaymentDTO{BookingID: 1, UserID: 10, Kind: payment.KindRent,
+		Period: "2027-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Amount != 8*3000*100 || p.Currency != "RUB" || p.Status != payment.StatusPending || p.CheckoutURL == "" {
+		t.Errorf("unexpected payment %+v", p)
+	}
+	if _, err = s.Create(ctx, &payment.CreatePaymentDTO{BookingID: 1, UserID: 10, Kind: payment.KindRent,
+		Period: "2027-01"}); !sameError(err, apperror.ConflictError("")) {
+		t.Errorf("expected conflict for the same month, got %v", err)
+	}
+}
+
+func TestCreateAfterPriceChange(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newService(t)
+	s.lots.(*lots).price = 5000
+
+	p, err := s.Create(ctx, &payment.CreatePaymentDTO{BookingID: 1, UserID: 10, Kind: payment.KindRent,
+		Period: "2026-12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Amount != 31*3000*100 {
+		t.Errorf("expected rent of December at price of acceptance, got %d", p.Amount)
+	}
+	deposit, err := s.Create(ctx, &payment.CreatePaymentDTO{BookingID: 1, UserID: 10, Kind: payment.KindDeposit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deposit.Amount != 30*3000*100 {
+		t.Errorf("expected deposit equal to rent of the first month of the stay, got %d", deposit.Amount)
+	}
+
+	s.lots.(*lots).period = lot.PeriodSale
+	if _, err = s.Create(ctx, &payment.CreatePaymentDTO{BookingID: 1, UserID: 10, Kind: payment.KindRent,
+		Period: "2026-11"}); !sameError(err, apperror.BadRequestError("", "")) {
+		t.Errorf("expected bad request for lot for sale, got %v", err)
+	}
+	s.lots.(*lots).period = lot.PeriodMonth
+	s.bookings.(*bookings).total = 0
+	if _, err = s.Create(ctx, &payment.CreatePaymentDTO{BookingID: 1, UserID: 10, Kind: payment.KindRent,
+		Period: "2026-11"}); !sameError(err, apperror.ConflictError("")) {
+		t.Errorf("expected conflict for booking without price, got %v", err)
+	}
+}
+
+func TestHandleWebhook(t *testing.T) {
+	ctx := context.Background()
+	s, r, pub := newService(t)
+	p, err := s.Create(ctx, &payment.CreatePaymentDTO{BookingID: 1, UserID: 10, Kind: payment.KindDeposit})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, body, err := s.provider.(provider.Simulator).Simulate(p.ProviderID, provider.EventSucceeded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.HandleWebhook(ctx, "mock", header, append(body, ' ')); !sameError(err,
+		apperror.UnauthorizedError("")) {
+		t.Errorf("expected unauthorized for forged webhook, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err = s.HandleWebhook(ctx, "mock", header, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, _ := r.FindByID(ctx, p.ID); got.Status != payment.StatusSucceeded {
+		t.Errorf("payment must succeed, got %s", got.Status)
+	}
+	if len(r.entries) != 2 {
+		t.Errorf("repeated webhook must be applied once, got %d ledger entries", len(r.entries))
+	}
+	if len(pub.recipients) != 2 {
+		t.Errorf("both parties must be notified once, got %v", pub.recipients)
+	}
+}
+
+func TestRefund(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newService(t)
+	p, err := s.Create(ctx, &payment.CreatePaymentDTO{BookingID: 1, UserID: 10, Kind: payment.KindDeposit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Refund(ctx, &payment.CreateRefundDTO{PaymentID: p.ID, UserID: 20, Amount: 100}); !sameError(err,
+		apperror.ConflictError("")) {
+		t.Errorf("expected conflict for pending payment, got %v", err)
+	}
+	if _, err = s.Simulate(ctx, &payment.SimulateDTO{ID: p.ID, UserID: 10, Outcome: payment.StatusSucceeded}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = s.Refund(ctx, &payment.CreateRefundDTO{PaymentID: p.ID, UserID: 10, Amount: 100}); !sameError(err,
+		apperror.ForbiddenError("")) {
+		t.Errorf("expected forbidden error for payer, got %v", err)
+	}
+	dto := &payment.CreateRefundDTO{PaymentID: p.ID, UserID: 20, Amount: 1000000, IdempotencyKey: "key"}
+	first, err := s.Refund(ctx, dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Status != payment.RefundSucceeded || first.ProviderID == "" {
+		t.Errorf("unexpected refund %+v", first)
+	}
+	second, err := s.Refund(ctx, dto)
+	if err != nil || second.ID != first.ID {
+		t.Errorf("expected the same refund for the same key, got %+v, %v", second, err)
+	}
+	if _, err = s.Refund(ctx, &payment.CreateRefundDTO{PaymentID: p.ID, UserID: 20, Amount: p.Amount}); !sameError(
+		err, apperror.ConflictError("")) {
+		t.Errorf("expected conflict for refunds over amount, got %v", err)
+	}
+}
+
+// sameError compares app errors by code, since their messages differ.
+func sameError(err, want error) bool {
+	var got, expected *apperror.AppError
+	if !errors.As(err, &got) || !errors.As(want, &expected) {
+		return false
+	}
+	return got.Code == expected.Code
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/payment"
+	"time"
+)
+
+type Repository interface {
+	// Create saves pending payment. Payment of the same kind and period, which is pending or succeeded,
+	// is a conflict.
+	Create(ctx context.Context, p *payment.Payment) (*payment.Payment, error)
+	// SetIntent saves intent of the provider, the payer pays it on the checkout page.
+	SetIntent(ctx context.Context, id uint, provider, providerID, checkoutURL string) error
+	// Fail marks pending payment as failed.
+	Fail(ctx context.Context, id uint) error
+	FindByID(ctx context.Context, id uint) (*payment.Payment, error)
+	FindByBookingID(ctx context.Context, bookingID uint) ([]*payment.Payment, error)
+	// ApplyEvent changes status of pending payment by webhook event of the provider and writes ledger entries,
+	// when the payment succeeds. Every event is applied once, false is returned for events received before
+	// and for events, which don't change the payment.
+	ApplyEvent(ctx context.Context, provider, eventID, providerID string, status payment.Status,
+		at time.Time) (*payment.Payment, bool, error)
+
+	// CreateRefund reserves amount of the refund in the payment. Refund with the same idempotency key
+	// is returned instead of new one with false.
+	CreateRefund(ctx context.Context, r *payment.Refund) (*payment.Refund, bool, error)
+	// CompleteRefund marks pending refund as succeeded and writes ledger entries.
+	CompleteRefund(ctx context.Context, id uint, providerID string, at time.Time) (*payment.Refund, error)
+	// FailRefund marks pending refund as failed and releases its amount.
+	FailRefund(ctx context.Context, id uint) error
+	FindRefunds(ctx context.Context, paymentID uint) ([]*payment.Refund, error)
+
+	// FindEntries returns ledger entries of the user after the given one, the oldest first.
+	FindEntries(ctx context.Context, userID, afterID uint, limit uint64) ([]*payment.Entry, error)
+	// FindName returns name of the user for receipts.
+	FindName(ctx context.Context, userID uint) (string, error)
+}
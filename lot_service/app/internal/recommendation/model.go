@@ -0,0 +1,115 @@
+package recommendation
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"math"
+	"sort"
+	"strings"
+)
+
+// SimilarLot is a lot recommended on page of another lot. Score is from 0 to 1, the most similar lots
+// have the highest score.
+type SimilarLot struct {
+	Lot   *lot.Lot `json:"lot"`
+	Score float64  `json:"score"`
+}
+
+// Ranker orders candidates by similarity to the lot, the most similar first.
+type Ranker interface {
+	Rank(ctx context.Context, l *lot.Lot, candidates []*lot.Lot) ([]*SimilarLot, error)
+}
+
+// Weights are importance of features of lots in the score, zero weight turns the feature off.
+type Weights struct {
+	EstateType float64
+	Rooms      float64
+	Area       float64
+	Price      float64
+	District   float64
+	Distance   float64
+}
+
+// FeatureRanker scores candidates by their features. Every feature is scored from 0 to 1 and the score
+// is weighted average of the features. Distance is scored only, when both lots have coordinates.
+type FeatureRanker struct {
+	Weights Weights
+	// PriceBand is relative difference of prices, at which prices aren't similar at all, e.g. 0.3
+	PriceBand float64
+	// MaxDistanceKm is distance, at which locations aren't similar at all
+	MaxDistanceKm float64
+}
+
+var _ Ranker = &FeatureRanker{}
+
+func (r *FeatureRanker) Rank(_ context.Context, l *lot.Lot, candidates []*lot.Lot) ([]*SimilarLot, error) {
+	ranked := make([]*SimilarLot, 0, len(candidates))
+	for _, c := range candidates {
+		ranked = append(ranked, &SimilarLot{Lot: c, Score: r.Score(l, c)})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked, nil
+}
+
+// Score returns similarity of the candidate to the lot from 0 to 1.
+func (r *FeatureRanker) Score(l, c *lot.Lot) float64 {
+	w := r.Weights
+	var sum, total float64
+	add := func(weight, score float64) {
+		sum += weight * score
+		total += weight
+	}
+
+	add(w.EstateType, equal(l.TypeOfEstate, c.TypeOfEstate))
+	add(w.Rooms, closeness(math.Abs(float64(l.Rooms-c.Rooms)), 3))
+	add(w.Area, closeness(relative(float64(l.Area), float64(c.Area)), 1))
+	add(w.Price, closeness(relative(float64(l.BasePrice()), float64(c.BasePrice())), r.PriceBand))
+	add(w.District, sameDistrict(l, c))
+	if l.Location != nil && c.Location != nil {
+		add(w.Distance, closeness(l.Location.DistanceKm(*c.Location), r.MaxDistanceKm))
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return math.Round(sum/total*1000) / 1000
+}
+
+// sameDistrict compares canonical districts, names are compared only if some lot hasn't been resolved yet.
+func sameDistrict(l, c *lot.Lot) float64 {
+	if l.DistrictID != nil && c.DistrictID != nil {
+		if *l.DistrictID == *c.DistrictID {
+			return 1
+		}
+		return 0
+	}
+	return equal(l.District, c.District)
+}
+
+func equal(a, b string) float64 {
+	if strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b)) {
+		return 1
+	}
+	return 0
+}
+
+// closeness is 1 for zero difference and decreases linearly to 0 at the limit.
+func closeness(diff, limit float64) float64 {
+	if limit <= 0 {
+		if diff == 0 {
+			return 1
+		}
+		return 0
+	}
+	return math.Max(0, 1-diff/limit)
+}
+
+// relative returns difference of the values relative to the first one.
+func relative(v, other float64) float64 {
+	if v == 0 {
+		return math.Abs(other)
+	}
+	return math.Abs(v-other) / v
+}
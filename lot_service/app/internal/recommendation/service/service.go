@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/recommendation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/api/sort"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"math"
+	"net/url"
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	// GetSimilar returns lots similar to the lot, the most similar first. Zero limit means Config.Limit.
+	GetSimilar(ctx context.Context, lotID uint, limit int) ([]*recommendation.SimilarLot, error)
+}
+
+type Config struct {
+	// Candidates limits number of lots ranked in every search of similar lots
+	Candidates int
+	// MinCandidates is number of candidates of the same city, estate type and price band, below which
+	// lots of other price bands and then of other estate types of the city are ranked too
+	MinCandidates int
+	// PriceBand is relative difference of prices of candidates, e.g. 0.3 for prices from 70% to 130%
+	PriceBand float64
+	Limit     int
+	MaxLimit  int
+}
+
+type service struct {
+	lots   lotStorage.Repository
+	ranker recommendation.Ranker
+	cfg    Config
+	logger logging.Logger
+}
+
+func NewService(lots lotStorage.Repository, ranker recommendation.Ranker, cfg Config,
+	logger logging.Logger) (*service, error) {
+	return &service{
+		lots:   lots,
+		ranker: ranker,
+		cfg:    cfg,
+		logger: logger,
+	}, nil
+}
+
+func (s *service) GetSimilar(ctx context.Context, lotID uint, limit int) ([]*recommendation.SimilarLot, error) {
+	if limit == 0 {
+		limit = s.cfg.Limit
+	}
+	if limit < 1 || limit > s.cfg.MaxLimit {
+		return nil, apperror.BadRequestError(fmt.Sprintf("limit must be from 1 to %d", s.cfg.MaxLimit), "")
+	}
+
+	l, err := s.lots.FindByLotID(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot by its id. error: %w", err)
+	}
+
+	candidates, err := s.candidates(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	ranked, err := s.ranker.Rank(ctx, l, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank similar lots. error: %w", err)
+	}
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// candidates returns other lots of the city, the same estate type, period of price and price band first.
+// Search is widened, until there are enough candidates.
+func (s *service) candidates(ctx context.Context, l *lot.Lot) ([]*lot.Lot, error) {
+	// price filters compare prices in lot.DefaultCurrency
+	low := int(math.Floor(float64(l.BasePrice()) * (1 - s.cfg.PriceBand)))
+	high := int(math.Ceil(float64(l.BasePrice()) * (1 + s.cfg.PriceBand)))
+	// canonical city is preferred, addresses of new lots are resolved in background
+	cityFilter, city := "city", l.City
+	if l.CityID != nil {
+		cityFilter, city = "city_id", fmt.Sprint(*l.CityID)
+	}
+	searches := []url.Values{
+		{
+			cityFilter: {city}, "estate_type": {l.TypeOfEstate}, "price_period": {l.PricePeriod},
+			"price": {fmt.Sprintf("%d:%d", low, high)},
+		},
+		{cityFilter: {city}, "estate_type": {l.TypeOfEstate}, "price_period": {l.PricePeriod}},
+		{cityFilter: {city}},
+	}
+
+	candidates := make([]*lot.Lot, 0, s.cfg.Candidates)
+	seen := map[uint]bool{l.ID: true}
+	for i, query := range searches {
+		if i > 0 {
+			s.logger.Debugf("found %d candidates similar to lot %d, widening search..", len(candidates), l.ID)
+		}
+		options, err := lotService.NewQueryOptions(&sort.Options{Field: sort.DefSort, Order: sort.DefOrder}, query)
+		if err != nil {
+			return nil, err
+		}
+		options.WithLimit(s.cfg.Candidates + 1)
+
+		found, err := s.lots.FindWithFilter(ctx, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find candidates for similar lots. error: %w", err)
+		}
+		for _, c := range found {
+			if !seen[c.ID] && len(candidates) < s.cfg.Candidates {
+				seen[c.ID] = true
+				candidates = append(candidates, c)
+			}
+		}
+		if len(candidates) >= s.cfg.MinCandidates {
+			break
+		}
+	}
+	return candidates, nil
+}
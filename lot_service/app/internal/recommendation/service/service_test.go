@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/recommendation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"testing"
+)
+
+// lots filters lots by city and estate type only, price band is ignored.
+type lots struct {
+	lotStorage.Repository
+	lots     []*lot.Lot
+	searches int
+}
+
+func (l *lots) FindByLotID(_ context.Context, id uint) (*lot.Lot, error) {
+	for _, lt := range l.lots {
+		if lt.ID == id {
+			return lt, nil
+		}
+	}
+	return nil, apperror.ErrNotFound
+}
+
+func (l *lots) FindWithFilter(_ context.Context, options lotStorage.QueryOptions) ([]*lot.Lot, error) {
+	l.searches++
+	filters := options.GetFilters()
+	found := make([]*lot.Lot, 0)
+	for _, lt := range l.lots {
+		if f, ok := filters["estate_type"]; ok && f[0].Value[0] != lt.TypeOfEstate {
+			continue
+		}
+		if f, ok := filters["city"]; ok && f[0].Value[0] != lt.City {
+			continue
+		}
+		found = append(found, lt)
+	}
+	return found, nil
+}
+
+func TestGetSimilar(t *testing.T) {
+	repo := &lots{lots: []*lot.Lot{
+		{ID: 1, TypeOfEstate: "квартира", City: "Москва", District: "ЦАО", Rooms: 2, Area: 50, Price: 50000},
+		{ID: 2, TypeOfEstate: "дом", City: "Москва", District: "ЦАО", Rooms: 2, Area: 50, Price: 50000},
+		{ID: 3, TypeOfEstate: "квартира", City: "Москва", District: "ЮАО", Rooms: 3, Area: 70, Price: 70000},
+		{ID: 4, TypeOfEstate: "квартира", City: "Москва", District: "ЦАО", Rooms: 2, Area: 52, Price: 52000},
+		{ID: 5, TypeOfEstate: "квартира", City: "Казань", District: "ЦАО", Rooms: 2, Area: 50, Price: 50000},
+	}}
+	ranker := &recommendation.FeatureRanker{
+		Weights:   recommendation.Weights{EstateType: 3, Rooms: 2, Area: 1, Price: 2, District: 1},
+		PriceBand: 0.3,
+	}
+	cfg := Config{Candidates: 10, MinCandidates: 3, PriceBand: 0.3, Limit: 10, MaxLimit: 20}
+	s, _ := NewService(repo, ranker, cfg, logging.GetLogger())
+
+	similar, err := s.GetSimilar(context.Background(), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// two flats aren't enough, so lots of other estate types of the city are ranked too
+	if repo.searches != 3 {
+		t.Errorf("expected search widened to the city, got %d searches", repo.searches)
+	}
+	want := []uint{4, 2, 3}
+	if len(similar) != len(want) {
+		t.Fatalf("expected %d similar lots, got %d", len(want), len(similar))
+	}
+	for i, id := range want {
+		if similar[i].Lot.ID != id {
+			t.Errorf("expected lot %d at %d, got %d", id, i, similar[i].Lot.ID)
+		}
+	}
+
+	similar, err = s.GetSimilar(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(similar) != 1 || similar[0].Lot.ID != 4 {
+		t.Errorf("expected the most similar lot only, got %d lots", len(similar))
+	}
+
+	if _, err = s.GetSimilar(context.Background(), 1, 21); err == nil {
+		t.Error("expected error for limit above maximum")
+	}
+}
+
+func TestScoreDistrict(t *testing.T) {
+	ranker := &recommendation.FeatureRanker{Weights: recommendation.Weights{District: 1}}
+	central, south := uint(1), uint(2)
+
+	tests := []struct {
+		name   string
+		l, c   *lot.Lot
+		wanted float64
+	}{
+		{
+			name:   "same canonical district with different names",
+			l:      &lot.Lot{DistrictID: &central, District: "ЦАО"},
+			c:      &lot.Lot{DistrictID: &central, District: "Центральный"},
+			wanted: 1,
+		},
+		{
+			name:   "different canonical districts with the same name",
+			l:      &lot.Lot{DistrictID: &central, District: "Ленинский"},
+			c:      &lot.Lot{DistrictID: &south, District: "Ленинский"},
+			wanted: 0,
+		},
+		{
+			name:   "unresolved district",
+			l:      &lot.Lot{DistrictID: &central, District: "ЦАО"},
+			c:      &lot.Lot{District: "цао"},
+			wanted: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if score := ranker.Score(tt.l, tt.c); score != tt.wanted {
+				t.Errorf("expected score %v, got %v", tt.wanted, score)
+			}
+		})
+	}
+}
@@ -0,0 +1,430 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/reference"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/reference/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+)
+
+const errDuplicateEntry = 1062
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+// names are tables of localized names of reference data with their columns of IDs.
+var (
+	estateTypeNames = names{table: "estate_type_names", column: "estate_type_id"}
+	cityNames       = names{table: "city_names", column: "city_id"}
+	districtNames   = names{table: "district_names", column: "district_id"}
+)
+
+type names struct {
+	table  string
+	column string
+}
+
+func (s *db) FindAll(ctx context.Context) (*reference.Reference, error) {
+	r := &reference.Reference{
+		EstateTypes: make([]*reference.EstateType, 0),
+		Cities:      make([]*reference.City, 0),
+		Districts:   make([]*reference.District, 0),
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT estate_type_id, code, position
+	FROM estate_types
+	ORDER BY position, estate_type_id;`)
+	if err != nil {
+		return nil, err
+	}
+	estateTypes := make(map[uint]map[string]string)
+	err = scan(rows, func() error {
+		t := &reference.EstateType{Names: make(map[string]string)}
+		if err := rows.Scan(&t.ID, &t.Code, &t.Position); err != nil {
+			return err
+		}
+		t.Name = t.Code
+		estateTypes[t.ID] = t.Names
+		r.EstateTypes = append(r.EstateTypes, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+	SELECT city_id, name
+	FROM cities
+	ORDER BY name, city_id;`)
+	if err != nil {
+		return nil, err
+	}
+	cities := make(map[uint]map[string]string)
+	err = scan(rows, func() error {
+		c := &reference.City{Names: make(map[string]string)}
+		if err := rows.Scan(&c.ID, &c.Name); err != nil {
+			return err
+		}
+		cities[c.ID] = c.Names
+		r.Cities = append(r.Cities, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+	SELECT d.district_id, d.city_id, d.name
+	FROM districts AS d
+	JOIN cities AS c ON c.city_id=d.city_id
+	ORDER BY c.name, d.city_id, d.name, d.district_id;`)
+	if err != nil {
+		return nil, err
+	}
+	districts := make(map[uint]map[string]string)
+	err = scan(rows, func() error {
+		d := &reference.District{Names: make(map[string]string)}
+		if err := rows.Scan(&d.ID, &d.CityID, &d.Name); err != nil {
+			return err
+		}
+		districts[d.ID] = d.Names
+		r.Districts = append(r.Districts, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for n, byID := range map[names]map[uint]map[string]string{
+		estateTypeNames: estateTypes,
+		cityNames:       cities,
+		districtNames:   districts,
+	} {
+		if err = s.findNames(ctx, n, byID); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// findNames fills names of the reference data by their IDs.
+func (s *db) findNames(ctx context.Context, n names, byID map[uint]map[string]string) error {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT %s, locale, name FROM %s;`, n.column, n.table))
+	if err != nil {
+		return err
+	}
+	return scan(rows, func() error {
+		var id uint
+		var locale, name string
+		if err := rows.Scan(&id, &locale, &name); err != nil {
+			return err
+		}
+		// names of data created after the data were selected are skipped
+		if names, ok := byID[id]; ok {
+			names[locale] = name
+		}
+		return nil
+	})
+}
+
+func (s *db) CreateEstateType(ctx context.Context, t *reference.EstateType) (uint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO estate_types (code, position)
+	VALUES (?, ?);`, t.Code, t.Position)
+	if err != nil {
+		return 0, duplicate(err, "estate type with the code exists already")
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err = setNames(ctx, tx, estateTypeNames, uint(id), t.Names); err != nil {
+		return 0, err
+	}
+	return uint(id), tx.Commit()
+}
+
+func (s *db) UpdateEstateType(ctx context.Context, t *reference.EstateType) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+	SELECT code
+	FROM estate_types
+	WHERE estate_type_id=?
+	FOR UPDATE;`, t.ID).Scan(&t.Code)
+	if err != nil {
+		return notFound(err)
+	}
+	_, err = tx.ExecContext(ctx, `
+	UPDATE estate_types
+	SET position=?
+	WHERE estate_type_id=?;`, t.Position, t.ID)
+	if err != nil {
+		return err
+	}
+	if err = setNames(ctx, tx, estateTypeNames, t.ID, t.Names); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *db) DeleteEstateType(ctx context.Context, id uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var used bool
+	err = tx.QueryRowContext(ctx, `
+	SELECT EXISTS(SELECT 1 FROM lots WHERE type_of_estate=t.code)
+	FROM estate_types AS t
+	WHERE t.estate_type_id=?
+	FOR UPDATE;`, id).Scan(&used)
+	if err != nil {
+		return notFound(err)
+	}
+	if used {
+		return apperror.ConflictError("there are lots of the estate type")
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM estate_types WHERE estate_type_id=?;`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *db) CreateCity(ctx context.Context, c *reference.City, key string) (uint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO cities (name, normalized)
+	VALUES (?, ?);`, c.Name, key)
+	if err != nil {
+		return 0, duplicate(err, "city with the name exists already")
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err = setNames(ctx, tx, cityNames, uint(id), c.Names); err != nil {
+		return 0, err
+	}
+	return uint(id), tx.Commit()
+}
+
+func (s *db) UpdateCity(ctx context.Context, c *reference.City, key string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id uint
+	err = tx.QueryRowContext(ctx, `SELECT city_id FROM cities WHERE city_id=? FOR UPDATE;`, c.ID).Scan(&id)
+	if err != nil {
+		return notFound(err)
+	}
+	_, err = tx.ExecContext(ctx, `
+	UPDATE cities
+	SET name=?, normalized=?
+	WHERE city_id=?;`, c.Name, key, c.ID)
+	if err != nil {
+		return duplicate(err, "city with the name exists already")
+	}
+	_, err = tx.ExecContext(ctx, `
+	UPDATE lots
+	SET city=?, redacted_at=redacted_at
+	WHERE city_id=?;`, c.Name, c.ID)
+	if err != nil {
+		return err
+	}
+	if err = setNames(ctx, tx, cityNames, c.ID, c.Names); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *db) DeleteCity(ctx context.Context, id uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var used bool
+	err = tx.QueryRowContext(ctx, `
+	SELECT EXISTS(SELECT 1 FROM lots WHERE city_id=c.city_id)
+	FROM cities AS c
+	WHERE c.city_id=?
+	FOR UPDATE;`, id).Scan(&used)
+	if err != nil {
+		return notFound(err)
+	}
+	if used {
+		return apperror.ConflictError("there are lots in the city")
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM cities WHERE city_id=?;`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *db) CreateDistrict(ctx context.Context, d *reference.District, key string) (uint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var cityID uint
+	err = tx.QueryRowContext(ctx, `SELECT city_id FROM cities WHERE city_id=? FOR UPDATE;`, d.CityID).Scan(&cityID)
+	if err != nil {
+		return 0, notFound(err)
+	}
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO districts (city_id, name, normalized)
+	VALUES (?, ?, ?);`, d.CityID, d.Name, key)
+	if err != nil {
+		return 0, duplicate(err, "district with the name exists in the city already")
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err = setNames(ctx, tx, districtNames, uint(id), d.Names); err != nil {
+		return 0, err
+	}
+	return uint(id), tx.Commit()
+}
+
+func (s *db) UpdateDistrict(ctx context.Context, d *reference.District, key string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+	SELECT city_id
+	FROM districts
+	WHERE district_id=?
+	FOR UPDATE;`, d.ID).Scan(&d.CityID)
+	if err != nil {
+		return notFound(err)
+	}
+	_, err = tx.ExecContext(ctx, `
+	UPDATE districts
+	SET name=?, normalized=?
+	WHERE district_id=?;`, d.Name, key, d.ID)
+	if err != nil {
+		return duplicate(err, "district with the name exists in the city already")
+	}
+	_, err = tx.ExecContext(ctx, `
+	UPDATE lots
+	SET district=?, redacted_at=redacted_at
+	WHERE district_id=?;`, d.Name, d.ID)
+	if err != nil {
+		return err
+	}
+	if err = setNames(ctx, tx, districtNames, d.ID, d.Names); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *db) DeleteDistrict(ctx context.Context, id uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var used bool
+	err = tx.QueryRowContext(ctx, `
+	SELECT EXISTS(SELECT 1 FROM lots WHERE district_id=d.district_id)
+	FROM districts AS d
+	WHERE d.district_id=?
+	FOR UPDATE;`, id).Scan(&used)
+	if err != nil {
+		return notFound(err)
+	}
+	if used {
+		return apperror.ConflictError("there are lots in the district")
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM districts WHERE district_id=?;`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// setNames replaces names of the reference data.
+func setNames(ctx context.Context, tx *sql.Tx, n names, id uint, localized map[string]string) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s=?;`, n.table, n.column), id)
+	if err != nil {
+		return err
+	}
+	for locale, name := range localized {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (%s, locale, name) VALUES (?, ?, ?);`,
+			n.table, n.column), id, locale, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scan(rows *sql.Rows, scanRow func() error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := scanRow(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.ErrNotFound
+	}
+	return err
+}
+
+func duplicate(err error, message string) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+		return apperror.ConflictError(message)
+	}
+	return err
+}
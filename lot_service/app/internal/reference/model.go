@@ -0,0 +1,206 @@
+package reference
+
+import (
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/address"
+	"regexp"
+)
+
+// DefLocale is locale of names of reference data, which aren't translated.
+const DefLocale = "ru"
+
+// localeFormat is language code with optional region, e.g. en or en-US.
+var localeFormat = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+// EstateType is type of estate of lots. Lots store its code, e.g. квартира, and the code is shown,
+// if the type has no name in the locale.
+type EstateType struct {
+	ID       uint              `json:"id"`
+	Code     string            `json:"code"`
+	Name     string            `json:"name"`
+	Position int               `json:"position"`        // order in lists
+	Names    map[string]string `json:"names,omitempty"` // by locale, for admins only
+}
+
+// City is canonical city of address.City with localized names.
+type City struct {
+	ID    uint              `json:"id"`
+	Name  string            `json:"name"`
+	Names map[string]string `json:"names,omitempty"`
+}
+
+type District struct {
+	ID     uint              `json:"id"`
+	CityID uint              `json:"city_id"`
+	Name   string            `json:"name"`
+	Names  map[string]string `json:"names,omitempty"`
+}
+
+// Reference is reference data for lists of forms and filters. Names are in the locale of the reference,
+// reference for admins has names in all locales.
+type Reference struct {
+	Locale      string        `json:"locale"`
+	EstateTypes []*EstateType `json:"estate_types"` // ordered by position
+	Cities      []*City       `json:"cities"`       // ordered by name
+	Districts   []*District   `json:"districts"`    // ordered by city and name
+	ETag        string        `json:"-"`
+}
+
+type CreateEstateTypeDTO struct {
+	Code     string            `json:"code"`
+	Position int               `json:"position"`
+	Names    map[string]string `json:"names"`
+}
+
+// UpdateEstateTypeDTO changes position and names of the type, code is kept, since lots refer to it.
+type UpdateEstateTypeDTO struct {
+	ID       uint              `json:"id"`
+	Position int               `json:"position"`
+	Names    map[string]string `json:"names"`
+}
+
+type CreateCityDTO struct {
+	Name  string            `json:"name"`
+	Names map[string]string `json:"names"`
+}
+
+type UpdateCityDTO struct {
+	ID    uint              `json:"id"`
+	Name  string            `json:"name"`
+	Names map[string]string `json:"names"`
+}
+
+type CreateDistrictDTO struct {
+	CityID uint              `json:"city_id"`
+	Name   string            `json:"name"`
+	Names  map[string]string `json:"names"`
+}
+
+type UpdateDistrictDTO struct {
+	ID    uint              `json:"id"`
+	Name  string            `json:"name"`
+	Names map[string]string `json:"names"`
+}
+
+func (dto *CreateEstateTypeDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.Code, validation.Required, validation.Length(1, 50)),
+		validation.Field(&dto.Names, validation.By(validateNames)))
+}
+
+func (dto *UpdateEstateTypeDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.Names, validation.By(validateNames)))
+}
+
+func (dto *CreateCityDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&dto.Names, validation.By(validateNames)))
+}
+
+func (dto *UpdateCityDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&dto.Names, validation.By(validateNames)))
+}
+
+func (dto *CreateDistrictDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.CityID, validation.Required),
+		validation.Field(&dto.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&dto.Names, validation.By(validateNames)))
+}
+
+func (dto *UpdateDistrictDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&dto.Names, validation.By(validateNames)))
+}
+
+// ValidLocale tells whether the locale is language code with optional region.
+func ValidLocale(locale string) bool {
+	return localeFormat.MatchString(locale)
+}
+
+func validateNames(value any) error {
+	for locale, name := range value.(map[string]string) {
+		if !ValidLocale(locale) {
+			return fmt.Errorf("unknown locale %q", locale)
+		}
+		if name == "" || len([]rune(name)) > 255 {
+			return fmt.Errorf("name in locale %q must be from 1 to 255 characters", locale)
+		}
+	}
+	return nil
+}
+
+func NewEstateType(dto *CreateEstateTypeDTO) *EstateType {
+	return &EstateType{
+		Code:     dto.Code,
+		Position: dto.Position,
+		Names:    dto.Names,
+	}
+}
+
+// NewCity cleans the name like canonical cities of addresses, so lots with the name are resolved to the city.
+func NewCity(name string, names map[string]string) (*City, string) {
+	c := address.NewCity(name)
+	return &City{Name: c.Name, Names: names}, c.Key
+}
+
+func NewDistrict(cityID uint, name string, names map[string]string) (*District, string) {
+	d := address.NewDistrict(cityID, name)
+	return &District{CityID: cityID, Name: d.Name, Names: names}, d.Key
+}
+
+// Localized returns copy of the reference with names in the locale.
+func (r *Reference) Localized(locale string) *Reference {
+	l := &Reference{
+		Locale:      locale,
+		EstateTypes: make([]*EstateType, 0, len(r.EstateTypes)),
+		Cities:      make([]*City, 0, len(r.Cities)),
+		Districts:   make([]*District, 0, len(r.Districts)),
+	}
+	for _, t := range r.EstateTypes {
+		l.EstateTypes = append(l.EstateTypes, &EstateType{
+			ID:       t.ID,
+			Code:     t.Code,
+			Name:     name(t.Names, locale, t.Code),
+			Position: t.Position,
+		})
+	}
+	for _, c := range r.Cities {
+		l.Cities = append(l.Cities, &City{ID: c.ID, Name: name(c.Names, locale, c.Name)})
+	}
+	for _, d := range r.Districts {
+		l.Districts = append(l.Districts, &District{ID: d.ID, CityID: d.CityID, Name: name(d.Names, locale, d.Name)})
+	}
+	return l
+}
+
+// EstateTypeCodes returns codes of estate types in the order of positions.
+func (r *Reference) EstateTypeCodes() []string {
+	codes := make([]string, 0, len(r.EstateTypes))
+	for _, t := range r.EstateTypes {
+		codes = append(codes, t.Code)
+	}
+	return codes
+}
+
+// name returns name in the locale, then in its language, then the default one.
+func name(names map[string]string, locale, def string) string {
+	if n, ok := names[locale]; ok {
+		return n
+	}
+	if len(locale) > 2 {
+		if n, ok := names[locale[:2]]; ok {
+			return n
+		}
+	}
+	return def
+}
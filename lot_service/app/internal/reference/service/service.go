@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/reference"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/reference/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"sync"
+	"time"
+)
+
+// maxCachedLocales limits number of localized references kept in memory, since any locale can be requested.
+const maxCachedLocales = 100
+
+var _ Service = &service{}
+
+type Service interface {
+	// Get returns reference data with names in the locale, names in the default locale are used for untranslated
+	// data. Reference data are cached and loaded again, when they are changed by admins or the cache expires.
+	Get(ctx context.Context, locale string) (*reference.Reference, error)
+	// GetAll returns reference data with names in all locales.
+	GetAll(ctx context.Context) (*reference.Reference, error)
+	// EstateTypes returns codes of known estate types, lots are validated with them.
+	EstateTypes(ctx context.Context) ([]string, error)
+
+	CreateEstateType(ctx context.Context, dto *reference.CreateEstateTypeDTO) (*reference.EstateType, error)
+	UpdateEstateType(ctx context.Context, dto *reference.UpdateEstateTypeDTO) (*reference.EstateType, error)
+	DeleteEstateType(ctx context.Context, id uint) error
+
+	CreateCity(ctx context.Context, dto *reference.CreateCityDTO) (*reference.City, error)
+	UpdateCity(ctx context.Context, dto *reference.UpdateCityDTO) (*reference.City, error)
+	DeleteCity(ctx context.Context, id uint) error
+
+	CreateDistrict(ctx context.Context, dto *reference.CreateDistrictDTO) (*reference.District, error)
+	UpdateDistrict(ctx context.Context, dto *reference.UpdateDistrictDTO) (*reference.District, error)
+	DeleteDistrict(ctx context.Context, id uint) error
+}
+
+type Config struct {
+	// CacheTTL is how long reference data are cached. Cities and districts may also be added by resolving
+	// addresses of lots, so cached data may miss them until the cache expires.
+	CacheTTL time.Duration
+}
+
+type service struct {
+	repository storage.Repository
+	cfg        Config
+	logger     logging.Logger
+
+	mu        sync.Mutex
+	data      *reference.Reference
+	loadedAt  time.Time
+	localized map[string]*reference.Reference
+}
+
+func NewService(referenceStorage storage.Repository, cfg Config, logger logging.Logger) (*service, error) {
+	return &service{
+		repository: referenceStorage,
+		cfg:        cfg,
+		logger:     logger,
+		localized:  make(map[string]*reference.Reference),
+	}, nil
+}
+
+func (s *service) Get(ctx context.Context, locale string) (*reference.Reference, error) {
+	if locale == "" {
+		locale = reference.DefLocale
+	}
+	if !reference.ValidLocale(locale) {
+		return nil, apperror.BadRequestError("locale must be a language code like en or en-US", "")
+	}
+
+	data, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.localized[locale]; ok && s.data == data {
+		return r, nil
+	}
+
+	r := data.Localized(locale)
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal reference. error: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	r.ETag = fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16]))
+
+	if s.data == data {
+		if _, ok := s.localized[locale]; !ok && len(s.localized) >= maxCachedLocales {
+			for k := range s.localized {
+				delete(s.localized, k)
+				break
+			}
+		}
+		s.localized[locale] = r
+	}
+	return r, nil
+}
+
+func (s *service) GetAll(ctx context.Context) (*reference.Reference, error) {
+	r, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reference data. error: %w", err)
+	}
+	return r, nil
+}
+
+func (s *service) EstateTypes(ctx context.Context) ([]string, error) {
+	data, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return data.EstateTypeCodes(), nil
+}
+
+func (s *service) CreateEstateType(ctx context.Context,
+	dto *reference.CreateEstateTypeDTO) (*reference.EstateType, error) {
+	s.logger.Debug("validating estate type fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	t := reference.NewEstateType(dto)
+	s.logger.Debug("creating new estate type..")
+	var err error
+	if t.ID, err = s.repository.CreateEstateType(ctx, t); err != nil {
+		return nil, failed(err, "create estate type")
+	}
+	t.Name = t.Code
+	s.invalidate()
+	return t, nil
+}
+
+func (s *service) UpdateEstateType(ctx context.Context,
+	dto *reference.UpdateEstateTypeDTO) (*reference.EstateType, error) {
+	s.logger.Debug("validating estate type fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	t := &reference.EstateType{ID: dto.ID, Position: dto.Position, Names: dto.Names}
+	if err := s.repository.UpdateEstateType(ctx, t); err != nil {
+		return nil, failed(err, "update estate type")
+	}
+	t.Name = t.Code
+	s.invalidate()
+	return t, nil
+}
+
+func (s *service) DeleteEstateType(ctx context.Context, id uint) error {
+	if err := s.repository.DeleteEstateType(ctx, id); err != nil {
+		return failed(err, "delete estate type")
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *service) CreateCity(ctx context.Context, dto *reference.CreateCityDTO) (*reference.City, error) {
+	s.logger.Debug("validating city fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	c, key := reference.NewCity(dto.Name, dto.Names)
+	if key == "" {
+		return nil, apperror.BadRequestError("name: must be a name of city", "")
+	}
+	s.logger.Debug("creating new city..")
+	var err error
+	if c.ID, err = s.repository.CreateCity(ctx, c, key); err != nil {
+		return nil, failed(err, "create city")
+	}
+	s.invalidate()
+	return c, nil
+}
+
+func (s *service) UpdateCity(ctx context.Context, dto *reference.UpdateCityDTO) (*reference.City, error) {
+	s.logger.Debug("validating city fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	c, key := reference.NewCity(dto.Name, dto.Names)
+	if key == "" {
+		return nil, apperror.BadRequestError("name: must be a name of city", "")
+	}
+	c.ID = dto.ID
+	if err := s.repository.UpdateCity(ctx, c, key); err != nil {
+		return nil, failed(err, "update city")
+	}
+	s.invalidate()
+	return c, nil
+}
+
+func (s *service) DeleteCity(ctx context.Context, id uint) error {
+	if err := s.repository.DeleteCity(ctx, id); err != nil {
+		return failed(err, "delete city")
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *service) CreateDistrict(ctx context.Context, dto *reference.CreateDistrictDTO) (*reference.District, error) {
+	s.logger.Debug("validating district fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	d, key := reference.NewDistrict(dto.CityID, dto.Name, dto.Names)
+	if key == "" {
+		return nil, apperror.BadRequestError("name: must be a name of district", "")
+	}
+	s.logger.Debug("creating new district..")
+	var err error
+	if d.ID, err = s.repository.CreateDistrict(ctx, d, key); err != nil {
+		return nil, failed(err, "create district")
+	}
+	s.invalidate()
+	return d, nil
+}
+
+func (s *service) UpdateDistrict(ctx context.Context, dto *reference.UpdateDistrictDTO) (*reference.District, error) {
+	s.logger.Debug("validating district fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	// city of the district is kept, the storage sets it
+	d, key := reference.NewDistrict(0, dto.Name, dto.Names)
+	if key == "" {
+		return nil, apperror.BadRequestError("name: must be a name of district", "")
+	}
+	d.ID = dto.ID
+	if err := s.repository.UpdateDistrict(ctx, d, key); err != nil {
+		return nil, failed(err, "update district")
+	}
+	s.invalidate()
+	return d, nil
+}
+
+func (s *service) DeleteDistrict(ctx context.Context, id uint) error {
+	if err := s.repository.DeleteDistrict(ctx, id); err != nil {
+		return failed(err, "delete district")
+	}
+	s.invalidate()
+	return nil
+}
+
+// load returns cached reference data, data are loaded again, if the cache is empty or expired.
+func (s *service) load(ctx context.Context) (*reference.Reference, error) {
+	s.mu.Lock()
+	data, loadedAt := s.data, s.loadedAt
+	s.mu.Unlock()
+	if data != nil && time.Since(loadedAt) < s.cfg.CacheTTL {
+		return data, nil
+	}
+
+	s.logger.Debug("loading reference data..")
+	data, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reference data. error: %w", err)
+	}
+
+	s.mu.Lock()
+	s.data = data
+	s.loadedAt = time.Now()
+	s.localized = make(map[string]*reference.Reference)
+	s.mu.Unlock()
+	return data, nil
+}
+
+func (s *service) invalidate() {
+	s.mu.Lock()
+	s.data = nil
+	s.localized = make(map[string]*reference.Reference)
+	s.mu.Unlock()
+}
+
+// failed passes errors of requests through and wraps the others.
+func failed(err error, action string) error {
+	var appErr *apperror.AppError
+	if errors.Is(err, apperror.ErrNotFound) || errors.As(err, &appErr) {
+		return err
+	}
+	return fmt.Errorf("failed to %s. error: %w", action, err)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/reference"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/reference/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	storage.Repository
+	estateTypes []*reference.EstateType
+	loads       int
+}
+
+func (r *repo) FindAll(_ context.Context) (*reference.Reference, error) {
+	r.loads++
+	found := &reference.Reference{
+		EstateTypes: make([]*reference.EstateType, 0, len(r.estateTypes)),
+		Cities: []*reference.City{
+			{ID: 1, Name: "Москва", Names: map[string]string{"en": "Moscow"}},
+		},
+		Districts: []*reference.District{
+			{ID: 1, CityID: 1, Name: "Арбат", Names: map[string]string{}},
+		},
+	}
+	for _, t := range r.estateTypes {
+		copied := *t
+		copied.Name = t.Code
+		found.EstateTypes = append(found.EstateTypes, &copied)
+	}
+	return found, nil
+}
+
+func (r *repo) CreateEstateType(_ context.Context, t *reference.EstateType) (uint, error) {
+	copied := *t
+	copied.ID = uint(len(r.estateTypes) + 1)
+	r.estateTypes = append(r.estateTypes, &copied)
+	return copied.ID, nil
+}
+
+func TestGet(t *testing.T) {
+	r := &repo{estateTypes: []*reference.EstateType{
+		{ID: 1, Code: "квартира", Names: map[string]string{"ru": "Квартира", "en": "Flat"}},
+		{ID: 2, Code: "дом", Names: map[string]string{"ru": "Дом"}},
+	}}
+	s, _ := NewService(r, Config{CacheTTL: time.Hour}, logging.GetLogger())
+	ctx := context.Background()
+
+	en, err := s.Get(ctx, "en-GB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if en.EstateTypes[0].Name != "Flat" || en.EstateTypes[1].Name != "дом" {
+		t.Errorf("estate types in en-GB are %q and %q, want Flat and дом",
+			en.EstateTypes[0].Name, en.EstateTypes[1].Name)
+	}
+	if en.Cities[0].Name != "Moscow" || en.Districts[0].Name != "Арбат" {
+		t.Errorf("city and district in en-GB are %q and %q, want Moscow and Арбат",
+			en.Cities[0].Name, en.Districts[0].Name)
+	}
+	if en.EstateTypes[0].Names != nil {
+		t.Errorf("localized reference has names in all locales")
+	}
+
+	ru, err := s.Get(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ru.Locale != reference.DefLocale || ru.EstateTypes[0].Name != "Квартира" {
+		t.Errorf("default reference is in %q with %q, want ru with Квартира", ru.Locale, ru.EstateTypes[0].Name)
+	}
+	if ru.ETag == "" || ru.ETag == en.ETag {
+		t.Errorf("ETags of locales are %q and %q, want different ones", ru.ETag, en.ETag)
+	}
+	if again, _ := s.Get(ctx, "ru"); again != ru {
+		t.Errorf("reference isn't cached")
+	}
+	if r.loads != 1 {
+		t.Errorf("reference data are loaded %d times, want once", r.loads)
+	}
+
+	if _, err = s.Get(ctx, "english"); err == nil {
+		t.Errorf("unknown locale is accepted")
+	}
+}
+
+func TestEstateTypes(t *testing.T) {
+	r := &repo{estateTypes: []*reference.EstateType{{ID: 1, Code: "квартира"}}}
+	s, _ := NewService(r, Config{CacheTTL: time.Hour}, logging.GetLogger())
+	ctx := context.Background()
+
+	before, err := s.Get(ctx, "ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.CreateEstateType(ctx, &reference.CreateEstateTypeDTO{
+		Code:  "комната",
+		Names: map[string]string{"en": "Room"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes, err := s.EstateTypes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"квартира", "комната"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("estate types are %v, want %v", codes, want)
+	}
+	after, _ := s.Get(ctx, "ru")
+	if after.ETag == before.ETag {
+		t.Errorf("ETag isn't changed by new estate type")
+	}
+
+	_, err = s.CreateEstateType(ctx, &reference.CreateEstateTypeDTO{
+		Code:  "студия",
+		Names: map[string]string{"EN": "Studio"},
+	})
+	if err == nil {
+		t.Errorf("names in unknown locale are accepted")
+	}
+}
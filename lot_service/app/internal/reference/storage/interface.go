@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/reference"
+)
+
+// Repository keeps names of reference data in all locales. Names of reference data are replaced as a whole
+// on updates.
+type Repository interface {
+	// FindAll returns all reference data with names in all locales.
+	FindAll(ctx context.Context) (*reference.Reference, error)
+
+	CreateEstateType(ctx context.Context, t *reference.EstateType) (uint, error)
+	UpdateEstateType(ctx context.Context, t *reference.EstateType) error
+	// DeleteEstateType fails with conflict, if lots of the type exist.
+	DeleteEstateType(ctx context.Context, id uint) error
+
+	// CreateCity creates the city with normalized name key, cities with the same key conflict.
+	CreateCity(ctx context.Context, c *reference.City, key string) (uint, error)
+	// UpdateCity renames the city and its lots.
+	UpdateCity(ctx context.Context, c *reference.City, key string) error
+	// DeleteCity deletes the city with its districts, it fails with conflict, if lots of the city exist.
+	DeleteCity(ctx context.Context, id uint) error
+
+	CreateDistrict(ctx context.Context, d *reference.District, key string) (uint, error)
+	// UpdateDistrict renames the district and its lots.
+	UpdateDistrict(ctx context.Context, d *reference.District, key string) error
+	// DeleteDistrict fails with conflict, if lots of the district exist.
+	DeleteDistrict(ctx context.Context, id uint) error
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
+	historyDB "github.com/levelord1311/backendForSharedProject/lot_service/internal/history/db"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/rental"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/rental/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+func (s *db) FindByLotIDs(ctx context.Context, lotIDs []uint) (map[uint]*rental.Rental, error) {
+	rentals := make(map[uint]*rental.Rental, len(lotIDs))
+	if len(lotIDs) == 0 {
+		return rentals, nil
+	}
+
+	sqlQ, args, err := sq.Select("lot_id", "mode", "price", "min_stay", "cleaning_fee").
+		From("lot_rental_modes").
+		Where(sq.Eq{"lot_id": lotIDs}).
+		OrderBy("lot_id", "mode").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, sqlQ, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var lotID uint
+		m := &rental.Mode{}
+		if err = rows.Scan(&lotID, &m.Mode, &m.Price, &m.MinStay, &m.CleaningFee); err != nil {
+			return nil, err
+		}
+		r, ok := rentals[lotID]
+		if !ok {
+			r = &rental.Rental{LotID: lotID, Modes: make([]*rental.Mode, 0, 2), Seasons: make([]*rental.Season, 0)}
+			rentals[lotID] = r
+		}
+		r.Modes = append(r.Modes, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sqlQ, args, err = sq.Select("season_id", "lot_id", "mode", "start_date", "end_date", "price").
+		From("lot_season_prices").
+		Where(sq.Eq{"lot_id": lotIDs}).
+		OrderBy("lot_id", "mode", "start_date").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	seasonRows, err := s.db.QueryContext(ctx, sqlQ, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer seasonRows.Close()
+	for seasonRows.Next() {
+		var lotID uint
+		var start, end mysql.RawTime
+		season := &rental.Season{}
+		if err = seasonRows.Scan(&season.ID, &lotID, &season.Mode, &start, &end, &season.Price); err != nil {
+			return nil, err
+		}
+		if season.Start, err = start.Date(); err != nil {
+			return nil, err
+		}
+		if season.End, err = end.Date(); err != nil {
+			return nil, err
+		}
+		if r, ok := rentals[lotID]; ok {
+			r.Seasons = append(r.Seasons, season)
+		}
+	}
+	return rentals, seasonRows.Err()
+}
+
+func (s *db) Save(ctx context.Context, r *rental.Rental, record *history.Record) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// seasons are deleted with their modes
+	if _, err = tx.ExecContext(ctx, `DELETE FROM lot_rental_modes WHERE lot_id=?;`, r.LotID); err != nil {
+		return err
+	}
+	for _, m := range r.Modes {
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO lot_rental_modes (lot_id, mode, price, min_stay, cleaning_fee)
+		VALUES (?, ?, ?, ?, ?);`, r.LotID, m.Mode, m.Price, m.MinStay, m.CleaningFee)
+		if err != nil {
+			return err
+		}
+	}
+	for _, season := range r.Seasons {
+		res, err := tx.ExecContext(ctx, `
+		INSERT INTO lot_season_prices (lot_id, mode, start_date, end_date, price)
+		VALUES (?, ?, ?, ?, ?);`, r.LotID, season.Mode, season.Start.Format(calendar.DateLayout),
+			season.End.Format(calendar.DateLayout), season.Price)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		season.ID = uint(id)
+	}
+	if record != nil {
+		if err = historyDB.Save(ctx, tx, record); err != nil {
+			return fmt.Errorf("failed to save version of lot. error: %w", err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,308 @@
+package rental
+
+import (
+	"errors"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	ModeLongTerm  = "long_term"  // priced by months
+	ModeShortTerm = "short_term" // priced by nights
+)
+
+const (
+	// daysInMonth prices days of long-term stay, which don't make a full month
+	daysInMonth = 30
+	// MaxStayNights limits stays, which are priced
+	MaxStayNights = 3 * 366
+	maxSeasons    = 100
+)
+
+// Mode is rental mode of the lot. Price is in currency of the lot, per night for short-term rent and per month
+// for long-term rent. Minimal stay is in the same units.
+type Mode struct {
+	Mode        string `json:"mode"`
+	Price       int    `json:"price"`
+	MinStay     int    `json:"min_stay"`
+	CleaningFee int    `json:"cleaning_fee"` // for the whole stay
+}
+
+// Season replaces price of the mode for nights or months starting in the season. End day is not included.
+type Season struct {
+	ID    uint      `json:"id"`
+	Mode  string    `json:"mode"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Price int       `json:"price"`
+}
+
+// Rental is rental modes of the lot. Lots without modes are rented in the mode of their price period
+// at their price, see Default.
+type Rental struct {
+	LotID    uint      `json:"lot_id"`
+	Currency string    `json:"currency"`
+	Modes    []*Mode   `json:"modes"`
+	Seasons  []*Season `json:"seasons"` // ordered by mode and start
+}
+
+type ModeDTO struct {
+	Mode        string `json:"mode"`
+	Price       int    `json:"price"`
+	MinStay     int    `json:"min_stay"` // optional, 1 by default
+	CleaningFee int    `json:"cleaning_fee"`
+}
+
+type SeasonDTO struct {
+	Mode  string `json:"mode"`
+	Start string `json:"start"` // YYYY-MM-DD
+	End   string `json:"end"`   // YYYY-MM-DD, the day is not included
+	Price int    `json:"price"`
+}
+
+// SetRentalDTO replaces rental modes and seasons of the lot, empty modes restore the default one.
+type SetRentalDTO struct {
+	LotID   uint        `json:"lot_id"`
+	UserID  uint        `json:"user_id"`
+	Modes   []ModeDTO   `json:"modes"`
+	Seasons []SeasonDTO `json:"seasons"`
+}
+
+type QuoteDTO struct {
+	LotID    uint   `json:"lot_id"`
+	Mode     string `json:"mode"`
+	CheckIn  string `json:"check_in"`  // YYYY-MM-DD
+	CheckOut string `json:"check_out"` // YYYY-MM-DD
+}
+
+// Validate is called for each mode of SetRentalDTO, so it has value receiver.
+func (dto ModeDTO) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Mode, validation.Required, validation.In(ModeLongTerm, ModeShortTerm)),
+		validation.Field(&dto.Price, validation.Required, validation.Min(1)),
+		validation.Field(&dto.MinStay, validation.Min(0), validation.Max(MaxStayNights)),
+		validation.Field(&dto.CleaningFee, validation.Min(0)),
+	)
+}
+
+func (dto SeasonDTO) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Mode, validation.Required, validation.In(ModeLongTerm, ModeShortTerm)),
+		validation.Field(&dto.Start, validation.Required, validation.Date(calendar.DateLayout)),
+		validation.Field(&dto.End, validation.Required, validation.Date(calendar.DateLayout)),
+		validation.Field(&dto.Price, validation.Required, validation.Min(1)),
+	)
+}
+
+func (dto *SetRentalDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.LotID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Modes, validation.Length(0, 2)),
+		validation.Field(&dto.Seasons, validation.Length(0, maxSeasons)),
+	)
+}
+
+func (dto *QuoteDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.LotID, validation.Required),
+		validation.Field(&dto.Mode, validation.Required, validation.In(ModeLongTerm, ModeShortTerm)),
+		validation.Field(&dto.CheckIn, validation.Required, validation.Date(calendar.DateLayout)),
+		validation.Field(&dto.CheckOut, validation.Required, validation.Date(calendar.DateLayout)),
+	)
+}
+
+// NewRental builds rental of the lot from validated DTO. Modes must be distinct and seasons of the same mode
+// must not overlap.
+func NewRental(l *lot.Lot, dto *SetRentalDTO) (*Rental, error) {
+	r := &Rental{
+		LotID:    l.ID,
+		Currency: l.Currency,
+		Modes:    make([]*Mode, 0, len(dto.Modes)),
+		Seasons:  make([]*Season, 0, len(dto.Seasons)),
+	}
+	for _, m := range dto.Modes {
+		if r.Mode(m.Mode) != nil {
+			return nil, fmt.Errorf("mode %s is repeated", m.Mode)
+		}
+		minStay := m.MinStay
+		if minStay == 0 {
+			minStay = 1
+		}
+		r.Modes = append(r.Modes, &Mode{Mode: m.Mode, Price: m.Price, MinStay: minStay, CleaningFee: m.CleaningFee})
+	}
+	for _, s := range dto.Seasons {
+		if r.Mode(s.Mode) == nil {
+			return nil, fmt.Errorf("season of mode %s, which the lot has no", s.Mode)
+		}
+		start, end, err := calendar.ParsePeriod(s.Start, s.End)
+		if err != nil {
+			return nil, fmt.Errorf("wrong season %s:%s: %w", s.Start, s.End, err)
+		}
+		r.Seasons = append(r.Seasons, &Season{Mode: s.Mode, Start: start, End: end, Price: s.Price})
+	}
+
+	sort.Slice(r.Seasons, func(i, j int) bool {
+		a, b := r.Seasons[i], r.Seasons[j]
+		if a.Mode != b.Mode {
+			return a.Mode < b.Mode
+		}
+		return a.Start.Before(b.Start)
+	})
+	for i := 1; i < len(r.Seasons); i++ {
+		prev, s := r.Seasons[i-1], r.Seasons[i]
+		if prev.Mode == s.Mode && s.Start.Before(prev.End) {
+			return nil, fmt.Errorf("seasons of mode %s overlap on %s", s.Mode, s.Start.Format(calendar.DateLayout))
+		}
+	}
+	return r, nil
+}
+
+// ModeOfPeriod returns rental mode of lots without modes with the price period, lots for sale aren't rented.
+func ModeOfPeriod(period string) (string, bool) {
+	switch period {
+	case lot.PeriodMonth:
+		return ModeLongTerm, true
+	case lot.PeriodDay:
+		return ModeShortTerm, true
+	}
+	return "", false
+}
+
+// PeriodOfMode is the inverse of ModeOfPeriod.
+func PeriodOfMode(mode string) string {
+	if mode == ModeShortTerm {
+		return lot.PeriodDay
+	}
+	return lot.PeriodMonth
+}
+
+// Default returns rental of the lot without modes, it has no modes, if the lot is for sale.
+func Default(l *lot.Lot) *Rental {
+	r := &Rental{LotID: l.ID, Currency: l.Currency, Modes: make([]*Mode, 0, 1), Seasons: make([]*Season, 0)}
+	if mode, ok := ModeOfPeriod(l.PricePeriod); ok {
+		r.Modes = append(r.Modes, &Mode{Mode: mode, Price: l.Price, MinStay: 1})
+	}
+	return r
+}
+
+func (r *Rental) Mode(mode string) *Mode {
+	for _, m := range r.Modes {
+		if m.Mode == mode {
+			return m
+		}
+	}
+	return nil
+}
+
+// StayLength returns length of stay in units of minimal stay of the mode: nights or full months.
+func StayLength(mode string, checkIn, checkOut time.Time) int {
+	if mode == ModeLongTerm {
+		months, _ := months(checkIn, checkOut)
+		return months
+	}
+	return nights(checkIn, checkOut)
+}
+
+// Quote returns price of the stay in the mode. Nights of short-term stay and months of long-term stay
+// are priced by seasons they start in.
+func (r *Rental) Quote(mode string, checkIn, checkOut time.Time) (*lot.Stay, error) {
+	m := r.Mode(mode)
+	if m == nil {
+		return nil, fmt.Errorf("the lot isn't rented in mode %s", mode)
+	}
+	n := nights(checkIn, checkOut)
+	if n <= 0 {
+		return nil, errors.New("check out must be after check in")
+	}
+	if n > MaxStayNights {
+		return nil, fmt.Errorf("stay must not be longer than %d nights", MaxStayNights)
+	}
+
+	stay := &lot.Stay{
+		Mode:        mode,
+		CheckIn:     checkIn.Format(calendar.DateLayout),
+		CheckOut:    checkOut.Format(calendar.DateLayout),
+		Nights:      n,
+		CleaningFee: m.CleaningFee,
+		Currency:    r.Currency,
+	}
+	if mode == ModeShortTerm {
+		if n < m.MinStay {
+			return nil, fmt.Errorf("minimal stay is %d nights", m.MinStay)
+		}
+		for d := checkIn; d.Before(checkOut); d = d.AddDate(0, 0, 1) {
+			stay.Price += r.price(m, d)
+		}
+	} else {
+		months, rest := months(checkIn, checkOut)
+		if months < m.MinStay {
+			return nil, fmt.Errorf("minimal stay is %d months", m.MinStay)
+		}
+		stay.Months = months
+		for i := 0; i < months; i++ {
+			stay.Price += r.price(m, checkIn.AddDate(0, i, 0))
+		}
+		if rest > 0 {
+			perDay := float64(r.price(m, checkIn.AddDate(0, months, 0))) / daysInMonth
+			stay.Price += int(math.Round(perDay * float64(rest)))
+		}
+	}
+	stay.Total = stay.Price + stay.CleaningFee
+	return stay, nil
+}
+
+// QuoteStay returns price of the stay in the mode, which suits it: long-term, if the stay lasts a full month,
+// and short-term otherwise. The other mode of the lot is used, if the stay can't be priced in the suitable one.
+func (r *Rental) QuoteStay(checkIn, checkOut time.Time) (*lot.Stay, error) {
+	modes := []string{ModeShortTerm, ModeLongTerm}
+	if StayLength(ModeLongTerm, checkIn, checkOut) > 0 {
+		modes[0], modes[1] = modes[1], modes[0]
+	}
+
+	var firstErr error
+	for _, mode := range modes {
+		if r.Mode(mode) == nil {
+			continue
+		}
+		stay, err := r.Quote(mode, checkIn, checkOut)
+		if err == nil {
+			return stay, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		return nil, errors.New("the lot isn't rented")
+	}
+	return nil, firstErr
+}
+
+// price returns price of the mode for night or month starting on the day.
+func (r *Rental) price(m *Mode, day time.Time) int {
+	for _, s := range r.Seasons {
+		if s.Mode == m.Mode && !day.Before(s.Start) && day.Before(s.End) {
+			return s.Price
+		}
+	}
+	return m.Price
+}
+
+func nights(checkIn, checkOut time.Time) int {
+	return int(math.Round(checkOut.Sub(checkIn).Hours() / 24))
+}
+
+// months returns number of full months of the stay and number of days left.
+func months(checkIn, checkOut time.Time) (int, int) {
+	months := 0
+	for !checkIn.AddDate(0, months+1, 0).After(checkOut) {
+		months++
+	}
+	return months, nights(checkIn.AddDate(0, months, 0), checkOut)
+}
@@ -0,0 +1,157 @@
+package rental
+
+import (
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func TestQuote(t *testing.T) {
+	l := &lot.Lot{ID: 1, Price: 60000, Currency: "RUB", PricePeriod: lot.PeriodMonth}
+	r, err := NewRental(l, &SetRentalDTO{
+		Modes: []ModeDTO{
+			{Mode: ModeLongTerm, Price: 60000, MinStay: 2},
+			{Mode: ModeShortTerm, Price: 3000, MinStay: 2, CleaningFee: 1500},
+		},
+		Seasons: []SeasonDTO{
+			{Mode: ModeShortTerm, Start: "2026-12-30", End: "2027-01-02", Price: 5000},
+			{Mode: ModeLongTerm, Start: "2027-01-01", End: "2027-03-01", Price: 90000},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		checkIn  string
+		checkOut string
+		want     lot.Stay
+		wantErr  bool
+	}{
+		{
+			// two usual nights and two nights of the season
+			name: "short-term", mode: ModeShortTerm, checkIn: "2026-12-28", checkOut: "2027-01-01",
+			want: lot.Stay{Nights: 4, Price: 16000, CleaningFee: 1500, Total: 17500},
+		},
+		{name: "short-term shorter than minimal stay", mode: ModeShortTerm, checkIn: "2026-12-28", checkOut: "2026-12-29", wantErr: true},
+		{
+			// December at usual price, January in the season and 15 days of February at seasonal price
+			name: "long-term", mode: ModeLongTerm, checkIn: "2026-12-01", checkOut: "2027-02-16",
+			want: lot.Stay{Nights: 77, Months: 2, Price: 60000 + 90000 + 45000, Total: 195000},
+		},
+		{name: "long-term shorter than minimal stay", mode: ModeLongTerm, checkIn: "2026-12-01", checkOut: "2027-01-20", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stay, err := r.Quote(tt.mode, date(tt.checkIn), date(tt.checkOut))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", stay)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := lot.Stay{Nights: stay.Nights, Months: stay.Months, Price: stay.Price, CleaningFee: stay.CleaningFee,
+				Total: stay.Total}
+			if got != tt.want || stay.Currency != "RUB" {
+				t.Errorf("expected %+v, got %+v", tt.want, *stay)
+			}
+		})
+	}
+
+	if _, err = Default(l).Quote(ModeShortTerm, date("2026-12-01"), date("2026-12-02")); err == nil {
+		t.Error("monthly lot without modes must not be rented by nights")
+	}
+}
+
+func TestQuoteStay(t *testing.T) {
+	l := &lot.Lot{ID: 1, Price: 60000, Currency: "RUB", PricePeriod: lot.PeriodMonth}
+	r, err := NewRental(l, &SetRentalDTO{Modes: []ModeDTO{
+		{Mode: ModeLongTerm, Price: 60000},
+		{Mode: ModeShortTerm, Price: 3000},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	daily := Default(&lot.Lot{ID: 2, Price: 3000, Currency: "RUB", PricePeriod: lot.PeriodDay})
+
+	tests := []struct {
+		name     string
+		r        *Rental
+		checkIn  string
+		checkOut string
+		want     string
+		wantErr  bool
+	}{
+		{name: "full month", r: r, checkIn: "2026-12-01", checkOut: "2027-01-05", want: ModeLongTerm},
+		{name: "less than a month", r: r, checkIn: "2026-12-01", checkOut: "2026-12-05", want: ModeShortTerm},
+		{name: "daily lot for a month", r: daily, checkIn: "2026-12-01", checkOut: "2027-01-05", want: ModeShortTerm},
+		{name: "monthly lot for days", r: Default(l), checkIn: "2026-12-01", checkOut: "2026-12-05", wantErr: true},
+		{name: "lot for sale", r: Default(&lot.Lot{ID: 3, Price: 9000000, PricePeriod: lot.PeriodSale}),
+			checkIn: "2026-12-01", checkOut: "2026-12-05", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stay, err := tt.r.QuoteStay(date(tt.checkIn), date(tt.checkOut))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", stay)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stay.Mode != tt.want {
+				t.Errorf("expected mode %s, got %s", tt.want, stay.Mode)
+			}
+		})
+	}
+}
+
+func TestNewRental(t *testing.T) {
+	l := &lot.Lot{ID: 1, Price: 3000, Currency: "RUB", PricePeriod: lot.PeriodDay}
+	tests := []struct {
+		name string
+		dto  *SetRentalDTO
+	}{
+		{name: "repeated mode", dto: &SetRentalDTO{Modes: []ModeDTO{
+			{Mode: ModeShortTerm, Price: 3000}, {Mode: ModeShortTerm, Price: 4000},
+		}}},
+		{name: "season of unknown mode", dto: &SetRentalDTO{
+			Modes:   []ModeDTO{{Mode: ModeShortTerm, Price: 3000}},
+			Seasons: []SeasonDTO{{Mode: ModeLongTerm, Start: "2026-06-01", End: "2026-09-01", Price: 90000}},
+		}},
+		{name: "overlapping seasons", dto: &SetRentalDTO{
+			Modes: []ModeDTO{{Mode: ModeShortTerm, Price: 3000}},
+			Seasons: []SeasonDTO{
+				{Mode: ModeShortTerm, Start: "2026-07-01", End: "2026-09-01", Price: 5000},
+				{Mode: ModeShortTerm, Start: "2026-06-01", End: "2026-07-02", Price: 4000},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRental(l, tt.dto); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	r, err := NewRental(l, &SetRentalDTO{Modes: []ModeDTO{{Mode: ModeShortTerm, Price: 3000}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Modes[0].MinStay != 1 {
+		t.Errorf("expected minimal stay of 1 night by default, got %d", r.Modes[0].MinStay)
+	}
+}
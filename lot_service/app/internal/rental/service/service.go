@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/calendar"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/rental"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/rental/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"time"
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	// Get returns rental modes of the lot, lots without modes have the mode of their price period.
+	Get(ctx context.Context, lotID uint) (*rental.Rental, error)
+	// Set replaces rental modes and seasons of the lot. Modes are set by the agent of the lot and by managers
+	// of its organization.
+	Set(ctx context.Context, dto *rental.SetRentalDTO) (*rental.Rental, error)
+	// Quote returns price of the stay in the lot.
+	Quote(ctx context.Context, dto *rental.QuoteDTO) (*lot.Stay, error)
+	// QuoteStay returns price of the stay in the lot in the mode, which suits the stay.
+	QuoteStay(ctx context.Context, l *lot.Lot, checkIn, checkOut time.Time) (*lot.Stay, error)
+	// QuoteLots sets prices of the stay to the lots, lots, which can't be rented for the stay, get no price.
+	QuoteLots(ctx context.Context, lots []*lot.Lot, mode string, checkIn, checkOut time.Time) error
+}
+
+type service struct {
+	repository    storage.Repository
+	lots          lotStorage.Repository
+	organizations organizationStorage.Repository
+	logger        logging.Logger
+}
+
+func NewService(rentalStorage storage.Repository, lots lotStorage.Repository,
+	organizations organizationStorage.Repository, logger logging.Logger) (*service, error) {
+	return &service{
+		repository:    rentalStorage,
+		lots:          lots,
+		organizations: organizations,
+		logger:        logger,
+	}, nil
+}
+
+func (s *service) Get(ctx context.Context, lotID uint) (*rental.Rental, error) {
+	l, err := s.findLot(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+	return s.find(ctx, l)
+}
+
+func (s *service) Set(ctx context.Context, dto *rental.SetRentalDTO) (*rental.Rental, error) {
+	s.logger.Debug("validating rental fields..")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	l, err := lotService.FindForChange(ctx, s.lots, s.organizations, dto.LotID, dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if l.PricePeriod == lot.PeriodSale {
+		return nil, apperror.BadRequestError("lots for sale can't be rented", "")
+	}
+	r, err := rental.NewRental(l, dto)
+	if err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	before, err := s.find(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	after := r
+	if len(r.Modes) == 0 {
+		after = rental.Default(l)
+	}
+
+	if err = s.repository.Save(ctx, r, history.NewRentalRecord(dto.UserID, l, before, after)); err != nil {
+		return nil, fmt.Errorf("failed to save rental modes. error: %w", err)
+	}
+	return after, nil
+}
+
+func (s *service) Quote(ctx context.Context, dto *rental.QuoteDTO) (*lot.Stay, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	checkIn, checkOut, err := calendar.ParsePeriod(dto.CheckIn, dto.CheckOut)
+	if err != nil {
+		return nil, apperror.BadRequestError(fmt.Sprintf("wrong stay: %v", err), "")
+	}
+
+	l, err := s.findLot(ctx, dto.LotID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.find(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	stay, err := r.Quote(dto.Mode, checkIn, checkOut)
+	if err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	return stay, nil
+}
+
+func (s *service) QuoteStay(ctx context.Context, l *lot.Lot, checkIn, checkOut time.Time) (*lot.Stay, error) {
+	r, err := s.find(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	stay, err := r.QuoteStay(checkIn, checkOut)
+	if err != nil {
+		return nil, apperror.BadRequestError(fmt.Sprintf("stay can't be priced: %v", err), "")
+	}
+	return stay, nil
+}
+
+func (s *service) QuoteLots(ctx context.Context, lots []*lot.Lot, mode string, checkIn, checkOut time.Time) error {
+	ids := make([]uint, 0, len(lots))
+	for _, l := range lots {
+		ids = append(ids, l.ID)
+	}
+	rentals, err := s.repository.FindByLotIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to find rental modes of lots. error: %w", err)
+	}
+
+	for _, l := range lots {
+		r, ok := rentals[l.ID]
+		if ok {
+			r.Currency = l.Currency
+		} else {
+			r = rental.Default(l)
+		}
+		if l.Stay, err = r.Quote(mode, checkIn, checkOut); err != nil {
+			s.logger.Debugf("lot %d can't be rented for the stay: %v", l.ID, err)
+		}
+	}
+	return nil
+}
+
+// find returns saved rental modes of the lot or the default one.
+func (s *service) find(ctx context.Context, l *lot.Lot) (*rental.Rental, error) {
+	rentals, err := s.repository.FindByLotIDs(ctx, []uint{l.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find rental modes of lot. error: %w", err)
+	}
+	r, ok := rentals[l.ID]
+	if !ok {
+		return rental.Default(l), nil
+	}
+	r.Currency = l.Currency
+	return r, nil
+}
+
+func (s *service) findLot(ctx context.Context, lotID uint) (*lot.Lot, error) {
+	l, err := s.lots.FindByLotID(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot. error: %w", err)
+	}
+	return l, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/rental"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/rental/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"testing"
+)
+
+type repo struct {
+	storage.Repository
+	saved  *rental.Rental
+	record *history.Record
+}
+
+func (r *repo) FindByLotIDs(_ context.Context, _ []uint) (map[uint]*rental.Rental, error) {
+	return make(map[uint]*rental.Rental), nil
+}
+
+func (r *repo) Save(_ context.Context, saved *rental.Rental, record *history.Record) error {
+	r.saved, r.record = saved, record
+	return nil
+}
+
+type lots struct {
+	lotStorage.Repository
+	lot *lot.Lot
+}
+
+func (l *lots) FindByLotID(_ context.Context, _ uint) (*lot.Lot, error) {
+	copied := *l.lot
+	return &copied, nil
+}
+
+type organizations struct {
+	organizationStorage.Repository
+	roles map[uint]organization.Role
+}
+
+func (o *organizations) FindMember(_ context.Context, organizationID, userID uint) (*organization.Member, error) {
+	role, ok := o.roles[userID]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return &organization.Member{OrganizationID: organizationID, UserID: userID, Role: role}, nil
+}
+
+func TestSet(t *testing.T) {
+	organizationID := uint(1)
+	members := &organizations{roles: map[uint]organization.Role{
+		10: organization.RoleManager,
+		11: organization.RoleAgent,
+		12: organization.RoleAgent,
+	}}
+	l := &lot.Lot{ID: 1, CreatedByUserID: 11, OrganizationID: &organizationID, Price: 50000,
+		Currency: "RUB", PricePeriod: lot.PeriodMonth}
+
+	tests := []struct {
+		name      string
+		userID    uint
+		forbidden bool
+	}{
+		{name: "agent of the lot", userID: 11},
+		{name: "manager", userID: 10},
+		{name: "other agent", userID: 12, forbidden: true},
+		{name: "not a member", userID: 20, forbidden: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &repo{}
+			s, _ := NewService(r, &lots{lot: l}, members, logging.GetLogger())
+
+			_, err := s.Set(context.Background(), &rental.SetRentalDTO{
+				LotID:  1,
+				UserID: tt.userID,
+				Modes:  []rental.ModeDTO{{Mode: rental.ModeShortTerm, Price: 3000}},
+			})
+			if tt.forbidden {
+				var appErr *apperror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != apperror.ForbiddenError("").Code {
+					t.Fatalf("expected forbidden error, got %v", err)
+				}
+				if r.saved != nil {
+					t.Error("rental modes must not be saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.saved == nil {
+				t.Fatal("rental modes must be saved")
+			}
+			v := r.record.Version
+			if v.Action != history.ActionRental || v.ActorID != tt.userID || len(v.Changes) != 1 {
+				t.Errorf("expected version with changed rental modes, got %+v", v)
+			}
+		})
+	}
+}
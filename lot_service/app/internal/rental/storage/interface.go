@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/history"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/rental"
+)
+
+type Repository interface {
+	// FindByLotIDs returns saved rental modes and seasons by IDs of lots, lots without modes are left out.
+	// Currency of rentals isn't set.
+	FindByLotIDs(ctx context.Context, lotIDs []uint) (map[uint]*rental.Rental, error)
+	// Save replaces rental modes and seasons of the lot and saves version of the record in the same transaction,
+	// nil record saves no version.
+	Save(ctx context.Context, r *rental.Rental, record *history.Record) error
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/review"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/review/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+)
+
+var _ storage.Repository = &db{}
+
+// errDuplicateEntry is code of MySQL error on violation of unique key.
+const errDuplicateEntry = 1062
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+const reviewColumns = `
+	r.review_id, IFNULL(r.booking_id, 0), IFNULL(r.lot_id, 0), r.landlord_id, r.author_id, r.rating, r.text,
+	IFNULL(r.reply, ''), r.replied_at, r.hidden, r.created_at,
+	(SELECT COUNT(*) FROM review_reports rr WHERE rr.review_id=r.review_id) AS reports`
+
+func scanReview(row scanner) (*review.Review, error) {
+	r := &review.Review{}
+	var createdAt, repliedAt *mysql.RawTime
+	err := row.Scan(&r.ID, &r.BookingID, &r.LotID, &r.LandlordID, &r.AuthorID, &r.Rating, &r.Text,
+		&r.Reply, &repliedAt, &r.Hidden, &createdAt, &r.Reports)
+	if err != nil {
+		return nil, err
+	}
+	if r.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	if repliedAt != nil {
+		t, err := repliedAt.Time()
+		if err != nil {
+			return nil, err
+		}
+		r.RepliedAt = &t
+	}
+	return r, nil
+}
+
+func (s *db) Create(ctx context.Context, r *review.Review) (uint, error) {
+	queryString := `
+	INSERT INTO reviews (booking_id, lot_id, landlord_id, author_id, rating, text, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?);`
+
+	res, err := s.db.ExecContext(ctx, queryString,
+		r.BookingID, r.LotID, r.LandlordID, r.AuthorID, r.Rating, r.Text, r.CreatedAt.UTC())
+	if err != nil {
+		var mysqlErr *driver.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+			return 0, apperror.ConflictError("booking has been reviewed already")
+		}
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), nil
+}
+
+func (s *db) FindByID(ctx context.Context, id uint) (*review.Review, error) {
+	queryString := `
+	SELECT` + reviewColumns + `
+	FROM reviews r
+	WHERE r.review_id=?;`
+
+	r, err := scanReview(s.db.QueryRowContext(ctx, queryString, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *db) Find(ctx context.Context, filter storage.Filter) ([]*review.Review, error) {
+	qb := sq.Select(reviewColumns).From("reviews r").OrderBy("r.created_at DESC", "r.review_id DESC")
+	if filter.LotID != 0 {
+		qb = qb.Where(sq.Eq{"r.lot_id": filter.LotID})
+	}
+	if filter.LandlordID != 0 {
+		qb = qb.Where(sq.Eq{"r.landlord_id": filter.LandlordID})
+	}
+	if !filter.WithHidden {
+		qb = qb.Where(sq.Eq{"r.hidden": false})
+	}
+
+	sqlQ, args, err := qb.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return s.query(ctx, sqlQ, args...)
+}
+
+func (s *db) FindReported(ctx context.Context) ([]*review.Review, error) {
+	queryString := `
+	SELECT` + reviewColumns + `
+	FROM reviews r
+	WHERE EXISTS (SELECT 1 FROM review_reports rr WHERE rr.review_id=r.review_id)
+	ORDER BY reports DESC, r.review_id;`
+
+	return s.query(ctx, queryString)
+}
+
+func (s *db) query(ctx context.Context, queryString string, args ...any) ([]*review.Review, error) {
+	rows, err := s.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := make([]*review.Review, 0)
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (s *db) Reply(ctx context.Context, r *review.Review) error {
+	queryString := `
+	UPDATE reviews
+	SET reply=?, replied_at=?
+	WHERE review_id=? AND landlord_id=?;`
+
+	res, err := s.db.ExecContext(ctx, queryString, r.Reply, r.RepliedAt.UTC(), r.ID, r.LandlordID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+func (s *db) Report(ctx context.Context, report *review.Report) (int, error) {
+	queryString := `
+	INSERT IGNORE INTO review_reports (review_id, user_id, reason, created_at)
+	VALUES (?, ?, ?, ?);`
+
+	if _, err := s.db.ExecContext(ctx, queryString,
+		report.ReviewID, report.UserID, report.Reason, report.CreatedAt.UTC()); err != nil {
+		return 0, err
+	}
+
+	var reports int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM review_reports WHERE review_id=?;`, report.ReviewID).
+		Scan(&reports)
+	return reports, err
+}
+
+func (s *db) SetHidden(ctx context.Context, id uint, hidden bool) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE reviews SET hidden=? WHERE review_id=?;`, hidden, id)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		// MySQL doesn't count rows, which already have the value
+		if _, err = s.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *db) LandlordRating(ctx context.Context, landlordID uint) (*review.Rating, error) {
+	queryString := `
+	SELECT IFNULL(AVG(rating), 0), COUNT(*)
+	FROM reviews
+	WHERE landlord_id=? AND hidden=FALSE;`
+
+	r := &review.Rating{}
+	if err := s.db.QueryRowContext(ctx, queryString, landlordID).Scan(&r.Average, &r.Count); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
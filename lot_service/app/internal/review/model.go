@@ -0,0 +1,90 @@
+package review
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"time"
+)
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Review is left by the renter after a completed booking. It rates both the lot and its landlord.
+type Review struct {
+	ID         uint       `json:"id"`
+	BookingID  uint       `json:"booking_id"`
+	LotID      uint       `json:"lot_id"`
+	LandlordID uint       `json:"landlord_id"`
+	AuthorID   uint       `json:"author_id"`
+	Rating     int        `json:"rating"`
+	Text       string     `json:"text"`
+	Reply      string     `json:"reply,omitempty"` // answer of the landlord
+	RepliedAt  *time.Time `json:"replied_at,omitempty"`
+	Hidden     bool       `json:"hidden"` // hidden reviews are shown only to moderators and don't count in ratings
+	Reports    int        `json:"reports"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Rating is aggregated over visible reviews.
+type Rating struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+// Report is a complaint of a user about abusive review.
+type Report struct {
+	ReviewID  uint      `json:"review_id"`
+	UserID    uint      `json:"user_id"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateReviewDTO struct {
+	BookingID uint   `json:"booking_id"`
+	AuthorID  uint   `json:"author_id"`
+	Rating    int    `json:"rating"`
+	Text      string `json:"text"`
+}
+
+type ReplyDTO struct {
+	ReviewID   uint   `json:"review_id"`
+	LandlordID uint   `json:"landlord_id"`
+	Text       string `json:"text"`
+}
+
+type ReportDTO struct {
+	ReviewID uint   `json:"review_id"`
+	UserID   uint   `json:"user_id"`
+	Reason   string `json:"reason"`
+}
+
+type ModerateDTO struct {
+	ReviewID uint `json:"review_id"`
+	Hidden   bool `json:"hidden"`
+}
+
+func (dto *CreateReviewDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.BookingID, validation.Required),
+		validation.Field(&dto.AuthorID, validation.Required),
+		validation.Field(&dto.Rating, validation.Required, validation.Min(MinRating), validation.Max(MaxRating)),
+		validation.Field(&dto.Text, validation.Length(0, 5000)),
+	)
+}
+
+func (dto *ReplyDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ReviewID, validation.Required),
+		validation.Field(&dto.LandlordID, validation.Required),
+		validation.Field(&dto.Text, validation.Required, validation.Length(1, 5000)),
+	)
+}
+
+func (dto *ReportDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ReviewID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Reason, validation.Required, validation.Length(1, 1000)),
+	)
+}
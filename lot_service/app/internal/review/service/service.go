@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	bookingStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/review"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/review/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"time"
+)
+
+var _ Service = &service{}
+
+type Service interface {
+	// Create saves review of the renter about completed booking.
+	Create(ctx context.Context, dto *review.CreateReviewDTO) (*review.Review, error)
+	GetByID(ctx context.Context, id uint) (*review.Review, error)
+	GetByLot(ctx context.Context, lotID uint) ([]*review.Review, error)
+	GetByLandlord(ctx context.Context, landlordID uint) ([]*review.Review, error)
+	GetLandlordRating(ctx context.Context, landlordID uint) (*review.Rating, error)
+	// Reply saves answer of the landlord to the review. The answer may be edited later.
+	Reply(ctx context.Context, dto *review.ReplyDTO) (*review.Review, error)
+	// Report saves complaint about abusive review. Review is hidden, when it gets enough complaints,
+	// and its author is notified with moderation event.
+	Report(ctx context.Context, dto *review.ReportDTO) error
+
+	// GetReported returns reviews with complaints for moderators, hidden ones included.
+	GetReported(ctx context.Context) ([]*review.Review, error)
+	// Moderate hides the review or shows it again, its author is notified with moderation event.
+	Moderate(ctx context.Context, dto *review.ModerateDTO) (*review.Review, error)
+}
+
+type Config struct {
+	// HideAfterReports is number of complaints of different users, which hides review until moderation.
+	// Zero disables hiding.
+	HideAfterReports int
+}
+
+type service struct {
+	repository storage.Repository
+	bookings   bookingStorage.Repository
+	events     eventService.Publisher
+	cfg        Config
+	logger     logging.Logger
+}
+
+func NewService(reviewStorage storage.Repository, bookings bookingStorage.Repository, events eventService.Publisher,
+	cfg Config, logger logging.Logger) (*service, error) {
+	return &service{
+		repository: reviewStorage,
+		bookings:   bookings,
+		events:     events,
+		cfg:        cfg,
+		logger:     logger,
+	}, nil
+}
+
+func (s *service) Create(ctx context.Context, dto *review.CreateReviewDTO) (*review.Review, error) {
+	s.logger.Debug("validating review fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	b, err := s.bookings.FindByID(ctx, dto.BookingID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find booking of review. error: %w", err)
+	}
+	if b.RenterID != dto.AuthorID {
+		// don't tell others that booking exists
+		return nil, apperror.ErrNotFound
+	}
+	if !completed(b, time.Now()) {
+		return nil, apperror.ForbiddenError("only completed bookings can be reviewed")
+	}
+
+	r := &review.Review{
+		BookingID:  b.ID,
+		LotID:      b.LotID,
+		LandlordID: b.LandlordID,
+		AuthorID:   dto.AuthorID,
+		Rating:     dto.Rating,
+		Text:       dto.Text,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+
+	s.logger.Debug("creating new review..")
+	r.ID, err = s.repository.Create(ctx, r)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create review. error: %w", err)
+	}
+
+	s.events.Publish(ctx, r.LandlordID, event.TypeReview, r)
+	return r, nil
+}
+
+// GetByID returns visible review.
+func (s *service) GetByID(ctx context.Context, id uint) (*review.Review, error) {
+	r, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Hidden {
+		return nil, apperror.ErrNotFound
+	}
+	return r, nil
+}
+
+func (s *service) GetByLot(ctx context.Context, lotID uint) ([]*review.Review, error) {
+	reviews, err := s.repository.Find(ctx, storage.Filter{LotID: lotID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reviews of lot. error: %w", err)
+	}
+	return reviews, nil
+}
+
+func (s *service) GetByLandlord(ctx context.Context, landlordID uint) ([]*review.Review, error) {
+	reviews, err := s.repository.Find(ctx, storage.Filter{LandlordID: landlordID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reviews of landlord. error: %w", err)
+	}
+	return reviews, nil
+}
+
+func (s *service) GetLandlordRating(ctx context.Context, landlordID uint) (*review.Rating, error) {
+	rating, err := s.repository.LandlordRating(ctx, landlordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating of landlord. error: %w", err)
+	}
+	return rating, nil
+}
+
+func (s *service) Reply(ctx context.Context, dto *review.ReplyDTO) (*review.Review, error) {
+	s.logger.Debug("validating reply fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	r, err := s.GetByID(ctx, dto.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+	if r.LandlordID != dto.LandlordID {
+		return nil, apperror.ForbiddenError("only the landlord can reply to the review")
+	}
+
+	repliedAt := time.Now().UTC().Truncate(time.Second)
+	r.Reply = dto.Text
+	r.RepliedAt = &repliedAt
+	if err = s.repository.Reply(ctx, r); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save reply to review. error: %w", err)
+	}
+
+	s.events.Publish(ctx, r.AuthorID, event.TypeReview, r)
+	return r, nil
+}
+
+func (s *service) Report(ctx context.Context, dto *review.ReportDTO) error {
+	s.logger.Debug("validating report fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return apperror.BadRequestError(err.Error(), "")
+	}
+
+	r, err := s.GetByID(ctx, dto.ReviewID)
+	if err != nil {
+		return err
+	}
+	if r.AuthorID == dto.UserID {
+		return apperror.BadRequestError("own review can't be reported", "")
+	}
+
+	reports, err := s.repository.Report(ctx, &review.Report{
+		ReviewID:  r.ID,
+		UserID:    dto.UserID,
+		Reason:    dto.Reason,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to report review. error: %w", err)
+	}
+
+	if s.cfg.HideAfterReports > 0 && reports >= s.cfg.HideAfterReports {
+		s.logger.Infof("review %d is hidden until moderation after %d reports", r.ID, reports)
+		if err = s.repository.SetHidden(ctx, r.ID, true); err != nil {
+			return fmt.Errorf("failed to hide reported review. error: %w", err)
+		}
+		r.Hidden = true
+		s.events.Publish(ctx, r.AuthorID, event.TypeModeration,
+			&event.Moderation{Subject: event.ModerationReview, Object: r})
+	}
+	return nil
+}
+
+func (s *service) GetReported(ctx context.Context) ([]*review.Review, error) {
+	reviews, err := s.repository.FindReported(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reported reviews. error: %w", err)
+	}
+	return reviews, nil
+}
+
+func (s *service) Moderate(ctx context.Context, dto *review.ModerateDTO) (*review.Review, error) {
+	if err := s.repository.SetHidden(ctx, dto.ReviewID, dto.Hidden); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to moderate review. error: %w", err)
+	}
+	s.logger.Infof("review %d hidden: %t", dto.ReviewID, dto.Hidden)
+	r, err := s.find(ctx, dto.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, r.AuthorID, event.TypeModeration, &event.Moderation{Subject: event.ModerationReview, Object: r})
+	return r, nil
+}
+
+func (s *service) find(ctx context.Context, id uint) (*review.Review, error) {
+	r, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find review by its id. error: %w", err)
+	}
+	return r, nil
+}
+
+// completed reports whether the renter has checked out after accepted booking.
+func completed(b *booking.Booking, now time.Time) bool {
+	return b.Status == booking.StatusAccepted && !now.Before(b.CheckOut)
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/booking"
+	bookingStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/booking/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/review"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/review/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	storage.Repository
+	created []*review.Review
+	review  *review.Review
+	reports map[uint]bool
+	hidden  bool
+}
+
+func (r *repo) Create(_ context.Context, rv *review.Review) (uint, error) {
+	r.created = append(r.created, rv)
+	return uint(len(r.created)), nil
+}
+
+func (r *repo) FindByID(_ context.Context, _ uint) (*review.Review, error) {
+	if r.review == nil {
+		return nil, apperror.ErrNotFound
+	}
+	copied := *r.review
+	copied.Hidden = r.hidden
+	return &copied, nil
+}
+
+func (r *repo) Report(_ context.Context, report *review.Report) (int, error) {
+	if r.reports == nil {
+		r.reports = make(map[uint]bool)
+	}
+	r.reports[report.UserID] = true
+	return len(r.reports), nil
+}
+
+func (r *repo) SetHidden(_ context.Context, _ uint, hidden bool) error {
+	r.hidden = hidden
+	return nil
+}
+
+type bookings struct {
+	bookingStorage.Repository
+	booking *booking.Booking
+}
+
+func (b *bookings) FindByID(_ context.Context, _ uint) (*booking.Booking, error) {
+	return b.booking, nil
+}
+
+type publisher struct {
+	recipients []uint
+}
+
+func (p *publisher) Publish(_ context.Context, userID uint, _ string, _ any) {
+	p.recipients = append(p.recipients, userID)
+}
+
+func TestCreate(t *testing.T) {
+	past := time.Now().AddDate(0, 0, -3).Truncate(24 * time.Hour)
+	future := time.Now().AddDate(0, 0, 3).Truncate(24 * time.Hour)
+	dto := &review.CreateReviewDTO{BookingID: 1, AuthorID: 10, Rating: 4, Text: "nice flat"}
+
+	tests := []struct {
+		name    string
+		booking *booking.Booking
+		want    error
+	}{
+		{
+			name:    "completed booking",
+			booking: &booking.Booking{ID: 1, LotID: 2, RenterID: 10, LandlordID: 20, Status: booking.StatusAccepted, CheckOut: past},
+		},
+		{
+			name:    "stay is not over",
+			booking: &booking.Booking{ID: 1, LotID: 2, RenterID: 10, LandlordID: 20, Status: booking.StatusAccepted, CheckOut: future},
+			want:    apperror.ForbiddenError(""),
+		},
+		{
+			name:    "booking was cancelled",
+			booking: &booking.Booking{ID: 1, LotID: 2, RenterID: 10, LandlordID: 20, Status: booking.StatusCancelled, CheckOut: past},
+			want:    apperror.ForbiddenError(""),
+		},
+		{
+			name:    "booking of another renter",
+			booking: &booking.Booking{ID: 1, LotID: 2, RenterID: 11, LandlordID: 20, Status: booking.StatusAccepted, CheckOut: past},
+			want:    apperror.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, p := &repo{}, &publisher{}
+			s, _ := NewService(r, &bookings{booking: tt.booking}, p, Config{}, logging.GetLogger())
+
+			rv, err := s.Create(context.Background(), dto)
+			if tt.want != nil {
+				if !sameError(err, tt.want) {
+					t.Fatalf("expected %v, got %v", tt.want, err)
+				}
+				if len(r.created) != 0 {
+					t.Error("review must not be saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rv.LotID != 2 || rv.LandlordID != 20 {
+				t.Errorf("review must be about lot 2 of landlord 20, got lot %d of landlord %d", rv.LotID, rv.LandlordID)
+			}
+			if len(p.recipients) != 1 || p.recipients[0] != 20 {
+				t.Errorf("landlord must be notified, got %v", p.recipients)
+			}
+		})
+	}
+}
+
+func TestReply(t *testing.T) {
+	r := &repo{review: &review.Review{ID: 1, LandlordID: 20, AuthorID: 10}}
+	s, _ := NewService(r, nil, &publisher{}, Config{}, logging.GetLogger())
+
+	_, err := s.Reply(context.Background(), &review.ReplyDTO{ReviewID: 1, LandlordID: 21, Text: "not mine"})
+	if !sameError(err, apperror.ForbiddenError("")) {
+		t.Errorf("expected forbidden error for other user, got %v", err)
+	}
+}
+
+func TestReport(t *testing.T) {
+	r, p := &repo{review: &review.Review{ID: 1, LandlordID: 20, AuthorID: 10}}, &publisher{}
+	s, _ := NewService(r, nil, p, Config{HideAfterReports: 2}, logging.GetLogger())
+	ctx := context.Background()
+
+	err := s.Report(ctx, &review.ReportDTO{ReviewID: 1, UserID: 10, Reason: "spam"})
+	if !sameError(err, apperror.BadRequestError("", "")) {
+		t.Errorf("expected bad request for own review, got %v", err)
+	}
+
+	for _, userID := range []uint{30, 30, 31} {
+		if r.hidden {
+			t.Fatalf("review is hidden before %d reports", s.cfg.HideAfterReports)
+		}
+		if err = s.Report(ctx, &review.ReportDTO{ReviewID: 1, UserID: userID, Reason: "abuse"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !r.hidden {
+		t.Error("review must be hidden after reports of different users")
+	}
+	if len(p.recipients) != 1 || p.recipients[0] != 10 {
+		t.Errorf("author must be notified about hidden review, got %v", p.recipients)
+	}
+
+	if _, err = s.GetByID(ctx, 1); !errors.Is(err, apperror.ErrNotFound) {
+		t.Errorf("hidden review must not be found, got %v", err)
+	}
+}
+
+func TestModerate(t *testing.T) {
+	r, p := &repo{review: &review.Review{ID: 1, LandlordID: 20, AuthorID: 10}}, &publisher{}
+	s, _ := NewService(r, nil, p, Config{}, logging.GetLogger())
+
+	for _, hidden := range []bool{true, false} {
+		rv, err := s.Moderate(context.Background(), &review.ModerateDTO{ReviewID: 1, Hidden: hidden})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rv.Hidden != hidden {
+			t.Errorf("expected hidden %t, got %t", hidden, rv.Hidden)
+		}
+	}
+	if len(p.recipients) != 2 || p.recipients[0] != 10 || p.recipients[1] != 10 {
+		t.Errorf("author must be notified about hiding and showing review, got %v", p.recipients)
+	}
+}
+
+// sameError compares app errors by code, since their messages differ.
+func sameError(err, want error) bool {
+	var got, expected *apperror.AppError
+	if !errors.As(err, &got) || !errors.As(want, &expected) {
+		return false
+	}
+	return got.Code == expected.Code
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/review"
+)
+
+// Filter selects reviews of the lot or of the landlord. Hidden reviews are selected only with WithHidden.
+type Filter struct {
+	LotID      uint
+	LandlordID uint
+	WithHidden bool
+}
+
+type Repository interface {
+	// Create saves review, only one review is allowed per booking.
+	Create(ctx context.Context, r *review.Review) (uint, error)
+	FindByID(ctx context.Context, id uint) (*review.Review, error)
+	Find(ctx context.Context, filter Filter) ([]*review.Review, error)
+	// FindReported returns reviews with at least one report, most reported first.
+	FindReported(ctx context.Context) ([]*review.Review, error)
+	Reply(ctx context.Context, r *review.Review) error
+	// Report saves complaint of the user about the review and returns number of reports of the review.
+	// Repeated complaints of the same user are not counted.
+	Report(ctx context.Context, report *review.Report) (int, error)
+	SetHidden(ctx context.Context, id uint, hidden bool) error
+	LandlordRating(ctx context.Context, landlordID uint) (*review.Rating, error)
+}
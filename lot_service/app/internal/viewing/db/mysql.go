@@ -0,0 +1,398 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/mysql"
+	"time"
+)
+
+var _ storage.Repository = &db{}
+
+type db struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+func NewStorage(storage *sql.DB, logger logging.Logger) *db {
+	return &db{
+		db:     storage,
+		logger: logger,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is either database or transaction.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const slotColumns = `
+	slot_id, lot_id, landlord_id, starts_at, ends_at, renter_id, booked_at, sequence, created_at`
+
+func scanSlot(row scanner) (*viewing.Slot, error) {
+	s := &viewing.Slot{}
+	var startsAt, endsAt, createdAt, bookedAt *mysql.RawTime
+	var renterID sql.NullInt64
+	err := row.Scan(&s.ID, &s.LotID, &s.LandlordID, &startsAt, &endsAt, &renterID, &bookedAt, &s.Sequence,
+		&createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if s.StartsAt, err = startsAt.Time(); err != nil {
+		return nil, err
+	}
+	if s.EndsAt, err = endsAt.Time(); err != nil {
+		return nil, err
+	}
+	if s.CreatedAt, err = createdAt.Time(); err != nil {
+		return nil, err
+	}
+	if renterID.Valid {
+		id := uint(renterID.Int64)
+		s.RenterID = &id
+	}
+	if bookedAt != nil {
+		t, err := bookedAt.Time()
+		if err != nil {
+			return nil, err
+		}
+		s.BookedAt = &t
+	}
+	return s, nil
+}
+
+func (s *db) query(ctx context.Context, queryString string, args ...any) ([]*viewing.Slot, error) {
+	rows, err := s.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := make([]*viewing.Slot, 0)
+	for rows.Next() {
+		slot, err := scanSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+func findSlot(ctx context.Context, q querier, id uint, forUpdate bool) (*viewing.Slot, error) {
+	queryString := `
+	SELECT` + slotColumns + `
+	FROM viewing_slots
+	WHERE slot_id=?`
+	if forUpdate {
+		queryString += ` FOR UPDATE`
+	}
+
+	slot, err := scanSlot(q.QueryRowContext(ctx, queryString, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+	return slot, nil
+}
+
+// lockUser serializes changes of slots of the user, so overlapping ones can't be saved concurrently.
+func lockUser(ctx context.Context, tx *sql.Tx, userID uint) error {
+	var id uint
+	err := tx.QueryRowContext(ctx, `SELECT user_id FROM users WHERE user_id=? FOR UPDATE;`, userID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.ErrNotFound
+	}
+	return err
+}
+
+// landlordBusy reports whether the landlord has other slots overlapping the period.
+func landlordBusy(ctx context.Context, tx *sql.Tx, landlordID, exceptID uint, start, end time.Time) (bool, error) {
+	var overlaps bool
+	err := tx.QueryRowContext(ctx, `
+	SELECT EXISTS (
+		SELECT 1 FROM viewing_slots
+		WHERE landlord_id=? AND slot_id<>? AND starts_at<? AND ends_at>?
+	);`, landlordID, exceptID, end.UTC(), start.UTC()).Scan(&overlaps)
+	return overlaps, err
+}
+
+// renterBusy reports whether the renter has other appointments overlapping the period.
+func renterBusy(ctx context.Context, tx *sql.Tx, renterID, exceptID uint, start, end time.Time) (bool, error) {
+	var overlaps bool
+	err := tx.QueryRowContext(ctx, `
+	SELECT EXISTS (
+		SELECT 1 FROM viewing_slots
+		WHERE renter_id=? AND slot_id<>? AND starts_at<? AND ends_at>?
+	);`, renterID, exceptID, end.UTC(), start.UTC()).Scan(&overlaps)
+	return overlaps, err
+}
+
+func (s *db) CreateSlot(ctx context.Context, slot *viewing.Slot) (uint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err = lockUser(ctx, tx, slot.LandlordID); err != nil {
+		return 0, err
+	}
+	busy, err := landlordBusy(ctx, tx, slot.LandlordID, 0, slot.StartsAt, slot.EndsAt)
+	if err != nil {
+		return 0, err
+	}
+	if busy {
+		return 0, apperror.ConflictError("slot overlaps with another viewing slot of the landlord")
+	}
+
+	res, err := tx.ExecContext(ctx, `
+	INSERT INTO viewing_slots (lot_id, landlord_id, starts_at, ends_at, created_at)
+	VALUES (?, ?, ?, ?, ?);`,
+		slot.LotID, slot.LandlordID, slot.StartsAt.UTC(), slot.EndsAt.UTC(), time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	retID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(retID), tx.Commit()
+}
+
+func (s *db) FindSlot(ctx context.Context, id uint) (*viewing.Slot, error) {
+	return findSlot(ctx, s.db, id, false)
+}
+
+func (s *db) FindByLot(ctx context.Context, lotID uint, from time.Time, onlyFree bool) ([]*viewing.Slot, error) {
+	queryString := `
+	SELECT` + slotColumns + `
+	FROM viewing_slots
+	WHERE lot_id=? AND starts_at>?`
+	if onlyFree {
+		queryString += ` AND renter_id IS NULL`
+	}
+	queryString += `
+	ORDER BY starts_at;`
+
+	return s.query(ctx, queryString, lotID, from.UTC())
+}
+
+func (s *db) FindAppointments(ctx context.Context, userID uint, party viewing.Party,
+	from time.Time) ([]*viewing.Slot, error) {
+	column := "renter_id"
+	if party == viewing.PartyLandlord {
+		column = "landlord_id"
+	}
+
+	queryString := `
+	SELECT` + slotColumns + `
+	FROM viewing_slots
+	WHERE ` + column + `=? AND renter_id IS NOT NULL AND ends_at>?
+	ORDER BY starts_at;`
+
+	return s.query(ctx, queryString, userID, from.UTC())
+}
+
+func (s *db) UpdateTime(ctx context.Context, slot *viewing.Slot) (*viewing.Slot, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = lockUser(ctx, tx, slot.LandlordID); err != nil {
+		return nil, err
+	}
+	current, err := findSlot(ctx, tx, slot.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	if current.LandlordID != slot.LandlordID {
+		return nil, apperror.ErrNotFound
+	}
+
+	busy, err := landlordBusy(ctx, tx, slot.LandlordID, slot.ID, slot.StartsAt, slot.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	if busy {
+		return nil, apperror.ConflictError("slot overlaps with another viewing slot of the landlord")
+	}
+	if current.RenterID != nil {
+		busy, err = renterBusy(ctx, tx, *current.RenterID, slot.ID, slot.StartsAt, slot.EndsAt)
+		if err != nil {
+			return nil, err
+		}
+		if busy {
+			return nil, apperror.ConflictError("renter has another viewing at this time, cancel the appointment instead")
+		}
+	}
+
+	// renter is reminded again about the new time
+	_, err = tx.ExecContext(ctx, `
+	UPDATE viewing_slots
+	SET starts_at=?, ends_at=?, sequence=sequence+1, reminder_sent_at=NULL
+	WHERE slot_id=?;`, slot.StartsAt.UTC(), slot.EndsAt.UTC(), slot.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := findSlot(ctx, tx, slot.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit()
+}
+
+func (s *db) DeleteSlot(ctx context.Context, id, landlordID uint) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM viewing_slots WHERE slot_id=? AND landlord_id=?;`, id, landlordID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+func (s *db) Book(ctx context.Context, slotID, renterID, fromSlotID uint, now time.Time) (*viewing.Slot, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = lockUser(ctx, tx, renterID); err != nil {
+		return nil, err
+	}
+	slot, err := findSlot(ctx, tx, slotID, true)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case slot.LandlordID == renterID:
+		return nil, apperror.BadRequestError("own lot can't be booked for viewing", "")
+	case slot.Booked():
+		return nil, apperror.ConflictError("slot is already booked")
+	case !slot.StartsAt.After(now):
+		return nil, apperror.ConflictError("slot has already started")
+	}
+
+	if fromSlotID != 0 {
+		from, err := findSlot(ctx, tx, fromSlotID, true)
+		if err != nil {
+			return nil, err
+		}
+		if from.RenterID == nil || *from.RenterID != renterID {
+			return nil, apperror.ErrNotFound
+		}
+		if from.LotID != slot.LotID {
+			return nil, apperror.BadRequestError("appointment can be moved only to a slot of the same lot", "")
+		}
+		if err = free(ctx, tx, fromSlotID); err != nil {
+			return nil, err
+		}
+	} else {
+		var hasAppointment bool
+		err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM viewing_slots
+			WHERE lot_id=? AND renter_id=? AND ends_at>?
+		);`, slot.LotID, renterID, now.UTC()).Scan(&hasAppointment)
+		if err != nil {
+			return nil, err
+		}
+		if hasAppointment {
+			return nil, apperror.ConflictError("renter already has a viewing of this lot, reschedule it instead")
+		}
+	}
+
+	busy, err := renterBusy(ctx, tx, renterID, slotID, slot.StartsAt, slot.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	if busy {
+		return nil, apperror.ConflictError("renter has another viewing at this time")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	UPDATE viewing_slots
+	SET renter_id=?, booked_at=?, sequence=sequence+1, reminder_sent_at=NULL
+	WHERE slot_id=?;`, renterID, now.UTC(), slotID)
+	if err != nil {
+		return nil, err
+	}
+
+	booked, err := findSlot(ctx, tx, slotID, false)
+	if err != nil {
+		return nil, err
+	}
+	return booked, tx.Commit()
+}
+
+func (s *db) Cancel(ctx context.Context, slotID, renterID uint) (*viewing.Slot, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	slot, err := findSlot(ctx, tx, slotID, true)
+	if err != nil {
+		return nil, err
+	}
+	if slot.RenterID == nil || *slot.RenterID != renterID {
+		return nil, apperror.ErrNotFound
+	}
+	if err = free(ctx, tx, slotID); err != nil {
+		return nil, err
+	}
+
+	cancelled, err := findSlot(ctx, tx, slotID, false)
+	if err != nil {
+		return nil, err
+	}
+	return cancelled, tx.Commit()
+}
+
+func free(ctx context.Context, tx *sql.Tx, slotID uint) error {
+	_, err := tx.ExecContext(ctx, `
+	UPDATE viewing_slots
+	SET renter_id=NULL, booked_at=NULL, sequence=sequence+1, reminder_sent_at=NULL
+	WHERE slot_id=?;`, slotID)
+	return err
+}
+
+func (s *db) FindForReminder(ctx context.Context, now, before time.Time) ([]*viewing.Slot, error) {
+	queryString := `
+	SELECT` + slotColumns + `
+	FROM viewing_slots
+	WHERE renter_id IS NOT NULL AND reminder_sent_at IS NULL AND starts_at>? AND starts_at<=?
+	ORDER BY starts_at;`
+
+	return s.query(ctx, queryString, now.UTC(), before.UTC())
+}
+
+func (s *db) MarkReminded(ctx context.Context, slotID uint, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE viewing_slots SET reminder_sent_at=? WHERE slot_id=?;`, at.UTC(), slotID)
+	return err
+}
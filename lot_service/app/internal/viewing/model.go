@@ -0,0 +1,143 @@
+package viewing
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"time"
+)
+
+const (
+	MinDuration = 10 * time.Minute
+	MaxDuration = 4 * time.Hour
+	// MaxAhead limits how far in the future slots can be published.
+	MaxAhead = 90 * 24 * time.Hour
+)
+
+type Action string
+
+const (
+	ActionBooked      Action = "booked"
+	ActionRescheduled Action = "rescheduled"
+	ActionCancelled   Action = "cancelled"
+	ActionReminder    Action = "reminder"
+)
+
+type Party string
+
+const (
+	PartyRenter   Party = "renter"
+	PartyLandlord Party = "landlord"
+)
+
+// Slot is time, when the landlord is ready to show the lot. Booked slot is an appointment of one renter.
+type Slot struct {
+	ID         uint       `json:"id"`
+	LotID      uint       `json:"lot_id"`
+	LandlordID uint       `json:"landlord_id"`
+	StartsAt   time.Time  `json:"starts_at"`
+	EndsAt     time.Time  `json:"ends_at"`
+	RenterID   *uint      `json:"renter_id,omitempty"`
+	BookedAt   *time.Time `json:"booked_at,omitempty"`
+	// Sequence grows with every change of the appointment, it's used in calendar invites.
+	Sequence  int       `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Booked reports whether the slot is taken by a renter.
+func (s *Slot) Booked() bool {
+	return s.RenterID != nil
+}
+
+// PartyOf returns the side of the appointment the user belongs to.
+func (s *Slot) PartyOf(userID uint) (Party, bool) {
+	switch {
+	case userID == s.LandlordID:
+		return PartyLandlord, true
+	case s.RenterID != nil && userID == *s.RenterID:
+		return PartyRenter, true
+	default:
+		return "", false
+	}
+}
+
+// Appointment is a booked slot with calendar invite for its parties.
+type Appointment struct {
+	Slot   *Slot  `json:"slot"`
+	Invite string `json:"invite"` // iCalendar request, or cancellation for cancelled appointments
+}
+
+// Notice is payload of viewing events.
+type Notice struct {
+	Action Action `json:"action"`
+	Appointment
+}
+
+type CreateSlotDTO struct {
+	LotID      uint   `json:"lot_id"`
+	LandlordID uint   `json:"landlord_id"`
+	StartsAt   string `json:"starts_at"` // RFC 3339
+	EndsAt     string `json:"ends_at"`   // RFC 3339
+}
+
+// UpdateSlotDTO moves the slot to other time, the renter of booked slot is notified.
+type UpdateSlotDTO struct {
+	ID         uint   `json:"id"`
+	LandlordID uint   `json:"landlord_id"`
+	StartsAt   string `json:"starts_at"`
+	EndsAt     string `json:"ends_at"`
+}
+
+// BookSlotDTO books the slot. With FromSlotID the renter's appointment is moved from that slot.
+type BookSlotDTO struct {
+	SlotID     uint `json:"slot_id"`
+	RenterID   uint `json:"renter_id"`
+	FromSlotID uint `json:"from_slot_id"`
+}
+
+func (dto *CreateSlotDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.LotID, validation.Required),
+		validation.Field(&dto.LandlordID, validation.Required),
+		validation.Field(&dto.StartsAt, validation.Required, validation.Date(time.RFC3339)),
+		validation.Field(&dto.EndsAt, validation.Required, validation.Date(time.RFC3339)),
+	)
+}
+
+func (dto *UpdateSlotDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.LandlordID, validation.Required),
+		validation.Field(&dto.StartsAt, validation.Required, validation.Date(time.RFC3339)),
+		validation.Field(&dto.EndsAt, validation.Required, validation.Date(time.RFC3339)),
+	)
+}
+
+func (dto *BookSlotDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.SlotID, validation.Required),
+		validation.Field(&dto.RenterID, validation.Required),
+	)
+}
+
+// ParsePeriod parses time of the slot and checks its duration. Slots must start after now.
+func ParsePeriod(startsAt, endsAt string, now time.Time) (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, startsAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.Parse(time.RFC3339, endsAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	start, end = start.UTC().Truncate(time.Minute), end.UTC().Truncate(time.Minute)
+	switch d := end.Sub(start); {
+	case !start.After(now):
+		return time.Time{}, time.Time{}, errors.New("slot must start in the future")
+	case start.After(now.Add(MaxAhead)):
+		return time.Time{}, time.Time{}, errors.New("slot is too far in the future")
+	case d < MinDuration || d > MaxDuration:
+		return time.Time{}, time.Time{}, errors.New("slot must last from 10 minutes to 4 hours")
+	}
+	return start, end, nil
+}
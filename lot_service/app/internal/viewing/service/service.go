@@ -0,0 +1,384 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/event"
+	eventService "github.com/levelord1311/backendForSharedProject/lot_service/internal/event/service"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotService "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/service"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/ical"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"time"
+)
+
+const prodID = "-//backendForSharedProject//viewings//EN"
+
+var _ Service = &service{}
+
+type Service interface {
+	// CreateSlot publishes time, when the landlord shows the lot. Slots are published by the agent of the lot
+	// and by managers of its organization, the one publishing the slot shows the lot.
+	CreateSlot(ctx context.Context, dto *viewing.CreateSlotDTO) (*viewing.Slot, error)
+	// GetLotSlots returns upcoming free slots of the lot, users, who can change the lot, see booked ones too.
+	GetLotSlots(ctx context.Context, lotID, userID uint) ([]*viewing.Slot, error)
+	GetAppointments(ctx context.Context, userID uint, party viewing.Party) ([]*viewing.Slot, error)
+	// UpdateSlot reschedules the slot, its renter is notified.
+	UpdateSlot(ctx context.Context, dto *viewing.UpdateSlotDTO) (*viewing.Appointment, error)
+	// DeleteSlot removes the slot, appointment in it is cancelled.
+	DeleteSlot(ctx context.Context, id, userID uint) error
+
+	// Book makes an appointment for the renter or moves the renter's appointment to other slot.
+	Book(ctx context.Context, dto *viewing.BookSlotDTO) (*viewing.Appointment, error)
+	// Cancel cancels appointment by either of its parties, the slot becomes free again.
+	Cancel(ctx context.Context, slotID, userID uint) (*viewing.Appointment, error)
+	// GetAppointment returns appointment with calendar invite to one of its parties.
+	GetAppointment(ctx context.Context, slotID, userID uint) (*viewing.Appointment, error)
+
+	// SendReminders notifies parties of appointments starting soon and returns their number.
+	SendReminders(ctx context.Context, now time.Time) (int, error)
+}
+
+type Config struct {
+	// Domain is used in UIDs of calendar invites.
+	Domain string
+	// RemindBefore is how long before the viewing its parties are reminded.
+	RemindBefore time.Duration
+}
+
+type service struct {
+	repository    storage.Repository
+	lots          lotStorage.Repository
+	organizations organizationStorage.Repository
+	events        eventService.Publisher
+	cfg           Config
+	logger        logging.Logger
+}
+
+// NewService returns viewing service. Parties of appointments are notified about changes with events,
+// which carry calendar invites.
+func NewService(viewingStorage storage.Repository, lots lotStorage.Repository,
+	organizations organizationStorage.Repository, events eventService.Publisher, cfg Config,
+	logger logging.Logger) (*service, error) {
+	return &service{
+		repository:    viewingStorage,
+		lots:          lots,
+		organizations: organizations,
+		events:        events,
+		cfg:           cfg,
+		logger:        logger,
+	}, nil
+}
+
+func (s *service) CreateSlot(ctx context.Context, dto *viewing.CreateSlotDTO) (*viewing.Slot, error) {
+	s.logger.Debug("validating slot fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	startsAt, endsAt, err := viewing.ParsePeriod(dto.StartsAt, dto.EndsAt, time.Now())
+	if err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	l, err := lotService.FindForChange(ctx, s.lots, s.organizations, dto.LotID, dto.LandlordID)
+	if err != nil {
+		return nil, err
+	}
+
+	slot := &viewing.Slot{
+		LotID:      l.ID,
+		LandlordID: dto.LandlordID,
+		StartsAt:   startsAt,
+		EndsAt:     endsAt,
+	}
+	s.logger.Debug("creating new viewing slot..")
+	slot.ID, err = s.repository.CreateSlot(ctx, slot)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create viewing slot. error: %w", err)
+	}
+	return s.findSlot(ctx, slot.ID)
+}
+
+func (s *service) GetLotSlots(ctx context.Context, lotID, userID uint) ([]*viewing.Slot, error) {
+	l, err := s.findLot(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	canChange, err := lotService.CanChange(ctx, s.organizations, l, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.repository.FindByLot(ctx, lotID, time.Now(), !canChange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find viewing slots of lot. error: %w", err)
+	}
+	return slots, nil
+}
+
+func (s *service) GetAppointments(ctx context.Context, userID uint, party viewing.Party) ([]*viewing.Slot, error) {
+	if party != viewing.PartyRenter && party != viewing.PartyLandlord {
+		return nil, apperror.BadRequestError("party must be either renter or landlord", "")
+	}
+
+	slots, err := s.repository.FindAppointments(ctx, userID, party, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find viewing appointments. error: %w", err)
+	}
+	return slots, nil
+}
+
+func (s *service) UpdateSlot(ctx context.Context, dto *viewing.UpdateSlotDTO) (*viewing.Appointment, error) {
+	s.logger.Debug("validating slot fields...")
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	startsAt, endsAt, err := viewing.ParsePeriod(dto.StartsAt, dto.EndsAt, time.Now())
+	if err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+
+	slot, err := s.repository.UpdateTime(ctx, &viewing.Slot{
+		ID:         dto.ID,
+		LandlordID: dto.LandlordID,
+		StartsAt:   startsAt,
+		EndsAt:     endsAt,
+	})
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update viewing slot. error: %w", err)
+	}
+
+	appointment, err := s.appointment(ctx, slot, false)
+	if err != nil {
+		return nil, err
+	}
+	if slot.Booked() {
+		s.notify(ctx, *slot.RenterID, viewing.ActionRescheduled, appointment)
+	}
+	return appointment, nil
+}
+
+func (s *service) DeleteSlot(ctx context.Context, id, userID uint) error {
+	slot, err := s.findSlot(ctx, id)
+	if err != nil {
+		return err
+	}
+	if slot.LandlordID != userID {
+		return apperror.ErrNotFound
+	}
+
+	if err = s.repository.DeleteSlot(ctx, id, userID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete viewing slot. error: %w", err)
+	}
+
+	if slot.Booked() && slot.StartsAt.After(time.Now()) {
+		slot.Sequence++
+		appointment, err := s.appointment(ctx, slot, true)
+		if err != nil {
+			s.logger.Errorf("failed to notify renter about deleted slot %d. error: %v", slot.ID, err)
+			return nil
+		}
+		s.notify(ctx, *slot.RenterID, viewing.ActionCancelled, appointment)
+	}
+	return nil
+}
+
+func (s *service) Book(ctx context.Context, dto *viewing.BookSlotDTO) (*viewing.Appointment, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, apperror.BadRequestError(err.Error(), "")
+	}
+	if dto.FromSlotID == dto.SlotID {
+		return nil, apperror.BadRequestError("appointment is already in this slot", "")
+	}
+
+	slot, err := s.repository.Book(ctx, dto.SlotID, dto.RenterID, dto.FromSlotID, time.Now())
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to book viewing slot. error: %w", err)
+	}
+
+	appointment, err := s.appointment(ctx, slot, false)
+	if err != nil {
+		return nil, err
+	}
+
+	action := viewing.ActionBooked
+	if dto.FromSlotID != 0 {
+		action = viewing.ActionRescheduled
+		s.cancelMoved(ctx, dto.FromSlotID, dto.RenterID)
+	}
+	s.notify(ctx, slot.LandlordID, action, appointment)
+	s.notify(ctx, dto.RenterID, action, appointment)
+	return appointment, nil
+}
+
+// cancelMoved sends cancellation of the slot, which appointment was moved from, so calendars drop it.
+func (s *service) cancelMoved(ctx context.Context, slotID, renterID uint) {
+	from, err := s.findSlot(ctx, slotID)
+	if err != nil {
+		s.logger.Errorf("failed to find slot %d of moved appointment. error: %v", slotID, err)
+		return
+	}
+	appointment, err := s.appointment(ctx, from, true)
+	if err != nil {
+		s.logger.Errorf("failed to build cancellation of slot %d. error: %v", slotID, err)
+		return
+	}
+	s.notify(ctx, from.LandlordID, viewing.ActionCancelled, appointment)
+	s.notify(ctx, renterID, viewing.ActionCancelled, appointment)
+}
+
+func (s *service) Cancel(ctx context.Context, slotID, userID uint) (*viewing.Appointment, error) {
+	slot, err := s.findSlot(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	party, ok := slot.PartyOf(userID)
+	if !ok || !slot.Booked() {
+		return nil, apperror.ErrNotFound
+	}
+	renterID := *slot.RenterID
+
+	slot, err = s.repository.Cancel(ctx, slotID, renterID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to cancel viewing appointment. error: %w", err)
+	}
+
+	appointment, err := s.appointment(ctx, slot, true)
+	if err != nil {
+		return nil, err
+	}
+	if party == viewing.PartyRenter {
+		s.notify(ctx, slot.LandlordID, viewing.ActionCancelled, appointment)
+	} else {
+		s.notify(ctx, renterID, viewing.ActionCancelled, appointment)
+	}
+	return appointment, nil
+}
+
+func (s *service) GetAppointment(ctx context.Context, slotID, userID uint) (*viewing.Appointment, error) {
+	slot, err := s.findSlot(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := slot.PartyOf(userID); !ok || !slot.Booked() {
+		return nil, apperror.ErrNotFound
+	}
+	return s.appointment(ctx, slot, false)
+}
+
+func (s *service) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	slots, err := s.repository.FindForReminder(ctx, now, now.Add(s.cfg.RemindBefore))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find appointments to remind of. error: %w", err)
+	}
+
+	for _, slot := range slots {
+		appointment, err := s.appointment(ctx, slot, false)
+		if err != nil {
+			return 0, err
+		}
+		s.notify(ctx, *slot.RenterID, viewing.ActionReminder, appointment)
+		s.notify(ctx, slot.LandlordID, viewing.ActionReminder, appointment)
+
+		if err = s.repository.MarkReminded(ctx, slot.ID, now); err != nil {
+			return 0, fmt.Errorf("failed to mark appointment as reminded. error: %w", err)
+		}
+	}
+	return len(slots), nil
+}
+
+// RunReminders sends reminders about upcoming viewings every interval until ctx is done.
+func RunReminders(ctx context.Context, s Service, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.SendReminders(ctx, time.Now())
+			if err != nil {
+				logger.Errorf("failed to send viewing reminders. error: %v", err)
+			} else if n > 0 {
+				logger.Infof("reminded of %d viewings", n)
+			}
+		}
+	}
+}
+
+// appointment builds calendar invite for the slot. The same UID is used for all versions
+// of the appointment in the slot, cancelled appointments are sent as cancellations.
+func (s *service) appointment(ctx context.Context, slot *viewing.Slot, cancelled bool) (*viewing.Appointment, error) {
+	l, err := s.findLot(ctx, slot.LotID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = ical.EncodeInvite(&buf, prodID, ical.Invite{
+		UID:       fmt.Sprintf("viewing-%d@%s", slot.ID, s.cfg.Domain),
+		Sequence:  slot.Sequence,
+		Summary:   fmt.Sprintf("Просмотр: %s, %d комн., %d м²", l.TypeOfEstate, l.Rooms, l.Area),
+		Location:  fmt.Sprintf("%s, %s, %s, %s", l.City, l.District, l.Street, l.Building),
+		Start:     slot.StartsAt,
+		End:       slot.EndsAt,
+		Cancelled: cancelled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode invite. error: %w", err)
+	}
+	return &viewing.Appointment{Slot: slot, Invite: buf.String()}, nil
+}
+
+func (s *service) notify(ctx context.Context, userID uint, action viewing.Action, appointment *viewing.Appointment) {
+	s.events.Publish(ctx, userID, event.TypeViewing, &viewing.Notice{Action: action, Appointment: *appointment})
+}
+
+func (s *service) findSlot(ctx context.Context, id uint) (*viewing.Slot, error) {
+	slot, err := s.repository.FindSlot(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find viewing slot. error: %w", err)
+	}
+	return slot, nil
+}
+
+func (s *service) findLot(ctx context.Context, id uint) (*lot.Lot, error) {
+	l, err := s.lots.FindByLotID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find lot. error: %w", err)
+	}
+	return l, nil
+}
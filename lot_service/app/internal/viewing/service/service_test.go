@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/lot"
+	lotStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/lot/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/organization"
+	organizationStorage "github.com/levelord1311/backendForSharedProject/lot_service/internal/organization/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing/storage"
+	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
+	"strings"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	storage.Repository
+	slots map[uint]*viewing.Slot
+}
+
+func (r *repo) FindSlot(_ context.Context, id uint) (*viewing.Slot, error) {
+	slot, ok := r.slots[id]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	copied := *slot
+	return &copied, nil
+}
+
+func (r *repo) CreateSlot(_ context.Context, slot *viewing.Slot) (uint, error) {
+	copied := *slot
+	copied.ID = uint(len(r.slots) + 1)
+	r.slots[copied.ID] = &copied
+	return copied.ID, nil
+}
+
+func (r *repo) Book(_ context.Context, slotID, renterID, fromSlotID uint, now time.Time) (*viewing.Slot, error) {
+	slot, ok := r.slots[slotID]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	if slot.Booked() {
+		return nil, apperror.ConflictError("slot is booked already")
+	}
+	if fromSlotID != 0 {
+		from := r.slots[fromSlotID]
+		from.RenterID = nil
+		from.Sequence++
+	}
+	slot.RenterID, slot.BookedAt = &renterID, &now
+	slot.Sequence++
+	copied := *slot
+	return &copied, nil
+}
+
+func (r *repo) Cancel(_ context.Context, slotID, renterID uint) (*viewing.Slot, error) {
+	slot := r.slots[slotID]
+	if !slot.Booked() || *slot.RenterID != renterID {
+		return nil, apperror.ErrNotFound
+	}
+	slot.RenterID, slot.BookedAt = nil, nil
+	slot.Sequence++
+	copied := *slot
+	return &copied, nil
+}
+
+type lots struct {
+	lotStorage.Repository
+}
+
+func (l *lots) FindByLotID(_ context.Context, id uint) (*lot.Lot, error) {
+	organizationID := uint(5)
+	return &lot.Lot{ID: id, CreatedByUserID: 20, OrganizationID: &organizationID, City: "Москва"}, nil
+}
+
+type organizations struct {
+	organizationStorage.Repository
+	roles map[uint]organization.Role
+}
+
+func (o *organizations) FindMember(_ context.Context, organizationID, userID uint) (*organization.Member, error) {
+	role, ok := o.roles[userID]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return &organization.Member{OrganizationID: organizationID, UserID: userID, Role: role}, nil
+}
+
+type notice struct {
+	userID uint
+	notice *viewing.Notice
+}
+
+type publisher struct {
+	notices []notice
+}
+
+func (p *publisher) Publish(_ context.Context, userID uint, _ string, payload any) {
+	p.notices = append(p.notices, notice{userID: userID, notice: payload.(*viewing.Notice)})
+}
+
+func newRepo() *repo {
+	start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Minute)
+	return &repo{slots: map[uint]*viewing.Slot{
+		1: {ID: 1, LotID: 2, LandlordID: 20, StartsAt: start, EndsAt: start.Add(30 * time.Minute)},
+		2: {ID: 2, LotID: 2, LandlordID: 20, StartsAt: start.Add(time.Hour), EndsAt: start.Add(90 * time.Minute)},
+	}}
+}
+
+func TestBook(t *testing.T) {
+	r, p := newRepo(), &publisher{}
+	s, _ := NewService(r, &lots{}, nil, p, Config{Domain: "example.com"}, logging.GetLogger())
+	ctx := context.Background()
+
+	appointment, err := s.Book(ctx, &viewing.BookSlotDTO{SlotID: 1, RenterID: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(appointment.Invite, "UID:viewing-1@example.com") {
+		t.Errorf("invite must have UID of the slot, got\n%s", appointment.Invite)
+	}
+	if len(p.notices) != 2 || p.notices[0].userID != 20 || p.notices[1].userID != 10 {
+		t.Fatalf("both parties must be notified, got %v", p.notices)
+	}
+
+	if _, err = s.Book(ctx, &viewing.BookSlotDTO{SlotID: 1, RenterID: 11}); !sameError(err, apperror.ConflictError("")) {
+		t.Errorf("expected conflict for booked slot, got %v", err)
+	}
+
+	p.notices = nil
+	if _, err = s.Book(ctx, &viewing.BookSlotDTO{SlotID: 2, RenterID: 10, FromSlotID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	var cancelled, rescheduled int
+	for _, n := range p.notices {
+		switch n.notice.Action {
+		case viewing.ActionCancelled:
+			cancelled++
+			if !strings.Contains(n.notice.Invite, "METHOD:CANCEL") || n.notice.Slot.ID != 1 {
+				t.Errorf("old slot must be cancelled in calendars, got\n%s", n.notice.Invite)
+			}
+		case viewing.ActionRescheduled:
+			rescheduled++
+		}
+	}
+	if cancelled != 2 || rescheduled != 2 {
+		t.Errorf("expected cancellation and new appointment for both parties, got %v", p.notices)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	r, p := newRepo(), &publisher{}
+	renterID := uint(10)
+	r.slots[1].RenterID = &renterID
+	s, _ := NewService(r, &lots{}, nil, p, Config{}, logging.GetLogger())
+	ctx := context.Background()
+
+	if _, err := s.Cancel(ctx, 1, 11); !errors.Is(err, apperror.ErrNotFound) {
+		t.Errorf("expected not found for stranger, got %v", err)
+	}
+
+	if _, err := s.Cancel(ctx, 1, 20); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.notices) != 1 || p.notices[0].userID != renterID {
+		t.Fatalf("renter must be notified, got %v", p.notices)
+	}
+	if r.slots[1].Booked() {
+		t.Error("slot must be free after cancellation")
+	}
+}
+
+func TestCreateSlot(t *testing.T) {
+	members := &organizations{roles: map[uint]organization.Role{
+		21: organization.RoleAgent,
+		22: organization.RoleManager,
+	}}
+	r := newRepo()
+	s, _ := NewService(r, &lots{}, members, &publisher{}, Config{}, logging.GetLogger())
+	ctx := context.Background()
+	start := time.Now().Add(time.Hour)
+	dto := &viewing.CreateSlotDTO{
+		LotID:      2,
+		LandlordID: 21,
+		StartsAt:   start.Format(time.RFC3339),
+		EndsAt:     start.Add(time.Hour).Format(time.RFC3339),
+	}
+
+	if _, err := s.CreateSlot(ctx, dto); !sameError(err, apperror.ForbiddenError("")) {
+		t.Errorf("expected forbidden error for agent of other lot, got %v", err)
+	}
+
+	dto.LandlordID = 22
+	slot, err := s.CreateSlot(ctx, dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot.LandlordID != 22 {
+		t.Errorf("manager publishing the slot must show the lot, got landlord %d", slot.LandlordID)
+	}
+}
+
+// sameError compares app errors by code, since their messages differ.
+func sameError(err, want error) bool {
+	var got, expected *apperror.AppError
+	if !errors.As(err, &got) || !errors.As(want, &expected) {
+		return false
+	}
+	return got.Code == expected.Code
+}
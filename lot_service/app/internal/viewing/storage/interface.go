@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/lot_service/internal/viewing"
+	"time"
+)
+
+type Repository interface {
+	// CreateSlot saves the slot, if it doesn't overlap with other slots of the landlord.
+	CreateSlot(ctx context.Context, s *viewing.Slot) (uint, error)
+	FindSlot(ctx context.Context, id uint) (*viewing.Slot, error)
+	// FindByLot returns slots of the lot starting after from, soonest first.
+	FindByLot(ctx context.Context, lotID uint, from time.Time, onlyFree bool) ([]*viewing.Slot, error)
+	// FindAppointments returns booked slots of the user starting after from, soonest first.
+	FindAppointments(ctx context.Context, userID uint, party viewing.Party, from time.Time) ([]*viewing.Slot, error)
+	// UpdateTime moves the slot, if it doesn't overlap with other slots of the landlord
+	// and with other appointments of its renter.
+	UpdateTime(ctx context.Context, s *viewing.Slot) (*viewing.Slot, error)
+	DeleteSlot(ctx context.Context, id, landlordID uint) error
+
+	// Book gives free slot, which has not started yet, to the renter. Renter can have only one appointment
+	// per lot and can't have overlapping appointments. Appointment is moved from fromSlotID, if it's not zero.
+	Book(ctx context.Context, slotID, renterID, fromSlotID uint, now time.Time) (*viewing.Slot, error)
+	// Cancel frees the slot booked by the renter.
+	Cancel(ctx context.Context, slotID, renterID uint) (*viewing.Slot, error)
+
+	// FindForReminder returns appointments starting between now and before, which renters weren't reminded of.
+	FindForReminder(ctx context.Context, now, before time.Time) ([]*viewing.Slot, error)
+	MarkReminded(ctx context.Context, slotID uint, at time.Time) error
+}
@@ -0,0 +1,194 @@
+// Package currency loads exchange rates and converts prices between currencies. Rates of external services
+// are plugged in by the Source interface, file and HTTP sources read rates in the same JSON format, so a local
+// stub serving a file can stand in for a real service.
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// codeFormat is ISO 4217 alphabetic code.
+var codeFormat = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidCode tells whether the code is in ISO 4217 format, e.g. RUB.
+func ValidCode(code string) bool {
+	return codeFormat.MatchString(code)
+}
+
+// Rates are values of units of currencies in the base currency, e.g. {"USD": 92.5} for base RUB.
+// Rate of the base currency is 1.
+type Rates struct {
+	Base      string             `json:"base"`
+	Values    map[string]float64 `json:"rates"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// NewRates returns rates of the base currency only.
+func NewRates(base string) *Rates {
+	return &Rates{Base: base, Values: map[string]float64{base: 1}}
+}
+
+// Validate checks codes and values of rates and adds the base currency to them.
+func (r *Rates) Validate() error {
+	if !ValidCode(r.Base) {
+		return fmt.Errorf("invalid base currency %q", r.Base)
+	}
+	if r.Values == nil {
+		r.Values = make(map[string]float64)
+	}
+	for code, v := range r.Values {
+		if !ValidCode(code) {
+			return fmt.Errorf("invalid currency %q", code)
+		}
+		if v <= 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+			return fmt.Errorf("rate of %s must be positive", code)
+		}
+	}
+	r.Values[r.Base] = 1
+	return nil
+}
+
+// Rebase returns rates with values in another currency of the rates.
+func (r *Rates) Rebase(base string) (*Rates, error) {
+	rate, ok := r.Values[base]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownCurrency, base)
+	}
+	rebased := &Rates{Base: base, Values: make(map[string]float64, len(r.Values)), UpdatedAt: r.UpdatedAt}
+	for code, v := range r.Values {
+		rebased.Values[code] = v / rate
+	}
+	rebased.Values[base] = 1
+	return rebased, nil
+}
+
+func (r *Rates) Known(code string) bool {
+	_, ok := r.Values[code]
+	return ok
+}
+
+// Codes returns codes of known currencies.
+func (r *Rates) Codes() []string {
+	codes := make([]string, 0, len(r.Values))
+	for code := range r.Values {
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// Convert returns amount in currency from converted to currency to.
+func (r *Rates) Convert(amount float64, from, to string) (float64, error) {
+	fromRate, ok := r.Values[from]
+	if !ok {
+		return 0, fmt.Errorf("%w %s", ErrUnknownCurrency, from)
+	}
+	toRate, ok := r.Values[to]
+	if !ok {
+		return 0, fmt.Errorf("%w %s", ErrUnknownCurrency, to)
+	}
+	return amount * fromRate / toRate, nil
+}
+
+type Source interface {
+	// Rates returns the latest rates.
+	Rates(ctx context.Context) (*Rates, error)
+}
+
+const (
+	TypeNone = "none"
+	TypeFile = "file"
+	TypeHTTP = "http"
+)
+
+// New returns source of given type, it's nil for type none. Location is path of file or URL.
+func New(sourceType, location string, timeout time.Duration) (Source, error) {
+	switch sourceType {
+	case TypeNone, "":
+		return nil, nil
+	case TypeFile:
+		return NewFileSource(location), nil
+	case TypeHTTP:
+		return NewHTTPSource(location, &http.Client{Timeout: timeout}), nil
+	default:
+		return nil, fmt.Errorf("unknown source of rates %q", sourceType)
+	}
+}
+
+type fileSource struct {
+	path string
+}
+
+// NewFileSource reads rates from JSON file, e.g. {"base": "RUB", "rates": {"USD": 92.5, "EUR": 100.1}}.
+// The file is read on every call, so rates can be changed without restart.
+func NewFileSource(path string) *fileSource {
+	return &fileSource{path: path}
+}
+
+func (s *fileSource) Rates(_ context.Context) (*Rates, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file of rates. error: %w", err)
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file of rates. error: %w", err)
+	}
+	return parse(data, info.ModTime())
+}
+
+type httpSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSource gets rates in JSON format of file source from the URL.
+func NewHTTPSource(url string, client *http.Client) *httpSource {
+	return &httpSource{url: url, client: client}
+}
+
+func (s *httpSource) Rates(ctx context.Context) (*Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rates. error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get rates. status: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates. error: %w", err)
+	}
+	return parse(data, time.Now())
+}
+
+// parse reads rates, time of update defaults to the given one.
+func parse(data []byte, updatedAt time.Time) (*Rates, error) {
+	r := &Rates{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse rates. error: %w", err)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if r.UpdatedAt.IsZero() {
+		r.UpdatedAt = updatedAt
+	}
+	r.UpdatedAt = r.UpdatedAt.UTC().Truncate(time.Second)
+	return r, nil
+}
@@ -0,0 +1,88 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const ratesJSON = `{"base": "RUB", "rates": {"USD": 90, "EUR": 100}}`
+
+func TestSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(path, []byte(ratesJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(ratesJSON))
+	}))
+	defer stub.Close()
+
+	for _, tc := range []struct {
+		sourceType string
+		location   string
+	}{
+		{TypeFile, path},
+		{TypeHTTP, stub.URL},
+	} {
+		s, err := New(tc.sourceType, tc.location, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := s.Rates(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tc.sourceType, err)
+		}
+		if r.Base != "RUB" || r.Values["RUB"] != 1 || r.Values["USD"] != 90 || r.UpdatedAt.IsZero() {
+			t.Errorf("%s: rates are %+v", tc.sourceType, r)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	r := &Rates{Base: "RUB", Values: map[string]float64{"USD": 90, "EUR": 100}}
+	if err := r.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		amount   float64
+		from, to string
+		want     float64
+	}{
+		{100, "USD", "RUB", 9000},
+		{9000, "RUB", "USD", 100},
+		{90, "EUR", "USD", 100},
+		{5, "RUB", "RUB", 5},
+	} {
+		got, err := r.Convert(tc.amount, tc.from, tc.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%v %s in %s is %v, want %v", tc.amount, tc.from, tc.to, got, tc.want)
+		}
+	}
+	if _, err := r.Convert(1, "GBP", "RUB"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("conversion of unknown currency returns %v", err)
+	}
+
+	usd, err := r.Rebase("USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(usd.Values["RUB"]-1.0/90) > 1e-12 || usd.Values["USD"] != 1 {
+		t.Errorf("rates rebased to USD are %v", usd.Values)
+	}
+
+	invalid := &Rates{Base: "RUB", Values: map[string]float64{"usd": 90}}
+	if err = invalid.Validate(); err == nil {
+		t.Errorf("invalid code is accepted")
+	}
+}
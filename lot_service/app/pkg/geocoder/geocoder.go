@@ -0,0 +1,89 @@
+// Package geocoder resolves postal addresses to their canonical parts and coordinates. Geocoders of external
+// services are plugged in by the Geocoder interface, file geocoder fakes them for local development and tests.
+package geocoder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrNotFound = errors.New("address not found")
+
+// Result is canonical address. District is empty, if the geocoder doesn't know it.
+type Result struct {
+	City      string  `json:"city"`
+	District  string  `json:"district"`
+	Street    string  `json:"street"`
+	Building  string  `json:"building"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type Geocoder interface {
+	// Geocode returns canonical address of free text address or ErrNotFound.
+	Geocode(ctx context.Context, address string) (*Result, error)
+}
+
+const (
+	TypeNone = "none"
+	TypeFile = "file"
+)
+
+// New returns geocoder of given type, it's nil for type none.
+func New(geocoderType, file string) (Geocoder, error) {
+	switch geocoderType {
+	case TypeNone, "":
+		return nil, nil
+	case TypeFile:
+		return NewFileGeocoder(file)
+	default:
+		return nil, fmt.Errorf("unknown geocoder type %q", geocoderType)
+	}
+}
+
+type fileGeocoder struct {
+	results map[string]*Result
+}
+
+// NewFileGeocoder reads JSON object of results by addresses, e.g.
+// {"Москва, Тверская, 1": {"city": "Москва", "district": "Тверской", ...}}. Addresses are looked up
+// ignoring case, punctuation and extra spaces.
+func NewFileGeocoder(path string) (*fileGeocoder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read geocoder file. error: %w", err)
+	}
+	results := make(map[string]*Result)
+	if err = json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse geocoder file. error: %w", err)
+	}
+	return NewStaticGeocoder(results), nil
+}
+
+// NewStaticGeocoder returns the results by addresses.
+func NewStaticGeocoder(results map[string]*Result) *fileGeocoder {
+	g := &fileGeocoder{results: make(map[string]*Result, len(results))}
+	for address, r := range results {
+		g.results[key(address)] = r
+	}
+	return g
+}
+
+func (g *fileGeocoder) Geocode(_ context.Context, address string) (*Result, error) {
+	r, ok := g.results[key(address)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *r
+	return &copied, nil
+}
+
+func key(address string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(address), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '.'
+	}), " ")
+}
@@ -0,0 +1,248 @@
+// Package ical reads and writes all-day events of iCalendar (RFC 5545) feeds,
+// which are used by rental platforms to share availability, and writes invites to timed meetings.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	maxLineLength  = 75
+)
+
+// Event is a period from Start (inclusive) to End (exclusive) day.
+type Event struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// Invite is a meeting sent to its attendees. Sequence must grow with every change of the meeting,
+// so calendars replace its previous version with the same UID.
+type Invite struct {
+	UID         string
+	Sequence    int
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	Cancelled   bool
+}
+
+var ErrNoCalendar = errors.New("no VCALENDAR in data")
+
+// Encode writes events as iCalendar with all-day events.
+func Encode(w io.Writer, prodID string, events []Event) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format(dateTimeLayout) + "Z"
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + escape(prodID),
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	for _, e := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escape(e.UID),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+e.Start.Format(dateLayout),
+			"DTEND;VALUE=DATE:"+e.End.Format(dateLayout),
+			"SUMMARY:"+escape(e.Summary),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	return writeLines(bw, lines)
+}
+
+// EncodeInvite writes the invite as iCalendar request, or as cancellation of the meeting.
+func EncodeInvite(w io.Writer, prodID string, invite Invite) error {
+	method, status := "REQUEST", "CONFIRMED"
+	if invite.Cancelled {
+		method, status = "CANCEL", "CANCELLED"
+	}
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + escape(prodID),
+		"CALSCALE:GREGORIAN",
+		"METHOD:" + method,
+		"BEGIN:VEVENT",
+		"UID:" + escape(invite.UID),
+		fmt.Sprintf("SEQUENCE:%d", invite.Sequence),
+		"DTSTAMP:" + time.Now().UTC().Format(dateTimeLayout) + "Z",
+		"DTSTART:" + invite.Start.UTC().Format(dateTimeLayout) + "Z",
+		"DTEND:" + invite.End.UTC().Format(dateTimeLayout) + "Z",
+		"SUMMARY:" + escape(invite.Summary),
+	}
+	if invite.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escape(invite.Description))
+	}
+	if invite.Location != "" {
+		lines = append(lines, "LOCATION:"+escape(invite.Location))
+	}
+	lines = append(lines,
+		"STATUS:"+status,
+		"END:VEVENT",
+		"END:VCALENDAR",
+	)
+
+	return writeLines(bufio.NewWriter(w), lines)
+}
+
+func writeLines(bw *bufio.Writer, lines []string) error {
+	for _, l := range lines {
+		if _, err := bw.WriteString(fold(l)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Decode reads events from iCalendar. Times of events are truncated to dates, cancelled events are skipped.
+func Decode(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var e *Event
+	var cancelled, hasEnd, inCalendar bool
+	for n, line := range lines {
+		name, params, value, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VCALENDAR":
+			inCalendar = true
+		case name == "BEGIN" && value == "VEVENT":
+			e = &Event{}
+			cancelled, hasEnd = false, false
+		case name == "END" && value == "VEVENT" && e != nil:
+			if e.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", n+1, e.UID)
+			}
+			if !hasEnd || !e.End.After(e.Start) {
+				e.End = e.Start.AddDate(0, 0, 1)
+			}
+			if !cancelled {
+				events = append(events, *e)
+			}
+			e = nil
+		case e == nil:
+			continue
+		case name == "UID":
+			e.UID = unescape(value)
+		case name == "SUMMARY":
+			e.Summary = unescape(value)
+		case name == "STATUS":
+			cancelled = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTART":
+			if e.Start, err = parseDate(params, value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+		case name == "DTEND":
+			if e.End, err = parseDate(params, value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			hasEnd = true
+		}
+	}
+
+	if !inCalendar {
+		return nil, ErrNoCalendar
+	}
+	return events, nil
+}
+
+// unfold joins continuation lines, which start with space or tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseLine splits content line "NAME;PARAM=VALUE:value" into parts.
+func parseLine(line string) (name string, params map[string]string, value string, ok bool) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params = make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		if k, v, found := strings.Cut(p, "="); found {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// parseDate parses DATE or DATE-TIME value and returns its date in UTC.
+// Local date of DATE-TIME with TZID is used as is.
+func parseDate(params map[string]string, value string) (time.Time, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		return time.Parse(dateLayout, value)
+	}
+
+	t, err := time.Parse(dateTimeLayout, strings.TrimSuffix(value, "Z"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// fold splits line into parts not longer than 75 octets, as required by RFC 5545, and terminates it with CRLF.
+func fold(line string) string {
+	var b strings.Builder
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		// don't split multibyte characters
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation line starts with space
+		limit = maxLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
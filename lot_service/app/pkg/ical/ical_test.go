@@ -0,0 +1,134 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestEncodeDecode(t *testing.T) {
+	events := []Event{
+		{UID: "booking-1@lots", Summary: "Booked", Start: date("2026-11-01"), End: date("2026-11-07")},
+		{UID: "block-2@lots", Summary: "Ремонт; окна, двери " + strings.Repeat("очень долго ", 10),
+			Start: date("2026-12-30"), End: date("2027-01-02")},
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, "-//lots//calendar//EN", events); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("line is not folded: %q", line)
+		}
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(events) {
+		t.Fatalf("got %d events, want %d", len(decoded), len(events))
+	}
+	for i := range events {
+		if decoded[i] != events[i] {
+			t.Errorf("event %d: got %+v, want %+v", i, decoded[i], events[i])
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:no-end\r\n" +
+		"DTSTART;VALUE=DATE:20261101\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:date-time\r\n" +
+		"DTSTART;TZID=Europe/Moscow:20261105T140000\r\n" +
+		"DTEND:20261108T090000Z\r\n" +
+		"SUMMARY:Reserved by \r\n" +
+		" other platform\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:cancelled\r\n" +
+		"DTSTART;VALUE=DATE:20261110\r\n" +
+		"DTEND;VALUE=DATE:20261112\r\n" +
+		"STATUS:CANCELLED\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := Decode(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Event{
+		{UID: "no-end", Start: date("2026-11-01"), End: date("2026-11-02")},
+		{UID: "date-time", Summary: "Reserved by other platform", Start: date("2026-11-05"), End: date("2026-11-08")},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d: got %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestDecode_NoCalendar(t *testing.T) {
+	if _, err := Decode(strings.NewReader("<html></html>")); err != ErrNoCalendar {
+		t.Errorf("got %v, want %v", err, ErrNoCalendar)
+	}
+}
+
+func TestEncodeInvite(t *testing.T) {
+	invite := Invite{
+		UID:      "viewing-3@lots",
+		Sequence: 2,
+		Summary:  "Просмотр квартиры",
+		Location: "Москва, Тверская, 1",
+		Start:    time.Date(2026, 11, 1, 18, 30, 0, 0, time.FixedZone("MSK", 3*60*60)),
+		End:      time.Date(2026, 11, 1, 19, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeInvite(&buf, "-//lots//viewings//EN", invite); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"METHOD:REQUEST", "SEQUENCE:2", "DTSTART:20261101T153000Z",
+		"DTEND:20261101T160000Z", "LOCATION:Москва\\, Тверская\\, 1", "STATUS:CONFIRMED"} {
+		if !strings.Contains(buf.String(), want+"\r\n") {
+			t.Errorf("invite has no %q:\n%s", want, buf.String())
+		}
+	}
+
+	invite.Cancelled = true
+	buf.Reset()
+	if err := EncodeInvite(&buf, "-//lots//viewings//EN", invite); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "METHOD:CANCEL\r\n") || !strings.Contains(buf.String(), "STATUS:CANCELLED\r\n") {
+		t.Errorf("cancelled invite must cancel the meeting:\n%s", buf.String())
+	}
+
+	events, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Errorf("cancelled meeting must not be decoded as busy period, got %+v", events)
+	}
+}
@@ -0,0 +1,117 @@
+// Package media stores uploaded files, e.g. attachments of messages.
+package media
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var ErrNotFound = errors.New("media not found")
+
+// Storage keeps files by keys generated on save.
+type Storage interface {
+	// Save writes data and returns key of the file.
+	Save(ctx context.Context, data io.Reader) (string, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+const TypeLocal = "local"
+
+// NewStorage returns storage of given type. Only local storage is supported for now.
+func NewStorage(storageType, dir string) (Storage, error) {
+	switch storageType {
+	case TypeLocal, "":
+		return NewLocalStorage(dir)
+	default:
+		return nil, fmt.Errorf("unknown media storage type %q", storageType)
+	}
+}
+
+type localStorage struct {
+	dir string
+}
+
+// NewLocalStorage keeps files in the dir, which is created if needed.
+func NewLocalStorage(dir string) (*localStorage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create media dir. error: %w", err)
+	}
+	return &localStorage{dir: dir}, nil
+}
+
+func (s *localStorage) Save(_ context.Context, data io.Reader) (string, error) {
+	key, err := newKey()
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(s.path(key), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(f, data); err != nil {
+		f.Close()
+		os.Remove(s.path(key))
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(s.path(key))
+		return "", err
+	}
+	return key, nil
+}
+
+func (s *localStorage) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, ErrNotFound
+	}
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *localStorage) Delete(_ context.Context, key string) error {
+	if !validKey(key) {
+		return ErrNotFound
+	}
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *localStorage) path(key string) string {
+	return filepath.Join(s.dir, key)
+}
+
+const keyLength = 16
+
+func newKey() (string, error) {
+	b := make([]byte, keyLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validKey protects from keys pointing outside of the storage.
+func validKey(key string) bool {
+	if len(key) != keyLength*2 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
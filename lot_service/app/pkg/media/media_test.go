@@ -0,0 +1,42 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := s.Save(ctx, strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := s.Open(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("got %q, %v", data, err)
+	}
+
+	if err = s.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if _, err = s.Open(ctx, "../../etc/passwd"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for invalid key, got %v", err)
+	}
+}
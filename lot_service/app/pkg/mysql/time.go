@@ -0,0 +1,21 @@
+package mysql
+
+import "time"
+
+const (
+	timeLayout = "2006-01-02 15:04:05"
+	dateLayout = "2006-01-02"
+)
+
+// RawTime scans DATETIME and DATE columns, which are returned as text without parseTime option of DSN.
+type RawTime []byte
+
+// Time parses DATETIME value.
+func (t *RawTime) Time() (time.Time, error) {
+	return time.Parse(timeLayout, string(*t))
+}
+
+// Date parses DATE value.
+func (t *RawTime) Date() (time.Time, error) {
+	return time.Parse(dateLayout, string(*t))
+}
@@ -0,0 +1,168 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// SignatureHeader carries hex HMAC-SHA256 of webhook body signed with the secret of the mock provider.
+const SignatureHeader = "X-Mock-Signature"
+
+type mockIntent struct {
+	amount   int64
+	paid     bool
+	refunded int64
+}
+
+// mockProvider keeps intents in memory. Checkout is simulated, payers never enter card data.
+type mockProvider struct {
+	secret      []byte
+	checkoutURL string
+
+	mu      sync.Mutex
+	intents map[string]*mockIntent
+	keys    map[string]string // idempotency key to ID of intent or refund
+}
+
+func NewMockProvider(secret, checkoutURL string) (*mockProvider, error) {
+	if secret == "" {
+		return nil, errors.New("secret of mock payment provider is required")
+	}
+	return &mockProvider{
+		secret:      []byte(secret),
+		checkoutURL: strings.TrimSuffix(checkoutURL, "/"),
+		intents:     make(map[string]*mockIntent),
+		keys:        make(map[string]string),
+	}, nil
+}
+
+func (p *mockProvider) Name() string {
+	return TypeMock
+}
+
+func (p *mockProvider) CreateIntent(_ context.Context, req *IntentRequest) (*Intent, error) {
+	if req.Amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id, ok := p.keys[req.IdempotencyKey]
+	if !ok || req.IdempotencyKey == "" {
+		var err error
+		if id, err = newID("pi"); err != nil {
+			return nil, err
+		}
+		p.intents[id] = &mockIntent{amount: req.Amount}
+		if req.IdempotencyKey != "" {
+			p.keys[req.IdempotencyKey] = id
+		}
+	}
+	return &Intent{ID: id, CheckoutURL: p.checkoutURL + "/" + id}, nil
+}
+
+func (p *mockProvider) Refund(_ context.Context, intentID string, amount int64, idempotencyKey string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if id, ok := p.keys[idempotencyKey]; ok && idempotencyKey != "" {
+		return id, nil
+	}
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return "", fmt.Errorf("intent %s not found", intentID)
+	}
+	if !intent.paid {
+		return "", fmt.Errorf("intent %s is not paid", intentID)
+	}
+	if amount <= 0 || intent.refunded+amount > intent.amount {
+		return "", fmt.Errorf("refund of %d exceeds paid amount of intent %s", amount, intentID)
+	}
+
+	id, err := newID("re")
+	if err != nil {
+		return "", err
+	}
+	intent.refunded += amount
+	if idempotencyKey != "" {
+		p.keys[idempotencyKey] = id
+	}
+	return id, nil
+}
+
+type mockWebhook struct {
+	ID       string    `json:"id"`
+	Type     EventType `json:"type"`
+	IntentID string    `json:"intent_id"`
+}
+
+func (p *mockProvider) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	signature, err := hex.DecodeString(header.Get(SignatureHeader))
+	if err != nil || !hmac.Equal(signature, p.sign(body)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var webhook mockWebhook
+	if err = json.Unmarshal(body, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook. error: %w", err)
+	}
+	if webhook.ID == "" || webhook.IntentID == "" {
+		return nil, errors.New("webhook has no id or intent_id")
+	}
+	return &Event{ID: webhook.ID, Type: webhook.Type, IntentID: webhook.IntentID}, nil
+}
+
+// Simulate finishes checkout of the intent and returns signed webhook about it.
+func (p *mockProvider) Simulate(intentID string, eventType EventType) (http.Header, []byte, error) {
+	if eventType != EventSucceeded && eventType != EventFailed {
+		return nil, nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+
+	p.mu.Lock()
+	intent, ok := p.intents[intentID]
+	if ok && eventType == EventSucceeded {
+		intent.paid = true
+	}
+	p.mu.Unlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("intent %s not found", intentID)
+	}
+
+	id, err := newID("evt")
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := json.Marshal(mockWebhook{ID: id, Type: eventType, IntentID: intentID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(SignatureHeader, hex.EncodeToString(p.sign(body)))
+	return header, body, nil
+}
+
+func (p *mockProvider) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func newID(prefix string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + "_" + hex.EncodeToString(b), nil
+}
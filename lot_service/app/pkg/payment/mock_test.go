@@ -0,0 +1,57 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMockProvider(t *testing.T) {
+	ctx := context.Background()
+	p, err := NewMockProvider("secret", "https://pay.local/checkout/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intent, err := p.CreateIntent(ctx, &IntentRequest{Amount: 100, Currency: "RUB", IdempotencyKey: "payment-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if intent.CheckoutURL != "https://pay.local/checkout/"+intent.ID {
+		t.Errorf("unexpected checkout url %s", intent.CheckoutURL)
+	}
+	again, err := p.CreateIntent(ctx, &IntentRequest{Amount: 100, Currency: "RUB", IdempotencyKey: "payment-1"})
+	if err != nil || again.ID != intent.ID {
+		t.Errorf("expected the same intent for the same key, got %v, %v", again, err)
+	}
+
+	if _, err = p.Refund(ctx, intent.ID, 50, "refund-1"); err == nil {
+		t.Error("unpaid intent must not be refunded")
+	}
+
+	header, body, err := p.Simulate(intent.ID, EventSucceeded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := p.ParseWebhook(header, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != EventSucceeded || e.IntentID != intent.ID || e.ID == "" {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if _, err = p.ParseWebhook(header, append(body, ' ')); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected invalid signature for changed body, got %v", err)
+	}
+
+	first, err := p.Refund(ctx, intent.ID, 60, "refund-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := p.Refund(ctx, intent.ID, 60, "refund-1"); second != first {
+		t.Error("expected the same refund for the same key")
+	}
+	if _, err = p.Refund(ctx, intent.ID, 60, "refund-2"); err == nil {
+		t.Error("refunds must not exceed paid amount")
+	}
+}
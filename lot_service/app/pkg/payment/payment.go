@@ -0,0 +1,78 @@
+// Package payment talks to payment providers. Card data is entered on checkout pages of the provider,
+// the service keeps only IDs of the provider's objects and never sees cards.
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature of webhook")
+
+type EventType string
+
+const (
+	EventSucceeded EventType = "succeeded" // the intent was paid
+	EventFailed    EventType = "failed"    // the payer failed to pay or abandoned checkout
+)
+
+// IntentRequest asks the provider to collect amount from the payer.
+type IntentRequest struct {
+	Amount      int64 // in minor units of the currency
+	Currency    string
+	Description string
+	// IdempotencyKey makes repeated requests return the same intent.
+	IdempotencyKey string
+}
+
+// Intent is a payment waiting for the payer on the checkout page.
+type Intent struct {
+	ID          string
+	CheckoutURL string
+}
+
+// Event is a change of the intent reported by the provider with webhook.
+// Providers may deliver an event several times, its ID is unique.
+type Event struct {
+	ID       string
+	Type     EventType
+	IntentID string
+}
+
+type Provider interface {
+	Name() string
+	CreateIntent(ctx context.Context, req *IntentRequest) (*Intent, error)
+	// Refund returns amount of paid intent to the payer and returns ID of the refund.
+	// Repeated requests with the same idempotency key make a single refund.
+	Refund(ctx context.Context, intentID string, amount int64, idempotencyKey string) (string, error)
+	// ParseWebhook checks signature of the webhook and returns its event.
+	ParseWebhook(header http.Header, body []byte) (*Event, error)
+}
+
+// Simulator is implemented by test providers, which have no checkout pages. It makes signed webhook
+// as if the payer finished checkout.
+type Simulator interface {
+	Simulate(intentID string, eventType EventType) (http.Header, []byte, error)
+}
+
+const TypeMock = "mock"
+
+type Config struct {
+	Type string
+	// Secret signs webhooks
+	Secret string
+	// CheckoutURL is base URL of checkout pages of the mock provider
+	CheckoutURL string
+}
+
+// NewProvider returns provider of given type. Only mock provider is supported for now.
+func NewProvider(cfg Config) (Provider, error) {
+	switch cfg.Type {
+	case TypeMock, "":
+		return NewMockProvider(cfg.Secret, cfg.CheckoutURL)
+	default:
+		return nil, fmt.Errorf("unknown payment provider type %q", cfg.Type)
+	}
+}
@@ -0,0 +1,186 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrInvalidFont = errors.New("invalid TrueType font")
+
+// Font is a TrueType font, which is embedded into documents as a whole.
+// Only glyphs of the Basic Multilingual Plane are mapped.
+type Font struct {
+	data       []byte
+	unitsPerEm int
+	ascent     int
+	descent    int
+	bbox       [4]int
+	advances   []int // by glyph ID, in font units
+	glyphs     map[rune]uint16
+}
+
+// LoadFont reads TrueType font from file.
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFont(data)
+}
+
+// ParseFont parses tables of TrueType font needed to lay out text.
+func ParseFont(data []byte) (*Font, error) {
+	tables, err := readTables(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "cmap"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("%w: no %s table", ErrInvalidFont, tag)
+		}
+	}
+
+	head, hhea := tables["head"], tables["hhea"]
+	if len(head) < 54 || len(hhea) < 36 {
+		return nil, ErrInvalidFont
+	}
+	f := &Font{
+		data:       data,
+		unitsPerEm: int(binary.BigEndian.Uint16(head[18:])),
+		ascent:     int(int16(binary.BigEndian.Uint16(hhea[4:]))),
+		descent:    int(int16(binary.BigEndian.Uint16(hhea[6:]))),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, fmt.Errorf("%w: zero units per em", ErrInvalidFont)
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+
+	metrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	if metrics == 0 || len(hmtx) < 4*metrics {
+		return nil, fmt.Errorf("%w: short hmtx table", ErrInvalidFont)
+	}
+	f.advances = make([]int, metrics)
+	for i := range f.advances {
+		f.advances[i] = int(binary.BigEndian.Uint16(hmtx[4*i:]))
+	}
+
+	if f.glyphs, err = readCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func readTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, ErrInvalidFont
+	}
+	if v := binary.BigEndian.Uint32(data); v != 0x00010000 && v != 0x74727565 { // 1.0 or 'true'
+		return nil, fmt.Errorf("%w: unsupported version %#x", ErrInvalidFont, v)
+	}
+
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*n {
+		return nil, ErrInvalidFont
+	}
+	tables := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		record := data[12+16*i:]
+		offset, length := binary.BigEndian.Uint32(record[8:]), binary.BigEndian.Uint32(record[12:])
+		if uint64(offset)+uint64(length) > uint64(len(data)) {
+			return nil, fmt.Errorf("%w: table out of file", ErrInvalidFont)
+		}
+		tables[string(record[:4])] = data[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// readCmap reads Unicode BMP subtable of format 4.
+func readCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, ErrInvalidFont
+	}
+	n := int(binary.BigEndian.Uint16(cmap[2:]))
+	if len(cmap) < 4+8*n {
+		return nil, ErrInvalidFont
+	}
+
+	for i := 0; i < n; i++ {
+		record := cmap[4+8*i:]
+		platform, encoding := binary.BigEndian.Uint16(record), binary.BigEndian.Uint16(record[2:])
+		offset := binary.BigEndian.Uint32(record[4:])
+		isUnicode := platform == 0 || platform == 3 && encoding == 1
+		if !isUnicode || uint64(offset)+8 > uint64(len(cmap)) {
+			continue
+		}
+		if table := cmap[offset:]; binary.BigEndian.Uint16(table) == 4 {
+			return readFormat4(table)
+		}
+	}
+	return nil, fmt.Errorf("%w: no unicode cmap of format 4", ErrInvalidFont)
+}
+
+func readFormat4(table []byte) (map[rune]uint16, error) {
+	segments := int(binary.BigEndian.Uint16(table[6:])) / 2
+	if len(table) < 16+8*segments {
+		return nil, ErrInvalidFont
+	}
+	u16 := func(pos int) int {
+		if pos+2 > len(table) {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(table[pos:]))
+	}
+	ends, starts := 14, 16+2*segments
+	deltas, ranges := starts+2*segments, starts+4*segments
+
+	glyphs := make(map[rune]uint16)
+	for i := 0; i < segments; i++ {
+		start, end := u16(starts+2*i), u16(ends+2*i)
+		delta, rangeOffset := u16(deltas+2*i), u16(ranges+2*i)
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var glyph int
+			if rangeOffset == 0 {
+				glyph = (c + delta) & 0xFFFF
+			} else if glyph = u16(ranges + 2*i + rangeOffset + 2*(c-start)); glyph != 0 {
+				glyph = (glyph + delta) & 0xFFFF
+			}
+			if glyph != 0 {
+				glyphs[rune(c)] = uint16(glyph)
+			}
+		}
+	}
+	return glyphs, nil
+}
+
+// glyph returns ID of glyph of the rune, zero is the missing glyph.
+func (f *Font) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+// advance returns width of the glyph in thousandths of em.
+func (f *Font) advance(glyph uint16) int {
+	i := int(glyph)
+	if i >= len(f.advances) {
+		i = len(f.advances) - 1
+	}
+	return f.advances[i] * 1000 / f.unitsPerEm
+}
+
+// scale converts font units into thousandths of em.
+func (f *Font) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+// Width returns width of the text in points for given font size.
+func (f *Font) Width(text string, size float64) float64 {
+	var w int
+	for _, r := range text {
+		w += f.advance(f.glyph(r))
+	}
+	return float64(w) * size / 1000
+}
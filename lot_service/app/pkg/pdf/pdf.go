@@ -0,0 +1,295 @@
+// Package pdf writes simple text documents, e.g. contracts, as PDF with embedded TrueType font,
+// so any script supported by the font is rendered.
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// A4 page in points.
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	margin     = 56.0
+)
+
+const (
+	textSize    = 10.5
+	headingSize = 13.0
+	footerSize  = 8.0
+	lineSpacing = 1.35
+)
+
+type line struct {
+	text string
+	x, y float64
+	size float64
+}
+
+// Document is laid out while text is added. Text is wrapped by words, pages are added when needed.
+type Document struct {
+	// Title and Created go into document information.
+	Title   string
+	Created time.Time
+	// Footer is printed on every page followed by page number.
+	Footer string
+
+	font  *Font
+	pages [][]line
+	y     float64
+}
+
+func New(font *Font) *Document {
+	return &Document{font: font}
+}
+
+// Heading adds centered paragraph in larger font.
+func (d *Document) Heading(text string) {
+	d.paragraph(text, headingSize, true)
+}
+
+// Paragraph adds text wrapped to the page width. Line breaks of the text are kept.
+func (d *Document) Paragraph(text string) {
+	d.paragraph(text, textSize, false)
+}
+
+func (d *Document) paragraph(text string, size float64, centered bool) {
+	height := size * lineSpacing
+	for _, l := range strings.Split(text, "\n") {
+		for _, wrapped := range d.wrap(l, size, pageWidth-2*margin) {
+			if len(d.pages) == 0 || d.y-height < margin+2*footerSize {
+				d.pages = append(d.pages, nil)
+				d.y = pageHeight - margin
+			}
+			d.y -= height
+
+			x := margin
+			if centered {
+				x = (pageWidth - d.font.Width(wrapped, size)) / 2
+			}
+			page := len(d.pages) - 1
+			d.pages[page] = append(d.pages[page], line{text: wrapped, x: x, y: d.y, size: size})
+		}
+	}
+	d.y -= height / 2
+}
+
+// wrap splits text into lines not wider than width. Words longer than width are not split.
+func (d *Document) wrap(text string, size, width float64) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	current := words[0]
+	for _, word := range words[1:] {
+		if candidate := current + " " + word; d.font.Width(candidate, size) <= width {
+			current = candidate
+			continue
+		}
+		lines = append(lines, current)
+		current = word
+	}
+	return append(lines, current)
+}
+
+// writer keeps offsets of objects for cross-reference table.
+type writer struct {
+	w       *bufio.Writer
+	n       int
+	offsets []int
+	err     error
+}
+
+func (pw *writer) printf(format string, args ...any) {
+	if pw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(pw.w, format, args...)
+	pw.n += n
+	pw.err = err
+}
+
+// object starts object with the next number, objects must be written in order of their numbers.
+func (pw *writer) object(body string) {
+	pw.offsets = append(pw.offsets, pw.n)
+	pw.printf("%d 0 obj\n%s\nendobj\n", len(pw.offsets), body)
+}
+
+func (pw *writer) stream(dict string, data []byte) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+
+	pw.offsets = append(pw.offsets, pw.n)
+	pw.printf("%d 0 obj\n<< %s /Length %d /Filter /FlateDecode >>\nstream\n", len(pw.offsets), dict, buf.Len())
+	if pw.err == nil {
+		n, err := pw.w.Write(buf.Bytes())
+		pw.n += n
+		pw.err = err
+	}
+	pw.printf("\nendstream\nendobj\n")
+}
+
+// Write writes the document. Empty document has one blank page.
+func (d *Document) Write(w io.Writer) error {
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = [][]line{nil}
+	}
+	used := make(map[uint16]rune)
+	for _, p := range pages {
+		for _, l := range p {
+			for _, r := range l.text {
+				used[d.font.glyph(r)] = r
+			}
+		}
+	}
+	for _, r := range d.Footer + "0123456789/ " {
+		used[d.font.glyph(r)] = r
+	}
+
+	// objects: 1 catalog, 2 pages, 3 info, 4 font, 5 CID font, 6 descriptor, 7 font file, 8 to unicode,
+	// then page and its contents for every page
+	const firstPage = 9
+	pw := &writer{w: bufio.NewWriter(w)}
+	pw.printf("%%PDF-1.7\n%%\xe2\xe3\xcf\xd3\n")
+
+	pw.object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	pw.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	pw.object(d.info())
+
+	f := d.font
+	pw.object("<< /Type /Font /Subtype /Type0 /BaseFont /Embedded /Encoding /Identity-H " +
+		"/DescendantFonts [5 0 R] /ToUnicode 8 0 R >>")
+	pw.object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /Embedded "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor 6 0 R /CIDToGIDMap /Identity /DW 1000 /W [%s] >>", d.widths(used)))
+	pw.object(fmt.Sprintf("<< /Type /FontDescriptor /FontName /Embedded /Flags 32 /FontBBox [%d %d %d %d] "+
+		"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 7 0 R >>",
+		f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.ascent)))
+	pw.stream(fmt.Sprintf("/Length1 %d", len(f.data)), f.data)
+	pw.stream("", toUnicode(used))
+
+	for i, p := range pages {
+		pw.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, firstPage+2*i+1))
+
+		footer := strings.TrimSpace(fmt.Sprintf("%s %d/%d", d.Footer, i+1, len(pages)))
+		p = append(p, line{text: footer, x: margin, y: margin, size: footerSize})
+		pw.stream("", d.content(p))
+	}
+
+	xref := pw.n
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	for _, offset := range pw.offsets {
+		pw.printf("%010d 00000 n \n", offset)
+	}
+	pw.printf("trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets)+1, xref)
+
+	if pw.err != nil {
+		return pw.err
+	}
+	return pw.w.Flush()
+}
+
+func (d *Document) info() string {
+	info := "<< /Producer " + textString("backendForSharedProject")
+	if d.Title != "" {
+		info += " /Title " + textString(d.Title)
+	}
+	if !d.Created.IsZero() {
+		info += " /CreationDate (D:" + d.Created.UTC().Format("20060102150405") + "Z)"
+	}
+	return info + " >>"
+}
+
+func (d *Document) content(lines []line) []byte {
+	var buf bytes.Buffer
+	for _, l := range lines {
+		fmt.Fprintf(&buf, "BT /F1 %.1f Tf %.2f %.2f Td <", l.size, l.x, l.y)
+		for _, r := range l.text {
+			fmt.Fprintf(&buf, "%04X", d.font.glyph(r))
+		}
+		buf.WriteString("> Tj ET\n")
+	}
+	return buf.Bytes()
+}
+
+// widths returns W array of used glyphs, so viewers don't need to read the font to lay out text.
+func (d *Document) widths(used map[uint16]rune) string {
+	glyphs := sortedGlyphs(used)
+	parts := make([]string, len(glyphs))
+	for i, g := range glyphs {
+		parts[i] = fmt.Sprintf("%d [%d]", g, d.font.advance(g))
+	}
+	return strings.Join(parts, " ")
+}
+
+// toUnicode maps glyphs back to text, so it can be copied and searched.
+func toUnicode(used map[uint16]rune) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	glyphs := sortedGlyphs(used)
+	for len(glyphs) > 0 {
+		// at most 100 entries are allowed in one block
+		n := len(glyphs)
+		if n > 100 {
+			n = 100
+		}
+		fmt.Fprintf(&buf, "%d beginbfchar\n", n)
+		for _, g := range glyphs[:n] {
+			fmt.Fprintf(&buf, "<%04X> <", g)
+			for _, u := range utf16.Encode([]rune{used[g]}) {
+				fmt.Fprintf(&buf, "%04X", u)
+			}
+			buf.WriteString(">\n")
+		}
+		buf.WriteString("endbfchar\n")
+		glyphs = glyphs[n:]
+	}
+
+	buf.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return buf.Bytes()
+}
+
+func sortedGlyphs(used map[uint16]rune) []uint16 {
+	glyphs := make([]uint16, 0, len(used))
+	for g := range used {
+		if g != 0 {
+			glyphs = append(glyphs, g)
+		}
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+	return glyphs
+}
+
+// textString encodes text string as UTF-16 with byte order mark.
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
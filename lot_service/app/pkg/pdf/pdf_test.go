@@ -0,0 +1,131 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFont builds minimal font, which maps A-Z to glyphs 1-26 and а-я to glyphs 27-58, all 500 units wide.
+func testFont(t *testing.T) *Font {
+	t.Helper()
+	be := binary.BigEndian
+
+	head := make([]byte, 54)
+	be.PutUint16(head[18:], 1000)
+	hhea := make([]byte, 36)
+	be.PutUint16(hhea[4:], 800)
+	be.PutUint16(hhea[6:], uint16(0xFFFF-199)) // -200
+	be.PutUint16(hhea[34:], 1)
+	hmtx := []byte{0x01, 0xF4, 0, 0} // advance 500 is used for all glyphs
+
+	// format 4 with segments A-Z, а-я and the final one
+	segments := []struct{ start, end, delta int }{
+		{'A', 'Z', 1 - 'A'},
+		{'а', 'я', 27 - 'а'},
+		{0xFFFF, 0xFFFF, 1},
+	}
+	sub := make([]byte, 16+8*len(segments))
+	be.PutUint16(sub, 4)
+	be.PutUint16(sub[2:], uint16(len(sub)))
+	be.PutUint16(sub[6:], uint16(2*len(segments)))
+	for i, s := range segments {
+		be.PutUint16(sub[14+2*i:], uint16(s.end))
+		be.PutUint16(sub[16+2*len(segments)+2*i:], uint16(s.start))
+		be.PutUint16(sub[16+4*len(segments)+2*i:], uint16(s.delta&0xFFFF))
+	}
+	cmap := append([]byte{0, 0, 0, 1, 0, 3, 0, 1, 0, 0, 0, 12}, sub...)
+
+	tables := []struct {
+		tag  string
+		data []byte
+	}{{"cmap", cmap}, {"head", head}, {"hhea", hhea}, {"hmtx", hmtx}}
+	font := make([]byte, 12+16*len(tables))
+	be.PutUint32(font, 0x00010000)
+	be.PutUint16(font[4:], uint16(len(tables)))
+	for i, table := range tables {
+		record := font[12+16*i:]
+		copy(record, table.tag)
+		be.PutUint32(record[8:], uint32(len(font)))
+		be.PutUint32(record[12:], uint32(len(table.data)))
+		font = append(font, table.data...)
+	}
+
+	f, err := ParseFont(font)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestParseFont(t *testing.T) {
+	f := testFont(t)
+
+	for r, want := range map[rune]uint16{'A': 1, 'Z': 26, 'а': 27, 'я': 58, '€': 0} {
+		if got := f.glyph(r); got != want {
+			t.Errorf("glyph of %q: expected %d, got %d", r, want, got)
+		}
+	}
+	if w := f.Width("ДОМ", 10); w != 15 {
+		t.Errorf("expected width 15, got %v", w)
+	}
+
+	if _, err := ParseFont([]byte("not a font at all")); err == nil {
+		t.Error("expected error for invalid font")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	d := New(testFont(t))
+	d.Title = "Договор"
+	d.Created = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	d.Footer = "Версия 1"
+
+	d.Heading("ДОГОВОР")
+	for i := 0; i < 80; i++ {
+		d.Paragraph(strings.Repeat("арендатор ", 40))
+	}
+
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+
+	if !bytes.HasPrefix(out, []byte("%PDF-1.7")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("output is not PDF")
+	}
+	if pages := len(d.pages); pages < 2 || !bytes.Contains(out, []byte("/Count "+strconv.Itoa(pages))) {
+		t.Errorf("expected long text on several pages, got %d", pages)
+	}
+	if !bytes.Contains(out, []byte("/CreationDate (D:20261019120000Z)")) {
+		t.Error("creation date is missing")
+	}
+
+	// every entry of cross-reference table must point to its object
+	xref := regexp.MustCompile(`(?m)^(\d{10}) 00000 n $`).FindAllSubmatch(out, -1)
+	if len(xref) == 0 {
+		t.Fatal("no cross-reference entries")
+	}
+	for i, entry := range xref {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := strconv.Itoa(i+1) + " 0 obj"; !bytes.HasPrefix(out[offset:], []byte(want)) {
+			t.Errorf("entry %d points to %q", i+1, out[offset:offset+10])
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	d := New(testFont(t))
+
+	// 500 units per glyph at size 10 are 5 points, so 10 glyphs fit into 50 points
+	lines := d.wrap("АА ББББ ВВВВВВВВВВВВ ГГ", 10, 50)
+	want := []string{"АА ББББ", "ВВВВВВВВВВВВ", "ГГ"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, lines)
+	}
+}
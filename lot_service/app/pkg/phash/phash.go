@@ -0,0 +1,84 @@
+// Package phash computes perceptual hashes of images. Hashes of resized, recompressed or slightly edited
+// copies of a photo differ in a few bits only, so copies are found by Hamming distance of hashes.
+package phash
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math/bits"
+	"strconv"
+)
+
+const (
+	width  = 9
+	height = 8
+)
+
+// DHash returns difference hash of the image: it's shrunk to 9x8 grayscale and every bit tells, whether
+// the pixel is brighter than its right neighbour.
+func DHash(img image.Image) uint64 {
+	var gray [height][width]float64
+	b := img.Bounds()
+	for y := 0; y < height; y++ {
+		y0, y1 := b.Min.Y+y*b.Dy()/height, b.Min.Y+(y+1)*b.Dy()/height
+		for x := 0; x < width; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/width, b.Min.X+(x+1)*b.Dx()/width
+			gray[y][x] = brightness(img, x0, y0, x1, y1)
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width-1; x++ {
+			hash <<= 1
+			if gray[y][x] > gray[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// Decode reads JPEG or PNG image and returns its DHash.
+func Decode(r io.Reader) (uint64, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image. error: %w", err)
+	}
+	return DHash(img), nil
+}
+
+// Distance returns number of different bits of the hashes, copies of the same photo have up to about 10.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Format returns the hash as 16 hex digits.
+func Format(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+func Parse(s string) (uint64, error) {
+	return strconv.ParseUint(s, 16, 64)
+}
+
+// brightness returns average luminance of the area, images smaller than 9x8 are sampled by a single pixel.
+func brightness(img image.Image, x0, y0, x1, y1 int) float64 {
+	if x1 <= x0 {
+		x1 = x0 + 1
+	}
+	if y1 <= y0 {
+		y1 = y0 + 1
+	}
+	var sum float64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+		}
+	}
+	return sum / float64((x1-x0)*(y1-y0))
+}
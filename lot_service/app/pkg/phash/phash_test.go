@@ -0,0 +1,45 @@
+package phash
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// gradient draws horizontal gradient with a dark square, scaled to the size. Mirrored gradient is
+// a different image.
+func gradient(w, h int, mirrored bool) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			px := x
+			if mirrored {
+				px = w - 1 - x
+			}
+			v := uint8(255 * (px*px + y) / (w*w + h))
+			if x > w/4 && x < w/2 && y > h/4 && y < h/2 {
+				v = 0
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func TestDHash(t *testing.T) {
+	original := DHash(gradient(640, 480, false))
+	resized := DHash(gradient(320, 240, false))
+	other := DHash(gradient(640, 480, true))
+
+	if d := Distance(original, resized); d > 10 {
+		t.Errorf("expected resized copy to be close, got distance %d", d)
+	}
+	if d := Distance(original, other); d <= 10 {
+		t.Errorf("expected different image to be far, got distance %d", d)
+	}
+
+	parsed, err := Parse(Format(original))
+	if err != nil || parsed != original {
+		t.Errorf("expected %x after format and parse, got %x, %v", original, parsed, err)
+	}
+}
@@ -0,0 +1,13 @@
+package rules
+
+import validation "github.com/go-ozzo/ozzo-validation"
+
+// RequiredIf checks that value isn't empty, if cond holds.
+func RequiredIf(cond bool) validation.RuleFunc {
+	return func(value interface{}) error {
+		if cond {
+			return validation.Validate(value, validation.Required)
+		}
+		return nil
+	}
+}
@@ -1,23 +1,28 @@
 package shutdown
 
 import (
+	"context"
 	"github.com/levelord1311/backendForSharedProject/lot_service/pkg/logging"
-	"io"
+	"net/http"
 	"os"
-	"os/signal"
+	"syscall"
+	"time"
 )
 
-func Graceful(signals []os.Signal, closeItems ...io.Closer) {
+// Signals stop the service.
+var Signals = []os.Signal{syscall.SIGABRT, syscall.SIGQUIT, syscall.SIGHUP, os.Interrupt, syscall.SIGTERM}
+
+// Graceful shuts down the server, when the context is done. Requests in progress are given timeout
+// to complete, Graceful returns when they are completed.
+func Graceful(ctx context.Context, server *http.Server, timeout time.Duration) {
 	logger := logging.GetLogger()
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, signals...)
-	sig := <-sigCh
-	logger.Infof("Caught signal %s. Shutting down...", sig)
+	<-ctx.Done()
+	logger.Info("Shutting down...")
 
-	for _, closer := range closeItems {
-		if err := closer.Close(); err != nil {
-			logger.Errorf("failed to close %v: %v", closer, err)
-		}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Errorf("failed to shut down server: %v", err)
 	}
 }
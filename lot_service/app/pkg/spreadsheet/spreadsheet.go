@@ -0,0 +1,86 @@
+// Package spreadsheet reads rows of CSV files and of the first sheet of XLSX workbooks.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnknownFormat = errors.New("unknown spreadsheet format")
+
+// zipMagic starts XLSX files, which are zip archives.
+var zipMagic = []byte("PK\x03\x04")
+
+// DetectFormat tells XLSX workbooks from CSV files by content.
+func DetectFormat(data []byte) string {
+	if bytes.HasPrefix(data, zipMagic) {
+		return FormatXLSX
+	}
+	return FormatCSV
+}
+
+// Read returns rows of the file in given format, empty format is detected by content.
+// Rows keep their numbers: row i of the result is row i+1 of the sheet, missing rows are empty.
+func Read(format string, data []byte) ([][]string, error) {
+	if format == "" {
+		format = DetectFormat(data)
+	}
+	switch format {
+	case FormatCSV:
+		return readCSV(data)
+	case FormatXLSX:
+		return readXLSX(data)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+}
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// readCSV reads comma, semicolon or tab separated values. Spreadsheet editors with Russian locale
+// save CSV with semicolons, so the delimiter is guessed by the first line.
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = guessDelimiter(data)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	rows := make([][]string, 0)
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, record)
+	}
+	return rows, nil
+}
+
+func guessDelimiter(data []byte) rune {
+	firstLine := string(data)
+	if i := strings.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+
+	delimiter, best := ',', strings.Count(firstLine, ",")
+	for _, d := range []rune{';', '\t'} {
+		if n := strings.Count(firstLine, string(d)); n > best {
+			delimiter, best = d, n
+		}
+	}
+	return delimiter
+}
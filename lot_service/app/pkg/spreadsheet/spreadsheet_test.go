@@ -0,0 +1,62 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	data := []byte("\xef\xbb\xbfcity;street;price\nМосква;\"Тверская; 1\";45000\n")
+
+	rows, err := Read("", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"city", "street", "price"}, {"Москва", "Тверская; 1", "45000"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("expected %q, got %q", want, rows)
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+			xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+			<sheets><sheet name="Лоты" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+			<Relationship Id="rId3" Type="worksheet" Target="worksheets/lots.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+			<si><t>city</t></si><si><t>price</t></si><si><r><t>Моск</t></r><r><t>ва</t></r></si></sst>`,
+		"xl/worksheets/lots.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+			<row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3" t="inlineStr"><is><t>центр</t></is></c><c r="C3"><v>45000</v></c></row>
+			</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if format := DetectFormat(buf.Bytes()); format != FormatXLSX {
+		t.Fatalf("expected xlsx format, got %s", format)
+	}
+	rows, err := Read("", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"city", "", "price"}, nil, {"Москва", "центр", "45000"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("expected %q, got %q", want, rows)
+	}
+}
@@ -0,0 +1,5 @@
+DROP TABLE `contact_reveals`;
+DROP TABLE `phone_verifications`;
+ALTER TABLE `users`
+    DROP COLUMN `phone_verified_at`,
+    DROP COLUMN `phone`;
@@ -0,0 +1,28 @@
+ALTER TABLE `users`
+    ADD COLUMN `phone` VARCHAR(20) AFTER `role`,
+    ADD COLUMN `phone_verified_at` TIMESTAMP NULL DEFAULT NULL AFTER `phone`;
+
+CREATE TABLE `phone_verifications` (
+    `user_id` INT UNSIGNED NOT NULL,
+    `phone` VARCHAR(20) NOT NULL,
+    `code_hash` CHAR(64) NOT NULL,
+    `attempts` INT NOT NULL DEFAULT 0,
+    `expires_at` TIMESTAMP NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`),
+    FOREIGN KEY (`user_id`) REFERENCES users(user_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `contact_reveals` (
+    `reveal_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `viewer_id` INT UNSIGNED NOT NULL,
+    `owner_id` INT UNSIGNED NOT NULL,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`reveal_id`),
+    INDEX (`viewer_id`, `created_at`),
+    INDEX (`owner_id`),
+    INDEX (`lot_id`),
+    FOREIGN KEY (`viewer_id`) REFERENCES users(user_id),
+    FOREIGN KEY (`owner_id`) REFERENCES users(user_id)
+    ) ENGINE = InnoDB;
@@ -0,0 +1,14 @@
+ALTER TABLE `users`
+    ADD COLUMN `totp_secret` VARCHAR(64) AFTER `phone_verified_at`,
+    ADD COLUMN `totp_enabled_at` TIMESTAMP NULL DEFAULT NULL AFTER `totp_secret`;
+
+CREATE TABLE `recovery_codes` (
+    `code_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `user_id` INT UNSIGNED NOT NULL,
+    `code_hash` CHAR(64) NOT NULL,
+    `used_at` TIMESTAMP NULL DEFAULT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`code_id`),
+    INDEX (`user_id`),
+    FOREIGN KEY (`user_id`) REFERENCES users(user_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
@@ -0,0 +1,9 @@
+CREATE TABLE `login_attempts` (
+    `attempt_key` VARCHAR(255) NOT NULL,
+    `failures` INT UNSIGNED NOT NULL DEFAULT 0,
+    `last_failure_at` DATETIME(3) NULL DEFAULT NULL,
+    `locked_until` DATETIME(3) NULL DEFAULT NULL,
+    `expires_at` DATETIME(3) NOT NULL,
+    PRIMARY KEY (`attempt_key`),
+    INDEX (`expires_at`)
+    ) ENGINE = InnoDB;
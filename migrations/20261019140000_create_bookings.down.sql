@@ -0,0 +1,3 @@
+DROP TABLE `bookings`;
+ALTER TABLE `lots`
+    DROP COLUMN `available`;
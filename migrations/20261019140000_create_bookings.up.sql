@@ -0,0 +1,24 @@
+ALTER TABLE `lots`
+    ADD COLUMN `available` BOOLEAN NOT NULL DEFAULT TRUE AFTER `price`;
+
+CREATE TABLE `bookings` (
+    `booking_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `renter_id` INT UNSIGNED NOT NULL,
+    `landlord_id` INT UNSIGNED NOT NULL,
+    `check_in` DATE NOT NULL,
+    `check_out` DATE NOT NULL,
+    `message` TEXT,
+    `status` VARCHAR(20) NOT NULL,
+    `expires_at` TIMESTAMP NULL DEFAULT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `redacted_at` TIMESTAMP on update CURRENT_TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`booking_id`),
+    INDEX (`lot_id`, `status`),
+    INDEX (`renter_id`),
+    INDEX (`landlord_id`),
+    INDEX (`status`, `expires_at`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE,
+    FOREIGN KEY (`renter_id`) REFERENCES users(user_id),
+    FOREIGN KEY (`landlord_id`) REFERENCES users(user_id)
+    ) ENGINE = InnoDB;
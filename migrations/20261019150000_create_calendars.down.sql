@@ -0,0 +1,2 @@
+DROP TABLE `calendar_imports`;
+DROP TABLE `calendar_blocks`;
@@ -0,0 +1,21 @@
+CREATE TABLE `calendar_blocks` (
+    `block_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `start_date` DATE NOT NULL,
+    `end_date` DATE NOT NULL,
+    `source` VARCHAR(20) NOT NULL,
+    `summary` VARCHAR(255),
+    `uid` VARCHAR(255),
+    PRIMARY KEY (`block_id`),
+    INDEX (`lot_id`, `start_date`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `calendar_imports` (
+    `lot_id` INT UNSIGNED NOT NULL,
+    `url` VARCHAR(2000) NOT NULL,
+    `last_imported_at` TIMESTAMP NULL DEFAULT NULL,
+    `last_error` VARCHAR(1000),
+    PRIMARY KEY (`lot_id`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
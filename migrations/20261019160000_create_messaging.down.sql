@@ -0,0 +1,4 @@
+DROP TABLE `user_blocks`;
+DROP TABLE `message_attachments`;
+DROP TABLE `messages`;
+DROP TABLE `conversations`;
@@ -0,0 +1,53 @@
+CREATE TABLE `conversations` (
+    `conversation_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `renter_id` INT UNSIGNED NOT NULL,
+    `landlord_id` INT UNSIGNED NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `last_message_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`conversation_id`),
+    UNIQUE (`lot_id`, `renter_id`),
+    INDEX (`renter_id`),
+    INDEX (`landlord_id`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE,
+    FOREIGN KEY (`renter_id`) REFERENCES users(user_id),
+    FOREIGN KEY (`landlord_id`) REFERENCES users(user_id)
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `messages` (
+    `message_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `conversation_id` INT UNSIGNED NOT NULL,
+    `sender_id` INT UNSIGNED NOT NULL,
+    `body` TEXT NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `read_at` TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (`message_id`),
+    INDEX (`conversation_id`, `read_at`),
+    INDEX (`created_at`),
+    FOREIGN KEY (`conversation_id`) REFERENCES conversations(conversation_id) ON DELETE CASCADE,
+    FOREIGN KEY (`sender_id`) REFERENCES users(user_id)
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `message_attachments` (
+    `attachment_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `message_id` INT UNSIGNED NULL DEFAULT NULL,
+    `uploader_id` INT UNSIGNED NOT NULL,
+    `name` VARCHAR(255) NOT NULL,
+    `content_type` VARCHAR(100) NOT NULL,
+    `size` BIGINT NOT NULL,
+    `storage_key` VARCHAR(64) NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`attachment_id`),
+    INDEX (`message_id`, `created_at`),
+    FOREIGN KEY (`message_id`) REFERENCES messages(message_id) ON DELETE CASCADE,
+    FOREIGN KEY (`uploader_id`) REFERENCES users(user_id)
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `user_blocks` (
+    `blocker_id` INT UNSIGNED NOT NULL,
+    `blocked_id` INT UNSIGNED NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`blocker_id`, `blocked_id`),
+    FOREIGN KEY (`blocker_id`) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (`blocked_id`) REFERENCES users(user_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
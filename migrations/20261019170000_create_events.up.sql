@@ -0,0 +1,10 @@
+CREATE TABLE `events` (
+    `event_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `user_id` INT UNSIGNED NOT NULL,
+    `type` VARCHAR(50) NOT NULL,
+    `payload` JSON NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`event_id`),
+    INDEX (`user_id`, `event_id`),
+    INDEX (`created_at`)
+    ) ENGINE = InnoDB;
@@ -0,0 +1,32 @@
+-- reviews outlive deleted lots and bookings, so landlords can't get rid of their ratings
+CREATE TABLE `reviews` (
+    `review_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `booking_id` INT UNSIGNED NULL,
+    `lot_id` INT UNSIGNED NULL,
+    `landlord_id` INT UNSIGNED NOT NULL,
+    `author_id` INT UNSIGNED NOT NULL,
+    `rating` TINYINT UNSIGNED NOT NULL,
+    `text` TEXT NOT NULL,
+    `reply` TEXT NULL DEFAULT NULL,
+    `replied_at` TIMESTAMP NULL DEFAULT NULL,
+    `hidden` BOOLEAN NOT NULL DEFAULT FALSE,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`review_id`),
+    UNIQUE (`booking_id`),
+    INDEX (`lot_id`, `hidden`),
+    INDEX (`landlord_id`, `hidden`),
+    FOREIGN KEY (`booking_id`) REFERENCES bookings(booking_id) ON DELETE SET NULL,
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE SET NULL,
+    FOREIGN KEY (`landlord_id`) REFERENCES users(user_id),
+    FOREIGN KEY (`author_id`) REFERENCES users(user_id)
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `review_reports` (
+    `review_id` INT UNSIGNED NOT NULL,
+    `user_id` INT UNSIGNED NOT NULL,
+    `reason` VARCHAR(1000) NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`review_id`, `user_id`),
+    FOREIGN KEY (`review_id`) REFERENCES reviews(review_id) ON DELETE CASCADE,
+    FOREIGN KEY (`user_id`) REFERENCES users(user_id)
+    ) ENGINE = InnoDB;
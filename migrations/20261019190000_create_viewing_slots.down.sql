@@ -0,0 +1 @@
+DROP TABLE `viewing_slots`;
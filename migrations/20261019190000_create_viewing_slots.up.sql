@@ -0,0 +1,20 @@
+CREATE TABLE `viewing_slots` (
+    `slot_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `landlord_id` INT UNSIGNED NOT NULL,
+    `starts_at` DATETIME NOT NULL,
+    `ends_at` DATETIME NOT NULL,
+    `renter_id` INT UNSIGNED NULL DEFAULT NULL,
+    `booked_at` DATETIME NULL DEFAULT NULL,
+    `sequence` INT UNSIGNED NOT NULL DEFAULT 0,
+    `reminder_sent_at` DATETIME NULL DEFAULT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`slot_id`),
+    INDEX (`lot_id`, `starts_at`),
+    INDEX (`landlord_id`, `starts_at`),
+    INDEX (`renter_id`, `starts_at`),
+    INDEX (`reminder_sent_at`, `starts_at`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE,
+    FOREIGN KEY (`landlord_id`) REFERENCES users(user_id),
+    FOREIGN KEY (`renter_id`) REFERENCES users(user_id)
+    ) ENGINE = InnoDB;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `agreements`;
+DROP TABLE IF EXISTS `agreement_templates`;
@@ -0,0 +1,35 @@
+CREATE TABLE `agreement_templates` (
+    `template_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `owner_id` INT UNSIGNED NOT NULL,
+    `name` VARCHAR(100) NOT NULL,
+    `body` MEDIUMTEXT NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`template_id`),
+    INDEX (`owner_id`),
+    FOREIGN KEY (`owner_id`) REFERENCES users(user_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+-- versions of agreements are evidence, so they outlive deleted lots, bookings and templates
+CREATE TABLE `agreements` (
+    `agreement_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `booking_id` INT UNSIGNED NOT NULL,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `landlord_id` INT UNSIGNED NOT NULL,
+    `renter_id` INT UNSIGNED NOT NULL,
+    `version` INT UNSIGNED NOT NULL,
+    `template_id` INT UNSIGNED NULL DEFAULT NULL,
+    `text` MEDIUMTEXT NOT NULL,
+    `hash` CHAR(64) NOT NULL,
+    `landlord_accepted_at` TIMESTAMP NULL DEFAULT NULL,
+    `landlord_ip` VARCHAR(45) NULL DEFAULT NULL,
+    `renter_accepted_at` TIMESTAMP NULL DEFAULT NULL,
+    `renter_ip` VARCHAR(45) NULL DEFAULT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`agreement_id`),
+    UNIQUE (`booking_id`, `version`),
+    INDEX (`landlord_id`),
+    INDEX (`renter_id`),
+    FOREIGN KEY (`template_id`) REFERENCES agreement_templates(template_id) ON DELETE SET NULL,
+    FOREIGN KEY (`landlord_id`) REFERENCES users(user_id),
+    FOREIGN KEY (`renter_id`) REFERENCES users(user_id)
+    ) ENGINE = InnoDB;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `ledger_entries`;
+DROP TABLE IF EXISTS `payment_webhook_events`;
+DROP TABLE IF EXISTS `payment_refunds`;
+DROP TABLE IF EXISTS `payments`;
@@ -0,0 +1,64 @@
+-- payments, refunds and ledger are financial records, so they outlive deleted lots and bookings
+CREATE TABLE `payments` (
+    `payment_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `booking_id` INT UNSIGNED NOT NULL,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `payer_id` INT UNSIGNED NOT NULL,
+    `payee_id` INT UNSIGNED NOT NULL,
+    `kind` VARCHAR(20) NOT NULL,
+    `period` CHAR(7) NOT NULL DEFAULT '',
+    `amount` BIGINT NOT NULL,
+    `refunded` BIGINT NOT NULL DEFAULT 0,
+    `currency` CHAR(3) NOT NULL,
+    `status` VARCHAR(20) NOT NULL,
+    `provider` VARCHAR(20) NOT NULL DEFAULT '',
+    `provider_id` VARCHAR(100) NULL DEFAULT NULL,
+    `checkout_url` VARCHAR(500) NOT NULL DEFAULT '',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `paid_at` TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (`payment_id`),
+    UNIQUE (`provider`, `provider_id`),
+    INDEX (`booking_id`, `kind`, `period`),
+    INDEX (`payer_id`),
+    INDEX (`payee_id`),
+    FOREIGN KEY (`payer_id`) REFERENCES users(user_id),
+    FOREIGN KEY (`payee_id`) REFERENCES users(user_id)
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `payment_refunds` (
+    `refund_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `payment_id` INT UNSIGNED NOT NULL,
+    `amount` BIGINT NOT NULL,
+    `reason` VARCHAR(500) NOT NULL DEFAULT '',
+    `status` VARCHAR(20) NOT NULL,
+    `idempotency_key` VARCHAR(64) NOT NULL,
+    `provider_id` VARCHAR(100) NULL DEFAULT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`refund_id`),
+    UNIQUE (`payment_id`, `idempotency_key`),
+    FOREIGN KEY (`payment_id`) REFERENCES payments(payment_id)
+    ) ENGINE = InnoDB;
+
+-- webhooks may be delivered several times, every event of the provider is applied once
+CREATE TABLE `payment_webhook_events` (
+    `provider` VARCHAR(20) NOT NULL,
+    `event_id` VARCHAR(100) NOT NULL,
+    `received_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`provider`, `event_id`)
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `ledger_entries` (
+    `entry_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `user_id` INT UNSIGNED NOT NULL,
+    `payment_id` INT UNSIGNED NOT NULL,
+    `refund_id` INT UNSIGNED NULL DEFAULT NULL,
+    `type` VARCHAR(20) NOT NULL,
+    `amount` BIGINT NOT NULL,
+    `currency` CHAR(3) NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`entry_id`),
+    INDEX (`user_id`, `entry_id`),
+    FOREIGN KEY (`user_id`) REFERENCES users(user_id),
+    FOREIGN KEY (`payment_id`) REFERENCES payments(payment_id),
+    FOREIGN KEY (`refund_id`) REFERENCES payment_refunds(refund_id)
+    ) ENGINE = InnoDB;
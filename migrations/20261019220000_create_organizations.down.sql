@@ -0,0 +1,5 @@
+ALTER TABLE `lots`
+    DROP FOREIGN KEY `lots_organization_fk`,
+    DROP COLUMN `organization_id`;
+DROP TABLE IF EXISTS `organization_members`;
+DROP TABLE IF EXISTS `organizations`;
@@ -0,0 +1,27 @@
+CREATE TABLE `organizations` (
+    `organization_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `name` VARCHAR(100) NOT NULL,
+    `description` TEXT NOT NULL,
+    `phone` VARCHAR(20) NOT NULL DEFAULT '',
+    `email` VARCHAR(255) NOT NULL DEFAULT '',
+    `website` VARCHAR(255) NOT NULL DEFAULT '',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`organization_id`)
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `organization_members` (
+    `organization_id` INT UNSIGNED NOT NULL,
+    `user_id` INT UNSIGNED NOT NULL,
+    `role` VARCHAR(20) NOT NULL,
+    `joined_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`organization_id`, `user_id`),
+    INDEX (`user_id`),
+    FOREIGN KEY (`organization_id`) REFERENCES organizations(organization_id) ON DELETE CASCADE,
+    FOREIGN KEY (`user_id`) REFERENCES users(user_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+-- lots of organization are assigned to its members by user_id, lots without organization belong to the user
+ALTER TABLE `lots`
+    ADD COLUMN `organization_id` INT UNSIGNED NULL DEFAULT NULL AFTER `user_id`,
+    ADD INDEX (`organization_id`),
+    ADD CONSTRAINT `lots_organization_fk` FOREIGN KEY (`organization_id`) REFERENCES organizations(organization_id) ON DELETE SET NULL;
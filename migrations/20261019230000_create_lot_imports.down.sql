@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `lot_import_jobs`;
+DROP TABLE IF EXISTS `lot_import_profiles`;
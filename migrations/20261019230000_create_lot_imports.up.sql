@@ -0,0 +1,34 @@
+CREATE TABLE `lot_import_profiles` (
+    `profile_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `owner_id` INT UNSIGNED NOT NULL,
+    `name` VARCHAR(100) NOT NULL,
+    `mapping` JSON NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`profile_id`),
+    UNIQUE (`owner_id`, `name`),
+    FOREIGN KEY (`owner_id`) REFERENCES users(user_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `lot_import_jobs` (
+    `job_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `user_id` INT UNSIGNED NOT NULL,
+    `organization_id` INT UNSIGNED NULL DEFAULT NULL,
+    `format` VARCHAR(10) NOT NULL,
+    `dry_run` BOOLEAN NOT NULL DEFAULT FALSE,
+    `mapping` JSON NOT NULL,
+    `status` VARCHAR(20) NOT NULL,
+    `total_rows` INT UNSIGNED NOT NULL DEFAULT 0,
+    `valid_rows` INT UNSIGNED NOT NULL DEFAULT 0,
+    `created_rows` INT UNSIGNED NOT NULL DEFAULT 0,
+    `errors` JSON NULL DEFAULT NULL,
+    `error` VARCHAR(500) NOT NULL DEFAULT '',
+    -- key of uploaded file in media storage, the file is deleted, when the job is finished
+    `file_key` VARCHAR(64) NOT NULL DEFAULT '',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `finished_at` TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (`job_id`),
+    INDEX (`user_id`, `job_id`),
+    INDEX (`status`, `job_id`),
+    FOREIGN KEY (`user_id`) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (`organization_id`) REFERENCES organizations(organization_id) ON DELETE SET NULL
+    ) ENGINE = InnoDB;
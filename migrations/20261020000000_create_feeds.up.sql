@@ -0,0 +1,11 @@
+-- feeds of lots for aggregator portals, lots are selected with filter in query syntax of lot search
+CREATE TABLE `feeds` (
+    `feed_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `name` VARCHAR(100) NOT NULL,
+    `format` VARCHAR(10) NOT NULL,
+    `filter` VARCHAR(1000) NOT NULL DEFAULT '',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`feed_id`),
+    UNIQUE (`name`)
+    ) ENGINE = InnoDB;
@@ -0,0 +1,19 @@
+-- views of lot pages, each user or anonymous session is counted once per day.
+-- viewer is a hash of user ID or session ID, so sessions aren't stored.
+CREATE TABLE `lot_views` (
+    `lot_id` INT UNSIGNED NOT NULL,
+    `day` DATE NOT NULL,
+    `viewer` CHAR(32) NOT NULL,
+    PRIMARY KEY (`lot_id`, `day`, `viewer`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `favorites` (
+    `user_id` INT UNSIGNED NOT NULL,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`, `lot_id`),
+    INDEX (`lot_id`, `created_at`),
+    FOREIGN KEY (`user_id`) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
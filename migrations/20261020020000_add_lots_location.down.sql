@@ -0,0 +1,3 @@
+ALTER TABLE `lots`
+    DROP COLUMN `longitude`,
+    DROP COLUMN `latitude`;
@@ -0,0 +1,4 @@
+-- coordinates of lots are optional, similar lots are found by distance, when both lots have them
+ALTER TABLE `lots`
+    ADD COLUMN `latitude` DECIMAL(9, 6) NULL DEFAULT NULL,
+    ADD COLUMN `longitude` DECIMAL(9, 6) NULL DEFAULT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `lot_duplicates`;
+DROP TABLE IF EXISTS `lot_fingerprints`;
@@ -0,0 +1,30 @@
+-- fingerprints of lots are compared at the same normalized address.
+-- photo_hashes are comma separated hex perceptual hashes of photos of the lot.
+CREATE TABLE `lot_fingerprints` (
+    `lot_id` INT UNSIGNED NOT NULL,
+    `address` VARCHAR(255) NOT NULL,
+    `floor` INT NOT NULL,
+    `area` INT NOT NULL,
+    `rooms` INT NOT NULL,
+    `photo_hashes` VARCHAR(2000) NOT NULL DEFAULT '',
+    PRIMARY KEY (`lot_id`),
+    INDEX (`address`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+-- lot_id is the newer lot of the pair, duplicate_of_id is the original
+CREATE TABLE `lot_duplicates` (
+    `duplicate_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `duplicate_of_id` INT UNSIGNED NOT NULL,
+    `score` DECIMAL(4,3) NOT NULL,
+    `status` ENUM('pending', 'confirmed', 'dismissed') NOT NULL DEFAULT 'pending',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `moderated_at` TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (`duplicate_id`),
+    UNIQUE (`lot_id`, `duplicate_of_id`),
+    INDEX (`duplicate_of_id`),
+    INDEX (`status`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE,
+    FOREIGN KEY (`duplicate_of_id`) REFERENCES lots(lot_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
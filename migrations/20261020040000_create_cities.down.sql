@@ -0,0 +1,7 @@
+ALTER TABLE `lots`
+    DROP FOREIGN KEY `lots_district_fk`,
+    DROP FOREIGN KEY `lots_city_fk`,
+    DROP COLUMN `district_id`,
+    DROP COLUMN `city_id`;
+DROP TABLE IF EXISTS `districts`;
+DROP TABLE IF EXISTS `cities`;
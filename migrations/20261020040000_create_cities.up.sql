@@ -0,0 +1,27 @@
+-- canonical cities and districts. normalized is the name in lower case without type, so spellings
+-- like "г. Москва" and "москва" refer to the same city.
+CREATE TABLE `cities` (
+    `city_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `name` VARCHAR(255) NOT NULL,
+    `normalized` VARCHAR(255) NOT NULL,
+    PRIMARY KEY (`city_id`),
+    UNIQUE (`normalized`)
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `districts` (
+    `district_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `city_id` INT UNSIGNED NOT NULL,
+    `name` VARCHAR(255) NOT NULL,
+    `normalized` VARCHAR(255) NOT NULL,
+    PRIMARY KEY (`district_id`),
+    UNIQUE (`city_id`, `normalized`),
+    FOREIGN KEY (`city_id`) REFERENCES cities(city_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+-- lots are resolved to canonical city and district after creation
+ALTER TABLE `lots`
+    ADD COLUMN `city_id` INT UNSIGNED NULL DEFAULT NULL AFTER `max_floor`,
+    ADD COLUMN `district_id` INT UNSIGNED NULL DEFAULT NULL AFTER `city_id`,
+    ADD INDEX (`city_id`, `district_id`),
+    ADD CONSTRAINT `lots_city_fk` FOREIGN KEY (`city_id`) REFERENCES cities(city_id) ON DELETE SET NULL,
+    ADD CONSTRAINT `lots_district_fk` FOREIGN KEY (`district_id`) REFERENCES districts(district_id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `district_names`;
+DROP TABLE IF EXISTS `city_names`;
+DROP TABLE IF EXISTS `estate_type_names`;
+DROP TABLE IF EXISTS `estate_types`;
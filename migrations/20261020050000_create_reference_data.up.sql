@@ -0,0 +1,39 @@
+-- types of estate of lots, lots store code of the type in type_of_estate
+CREATE TABLE `estate_types` (
+    `estate_type_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `code` VARCHAR(50) CHARACTER SET utf8 COLLATE utf8_general_ci NOT NULL,
+    `position` INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`estate_type_id`),
+    UNIQUE (`code`)
+    ) ENGINE = InnoDB;
+
+-- display names of reference data by locale, e.g. en or ru
+CREATE TABLE `estate_type_names` (
+    `estate_type_id` INT UNSIGNED NOT NULL,
+    `locale` VARCHAR(10) NOT NULL,
+    `name` VARCHAR(255) NOT NULL,
+    PRIMARY KEY (`estate_type_id`, `locale`),
+    FOREIGN KEY (`estate_type_id`) REFERENCES estate_types(estate_type_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `city_names` (
+    `city_id` INT UNSIGNED NOT NULL,
+    `locale` VARCHAR(10) NOT NULL,
+    `name` VARCHAR(255) NOT NULL,
+    PRIMARY KEY (`city_id`, `locale`),
+    FOREIGN KEY (`city_id`) REFERENCES cities(city_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+CREATE TABLE `district_names` (
+    `district_id` INT UNSIGNED NOT NULL,
+    `locale` VARCHAR(10) NOT NULL,
+    `name` VARCHAR(255) NOT NULL,
+    PRIMARY KEY (`district_id`, `locale`),
+    FOREIGN KEY (`district_id`) REFERENCES districts(district_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+INSERT INTO `estate_types` (`code`, `position`) VALUES ('квартира', 1), ('дом', 2);
+INSERT INTO `estate_type_names` (`estate_type_id`, `locale`, `name`)
+SELECT `estate_type_id`, 'ru', CASE `code` WHEN 'квартира' THEN 'Квартира' ELSE 'Дом' END FROM `estate_types`
+UNION ALL
+SELECT `estate_type_id`, 'en', CASE `code` WHEN 'квартира' THEN 'Flat' ELSE 'House' END FROM `estate_types`;
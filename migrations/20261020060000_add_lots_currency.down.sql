@@ -0,0 +1,5 @@
+ALTER TABLE `lots`
+    DROP COLUMN `price_base`,
+    DROP COLUMN `price_period`,
+    DROP COLUMN `currency`;
+DROP TABLE IF EXISTS `currency_rates`;
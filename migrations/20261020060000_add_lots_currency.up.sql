@@ -0,0 +1,18 @@
+-- values of units of currencies in RUB, prices of lots are compared in RUB
+CREATE TABLE `currency_rates` (
+    `currency` CHAR(3) NOT NULL,
+    `rate` DOUBLE NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`currency`)
+    ) ENGINE = InnoDB;
+
+INSERT INTO `currency_rates` (`currency`, `rate`, `updated_at`) VALUES ('RUB', 1, UTC_TIMESTAMP());
+
+-- price_base is price in RUB at the latest rates, it's null for currencies without rates
+ALTER TABLE `lots`
+    ADD COLUMN `currency` CHAR(3) NOT NULL DEFAULT 'RUB' AFTER `price`,
+    ADD COLUMN `price_period` ENUM('month', 'day', 'sale') NOT NULL DEFAULT 'month' AFTER `currency`,
+    ADD COLUMN `price_base` BIGINT NULL DEFAULT NULL AFTER `price_period`,
+    ADD INDEX (`price_base`);
+
+UPDATE `lots` SET `price_base`=`price`, `redacted_at`=`redacted_at`;
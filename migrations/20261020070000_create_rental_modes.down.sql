@@ -0,0 +1,5 @@
+ALTER TABLE `bookings`
+    DROP COLUMN `currency`,
+    DROP COLUMN `total`;
+DROP TABLE IF EXISTS `lot_season_prices`;
+DROP TABLE IF EXISTS `lot_rental_modes`;
@@ -0,0 +1,30 @@
+-- rental modes of lots with prices in currency of the lot, lots without modes are rented in the mode
+-- of their price period. min_stay is in nights for short-term and in months for long-term rent
+CREATE TABLE `lot_rental_modes` (
+    `lot_id` INT UNSIGNED NOT NULL,
+    `mode` ENUM('long_term', 'short_term') NOT NULL,
+    `price` INT UNSIGNED NOT NULL,
+    `min_stay` INT UNSIGNED NOT NULL DEFAULT 1,
+    `cleaning_fee` INT UNSIGNED NOT NULL DEFAULT 0,
+    PRIMARY KEY (`lot_id`, `mode`),
+    INDEX (`mode`, `min_stay`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+-- seasonal prices replace prices of modes for nights or months starting in the season, end day is not included
+CREATE TABLE `lot_season_prices` (
+    `season_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `mode` ENUM('long_term', 'short_term') NOT NULL,
+    `start_date` DATE NOT NULL,
+    `end_date` DATE NOT NULL,
+    `price` INT UNSIGNED NOT NULL,
+    PRIMARY KEY (`season_id`),
+    INDEX (`lot_id`, `mode`, `start_date`),
+    FOREIGN KEY (`lot_id`, `mode`) REFERENCES lot_rental_modes(lot_id, mode) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+-- bookings keep price of the stay quoted on acceptance, payments are shares of it
+ALTER TABLE `bookings`
+    ADD COLUMN `total` INT NOT NULL DEFAULT 0 AFTER `expires_at`,
+    ADD COLUMN `currency` CHAR(3) NOT NULL DEFAULT '' AFTER `total`;
@@ -0,0 +1,16 @@
+-- versions of lots, every change of a lot is a new version with the state of the lot after the change
+-- and changed fields. Lots created before versioning get their first version on the first change
+CREATE TABLE `lot_versions` (
+    `version_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `number` INT UNSIGNED NOT NULL,
+    `action` ENUM('create', 'update', 'transfer', 'revert') NOT NULL,
+    `actor_id` INT UNSIGNED NOT NULL,
+    `reverted_to` INT UNSIGNED NULL,
+    `changes` JSON NOT NULL,
+    `snapshot` JSON NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`version_id`),
+    UNIQUE (`lot_id`, `number`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
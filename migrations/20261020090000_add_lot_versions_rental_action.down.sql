@@ -0,0 +1,3 @@
+DELETE FROM `lot_versions` WHERE `action`='rental';
+ALTER TABLE `lot_versions`
+    MODIFY COLUMN `action` ENUM('create', 'update', 'transfer', 'revert') NOT NULL;
@@ -0,0 +1,3 @@
+-- changes of rental modes of lots are kept as versions too
+ALTER TABLE `lot_versions`
+    MODIFY COLUMN `action` ENUM('create', 'update', 'transfer', 'revert', 'rental') NOT NULL;
@@ -0,0 +1,16 @@
+-- contact reveals reported by api_service, so analytics of lots don't read contact_reveals of user_service
+CREATE TABLE `lot_reveals` (
+    `reveal_id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `lot_id` INT UNSIGNED NOT NULL,
+    `viewer_id` INT UNSIGNED NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`reveal_id`),
+    INDEX (`lot_id`, `created_at`),
+    FOREIGN KEY (`lot_id`) REFERENCES lots(lot_id) ON DELETE CASCADE
+    ) ENGINE = InnoDB;
+
+-- reveals made before they were reported
+INSERT INTO `lot_reveals` (`lot_id`, `viewer_id`, `created_at`)
+SELECT r.`lot_id`, r.`viewer_id`, r.`created_at`
+FROM `contact_reveals` r
+JOIN `lots` l ON l.`lot_id`=r.`lot_id`;
@@ -0,0 +1,3 @@
+-- last accepted time step of TOTP codes, codes of this and earlier steps can't be used again
+ALTER TABLE `users`
+    ADD COLUMN `totp_step` BIGINT UNSIGNED NULL DEFAULT NULL AFTER `totp_enabled_at`;
@@ -8,10 +8,13 @@ import (
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/handlers"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/user"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/user/db"
+	"github.com/levelord1311/backendForSharedProject/user_service/pkg/limiter"
 	"github.com/levelord1311/backendForSharedProject/user_service/pkg/logging"
 	"github.com/levelord1311/backendForSharedProject/user_service/pkg/metric"
 	"github.com/levelord1311/backendForSharedProject/user_service/pkg/mysql"
+	"github.com/levelord1311/backendForSharedProject/user_service/pkg/passhash"
 	"github.com/levelord1311/backendForSharedProject/user_service/pkg/shutdown"
+	"github.com/levelord1311/backendForSharedProject/user_service/pkg/sms"
 	"net"
 	"net/http"
 	"os"
@@ -47,8 +50,59 @@ func main() {
 		logger.Fatalln(err)
 	}
 
+	smsSender, err := sms.NewSender(cfg.SMS.Sender, cfg.SMS.FilePath, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	attemptsStore, err := limiter.NewStore(cfg.SignInLimits.Store, mysqlClient)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	signInLimits := limiter.Config{
+		FreeAttempts:    cfg.SignInLimits.FreeAttempts,
+		BaseDelay:       cfg.SignInLimits.BaseDelay,
+		MaxDelay:        cfg.SignInLimits.MaxDelay,
+		LockoutDuration: cfg.SignInLimits.LockoutDuration,
+		Window:          cfg.SignInLimits.Window,
+	}
+	accountLimits, ipLimits := signInLimits, signInLimits
+	accountLimits.MaxAttempts = cfg.SignInLimits.AccountMaxAttempts
+	ipLimits.MaxAttempts = cfg.SignInLimits.IPMaxAttempts
+
+	codeLimits := limiter.Config{
+		FreeAttempts:    cfg.Phone.CodeLimits.FreeAttempts,
+		BaseDelay:       cfg.Phone.CodeLimits.BaseDelay,
+		MaxDelay:        cfg.Phone.CodeLimits.MaxDelay,
+		LockoutDuration: cfg.Phone.CodeLimits.LockoutDuration,
+		Window:          cfg.Phone.CodeLimits.Window,
+	}
+	userCodeLimits, phoneCodeLimits := codeLimits, codeLimits
+	userCodeLimits.MaxAttempts = cfg.Phone.CodeLimits.UserMaxAttempts
+	phoneCodeLimits.MaxAttempts = cfg.Phone.CodeLimits.PhoneMaxAttempts
+
+	hasher, err := passhash.New(passhash.Config{
+		Algorithm:  cfg.PasswordHashing.Algorithm,
+		BcryptCost: cfg.PasswordHashing.BcryptCost,
+		Argon2id:   passhash.Argon2idParams(cfg.PasswordHashing.Argon2id),
+	})
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
 	userStorage := db.NewStorage(mysqlClient, logger)
-	userService, err := user.NewService(userStorage, logger)
+	userService, err := user.NewService(userStorage, smsSender, attemptsStore, hasher, user.Config{
+		PhoneCodeTTL:         cfg.Phone.CodeTTL,
+		PhoneCodeMaxAttempts: cfg.Phone.CodeMaxAttempts,
+		DailyRevealLimit:     cfg.Phone.DailyRevealLimit,
+		TOTPIssuer:           cfg.TwoFactor.Issuer,
+		RecoveryCodesCount:   cfg.TwoFactor.RecoveryCodesCount,
+		AccountLimits:        accountLimits,
+		IPLimits:             ipLimits,
+		UserCodeLimits:       userCodeLimits,
+		PhoneCodeLimits:      phoneCodeLimits,
+	}, logger)
 	if err != nil {
 		logger.Fatalln(err)
 	}
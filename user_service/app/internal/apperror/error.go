@@ -8,6 +8,16 @@ const (
 	ErrInvalidJSONScheme     AppError = "invalid JSON scheme. check swagger API"
 	ErrAllFieldsMustBeFilled AppError = "all fields must be filled"
 	ErrWrongCredentials      AppError = "wrong login and/or password"
+	ErrNoPendingVerification AppError = "no pending phone verification"
+	ErrWrongCode             AppError = "wrong verification code"
+	ErrCodeExpired           AppError = "verification code expired"
+	ErrTooManyAttempts       AppError = "too many attempts, request a new code"
+	ErrPhoneNotVerified      AppError = "phone number must be verified first"
+	ErrContactNotAvailable   AppError = "owner has no verified contact"
+	ErrRevealLimitExceeded   AppError = "daily limit of contact reveals exceeded"
+	ErrTwoFactorEnabled      AppError = "two-factor authentication is already enabled"
+	ErrTwoFactorNotEnrolled  AppError = "two-factor authentication enrollment is not started"
+	ErrTwoFactorDisabled     AppError = "two-factor authentication is not enabled"
 )
 
 func (e AppError) Error() string {
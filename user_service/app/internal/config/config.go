@@ -4,6 +4,7 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/levelord1311/backendForSharedProject/user_service/pkg/logging"
 	"sync"
+	"time"
 )
 
 type Config struct {
@@ -21,6 +22,55 @@ type Config struct {
 		Password string `yaml:"password" env-default:"testPassword"`
 		DBName   string `yaml:"db_name" env-default:"test_db"`
 	} `yaml:"MysqlDB"`
+
+	SMS struct {
+		Sender   string `yaml:"sender" env-default:"log"` // log or file
+		FilePath string `yaml:"file_path" env-default:"logs/sms.log"`
+	} `yaml:"sms"`
+
+	Phone struct {
+		CodeTTL          time.Duration `yaml:"code_ttl" env-default:"10m"`
+		CodeMaxAttempts  int           `yaml:"code_max_attempts" env-default:"5"`
+		DailyRevealLimit int           `yaml:"daily_reveal_limit" env-default:"20"`
+		// CodeLimits throttle sending of verification codes, every sent code counts as an attempt.
+		CodeLimits struct {
+			FreeAttempts     int           `yaml:"free_attempts" env-default:"0"`
+			BaseDelay        time.Duration `yaml:"base_delay" env-default:"1m"`
+			MaxDelay         time.Duration `yaml:"max_delay" env-default:"1h"`
+			UserMaxAttempts  int           `yaml:"user_max_attempts" env-default:"10"`
+			PhoneMaxAttempts int           `yaml:"phone_max_attempts" env-default:"5"`
+			LockoutDuration  time.Duration `yaml:"lockout_duration" env-default:"24h"`
+			Window           time.Duration `yaml:"window" env-default:"24h"`
+		} `yaml:"code_limits"`
+	} `yaml:"phone"`
+
+	TwoFactor struct {
+		Issuer             string `yaml:"issuer" env-default:"backendForSharedProject"`
+		RecoveryCodesCount int    `yaml:"recovery_codes_count" env-default:"10"`
+	} `yaml:"two_factor"`
+
+	PasswordHashing struct {
+		Algorithm  string `yaml:"algorithm" env-default:"bcrypt"` // bcrypt or argon2id
+		BcryptCost int    `yaml:"bcrypt_cost" env-default:"12"`
+		Argon2id   struct {
+			Time    uint32 `yaml:"time" env-default:"3"`
+			Memory  uint32 `yaml:"memory" env-default:"65536"` // in KiB
+			Threads uint8  `yaml:"threads" env-default:"2"`
+			SaltLen uint32 `yaml:"salt_len" env-default:"16"`
+			KeyLen  uint32 `yaml:"key_len" env-default:"32"`
+		} `yaml:"argon2id"`
+	} `yaml:"password_hashing"`
+
+	SignInLimits struct {
+		Store              string        `yaml:"store" env-default:"memory"` // memory or mysql, use mysql to share attempts between instances
+		FreeAttempts       int           `yaml:"free_attempts" env-default:"3"`
+		BaseDelay          time.Duration `yaml:"base_delay" env-default:"1s"`
+		MaxDelay           time.Duration `yaml:"max_delay" env-default:"1m"`
+		AccountMaxAttempts int           `yaml:"account_max_attempts" env-default:"10"`
+		IPMaxAttempts      int           `yaml:"ip_max_attempts" env-default:"50"`
+		LockoutDuration    time.Duration `yaml:"lockout_duration" env-default:"15m"`
+		Window             time.Duration `yaml:"window" env-default:"1h"`
+	} `yaml:"sign_in_limits"`
 }
 
 var instance *Config
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// secretUser has every sensitive field filled, so that any leak is visible in the response body.
+var secretUser = &models.User{
+	ID:                42,
+	Username:          "landlord",
+	Email:             "private@email.org",
+	Password:          "plainPassword",
+	EncryptedPassword: "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+	GivenName:         "Name",
+	FamilyName:        "Surname",
+	Role:              models.RoleUser,
+	Phone:             "+79001234567",
+	PhoneVerifiedAt:   time.Date(2022, 11, 9, 13, 0, 0, 0, time.UTC),
+	TOTPSecret:        "JBSWY3DPEHPK3PXP",
+	TOTPEnabledAt:     time.Date(2022, 11, 9, 14, 0, 0, 0, time.UTC),
+	CreatedAt:         time.Date(2022, 11, 9, 12, 0, 0, 0, time.UTC),
+	RedactedAt:        time.Date(2022, 11, 10, 12, 0, 0, 0, time.UTC),
+}
+
+type secretService struct {
+	stubService
+}
+
+func (s *secretService) GetByID(ctx context.Context, id int) (*models.User, error) {
+	u := *secretUser
+	return &u, nil
+}
+
+func (s *secretService) Create(ctx context.Context, dto *models.CreateUserDTO) (uint, error) {
+	return secretUser.ID, nil
+}
+
+func (s *secretService) SignIn(ctx context.Context, dto *models.SignInUserDTO) (*models.User, error) {
+	u := *secretUser
+	return &u, nil
+}
+
+// credentials must never be serialized on any endpoint.
+var credentials = []string{
+	"password",
+	secretUser.Password,
+	secretUser.EncryptedPassword,
+	"$2a$",
+	secretUser.TOTPSecret,
+	"totp",
+}
+
+// privateFields are serialized only to the user themself and to admins.
+var privateFields = []string{
+	"email",
+	secretUser.Email,
+	"phone",
+	secretUser.Phone,
+	"role",
+	"redacted_at",
+	"two_factor_enabled",
+}
+
+func TestContract_GetUser(t *testing.T) {
+	h := NewHandler(&secretService{})
+	router := httprouter.New()
+	router.HandlerFunc(http.MethodGet, singleUserURL, h.GetUser)
+
+	cases := []struct {
+		name          string
+		requesterID   string
+		requesterRole string
+		wantPrivate   bool
+	}{
+		{
+			name:        "anonymous requester",
+			wantPrivate: false,
+		},
+		{
+			name:        "another user",
+			requesterID: "7",
+			wantPrivate: false,
+		},
+		{
+			name:        "malformed requester id",
+			requesterID: "42abc",
+			wantPrivate: false,
+		},
+		{
+			name:          "unknown role",
+			requesterID:   "7",
+			requesterRole: "superuser",
+			wantPrivate:   false,
+		},
+		{
+			name:        "user themself",
+			requesterID: "42",
+			wantPrivate: true,
+		},
+		{
+			name:          "admin",
+			requesterID:   "1",
+			requesterRole: models.RoleAdmin,
+			wantPrivate:   true,
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%d", usersURL, secretUser.ID), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.requesterID != "" {
+				request.Header.Set(requesterIDHeader, test.requesterID)
+			}
+			if test.requesterRole != "" {
+				request.Header.Set(requesterRoleHeader, test.requesterRole)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, request)
+
+			body := readBody(t, w)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assertNoCredentials(t, body)
+
+			for _, field := range privateFields {
+				assert.Equal(t, test.wantPrivate, strings.Contains(body, field), "field %q", field)
+			}
+		})
+	}
+}
+
+func TestContract_SignIn(t *testing.T) {
+	h := NewHandler(&secretService{})
+
+	rBody, err := json.Marshal(&models.SignInUserDTO{Login: secretUser.Username, Password: secretUser.Password})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := http.NewRequest(http.MethodPost, authURL, bytes.NewBuffer(rBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.SignIn(w, request)
+
+	body := readBody(t, w)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assertNoCredentials(t, body)
+}
+
+func TestContract_UserMarshal(t *testing.T) {
+	// even if someone marshals internal model by mistake, nothing must leak
+	b, err := json.Marshal(secretUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "{}", string(b))
+}
+
+func assertNoCredentials(t *testing.T, body string) {
+	t.Helper()
+	for _, c := range credentials {
+		assert.NotContains(t, body, c)
+	}
+}
+
+func readBody(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	response := w.Result()
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
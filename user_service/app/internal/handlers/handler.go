@@ -3,24 +3,49 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/julienschmidt/httprouter"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/apperror"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
+	"github.com/levelord1311/backendForSharedProject/user_service/pkg/limiter"
+	"math"
 	"net/http"
 	"strconv"
 )
 
 const (
-	usersURL      = "/api/users"
-	singleUserURL = "/api/users/:id"
-	authURL       = "/api/users/auth"
+	usersURL       = "/api/users"
+	singleUserURL  = "/api/users/:id"
+	authURL        = "/api/users/auth"
+	phoneURL       = "/api/users/:id/phone"
+	verifyURL      = "/api/users/:id/phone/verification"
+	contactsURL    = "/api/contacts"
+	totpURL        = "/api/users/:id/totp"
+	totpConfirmURL = "/api/users/:id/totp/confirmation"
+	totpAuthURL    = "/api/users/auth/totp"
+	lockURL        = "/api/users/:id/lock"
+
+	// requester headers are set by api_service from JWT claims of the user making the request
+	requesterIDHeader   = "X-Requester-ID"
+	requesterRoleHeader = "X-Requester-Role"
+	// clientIPHeader is set by api_service to IP of the client signing in
+	clientIPHeader = "X-Real-IP"
 )
 
 type Service interface {
 	GetByID(ctx context.Context, id int) (*models.User, error)
 	Create(ctx context.Context, dto *models.CreateUserDTO) (uint, error)
 	SignIn(ctx context.Context, dto *models.SignInUserDTO) (*models.User, error)
+	SetPhone(ctx context.Context, userID uint, dto *models.SetPhoneDTO) error
+	VerifyPhone(ctx context.Context, userID uint, dto *models.VerifyPhoneDTO) error
+	RevealContact(ctx context.Context, dto *models.RevealContactDTO) (*models.Contact, error)
+	EnrollTOTP(ctx context.Context, userID uint) (*models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID uint, dto *models.TOTPCodeDTO) (*models.RecoveryCodes, error)
+	VerifyTOTP(ctx context.Context, dto *models.VerifyTOTPDTO) error
+	ResetTOTP(ctx context.Context, userID uint) error
+	Unlock(ctx context.Context, userID uint) error
 	//UpdatePassword(ctx context.Context, dto *models.UpdateUserDTO) error
 	//Delete(ctx context.Context, id string) error
 }
@@ -39,6 +64,14 @@ func (h *handler) Register(router *httprouter.Router) {
 	router.HandlerFunc(http.MethodGet, singleUserURL, h.GetUser)
 	router.HandlerFunc(http.MethodPost, usersURL, h.CreateUser)
 	router.HandlerFunc(http.MethodPost, authURL, h.SignIn)
+	router.HandlerFunc(http.MethodPut, phoneURL, h.SetPhone)
+	router.HandlerFunc(http.MethodPut, verifyURL, h.VerifyPhone)
+	router.HandlerFunc(http.MethodPost, contactsURL, h.RevealContact)
+	router.HandlerFunc(http.MethodPut, totpURL, h.EnrollTOTP)
+	router.HandlerFunc(http.MethodPut, totpConfirmURL, h.ConfirmTOTP)
+	router.HandlerFunc(http.MethodDelete, totpURL, h.ResetTOTP)
+	router.HandlerFunc(http.MethodPost, totpAuthURL, h.VerifyTOTP)
+	router.HandlerFunc(http.MethodDelete, lockURL, h.Unlock)
 	//router.HandlerFunc(http.MethodPatch, singleUserURL, h.PartiallyUpdateUser)
 	//router.HandlerFunc(http.MethodDelete, singleUserURL, h.DeleteUser)
 }
@@ -67,7 +100,8 @@ func (h *handler) GetUser(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	userBytes, err := json.Marshal(user)
+	visibility := models.VisibilityFor(user, requesterID(r), r.Header.Get(requesterRoleHeader))
+	userBytes, err := json.Marshal(user.View(visibility))
 	if err != nil {
 		writeError(w, err, http.StatusInternalServerError)
 		return
@@ -106,13 +140,15 @@ func (h *handler) SignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer r.Body.Close()
+	dto.IP = r.Header.Get(clientIPHeader)
 
 	user, err := h.service.SignIn(r.Context(), dto)
 	if err != nil {
-		writeError(w, err, http.StatusInternalServerError)
+		setRetryAfter(w, err)
+		writeError(w, err, errorStatus(err))
 		return
 	}
-	userBytes, err := json.Marshal(user)
+	userBytes, err := json.Marshal(user.View(models.VisibilitySelf))
 	if err != nil {
 		writeError(w, err, http.StatusInternalServerError)
 		return
@@ -123,6 +159,193 @@ func (h *handler) SignIn(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// SetPhone sends verification code to the new phone of the user.
+func (h *handler) SetPhone(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := userIDFromParams(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var dto *models.SetPhoneDTO
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, apperror.ErrInvalidJSONScheme, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.service.SetPhone(r.Context(), userID, dto); err != nil {
+		setRetryAfter(w, err)
+		writeError(w, err, errorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// VerifyPhone confirms phone of the user with the code received via SMS.
+func (h *handler) VerifyPhone(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := userIDFromParams(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var dto *models.VerifyPhoneDTO
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, apperror.ErrInvalidJSONScheme, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.service.VerifyPhone(r.Context(), userID, dto); err != nil {
+		writeError(w, err, errorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevealContact returns phone of the lot owner to the viewer. Every call is recorded in audit log.
+func (h *handler) RevealContact(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var dto *models.RevealContactDTO
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, apperror.ErrInvalidJSONScheme, http.StatusBadRequest)
+		return
+	}
+
+	contact, err := h.service.RevealContact(r.Context(), dto)
+	if err != nil {
+		writeError(w, err, errorStatus(err))
+		return
+	}
+
+	contactBytes, err := json.Marshal(contact)
+	if err != nil {
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(contactBytes)
+}
+
+// EnrollTOTP starts enabling of two-factor authentication and returns new secret.
+func (h *handler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := userIDFromParams(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	enrollment, err := h.service.EnrollTOTP(r.Context(), userID)
+	if err != nil {
+		writeError(w, err, errorStatus(err))
+		return
+	}
+
+	enrollmentBytes, err := json.Marshal(enrollment)
+	if err != nil {
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(enrollmentBytes)
+}
+
+// ConfirmTOTP enables two-factor authentication and returns recovery codes.
+func (h *handler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := userIDFromParams(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var dto *models.TOTPCodeDTO
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, apperror.ErrInvalidJSONScheme, http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.service.ConfirmTOTP(r.Context(), userID, dto)
+	if err != nil {
+		writeError(w, err, errorStatus(err))
+		return
+	}
+
+	codesBytes, err := json.Marshal(codes)
+	if err != nil {
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(codesBytes)
+}
+
+// VerifyTOTP checks the second factor on sign in.
+func (h *handler) VerifyTOTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var dto *models.VerifyTOTPDTO
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, apperror.ErrInvalidJSONScheme, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.VerifyTOTP(r.Context(), dto); err != nil {
+		writeError(w, err, errorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetTOTP disables two-factor authentication of the user.
+func (h *handler) ResetTOTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := userIDFromParams(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.service.ResetTOTP(r.Context(), userID); err != nil {
+		writeError(w, err, errorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unlock removes lockout of the user caused by failed sign in attempts.
+func (h *handler) Unlock(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := userIDFromParams(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.service.Unlock(r.Context(), userID); err != nil {
+		writeError(w, err, errorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 //func (h *handler) PartiallyUpdateUser(w http.ResponseWriter, r *http.Request) {
 //	h.Logger.Info("PARTIALLY UPDATE USER")
 //	w.Header().Set("Content-Type", "application/json")
@@ -169,6 +392,63 @@ func (h *handler) SignIn(w http.ResponseWriter, r *http.Request) {
 //	return nil
 //}
 
+func userIDFromParams(r *http.Request) (uint, error) {
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+	userID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || userID < 0 {
+		return 0, apperror.ErrCantConvertID
+	}
+	return uint(userID), nil
+}
+
+// errorStatus maps errors returned by service to status codes.
+func errorStatus(err error) int {
+	var validationErrs validation.Errors
+	var blocked *limiter.BlockedError
+	switch {
+	case errors.As(err, &validationErrs):
+		return http.StatusBadRequest
+	case errors.Is(err, apperror.ErrWrongCredentials):
+		return http.StatusUnauthorized
+	case errors.Is(err, apperror.ErrNotFound),
+		errors.Is(err, apperror.ErrNoPendingVerification),
+		errors.Is(err, apperror.ErrContactNotAvailable):
+		return http.StatusNotFound
+	case errors.Is(err, apperror.ErrWrongCode),
+		errors.Is(err, apperror.ErrCodeExpired),
+		errors.Is(err, apperror.ErrTwoFactorNotEnrolled),
+		errors.Is(err, apperror.ErrTwoFactorDisabled):
+		return http.StatusBadRequest
+	case errors.Is(err, apperror.ErrTwoFactorEnabled):
+		return http.StatusConflict
+	case errors.Is(err, apperror.ErrPhoneNotVerified):
+		return http.StatusForbidden
+	case errors.Is(err, apperror.ErrTooManyAttempts),
+		errors.Is(err, apperror.ErrRevealLimitExceeded),
+		errors.As(err, &blocked):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// setRetryAfter tells the client when to retry, if the request was blocked by limiter.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var blocked *limiter.BlockedError
+	if errors.As(err, &blocked) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	}
+}
+
+// requesterID returns ID of the user making the request or 0 for anonymous one.
+func requesterID(r *http.Request) uint {
+	id, err := strconv.Atoi(r.Header.Get(requesterIDHeader))
+	if err != nil || id < 0 {
+		return 0
+	}
+	return uint(id)
+}
+
 func writeError(w http.ResponseWriter, err error, statusCode int) {
 	w.WriteHeader(statusCode)
 	w.Write([]byte(err.Error()))
@@ -9,6 +9,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/apperror"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
+	"github.com/levelord1311/backendForSharedProject/user_service/pkg/limiter"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"net/http"
@@ -18,7 +19,7 @@ import (
 )
 
 var (
-	expectedModel     = &models.User{}
+	expectedModel     = &models.UserView{}
 	exampleUserReturn = &models.User{
 		ID:                1234,
 		Username:          "testUser",
@@ -27,6 +28,7 @@ var (
 		EncryptedPassword: "",
 		GivenName:         "Name",
 		FamilyName:        "Surname",
+		Role:              models.RoleUser,
 		CreatedAt:         time.Time{},
 		RedactedAt:        time.Time{},
 	}
@@ -58,6 +60,47 @@ func (s *stubService) SignIn(ctx context.Context, dto *models.SignInUserDTO) (*m
 	return exampleUserReturn, nil
 }
 
+func (s *stubService) SetPhone(ctx context.Context, userID uint, dto *models.SetPhoneDTO) error {
+	return s.err
+}
+
+func (s *stubService) VerifyPhone(ctx context.Context, userID uint, dto *models.VerifyPhoneDTO) error {
+	return s.err
+}
+
+func (s *stubService) RevealContact(ctx context.Context, dto *models.RevealContactDTO) (*models.Contact, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.Contact{UserID: exampleUserReturn.ID, Phone: "+79001234567"}, nil
+}
+
+func (s *stubService) EnrollTOTP(ctx context.Context, userID uint) (*models.TOTPEnrollment, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.TOTPEnrollment{}, nil
+}
+
+func (s *stubService) ConfirmTOTP(ctx context.Context, userID uint, dto *models.TOTPCodeDTO) (*models.RecoveryCodes, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.RecoveryCodes{}, nil
+}
+
+func (s *stubService) VerifyTOTP(ctx context.Context, dto *models.VerifyTOTPDTO) error {
+	return s.err
+}
+
+func (s *stubService) ResetTOTP(ctx context.Context, userID uint) error {
+	return s.err
+}
+
+func (s *stubService) Unlock(ctx context.Context, userID uint) error {
+	return s.err
+}
+
 func TestHandler_GetUser(t *testing.T) {
 
 	h := NewHandler(nil)
@@ -135,7 +178,7 @@ func TestHandler_GetUser(t *testing.T) {
 				return
 			}
 
-			receivedUser := &models.User{}
+			receivedUser := &models.UserView{}
 			switch test.wantStatusCode {
 			case http.StatusNotFound:
 				assert.Equal(t, expectedModel, receivedUser)
@@ -144,7 +187,7 @@ func TestHandler_GetUser(t *testing.T) {
 				if err != nil {
 					t.Fatal(err)
 				}
-				assert.Equal(t, exampleUserReturn, receivedUser)
+				assert.Equal(t, exampleUserReturn.View(models.VisibilityPublic), receivedUser)
 			}
 
 		})
@@ -285,6 +328,24 @@ func TestHandler_SignIn(t *testing.T) {
 			wantStatusCode: http.StatusInternalServerError,
 			serviceErr:     ServiceErr,
 		},
+		{
+			name: "wrong credentials",
+			requestBody: &models.SignInUserDTO{
+				Login:    "someLogin",
+				Password: "wrongPassword",
+			},
+			wantStatusCode: http.StatusUnauthorized,
+			serviceErr:     apperror.ErrWrongCredentials,
+		},
+		{
+			name: "too many attempts",
+			requestBody: &models.SignInUserDTO{
+				Login:    "someLogin",
+				Password: "somePassword",
+			},
+			wantStatusCode: http.StatusTooManyRequests,
+			serviceErr:     &limiter.BlockedError{RetryAfter: 1500 * time.Millisecond},
+		},
 	}
 
 	for _, test := range cases {
@@ -320,17 +381,68 @@ func TestHandler_SignIn(t *testing.T) {
 
 			if test.serviceErr != nil {
 				assert.Equal(t, test.serviceErr.Error(), string(body))
+				if test.wantStatusCode == http.StatusTooManyRequests {
+					assert.Equal(t, "2", response.Header.Get("Retry-After"))
+				}
 				return
 			}
 
-			receivedUser := &models.User{}
+			receivedUser := &models.UserView{}
 			err = json.Unmarshal(body, receivedUser)
 			if err != nil {
 				t.Fatal(err)
 			}
-			assert.Equal(t, exampleUserReturn, receivedUser)
+			assert.Equal(t, exampleUserReturn.View(models.VisibilitySelf), receivedUser)
 
 		})
 	}
 
 }
+
+func TestHandler_SetPhone(t *testing.T) {
+
+	h := NewHandler(nil)
+	router := httprouter.New()
+	router.HandlerFunc(http.MethodPut, phoneURL, h.SetPhone)
+
+	cases := []struct {
+		name           string
+		wantStatusCode int
+		retryAfter     string
+		serviceErr     error
+	}{
+		{
+			name:           "code is sent",
+			wantStatusCode: http.StatusAccepted,
+		},
+		{
+			name:           "code was sent recently",
+			wantStatusCode: http.StatusTooManyRequests,
+			retryAfter:     "60",
+			serviceErr: fmt.Errorf("verification code was sent recently. error: %w",
+				&limiter.BlockedError{RetryAfter: time.Minute}),
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+
+			h.service = &stubService{err: test.serviceErr}
+
+			w := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/1/phone", usersURL),
+				bytes.NewBufferString(`{"phone": "+79001234567"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			router.ServeHTTP(w, request)
+
+			response := w.Result()
+			defer response.Body.Close()
+
+			assert.Equal(t, test.wantStatusCode, response.StatusCode)
+			assert.Equal(t, test.retryAfter, response.Header.Get("Retry-After"))
+		})
+	}
+}
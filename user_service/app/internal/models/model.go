@@ -3,20 +3,40 @@ package models
 import (
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/levelord1311/backendForSharedProject/user_service/pkg/passhash"
 	"time"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// User is the internal representation of a user. It must never be marshalled into a response directly,
+// use View to get a representation suitable for the requester.
 type User struct {
-	ID                uint      `json:"id"`
-	Username          string    `json:"username"`
-	Email             string    `json:"email"`
-	Password          string    `json:"password,omitempty"`
+	ID                uint      `json:"-"`
+	Username          string    `json:"-"`
+	Email             string    `json:"-"`
+	Password          string    `json:"-"`
 	EncryptedPassword string    `json:"-"`
-	GivenName         string    `json:"given_name"`
-	FamilyName        string    `json:"family_name"`
-	CreatedAt         time.Time `json:"created_at"`
-	RedactedAt        time.Time `json:"redacted_at"`
+	GivenName         string    `json:"-"`
+	FamilyName        string    `json:"-"`
+	Role              string    `json:"-"`
+	Phone             string    `json:"-"`
+	PhoneVerifiedAt   time.Time `json:"-"` // zero if phone is not verified
+	TOTPSecret        string    `json:"-"`
+	TOTPEnabledAt     time.Time `json:"-"` // zero until enrollment is confirmed with the first code
+	CreatedAt         time.Time `json:"-"`
+	RedactedAt        time.Time `json:"-"`
+}
+
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPSecret != "" && !u.TOTPEnabledAt.IsZero()
+}
+
+func (u *User) PhoneVerified() bool {
+	return u.Phone != "" && !u.PhoneVerifiedAt.IsZero()
 }
 
 func (u *User) ValidateFields() error {
@@ -46,6 +66,7 @@ type UpdateUserDTO struct {
 type SignInUserDTO struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
+	IP       string `json:"-"` // IP of the client, set from request header
 }
 
 func NewUser(dto *CreateUserDTO) *User {
@@ -53,6 +74,7 @@ func NewUser(dto *CreateUserDTO) *User {
 		Username: dto.Username,
 		Email:    dto.Email,
 		Password: dto.Password,
+		Role:     RoleUser,
 	}
 }
 
@@ -68,9 +90,9 @@ func (u *User) Sanitize() {
 	u.Password = ""
 }
 
-func (u *User) EncryptPassword() error {
+func (u *User) EncryptPassword(hasher passhash.Hasher) error {
 	if len(u.Password) > 0 {
-		enc, err := encryptString(u.Password)
+		enc, err := hasher.Hash(u.Password)
 		if err != nil {
 			return err
 		}
@@ -80,8 +102,8 @@ func (u *User) EncryptPassword() error {
 	return nil
 }
 
-func (u *User) ComparePassword(password string) bool {
-	return bcrypt.CompareHashAndPassword([]byte(u.EncryptedPassword), []byte(password)) == nil
+func (u *User) ComparePassword(hasher passhash.Hasher, password string) (bool, error) {
+	return hasher.Compare(u.EncryptedPassword, password)
 }
 
 func (u *User) RemoveEncryptedPassword() {
@@ -97,14 +119,6 @@ func requiredIf(cond bool) validation.RuleFunc {
 	}
 }
 
-func encryptString(s string) (string, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(s), bcrypt.MinCost)
-	if err != nil {
-		return "", err
-	}
-	return string(b), nil
-}
-
 func (dto *UpdateUserDTO) ValidateFields() error {
 	return validation.ValidateStruct(dto,
 		validation.Field(&dto.OldPassword, validation.Required),
@@ -0,0 +1,79 @@
+package models
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// phoneRegexp matches phone numbers in E.164 format
+var phoneRegexp = regexp.MustCompile(`^\+[1-9]\d{9,14}$`)
+
+// PhoneVerification is a pending confirmation of a phone number by one-time code sent via SMS.
+type PhoneVerification struct {
+	UserID    uint
+	Phone     string
+	CodeHash  string
+	Attempts  int
+	ExpiresAt time.Time
+}
+
+type SetPhoneDTO struct {
+	Phone string `json:"phone"`
+}
+
+type VerifyPhoneDTO struct {
+	Code string `json:"code"`
+}
+
+// ContactReveal is an audit record of the viewer getting contact data of the lot owner.
+type ContactReveal struct {
+	ID        uint
+	ViewerID  uint
+	OwnerID   uint
+	LotID     uint
+	CreatedAt time.Time
+}
+
+type RevealContactDTO struct {
+	ViewerID uint `json:"viewer_id"`
+	OwnerID  uint `json:"owner_id"`
+	LotID    uint `json:"lot_id"`
+}
+
+type Contact struct {
+	UserID uint   `json:"user_id"`
+	Phone  string `json:"phone"`
+}
+
+// NormalizePhone removes formatting symbols from phone number, so "+7 (900) 123-45-67" becomes "+79001234567".
+func NormalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+}
+
+func (dto *SetPhoneDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.Phone, validation.Required, validation.Match(phoneRegexp)),
+	)
+}
+
+func (dto *VerifyPhoneDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.Code, validation.Required),
+	)
+}
+
+func (dto *RevealContactDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.ViewerID, validation.Required),
+		validation.Field(&dto.OwnerID, validation.Required),
+		validation.Field(&dto.LotID, validation.Required),
+	)
+}
@@ -0,0 +1,42 @@
+package models
+
+import validation "github.com/go-ozzo/ozzo-validation"
+
+// TOTPEnrollment is returned once, when the user starts enabling two-factor authentication.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+	QRCode []byte `json:"qr_code"` // PNG image of URI
+}
+
+type RecoveryCode struct {
+	ID       uint
+	UserID   uint
+	CodeHash string
+}
+
+type TOTPCodeDTO struct {
+	Code string `json:"code"`
+}
+
+type VerifyTOTPDTO struct {
+	UserID uint   `json:"user_id"`
+	Code   string `json:"code"` // either TOTP code or one of recovery codes
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+func (dto *TOTPCodeDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.Code, validation.Required),
+	)
+}
+
+func (dto *VerifyTOTPDTO) ValidateFields() error {
+	return validation.ValidateStruct(dto,
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Code, validation.Required),
+	)
+}
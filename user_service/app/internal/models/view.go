@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Visibility defines which fields of a user are shown to the requester.
+// Each level includes all the fields of the levels below it.
+type Visibility int
+
+const (
+	VisibilityPublic Visibility = iota
+	VisibilitySelf
+	VisibilityAdmin
+)
+
+// UserView is the only representation of a user that is allowed to leave the service.
+// Fields with omitempty are private and filled only for VisibilitySelf and higher.
+type UserView struct {
+	ID            uint       `json:"id"`
+	Username      string     `json:"username"`
+	GivenName     string     `json:"given_name"`
+	FamilyName    string     `json:"family_name"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Email         string     `json:"email,omitempty"`
+	Phone         string     `json:"phone,omitempty"`
+	PhoneVerified *bool      `json:"phone_verified,omitempty"`
+	TwoFactor     *bool      `json:"two_factor_enabled,omitempty"`
+	Role          string     `json:"role,omitempty"`
+	RedactedAt    *time.Time `json:"redacted_at,omitempty"`
+}
+
+// VisibilityFor returns the visibility level of the user for the requester.
+// Zero requesterID means anonymous request.
+func VisibilityFor(u *User, requesterID uint, requesterRole string) Visibility {
+	switch {
+	case requesterRole == RoleAdmin:
+		return VisibilityAdmin
+	case requesterID != 0 && requesterID == u.ID:
+		return VisibilitySelf
+	default:
+		return VisibilityPublic
+	}
+}
+
+// View builds representation of the user according to visibility level.
+// Passwords and hashes are never a part of it.
+func (u *User) View(v Visibility) *UserView {
+	view := &UserView{
+		ID:         u.ID,
+		Username:   u.Username,
+		GivenName:  u.GivenName,
+		FamilyName: u.FamilyName,
+		CreatedAt:  u.CreatedAt,
+	}
+
+	if v >= VisibilitySelf {
+		redactedAt := u.RedactedAt
+		phoneVerified := u.PhoneVerified()
+		twoFactor := u.TwoFactorEnabled()
+		view.Email = u.Email
+		view.Phone = u.Phone
+		view.PhoneVerified = &phoneVerified
+		view.TwoFactor = &twoFactor
+		view.Role = u.Role
+		view.RedactedAt = &redactedAt
+	}
+
+	return view
+}
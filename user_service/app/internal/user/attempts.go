@@ -0,0 +1,66 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
+	"strconv"
+)
+
+func accountAttemptsKey(userID uint) string {
+	return "account:" + strconv.Itoa(int(userID))
+}
+
+func ipAttemptsKey(ip string) string {
+	return "ip:" + ip
+}
+
+// registerFailure counts failed sign in attempt for the IP and, if the user is known, for the account.
+// Errors are only logged: the attempt has failed anyway.
+func (s *service) registerFailure(ctx context.Context, u *models.User, ip string) {
+	if ip != "" {
+		if _, err := s.ipLimiter.Fail(ctx, ipAttemptsKey(ip)); err != nil {
+			s.logger.Errorf("failed to register sign in attempt from %s. error: %v", ip, err)
+		}
+	}
+
+	if u == nil {
+		return
+	}
+
+	locked, err := s.accountLimiter.Fail(ctx, accountAttemptsKey(u.ID))
+	if err != nil {
+		s.logger.Errorf("failed to register sign in attempt of user %d. error: %v", u.ID, err)
+		return
+	}
+	if locked {
+		s.notifyLockout(ctx, u)
+	}
+}
+
+// notifyLockout tells the owner that the account has been locked. Only verified phone can be used for now.
+func (s *service) notifyLockout(ctx context.Context, u *models.User) {
+	s.logger.Warnf("user %d is locked after too many failed sign in attempts", u.ID)
+
+	if !u.PhoneVerified() {
+		return
+	}
+
+	text := fmt.Sprintf("Your account has been locked for %s after too many failed sign in attempts. "+
+		"If it wasn't you, change your password.", s.cfg.AccountLimits.LockoutDuration)
+	if err := s.smsSender.Send(ctx, u.Phone, text); err != nil {
+		s.logger.Errorf("failed to notify user %d about lockout. error: %v", u.ID, err)
+	}
+}
+
+// Unlock resets failed sign in attempts of the user, which also removes the lockout.
+func (s *service) Unlock(ctx context.Context, userID uint) error {
+	if _, err := s.storage.FindByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.accountLimiter.Reset(ctx, accountAttemptsKey(userID)); err != nil {
+		return fmt.Errorf("failed to unlock user. error: %w", err)
+	}
+	return nil
+}
@@ -5,9 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/apperror"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/user"
-	"github.com/levelord1311/backendForSharedProject/user_service/pkg/apperror"
 	"github.com/levelord1311/backendForSharedProject/user_service/pkg/logging"
 	"time"
 )
@@ -35,8 +35,8 @@ func (t *rawTime) time() (time.Time, error) {
 func (s *db) Create(ctx context.Context, u *models.User) (uint, error) {
 
 	queryString := `
-	INSERT INTO users (username, email, encrypted_password)
-	VALUES (?, ?, ?);`
+	INSERT INTO users (username, email, encrypted_password, role)
+	VALUES (?, ?, ?, ?);`
 
 	stmt, err := s.db.PrepareContext(ctx, queryString)
 	if err != nil {
@@ -44,7 +44,7 @@ func (s *db) Create(ctx context.Context, u *models.User) (uint, error) {
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, u.Username, u.Email, u.EncryptedPassword)
+	res, err := stmt.ExecContext(ctx, u.Username, u.Email, u.EncryptedPassword, u.Role)
 	if err != nil {
 		return 0, err
 	}
@@ -56,110 +56,61 @@ func (s *db) Create(ctx context.Context, u *models.User) (uint, error) {
 	return uint(retID), nil
 }
 
-func (s *db) FindByEmail(ctx context.Context, email string) (*models.User, error) {
-	u := &models.User{}
-	var createdAt, redactedAt *rawTime
-
-	queryString := `
-	SELECT user_id, username, email, encrypted_password,
+const userColumns = `
+	user_id, username, email, encrypted_password,
 	IFNULL(given_name, ""),
 	IFNULL(family_name, ""),
-	created_at, redacted_at
+	role,
+	IFNULL(phone, ""),
+	phone_verified_at,
+	IFNULL(totp_secret, ""),
+	totp_enabled_at,
+	created_at, redacted_at`
+
+func (s *db) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	queryString := `
+	SELECT` + userColumns + `
 	FROM users 
 	WHERE email=?;`
 
-	err := s.db.QueryRowContext(ctx, queryString, email).Scan(
-		&u.ID,
-		&u.Username,
-		&u.Email,
-		&u.EncryptedPassword,
-		&u.GivenName,
-		&u.FamilyName,
-		&createdAt,
-		&redactedAt,
-	)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, apperror.ErrNotFound
-		}
-		return nil, err
-	}
-
-	u.CreatedAt, err = createdAt.time()
-	if err != nil {
-		return nil, err
-	}
-
-	u.RedactedAt, err = redactedAt.time()
-	if err != nil {
-		return nil, err
-	}
-
-	return u, nil
+	return s.scanUser(s.db.QueryRowContext(ctx, queryString, email))
 }
 
 func (s *db) FindByUsername(ctx context.Context, username string) (*models.User, error) {
-	u := &models.User{}
-	var createdAt, redactedAt *rawTime
-
 	queryString := `
-	SELECT user_id, username, email, encrypted_password,
-	IFNULL(given_name, ""),
-	IFNULL(family_name, ""),
-	created_at, redacted_at
+	SELECT` + userColumns + `
 	FROM users 
 	WHERE username=?;`
 
-	err := s.db.QueryRowContext(ctx, queryString, username).Scan(
-		&u.ID,
-		&u.Username,
-		&u.Email,
-		&u.EncryptedPassword,
-		&u.GivenName,
-		&u.FamilyName,
-		&createdAt,
-		&redactedAt,
-	)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, apperror.ErrNotFound
-		}
-		return nil, err
-	}
-
-	u.CreatedAt, err = createdAt.time()
-	if err != nil {
-		return nil, err
-	}
-
-	u.RedactedAt, err = redactedAt.time()
-	if err != nil {
-		return nil, err
-	}
-
-	return u, nil
+	return s.scanUser(s.db.QueryRowContext(ctx, queryString, username))
 }
 
 func (s *db) FindByID(ctx context.Context, id uint) (*models.User, error) {
-
-	u := &models.User{}
-	var createdAt, redactedAt *rawTime
-
 	queryString := `
-	SELECT user_id, username, email, encrypted_password,
-	IFNULL(given_name, ""),
-	IFNULL(family_name, ""),
-	created_at, redacted_at
+	SELECT` + userColumns + `
 	FROM users 
 	WHERE user_id=?;`
 
-	err := s.db.QueryRowContext(ctx, queryString, id).Scan(
+	return s.scanUser(s.db.QueryRowContext(ctx, queryString, id))
+}
+
+// scanUser scans row selected with userColumns.
+func (s *db) scanUser(row *sql.Row) (*models.User, error) {
+	u := &models.User{}
+	var createdAt, redactedAt, phoneVerifiedAt, totpEnabledAt *rawTime
+
+	err := row.Scan(
 		&u.ID,
 		&u.Username,
 		&u.Email,
 		&u.EncryptedPassword,
 		&u.GivenName,
 		&u.FamilyName,
+		&u.Role,
+		&u.Phone,
+		&phoneVerifiedAt,
+		&u.TOTPSecret,
+		&totpEnabledAt,
 		&createdAt,
 		&redactedAt,
 	)
@@ -180,6 +131,20 @@ func (s *db) FindByID(ctx context.Context, id uint) (*models.User, error) {
 		return nil, err
 	}
 
+	if phoneVerifiedAt != nil {
+		u.PhoneVerifiedAt, err = phoneVerifiedAt.time()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if totpEnabledAt != nil {
+		u.TOTPEnabledAt, err = totpEnabledAt.time()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return u, nil
 }
 
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
+	"time"
+)
+
+func (s *db) SetVerifiedPhone(ctx context.Context, userID uint, phone string) error {
+	queryString := `
+	UPDATE users
+	SET phone=?, phone_verified_at=CURRENT_TIMESTAMP
+	WHERE user_id=?;`
+
+	res, err := s.db.ExecContext(ctx, queryString, phone, userID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+// SavePhoneVerification replaces pending verification of the user, if there is any.
+func (s *db) SavePhoneVerification(ctx context.Context, v *models.PhoneVerification) error {
+	queryString := `
+	REPLACE INTO phone_verifications (user_id, phone, code_hash, attempts, expires_at)
+	VALUES (?, ?, ?, ?, ?);`
+
+	_, err := s.db.ExecContext(ctx, queryString, v.UserID, v.Phone, v.CodeHash, v.Attempts, v.ExpiresAt.UTC())
+	return err
+}
+
+func (s *db) FindPhoneVerification(ctx context.Context, userID uint) (*models.PhoneVerification, error) {
+	v := &models.PhoneVerification{}
+	var expiresAt *rawTime
+
+	queryString := `
+	SELECT user_id, phone, code_hash, attempts, expires_at
+	FROM phone_verifications
+	WHERE user_id=?;`
+
+	err := s.db.QueryRowContext(ctx, queryString, userID).Scan(
+		&v.UserID,
+		&v.Phone,
+		&v.CodeHash,
+		&v.Attempts,
+		&expiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNoPendingVerification
+		}
+		return nil, err
+	}
+
+	v.ExpiresAt, err = expiresAt.time()
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *db) IncrementPhoneVerificationAttempts(ctx context.Context, userID uint) error {
+	queryString := `
+	UPDATE phone_verifications
+	SET attempts=attempts+1
+	WHERE user_id=?;`
+
+	_, err := s.db.ExecContext(ctx, queryString, userID)
+	return err
+}
+
+func (s *db) DeletePhoneVerification(ctx context.Context, userID uint) error {
+	queryString := `
+	DELETE
+	FROM phone_verifications
+	WHERE user_id=?;`
+
+	_, err := s.db.ExecContext(ctx, queryString, userID)
+	return err
+}
+
+// CreateContactReveal saves reveal of the contact unless the viewer has already revealed contacts
+// of limit distinct lots since given time. Repeated reveal of the same lot is not counted.
+// The viewer row is locked while counting, so concurrent reveals can't exceed the limit.
+func (s *db) CreateContactReveal(ctx context.Context, r *models.ContactReveal, since time.Time, limit int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var viewerID uint
+	err = tx.QueryRowContext(ctx, `
+	SELECT user_id
+	FROM users
+	WHERE user_id=?
+	FOR UPDATE;`, r.ViewerID).Scan(&viewerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperror.ErrNotFound
+		}
+		return err
+	}
+
+	revealed, err := contactRevealed(ctx, tx, r.ViewerID, r.LotID, since)
+	if err != nil {
+		return err
+	}
+	if !revealed {
+		count, err := countRevealedLots(ctx, tx, r.ViewerID, since)
+		if err != nil {
+			return err
+		}
+		if count >= limit {
+			return apperror.ErrRevealLimitExceeded
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO contact_reveals (viewer_id, owner_id, lot_id)
+	VALUES (?, ?, ?);`, r.ViewerID, r.OwnerID, r.LotID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// countRevealedLots returns number of distinct lots which contacts were revealed to the viewer since given time.
+func countRevealedLots(ctx context.Context, tx *sql.Tx, viewerID uint, since time.Time) (int, error) {
+	var count int
+
+	queryString := `
+	SELECT COUNT(DISTINCT lot_id)
+	FROM contact_reveals
+	WHERE viewer_id=? AND created_at>=?;`
+
+	err := tx.QueryRowContext(ctx, queryString, viewerID, since.UTC()).Scan(&count)
+	return count, err
+}
+
+func contactRevealed(ctx context.Context, tx *sql.Tx, viewerID, lotID uint, since time.Time) (bool, error) {
+	var exists bool
+
+	queryString := `
+	SELECT EXISTS(
+		SELECT 1
+		FROM contact_reveals
+		WHERE viewer_id=? AND lot_id=? AND created_at>=?
+	);`
+
+	err := tx.QueryRowContext(ctx, queryString, viewerID, lotID, since.UTC()).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
+)
+
+// SetTOTPSecret saves secret of not yet confirmed enrollment.
+func (s *db) SetTOTPSecret(ctx context.Context, userID uint, secret string) error {
+	queryString := `
+	UPDATE users
+	SET totp_secret=?, totp_enabled_at=NULL, totp_step=NULL
+	WHERE user_id=?;`
+
+	res, err := s.db.ExecContext(ctx, queryString, secret, userID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+// EnableTOTP confirms enrollment and replaces recovery codes of the user in one transaction.
+func (s *db) EnableTOTP(ctx context.Context, userID uint, recoveryCodeHashes []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+	UPDATE users
+	SET totp_enabled_at=CURRENT_TIMESTAMP
+	WHERE user_id=?;`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	DELETE
+	FROM recovery_codes
+	WHERE user_id=?;`, userID)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO recovery_codes (user_id, code_hash)
+	VALUES (?, ?);`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, h := range recoveryCodeHashes {
+		if _, err = stmt.ExecContext(ctx, userID, h); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ResetTOTP disables two-factor authentication and removes recovery codes.
+func (s *db) ResetTOTP(ctx context.Context, userID uint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+	UPDATE users
+	SET totp_secret=NULL, totp_enabled_at=NULL, totp_step=NULL
+	WHERE user_id=?;`, userID)
+	if err != nil {
+		return err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if rowsAff == 0 {
+		return apperror.ErrNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	DELETE
+	FROM recovery_codes
+	WHERE user_id=?;`, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindRecoveryCodes returns unused recovery codes of the user.
+func (s *db) FindRecoveryCodes(ctx context.Context, userID uint) ([]*models.RecoveryCode, error) {
+	codes := make([]*models.RecoveryCode, 0, 10)
+
+	queryString := `
+	SELECT code_id, user_id, code_hash
+	FROM recovery_codes
+	WHERE user_id=? AND used_at IS NULL;`
+
+	rows, err := s.db.QueryContext(ctx, queryString, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		c := &models.RecoveryCode{}
+		if err = rows.Scan(&c.ID, &c.UserID, &c.CodeHash); err != nil {
+			return nil, err
+		}
+		codes = append(codes, c)
+	}
+	if err = rows.Err(); err != nil {
+		return codes, err
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode marks code as used. Returns false if the code has been used concurrently.
+func (s *db) UseRecoveryCode(ctx context.Context, codeID uint) (bool, error) {
+	queryString := `
+	UPDATE recovery_codes
+	SET used_at=CURRENT_TIMESTAMP
+	WHERE code_id=? AND used_at IS NULL;`
+
+	res, err := s.db.ExecContext(ctx, queryString, codeID)
+	if err != nil {
+		return false, err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAff == 1, nil
+}
+
+// UseTOTPStep saves time step of accepted TOTP code. Returns false if code of the same or later step
+// has been accepted already.
+func (s *db) UseTOTPStep(ctx context.Context, userID uint, step uint64) (bool, error) {
+	queryString := `
+	UPDATE users
+	SET totp_step=?
+	WHERE user_id=? AND (totp_step IS NULL OR totp_step<?);`
+
+	res, err := s.db.ExecContext(ctx, queryString, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	rowsAff, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAff == 1, nil
+}
@@ -0,0 +1,186 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+const phoneCodeLength = 6
+
+// SetPhone starts verification of a new phone number of the user by sending one-time code to it.
+// Phone is saved in the profile only after the code is confirmed with VerifyPhone.
+func (s *service) SetPhone(ctx context.Context, userID uint, dto *models.SetPhoneDTO) error {
+	dto.Phone = models.NormalizePhone(dto.Phone)
+	if err := dto.ValidateFields(); err != nil {
+		return err
+	}
+
+	if _, err := s.storage.FindByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.throttleCode(ctx, userID, dto.Phone); err != nil {
+		return err
+	}
+
+	code, err := generateCode(phoneCodeLength)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification code. error: %w", err)
+	}
+
+	v := &models.PhoneVerification{
+		UserID:    userID,
+		Phone:     dto.Phone,
+		CodeHash:  hashCode(userID, code),
+		ExpiresAt: time.Now().Add(s.cfg.PhoneCodeTTL),
+	}
+	if err = s.storage.SavePhoneVerification(ctx, v); err != nil {
+		return fmt.Errorf("failed to save phone verification. error: %w", err)
+	}
+
+	s.logger.Debugf("sending verification code to user %d", userID)
+	if err = s.smsSender.Send(ctx, dto.Phone, fmt.Sprintf("Код подтверждения: %s", code)); err != nil {
+		return fmt.Errorf("failed to send verification code. error: %w", err)
+	}
+	return nil
+}
+
+// VerifyPhone checks one-time code and marks pending phone of the user as verified.
+func (s *service) VerifyPhone(ctx context.Context, userID uint, dto *models.VerifyPhoneDTO) error {
+	if err := dto.ValidateFields(); err != nil {
+		return err
+	}
+
+	v, err := s.storage.FindPhoneVerification(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().After(v.ExpiresAt) {
+		return apperror.ErrCodeExpired
+	}
+	if v.Attempts >= s.cfg.PhoneCodeMaxAttempts {
+		return apperror.ErrTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(v.CodeHash), []byte(hashCode(userID, dto.Code))) != 1 {
+		if err = s.storage.IncrementPhoneVerificationAttempts(ctx, userID); err != nil {
+			return fmt.Errorf("failed to count verification attempt. error: %w", err)
+		}
+		return apperror.ErrWrongCode
+	}
+
+	if err = s.storage.SetVerifiedPhone(ctx, userID, v.Phone); err != nil {
+		return fmt.Errorf("failed to save verified phone. error: %w", err)
+	}
+	if err = s.storage.DeletePhoneVerification(ctx, userID); err != nil {
+		s.logger.Errorf("failed to delete used phone verification of user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// RevealContact returns phone of the lot owner to the viewer and records it in audit log.
+// Viewer must have a verified phone too and is limited in number of distinct lots per day,
+// repeated reveals of the same lot during the day are not counted.
+func (s *service) RevealContact(ctx context.Context, dto *models.RevealContactDTO) (*models.Contact, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, err
+	}
+
+	viewer, err := s.storage.FindByID(ctx, dto.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !viewer.PhoneVerified() {
+		return nil, apperror.ErrPhoneNotVerified
+	}
+
+	owner, err := s.storage.FindByID(ctx, dto.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !owner.PhoneVerified() {
+		return nil, apperror.ErrContactNotAvailable
+	}
+
+	if viewer.ID != owner.ID {
+		reveal := &models.ContactReveal{
+			ViewerID: viewer.ID,
+			OwnerID:  owner.ID,
+			LotID:    dto.LotID,
+		}
+		err = s.storage.CreateContactReveal(ctx, reveal, startOfDay(time.Now().UTC()), s.cfg.DailyRevealLimit)
+		if err != nil {
+			if errors.Is(err, apperror.ErrRevealLimitExceeded) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to save contact reveal. error: %w", err)
+		}
+	}
+
+	return &models.Contact{
+		UserID: owner.ID,
+		Phone:  owner.Phone,
+	}, nil
+}
+
+// throttleCode limits how often verification codes are sent, both to the user and to the phone,
+// so the endpoint can't be used to pump SMS to a number. Every send is counted, confirmed or not.
+func (s *service) throttleCode(ctx context.Context, userID uint, phone string) error {
+	userKey, phoneKey := userCodeKey(userID), phoneCodeKey(phone)
+	if err := s.userCodeLimiter.Allow(ctx, userKey); err != nil {
+		return fmt.Errorf("verification code was sent recently. error: %w", err)
+	}
+	if err := s.phoneCodeLimiter.Allow(ctx, phoneKey); err != nil {
+		return fmt.Errorf("verification code was sent recently. error: %w", err)
+	}
+
+	if _, err := s.userCodeLimiter.Fail(ctx, userKey); err != nil {
+		return fmt.Errorf("failed to count sent code. error: %w", err)
+	}
+	if _, err := s.phoneCodeLimiter.Fail(ctx, phoneKey); err != nil {
+		return fmt.Errorf("failed to count sent code. error: %w", err)
+	}
+	return nil
+}
+
+func userCodeKey(userID uint) string {
+	return "phone_code:user:" + strconv.Itoa(int(userID))
+}
+
+func phoneCodeKey(phone string) string {
+	return "phone_code:phone:" + phone
+}
+
+func generateCode(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
+
+// hashCode binds code to the user, so the same code of different users has different hashes.
+func hashCode(userID uint, code string) string {
+	sum := sha256.Sum256([]byte(strconv.Itoa(int(userID)) + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
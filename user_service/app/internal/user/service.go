@@ -6,18 +6,49 @@ import (
 	"fmt"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/apperror"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
+	"github.com/levelord1311/backendForSharedProject/user_service/pkg/limiter"
 	"github.com/levelord1311/backendForSharedProject/user_service/pkg/logging"
+	"github.com/levelord1311/backendForSharedProject/user_service/pkg/passhash"
+	"github.com/levelord1311/backendForSharedProject/user_service/pkg/sms"
+	"time"
 )
 
+type Config struct {
+	PhoneCodeTTL         time.Duration
+	PhoneCodeMaxAttempts int
+	DailyRevealLimit     int
+	TOTPIssuer           string
+	RecoveryCodesCount   int
+	AccountLimits        limiter.Config
+	IPLimits             limiter.Config
+	UserCodeLimits       limiter.Config // sending of phone verification codes by one user
+	PhoneCodeLimits      limiter.Config // sending of phone verification codes to one phone
+}
+
 type service struct {
-	storage Storage
-	logger  logging.Logger
+	storage          Storage
+	smsSender        sms.Sender
+	accountLimiter   *limiter.Limiter
+	ipLimiter        *limiter.Limiter
+	userCodeLimiter  *limiter.Limiter
+	phoneCodeLimiter *limiter.Limiter
+	hasher           passhash.Hasher
+	cfg              Config
+	logger           logging.Logger
 }
 
-func NewService(userStorage Storage, logger logging.Logger) (*service, error) {
+func NewService(userStorage Storage, smsSender sms.Sender, attempts limiter.Store, hasher passhash.Hasher,
+	cfg Config, logger logging.Logger) (*service, error) {
 	return &service{
-		storage: userStorage,
-		logger:  logger,
+		storage:          userStorage,
+		smsSender:        smsSender,
+		accountLimiter:   limiter.New(attempts, cfg.AccountLimits),
+		ipLimiter:        limiter.New(attempts, cfg.IPLimits),
+		userCodeLimiter:  limiter.New(attempts, cfg.UserCodeLimits),
+		phoneCodeLimiter: limiter.New(attempts, cfg.PhoneCodeLimits),
+		hasher:           hasher,
+		cfg:              cfg,
+		logger:           logger,
 	}, nil
 }
 
@@ -37,7 +68,7 @@ func (s *service) Create(ctx context.Context, dto *models.CreateUserDTO) (uint,
 		return 0, err
 	}
 
-	if err := user.EncryptPassword(); err != nil {
+	if err := user.EncryptPassword(s.hasher); err != nil {
 		return 0, err
 	}
 	user.Sanitize()
@@ -54,7 +85,15 @@ func (s *service) Create(ctx context.Context, dto *models.CreateUserDTO) (uint,
 
 }
 
+// SignIn checks credentials of the user. Failed attempts are counted per account and per IP,
+// see attempts.go.
 func (s *service) SignIn(ctx context.Context, dto *models.SignInUserDTO) (*models.User, error) {
+	if dto.IP != "" {
+		if err := s.ipLimiter.Allow(ctx, ipAttemptsKey(dto.IP)); err != nil {
+			return nil, err
+		}
+	}
+
 	var u *models.User
 	var err error
 	if dto.LoginIsEmail() {
@@ -63,16 +102,58 @@ func (s *service) SignIn(ctx context.Context, dto *models.SignInUserDTO) (*model
 		u, err = s.storage.FindByUsername(ctx, dto.Login)
 	}
 	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			s.registerFailure(ctx, nil, dto.IP)
+			return nil, apperror.ErrWrongCredentials
+		}
+		return nil, err
+	}
+
+	if err = s.accountLimiter.Allow(ctx, accountAttemptsKey(u.ID)); err != nil {
 		return nil, err
 	}
-	if !u.ComparePassword(dto.Password) {
+
+	ok, err := u.ComparePassword(s.hasher, dto.Password)
+	if err != nil {
+		s.logger.Errorf("failed to compare password of user %d. error: %v", u.ID, err)
+	}
+	if !ok {
+		s.registerFailure(ctx, u, dto.IP)
 		return nil, apperror.ErrWrongCredentials
 	}
 
+	s.rehashPassword(ctx, u, dto.Password)
+
+	if err = s.accountLimiter.Reset(ctx, accountAttemptsKey(u.ID)); err != nil {
+		s.logger.Errorf("failed to reset sign in attempts of user %d. error: %v", u.ID, err)
+	}
+
 	return u, nil
 
 }
 
+// rehashPassword saves new hash of the password if the stored one doesn't match current hashing policy,
+// so users are migrated to it on sign in without resetting passwords. Errors are only logged.
+func (s *service) rehashPassword(ctx context.Context, u *models.User, password string) {
+	if !s.hasher.NeedsRehash(u.EncryptedPassword) {
+		return
+	}
+
+	u.Password = password
+	err := u.EncryptPassword(s.hasher)
+	u.Sanitize()
+	if err != nil {
+		s.logger.Errorf("failed to rehash password of user %d. error: %v", u.ID, err)
+		return
+	}
+
+	if err = s.storage.Update(ctx, u); err != nil {
+		s.logger.Errorf("failed to save rehashed password of user %d. error: %v", u.ID, err)
+		return
+	}
+	s.logger.Infof("password of user %d has been rehashed", u.ID)
+}
+
 //func (s *service) UpdatePassword(ctx context.Context, dto *models.UpdateUserDTO) error {
 //
 //	s.logger.Debug("validating DTO fields..")
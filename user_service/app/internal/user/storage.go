@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
+	"time"
 )
 
 type Storage interface {
@@ -12,4 +13,21 @@ type Storage interface {
 	FindByID(ctx context.Context, id uint) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, id uint) error
+
+	SetVerifiedPhone(ctx context.Context, userID uint, phone string) error
+	SavePhoneVerification(ctx context.Context, v *models.PhoneVerification) error
+	FindPhoneVerification(ctx context.Context, userID uint) (*models.PhoneVerification, error)
+	IncrementPhoneVerificationAttempts(ctx context.Context, userID uint) error
+	DeletePhoneVerification(ctx context.Context, userID uint) error
+
+	SetTOTPSecret(ctx context.Context, userID uint, secret string) error
+	EnableTOTP(ctx context.Context, userID uint, recoveryCodeHashes []string) error
+	ResetTOTP(ctx context.Context, userID uint) error
+	FindRecoveryCodes(ctx context.Context, userID uint) ([]*models.RecoveryCode, error)
+	UseRecoveryCode(ctx context.Context, codeID uint) (bool, error)
+	UseTOTPStep(ctx context.Context, userID uint, step uint64) (bool, error)
+
+	// CreateContactReveal returns apperror.ErrRevealLimitExceeded if the viewer has revealed
+	// contacts of limit distinct lots since given time.
+	CreateContactReveal(ctx context.Context, r *models.ContactReveal, since time.Time, limit int) error
 }
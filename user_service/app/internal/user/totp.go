@@ -0,0 +1,217 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/apperror"
+	"github.com/levelord1311/backendForSharedProject/user_service/internal/models"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"image/png"
+	"strings"
+	"time"
+)
+
+const (
+	qrCodeSize = 256
+	// totpPeriod and totpSkew are defaults of authenticator apps and of totp.Validate
+	totpPeriod = 30
+	totpSkew   = 1
+)
+
+// EnrollTOTP generates new TOTP secret for the user. Two-factor authentication is not enabled
+// until the user confirms enrollment with a code from authenticator app.
+func (s *service) EnrollTOTP(ctx context.Context, userID uint) (*models.TOTPEnrollment, error) {
+	u, err := s.storage.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.TwoFactorEnabled() {
+		return nil, apperror.ErrTwoFactorEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.cfg.TOTPIssuer,
+		AccountName: u.Username,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP key. error: %w", err)
+	}
+
+	img, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code. error: %w", err)
+	}
+	var qr bytes.Buffer
+	if err = png.Encode(&qr, img); err != nil {
+		return nil, fmt.Errorf("failed to encode QR code. error: %w", err)
+	}
+
+	if err = s.storage.SetTOTPSecret(ctx, userID, key.Secret()); err != nil {
+		return nil, fmt.Errorf("failed to save TOTP secret. error: %w", err)
+	}
+
+	return &models.TOTPEnrollment{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: qr.Bytes(),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication if the code matches enrolled secret
+// and returns recovery codes. They are shown only once, only hashes are stored.
+func (s *service) ConfirmTOTP(ctx context.Context, userID uint, dto *models.TOTPCodeDTO) (*models.RecoveryCodes, error) {
+	if err := dto.ValidateFields(); err != nil {
+		return nil, err
+	}
+
+	u, err := s.storage.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.TwoFactorEnabled() {
+		return nil, apperror.ErrTwoFactorEnabled
+	}
+	if u.TOTPSecret == "" {
+		return nil, apperror.ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := totpStep(dto.Code, u.TOTPSecret, time.Now())
+	if !ok {
+		return nil, apperror.ErrWrongCode
+	}
+	// the code confirming enrollment can't be used to sign in
+	accepted, err := s.storage.UseTOTPStep(ctx, userID, step)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save TOTP step. error: %w", err)
+	}
+	if !accepted {
+		return nil, apperror.ErrWrongCode
+	}
+
+	codes := make([]string, s.cfg.RecoveryCodesCount)
+	hashes := make([]string, s.cfg.RecoveryCodesCount)
+	for i := range codes {
+		codes[i], err = generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code. error: %w", err)
+		}
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err = s.storage.EnableTOTP(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication. error: %w", err)
+	}
+
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// VerifyTOTP checks the second factor of the user on sign in.
+// Code is either current TOTP code or one of unused recovery codes, which becomes used.
+// TOTP code is accepted once, as well as codes of earlier time steps after it.
+func (s *service) VerifyTOTP(ctx context.Context, dto *models.VerifyTOTPDTO) error {
+	if err := dto.ValidateFields(); err != nil {
+		return err
+	}
+
+	u, err := s.storage.FindByID(ctx, dto.UserID)
+	if err != nil {
+		return err
+	}
+	if !u.TwoFactorEnabled() {
+		return apperror.ErrTwoFactorDisabled
+	}
+
+	// wrong codes count as failed sign in attempts, so the second factor can't be brute-forced
+	if err = s.accountLimiter.Allow(ctx, accountAttemptsKey(u.ID)); err != nil {
+		return err
+	}
+
+	if step, ok := totpStep(dto.Code, u.TOTPSecret, time.Now()); ok {
+		accepted, err := s.storage.UseTOTPStep(ctx, u.ID, step)
+		if err != nil {
+			return fmt.Errorf("failed to save TOTP step. error: %w", err)
+		}
+		if accepted {
+			return nil
+		}
+		// intercepted code may be replayed within its period
+		s.logger.Warnf("user %d used TOTP code, which has been used already", u.ID)
+		s.registerFailure(ctx, u, "")
+		return apperror.ErrWrongCode
+	}
+
+	codes, err := s.storage.FindRecoveryCodes(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find recovery codes. error: %w", err)
+	}
+	hash := hashRecoveryCode(dto.Code)
+	for _, c := range codes {
+		if subtle.ConstantTimeCompare([]byte(c.CodeHash), []byte(hash)) != 1 {
+			continue
+		}
+		used, err := s.storage.UseRecoveryCode(ctx, c.ID)
+		if err != nil {
+			return fmt.Errorf("failed to use recovery code. error: %w", err)
+		}
+		if !used {
+			break
+		}
+		s.logger.Infof("user %d signed in with recovery code", u.ID)
+		return nil
+	}
+
+	s.registerFailure(ctx, u, "")
+	return apperror.ErrWrongCode
+}
+
+// ResetTOTP disables two-factor authentication of the user, i.e. when authenticator app is lost.
+func (s *service) ResetTOTP(ctx context.Context, userID uint) error {
+	if err := s.storage.ResetTOTP(ctx, userID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to reset two-factor authentication. error: %w", err)
+	}
+	return nil
+}
+
+// totpStep returns time step of the code, if the code is valid for the time within totpSkew steps.
+func totpStep(code, secret string, t time.Time) (uint64, bool) {
+	opts := totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+	current := uint64(t.Unix()) / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(int64(step*totpPeriod), 0), opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// generateRecoveryCode returns random code like "k3x9a-7fqzt".
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode uses fast hash, as recovery codes are random and long enough to resist brute force.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"github.com/pquerna/otp/totp"
+	"testing"
+	"time"
+)
+
+func TestTOTPStep(t *testing.T) {
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "test", AccountName: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	current := uint64(now.Unix()) / totpPeriod
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		step  uint64
+		valid bool
+	}{
+		{name: "current code", at: now, step: current, valid: true},
+		{name: "previous code", at: now.Add(-totpPeriod * time.Second), step: current - 1, valid: true},
+		{name: "next code", at: now.Add(totpPeriod * time.Second), step: current + 1, valid: true},
+		{name: "expired code", at: now.Add(-2 * totpPeriod * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := totp.GenerateCode(key.Secret(), tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			step, ok := totpStep(code, key.Secret(), now)
+			if ok != tt.valid || step != tt.step {
+				t.Errorf("expected step %d and valid %t, got %d and %t", tt.step, tt.valid, step, ok)
+			}
+		})
+	}
+}
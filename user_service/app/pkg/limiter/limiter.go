@@ -0,0 +1,138 @@
+package limiter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	TypeMemory = "memory"
+	TypeMySQL  = "mysql"
+)
+
+// Attempts is the state of failed attempts made with one key.
+type Attempts struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// Store keeps failed attempts. Use shared store (i.e. mysql) when several instances
+// of the service must see the same attempts.
+type Store interface {
+	// Get returns zero Attempts if nothing is stored for the key.
+	Get(ctx context.Context, key string) (Attempts, error)
+	// Fail atomically registers failed attempt. Failures older than window are forgotten.
+	Fail(ctx context.Context, key string, at time.Time, window time.Duration) (Attempts, error)
+	// Lock locks the key until given time and resets its failures.
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// NewStore returns Store of given type. Memory store is not shared between instances of the service.
+func NewStore(storeType string, db *sql.DB) (Store, error) {
+	switch storeType {
+	case TypeMemory:
+		return NewMemoryStore(), nil
+	case TypeMySQL:
+		return NewMySQLStore(db), nil
+	default:
+		return nil, fmt.Errorf("unknown limiter store type %q", storeType)
+	}
+}
+
+type Config struct {
+	FreeAttempts    int           // failures allowed without delay
+	BaseDelay       time.Duration // delay after the first failure over FreeAttempts, doubled on each next one
+	MaxDelay        time.Duration
+	MaxAttempts     int // failures that lock the key
+	LockoutDuration time.Duration
+	Window          time.Duration
+}
+
+// BlockedError is returned when the key must not be used until RetryAfter passes.
+type BlockedError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *BlockedError) Error() string {
+	if e.Locked {
+		return "temporarily locked due to too many failed attempts"
+	}
+	return "too many failed attempts, try again later"
+}
+
+// Limiter slows down attempts with exponential backoff and locks the key
+// after too many failures.
+type Limiter struct {
+	store Store
+	cfg   Config
+	now   func() time.Time
+}
+
+func New(store Store, cfg Config) *Limiter {
+	return &Limiter{
+		store: store,
+		cfg:   cfg,
+		now:   time.Now,
+	}
+}
+
+// Allow returns *BlockedError if the next attempt with the key is not allowed yet.
+func (l *Limiter) Allow(ctx context.Context, key string) error {
+	a, err := l.store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to get attempts. error: %w", err)
+	}
+
+	now := l.now()
+	if now.Before(a.LockedUntil) {
+		return &BlockedError{RetryAfter: a.LockedUntil.Sub(now), Locked: true}
+	}
+
+	if next := a.LastFailureAt.Add(l.delay(a.Failures)); now.Before(next) {
+		return &BlockedError{RetryAfter: next.Sub(now)}
+	}
+	return nil
+}
+
+// Fail registers failed attempt and reports whether the key has been locked by it.
+func (l *Limiter) Fail(ctx context.Context, key string) (bool, error) {
+	now := l.now()
+	a, err := l.store.Fail(ctx, key, now, l.cfg.Window)
+	if err != nil {
+		return false, fmt.Errorf("failed to register attempt. error: %w", err)
+	}
+
+	if a.Failures < l.cfg.MaxAttempts {
+		return false, nil
+	}
+
+	if err = l.store.Lock(ctx, key, now.Add(l.cfg.LockoutDuration)); err != nil {
+		return false, fmt.Errorf("failed to lock. error: %w", err)
+	}
+	return true, nil
+}
+
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, key)
+}
+
+func (l *Limiter) delay(failures int) time.Duration {
+	over := failures - l.cfg.FreeAttempts
+	if over <= 0 {
+		return 0
+	}
+
+	delay := l.cfg.BaseDelay
+	for i := 1; i < over; i++ {
+		delay *= 2
+		if delay >= l.cfg.MaxDelay {
+			return l.cfg.MaxDelay
+		}
+	}
+	return delay
+}
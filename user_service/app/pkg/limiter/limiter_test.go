@@ -0,0 +1,89 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTestLimiter(now *time.Time) *Limiter {
+	l := New(NewMemoryStore(), Config{
+		FreeAttempts:    2,
+		BaseDelay:       time.Second,
+		MaxDelay:        4 * time.Second,
+		MaxAttempts:     6,
+		LockoutDuration: time.Minute,
+		Window:          time.Hour,
+	})
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestLimiter_Backoff(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	l := newTestLimiter(&now)
+
+	wantDelays := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second}
+	for i, want := range wantDelays {
+		locked, err := l.Fail(ctx, "key")
+		assert.NoError(t, err)
+		assert.False(t, locked)
+
+		err = l.Allow(ctx, "key")
+		if want == 0 {
+			assert.NoError(t, err, "failure %d", i+1)
+			continue
+		}
+
+		var blocked *BlockedError
+		if assert.True(t, errors.As(err, &blocked), "failure %d", i+1) {
+			assert.Equal(t, want, blocked.RetryAfter)
+			assert.False(t, blocked.Locked)
+		}
+		now = now.Add(want)
+		assert.NoError(t, l.Allow(ctx, "key"))
+	}
+}
+
+func TestLimiter_Lockout(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	l := newTestLimiter(&now)
+
+	for i := 1; i < 6; i++ {
+		locked, err := l.Fail(ctx, "key")
+		assert.NoError(t, err)
+		assert.False(t, locked)
+	}
+	locked, err := l.Fail(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	var blocked *BlockedError
+	if assert.True(t, errors.As(l.Allow(ctx, "key"), &blocked)) {
+		assert.True(t, blocked.Locked)
+		assert.Equal(t, time.Minute, blocked.RetryAfter)
+	}
+	assert.NoError(t, l.Allow(ctx, "other key"))
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, l.Allow(ctx, "key"))
+}
+
+func TestLimiter_Reset(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	l := newTestLimiter(&now)
+
+	for i := 0; i < 6; i++ {
+		_, err := l.Fail(ctx, "key")
+		assert.NoError(t, err)
+	}
+	assert.Error(t, l.Allow(ctx, "key"))
+
+	assert.NoError(t, l.Reset(ctx, "key"))
+	assert.NoError(t, l.Allow(ctx, "key"))
+}
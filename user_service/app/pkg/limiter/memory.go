@@ -0,0 +1,78 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	attempts  Attempts
+	expiresAt time.Time
+}
+
+// memoryStore keeps attempts in memory of the process. It is not shared between instances.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return Attempts{}, nil
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(s.entries, key)
+		return Attempts{}, nil
+	}
+	return e.attempts, nil
+}
+
+func (s *memoryStore) Fail(_ context.Context, key string, at time.Time, window time.Duration) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || at.After(e.expiresAt) {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	e.attempts.Failures++
+	e.attempts.LastFailureAt = at
+	if expiresAt := at.Add(window); expiresAt.After(e.expiresAt) {
+		e.expiresAt = expiresAt
+	}
+	return e.attempts, nil
+}
+
+func (s *memoryStore) Lock(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	e.attempts = Attempts{LockedUntil: until}
+	if until.After(e.expiresAt) {
+		e.expiresAt = until
+	}
+	return nil
+}
+
+func (s *memoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
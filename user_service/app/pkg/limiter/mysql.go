@@ -0,0 +1,82 @@
+package limiter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// mysqlStore keeps attempts in login_attempts table, so they are shared by all instances.
+type mysqlStore struct {
+	db *sql.DB
+}
+
+func NewMySQLStore(db *sql.DB) Store {
+	return &mysqlStore{db: db}
+}
+
+func (s *mysqlStore) Get(ctx context.Context, key string) (Attempts, error) {
+	var a Attempts
+	var lastFailureAt, lockedUntil sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE attempt_key = ? AND expires_at > ?`,
+		key, time.Now().UTC()).Scan(&a.Failures, &lastFailureAt, &lockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Attempts{}, nil
+		}
+		return Attempts{}, err
+	}
+	if a.LastFailureAt, err = parseTime(lastFailureAt); err != nil {
+		return Attempts{}, err
+	}
+	if a.LockedUntil, err = parseTime(lockedUntil); err != nil {
+		return Attempts{}, err
+	}
+	return a, nil
+}
+
+func (s *mysqlStore) Fail(ctx context.Context, key string, at time.Time, window time.Duration) (Attempts, error) {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO login_attempts (attempt_key, failures, last_failure_at, expires_at)
+		VALUES (?, 1, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			failures = IF(expires_at < VALUES(last_failure_at), 1, failures + 1),
+			locked_until = IF(expires_at < VALUES(last_failure_at), NULL, locked_until),
+			last_failure_at = VALUES(last_failure_at),
+			expires_at = GREATEST(expires_at, VALUES(expires_at))`,
+		key, at.UTC(), at.Add(window).UTC())
+	if err != nil {
+		return Attempts{}, err
+	}
+	return s.Get(ctx, key)
+}
+
+func (s *mysqlStore) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO login_attempts (attempt_key, failures, locked_until, expires_at)
+		VALUES (?, 0, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			failures = 0,
+			last_failure_at = NULL,
+			locked_until = VALUES(locked_until),
+			expires_at = GREATEST(expires_at, VALUES(expires_at))`,
+		key, until.UTC(), until.UTC())
+	return err
+}
+
+func (s *mysqlStore) Reset(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE attempt_key = ?`, key)
+	return err
+}
+
+// parseTime parses DATETIME column, which is returned as text without parseTime option of the driver.
+func parseTime(s sql.NullString) (time.Time, error) {
+	if !s.Valid {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", s.String, time.UTC)
+}
@@ -0,0 +1,164 @@
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+
+	argon2idPrefix = "$argon2id$"
+)
+
+var ErrUnknownFormat = errors.New("unknown password hash format")
+
+// Hasher hashes passwords according to the current policy. It can compare passwords with hashes
+// made by any supported algorithm, so hashes made under older policies stay valid until rehashed.
+type Hasher interface {
+	Hash(password string) (string, error)
+	Compare(hash, password string) (bool, error)
+	// NeedsRehash reports whether the hash was made by another algorithm or with other parameters
+	// than the current policy requires.
+	NeedsRehash(hash string) bool
+}
+
+type Config struct {
+	Algorithm  string // bcrypt or argon2id
+	BcryptCost int
+	Argon2id   Argon2idParams
+}
+
+// Argon2idParams are encoded into every argon2id hash, see RFC 9106.
+type Argon2idParams struct {
+	Time    uint32
+	Memory  uint32 // in KiB
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+func New(cfg Config) (Hasher, error) {
+	switch cfg.Algorithm {
+	case AlgorithmBcrypt:
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case AlgorithmArgon2id:
+		p := cfg.Argon2id
+		if p.Time == 0 || p.Memory == 0 || p.Threads == 0 || p.SaltLen == 0 || p.KeyLen == 0 {
+			return nil, errors.New("all argon2id parameters must be positive")
+		}
+	default:
+		return nil, fmt.Errorf("unknown password hashing algorithm %q", cfg.Algorithm)
+	}
+	return &hasher{cfg: cfg}, nil
+}
+
+type hasher struct {
+	cfg Config
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	if h.cfg.Algorithm == AlgorithmArgon2id {
+		return hashArgon2id(password, h.cfg.Argon2id)
+	}
+
+	b, err := bcrypt.GenerateFromPassword([]byte(password), h.cfg.BcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (h *hasher) Compare(hash, password string) (bool, error) {
+	switch {
+	case isBcrypt(hash):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	case strings.HasPrefix(hash, argon2idPrefix):
+		p, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1, nil
+	default:
+		return false, ErrUnknownFormat
+	}
+}
+
+func (h *hasher) NeedsRehash(hash string) bool {
+	switch h.cfg.Algorithm {
+	case AlgorithmBcrypt:
+		if !isBcrypt(hash) {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.cfg.BcryptCost
+	case AlgorithmArgon2id:
+		if !strings.HasPrefix(hash, argon2idPrefix) {
+			return true
+		}
+		p, _, _, err := decodeArgon2id(hash)
+		return err != nil || p != h.cfg.Argon2id
+	default:
+		return false
+	}
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// hashArgon2id returns hash in PHC string format: $argon2id$v=19$m=65536,t=1,p=4$salt$key
+func hashArgon2id(password string, p Argon2idParams) (string, error) {
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, p.Memory, p.Time, p.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func decodeArgon2id(hash string) (p Argon2idParams, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, ErrUnknownFormat
+	}
+	if version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, nil, nil, ErrUnknownFormat
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return p, nil, nil, ErrUnknownFormat
+	}
+	p.SaltLen = uint32(len(salt))
+	p.KeyLen = uint32(len(key))
+	return p, salt, key, nil
+}
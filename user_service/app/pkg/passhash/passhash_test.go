@@ -0,0 +1,101 @@
+package passhash
+
+import (
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+)
+
+var testArgon2id = Argon2idParams{Time: 1, Memory: 1024, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+func TestHasher_Compare(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "bcrypt",
+			cfg:  Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost},
+		},
+		{
+			name: "argon2id",
+			cfg:  Config{Algorithm: AlgorithmArgon2id, Argon2id: testArgon2id},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			h, err := New(test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hash, err := h.Hash("somePassword")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ok, err := h.Compare(hash, "somePassword")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = h.Compare(hash, "otherPassword")
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.False(t, h.NeedsRehash(hash))
+		})
+	}
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	oldBcrypt, err := New(Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldHash, err := oldBcrypt.Hash("somePassword")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newBcrypt, err := New(Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, newBcrypt.NeedsRehash(oldHash))
+
+	argon, err := New(Config{Algorithm: AlgorithmArgon2id, Argon2id: testArgon2id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, argon.NeedsRehash(oldHash))
+
+	// hashes made under the old policy are still accepted
+	ok, err := argon.Compare(oldHash, "somePassword")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	argonHash, err := argon.Hash("somePassword")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stronger := testArgon2id
+	stronger.Time = 2
+	strongerArgon, err := New(Config{Algorithm: AlgorithmArgon2id, Argon2id: stronger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, strongerArgon.NeedsRehash(argonHash))
+	assert.True(t, newBcrypt.NeedsRehash(argonHash))
+}
+
+func TestHasher_UnknownFormat(t *testing.T) {
+	h, err := New(Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.DefaultCost})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = h.Compare("plain text", "plain text")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	assert.True(t, h.NeedsRehash("plain text"))
+}
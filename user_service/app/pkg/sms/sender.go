@@ -0,0 +1,66 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"github.com/levelord1311/backendForSharedProject/user_service/pkg/logging"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	TypeLog  = "log"
+	TypeFile = "file"
+)
+
+// Sender delivers text messages to phone numbers.
+// Implementations for real SMS gateways must be safe for concurrent use.
+type Sender interface {
+	Send(ctx context.Context, phone, text string) error
+}
+
+// NewSender returns Sender of given type. Log and file senders are fakes for local runs,
+// they don't deliver anything.
+func NewSender(senderType, filePath string, logger logging.Logger) (Sender, error) {
+	switch senderType {
+	case TypeLog:
+		return &logSender{logger: logger}, nil
+	case TypeFile:
+		if filePath == "" {
+			return nil, fmt.Errorf("file path is required for sms sender of type %q", senderType)
+		}
+		return &fileSender{path: filePath}, nil
+	default:
+		return nil, fmt.Errorf("unknown sms sender type %q", senderType)
+	}
+}
+
+type logSender struct {
+	logger logging.Logger
+}
+
+func (s *logSender) Send(_ context.Context, phone, text string) error {
+	s.logger.Infof("SMS to %s: %s", phone, text)
+	return nil
+}
+
+// fileSender appends messages to a file, one per line.
+type fileSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (s *fileSender) Send(_ context.Context, phone, text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open sms file. error: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), phone, text)
+	return err
+}